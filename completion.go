@@ -0,0 +1,281 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// completeCommandName is the hidden command the completion scripts call to
+// complete the command line typed after "--".
+const completeCommandName = "__complete"
+
+// completionScripts are the completion scripts by shell, formatted with the
+// program name. They defer every candidate to the __complete command, so new
+// commands, plugins and installs appear without regenerating them.
+var completionScripts = map[string]string{ //nolint:gochecknoglobals // read-only lookup table
+	"bash": `# bash completion for %[1]s
+_%[2]s() {
+	local IFS=$'\n'
+	COMPREPLY=($(%[1]s ` + completeCommandName + ` -- "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _%[2]s %[1]s
+`,
+	"zsh": `#compdef %[1]s
+_%[2]s() {
+	local -a candidates
+	candidates=(${(f)"$(%[1]s ` + completeCommandName + ` -- "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+	compadd -a candidates
+}
+compdef _%[2]s %[1]s
+`,
+	"fish": `# fish completion for %[1]s
+function __%[2]s_complete
+	set -l tokens (commandline -opc) (commandline -ct)
+	%[1]s ` + completeCommandName + ` -- $tokens[2..-1] 2>/dev/null
+end
+complete -c %[1]s -f -a '(__%[2]s_complete)'
+`,
+}
+
+// installedVersionCommands are the commands whose version arguments name installed versions.
+var installedVersionCommands = []string{"uninstall", "where", "which", "diff-versions"} //nolint:gochecknoglobals // read-only lookup table
+
+// cmdCompletion prints the completion script of shell for the program name.
+func cmdCompletion(out io.Writer, name, shell string) error {
+	script, ok := completionScripts[shell]
+	if !ok {
+		return fmt.Errorf("%w: %s", errCompletionShellUnsupported, shell)
+	}
+
+	_, err := fmt.Fprintf(out, script, name, strings.ReplaceAll(name, "-", "_"))
+
+	return err
+}
+
+// cmdComplete prints the candidates completing the last of words, one per line.
+func cmdComplete(out io.Writer, app *cli.App, words []string) error {
+	if len(words) > 0 && words[0] == "--" {
+		words = words[1:]
+	}
+
+	for _, candidate := range completionCandidates(app, words) {
+		if _, err := fmt.Fprintln(out, candidate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// completionCandidates returns the candidates completing the last of words,
+// the command line typed after the program name: commands, flags, plugin names
+// and installed versions, depending on the position.
+func completionCandidates(app *cli.App, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+
+	current := words[len(words)-1]
+	flags := app.Flags
+
+	var (
+		command     *cli.Command
+		pluginName  string
+		pending     string
+		positionals []string
+	)
+
+	for _, word := range words[:len(words)-1] {
+		switch {
+		case pending != "":
+			if pending == "plugin" {
+				pluginName = word
+			}
+
+			pending = ""
+		case isFlagArg(word):
+			name, value, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			flag := findFlag(flags, name)
+
+			switch {
+			case flag == nil:
+			case hasValue && flag.Names()[0] == "plugin":
+				pluginName = value
+			case takesValue(flagsTakingValue(flags), word):
+				pending = flag.Names()[0]
+			}
+		case command == nil:
+			if command = app.Command(word); command == nil {
+				return nil
+			}
+
+			flags = append(slices.Clip(app.Flags), command.Flags...)
+		case len(positionals) == 0 && findSubcommand(command, word) != nil:
+			command = findSubcommand(command, word)
+			flags = append(slices.Clip(app.Flags), command.Flags...)
+		default:
+			positionals = append(positionals, word)
+		}
+	}
+
+	var candidates []string
+
+	switch {
+	case pending == "plugin":
+		candidates = pluginNames()
+	case pending == "version" && command != nil && slices.Contains(installedVersionCommands, command.Name):
+		candidates = installedVersions(completionTool(pluginName, positionals))
+	case pending != "":
+	case strings.HasPrefix(current, "-"):
+		candidates = flagNames(flags)
+	case command == nil:
+		candidates = commandNames(app.VisibleCommands())
+	default:
+		candidates = positionalCandidates(command, pluginName, positionals)
+	}
+
+	return slices.DeleteFunc(candidates, func(candidate string) bool {
+		return !strings.HasPrefix(candidate, current)
+	})
+}
+
+// positionalCandidates returns the candidates for the next positional argument of command.
+func positionalCandidates(command *cli.Command, pluginName string, positionals []string) []string {
+	if len(command.Subcommands) > 0 && len(positionals) == 0 {
+		return commandNames(command.VisibleCommands())
+	}
+
+	if !takesToolArgument(command) {
+		return nil
+	}
+
+	if pluginName == "" && len(positionals) == 0 {
+		return pluginNames()
+	}
+
+	if slices.Contains(installedVersionCommands, command.Name) {
+		return installedVersions(completionTool(pluginName, positionals))
+	}
+
+	return nil
+}
+
+// takesToolArgument reports whether the first positional argument of command
+// names a plugin, either because it accepts --plugin or its usage says so.
+func takesToolArgument(command *cli.Command) bool {
+	return findFlag(command.Flags, "plugin") != nil ||
+		strings.HasPrefix(command.ArgsUsage, "<tool>") || strings.HasPrefix(command.ArgsUsage, "[tool]")
+}
+
+// completionTool returns the tool named by --plugin or the first positional argument.
+func completionTool(pluginName string, positionals []string) string {
+	if pluginName == "" && len(positionals) > 0 {
+		pluginName = positionals[0]
+	}
+
+	if plugin, err := plugins.GetPlugin(pluginName); err == nil {
+		return plugin.Name()
+	}
+
+	return pluginName
+}
+
+// findFlag returns the flag of flags called name, or nil.
+func findFlag(flags []cli.Flag, name string) cli.Flag {
+	for _, flag := range flags {
+		if slices.Contains(flag.Names(), name) {
+			return flag
+		}
+	}
+
+	return nil
+}
+
+// flagNames returns every name and alias of flags as typed on the command
+// line, once even when a flag is shared by the app and the command.
+func flagNames(flags []cli.Flag) []string {
+	var names []string
+
+	for _, flag := range flags {
+		for _, name := range flag.Names() {
+			if len(name) == 1 {
+				name = "-" + name
+			} else {
+				name = "--" + name
+			}
+
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// commandNames returns the names and aliases of commands.
+func commandNames(commands []*cli.Command) []string {
+	var names []string
+
+	for _, command := range commands {
+		names = append(names, command.Names()...)
+	}
+
+	return names
+}
+
+// pluginNames returns every registered plugin name and alias, sorted.
+func pluginNames() []string {
+	var names []string
+
+	for _, entry := range plugins.GetPluginRegistry().All() {
+		names = append(names, entry.Names...)
+	}
+
+	slices.Sort(names)
+
+	return slices.Compact(names)
+}
+
+// installedVersions returns the installed versions of tool, newest first.
+func installedVersions(tool string) []string {
+	if tool == "" {
+		return nil
+	}
+
+	installed, err := asdf.ListInstalled(tool, "", nil)
+	if err != nil {
+		asdf.Logger().Debug("listing installed versions for completion", "tool", tool, "error", err)
+
+		return nil
+	}
+
+	versions := make([]string, 0, len(installed))
+	for _, version := range installed {
+		versions = append(versions, version.Version)
+	}
+
+	return versions
+}
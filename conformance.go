@@ -0,0 +1,444 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/github"
+	githubmock "github.com/sumicare/universal-asdf-plugin/plugins/github/mock"
+)
+
+// Conformance check states printed by conformance.
+const (
+	conformanceStatePassed  = "ok"
+	conformanceStateFailed  = "FAIL"
+	conformanceStateSkipped = "skip"
+)
+
+const (
+	// defaultConformanceJobs is the number of plugins conformance checks at a time.
+	defaultConformanceJobs = 8
+	// defaultConformanceGoldenDir holds the versions recorded for the plugin tests.
+	defaultConformanceGoldenDir = "plugins/asdf/plugins/testdata"
+	// conformanceOnlineEnv makes conformance list versions from the upstreams when set to 1.
+	conformanceOnlineEnv = "ONLINE"
+	// conformanceVersion is downloaded when no latest stable version was resolved.
+	conformanceVersion = "1.2.3"
+)
+
+var (
+	// errConformanceFailed is returned when a plugin failed a conformance check.
+	errConformanceFailed = errors.New("conformance failed")
+	// errConformanceSkipped marks a check that does not apply to a plugin.
+	errConformanceSkipped = errors.New("skipped")
+	// errConformanceEmpty is returned when a plugin method returned nothing.
+	errConformanceEmpty = errors.New("empty result")
+	// errConformanceUnlisted is returned when the latest stable version is not a listed version.
+	errConformanceUnlisted = errors.New("latest stable version not listed by ListAll")
+	// errConformancePanic is returned when a plugin method panicked.
+	errConformancePanic = errors.New("panic")
+)
+
+type (
+	// conformanceTarget is a plugin exercised by conformance.
+	conformanceTarget struct {
+		plugin asdf.Plugin
+		name   string
+	}
+
+	// conformanceCheck exercises a plugin method. It returns an error wrapping
+	// errConformanceSkipped when the method cannot be exercised.
+	conformanceCheck struct {
+		run  func(ctx context.Context, run *conformanceRun) error
+		name string
+	}
+
+	// conformanceRun is the state the checks of a target share.
+	conformanceRun struct {
+		plugin asdf.Plugin
+		// closers stop the mock servers started for the plugin.
+		closers []func()
+		// goldenDir holds the recorded versions listed offline.
+		goldenDir string
+		// assetURL serves a placeholder for every download.
+		assetURL string
+		// latest is the latest stable version, once resolved.
+		latest   string
+		versions []string
+		online   bool
+	}
+
+	// conformanceGolden is the part of a recorded golden document conformance uses.
+	conformanceGolden struct {
+		Versions []string `json:"versions"`
+	}
+
+	// conformanceResult is the outcome of the checks of a target.
+	conformanceResult struct {
+		// states and errs are indexed like conformanceChecks.
+		states  []string
+		errs    []error
+		elapsed time.Duration
+	}
+)
+
+// conformanceChecks are run in order for every plugin.
+var conformanceChecks = []conformanceCheck{ //nolint:gochecknoglobals // fixed check table
+	{name: "list-all", run: conformanceListAll},
+	{name: "latest-stable", run: conformanceLatestStable},
+	{name: "bin-paths", run: conformanceBinPaths},
+	{name: "help", run: conformanceHelp},
+	{name: "legacy-files", run: conformanceLegacyFiles},
+	{name: "download", run: conformanceDownload},
+}
+
+// cmdConformance implements the `conformance` subcommand. It exercises the
+// given tools, or every registered plugin, jobs at a time and prints a
+// pass/fail matrix with the time taken per plugin. Versions are listed from
+// the recordings in goldenDir unless ONLINE=1, and plain binary plugins
+// download from a local placeholder asset. Any failure fails the command.
+func cmdConformance(ctx context.Context, out io.Writer, tools []string, jobs int, goldenDir string) error {
+	targets := make([]conformanceTarget, 0, len(tools))
+
+	if len(tools) == 0 {
+		for _, entry := range plugins.GetPluginRegistry().All() {
+			targets = append(targets, conformanceTarget{plugin: entry.Factory(), name: entry.Names[0]})
+		}
+
+		slices.SortFunc(targets, func(a, b conformanceTarget) int { return strings.Compare(a.name, b.name) })
+	}
+
+	for _, tool := range tools {
+		plugin, err := plugins.GetPlugin(tool)
+		if err != nil {
+			return err
+		}
+
+		targets = append(targets, conformanceTarget{plugin: plugin, name: tool})
+	}
+
+	return conformance(ctx, out, targets, jobs, goldenDir, os.Getenv(conformanceOnlineEnv) == "1")
+}
+
+// conformance runs the checks of targets, jobs at a time, and prints the matrix
+// followed by the failures and a summary.
+func conformance(
+	ctx context.Context,
+	out io.Writer,
+	targets []conformanceTarget,
+	jobs int,
+	goldenDir string,
+	online bool,
+) error {
+	ctx, stop := interruptContext(ctx)
+	defer stop()
+
+	assets := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte("conformance asset\n"))
+	}))
+	defer assets.Close()
+
+	var wg sync.WaitGroup
+
+	slots := make(chan struct{}, max(jobs, 1))
+	results := make([]conformanceResult, len(targets))
+
+	for i, target := range targets {
+		wg.Go(func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			results[i] = runConformance(ctx, &conformanceRun{
+				plugin:    target.plugin,
+				goldenDir: goldenDir,
+				assetURL:  assets.URL,
+				online:    online,
+			})
+		})
+	}
+
+	wg.Wait()
+
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	header := []string{"PLUGIN"}
+	for _, check := range conformanceChecks {
+		header = append(header, strings.ToUpper(check.name))
+	}
+
+	_, _ = fmt.Fprintln(table, strings.Join(append(header, "TIME"), "\t"))
+
+	var failures []string
+
+	for i, result := range results {
+		row := append([]string{targets[i].name}, result.states...)
+		_, _ = fmt.Fprintln(table, strings.Join(append(row, result.elapsed.Round(time.Millisecond).String()), "\t"))
+
+		for j, err := range result.errs {
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s %s: %v", targets[i].name, conformanceChecks[j].name, err))
+			}
+		}
+	}
+
+	_ = table.Flush()
+
+	failed := 0
+
+	for _, result := range results {
+		if slices.Contains(result.states, conformanceStateFailed) {
+			failed++
+		}
+	}
+
+	if len(failures) > 0 {
+		_, _ = fmt.Fprintf(out, "\n%s\n", strings.Join(failures, "\n"))
+	}
+
+	_, _ = fmt.Fprintf(out, "\nPassed: %d, Failed: %d\n", len(targets)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d plugins", errConformanceFailed, failed, len(targets))
+	}
+
+	return nil
+}
+
+// runConformance runs every check against the plugin of run.
+func runConformance(ctx context.Context, run *conformanceRun) conformanceResult {
+	started := time.Now()
+
+	defer func() {
+		for _, closer := range run.closers {
+			closer()
+		}
+	}()
+
+	result := conformanceResult{
+		states: make([]string, len(conformanceChecks)),
+		errs:   make([]error, len(conformanceChecks)),
+	}
+
+	for i, check := range conformanceChecks {
+		result.states[i], result.errs[i] = runConformanceCheck(ctx, check, run)
+	}
+
+	result.elapsed = time.Since(started)
+
+	return result
+}
+
+// runConformanceCheck runs check and returns its state, reporting a panic of
+// the plugin as a failure so that it does not end the whole run.
+func runConformanceCheck(ctx context.Context, check conformanceCheck, run *conformanceRun) (state string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			state, err = conformanceStateFailed, fmt.Errorf("%w: %v", errConformancePanic, recovered)
+		}
+	}()
+
+	err = check.run(ctx, run)
+
+	switch {
+	case err == nil:
+		return conformanceStatePassed, nil
+	case errors.Is(err, errConformanceSkipped):
+		return conformanceStateSkipped, nil
+	default:
+		return conformanceStateFailed, err
+	}
+}
+
+// conformanceListAll lists the versions of the plugin, offline from a GitHub
+// mock publishing the recorded versions.
+func conformanceListAll(ctx context.Context, run *conformanceRun) error {
+	if !run.online {
+		if err := run.serveRecordedVersions(); err != nil {
+			return err
+		}
+	}
+
+	versions, err := run.plugin.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		return fmt.Errorf("%w: no versions", errConformanceEmpty)
+	}
+
+	run.versions = versions
+
+	return nil
+}
+
+// conformanceLatestStable resolves the latest stable version, which must be
+// one of the listed versions.
+func conformanceLatestStable(ctx context.Context, run *conformanceRun) error {
+	if run.versions == nil {
+		return fmt.Errorf("%w: no versions listed", errConformanceSkipped)
+	}
+
+	latest, err := run.plugin.LatestStable(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	if latest == "" {
+		return fmt.Errorf("%w: no version", errConformanceEmpty)
+	}
+
+	if !slices.Contains(run.versions, latest) {
+		return fmt.Errorf("%w: %s", errConformanceUnlisted, latest)
+	}
+
+	run.latest = latest
+
+	return nil
+}
+
+// conformanceBinPaths checks the plugin names at least one bin path.
+func conformanceBinPaths(_ context.Context, run *conformanceRun) error {
+	if strings.TrimSpace(run.plugin.ListBinPaths()) == "" {
+		return fmt.Errorf("%w: no bin paths", errConformanceEmpty)
+	}
+
+	return nil
+}
+
+// conformanceHelp checks the plugin has an overview.
+func conformanceHelp(_ context.Context, run *conformanceRun) error {
+	if strings.TrimSpace(run.plugin.Help().Overview) == "" {
+		return fmt.Errorf("%w: no overview", errConformanceEmpty)
+	}
+
+	return nil
+}
+
+// conformanceLegacyFiles checks the legacy version file names are not empty.
+func conformanceLegacyFiles(_ context.Context, run *conformanceRun) error {
+	if slices.Contains(run.plugin.ListLegacyFilenames(), "") {
+		return fmt.Errorf("%w: empty legacy file name", errConformanceEmpty)
+	}
+
+	return nil
+}
+
+// conformanceDownload downloads the latest stable version of a plain binary
+// plugin from the placeholder asset server into a temporary directory. Other
+// plugins verify or unpack what they download, so they are skipped.
+func conformanceDownload(ctx context.Context, run *conformanceRun) error {
+	binary, ok := run.plugin.(*asdf.BinaryPlugin)
+	if !ok {
+		return fmt.Errorf("%w: custom download", errConformanceSkipped)
+	}
+
+	config := binary.Config
+	if _, ok := config.MappedArch(runtime.GOOS, runtime.GOARCH); !ok || config.OsMap[runtime.GOOS] == "" {
+		return fmt.Errorf("%w: %s/%s not supported", errConformanceSkipped, runtime.GOOS, runtime.GOARCH)
+	}
+
+	config.DownloadURLTemplate = run.assetURL + "/{{.FileName}}"
+
+	downloadPath, err := os.MkdirTemp("", "conformance-"+run.plugin.Name()+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(downloadPath)
+
+	return binary.Download(ctx, cmp.Or(run.latest, conformanceVersion), downloadPath)
+}
+
+// serveRecordedVersions points the GitHub client of the plugin at a mock
+// publishing its recorded versions as both tags and releases.
+func (run *conformanceRun) serveRecordedVersions() error {
+	typed, ok := run.plugin.(interface {
+		WithGithubClient(client *github.Client) *asdf.BinaryPlugin
+	})
+	if !ok {
+		return fmt.Errorf("%w: no offline version source, set %s=1", errConformanceSkipped, conformanceOnlineEnv)
+	}
+
+	golden, err := readConformanceGolden(run.goldenDir, run.plugin.Name())
+	if err != nil {
+		return err
+	}
+
+	if len(golden.Versions) == 0 {
+		return fmt.Errorf("%w: no recorded versions", errConformanceSkipped)
+	}
+
+	server := githubmock.NewServer()
+	run.closers = append(run.closers, server.Close)
+
+	config := typed.WithGithubClient(github.NewClientWithHTTP(http.DefaultClient, server.URL())).Config
+
+	tags := make([]string, 0, len(golden.Versions))
+	for _, version := range golden.Versions {
+		tags = append(tags, config.TagPrefix+config.VersionPrefix+version)
+	}
+
+	server.AddTags(config.RepoOwner, config.RepoName, tags)
+	server.AddReleases(config.RepoOwner, config.RepoName, tags)
+
+	return nil
+}
+
+// readConformanceGolden reads the golden document of name in dir, or the
+// plain-text name_list_all.golden file. It is empty when nothing was recorded.
+func readConformanceGolden(dir, name string) (*conformanceGolden, error) {
+	var golden conformanceGolden
+
+	data, err := os.ReadFile(filepath.Join(dir, name+".golden"))
+	if err == nil {
+		if err := json.Unmarshal(data, &golden); err != nil {
+			return nil, fmt.Errorf("decoding %s golden file: %w", name, err)
+		}
+
+		return &golden, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s golden file: %w", name, err)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, name+"_list_all.golden"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s golden file: %w", name, err)
+	}
+
+	golden.Versions = strings.Fields(string(data))
+
+	return &golden, nil
+}
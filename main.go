@@ -16,22 +16,28 @@
 package main
 
 import (
-	"bufio"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	p "github.com/sumicare/universal-asdf-plugin/plugins"
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/filelock"
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
 	"github.com/urfave/cli/v2"
 )
@@ -53,14 +59,50 @@ var (
 	errAsdfPluginCastFailed = errors.New("failed to cast to AsdfPlugin")
 	// errChecksumMismatch is returned when a recorded checksum does not match.
 	errChecksumMismatch = errors.New("checksum mismatch")
+	// errInstallsModified is returned when installs no longer match their recorded checksums.
+	errInstallsModified = errors.New("installs modified since their checksums were recorded")
 	// errWhichUsage indicates invalid usage of the which command.
-	errWhichUsage = errors.New("usage: asdf which <tool>")
+	errWhichUsage = errors.New("usage: asdf which <tool> [executable]")
+	// errWhereUsage indicates invalid usage of the where command.
+	errWhereUsage = errors.New("usage: where <tool> [version]")
+	// errCompletionsPathUsage indicates invalid usage of the completions-path command.
+	errCompletionsPathUsage = errors.New("usage: completions-path <tool> [version]")
+	// errNoCompletions is returned when an install holds no shell completions.
+	errNoCompletions = errors.New("no shell completions installed")
+	// errMetadataUsage indicates invalid usage of the metadata command.
+	errMetadataUsage = errors.New("usage: metadata <tool> [version]")
+	// errNoInstallMetadata is returned when an install records no install metadata.
+	errNoInstallMetadata = errors.New("no install metadata recorded")
+	// errDiffVersionsUsage indicates invalid usage of the diff-versions command.
+	errDiffVersionsUsage = errors.New("usage: diff-versions <tool> <version1> <version2>")
 	// errNoVersionSet is returned when no version is configured for a tool.
 	errNoVersionSet = errors.New("no version set")
 	// errVersionNotInstalled is returned when a version is not installed.
 	errVersionNotInstalled = errors.New("version is not installed")
 	// errNoExecutableFound is returned when no executable can be located in an install.
 	errNoExecutableFound = errors.New("no executable found")
+	// errDoctorIssues is returned when doctor finds problems in the data dir.
+	errDoctorIssues = errors.New("doctor found problems")
+	// errLocksBreakUsage indicates invalid usage of the locks break command.
+	errLocksBreakUsage = errors.New("usage: locks break <name>")
+	// errHistoryUsage indicates invalid usage of the history command.
+	errHistoryUsage = errors.New("usage: history [tool]")
+	// errChangelogUsage indicates invalid usage of the changelog command.
+	errChangelogUsage = errors.New("usage: changelog <tool> <version>")
+	// errListUsage indicates invalid usage of the list command.
+	errListUsage = errors.New("usage: list [tool]")
+	// errCompletionUsage indicates invalid usage of the completion command.
+	errCompletionUsage = errors.New("usage: completion <bash|zsh|fish>")
+	// errCompletionShellUnsupported is returned for shells without a completion script.
+	errCompletionShellUnsupported = errors.New("unsupported shell")
+	// errExecEnvShellUnsupported is returned for exec-env shells without an output format.
+	errExecEnvShellUnsupported = errors.New("unsupported shell")
+	// errNotLocked is returned when a tool is installed with --locked but is not in the lockfile.
+	errNotLocked = errors.New("not in " + asdf.ToolVersionsLockFileName)
+	// errLockedVersionDrift is returned when a requested version differs from the locked one.
+	errLockedVersionDrift = errors.New("version differs from " + asdf.ToolVersionsLockFileName)
+	// errToolsNotInstalled is returned by pin-installed --strict when pinned tools have no install.
+	errToolsNotInstalled = errors.New("tools without an installed version")
 
 	// version, commit and date are set via ldflags at build time by the release
 	// tooling. These fields are surfaced via the "version" subcommand.
@@ -76,7 +118,7 @@ var (
 func main() {
 	app := newCLIApp()
 
-	args := reorderFlags(os.Args)
+	args := reorderFlags(app, os.Args)
 
 	err := app.Run(args)
 	if err != nil {
@@ -85,64 +127,137 @@ func main() {
 	}
 }
 
-// reorderFlags moves command-level flags to appear before positional arguments.
-// This works around urfave/cli's requirement that flags come before args.
-// Keeps the command name in place to avoid triggering global flags.
-func reorderFlags(args []string) []string {
-	if len(args) < 3 {
+// reorderFlags moves the flags given after a command before its positional
+// arguments, since urfave/cli stops parsing flags at the first positional one,
+// and moves app flags given after the command before the command. Flag
+// definitions tell which flags take a value, so boolean flags never consume
+// the next argument, and everything from a "--" terminator on is kept verbatim.
+func reorderFlags(app *cli.App, args []string) []string {
+	if len(args) < 2 {
 		return args
 	}
 
-	result := make([]string, 0, len(args))
-
-	result = append(result, args[0])
+	appFlags := flagsTakingValue(app.Flags)
+	global := []string{args[0]}
 
-	var cmdIdx int
+	index := 1
+	for ; index < len(args) && isFlagArg(args[index]) && args[index] != "--"; index++ {
+		global = append(global, args[index])
 
-	for i := 1; i < len(args); i++ {
-		if !strings.HasPrefix(args[i], "-") {
-			cmdIdx = i
+		if takesValue(appFlags, args[index]) && index+1 < len(args) {
+			index++
 
-			break
+			global = append(global, args[index])
 		}
 	}
 
-	if cmdIdx == 0 {
+	if index >= len(args) || args[index] == "--" {
 		return args
 	}
 
-	result = append(result, args[1:cmdIdx]...)
+	// Commands added by urfave/cli itself, such as help, have no flags to look up.
+	command := app.Command(args[index])
+	commandPath := []string{args[index]}
+
+	for index++; command != nil && index < len(args); index++ {
+		subcommand := findSubcommand(command, args[index])
+		if subcommand == nil {
+			break
+		}
 
-	if cmdIdx >= len(args) {
-		return result
+		command = subcommand
+		commandPath = append(commandPath, args[index])
 	}
 
-	result = append(result, args[cmdIdx])
-	cmdIdx++
+	var commandFlags map[string]bool
+	if command != nil {
+		commandFlags = flagsTakingValue(command.Flags)
+	}
 
-	var (
-		flags       []string
-		positionals []string
-	)
+	var flags, positionals []string
+
+	for ; index < len(args); index++ {
+		arg := args[index]
+
+		if arg == "--" {
+			positionals = append(positionals, args[index:]...)
+
+			break
+		}
 
-	for i := cmdIdx; i < len(args); i++ {
-		if strings.HasPrefix(args[i], "-") {
-			flags = append(flags, args[i])
-			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") &&
-				!strings.Contains(args[i], "=") {
-				i++
+		if !isFlagArg(arg) {
+			positionals = append(positionals, arg)
+
+			continue
+		}
 
-				flags = append(flags, args[i])
+		target, definitions := &flags, commandFlags
+		if _, ok := commandFlags[flagArgName(arg)]; !ok {
+			if _, ok := appFlags[flagArgName(arg)]; ok {
+				target, definitions = &global, appFlags
 			}
-		} else {
-			positionals = append(positionals, args[i])
+		}
+
+		*target = append(*target, arg)
+
+		if takesValue(definitions, arg) && index+1 < len(args) {
+			index++
+
+			*target = append(*target, args[index])
 		}
 	}
 
+	result := make([]string, 0, len(args))
+	result = append(result, global...)
+	result = append(result, commandPath...)
 	result = append(result, flags...)
-	result = append(result, positionals...)
 
-	return result
+	return append(result, positionals...)
+}
+
+// flagsTakingValue maps every name and alias of flags to whether it takes a value.
+func flagsTakingValue(flags []cli.Flag) map[string]bool {
+	definitions := make(map[string]bool)
+
+	for _, flag := range flags {
+		valueFlag, ok := flag.(cli.DocGenerationFlag)
+
+		for _, name := range flag.Names() {
+			definitions[name] = ok && valueFlag.TakesValue()
+		}
+	}
+
+	return definitions
+}
+
+// takesValue reports whether the flag argument arg consumes the next argument:
+// it must be defined as taking a value and not carry one after "=".
+func takesValue(definitions map[string]bool, arg string) bool {
+	return !strings.Contains(arg, "=") && definitions[flagArgName(arg)]
+}
+
+// isFlagArg reports whether arg is a flag rather than a positional argument
+// or "-", which conventionally names standard input.
+func isFlagArg(arg string) bool {
+	return len(arg) > 1 && strings.HasPrefix(arg, "-")
+}
+
+// flagArgName returns the name of the flag argument arg, without dashes and value.
+func flagArgName(arg string) string {
+	name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+
+	return name
+}
+
+// findSubcommand returns the subcommand of command called name, or nil.
+func findSubcommand(command *cli.Command, name string) *cli.Command {
+	for _, subcommand := range command.Subcommands {
+		if subcommand.HasName(name) {
+			return subcommand
+		}
+	}
+
+	return nil
 }
 
 // newCLIApp builds the urfave/cli application.
@@ -173,10 +288,16 @@ func newCLIApp() *cli.App {
 		EnvVars: []string{"ASDF_INSTALL_PATH"},
 	}
 
+	installTypeFlag := &cli.StringFlag{
+		Name:    "install-type",
+		Usage:   asdf.InstallTypeVersion + ", or " + asdf.InstallTypeRef + " to build the git ref given as version from source",
+		EnvVars: []string{asdf.InstallTypeEnv},
+	}
+
 	queryFlag := &cli.StringFlag{
 		Name:    "query",
 		Aliases: []string{"q"},
-		Usage:   "filter for latest-stable (optional)",
+		Usage:   "filter for latest-stable (optional), prefix with " + asdf.PrereleaseQueryPrefix + " to include prereleases",
 	}
 
 	legacyFileFlag := &cli.StringFlag{
@@ -186,83 +307,56 @@ func newCLIApp() *cli.App {
 	}
 
 	return &cli.App{
-		Name:    "universal-asdf-plugin",
-		Usage:   "universal ASDF plugin implementation in Go",
-		Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
+		Name:        "universal-asdf-plugin",
+		Usage:       "universal ASDF plugin implementation in Go",
+		Description: asdf.DockerfileHelp + "\n\n" + asdf.SharedDataDirHelp,
+		Version:     fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
 		Flags: []cli.Flag{
 			pluginFlag,
+			&cli.BoolFlag{
+				Name:    "verbose",
+				Aliases: []string{"V"},
+				Usage:   "enable debug logging (overrides " + asdf.LogEnv + ")",
+			},
+			&cli.BoolFlag{
+				Name:    "offline",
+				Usage:   "never touch the network: use pre-seeded downloads and recorded version lists",
+				EnvVars: []string{asdf.OfflineEnv},
+			},
+		},
+		Before: func(cliContext *cli.Context) error {
+			// Plugins and child processes read offline mode from the environment.
+			if cliContext.Bool("offline") {
+				if err := os.Setenv(asdf.OfflineEnv, "1"); err != nil {
+					return err
+				}
+			}
+
+			return asdf.ConfigureLogging(os.Stderr, cliContext.Bool("verbose"))
 		},
 		Commands: []*cli.Command{
 			{
 				Name:  "plugins",
 				Usage: "List available plugins",
-				Action: func(_ *cli.Context) error {
-					_, _ = fmt.Fprintln(os.Stdout, "Available plugins:")
-					_, _ = fmt.Fprintln(os.Stdout, "  argo          - Argo Workflows CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  argocd        - ArgoCD CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  argo-rollouts - Argo Rollouts CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  aws-nuke      - AWS resource cleanup")
-					_, _ = fmt.Fprintln(os.Stdout, "  aws-sso-cli   - AWS SSO CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  awscli        - AWS Command Line Interface")
-					_, _ = fmt.Fprintln(os.Stdout, "  buf           - Protocol Buffers tooling")
-					_, _ = fmt.Fprintln(os.Stdout, "  checkov       - IaC security scanner")
-					_, _ = fmt.Fprintln(os.Stdout, "  cmake         - Build system generator")
-					_, _ = fmt.Fprintln(os.Stdout, "  cosign        - Cosign container signing")
-					_, _ = fmt.Fprintln(os.Stdout, "  doctl         - DigitalOcean CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  gcloud        - Google Cloud SDK")
-					_, _ = fmt.Fprintln(os.Stdout, "  jq            - Command-line JSON processor")
-					_, _ = fmt.Fprintln(os.Stdout, "  k9s           - Kubernetes CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  kind          - Kubernetes IN Docker")
-					_, _ = fmt.Fprintln(os.Stdout, "  ko            - Container image builder")
-					_, _ = fmt.Fprintln(os.Stdout, "  kubectl       - Kubernetes CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  lazygit       - Git TUI")
-					_, _ = fmt.Fprintln(os.Stdout, "  linkerd       - Service mesh")
-					_, _ = fmt.Fprintln(os.Stdout, "  nerdctl       - Docker-compatible CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  ginkgo        - BDD testing framework")
-					_, _ = fmt.Fprintln(os.Stdout, "  github-cli    - GitHub CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  gitsign       - Git commit signing")
-					_, _ = fmt.Fprintln(os.Stdout, "  gitleaks      - Detect secrets in code")
-					_, _ = fmt.Fprintln(os.Stdout, "  golang        - Go programming language")
-					_, _ = fmt.Fprintln(os.Stdout, "  goreleaser    - Release automation")
-					_, _ = fmt.Fprintln(os.Stdout, "  golangci-lint - Go linters aggregator")
-					_, _ = fmt.Fprintln(os.Stdout, "  grype         - Vulnerability scanner")
-					_, _ = fmt.Fprintln(os.Stdout, "  helm          - Kubernetes Package Manager")
-					_, _ = fmt.Fprintln(os.Stdout, "  pipx          - Python app installer")
-					_, _ = fmt.Fprintln(os.Stdout, "  python        - Python programming language")
-					_, _ = fmt.Fprintln(os.Stdout, "  rust          - Rust programming language")
-					_, _ = fmt.Fprintln(os.Stdout, "  sccache       - Compiler cache")
-					_, _ = fmt.Fprintln(os.Stdout, "  shellcheck    - Shell script analysis")
-					_, _ = fmt.Fprintln(os.Stdout, "  shfmt         - Shell script formatter")
-					_, _ = fmt.Fprintln(os.Stdout, "  sops          - Secrets management")
-					_, _ = fmt.Fprintln(os.Stdout, "  syft          - SBOM generator")
-					_, _ = fmt.Fprintln(os.Stdout, "  terraform     - Infrastructure as Code")
-					_, _ = fmt.Fprintln(os.Stdout, "  tflint        - Terraform linter")
-					_, _ = fmt.Fprintln(
-						os.Stdout,
-						"  trivy         - Container vulnerability scanner",
-					)
-					_, _ = fmt.Fprintln(os.Stdout, "  terragrunt    - Terraform wrapper")
-					_, _ = fmt.Fprintln(os.Stdout, "  terrascan     - IaC security scanner")
-					_, _ = fmt.Fprintln(os.Stdout, "  tfupdate      - Terraform updater")
-					_, _ = fmt.Fprintln(os.Stdout, "  vultr-cli     - Vultr CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  nodejs        - Node.js runtime")
-					_, _ = fmt.Fprintln(os.Stdout, "  opentofu      - Open source Terraform")
-					_, _ = fmt.Fprintln(os.Stdout, "  protoc        - Protocol Buffers compiler")
-					_, _ = fmt.Fprintln(os.Stdout, "  protoc-gen-go - Go protobuf generator")
-					_, _ = fmt.Fprintln(os.Stdout, "  protoc-gen-go-grpc - gRPC Go protoc plugin")
-					_, _ = fmt.Fprintln(os.Stdout, "  protoc-gen-grpc-web - gRPC-Web protoc plugin")
-					_, _ = fmt.Fprintln(os.Stdout, "  protolint     - Protocol Buffers linter")
-					_, _ = fmt.Fprintln(os.Stdout, "  sqlc          - SQL code generator")
-					_, _ = fmt.Fprintln(os.Stdout, "  tekton-cli    - Tekton CLI")
-					_, _ = fmt.Fprintln(os.Stdout, "  telepresence  - K8s local dev")
-					_, _ = fmt.Fprintln(os.Stdout, "  traefik       - Cloud native proxy")
-					_, _ = fmt.Fprintln(os.Stdout, "  velero        - Kubernetes backup")
-					_, _ = fmt.Fprintln(os.Stdout, "  upx           - Executable packer")
-					_, _ = fmt.Fprintln(os.Stdout, "  uv            - Python package manager")
-					_, _ = fmt.Fprintln(os.Stdout, "  yq            - YAML processor")
-					_, _ = fmt.Fprintln(os.Stdout, "  zig           - Zig programming language")
-
-					return nil
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print the plugin catalog, including the capabilities of each plugin, as JSON",
+					},
+				},
+				Action: func(cliContext *cli.Context) error {
+					if cliContext.Bool("json") {
+						_, err := os.Stdout.Write(plugins.EmbeddedCatalogJSON())
+
+						return err
+					}
+
+					catalog, err := plugins.EmbeddedCatalog()
+					if err != nil {
+						return err
+					}
+
+					return plugins.WriteListing(os.Stdout, catalog)
 				},
 			},
 			{
@@ -273,10 +367,99 @@ func newCLIApp() *cli.App {
 				},
 			},
 			{
-				Name:  "update-tool-versions",
-				Usage: "Update .tool-versions, replacing 'latest' with actual versions",
-				Action: func(_ *cli.Context) error {
-					return cmdUpdateToolVersions()
+				Name:  "lock",
+				Usage: "Write " + asdf.ToolVersionsLockFileName + " with the exact versions, URLs and hashes of .tool-versions",
+				Action: func(cliContext *cli.Context) error {
+					return cmdLock(cliContext.Context)
+				},
+			},
+			{
+				Name:      "prefetch",
+				Usage:     "Download every tool version of .tool-versions without installing it, e.g. to warm CI caches",
+				ArgsUsage: "[file]",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "jobs",
+						Usage: "download this many tool versions at a time",
+						Value: defaultPrefetchJobs,
+					},
+				},
+				Action: func(cliContext *cli.Context) error {
+					toolVersionsPath := ".tool-versions"
+					if cliContext.NArg() > 0 {
+						toolVersionsPath = cliContext.Args().First()
+					}
+
+					return cmdPrefetch(cliContext.Context, os.Stdout, toolVersionsPath, cliContext.Int("jobs"))
+				},
+			},
+			{
+				Name:      "conformance",
+				Usage:     "Exercise registered plugins and print a pass/fail matrix (upstream versions with ONLINE=1)",
+				ArgsUsage: "[tool...]",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "jobs",
+						Usage: "check this many plugins at a time",
+						Value: defaultConformanceJobs,
+					},
+					&cli.StringFlag{
+						Name:  "golden-dir",
+						Usage: "directory of the recorded versions listed when offline",
+						Value: defaultConformanceGoldenDir,
+					},
+				},
+				Action: func(cliContext *cli.Context) error {
+					return cmdConformance(cliContext.Context, os.Stdout, cliContext.Args().Slice(),
+						cliContext.Int("jobs"), cliContext.String("golden-dir"))
+				},
+			},
+			{
+				Name:      "update-tool-versions",
+				Usage:     "Update .tool-versions, replacing 'latest' with actual versions",
+				ArgsUsage: "[file]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "pin-comment",
+						Usage: "mark resolved versions with an 'auto: was latest' comment and keep them updated",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "print the changes without writing the file",
+					},
+				},
+				Action: func(cliContext *cli.Context) error {
+					toolVersionsPath := ".tool-versions"
+					if cliContext.NArg() > 0 {
+						toolVersionsPath = cliContext.Args().First()
+					}
+
+					return cmdUpdateToolVersions(
+						os.Stdout, toolVersionsPath, cliContext.Bool("pin-comment"), cliContext.Bool("dry-run"),
+					)
+				},
+			},
+			{
+				Name:      "pin-installed",
+				Usage:     "Pin the tools in .tool-versions to their newest installed versions",
+				ArgsUsage: "[file]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "also pin tools with installs that are not in the file",
+					},
+					&cli.BoolFlag{
+						Name:  "strict",
+						Usage: "fail when a tool in the file has no installed version",
+					},
+				},
+				Action: func(cliContext *cli.Context) error {
+					toolVersionsPath := ".tool-versions"
+					if cliContext.NArg() > 0 {
+						toolVersionsPath = cliContext.Args().First()
+					}
+
+					return cmdPinInstalled(os.Stdout, toolVersionsPath, cliContext.Bool("all"), cliContext.Bool("strict"))
 				},
 			},
 			{
@@ -286,6 +469,58 @@ func newCLIApp() *cli.App {
 					return cmdGenerateToolSums()
 				},
 			},
+			{
+				Name:  "gc",
+				Usage: "Remove old installed versions, interrupted installs, stale downloads and orphaned shims from the data directory",
+				Description: "Versions pinned in the tool versions files of the current directory and its parents,\n" +
+					"or anywhere below the home directory and the --project directories outside .git,\n" +
+					"node_modules and vendor directories, are never removed.",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "keep",
+						Usage: "keep the N newest installed versions of each tool (default: all)",
+					},
+					&cli.DurationFlag{
+						Name:  "downloads-older-than",
+						Usage: "remove downloads last modified longer ago than this duration, e.g. 720h",
+					},
+					&cli.BoolFlag{
+						Name:  "prune-shims",
+						Usage: "remove shims whose executable no longer exists",
+					},
+					&cli.StringSliceFlag{
+						Name:  "project",
+						Usage: "also keep the versions pinned in the tool versions files below this directory",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "list what would be removed without removing it",
+					},
+				},
+				Action: func(cliContext *cli.Context) error {
+					options := asdf.GCOptions{
+						Keep:               cliContext.Int("keep"),
+						DownloadsOlderThan: cliContext.Duration("downloads-older-than"),
+						PruneShims:         cliContext.Bool("prune-shims"),
+					}
+
+					return cmdGC(cliContext.Context, os.Stdout, options, cliContext.StringSlice("project"), cliContext.Bool("dry-run"))
+				},
+			},
+			{
+				Name:  "verify-installs",
+				Usage: "Compare installed tool versions against the checksums in .tool-sums",
+				Action: func(_ *cli.Context) error {
+					return cmdVerifyInstalls(os.Stdout)
+				},
+			},
+			{
+				Name:  "sbom",
+				Usage: "Print a CycloneDX " + asdf.SBOMSpecVersion + " SBOM of every installed tool version",
+				Action: func(cliContext *cli.Context) error {
+					return cmdSBOM(cliContext.Context, os.Stdout)
+				},
+			},
 			{
 				Name:  "reshim",
 				Usage: "Regenerate shims for all installed tool versions",
@@ -294,9 +529,143 @@ func newCLIApp() *cli.App {
 				},
 			},
 			{
-				Name:  "which",
-				Usage: "Display the path to an executable",
-				Flags: []cli.Flag{pluginFlag, versionFlag},
+				Name:        "doctor",
+				Usage:       "Check that the data directory can be used by every user sharing it",
+				Description: asdf.SharedDataDirHelp,
+				Action: func(_ *cli.Context) error {
+					return cmdDoctor()
+				},
+			},
+			{
+				Name:  "locks",
+				Usage: "List or break the locks held on the data directory",
+				Description: "Locks serialize installs and tool sums updates. A lock left behind by a\n" +
+					"crashed process, e.g. on NFS, can be broken unless its holder still runs on this host.\n" +
+					"Set " + asdf.LockTimeoutEnv + " to change how long to wait for a lock (default 10m).",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "List the lock files and their holders",
+						Action: func(_ *cli.Context) error {
+							return cmdLocksList()
+						},
+					},
+					{
+						Name:      "break",
+						Usage:     "Remove a stale lock",
+						ArgsUsage: "<name>",
+						Action: func(cliContext *cli.Context) error {
+							if cliContext.NArg() != 1 {
+								return errLocksBreakUsage
+							}
+
+							return cmdLocksBreak(cliContext.Args().First())
+						},
+					},
+				},
+			},
+			{
+				Name:      "history",
+				Usage:     "Show installs, uninstalls and version updates, newest first",
+				ArgsUsage: "[tool]",
+				Description: "History is recorded in " + asdf.HistoryFileName + " below the data directory.\n" +
+					"Set " + asdf.HistoryDisableEnv + "=1 to stop recording it.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "operation",
+						Usage: "only show one operation (install, uninstall or update-tool-versions)",
+					},
+					&cli.BoolFlag{
+						Name:  "failed",
+						Usage: "only show failed operations",
+					},
+					&cli.DurationFlag{
+						Name:  "since",
+						Usage: "only show operations newer than this duration, e.g. 24h",
+					},
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "show at most this many entries",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print the history as JSON",
+					},
+				},
+				Action: func(cliContext *cli.Context) error {
+					if cliContext.NArg() > 1 {
+						return errHistoryUsage
+					}
+
+					filter := asdf.HistoryFilter{
+						Tool:       cliContext.Args().First(),
+						Operation:  cliContext.String("operation"),
+						FailedOnly: cliContext.Bool("failed"),
+						Limit:      cliContext.Int("limit"),
+					}
+
+					if since := cliContext.Duration("since"); since > 0 {
+						filter.Since = time.Now().Add(-since)
+					}
+
+					return cmdHistory(filter, cliContext.Bool("json"))
+				},
+			},
+			{
+				Name:      "where",
+				Usage:     "Display the install path of a tool version",
+				ArgsUsage: "<tool> [version]",
+				Description: "The version defaults to the one selected by .tool-versions. Plugins that nest\n" +
+					"their root below the install path, such as gcloud, print that root instead.",
+				Action: func(cliContext *cli.Context) error {
+					if cliContext.NArg() < 1 || cliContext.NArg() > 2 {
+						return errWhereUsage
+					}
+
+					args := cliContext.Args()
+
+					return cmdWhere(cliContext.Context, os.Stdout, args.Get(0), args.Get(1))
+				},
+			},
+			{
+				Name:      "completions-path",
+				Usage:     "Display the shell completions directory of a tool version",
+				ArgsUsage: "<tool> [version]",
+				Description: "The version defaults to the one selected by .tool-versions. Source the file of\n" +
+					"your shell from the directory in your shell profile.",
+				Action: func(cliContext *cli.Context) error {
+					if cliContext.NArg() < 1 || cliContext.NArg() > 2 {
+						return errCompletionsPathUsage
+					}
+
+					args := cliContext.Args()
+
+					return cmdCompletionsPath(cliContext.Context, os.Stdout, args.Get(0), args.Get(1))
+				},
+			},
+			{
+				Name:      "metadata",
+				Usage:     "Display the install metadata of a tool version",
+				ArgsUsage: "<tool> [version]",
+				Description: "Prints the " + asdf.InstallMetadataFileName + " recorded at install time: the\n" +
+					"requested and resolved version, the downloaded artifacts with their URL, SHA256 and\n" +
+					"download time, and the version and commit of the binary that installed them.\n" +
+					"The version defaults to the one selected by .tool-versions.",
+				Action: func(cliContext *cli.Context) error {
+					if cliContext.NArg() < 1 || cliContext.NArg() > 2 {
+						return errMetadataUsage
+					}
+
+					args := cliContext.Args()
+
+					return cmdMetadata(cliContext.Context, os.Stdout, args.Get(0), args.Get(1))
+				},
+			},
+			{
+				Name:      "which",
+				Usage:     "Display the path to an executable",
+				ArgsUsage: "<tool> [executable]",
+				Flags:     []cli.Flag{pluginFlag, versionFlag},
 				Action: func(cliContext *cli.Context) error {
 					toolName := cliContext.Args().First()
 					if toolName == "" {
@@ -311,7 +680,138 @@ func newCLIApp() *cli.App {
 						return errWhichUsage
 					}
 
-					return cmdWhich(toolName)
+					return cmdWhich(toolName, cliContext.Args().Get(1))
+				},
+			},
+			{
+				Name:      "diff-versions",
+				Usage:     "Compare the files of two installed versions of a tool",
+				ArgsUsage: "<tool> <version1> <version2>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print the difference as JSON",
+					},
+					&cli.BoolFlag{
+						Name:  "bin-only",
+						Usage: "only compare files below the plugin bin paths",
+					},
+				},
+				Action: func(cliContext *cli.Context) error {
+					if cliContext.NArg() != 3 {
+						return errDiffVersionsUsage
+					}
+
+					args := cliContext.Args()
+
+					return cmdDiffVersions(
+						args.Get(0),
+						args.Get(1),
+						args.Get(2),
+						cliContext.Bool("json"),
+						cliContext.Bool("bin-only"),
+					)
+				},
+			},
+			{
+				Name:      "tool-versions-diff",
+				Usage:     "Compare the pins of two .tool-versions files, or of one with the installed versions",
+				ArgsUsage: "<old-file> <new-file> | --against-installed [file]",
+				Description: "Exits with status 1 when the pins differ. --against-installed compares each\n" +
+					"pinned version with the newest installed version of the tool.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "against-installed",
+						Usage: "compare the file with the newest installed version of each tool",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print the difference as JSON",
+					},
+				},
+				Action: func(cliContext *cli.Context) error {
+					args := cliContext.Args()
+
+					if cliContext.Bool("against-installed") {
+						if cliContext.NArg() > 1 {
+							return errToolVersionsDiffUsage
+						}
+
+						path := cmp.Or(args.First(), asdf.ToolVersionsFilename())
+
+						return cmdToolVersionsDiffInstalled(os.Stdout, path, cliContext.Bool("json"))
+					}
+
+					if cliContext.NArg() != 2 {
+						return errToolVersionsDiffUsage
+					}
+
+					return cmdToolVersionsDiff(os.Stdout, args.Get(0), args.Get(1), cliContext.Bool("json"))
+				},
+			},
+			{
+				Name:      "changelog",
+				Usage:     "Print the release notes of a tool version",
+				ArgsUsage: "<tool> <version>",
+				Action: func(cliContext *cli.Context) error {
+					if cliContext.NArg() != 2 {
+						return errChangelogUsage
+					}
+
+					args := cliContext.Args()
+
+					return cmdChangelog(cliContext.Context, args.Get(0), args.Get(1))
+				},
+			},
+			{
+				Name:      "resolve",
+				Usage:     "Print the resolved version and the downloads of a tool version without downloading them",
+				ArgsUsage: "<tool> [version]",
+				Action: func(cliContext *cli.Context) error {
+					if cliContext.NArg() < 1 || cliContext.NArg() > 2 {
+						return errResolveUsage
+					}
+
+					plugin, err := plugins.GetPlugin(cliContext.Args().First())
+					if err != nil {
+						return err
+					}
+
+					return cmdResolve(cliContext.Context, os.Stdout, plugin, cliContext.Args().Get(1))
+				},
+			},
+			{
+				Name:      "completion",
+				Usage:     "Print a shell completion script",
+				ArgsUsage: "<bash|zsh|fish>",
+				Description: "Load it with e.g. 'source <(universal-asdf-plugin completion bash)'.\n" +
+					"Plugin names and installed versions are looked up when completing.",
+				Action: func(cliContext *cli.Context) error {
+					if cliContext.NArg() != 1 {
+						return errCompletionUsage
+					}
+
+					return cmdCompletion(os.Stdout, cliContext.App.Name, cliContext.Args().First())
+				},
+			},
+			{
+				Name:            completeCommandName,
+				Hidden:          true,
+				SkipFlagParsing: true,
+				Action: func(cliContext *cli.Context) error {
+					return cmdComplete(os.Stdout, cliContext.App, cliContext.Args().Slice())
+				},
+			},
+			{
+				Name:      "list",
+				Usage:     "List installed versions, marking the current one with an asterisk",
+				ArgsUsage: "[tool]",
+				Action: func(cliContext *cli.Context) error {
+					if cliContext.NArg() > 1 {
+						return errListUsage
+					}
+
+					return cmdList(cliContext.Args().First())
 				},
 			},
 			{
@@ -330,20 +830,27 @@ func newCLIApp() *cli.App {
 			{
 				Name:  "download",
 				Usage: "Download a specific version (verifies/records checksums)",
-				Flags: []cli.Flag{pluginFlag, versionFlag, downloadPathFlag},
+				Flags: []cli.Flag{pluginFlag, versionFlag, downloadPathFlag, installTypeFlag},
 				Action: func(cliContext *cli.Context) error {
 					plugin, args, err := resolvePluginFromContext(cliContext)
 					if err != nil {
 						return err
 					}
 
-					installVersion := cliContext.String("version")
-					if installVersion == "" && len(args) > 0 {
-						installVersion = args[0]
+					installType, installVersion, downloadPath, err := installTarget(cliContext, args, "download-path")
+					if err != nil {
+						return err
+					}
+
+					if installType == asdf.InstallTypeRef {
+						// The sources of a ref are fetched when installing it.
+						_, err := asdf.RefInstallPlugin(plugin)
+
+						return err
 					}
 
 					if installVersion == "" {
-						latestVersion, err := plugin.LatestStable(cliContext.Context, "")
+						latestVersion, err := asdf.LatestStableVersion(cliContext.Context, plugin, "")
 						if err != nil {
 							return fmt.Errorf("resolving latest version: %w", err)
 						}
@@ -351,14 +858,11 @@ func newCLIApp() *cli.App {
 						installVersion = latestVersion
 					}
 
-					downloadPath := cliContext.String("download-path")
 					if downloadPath == "" {
-						downloadPath = filepath.Join(
-							getAsdfDataDir(),
-							"downloads",
-							plugin.Name(),
-							installVersion,
-						)
+						downloadPath, err = defaultDownloadPath(plugin.Name(), installVersion)
+						if err != nil {
+							return err
+						}
 					}
 
 					return cmdDownload(cliContext.Context, plugin, installVersion, downloadPath)
@@ -367,53 +871,115 @@ func newCLIApp() *cli.App {
 			{
 				Name:  "install",
 				Usage: "Install a specific version",
-				Flags: []cli.Flag{pluginFlag, versionFlag, downloadPathFlag, installPathFlag},
+				Flags: []cli.Flag{
+					pluginFlag, versionFlag, downloadPathFlag, installPathFlag, installTypeFlag,
+					&cli.BoolFlag{
+						Name:  "locked",
+						Usage: "install the versions of " + asdf.ToolVersionsLockFileName + ", all of them without a plugin, and verify their hashes",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "empty the install path before installing",
+					},
+					&cli.BoolFlag{
+						Name:  "force-download",
+						Usage: "empty the download path too, fetching the release again",
+					},
+				},
 				Action: func(cliContext *cli.Context) error {
+					if cliContext.Bool("locked") {
+						var plugin asdf.Plugin
+
+						if cliContext.String("plugin") != "" || cliContext.Args().Present() {
+							resolved, _, err := resolvePluginFromContext(cliContext)
+							if err != nil {
+								return err
+							}
+
+							plugin = resolved
+						}
+
+						return cmdInstallLocked(cliContext.Context, plugin, cliContext.String("version"))
+					}
+
 					plugin, args, err := resolvePluginFromContext(cliContext)
 					if err != nil {
 						return err
 					}
 
-					installVersion := cliContext.String("version")
-					if installVersion == "" && len(args) > 0 {
-						installVersion = args[0]
+					installType, installVersion, installPath, err := installTarget(cliContext, args, "install-path")
+					if err != nil {
+						return err
 					}
 
-					if installVersion == "" {
-						latestVersion, err := plugin.LatestStable(cliContext.Context, "")
+					// requestedVersion is the version as given, recorded in the install metadata.
+					requestedVersion := installVersion
+					if requestedVersion == "" {
+						requestedVersion = "latest"
+					}
+
+					// versionDir names the version in the default paths.
+					var versionDir string
+
+					if installType == asdf.InstallTypeRef {
+						if installVersion == "" {
+							return errASDFInstallVersionNotSet
+						}
+
+						plugin, err = asdf.RefInstallPlugin(plugin)
 						if err != nil {
-							return fmt.Errorf("resolving latest version: %w", err)
+							return err
 						}
 
-						installVersion = latestVersion
+						versionDir = asdf.RefVersionDir(installVersion)
+					} else {
+						if installVersion == "" {
+							latestVersion, err := asdf.LatestStableVersion(cliContext.Context, plugin, "")
+							if err != nil {
+								return fmt.Errorf("resolving latest version: %w", err)
+							}
+
+							installVersion = latestVersion
+						}
+
+						cwd, err := os.Getwd()
+						if err != nil {
+							return err
+						}
+
+						installVersion, err = asdf.ResolveEffectiveVersion(plugin, cwd, installVersion)
+						if err != nil {
+							return err
+						}
+
+						versionDir = installVersion
 					}
 
-					installPath := cliContext.String("install-path")
 					if installPath == "" {
-						installPath = filepath.Join(
-							getAsdfDataDir(),
-							"installs",
-							plugin.Name(),
-							installVersion,
-						)
+						installPath, err = defaultInstallPath(plugin.Name(), versionDir)
+						if err != nil {
+							return err
+						}
 					}
 
 					downloadPath := cliContext.String("download-path")
 					if downloadPath == "" {
-						downloadPath = filepath.Join(
-							getAsdfDataDir(),
-							"downloads",
-							plugin.Name(),
-							installVersion,
-						)
+						downloadPath, err = defaultDownloadPath(plugin.Name(), versionDir)
+						if err != nil {
+							return err
+						}
 					}
 
+					ctx := asdf.WithInstallRequest(cliContext.Context, asdf.InstallRequest{RequestedVersion: requestedVersion})
+
 					return cmdInstall(
-						cliContext.Context,
+						ctx,
 						plugin,
 						installVersion,
 						downloadPath,
 						installPath,
+						cliContext.Bool("force"),
+						cliContext.Bool("force-download"),
 					)
 				},
 			},
@@ -431,12 +997,10 @@ func newCLIApp() *cli.App {
 					if installPath == "" && len(args) > 0 {
 						uninstallVersion := args[0]
 
-						installPath = filepath.Join(
-							getAsdfDataDir(),
-							"installs",
-							plugin.Name(),
-							uninstallVersion,
-						)
+						installPath, err = defaultInstallPath(plugin.Name(), uninstallVersion)
+						if err != nil {
+							return err
+						}
 					}
 
 					if installPath == "" {
@@ -462,14 +1026,38 @@ func newCLIApp() *cli.App {
 			{
 				Name:  "exec-env",
 				Usage: "Print environment variables for execution",
-				Flags: []cli.Flag{pluginFlag, installPathFlag},
+				Flags: []cli.Flag{
+					pluginFlag, installPathFlag,
+					&cli.StringFlag{
+						Name:  "shell",
+						Value: "bash",
+						Usage: "output format: bash, zsh, fish, pwsh or env (KEY=value lines)",
+					},
+				},
 				Action: func(cliContext *cli.Context) error {
 					plugin, _, err := resolvePluginFromContext(cliContext)
 					if err != nil {
 						return err
 					}
 
-					return cmdExecEnv(plugin, cliContext.String("install-path"))
+					return cmdExecEnv(os.Stdout, plugin, cliContext.String("install-path"), cliContext.String("shell"))
+				},
+			},
+			{
+				Name:      "env",
+				Usage:     "Print shell code activating the selected tool versions, e.g. for direnv, instead of shims",
+				ArgsUsage: "[tool...]",
+				Description: "Defaults to the tools of the nearest .tool-versions. Load it with e.g.\n" +
+					"'eval \"$(universal-asdf-plugin env)\"' or from an .envrc.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "shell",
+						Value: "bash",
+						Usage: "output format: bash, zsh, fish or pwsh",
+					},
+				},
+				Action: func(cliContext *cli.Context) error {
+					return cmdEnv(cliContext.Context, os.Stdout, cliContext.Args().Slice(), cliContext.String("shell"))
 				},
 			},
 			{
@@ -581,21 +1169,70 @@ func newCLIApp() *cli.App {
 	}
 }
 
-// getAsdfDataDir returns the ASDF data directory, defaulting to ~/.asdf if not set.
-func getAsdfDataDir() string {
-	if dir := os.Getenv("ASDF_DATA_DIR"); dir != "" {
-		return dir
-	}
-
-	home, err := os.UserHomeDir()
+// defaultDownloadPath returns the download path for a tool version from the data layout.
+func defaultDownloadPath(toolName, toolVersion string) (string, error) {
+	layout, err := asdf.CurrentLayout()
 	if err != nil {
-		return filepath.Join(os.TempDir(), ".asdf")
+		return "", err
 	}
 
-	return filepath.Join(home, ".asdf")
+	return layout.DownloadPath(toolName, toolVersion), nil
 }
 
-// resolvePluginFromContext resolves plugin from flag, first arg, or executable name.
+// defaultInstallPath returns the install path for a tool version from the data layout.
+func defaultInstallPath(toolName, toolVersion string) (string, error) {
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return "", err
+	}
+
+	return layout.InstallPath(toolName, toolVersion), nil
+}
+
+// installTarget returns the install type, version and path of the download
+// and install commands from their flags and the variables asdf sets, falling
+// back to the positional "<type> <version> <path>" arguments some asdf
+// versions pass to bin/download and bin/install, or to a lone "<version>".
+// A "ref:<ref>" version selects the ref install type.
+func installTarget(cliContext *cli.Context, args []string, pathFlag string) (string, string, string, error) {
+	installType := cliContext.String("install-type")
+	installVersion := cliContext.String("version")
+	path := cliContext.String(pathFlag)
+
+	positionalVersion := ""
+
+	switch {
+	case len(args) >= 2 && (args[0] == asdf.InstallTypeVersion || args[0] == asdf.InstallTypeRef):
+		if installType == "" {
+			installType = args[0]
+		}
+
+		positionalVersion = args[1]
+
+		if path == "" && len(args) > 2 {
+			path = args[2]
+		}
+	case len(args) > 0:
+		positionalVersion = args[0]
+	}
+
+	if installVersion == "" {
+		installVersion = positionalVersion
+	}
+
+	if ref, ok := strings.CutPrefix(installVersion, asdf.RefVersionPrefix); ok {
+		installType, installVersion = asdf.InstallTypeRef, ref
+	}
+
+	installType, err := asdf.ParseInstallType(installType)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return installType, installVersion, path, nil
+}
+
+// resolvePluginFromContext resolves plugin from flag, first arg, or executable name.
 func resolvePluginFromContext(cliContext *cli.Context) (asdf.Plugin, []string, error) {
 	pluginName := strings.TrimSpace(cliContext.String("plugin"))
 
@@ -634,7 +1271,7 @@ func resolvePluginFromContext(cliContext *cli.Context) (asdf.Plugin, []string, e
 
 // cmdListAll implements the `list-all` subcommand for a plugins.
 func cmdListAll(ctx context.Context, plugin asdf.Plugin) error {
-	versions, err := plugin.ListAll(ctx)
+	versions, err := asdf.ListAllVersions(ctx, plugin)
 	if err != nil {
 		return fmt.Errorf("listing versions: %w", err)
 	}
@@ -646,6 +1283,8 @@ func cmdListAll(ctx context.Context, plugin asdf.Plugin) error {
 
 // cmdDownload implements the `download` subcommand for a plugins.
 // It downloads the requested version into the provided downloadPath and manages checksums.
+// In offline mode the download must already be there and is only verified.
+// A failing post_download_<tool> hook is logged without failing the download.
 func cmdDownload(
 	ctx context.Context,
 	plugin asdf.Plugin,
@@ -664,8 +1303,27 @@ func cmdDownload(
 		return fmt.Errorf("creating download directory: %w", err)
 	}
 
-	err = plugin.Download(ctx, installVersion, downloadPath)
+	ctx, stop := interruptContext(ctx)
+	defer stop()
+
+	if asdf.Offline() {
+		err = asdf.CheckOfflineDownload(plugin, installVersion, downloadPath)
+	} else {
+		if err := asdf.CheckDiskSpace(ctx, plugin, installVersion, downloadPath, ""); err != nil {
+			return err
+		}
+
+		ctx = asdf.WithProgressReporter(
+			ctx,
+			asdf.NewProgressReporter(fmt.Sprintf("Downloading %s %s", plugin.Name(), installVersion)),
+		)
+
+		err = plugin.Download(ctx, installVersion, downloadPath)
+	}
+
 	if err != nil {
+		removeIncomplete(ctx, downloadPath, err)
+
 		return err
 	}
 
@@ -676,19 +1334,38 @@ func cmdDownload(
 
 	err = recordToolSum(plugin.Name(), installVersion, downloadPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to record checksum: %v\n", err)
+		asdf.Logger().Warn("failed to record checksum", "tool", plugin.Name(), "version", installVersion, "error", err)
+	}
+
+	config, err := asdf.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	err = config.RunHook(ctx, asdf.HookPostDownload, plugin.Name(), installVersion, downloadPath, "")
+	if err != nil {
+		asdf.Logger().Warn("post-download hook failed", "tool", plugin.Name(), "version", installVersion, "error", err)
 	}
 
 	return nil
 }
 
 // cmdInstall implements the `install` subcommand for a plugins.
-// It installs the requested version into installPath.
+// It installs the requested version into installPath, running the configured
+// pre_install_<tool> hook first, which aborts the install on failure, and the
+// post_install_<tool> hook afterwards, whose failure is only logged.
+// Plugins install into a staging directory renamed into place once
+// complete, replacing any previous install, so that readers never see a
+// partial install; those implementing asdf.InPlaceInstaller install in place
+// instead, after the install path is emptied with force or forceDownload.
+// forceDownload empties the download path as well, and the leftovers of an
+// interrupted staged install are removed first.
 func cmdInstall(
 	ctx context.Context,
 	plugin asdf.Plugin,
 	installVersion, downloadPath, installPath string,
-) error {
+	force, forceDownload bool,
+) (err error) {
 	if installVersion == "" {
 		return errASDFInstallVersionNotSet
 	}
@@ -697,25 +1374,155 @@ func cmdInstall(
 		return errASDFInstallPathNotSet
 	}
 
+	started := time.Now()
+
+	ctx, stop := interruptContext(ctx)
+	defer stop()
+
+	defer func() {
+		asdf.RecordHistory(asdf.HistoryOperationInstall, plugin.Name(), installVersion, started, err)
+	}()
+
+	release, err := asdf.EnterInstall(plugin.Name())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	installLock, err := asdf.AcquireLock(
+		ctx,
+		asdf.InstallLockName(plugin.Name(), installVersion),
+		fmt.Sprintf("install %s %s", plugin.Name(), installVersion),
+		false,
+	)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if unlockErr := installLock.Release(); unlockErr != nil {
+			asdf.Logger().Warn("failed to release install lock", "error", unlockErr)
+		}
+	}()
+
 	actualDownloadPath := downloadPath
 	if actualDownloadPath == "" {
-		actualDownloadPath = filepath.Join(
-			os.TempDir(),
-			fmt.Sprintf("asdf-%s-%s", plugin.Name(), installVersion),
-		)
+		actualDownloadPath, err = defaultDownloadPath(plugin.Name(), installVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	if forceDownload {
+		if err := asdf.ResetDir(actualDownloadPath); err != nil {
+			return fmt.Errorf("clearing download directory: %w", err)
+		}
+	}
+
+	if err := asdf.RemoveInstallLeftovers(installPath); err != nil {
+		asdf.Logger().Warn("failed to remove interrupted install leftovers", "error", err)
+	}
+
+	inPlace := asdf.InstallsInPlace(plugin)
+
+	if (force || forceDownload) && inPlace {
+		if err := asdf.ResetDir(installPath); err != nil {
+			return fmt.Errorf("clearing install directory: %w", err)
+		}
 	}
 
-	err := os.MkdirAll(actualDownloadPath, asdf.CommonDirectoryPermission)
+	err = os.MkdirAll(actualDownloadPath, asdf.CommonDirectoryPermission)
 	if err != nil {
 		return fmt.Errorf("creating download directory: %w", err)
 	}
 
-	err = os.MkdirAll(installPath, asdf.CommonDirectoryPermission)
+	if asdf.Offline() {
+		if err := asdf.CheckOfflineDownload(plugin, installVersion, actualDownloadPath); err != nil {
+			return err
+		}
+	} else if err := asdf.CheckDiskSpace(ctx, plugin, installVersion, actualDownloadPath, installPath); err != nil {
+		return err
+	}
+
+	targetPath := installPath
+
+	if inPlace {
+		err = os.MkdirAll(installPath, asdf.CommonDirectoryPermission)
+		if err != nil {
+			return fmt.Errorf("creating install directory: %w", err)
+		}
+	} else {
+		targetPath, err = asdf.StageInstall(installPath)
+		if err != nil {
+			return err
+		}
+
+		defer os.RemoveAll(targetPath)
+	}
+
+	config, err := asdf.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	err = config.RunHook(ctx, asdf.HookPreInstall, plugin.Name(), installVersion, actualDownloadPath, installPath)
+	if err != nil {
+		return err
+	}
+
+	ctx = asdf.WithProgressReporter(
+		ctx,
+		asdf.NewProgressReporter(fmt.Sprintf("Installing %s %s", plugin.Name(), installVersion)),
+	)
+
+	request := asdf.InstallRequestFromContext(ctx)
+	request.InstallerVersion, request.InstallerCommit = version, commit
+	ctx = asdf.WithInstallRequest(ctx, request)
+
+	if err := plugin.Install(ctx, installVersion, actualDownloadPath, targetPath); err != nil {
+		removeIncomplete(ctx, targetPath, err)
+
+		return err
+	}
+
+	if err := asdf.NormalizeInstall(targetPath, started); err != nil {
+		return err
+	}
+
+	if err := asdf.ShareTree(targetPath); err != nil {
+		return err
+	}
+
+	if !inPlace {
+		if err := asdf.CommitInstall(targetPath, installPath); err != nil {
+			return err
+		}
+	}
+
+	err = config.RunHook(ctx, asdf.HookPostInstall, plugin.Name(), installVersion, actualDownloadPath, installPath)
 	if err != nil {
-		return fmt.Errorf("creating install directory: %w", err)
+		asdf.Logger().Warn("post-install hook failed", "tool", plugin.Name(), "version", installVersion, "error", err)
+	}
+
+	return nil
+}
+
+// interruptContext returns ctx cancelled on SIGINT or SIGTERM, so that an
+// interrupted download or install stops its network and build operations.
+func interruptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+}
+
+// removeIncomplete removes path, left incomplete by a download or install
+// failing with err, when ctx was cancelled or the filesystem filled up.
+func removeIncomplete(ctx context.Context, path string, err error) {
+	if ctx.Err() == nil && !asdf.IsNoSpace(err) {
+		return
 	}
 
-	return plugin.Install(ctx, installVersion, actualDownloadPath, installPath)
+	if err := os.RemoveAll(path); err != nil {
+		asdf.Logger().Warn("failed to remove incomplete operation leftovers", "path", path, "error", err)
+	}
 }
 
 // cmdListBinPaths implements the `list-bin-paths` subcommand.
@@ -727,20 +1534,54 @@ func cmdListBinPaths(plugin asdf.Plugin) error {
 }
 
 // cmdExecEnv implements the `exec-env` subcommand.
-// It prints shell export statements for the plugin's execution environment.
-func cmdExecEnv(plugin asdf.Plugin, installPath string) error {
+// It prints the plugin's execution environment sorted by name, as assignments
+// for shell: bash, zsh, fish, pwsh, or env for plain KEY=value lines.
+func cmdExecEnv(out io.Writer, plugin asdf.Plugin, installPath, shell string) error {
+	format, ok := execEnvFormats[shell]
+	if !ok {
+		return fmt.Errorf("%w: %s (use bash, zsh, fish, pwsh or env)", errExecEnvShellUnsupported, shell)
+	}
+
 	if installPath == "" {
 		return nil
 	}
 
-	env := plugin.ExecEnv(installPath)
-	for key, value := range env {
-		_, _ = fmt.Fprintf(os.Stdout, "export %s=%q\n", key, value)
+	env := asdf.ComposeExecEnv(plugin.ExecEnv(installPath), os.Getenv)
+	for _, key := range slices.Sorted(maps.Keys(env)) {
+		_, _ = fmt.Fprintln(out, format(key, env[key]))
 	}
 
 	return nil
 }
 
+// execEnvFormats render one exec-env variable assignment per shell, quoting
+// the value so that quotes, newlines and dollar signs are taken literally.
+var execEnvFormats = map[string]func(key, value string) string{ //nolint:gochecknoglobals // lookup table
+	"bash": posixExport,
+	"zsh":  posixExport,
+	"fish": func(key, value string) string {
+		return "set -gx " + key + " '" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+	},
+	"pwsh": func(key, value string) string {
+		return "$env:" + key + " = '" + strings.ReplaceAll(value, "'", "''") + "'"
+	},
+	"env": func(key, value string) string {
+		if value != "" && !strings.ContainsAny(value, " \t\n\r\"'\\$#`") {
+			return key + "=" + value
+		}
+
+		return key + `="` + strings.NewReplacer(
+			`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`,
+		).Replace(value) + `"`
+	},
+}
+
+// posixExport renders a bash or zsh export with the value in single quotes,
+// inside which only the single quote itself needs escaping.
+func posixExport(key, value string) string {
+	return "export " + key + "='" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
 // cmdUninstall implements the `uninstall` subcommand.
 // It removes the plugin installation at ASDF_INSTALL_PATH.
 func cmdUninstall(ctx context.Context, plugin asdf.Plugin, installPath string) error {
@@ -748,13 +1589,18 @@ func cmdUninstall(ctx context.Context, plugin asdf.Plugin, installPath string) e
 		return errASDFInstallPathNotSet
 	}
 
-	return plugin.Uninstall(ctx, installPath)
+	started := time.Now()
+	err := plugin.Uninstall(ctx, installPath)
+
+	asdf.RecordHistory(asdf.HistoryOperationUninstall, plugin.Name(), filepath.Base(installPath), started, err)
+
+	return err
 }
 
 // cmdLatestStable implements the `latest-stable` subcommand.
 // It prints the latest stable version matching an optional query.
 func cmdLatestStable(ctx context.Context, plugin asdf.Plugin, query string) error {
-	latestVersion, err := plugin.LatestStable(ctx, query)
+	latestVersion, err := asdf.LatestStableVersion(ctx, plugin, query)
 	if err != nil {
 		return err
 	}
@@ -774,13 +1620,18 @@ func cmdListLegacyFilenames(plugin asdf.Plugin) error {
 }
 
 // cmdParseLegacyFile implements the `parse-legacy-file` subcommand.
-// It reads a legacy version file and prints the parsed version.
+// It reads a legacy version file and prints the parsed version, or nothing
+// when the file pins none.
 func cmdParseLegacyFile(plugin asdf.Plugin, filePath string) error {
 	if filePath == "" {
 		return errLegacyFilePathRequired
 	}
 
 	parsedVersion, err := plugin.ParseLegacyFile(filePath)
+	if errors.Is(err, asdf.ErrNoLegacyPin) {
+		return nil
+	}
+
 	if err != nil {
 		return err
 	}
@@ -795,6 +1646,26 @@ func cmdHelpOverview(plugin asdf.Plugin) error {
 	help := plugin.Help()
 	_, _ = fmt.Fprintln(os.Stdout, help.Overview)
 
+	_, _ = fmt.Fprintln(os.Stdout, "\nCapabilities: "+asdf.JoinCapabilities(asdf.Capabilities(plugin)))
+
+	if len(help.Platforms) > 0 {
+		current, support := runtime.GOOS+"/"+runtime.GOARCH, "supported"
+		if arch, err := asdf.GetArch(); err == nil {
+			current = runtime.GOOS + "/" + arch
+		}
+
+		if !slices.Contains(help.Platforms, current) {
+			support = "not supported"
+		}
+
+		_, _ = fmt.Fprintln(os.Stdout, "Platforms: "+strings.Join(help.Platforms, ", "))
+		_, _ = fmt.Fprintf(os.Stdout, "This machine (%s): %s\n", current, support)
+	}
+
+	if help.ArchiveType != "" {
+		_, _ = fmt.Fprintln(os.Stdout, "Archive type: "+help.ArchiveType)
+	}
+
 	return nil
 }
 
@@ -806,10 +1677,10 @@ func cmdHelpDeps(plugin asdf.Plugin) error {
 	return nil
 }
 
-// cmdHelpConfig prints the plugin's configuration help section.
+// cmdHelpConfig prints the plugin's configuration help section, its
+// environment variables as a table followed by the free-text notes.
 func cmdHelpConfig(plugin asdf.Plugin) error {
-	help := plugin.Help()
-	_, _ = fmt.Fprintln(os.Stdout, help.Config)
+	_, _ = fmt.Fprintln(os.Stdout, asdf.HelpConfig(plugin))
 
 	return nil
 }
@@ -822,12 +1693,17 @@ func cmdHelpLinks(plugin asdf.Plugin) error {
 	return nil
 }
 
-// cmdWhich displays the path to an executable.
-func cmdWhich(toolName string) error {
+// cmdWhich displays the path to executable of the selected version of a tool,
+// by default the executable named after the tool (see findExecutable).
+func cmdWhich(toolName, executable string) error {
 	ctx := context.Background()
 
 	// 1. Resolve version
-	toolVersion := resolveToolVersion(ctx, toolName)
+	toolVersion, err := resolveToolVersion(ctx, toolName)
+	if err != nil {
+		return err
+	}
+
 	if toolVersion == "" {
 		return fmt.Errorf("%w for %s", errNoVersionSet, toolName)
 	}
@@ -838,175 +1714,643 @@ func cmdWhich(toolName string) error {
 		return err
 	}
 
+	// A "system" pin falls through to the host binary on PATH.
+	if toolVersion == asdf.SystemVersion {
+		path, err := systemExecutable(plugin)
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintln(os.Stdout, path)
+
+		return nil
+	}
+
+	// Executing a managed version of a tool while it is being installed means a
+	// build toolchain resolved to our own shim, which would loop forever.
+	if err := asdf.CheckInstallRecursion(toolName); err != nil {
+		return err
+	}
+
 	// 3. Construct install path
-	asdfDataDir := getAsdfDataDir()
-	installPath := filepath.Join(asdfDataDir, "installs", toolName, toolVersion)
+	installPath, err := defaultInstallPath(toolName, toolVersion)
+	if err != nil {
+		return err
+	}
 
 	if _, err := os.Stat(installPath); os.IsNotExist(err) {
 		return fmt.Errorf("%w: %s %s", errVersionNotInstalled, toolName, toolVersion)
 	}
 
 	// 4. Find executable
-	binPathsStr := plugin.ListBinPaths()
-	if binPathsStr == "" {
-		binPathsStr = "bin"
+	path, err := findExecutable(plugin, installPath, executable)
+	if err != nil {
+		return fmt.Errorf("%w for %s %s", err, toolName, toolVersion)
 	}
 
-	binPaths := strings.FieldsSeq(binPathsStr)
-	for binPath := range binPaths {
-		binDir := filepath.Join(installPath, binPath)
+	_, _ = fmt.Fprintln(os.Stdout, path)
 
-		entries, err := os.ReadDir(binDir)
-		if err != nil {
-			continue
+	return nil
+}
+
+// findExecutable returns the executable named executable in the bin paths of
+// plugin under installPath, or in its extra bin directories (see asdf.BinDirsOf). Without a name it looks for the plugin name, then
+// for the binary name of the plugin, and finally takes the first executable
+// with a warning, since multi-binary installs such as gcloud ship many.
+// A trailing .exe is tolerated.
+func findExecutable(plugin asdf.Plugin, installPath, executable string) (string, error) {
+	names := []string{executable}
+	if executable == "" {
+		names = []string{plugin.Name()}
+		if binaryName := asdf.BinaryNameOf(plugin); binaryName != plugin.Name() {
+			names = append(names, binaryName)
 		}
+	}
 
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
-			}
+	binDirs := asdf.BinDirsOf(plugin, installPath)
 
-			// return the first executable found
-			// simplified logic, might need to match tool name or config
-			info, err := entry.Info()
-			if err != nil {
-				continue
-			}
+	var first string
 
-			if info.Mode()&0o111 != 0 {
-				_, _ = fmt.Fprintln(os.Stdout, filepath.Join(binDir, entry.Name()))
+	for _, name := range names {
+		for _, binDir := range binDirs {
+			for _, candidate := range []string{name, name + ".exe"} {
+				if isExecutableFile(filepath.Join(binDir, candidate)) {
+					return filepath.Join(binDir, candidate), nil
+				}
+			}
 
-				return nil
+			if first == "" {
+				first = firstExecutable(binDir)
 			}
 		}
 	}
 
-	return fmt.Errorf("%w for %s %s", errNoExecutableFound, toolName, toolVersion)
-}
-
-// cmdReshim regenerates shims for all installed tool versions.
-func cmdReshim() error {
-	asdfDataDir := os.Getenv("ASDF_DATA_DIR")
-	if asdfDataDir == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("getting home directory: %w", err)
-		}
+	if executable != "" {
+		return "", fmt.Errorf("%w named %s", errNoExecutableFound, executable)
+	}
 
-		asdfDataDir = filepath.Join(homeDir, ".asdf")
+	if first == "" {
+		return "", errNoExecutableFound
 	}
 
-	shimsDir := filepath.Join(asdfDataDir, "shims")
-	installsDir := filepath.Join(asdfDataDir, "installs")
+	asdf.Logger().Warn("no executable named after the tool, using the first one",
+		"tool", plugin.Name(), "executable", filepath.Base(first))
 
-	// Ensure shims directory exists
-	if err := os.MkdirAll(shimsDir, asdf.CommonDirectoryPermission); err != nil {
-		return fmt.Errorf("creating shims directory: %w", err)
-	}
+	return first, nil
+}
 
-	// Remove all existing shims
-	entries, err := os.ReadDir(shimsDir)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("reading shims directory: %w", err)
+// isExecutableFile reports whether path is a regular file with an execute bit.
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+
+	return err == nil && !info.IsDir() && info.Mode()&0o111 != 0
+}
+
+// firstExecutable returns the first executable file in dir, or "".
+func firstExecutable(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
 	}
 
 	for _, entry := range entries {
-		err := os.Remove(filepath.Join(shimsDir, entry.Name()))
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to remove old shim %s: %v\n", entry.Name(), err)
+		if path := filepath.Join(dir, entry.Name()); isExecutableFile(path) {
+			return path
 		}
 	}
 
-	// Read .tool-versions to determine which versions to shim
-	toolVersions, err := parseToolVersions(".tool-versions")
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("reading .tool-versions: %w", err)
-	}
+	return ""
+}
 
-	shimCount := 0
+// cmdDiffVersions prints the files added, removed and changed between two
+// installed versions of a tool.
+func cmdDiffVersions(toolName, oldVersion, newVersion string, asJSON, binOnly bool) error {
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return err
+	}
 
-	for toolName, version := range toolVersions {
-		installPath := filepath.Join(installsDir, toolName, version)
+	digests := make([]asdf.TreeDigest, 0, 2)
 
-		// Skip if not installed
-		if _, err := os.Stat(installPath); os.IsNotExist(err) {
-			continue
+	for _, toolVersion := range []string{oldVersion, newVersion} {
+		installPath := layout.InstallPath(toolName, toolVersion)
+		if _, err := os.Stat(installPath); err != nil {
+			return fmt.Errorf("%w: %s %s", errVersionNotInstalled, toolName, toolVersion)
 		}
 
-		// Get bin paths for this tool
-		plugin, err := plugins.GetPlugin(toolName)
+		digest, err := asdf.HashTree(installPath)
 		if err != nil {
-			continue
+			return fmt.Errorf("hashing %s: %w", installPath, err)
 		}
 
-		binPathsStr := plugin.ListBinPaths()
-		if binPathsStr == "" {
-			binPathsStr = "bin"
+		digests = append(digests, digest)
+	}
+
+	oldFiles, newFiles := digests[0].Files, digests[1].Files
+
+	if binOnly {
+		binPaths := "bin"
+
+		if plugin, err := plugins.GetPlugin(toolName); err == nil && plugin.ListBinPaths() != "" {
+			binPaths = plugin.ListBinPaths()
 		}
 
-		binPaths := strings.FieldsSeq(binPathsStr)
-		for binPath := range binPaths {
-			binDir := filepath.Join(installPath, binPath)
+		oldFiles = asdf.FilterTreeFiles(oldFiles, strings.Fields(binPaths))
+		newFiles = asdf.FilterTreeFiles(newFiles, strings.Fields(binPaths))
+	}
 
-			binaries, err := os.ReadDir(binDir)
-			if err != nil {
-				continue
-			}
+	return asdf.DiffTrees(oldFiles, newFiles).Write(os.Stdout, asJSON)
+}
 
-			for _, binary := range binaries {
-				if binary.IsDir() {
-					continue
-				}
+// systemExecutable returns the host executable of a tool pinned to "system":
+// the first of systemExecutableNames found on PATH outside the shims directory.
+func systemExecutable(plugin asdf.Plugin) (string, error) {
+	var firstErr error
 
-				binFile := filepath.Join(binDir, binary.Name())
+	for _, name := range systemExecutableNames(plugin) {
+		path, err := asdf.FindSystemExecutable(name)
+		if err == nil {
+			return path, nil
+		}
 
-				info, err := os.Stat(binFile)
-				if err != nil {
-					continue
-				}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
 
-				// Only create shims for executable files
-				if info.Mode()&0o111 == 0 {
-					continue
-				}
+	return "", firstErr
+}
 
-				shimPath := filepath.Join(shimsDir, binary.Name())
+// systemExecutableNames returns the executables a "system" pin of plugin
+// stands for: the tool name and binary name followed by the executables of its
+// installed versions.
+func systemExecutableNames(plugin asdf.Plugin) []string {
+	names := []string{plugin.Name()}
+	if binaryName := asdf.BinaryNameOf(plugin); binaryName != plugin.Name() {
+		names = append(names, binaryName)
+	}
 
-				// Remove existing shim if present
-				if err := os.Remove(shimPath); err != nil && !os.IsNotExist(err) {
-					fmt.Fprintf(
-						os.Stderr,
-						"Warning: failed to remove existing shim %s: %v\n",
-						shimPath,
-						err,
-					)
-				}
+	binPaths := strings.Fields(plugin.ListBinPaths())
+	if len(binPaths) == 0 {
+		binPaths = []string{"bin"}
+	}
 
-				// Create symlink to actual binary
-				if err := os.Symlink(binFile, shimPath); err != nil {
-					fmt.Fprintf(
-						os.Stderr,
-						"Warning: failed to create shim for %s: %v\n",
-						binary.Name(),
-						err,
-					)
+	installed, err := asdf.ListInstalled(plugin.Name(), "", binPaths)
+	if err != nil {
+		return names
+	}
+
+	for _, version := range installed {
+		for _, binPath := range binPaths {
+			entries, err := os.ReadDir(filepath.Join(version.Path, binPath))
+			if err != nil {
+				continue
+			}
 
+			for _, entry := range entries {
+				info, err := entry.Info()
+				if err != nil || entry.IsDir() || info.Mode()&0o111 == 0 || slices.Contains(names, entry.Name()) {
 					continue
 				}
 
-				shimCount++
+				names = append(names, entry.Name())
 			}
 		}
 	}
 
-	_, _ = fmt.Fprintf(os.Stdout, "Created %d shims in %s\n", shimCount, shimsDir)
+	return names
+}
+
+// cmdWhere prints the install path of a tool version, the version selected by
+// .tool-versions when toolVersion is empty. It fails with errVersionNotInstalled
+// unless the version is installed with an executable in its bin paths.
+func cmdWhere(ctx context.Context, out io.Writer, toolName, toolVersion string) error {
+	plugin, installPath, err := installedToolPath(ctx, toolName, toolVersion)
+	if err != nil {
+		return err
+	}
+
+	if provider, ok := plugin.(asdf.InstallRootProvider); ok {
+		installPath = provider.InstallRoot(installPath)
+	}
+
+	_, _ = fmt.Fprintln(out, installPath)
+
+	return nil
+}
+
+// cmdCompletionsPath prints the shell completions directory of a tool
+// version, selected like cmdWhere, which plugins fill from the release
+// archive. It fails with errNoCompletions when the release shipped none.
+func cmdCompletionsPath(ctx context.Context, out io.Writer, toolName, toolVersion string) error {
+	plugin, installPath, err := installedToolPath(ctx, toolName, toolVersion)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(installPath, filepath.FromSlash(asdf.CompletionsDir))
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) == 0 {
+		return fmt.Errorf("%w: %s %s", errNoCompletions, plugin.Name(), filepath.Base(installPath))
+	}
+
+	_, _ = fmt.Fprintln(out, dir)
+
+	return nil
+}
+
+// cmdMetadata implements the `metadata` subcommand, printing the install
+// metadata of an installed tool version.
+func cmdMetadata(ctx context.Context, out io.Writer, toolName, toolVersion string) error {
+	plugin, installPath, err := installedToolPath(ctx, toolName, toolVersion)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(installPath, asdf.InstallMetadataFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w for %s %s", errNoInstallMetadata, plugin.Name(), filepath.Base(installPath))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(data)
+
+	return err
+}
+
+// installedToolPath returns the plugin of toolName and the install path of
+// its version toolVersion, the version selected by .tool-versions when empty.
+// It fails with errVersionNotInstalled unless the version is installed with
+// an executable in its bin paths.
+func installedToolPath(ctx context.Context, toolName, toolVersion string) (asdf.Plugin, string, error) {
+	plugin, err := plugins.GetPlugin(toolName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if toolVersion == "" {
+		toolVersion, err = resolveToolVersion(ctx, plugin.Name())
+		if err != nil {
+			return nil, "", err
+		}
+
+		if toolVersion == "" {
+			return nil, "", fmt.Errorf("%w for %s", errNoVersionSet, plugin.Name())
+		}
+	}
+
+	installed, err := asdf.ListInstalled(plugin.Name(), "", strings.Fields(plugin.ListBinPaths()))
+	if err != nil {
+		return nil, "", err
+	}
+
+	index := slices.IndexFunc(installed, func(version asdf.InstalledVersion) bool {
+		return version.Version == toolVersion
+	})
+	if index < 0 || installed[index].Incomplete {
+		return nil, "", fmt.Errorf("%w: %s %s", errVersionNotInstalled, plugin.Name(), toolVersion)
+	}
+
+	return plugin, installed[index].Path, nil
+}
+
+// cmdChangelog prints the release notes of a tool version, or where to read
+// them when the plugin cannot fetch them. "latest" and "latest:<query>" resolve
+// to the latest stable version.
+func cmdChangelog(ctx context.Context, toolName, toolVersion string) error {
+	plugin, err := plugins.GetPlugin(toolName)
+	if err != nil {
+		return err
+	}
+
+	if err := asdf.RequireCapability(plugin, asdf.CapabilityChangelog, "changelog"); err != nil {
+		return err
+	}
+
+	toolVersion, err = asdf.ResolveLatestVersion(ctx, plugin, toolVersion)
+	if err != nil {
+		return err
+	}
+
+	provider, _ := plugin.(asdf.ChangelogProvider)
+	_, _ = fmt.Fprintf(os.Stdout, "%s %s: %s\n", plugin.Name(), toolVersion, provider.ReleaseURL(toolVersion))
+
+	notesProvider, ok := plugin.(asdf.ReleaseNotesProvider)
+	if !ok {
+		return nil
+	}
+
+	notes, err := notesProvider.ReleaseNotes(ctx, toolVersion)
+	if err != nil {
+		return err
+	}
+
+	if notes = strings.TrimSpace(notes); notes != "" {
+		_, _ = fmt.Fprintf(os.Stdout, "\n%s\n", notes)
+	}
+
+	return nil
+}
+
+// cmdReshim regenerates shims for all installed tool versions.
+func cmdReshim() error {
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return err
+	}
+
+	shimsDir := layout.ShimsDir
+
+	// Ensure shims directory exists
+	if err := os.MkdirAll(shimsDir, asdf.CommonDirectoryPermission); err != nil {
+		return fmt.Errorf("creating shims directory: %w", err)
+	}
+
+	// Remove all existing shims
+	entries, err := os.ReadDir(shimsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading shims directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		err := os.Remove(filepath.Join(shimsDir, entry.Name()))
+		if err != nil {
+			asdf.Logger().Warn("failed to remove old shim", "shim", entry.Name(), "error", err)
+		}
+	}
+
+	// Shim the versions which would select: ASDF_<TOOL>_VERSION, else the
+	// nearest tool versions file pinning each tool
+	toolVersions, err := asdf.SelectedToolVersions()
+	if err != nil {
+		return fmt.Errorf("resolving tool versions: %w", err)
+	}
+
+	shimCount := 0
+
+	for toolName, version := range toolVersions {
+		// A "system" pin still gets shims, pointing at the host binaries, so
+		// that switching between system and managed versions keeps working.
+		if version == asdf.SystemVersion {
+			shimCount += reshimSystem(shimsDir, toolName)
+
+			continue
+		}
+
+		installPath := layout.InstallPath(toolName, version)
+
+		// Skip if not installed
+		if _, err := os.Stat(installPath); os.IsNotExist(err) {
+			continue
+		}
+
+		// Get bin paths for this tool
+		plugin, err := plugins.GetPlugin(toolName)
+		if err != nil {
+			continue
+		}
+
+		// Bin paths, plus app directories such as those of pipx
+		for _, binDir := range asdf.BinDirsOf(plugin, installPath) {
+			binaries, err := os.ReadDir(binDir)
+			if err != nil {
+				continue
+			}
+
+			for _, binary := range binaries {
+				if binary.IsDir() {
+					continue
+				}
+
+				binFile := filepath.Join(binDir, binary.Name())
+
+				info, err := os.Stat(binFile)
+				if err != nil {
+					continue
+				}
+
+				// Only create shims for executable files
+				if info.Mode()&0o111 == 0 {
+					continue
+				}
+
+				shimPath := filepath.Join(shimsDir, binary.Name())
+
+				// Remove existing shim if present
+				if err := os.Remove(shimPath); err != nil && !os.IsNotExist(err) {
+					asdf.Logger().Warn("failed to remove existing shim", "shim", shimPath, "error", err)
+				}
+
+				// Create symlink to actual binary
+				if err := os.Symlink(binFile, shimPath); err != nil {
+					asdf.Logger().Warn("failed to create shim", "binary", binary.Name(), "error", err)
+
+					continue
+				}
+
+				shimCount++
+			}
+		}
+	}
+
+	if err := asdf.ShareTree(shimsDir); err != nil {
+		return fmt.Errorf("sharing shims with the group: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "Created %d shims in %s\n", shimCount, shimsDir)
+
+	return nil
+}
+
+// reshimSystem links the shims of a tool pinned to "system" to the host
+// executables found on PATH and returns the number of shims created.
+func reshimSystem(shimsDir, toolName string) int {
+	plugin, err := plugins.GetPlugin(toolName)
+	if err != nil {
+		return 0
+	}
+
+	shimCount := 0
+
+	for _, name := range systemExecutableNames(plugin) {
+		path, err := asdf.FindSystemExecutable(name)
+		if err != nil {
+			continue
+		}
+
+		shimPath := filepath.Join(shimsDir, name)
+
+		if err := os.Remove(shimPath); err != nil && !os.IsNotExist(err) {
+			asdf.Logger().Warn("failed to remove existing shim", "shim", shimPath, "error", err)
+		}
+
+		if err := os.Symlink(path, shimPath); err != nil {
+			asdf.Logger().Warn("failed to create shim", "binary", name, "error", err)
+
+			continue
+		}
+
+		shimCount++
+	}
+
+	return shimCount
+}
+
+// cmdDoctor reports data dir entries that other users sharing it cannot use.
+func cmdDoctor() error {
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return err
+	}
+
+	issues, err := asdf.CheckOwnership(layout.DataDir)
+	if err != nil {
+		return fmt.Errorf("checking %s: %w", layout.DataDir, err)
+	}
+
+	if len(issues) == 0 {
+		_, _ = fmt.Fprintf(os.Stdout, "%s: ok\n", layout.DataDir)
+
+		return nil
+	}
+
+	for _, issue := range issues {
+		_, _ = fmt.Fprintf(os.Stdout, "%s: %s\n", issue.Path, issue.Problem)
+	}
+
+	return fmt.Errorf("%w: %d entries in %s", errDoctorIssues, len(issues), layout.DataDir)
+}
+
+// cmdList prints the installed versions of toolName, or a section per
+// installed tool when toolName is empty.
+func cmdList(toolName string) error {
+	tools := []string{toolName}
+
+	if toolName == "" {
+		installed, err := asdf.InstalledTools()
+		if err != nil {
+			return err
+		}
+
+		tools = installed
+	}
+
+	for _, tool := range tools {
+		var (
+			binPaths []string
+			current  string
+		)
+
+		if plugin, err := plugins.GetPlugin(tool); err == nil {
+			binPaths = strings.Fields(plugin.ListBinPaths())
+
+			current, err = asdf.ResolveToolVersion(plugin)
+			if err != nil {
+				asdf.Logger().Debug("resolving current version", "tool", tool, "error", err)
+			}
+		}
+
+		versions, err := asdf.ListInstalled(tool, current, binPaths)
+		if err != nil {
+			return err
+		}
+
+		if toolName == "" {
+			_, _ = fmt.Fprintln(os.Stdout, tool)
+		}
+
+		if len(versions) == 0 {
+			_, _ = fmt.Fprintln(os.Stdout, "  No versions installed")
+
+			continue
+		}
+
+		if err := asdf.WriteInstalled(os.Stdout, versions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cmdLocksList prints the lock files of the data directory and their holders.
+func cmdLocksList() error {
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := filelock.List(layout.LocksDir())
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		state := "free"
+
+		switch {
+		case status.Held:
+			state = "held"
+		case status.Holder != nil:
+			state = "stale"
+		}
+
+		holder := ""
+		if status.Holder != nil {
+			holder = status.Holder.String()
+		}
+
+		name := strings.TrimSuffix(filepath.Base(status.Path), filelock.Suffix)
+		_, _ = fmt.Fprintf(os.Stdout, "%-40s %-6s %s\n", name, state, holder)
+	}
+
+	return nil
+}
+
+// cmdLocksBreak removes the named lock unless its holder still runs on this host.
+func cmdLocksBreak(name string) error {
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return err
+	}
+
+	status, err := filelock.Break(filepath.Join(layout.LocksDir(), asdf.LockFileName(name)))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status.Held && status.Holder != nil:
+		_, _ = fmt.Fprintf(os.Stdout, "Broke lock %s held by %s\n", name, status.Holder)
+	case status.Holder != nil:
+		_, _ = fmt.Fprintf(os.Stdout, "Removed stale lock %s last held by %s\n", name, status.Holder)
+	default:
+		_, _ = fmt.Fprintf(os.Stdout, "Removed lock %s\n", name)
+	}
 
 	return nil
 }
 
+// cmdHistory prints the recorded history entries matching filter.
+func cmdHistory(filter asdf.HistoryFilter, asJSON bool) error {
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return err
+	}
+
+	entries, err := asdf.ReadHistory(layout.HistoryFile())
+	if err != nil {
+		return err
+	}
+
+	return asdf.WriteHistory(os.Stdout, asdf.FilterHistory(entries, filter), asJSON)
+}
+
 // cmdInstallPlugin installs this binary as one or more asdf plugins.
 func cmdInstallPlugin() error {
-	pluginsToInstall := asdf.AvailablePlugins()
+	pluginsToInstall := plugins.AvailablePlugins()
 	if len(os.Args) >= 3 {
 		pluginsToInstall = os.Args[2:]
 	}
@@ -1079,34 +2423,38 @@ type ToolUpdateResult struct {
 	Name       string
 	OldVersion string
 	NewVersion string
+	// ReleaseURL links the release notes of NewVersion when the plugin provides them.
+	ReleaseURL string
 	Changed    bool
 }
 
 // cmdUpdateToolVersions updates all tools in .tool-versions to their latest versions.
 // Tools with "latest" as their version will be resolved to actual version numbers.
 // cmdUpdateToolVersions implements the update-tool-versions subcommand.
-// It expands any "latest" entries in .tool-versions to concrete versions
-// by querying each plugin for its latest stable release.
-func cmdUpdateToolVersions() error {
-	toolVersionsPath := ".tool-versions"
-	if len(os.Args) >= 3 {
-		toolVersionsPath = os.Args[2]
-	}
-
-	existingVersions, err := parseToolVersions(toolVersionsPath)
+// It expands any "latest" and "latest:<query>" entries in .tool-versions to
+// concrete versions by querying each plugin for its latest stable release.
+// Other entries are written back as they were, keeping any ${VAR} references.
+//
+// With pinComment, every resolved entry gets an "auto: was <spec>, updated
+// <date>" comment, and entries carrying one are resolved from <spec> again on
+// later runs, rewriting the comment only when the version changes. With
+// dryRun, the lines that would change are printed and the file is left alone.
+func cmdUpdateToolVersions(out io.Writer, toolVersionsPath string, pinComment, dryRun bool) error {
+	existingVersions, err := parseToolVersionEntries(toolVersionsPath)
 	if err != nil {
 		return fmt.Errorf("parsing %s: %w", toolVersionsPath, err)
 	}
 
 	if len(existingVersions) == 0 {
-		_, _ = fmt.Fprintln(os.Stdout, "No tools found in", toolVersionsPath)
+		_, _ = fmt.Fprintln(out, "No tools found in", toolVersionsPath)
 
 		return nil
 	}
 
 	ctx := context.Background()
+	started := time.Now()
 	results := make([]ToolUpdateResult, 0, len(existingVersions))
-	updatedVersions := make(map[string]string, len(existingVersions))
+	updatedVersions := make(map[string]toolVersionEntry, len(existingVersions))
 
 	// Fetch latest versions in parallel
 	var (
@@ -1114,67 +2462,51 @@ func cmdUpdateToolVersions() error {
 		wg sync.WaitGroup
 	)
 
-	type job struct {
-		name       string
-		oldVersion string
-	}
-
-	jobs := make([]job, 0, len(existingVersions))
-	for name, version := range existingVersions {
-		jobs = append(jobs, job{name: name, oldVersion: version})
-	}
-
-	for i := range jobs {
-		toolJob := jobs[i]
-
+	for name, entry := range existingVersions {
 		wg.Go(func() {
 			result := ToolUpdateResult{
-				Name:       toolJob.name,
-				OldVersion: toolJob.oldVersion,
+				Name:       name,
+				OldVersion: entry.Version,
+				NewVersion: entry.Version,
 			}
 
-			plugin, err := plugins.GetPlugin(toolJob.name)
-			if err != nil {
-				result.NewVersion = toolJob.oldVersion
-				result.Error = err
-
-				mu.Lock()
-
-				results = append(results, result)
-				updatedVersions[toolJob.name] = toolJob.oldVersion
+			// Entries that are not bumped are written as they were, so
+			// variable references and comments survive the rewrite.
+			written := entry
 
-				mu.Unlock()
-
-				return
+			spec := entry.Raw
+			if autoSpec, ok := autoPinSpec(entry.Comment); ok && pinComment {
+				spec = autoSpec
 			}
 
-			if toolJob.oldVersion == "latest" {
-				latestVersion, err := plugin.LatestStable(ctx, "")
-				if err != nil {
-					result.NewVersion = toolJob.oldVersion
-					result.Error = err
-
-					mu.Lock()
+			plugin, err := plugins.GetPlugin(name)
+			if err != nil {
+				result.Error = err
+			} else if query, latest := asdf.ParseLatestVersion(spec); latest {
+				result.NewVersion, err = asdf.LatestStableVersion(ctx, plugin, query)
 
-					results = append(results, result)
-					updatedVersions[toolJob.name] = toolJob.oldVersion
+				switch {
+				case err != nil:
+					result.NewVersion = entry.Version
+					result.Error = err
+				case result.NewVersion != entry.Version:
+					result.Changed = true
+					written = toolVersionEntry{Raw: result.NewVersion, Version: result.NewVersion, Comment: entry.Comment}
 
-					mu.Unlock()
+					if pinComment {
+						written.Comment = autoPinComment(spec, started)
+					}
 
-					return
+					if provider, ok := plugin.(asdf.ChangelogProvider); ok {
+						result.ReleaseURL = provider.ReleaseURL(result.NewVersion)
+					}
 				}
-
-				result.NewVersion = latestVersion
-				result.Changed = true
-			} else {
-				result.NewVersion = toolJob.oldVersion
-				result.Changed = false
 			}
 
 			mu.Lock()
 
 			results = append(results, result)
-			updatedVersions[toolJob.name] = result.NewVersion
+			updatedVersions[name] = written
 
 			mu.Unlock()
 		})
@@ -1182,9 +2514,29 @@ func cmdUpdateToolVersions() error {
 
 	wg.Wait()
 
-	err = writeToolVersions(toolVersionsPath, updatedVersions)
-	if err != nil {
-		return fmt.Errorf("writing %s: %w", toolVersionsPath, err)
+	if !dryRun {
+		err = writeToolVersions(toolVersionsPath, updatedVersions)
+		if err != nil {
+			err = fmt.Errorf("writing %s: %w", toolVersionsPath, err)
+		}
+
+		for i := range results {
+			res := results[i]
+			if !res.Changed && res.Error == nil {
+				continue
+			}
+
+			resErr := res.Error
+			if resErr == nil {
+				resErr = err
+			}
+
+			asdf.RecordHistory(asdf.HistoryOperationUpdateToolVersions, res.Name, res.NewVersion, started, resErr)
+		}
+
+		if err != nil {
+			return err
+		}
 	}
 
 	sort.Slice(results, func(i, j int) bool {
@@ -1199,7 +2551,7 @@ func cmdUpdateToolVersions() error {
 		switch {
 		case res.Error != nil:
 			_, _ = fmt.Fprintf(
-				os.Stdout,
+				out,
 				"  %-20s %s (error: %v)\n",
 				res.Name,
 				res.OldVersion,
@@ -1210,13 +2562,21 @@ func cmdUpdateToolVersions() error {
 
 		case res.Changed:
 			_, _ = fmt.Fprintf(
-				os.Stdout,
+				out,
 				"  %-20s %s -> %s\n",
 				res.Name,
 				res.OldVersion,
 				res.NewVersion,
 			)
 
+			if dryRun {
+				_, _ = fmt.Fprintf(out, "  %-20s would write: %s\n", "", updatedVersions[res.Name].line(res.Name))
+			}
+
+			if res.ReleaseURL != "" {
+				_, _ = fmt.Fprintf(out, "  %-20s changelog: %s\n", "", res.ReleaseURL)
+			}
+
 			updated++
 
 		default:
@@ -1224,9 +2584,15 @@ func cmdUpdateToolVersions() error {
 		}
 	}
 
+	summary := "Updated"
+	if dryRun {
+		summary = "Would update"
+	}
+
 	_, _ = fmt.Fprintf(
-		os.Stdout,
-		"\nUpdated: %d, Unchanged: %d, Failed: %d\n",
+		out,
+		"\n%s: %d, Unchanged: %d, Failed: %d\n",
+		summary,
 		updated,
 		unchanged,
 		failed,
@@ -1235,79 +2601,286 @@ func cmdUpdateToolVersions() error {
 	return nil
 }
 
-// resolveToolVersion resolves the version of a tool from the nearest .tool-versions file.
-func resolveToolVersion(_ context.Context, toolName string) string {
-	// 1. Try to find .tool-versions
-	path, err := asdf.ResolveToolVersionsPath()
-	if err != nil {
-		return ""
+// cmdPinInstalled implements the pin-installed subcommand. It pins every tool
+// in .tool-versions, or with all every tool with installs, to its newest
+// installed version and prints the old and new version of each changed line.
+// Tools without an installed version are left as they were and listed as
+// skipped; with strict they make the command fail once the file is written.
+// Only the pinned lines change and tools missing from the file are appended
+// (see writeToolVersions).
+func cmdPinInstalled(out io.Writer, toolVersionsPath string, all, strict bool) error {
+	entries, err := parseToolVersionEntries(toolVersionsPath)
+	if err != nil && (!all || !os.IsNotExist(err)) {
+		return fmt.Errorf("parsing %s: %w", toolVersionsPath, err)
+	}
+
+	if entries == nil {
+		entries = make(map[string]toolVersionEntry)
+	}
+
+	if all {
+		tools, err := asdf.InstalledTools()
+		if err != nil {
+			return err
+		}
+
+		for _, tool := range tools {
+			if _, ok := entries[tool]; !ok {
+				entries[tool] = toolVersionEntry{}
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(out, "No tools found in", toolVersionsPath)
+
+		return nil
+	}
+
+	names := slices.Sorted(maps.Keys(entries))
+
+	var pinned, unchanged, skipped []string
+
+	for _, name := range names {
+		entry := entries[name]
+
+		installed, err := asdf.ListInstalled(name, "", nil)
+		if err != nil {
+			return err
+		}
+
+		if len(installed) == 0 {
+			skipped = append(skipped, name)
+
+			continue
+		}
+
+		newest := installed[0].Version
+		if newest == entry.Version {
+			unchanged = append(unchanged, name)
+
+			continue
+		}
+
+		_, _ = fmt.Fprintf(out, "  %-20s %s -> %s\n", name, cmp.Or(entry.Version, "(none)"), newest)
+
+		entries[name] = toolVersionEntry{Raw: newest, Version: newest, Comment: entry.Comment}
+		pinned = append(pinned, name)
+	}
+
+	if len(pinned) > 0 {
+		if err := writeToolVersions(toolVersionsPath, entries); err != nil {
+			return fmt.Errorf("writing %s: %w", toolVersionsPath, err)
+		}
+	}
+
+	for _, name := range skipped {
+		_, _ = fmt.Fprintf(out, "  %-20s %s (skipped: not installed)\n", name, entries[name].Raw)
+	}
+
+	_, _ = fmt.Fprintf(out, "\nPinned: %d, Unchanged: %d, Skipped: %d\n", len(pinned), len(unchanged), len(skipped))
+
+	if strict && len(skipped) > 0 {
+		return fmt.Errorf("%w: %s", errToolsNotInstalled, strings.Join(skipped, ", "))
 	}
 
-	// 2. Parse it
-	versions, err := parseToolVersions(path)
+	return nil
+}
+
+// autoPinCommentPrefix starts the comment update-tool-versions --pin-comment
+// appends to the versions it resolves.
+const autoPinCommentPrefix = "auto: was "
+
+// autoPinComment returns the comment recording that a version was resolved from spec on date.
+func autoPinComment(spec string, date time.Time) string {
+	return autoPinCommentPrefix + spec + ", updated " + date.Format(time.DateOnly)
+}
+
+// autoPinSpec returns the spec recorded by an autoPinComment, e.g. "latest".
+func autoPinSpec(comment string) (string, bool) {
+	rest, ok := strings.CutPrefix(comment, autoPinCommentPrefix)
+	if !ok {
+		return "", false
+	}
+
+	spec, _, ok := strings.Cut(rest, ", updated ")
+
+	return spec, ok && spec != ""
+}
+
+// resolveToolVersion resolves the version of a tool from ASDF_<TOOL>_VERSION, the
+// nearest .tool-versions file or an enabled legacy version file (see
+// asdf.ResolveToolVersion). Project keywords such as "ginkgo project" are
+// resolved to the effective version.
+func resolveToolVersion(_ context.Context, toolName string) (string, error) {
+	plugin, err := plugins.GetPlugin(toolName)
 	if err != nil {
-		return ""
+		return "", err
+	}
+
+	return asdf.ResolveToolVersion(plugin)
+}
+
+// toolVersionEntry is a tool pinned in a .tool-versions file.
+type toolVersionEntry struct {
+	// Raw is the version as written in the file.
+	Raw string
+	// Version is Raw with variables expanded (see asdf.ExpandToolVersion).
+	Version string
+	// Versions are every version of the line, expanded, starting with Version;
+	// asdf falls back to the later ones when the first is not installed.
+	Versions []string
+	// Comment is the trailing comment of the line, without the "#".
+	Comment string
+}
+
+// line returns the .tool-versions line pinning name to the entry.
+func (entry toolVersionEntry) line(name string) string {
+	if entry.Comment == "" {
+		return name + " " + entry.Raw
 	}
 
-	return versions[toolName]
+	return name + " " + entry.Raw + "  # " + entry.Comment
 }
 
-// parseToolVersions parses a .tool-versions file and returns a map of tool name to version.
 // parseToolVersions reads a .tool-versions file into a map keyed by tool
-// name so that callers can update or inspect requested versions.
+// name with the expanded version of each tool.
 func parseToolVersions(path string) (map[string]string, error) {
-	file, err := os.Open(path)
+	entries, err := parseToolVersionEntries(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	versions := make(map[string]string)
+	versions := make(map[string]string, len(entries))
+	for name, entry := range entries {
+		versions[name] = entry.Version
+	}
+
+	return versions, nil
+}
+
+// parseToolVersionEntries reads a .tool-versions file into a map keyed by tool
+// name, keeping the raw and the expanded versions of each tool as well as its
+// trailing comment. A version
+// that cannot be expanded is reported with the offending line.
+func parseToolVersionEntries(path string) (map[string]toolVersionEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]toolVersionEntry)
+
+	for lineNumber, line := range asdf.VersionFileLines(string(data)) {
+		content, comment, _ := strings.Cut(line, "#")
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
+		fields := strings.Fields(content)
+		if len(fields) < 2 {
 			continue
 		}
 
-		fields := strings.Fields(line)
-		if len(fields) >= 2 {
-			versions[fields[0]] = fields[1]
+		versions := make([]string, 0, len(fields)-1)
+
+		for _, raw := range fields[1:] {
+			version, err := asdf.ExpandToolVersion(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %q: %w", path, lineNumber, line, err)
+			}
+
+			versions = append(versions, version)
 		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+		entries[fields[0]] = toolVersionEntry{
+			Raw:      fields[1],
+			Version:  versions[0],
+			Versions: versions,
+			Comment:  strings.TrimSpace(comment),
+		}
 	}
 
-	return versions, nil
+	return entries, nil
 }
 
-// writeToolVersions writes the given versions map to a .tool-versions file.
-// writeToolVersions writes the provided versions back to a .tool-versions
-// file, keeping tools sorted for deterministic output.
-func writeToolVersions(path string, versions map[string]string) error {
-	keys := make([]string, 0, len(versions))
-	for name := range versions {
-		keys = append(keys, name)
+// writeToolVersions rewrites, in place, the lines of the .tool-versions file
+// at path pinning a tool of versions to another version or comment, replacing
+// only their first version and trailing comment. Every other line, including
+// comments, blank lines and fallback versions, is kept byte for byte. Tools
+// without a line are appended, sorted, and entries without a Raw version are
+// ignored.
+func writeToolVersions(path string, versions map[string]toolVersionEntry) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
 	}
 
-	sort.Strings(keys)
+	var rewritten strings.Builder
 
-	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, asdf.CommonFilePermission)
-	if err != nil {
-		return err
+	written := make(map[string]bool, len(versions))
+
+	for line := range strings.Lines(string(data)) {
+		body := strings.TrimRight(line, "\r\n")
+
+		name, replaced := rewriteToolVersionLine(body, versions)
+		if name != "" {
+			written[name] = true
+		}
+
+		rewritten.WriteString(replaced + line[len(body):])
 	}
-	defer file.Close()
 
-	for _, name := range keys {
-		if toolVersion := versions[name]; toolVersion != "" {
-			fmt.Fprintf(file, "%s %s\n", name, toolVersion)
+	for _, name := range slices.Sorted(maps.Keys(versions)) {
+		if entry := versions[name]; !written[name] && entry.Raw != "" {
+			if rewritten.Len() > 0 && !strings.HasSuffix(rewritten.String(), "\n") {
+				rewritten.WriteString("\n")
+			}
+
+			rewritten.WriteString(entry.line(name) + "\n")
 		}
 	}
 
-	return nil
+	if err == nil && rewritten.String() == string(data) {
+		return nil
+	}
+
+	return os.WriteFile(path, []byte(rewritten.String()), asdf.CommonFilePermission)
+}
+
+// rewriteToolVersionLine returns the tool pinned by a .tool-versions line,
+// "" for comments and blank lines, and the line with the first version and
+// trailing comment of the tool replaced by those of its entry in versions.
+// Lines of other tools, or already matching their entry, are returned as-is.
+func rewriteToolVersionLine(line string, versions map[string]toolVersionEntry) (string, string) {
+	content, comment, hasComment := strings.Cut(line, "#")
+
+	// The first line may start with the UTF-8 byte order mark of Windows editors.
+	fields := strings.Fields(strings.TrimPrefix(content, "\ufeff"))
+	if len(fields) < 2 {
+		return "", line
+	}
+
+	entry, ok := versions[fields[0]]
+	if !ok || entry.Raw == "" || entry.Raw == fields[1] && entry.Comment == strings.TrimSpace(comment) {
+		return fields[0], line
+	}
+
+	versionStart := strings.Index(content, fields[0]) + len(fields[0])
+	versionStart += strings.Index(content[versionStart:], fields[1])
+	content = content[:versionStart] + entry.Raw + content[versionStart+len(fields[1]):]
+
+	switch {
+	case entry.Comment == strings.TrimSpace(comment):
+		if hasComment {
+			return fields[0], content + "#" + comment
+		}
+
+		return fields[0], content
+	case entry.Comment == "":
+		return fields[0], strings.TrimRight(content, " \t")
+	case hasComment:
+		return fields[0], content + "# " + entry.Comment
+	default:
+		return fields[0], strings.TrimRight(content, " \t") + "  # " + entry.Comment
+	}
 }
 
 // toolSumsFile is the filename used to store checksums for helper tools.
@@ -1315,15 +2888,14 @@ const toolSumsFile = ".tool-sums"
 
 // parseToolSumsFromReader parses tool sums from an io.Reader.
 func parseToolSumsFromReader(r io.Reader) (map[string]string, error) {
-	sums := make(map[string]string)
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
 
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+	sums := make(map[string]string)
 
+	for _, line := range asdf.VersionFileLines(string(data)) {
 		fields := strings.Fields(line)
 		if len(fields) >= 3 {
 			key := fields[0] + ":" + fields[1]
@@ -1332,11 +2904,6 @@ func parseToolSumsFromReader(r io.Reader) (map[string]string, error) {
 		}
 	}
 
-	err := scanner.Err()
-	if err != nil {
-		return nil, err
-	}
-
 	return sums, nil
 }
 
@@ -1401,71 +2968,34 @@ func calculateFileHash(path string) (string, error) {
 	}
 	defer file.Close()
 
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return "", err
-	}
-
-	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
-}
-
-// calculateDirHash calculates a combined hash of all files in a directory.
-func calculateDirHash(dir string) (string, error) {
-	hash := sha256.New()
-
-	err := filepath.WalkDir(dir, func(path string, dirEntry os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-
-		relPath, err := filepath.Rel(dir, path)
-		if err != nil {
-			return err
-		}
-
-		if dirEntry.IsDir() {
-			return nil
-		}
-
-		info, err := dirEntry.Info()
-		if err != nil {
-			return nil
-		}
-
-		if info.Mode()&os.ModeSymlink != 0 {
-			target, err := os.Readlink(path)
-			if err != nil {
-				return nil
-			}
-
-			hash.Write([]byte(relPath + "->" + target))
-
-			return nil
-		}
-
-		if !info.Mode().IsRegular() {
-			return nil
-		}
-
-		hash.Write([]byte(relPath))
-
-		file, err := os.Open(path)
-		if err != nil {
-			return nil
-		}
-		defer file.Close()
-
-		if _, err := io.Copy(hash, file); err != nil {
-			return nil
-		}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
 
-		return nil
-	})
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// calculateDirHash calculates a combined hash of all files in a directory,
+// leaving out those matching one of the ignore globs.
+func calculateDirHash(dir string, ignore ...string) (string, error) {
+	digest, err := asdf.HashTree(dir, ignore...)
 	if err != nil {
 		return "", err
 	}
 
-	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+	return digest.Hash, nil
+}
+
+// calculateInstallHash calculates the hash of an install of tool, leaving out
+// the files its plugin excludes from checksums.
+func calculateInstallHash(tool, installPath string) (string, error) {
+	var ignore []string
+	if plugin, err := plugins.GetPlugin(tool); err == nil {
+		ignore = asdf.HashIgnoreGlobsOf(plugin)
+	}
+
+	return calculateDirHash(installPath, ignore...)
 }
 
 // getDownloadHash calculates the hash of downloaded files in the download path.
@@ -1492,8 +3022,8 @@ func getDownloadHash(downloadPath string) (string, error) {
 	return calculateDirHash(downloadPath)
 }
 
-// withToolSumsLock executes the given function with a file lock held on the tool sums file.
-// If readOnly is true, it acquires a shared lock. Otherwise, it acquires an exclusive lock.
+// withToolSumsLock executes the given function with a lock held on the tool sums file.
+// If lockExclusive is false, it acquires a shared lock. Otherwise, it acquires an exclusive lock.
 func withToolSumsLock(
 	path string,
 	flags int,
@@ -1501,7 +3031,18 @@ func withToolSumsLock(
 	allowMissing bool,
 	fn func(file *os.File) error,
 ) error {
-	file, err := os.OpenFile(path, flags, asdf.CommonFilePermission)
+	lock, err := asdf.AcquireLock(context.Background(), toolSumsLockName(path), "update "+path, !lockExclusive)
+	if err != nil {
+		return fmt.Errorf("locking %s: %w", path, err)
+	}
+
+	defer func() {
+		if unlockErr := lock.Release(); unlockErr != nil {
+			asdf.Logger().Warn("failed to unlock tool sums file", "error", unlockErr)
+		}
+	}()
+
+	file, err := asdf.OpenSharedFile(path, flags, asdf.CommonFilePermission)
 	if err != nil {
 		if allowMissing && os.IsNotExist(err) {
 			return fn(nil)
@@ -1511,18 +3052,19 @@ func withToolSumsLock(
 	}
 	defer file.Close()
 
-	if err := lockToolSumsFile(int(file.Fd()), lockExclusive); err != nil {
-		return fmt.Errorf("locking file: %w", err)
+	return fn(file)
+}
+
+// toolSumsLockName returns the lock name of the tool sums file at path, which
+// is unique per project directory.
+func toolSumsLockName(path string) string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
 	}
 
-	defer func() {
-		unlockErr := unlockToolSumsFile(int(file.Fd()))
-		if unlockErr != nil {
-			fmt.Fprintf(os.Stderr, "warning: unlocking tool sums file: %v\n", unlockErr)
-		}
-	}()
+	sum := sha256.Sum256([]byte(path))
 
-	return fn(file)
+	return "tool-sums-" + hex.EncodeToString(sum[:6])
 }
 
 func withToolSumsReadLock(path string, fn func(file *os.File) error) error {
@@ -1533,13 +3075,11 @@ func withToolSumsWriteLock(path string, fn func(file *os.File) error) error {
 	return withToolSumsLock(path, os.O_RDWR|os.O_CREATE, true, false, fn)
 }
 
-// verifyToolSum verifies the checksum of a downloaded tool.
-func verifyToolSum(name, version, downloadPath string) error {
-	sumsPath := toolSumsFile
-
+// readToolSums returns the checksums recorded in the tool sums file at path.
+func readToolSums(path string) (map[string]string, error) {
 	var sums map[string]string
 
-	err := withToolSumsReadLock(sumsPath, func(file *os.File) error {
+	err := withToolSumsReadLock(path, func(file *os.File) error {
 		if file == nil {
 			sums = make(map[string]string)
 
@@ -1553,7 +3093,17 @@ func verifyToolSum(name, version, downloadPath string) error {
 		return err
 	})
 	if err != nil {
-		return fmt.Errorf("reading tool sums: %w", err)
+		return nil, fmt.Errorf("reading tool sums: %w", err)
+	}
+
+	return sums, nil
+}
+
+// verifyToolSum verifies the checksum of a downloaded tool.
+func verifyToolSum(name, version, downloadPath string) error {
+	sums, err := readToolSums(toolSumsFile)
+	if err != nil {
+		return err
 	}
 
 	key := name + ":" + version
@@ -1617,6 +3167,186 @@ func recordToolSum(name, version, downloadPath string) error {
 	})
 }
 
+// cmdLock implements the `lock` subcommand. It writes the resolved version,
+// artifact URLs and hashes of every tool of .tool-versions to the lockfile.
+// Hashes come from .tool-sums, or from the download, which is fetched first
+// when it is not present yet.
+func cmdLock(ctx context.Context) error {
+	pinned, err := parseToolVersions(".tool-versions")
+	if err != nil {
+		return fmt.Errorf("parsing .tool-versions: %w", err)
+	}
+
+	sums, err := readToolSums(toolSumsFile)
+	if err != nil {
+		return err
+	}
+
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	var lockfile asdf.Lockfile
+
+	for _, name := range slices.Sorted(maps.Keys(pinned)) {
+		plugin, err := plugins.GetPlugin(name)
+		if err != nil {
+			asdf.Logger().Warn("not locking tool without plugin", "tool", name, "error", err)
+
+			continue
+		}
+
+		toolVersion, err := asdf.ResolveLatestVersion(ctx, plugin, pinned[name])
+		if err != nil {
+			return fmt.Errorf("resolving latest %s version: %w", name, err)
+		}
+
+		toolVersion, err = asdf.ResolveEffectiveVersion(plugin, cwd, toolVersion)
+		if err != nil {
+			return err
+		}
+
+		downloadPath := layout.DownloadPath(name, toolVersion)
+
+		if err := ensureLockDownload(ctx, plugin, toolVersion, downloadPath); err != nil {
+			return err
+		}
+
+		hash := sums[name+":"+toolVersion]
+		if hash == "" {
+			if hash, err = getDownloadHash(downloadPath); err != nil {
+				return fmt.Errorf("hashing %s %s download: %w", name, toolVersion, err)
+			}
+		}
+
+		tool, err := asdf.LockTool(ctx, plugin, toolVersion, downloadPath, hash)
+		if err != nil {
+			return err
+		}
+
+		lockfile.Tools = append(lockfile.Tools, tool)
+	}
+
+	if err := asdf.WriteLockfile(asdf.ToolVersionsLockFileName, lockfile); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "Locked %d tools in %s\n", len(lockfile.Tools), asdf.ToolVersionsLockFileName)
+
+	return nil
+}
+
+// ensureLockDownload downloads version of plugin into downloadPath unless
+// every file hashed into the lockfile is already there.
+func ensureLockDownload(ctx context.Context, plugin asdf.Plugin, version, downloadPath string) error {
+	missing, err := asdf.MissingArtifacts(plugin, version, downloadPath)
+	if err != nil {
+		return err
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if err := cmdDownload(ctx, plugin, version, downloadPath); err != nil {
+		return fmt.Errorf("downloading %s %s to lock it: %w", plugin.Name(), version, err)
+	}
+
+	return nil
+}
+
+// cmdInstallLocked implements `install --locked`. It installs the locked
+// version of plugin, or of every locked tool when plugin is nil, and fails when
+// a download does not match the hashes of the lockfile.
+func cmdInstallLocked(ctx context.Context, plugin asdf.Plugin, requestedVersion string) error {
+	lockfile, err := asdf.ReadLockfile(asdf.ToolVersionsLockFileName)
+	if err != nil {
+		return err
+	}
+
+	if pinned, err := parseToolVersions(".tool-versions"); err == nil {
+		names := make([]string, 0, len(pinned))
+		for name := range pinned {
+			if _, err := plugins.GetPlugin(name); err == nil {
+				names = append(names, name)
+			}
+		}
+
+		if err := lockfile.CheckPinned(names); err != nil {
+			return err
+		}
+	}
+
+	if plugin == nil {
+		for _, tool := range lockfile.Tools {
+			toolPlugin, err := plugins.GetPlugin(tool.Name)
+			if err != nil {
+				return err
+			}
+
+			if err := installLockedTool(ctx, toolPlugin, tool); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	tool, ok := lockfile.Tool(plugin.Name())
+	if !ok {
+		return fmt.Errorf("%w: %s", errNotLocked, plugin.Name())
+	}
+
+	if requestedVersion != "" && requestedVersion != tool.Version {
+		return fmt.Errorf("%w: %s %s requested, %s locked", errLockedVersionDrift, tool.Name, requestedVersion, tool.Version)
+	}
+
+	return installLockedTool(ctx, plugin, tool)
+}
+
+// installLockedTool downloads tool, verifies the download against the
+// lockfile and installs it.
+func installLockedTool(ctx context.Context, plugin asdf.Plugin, tool asdf.LockedTool) error {
+	if err := tool.CheckHashed(); err != nil {
+		return err
+	}
+
+	downloadPath, err := defaultDownloadPath(tool.Name, tool.Version)
+	if err != nil {
+		return err
+	}
+
+	installPath, err := defaultInstallPath(tool.Name, tool.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := cmdDownload(ctx, plugin, tool.Version, downloadPath); err != nil {
+		return err
+	}
+
+	if err := asdf.VerifyLockedTool(ctx, plugin, tool, downloadPath); err != nil {
+		return err
+	}
+
+	hash, err := getDownloadHash(downloadPath)
+	if err != nil {
+		return fmt.Errorf("calculating hash: %w", err)
+	}
+
+	if hash != tool.SHA256 {
+		return fmt.Errorf("%w for %s %s: locked %s, got %s", errChecksumMismatch, tool.Name, tool.Version, tool.SHA256, hash)
+	}
+
+	return cmdInstall(ctx, plugin, tool.Version, downloadPath, installPath, false, false)
+}
+
 // cmdGenerateToolSums generates checksums for all installed tools (internal command for selftest).
 func cmdGenerateToolSums() error {
 	toolVersionsPath := ".tool-versions"
@@ -1630,29 +3360,24 @@ func cmdGenerateToolSums() error {
 		return nil
 	}
 
-	asdfDataDir := os.Getenv("ASDF_DATA_DIR")
-	if asdfDataDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("getting home directory: %w", err)
-		}
-
-		asdfDataDir = filepath.Join(home, ".asdf")
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return err
 	}
 
 	sums := make(map[string]string)
 
 	for name, version := range versions {
-		if version == "nightly" || version == "latest" {
+		if _, latest := asdf.ParseLatestVersion(version); latest || version == "nightly" {
 			continue
 		}
 
-		installPath := filepath.Join(asdfDataDir, "installs", name, version)
+		installPath := layout.InstallPath(name, version)
 		if _, err := os.Stat(installPath); os.IsNotExist(err) {
 			continue
 		}
 
-		hash, err := calculateDirHash(installPath)
+		hash, err := calculateInstallHash(name, installPath)
 		if err != nil {
 			continue
 		}
@@ -1670,3 +3395,174 @@ func cmdGenerateToolSums() error {
 
 	return nil
 }
+
+// Install states printed by verify-installs.
+const (
+	installStateOK       = "OK"
+	installStateModified = "MODIFIED"
+	installStateMissing  = "MISSING"
+)
+
+// cmdVerifyInstalls implements the `verify-installs` subcommand. It hashes the
+// install of every tool version recorded in .tool-sums again and prints OK,
+// MODIFIED or MISSING for each, failing when any install was modified.
+func cmdVerifyInstalls(out io.Writer) error {
+	sums, err := readToolSums(toolSumsFile)
+	if err != nil {
+		return err
+	}
+
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return err
+	}
+
+	modified := 0
+
+	for _, key := range slices.Sorted(maps.Keys(sums)) {
+		name, version, _ := strings.Cut(key, ":")
+
+		state := installStateOK
+
+		installPath := layout.InstallPath(name, version)
+		if _, err := os.Stat(installPath); os.IsNotExist(err) {
+			state = installStateMissing
+		} else {
+			hash, err := calculateInstallHash(name, installPath)
+			if err != nil {
+				return fmt.Errorf("hashing %s %s: %w", name, version, err)
+			}
+
+			if hash != sums[key] {
+				state = installStateModified
+				modified++
+			}
+		}
+
+		_, _ = fmt.Fprintf(out, "%-8s %s %s\n", state, name, version)
+	}
+
+	if modified > 0 {
+		return fmt.Errorf("%w: %d of %d installs", errInstallsModified, modified, len(sums))
+	}
+
+	return nil
+}
+
+// cmdGC implements the `gc` subcommand. It prints the kind, size and path of
+// every entry options select for removal, and removes them unless dryRun.
+// The versions pinned in the tool versions files consulted from the working
+// directory or found below the home directory or projects, and those selected
+// through ASDF_<TOOL>_VERSION, are protected.
+func cmdGC(ctx context.Context, out io.Writer, options asdf.GCOptions, projects []string, dryRun bool) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("determining home directory: %w", err)
+	}
+
+	files := asdf.ToolVersionsFiles(cwd)
+
+	for _, project := range append([]string{home}, projects...) {
+		projectFiles, err := asdf.ProjectToolVersionsFiles(project)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, projectFiles...)
+	}
+
+	options.Protected = asdf.ReferencedToolVersions(files...)
+
+	selected, err := asdf.SelectedToolVersions()
+	if err != nil {
+		return fmt.Errorf("resolving tool versions: %w", err)
+	}
+
+	for tool, version := range selected {
+		if options.Protected[tool] == nil {
+			options.Protected[tool] = make(map[string]bool)
+		}
+
+		options.Protected[tool][version] = true
+	}
+
+	options.Now = time.Now()
+
+	entries, err := asdf.PlanGC(options)
+	if err != nil {
+		return err
+	}
+
+	var planned int64
+
+	for _, entry := range entries {
+		planned += entry.Size
+
+		_, _ = fmt.Fprintf(out, "%-8s %10s %s\n", entry.Kind, asdf.FormatBytes(entry.Size), entry.Path)
+	}
+
+	if dryRun {
+		_, _ = fmt.Fprintf(out, "Would reclaim %s from %d entries\n", asdf.FormatBytes(planned), len(entries))
+
+		return nil
+	}
+
+	reclaimed, err := asdf.RemoveGCEntries(ctx, entries)
+
+	_, _ = fmt.Fprintf(out, "Reclaimed %s\n", asdf.FormatBytes(reclaimed))
+
+	return err
+}
+
+// cmdSBOM implements the `sbom` subcommand. It prints a CycloneDX JSON
+// document with a component for every complete install, carrying the install
+// checksum recorded in .tool-sums and the URLs the plugin downloads from.
+func cmdSBOM(ctx context.Context, out io.Writer) error {
+	sums, err := readToolSums(toolSumsFile)
+	if err != nil {
+		return err
+	}
+
+	tools, err := asdf.InstalledTools()
+	if err != nil {
+		return err
+	}
+
+	var components []asdf.SBOMComponent
+
+	for _, tool := range tools {
+		// Tools installed by other asdf plugins are still listed, as pkg:generic.
+		var binPaths []string
+
+		plugin, err := plugins.GetPlugin(tool)
+		if err == nil {
+			binPaths = strings.Fields(plugin.ListBinPaths())
+		}
+
+		installed, err := asdf.ListInstalled(tool, "", binPaths)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range installed {
+			if version.Incomplete {
+				continue
+			}
+
+			component, err := asdf.SBOMComponentOf(ctx, plugin, tool, version.Version, version.Path,
+				sums[tool+":"+version.Version])
+			if err != nil {
+				return err
+			}
+
+			components = append(components, component)
+		}
+	}
+
+	return asdf.NewSBOM(components).Write(out)
+}
@@ -0,0 +1,1452 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// TestReorderFlags verifies flags move before positionals according to their definitions.
+func TestReorderFlags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     string
+		expected string
+	}{
+		{name: "program only", args: "uap", expected: "uap"},
+		{name: "command only", args: "uap plugins", expected: "uap plugins"},
+		{name: "unknown command", args: "uap nope x --locked", expected: "uap nope --locked x"},
+		{name: "help command", args: "uap help kubectl --verbose", expected: "uap --verbose help kubectl"},
+		{
+			name:     "app boolean flag after command",
+			args:     "uap install --verbose golang 1.22",
+			expected: "uap --verbose install golang 1.22",
+		},
+		{
+			name:     "app flag alias after positionals",
+			args:     "uap install golang 1.22 -V",
+			expected: "uap -V install golang 1.22",
+		},
+		{
+			name:     "command boolean flag does not consume the next argument",
+			args:     "uap install --locked golang",
+			expected: "uap install --locked golang",
+		},
+		{
+			name:     "command boolean flag after positionals",
+			args:     "uap install golang --locked",
+			expected: "uap install --locked golang",
+		},
+		{
+			name:     "command value flag after positionals",
+			args:     "uap install golang --version 1.22",
+			expected: "uap install --version 1.22 golang",
+		},
+		{
+			name:     "flag with inline value",
+			args:     "uap latest-stable golang --query=1.22 extra",
+			expected: "uap latest-stable --query=1.22 golang extra",
+		},
+		{
+			name:     "value flag consumes a dash-prefixed value",
+			args:     "uap latest-stable golang -q -rc",
+			expected: "uap latest-stable -q -rc golang",
+		},
+		{
+			name:     "repeated flags keep their order",
+			args:     "uap latest-stable x --query 1 y --query 2",
+			expected: "uap latest-stable --query 1 --query 2 x y",
+		},
+		{
+			name:     "app value flag before command",
+			args:     "uap --plugin golang install 1.22 --offline",
+			expected: "uap --plugin golang --offline install 1.22",
+		},
+		{
+			name:     "terminator after command",
+			args:     "uap install -- --locked golang",
+			expected: "uap install -- --locked golang",
+		},
+		{
+			name:     "terminator after positionals",
+			args:     "uap install golang --verbose -- kubectl --version get pods",
+			expected: "uap --verbose install golang -- kubectl --version get pods",
+		},
+		{
+			name:     "terminator before command",
+			args:     "uap -- install --locked",
+			expected: "uap -- install --locked",
+		},
+		{
+			name:     "subcommand flags",
+			args:     "uap locks break stale --verbose",
+			expected: "uap --verbose locks break stale",
+		},
+		{
+			name:     "dash is a positional",
+			args:     "uap install - --locked",
+			expected: "uap install --locked -",
+		},
+		{
+			name:     "trailing value flag without value",
+			args:     "uap install golang --version",
+			expected: "uap install --version golang",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reordered := reorderFlags(newCLIApp(), strings.Fields(tt.args))
+			require.Equal(t, strings.Fields(tt.expected), reordered)
+		})
+	}
+}
+
+// TestParseToolVersionEntries verifies versions keep their raw text next to the expanded value.
+func TestParseToolVersionEntries(t *testing.T) {
+	t.Setenv(asdf.ExpandToolVersionsEnv, "1")
+	t.Setenv("UAP_TEST_TF_VERSION", "")
+
+	path := filepath.Join(t.TempDir(), ".tool-versions")
+	require.NoError(t, os.WriteFile(path,
+		[]byte("# tools\nterraform ${UAP_TEST_TF_VERSION:-1.7.5}\njq 1.7.1\npython 3.12.1 3.11.7 # both\n"), 0o600))
+
+	entries, err := parseToolVersionEntries(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]toolVersionEntry{
+		"terraform": {Raw: "${UAP_TEST_TF_VERSION:-1.7.5}", Version: "1.7.5", Versions: []string{"1.7.5"}},
+		"jq":        {Raw: "1.7.1", Version: "1.7.1", Versions: []string{"1.7.1"}},
+		"python":    {Raw: "3.12.1", Version: "3.12.1", Versions: []string{"3.12.1", "3.11.7"}, Comment: "both"},
+	}, entries)
+
+	require.NoError(t, writeToolVersions(path, map[string]toolVersionEntry{
+		"terraform": entries["terraform"], "jq": {Raw: "1.8.0", Version: "1.8.0"},
+	}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t,
+		"# tools\nterraform ${UAP_TEST_TF_VERSION:-1.7.5}\njq 1.8.0\npython 3.12.1 3.11.7 # both\n", string(data))
+
+	require.NoError(t, os.WriteFile(path, []byte("jq 1.7.1\n\nterraform ${UAP_TEST_UNSET_VERSION}\n"), 0o600))
+
+	_, err = parseToolVersionEntries(path)
+	require.EqualError(t, err,
+		path+`:3: "terraform ${UAP_TEST_UNSET_VERSION}": undefined variable: UAP_TEST_UNSET_VERSION`)
+}
+
+// TestParseWindowsToolFiles verifies .tool-versions and .tool-sums files
+// saved with a UTF-8 BOM, CRLF line endings and trailing spaces parse like
+// plain ones.
+func TestParseWindowsToolFiles(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".tool-versions")
+	require.NoError(t, os.WriteFile(path, []byte("\ufeffgolang 1.22.5  \r\n# tools\r\n\r\nzig 0.14.0\r\n"), 0o600))
+
+	versions, err := parseToolVersions(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"golang": "1.22.5", "zig": "0.14.0"}, versions)
+
+	sums, err := parseToolSumsFromReader(strings.NewReader("\ufeffgolang 1.22.5 sha256:abc\r\nzig 0.14.0 sha256:def \r\n"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"golang:1.22.5": "sha256:abc", "zig:0.14.0": "sha256:def"}, sums)
+}
+
+// TestCompletionCandidates verifies the __complete entry point completes commands, flags, plugins and installs.
+func TestCompletionCandidates(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+
+	for _, version := range []string{"1.21.0", "1.22.1"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "installs", "golang", version), asdf.CommonDirectoryPermission))
+	}
+
+	app := newCLIApp()
+
+	tests := []struct {
+		name     string
+		words    string
+		expected []string
+	}{
+		{name: "commands", words: "uninst", expected: []string{"uninstall"}},
+		{name: "commands after app flag", words: "--verbose install-", expected: []string{"install-plugin"}},
+		{name: "hidden command", words: "__comp", expected: nil},
+		{name: "app flags", words: "--off", expected: []string{"--offline"}},
+		{name: "shared flag listed once", words: "install --plu", expected: []string{"--plugin"}},
+		{name: "command flags", words: "diff-versions --", expected: []string{"--plugin", "--verbose", "--offline", "--json", "--bin-only"}},
+		{name: "subcommands", words: "locks b", expected: []string{"break"}},
+		{name: "plugin flag value", words: "install -p kubec", expected: []string{"kubectl", "kubectx"}},
+		{name: "plugin argument", words: "list-all kubec", expected: []string{"kubectl", "kubectx"}},
+		{name: "tool argument from usage", words: "changelog kubec", expected: []string{"kubectl", "kubectx"}},
+		{name: "installed versions", words: "uninstall golang ", expected: []string{"1.22.1", "1.21.0"}},
+		{name: "installed versions after plugin flag", words: "uninstall --plugin=golang 1.21", expected: []string{"1.21.0"}},
+		{name: "installed versions for alias", words: "diff-versions go 1.22.1 ", expected: []string{"1.22.1", "1.21.0"}},
+		{name: "version flag value", words: "which -p golang --version ", expected: []string{"1.22.1", "1.21.0"}},
+		{name: "versions of other commands", words: "install golang ", expected: nil},
+		{name: "no installs", words: "uninstall kubectl ", expected: nil},
+		{name: "unknown command", words: "nope ", expected: nil},
+		{name: "value of other flag", words: "history --operation ", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words := strings.Split(tt.words, " ")
+			candidates := completionCandidates(app, words)
+			if len(tt.expected) == 0 {
+				require.Empty(t, candidates)
+
+				return
+			}
+
+			require.Equal(t, tt.expected, candidates)
+		})
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, cmdComplete(&out, app, []string{"--", "uninstall", "golang", "1.22"}))
+	require.Equal(t, "1.22.1\n", out.String())
+}
+
+// TestRefInstallType verifies ref installs are accepted from ASDF_INSTALL_TYPE
+// and the positional "<type> <version> <path>" form, and refused by plugins
+// that only install released versions.
+func TestRefInstallType(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+
+	downloadPath := t.TempDir()
+
+	require.NoError(t, newCLIApp().Run([]string{"uap", "download", "ginkgo", "ref", "master", downloadPath}))
+	require.NoError(t, newCLIApp().Run([]string{"uap", "download", "ginkgo", "ref:master", downloadPath}))
+
+	err := newCLIApp().Run([]string{"uap", "install", "kubectl", "ref:master"})
+	require.ErrorContains(t, err, "ref installs not supported: kubectl")
+
+	err = newCLIApp().Run([]string{"uap", "download", "kubectl", "ref", "master", downloadPath})
+	require.ErrorContains(t, err, "ref installs not supported: kubectl")
+
+	t.Setenv(asdf.InstallTypeEnv, asdf.InstallTypeRef)
+
+	err = newCLIApp().Run([]string{"uap", "install", "kubectl", "master"})
+	require.ErrorContains(t, err, "ref installs not supported: kubectl")
+
+	t.Setenv(asdf.InstallTypeEnv, "tarball")
+
+	err = newCLIApp().Run([]string{"uap", "download", "kubectl", "1.30.0", downloadPath})
+	require.ErrorContains(t, err, `unknown install type: "tarball"`)
+}
+
+// inPlacePlugin installs in place, see asdf.InPlaceInstaller.
+type inPlacePlugin struct {
+	*asdf.SourceBuildPlugin
+}
+
+func (inPlacePlugin) InstallsInPlace() bool {
+	return true
+}
+
+// TestCmdInstallForce verifies --force empties the install path of plugins
+// installing in place, and --force-download the download path too, before
+// the plugin installs.
+func TestCmdInstallForce(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+	t.Chdir(t.TempDir())
+
+	plugin := inPlacePlugin{asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		Name:         "tool",
+		SkipDownload: true,
+		SkipExtract:  true,
+		BuildVersion: func(_ context.Context, version, _, installPath string) error {
+			return os.WriteFile(filepath.Join(installPath, "tool"), []byte(version), asdf.CommonExecutablePermission)
+		},
+	})}
+
+	downloadPath, installPath := t.TempDir(), t.TempDir()
+	stale := filepath.Join(installPath, "crashed.partial")
+	cached := filepath.Join(downloadPath, "tool.tar.gz")
+
+	for _, path := range []string{stale, cached} {
+		require.NoError(t, os.WriteFile(path, nil, asdf.CommonFilePermission))
+	}
+
+	require.NoError(t, cmdInstall(t.Context(), plugin, "1.0.0", downloadPath, installPath, false, false))
+	require.FileExists(t, stale)
+
+	require.NoError(t, cmdInstall(t.Context(), plugin, "1.0.0", downloadPath, installPath, true, false))
+	require.NoFileExists(t, stale)
+	require.FileExists(t, filepath.Join(installPath, "tool"))
+	require.FileExists(t, cached)
+
+	require.NoError(t, cmdInstall(t.Context(), plugin, "1.0.0", downloadPath, installPath, false, true))
+	require.NoFileExists(t, cached)
+	require.DirExists(t, downloadPath)
+	require.FileExists(t, filepath.Join(installPath, "tool"))
+}
+
+// TestCmdInstallStaged verifies installs are staged and renamed into place:
+// a failing install keeps the previous one, and the leftovers of a crashed
+// install are removed by the next attempt.
+func TestCmdInstallStaged(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+	t.Chdir(t.TempDir())
+
+	var failure error
+
+	plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		Name:         "tool",
+		SkipDownload: true,
+		SkipExtract:  true,
+		BuildVersion: func(_ context.Context, version, _, installPath string) error {
+			if err := os.WriteFile(filepath.Join(installPath, "tool"), []byte(version), asdf.CommonExecutablePermission); err != nil {
+				return err
+			}
+
+			return failure
+		},
+	})
+
+	dir := t.TempDir()
+	installPath := filepath.Join(dir, "1.0.0")
+	crashed := filepath.Join(dir, "1.0.0.staging-123")
+	require.NoError(t, os.MkdirAll(filepath.Join(installPath, "old"), asdf.CommonDirectoryPermission))
+	require.NoError(t, os.MkdirAll(crashed, asdf.CommonDirectoryPermission))
+
+	require.NoError(t, cmdInstall(t.Context(), plugin, "1.0.0", t.TempDir(), installPath, false, false))
+	require.FileExists(t, filepath.Join(installPath, "tool"))
+	require.NoDirExists(t, filepath.Join(installPath, "old"))
+	require.NoDirExists(t, crashed)
+
+	failure = errors.New("build failed")
+	require.ErrorIs(t, cmdInstall(t.Context(), plugin, "1.0.0", t.TempDir(), installPath, false, false), failure)
+	require.FileExists(t, filepath.Join(installPath, "tool"))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+// TestCmdInstallRemovesInstallOnFullDisk verifies an install failing because
+// the filesystem filled up leaves no partial install behind.
+func TestCmdInstallRemovesInstallOnFullDisk(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+	t.Chdir(t.TempDir())
+
+	plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		Name:         "tool",
+		SkipDownload: true,
+		SkipExtract:  true,
+		BuildVersion: func(_ context.Context, _, _, installPath string) error {
+			partial := filepath.Join(installPath, "lib", "libtool.a")
+			if err := os.MkdirAll(filepath.Dir(partial), asdf.CommonDirectoryPermission); err != nil {
+				return err
+			}
+
+			return fmt.Errorf("extracting archive: %w", &os.PathError{Op: "write", Path: partial, Err: syscall.ENOSPC})
+		},
+	})
+
+	installPath := filepath.Join(t.TempDir(), "1.0.0")
+
+	err := cmdInstall(t.Context(), plugin, "1.0.0", t.TempDir(), installPath, false, false)
+	require.ErrorIs(t, err, syscall.ENOSPC)
+	require.NoDirExists(t, installPath)
+}
+
+// TestResolveToolVersionLegacyFile verifies .nvmrc selects the Node.js version
+// only with legacy_version_file = yes and yields to .tool-versions.
+func TestResolveToolVersionLegacyFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(asdf.ToolVersionEnv("nodejs"), "")
+	t.Setenv(asdf.ConfigFileEnv, filepath.Join(dir, ".asdfrc"))
+	require.NoError(t, os.WriteFile(".nvmrc", []byte("v20.11.0\n"), asdf.CommonFilePermission))
+
+	version, err := resolveToolVersion(t.Context(), "nodejs")
+	require.NoError(t, err)
+	require.Empty(t, version)
+
+	require.NoError(t, os.WriteFile(".asdfrc", []byte("legacy_version_file = yes\n"), asdf.CommonFilePermission))
+
+	version, err = resolveToolVersion(t.Context(), "nodejs")
+	require.NoError(t, err)
+	require.Equal(t, "20.11.0", version)
+
+	require.NoError(t, os.WriteFile(".tool-versions", []byte("nodejs 22.1.0\n"), asdf.CommonFilePermission))
+
+	version, err = resolveToolVersion(t.Context(), "nodejs")
+	require.NoError(t, err)
+	require.Equal(t, "22.1.0", version)
+}
+
+// TestCmdCompletion verifies every shell script calls back into __complete.
+func TestCmdCompletion(t *testing.T) {
+	t.Parallel()
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var out bytes.Buffer
+		require.NoError(t, cmdCompletion(&out, "universal-asdf-plugin", shell))
+		require.Contains(t, out.String(), "universal-asdf-plugin "+completeCommandName+" -- ")
+		require.Contains(t, out.String(), "_universal_asdf_plugin")
+	}
+
+	require.ErrorIs(t, cmdCompletion(&bytes.Buffer{}, "universal-asdf-plugin", "tcsh"), errCompletionShellUnsupported)
+}
+
+// TestCmdWhere verifies where prints install paths and rejects missing or incomplete installs.
+func TestCmdWhere(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+
+	binaries := map[string]string{
+		"jq/1.7.1":       "bin/jq",
+		"gcloud/500.0.0": "google-cloud-sdk/bin/gcloud",
+		"jq/1.6":         "",
+	}
+	for install, binary := range binaries {
+		installPath := filepath.Join(dataDir, "installs", install)
+		require.NoError(t, os.MkdirAll(installPath, asdf.CommonDirectoryPermission))
+
+		if binary != "" {
+			binaryPath := filepath.Join(installPath, binary)
+			require.NoError(t, os.MkdirAll(filepath.Dir(binaryPath), asdf.CommonDirectoryPermission))
+			require.NoError(t, os.WriteFile(binaryPath, nil, 0o755))
+		}
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, cmdWhere(t.Context(), &out, "jq", "1.7.1"))
+	require.Equal(t, filepath.Join(dataDir, "installs", "jq", "1.7.1")+"\n", out.String())
+
+	out.Reset()
+	require.NoError(t, cmdWhere(t.Context(), &out, "gcloud", "500.0.0"))
+	require.Equal(t, filepath.Join(dataDir, "installs", "gcloud", "500.0.0", "google-cloud-sdk")+"\n", out.String())
+
+	require.ErrorIs(t, cmdWhere(t.Context(), &out, "jq", "1.6"), errVersionNotInstalled)
+	require.ErrorIs(t, cmdWhere(t.Context(), &out, "jq", "1.5"), errVersionNotInstalled)
+}
+
+// envMarkerRe matches the marker line of the env output.
+var envMarkerRe = regexp.MustCompile(`(?m)^# asdf-env: [0-9a-f]{64}$`)
+
+// TestCmdEnv compares the env output for bash and fish with golden files,
+// the data directory replaced by /data and the hash by <hash>, and verifies
+// the hash covers the output and only changes with it.
+func TestCmdEnv(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GOROOT", "")
+	t.Setenv("GOPATH", "")
+	t.Setenv("GOBIN", "")
+	t.Setenv("ASDF_GOLANG_SET_GOBIN", "")
+
+	for _, binary := range []string{"golang/1.23.4/go/bin/go", "jq/1.7.1/bin/jq", "jq/1.8.0/bin/jq"} {
+		path := filepath.Join(dataDir, "installs", filepath.FromSlash(binary))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(path, nil, asdf.CommonExecutablePermission))
+	}
+
+	goldenDir, err := filepath.Abs(filepath.Join("testdata", "env"))
+	require.NoError(t, err)
+
+	project := t.TempDir()
+	t.Chdir(project)
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".tool-versions"),
+		[]byte("terraform 1.7.5\njq 1.7.1  # pinned\ngolang 1.23.4\nnosuchtool 1.0\n"), 0o600))
+
+	render := func(shell string, tools ...string) string {
+		t.Helper()
+
+		var out bytes.Buffer
+		require.NoError(t, cmdEnv(t.Context(), &out, tools, shell))
+
+		return out.String()
+	}
+
+	for _, shell := range []string{"bash", "fish"} {
+		output := render(shell)
+
+		golden, err := os.ReadFile(filepath.Join(goldenDir, shell+".golden"))
+		require.NoError(t, err)
+
+		normalized := envMarkerRe.ReplaceAllString(strings.ReplaceAll(output, dataDir, "/data"), "# asdf-env: <hash>")
+		require.Equal(t, string(golden), normalized, shell)
+
+		marker, body, _ := strings.Cut(output, "\n")
+		sum := sha256.Sum256([]byte(strings.TrimSuffix(body, "\n")))
+		require.Equal(t, "# asdf-env: "+hex.EncodeToString(sum[:]), marker)
+		require.Equal(t, output, render(shell, "nosuchtool", "terraform", "jq", "golang", "jq"))
+	}
+
+	before := render("bash")
+
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".tool-versions"), []byte("jq 1.8.0\n"), 0o600))
+	require.NotEqual(t, strings.SplitN(before, "\n", 2)[0], strings.SplitN(render("bash"), "\n", 2)[0])
+
+	require.ErrorIs(t, cmdEnv(t.Context(), &bytes.Buffer{}, nil, "env"), errExecEnvShellUnsupported)
+}
+
+// TestCmdCompletionsPath verifies the completions directory of an install
+// is printed only when the release shipped completions.
+func TestCmdCompletionsPath(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+
+	for _, file := range []string{"k9s/0.32.5/bin/k9s", "k9s/0.32.5/share/completions/k9s.bash", "k9s/0.32.4/bin/k9s"} {
+		path := filepath.Join(dataDir, "installs", filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(path, nil, asdf.CommonExecutablePermission))
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, cmdCompletionsPath(t.Context(), &out, "k9s", "0.32.5"))
+	require.Equal(t, filepath.Join(dataDir, "installs", "k9s", "0.32.5", "share", "completions")+"\n", out.String())
+
+	require.ErrorIs(t, cmdCompletionsPath(t.Context(), &out, "k9s", "0.32.4"), errNoCompletions)
+	require.ErrorIs(t, cmdCompletionsPath(t.Context(), &out, "k9s", "0.32.3"), errVersionNotInstalled)
+}
+
+// TestCmdMetadata verifies install records the requested version and the
+// installer build in the metadata a plugin writes, which metadata prints.
+func TestCmdMetadata(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+	t.Chdir(t.TempDir())
+
+	plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		Name:         "cosign",
+		SkipDownload: true,
+		SkipExtract:  true,
+		BuildVersion: func(ctx context.Context, version, _, installPath string) error {
+			if err := os.MkdirAll(filepath.Join(installPath, "bin"), asdf.CommonDirectoryPermission); err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(filepath.Join(installPath, "bin", "cosign"), nil, asdf.CommonExecutablePermission); err != nil {
+				return err
+			}
+
+			return asdf.WriteInstallMetadata(ctx, installPath, asdf.InstallMetadata{Plugin: "cosign", Version: version})
+		},
+	})
+
+	ctx := asdf.WithInstallRequest(t.Context(), asdf.InstallRequest{RequestedVersion: "latest"})
+	installPath := filepath.Join(dataDir, "installs", "cosign", "2.4.1")
+	require.NoError(t, cmdInstall(ctx, plugin, "2.4.1", t.TempDir(), installPath, false, false))
+
+	var out bytes.Buffer
+	require.NoError(t, cmdMetadata(t.Context(), &out, "cosign", "2.4.1"))
+
+	var metadata asdf.InstallMetadata
+	require.NoError(t, json.Unmarshal(out.Bytes(), &metadata))
+	require.Equal(t, asdf.InstallMetadata{
+		Plugin:           "cosign",
+		RequestedVersion: "latest",
+		Version:          "2.4.1",
+		InstallerVersion: version,
+		InstallerCommit:  commit,
+	}, metadata)
+
+	require.NoError(t, os.Remove(filepath.Join(installPath, asdf.InstallMetadataFileName)))
+	require.ErrorIs(t, cmdMetadata(t.Context(), &out, "cosign", "2.4.1"), errNoInstallMetadata)
+	require.ErrorIs(t, cmdMetadata(t.Context(), &out, "cosign", "2.4.0"), errVersionNotInstalled)
+}
+
+// TestFindExecutable verifies which prefers the executable named after the tool or its binary.
+func TestFindExecutable(t *testing.T) {
+	installPath := t.TempDir()
+	binDir := filepath.Join(installPath, "google-cloud-sdk", "bin")
+	require.NoError(t, os.MkdirAll(binDir, asdf.CommonDirectoryPermission))
+
+	for _, name := range []string{"bq", "gcloud", "gh.exe", "gsutil"} {
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, name), nil, asdf.CommonExecutablePermission))
+	}
+
+	gcloud, err := plugins.GetPlugin("gcloud")
+	require.NoError(t, err)
+
+	path, err := findExecutable(gcloud, installPath, "")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(binDir, "gcloud"), path)
+
+	path, err = findExecutable(gcloud, installPath, "gsutil")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(binDir, "gsutil"), path)
+
+	_, err = findExecutable(gcloud, installPath, "gcutil")
+	require.ErrorIs(t, err, errNoExecutableFound)
+
+	ghInstall := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(ghInstall, "bin"), asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(filepath.Join(ghInstall, "bin", "README"), nil, asdf.CommonFilePermission))
+	require.NoError(t, os.WriteFile(filepath.Join(ghInstall, "bin", "gh.exe"), nil, asdf.CommonExecutablePermission))
+
+	githubCLI, err := plugins.GetPlugin("github-cli")
+	require.NoError(t, err)
+
+	path, err = findExecutable(githubCLI, ghInstall, "")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(ghInstall, "bin", "gh.exe"), path)
+
+	jq, err := plugins.GetPlugin("jq")
+	require.NoError(t, err)
+
+	path, err = findExecutable(jq, ghInstall, "")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(ghInstall, "bin", "gh.exe"), path, "falls back to the first executable")
+
+	_, err = findExecutable(jq, t.TempDir(), "")
+	require.ErrorIs(t, err, errNoExecutableFound)
+}
+
+// TestCmdVerifyInstalls verifies installs are checked against .tool-sums, ignoring the files their plugin excludes.
+func TestCmdVerifyInstalls(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+	t.Chdir(t.TempDir())
+
+	files := map[string]string{
+		"python/3.12.0/bin/python3": "python",
+		"jq/1.7.1/bin/jq":           "jq",
+	}
+	for path, content := range files {
+		path = filepath.Join(dataDir, "installs", path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(path, []byte(content), asdf.CommonExecutablePermission))
+	}
+
+	require.NoError(t, os.WriteFile(".tool-versions", []byte("python 3.12.0\njq 1.7.1\n"), 0o600))
+	require.NoError(t, cmdGenerateToolSums())
+
+	sums, err := readToolSums(toolSumsFile)
+	require.NoError(t, err)
+	require.Len(t, sums, 2)
+
+	sums["helm:3.14.0"] = "sha256:00"
+	require.NoError(t, writeToolSums(toolSumsFile, sums))
+
+	var out bytes.Buffer
+	require.NoError(t, cmdVerifyInstalls(&out))
+	require.Equal(t, "MISSING  helm 3.14.0\nOK       jq 1.7.1\nOK       python 3.12.0\n", out.String())
+
+	pycache := filepath.Join(dataDir, "installs", "python", "3.12.0", "lib", "__pycache__", "site.cpython-312.pyc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(pycache), asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(pycache, []byte("bytecode"), asdf.CommonFilePermission))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "installs", "jq", "1.7.1", "bin", "jq"), []byte("patched"),
+		asdf.CommonExecutablePermission))
+
+	out.Reset()
+	require.ErrorIs(t, cmdVerifyInstalls(&out), errInstallsModified)
+	require.Equal(t, "MISSING  helm 3.14.0\nMODIFIED jq 1.7.1\nOK       python 3.12.0\n", out.String())
+}
+
+// TestReshimPipxApps verifies apps installed with pipx get shims and are found by which.
+func TestReshimPipxApps(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+	t.Chdir(t.TempDir())
+
+	installPath := filepath.Join(dataDir, "installs", "pipx", "1.7.1")
+	venvBin := filepath.Join(installPath, "apps", "venvs", "black", "bin")
+	appsBin := filepath.Join(installPath, "apps", "bin")
+
+	for _, dir := range []string{filepath.Join(installPath, "bin"), venvBin, appsBin} {
+		require.NoError(t, os.MkdirAll(dir, asdf.CommonDirectoryPermission))
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(installPath, "bin", "pipx"), nil, asdf.CommonExecutablePermission))
+	require.NoError(t, os.WriteFile(filepath.Join(venvBin, "black"), nil, asdf.CommonExecutablePermission))
+	require.NoError(t, os.Symlink(filepath.Join(venvBin, "black"), filepath.Join(appsBin, "black")))
+	require.NoError(t, os.WriteFile(".tool-versions", []byte("pipx 1.7.1\n"), 0o600))
+
+	pipx, err := plugins.GetPlugin("pipx")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"PIPX_HOME":    filepath.Join(installPath, "apps"),
+		"PIPX_BIN_DIR": appsBin,
+	}, pipx.ExecEnv(installPath))
+
+	require.NoError(t, cmdReshim())
+
+	for _, name := range []string{"pipx", "black"} {
+		require.FileExists(t, filepath.Join(dataDir, "shims", name))
+	}
+
+	target, err := os.Readlink(filepath.Join(dataDir, "shims", "black"))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(appsBin, "black"), target)
+
+	path, err := findExecutable(pipx, installPath, "black")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(appsBin, "black"), path)
+
+	path, err = findExecutable(pipx, installPath, "")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(installPath, "bin", "pipx"), path)
+}
+
+// TestReshimAndWhichAgreeInNestedDirectories verifies reshim links the version
+// which resolves from a parent .tool-versions or ASDF_<TOOL>_VERSION.
+func TestReshimAndWhichAgreeInNestedDirectories(t *testing.T) {
+	dataDir, root := t.TempDir(), t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(asdf.ToolVersionsFilenameEnv, "")
+	t.Setenv(asdf.ToolVersionEnv("jq"), "")
+
+	for _, version := range []string{"1.7.1", "1.8.0"} {
+		binDir := filepath.Join(dataDir, "installs", "jq", version, "bin")
+		require.NoError(t, os.MkdirAll(binDir, asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, "jq"), nil, asdf.CommonExecutablePermission))
+	}
+
+	nested := filepath.Join(root, "services", "api")
+	require.NoError(t, os.MkdirAll(nested, asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".tool-versions"), []byte("jq 1.7.1\n"), asdf.CommonFilePermission))
+	t.Chdir(nested)
+
+	for _, version := range []string{"1.7.1", "1.8.0"} {
+		if version == "1.8.0" {
+			t.Setenv(asdf.ToolVersionEnv("jq"), version)
+		}
+
+		resolved, err := resolveToolVersion(t.Context(), "jq")
+		require.NoError(t, err)
+		require.Equal(t, version, resolved)
+
+		require.NoError(t, cmdReshim())
+
+		target, err := os.Readlink(filepath.Join(dataDir, "shims", "jq"))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dataDir, "installs", "jq", version, "bin", "jq"), target)
+	}
+}
+
+// TestReshimSystem verifies a "system" pin shims the host binaries of the tool and its installs.
+func TestReshimSystem(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+
+	binDir := filepath.Join(dataDir, "installs", "golang", "1.22.0", "go", "bin")
+	require.NoError(t, os.MkdirAll(binDir, asdf.CommonDirectoryPermission))
+
+	hostDir := t.TempDir()
+	for _, name := range []string{"go", "gofmt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, name), nil, asdf.CommonExecutablePermission))
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, "go"), nil, asdf.CommonExecutablePermission))
+	t.Setenv("PATH", hostDir)
+
+	plugin, err := plugins.GetPlugin("golang")
+	require.NoError(t, err)
+	require.Equal(t, []string{"golang", "go", "gofmt"}, systemExecutableNames(plugin))
+
+	path, err := systemExecutable(plugin)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(hostDir, "go"), path)
+
+	shimsDir := t.TempDir()
+	require.Equal(t, 1, reshimSystem(shimsDir, "golang"))
+
+	target, err := os.Readlink(filepath.Join(shimsDir, "go"))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(hostDir, "go"), target)
+}
+
+// TestCmdUpdateToolVersionsPinComment verifies auto-managed pins are re-resolved in place and dry runs write nothing.
+func TestCmdUpdateToolVersionsPinComment(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+	t.Setenv(asdf.OfflineEnv, "1")
+	require.NoError(t, asdf.RecordVersionCatalog("jq", []string{"1.7.1", "1.8.0"}))
+
+	path := filepath.Join(t.TempDir(), ".tool-versions")
+	require.NoError(t, os.WriteFile(path, []byte("jq latest\nterraform 1.7.5  # pinned for CI\n"), 0o600))
+
+	var out bytes.Buffer
+	require.NoError(t, cmdUpdateToolVersions(&out, path, true, false))
+
+	today := time.Now().Format(time.DateOnly)
+	pinned := "jq 1.8.0  # auto: was latest, updated " + today + "\nterraform 1.7.5  # pinned for CI\n"
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, pinned, string(data))
+
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(pinned, today, "2025-06-01", 1)), 0o600))
+
+	out.Reset()
+	require.NoError(t, cmdUpdateToolVersions(&out, path, true, false))
+	require.Contains(t, out.String(), "Updated: 0, Unchanged: 2, Failed: 0")
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, strings.Replace(pinned, today, "2025-06-01", 1), string(data))
+
+	require.NoError(t, asdf.RecordVersionCatalog("jq", []string{"1.7.1", "1.8.0", "1.8.1"}))
+
+	out.Reset()
+	require.NoError(t, cmdUpdateToolVersions(&out, path, true, true))
+	require.Contains(t, out.String(), "would write: jq 1.8.1  # auto: was latest, updated "+today)
+	require.Contains(t, out.String(), "Would update: 1, Unchanged: 1, Failed: 0")
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, strings.Replace(pinned, today, "2025-06-01", 1), string(data))
+
+	require.NoError(t, cmdUpdateToolVersions(&bytes.Buffer{}, path, true, false))
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, strings.Replace(pinned, "1.8.0", "1.8.1", 1), string(data))
+}
+
+// TestCmdUpdateToolVersionsInPlace verifies update-tool-versions rewrites the
+// resolved lines only, keeping the order, comments, blank lines, fallback
+// versions and line endings of the rest of the file.
+func TestCmdUpdateToolVersionsInPlace(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+	t.Setenv(asdf.OfflineEnv, "1")
+	require.NoError(t, asdf.RecordVersionCatalog("jq", []string{"1.7.1", "1.8.0"}))
+
+	path := filepath.Join(t.TempDir(), ".tool-versions")
+	require.NoError(t, os.WriteFile(path, []byte("\ufeff# project tools\r\n"+
+		"terraform 1.7.5   # pinned for CI\r\n"+
+		"\r\n"+
+		"jq\tlatest 1.7.1 # fallback\r\n"+
+		"python 3.12.1 3.11.7\r\n"), 0o600))
+
+	require.NoError(t, cmdUpdateToolVersions(&bytes.Buffer{}, path, true, false))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "\ufeff# project tools\r\n"+
+		"terraform 1.7.5   # pinned for CI\r\n"+
+		"\r\n"+
+		"jq\t1.8.0 1.7.1 # auto: was latest, updated "+time.Now().Format(time.DateOnly)+"\r\n"+
+		"python 3.12.1 3.11.7\r\n", string(data))
+}
+
+// TestCmdPinInstalled verifies tools are pinned to their newest install,
+// keeping comments, and tools without installs are skipped.
+func TestCmdPinInstalled(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+
+	for _, install := range []string{"jq/1.7.1", "jq/1.10.0", "jq/1.8.0", "yq/4.44.1"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "installs", install, "bin"), asdf.CommonDirectoryPermission))
+	}
+
+	path := filepath.Join(t.TempDir(), ".tool-versions")
+	require.NoError(t, os.WriteFile(path, []byte("jq 1.7.1  # keep me\nterraform 1.7.5\n"), 0o600))
+
+	var out bytes.Buffer
+	require.NoError(t, cmdPinInstalled(&out, path, false, false))
+	require.Contains(t, out.String(), "jq                   1.7.1 -> 1.10.0")
+	require.Contains(t, out.String(), "terraform            1.7.5 (skipped: not installed)")
+	require.Contains(t, out.String(), "Pinned: 1, Unchanged: 0, Skipped: 1")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "jq 1.10.0  # keep me\nterraform 1.7.5\n", string(data))
+
+	out.Reset()
+
+	err = cmdPinInstalled(&out, path, true, true)
+	require.ErrorIs(t, err, errToolsNotInstalled)
+	require.Contains(t, out.String(), "yq                   (none) -> 4.44.1")
+	require.Contains(t, out.String(), "Pinned: 1, Unchanged: 1, Skipped: 1")
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "jq 1.10.0  # keep me\nterraform 1.7.5\nyq 4.44.1\n", string(data))
+
+	missing := filepath.Join(t.TempDir(), ".tool-versions")
+	require.Error(t, cmdPinInstalled(&bytes.Buffer{}, missing, false, false))
+	require.NoError(t, cmdPinInstalled(&bytes.Buffer{}, missing, true, true))
+
+	data, err = os.ReadFile(missing)
+	require.NoError(t, err)
+	require.Equal(t, "jq 1.10.0\nyq 4.44.1\n", string(data))
+}
+
+// TestCmdPinInstalledInPlace verifies pin-installed rewrites the pinned lines
+// only, keeping comments, blank lines, fallback versions and the tool order.
+func TestCmdPinInstalledInPlace(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+
+	for _, install := range []string{"jq/1.8.0", "python/3.12.4", "yq/4.44.1"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "installs", install, "bin"), asdf.CommonDirectoryPermission))
+	}
+
+	path := filepath.Join(t.TempDir(), ".tool-versions")
+	require.NoError(t, os.WriteFile(path, []byte("# runtimes\n"+
+		"python 3.12.1 3.11.7  # keep the fallback\n"+
+		"\n"+
+		"# tools\n"+
+		"terraform 1.7.5\n"+
+		"jq 1.7.1\n"), 0o600))
+
+	require.NoError(t, cmdPinInstalled(&bytes.Buffer{}, path, true, false))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "# runtimes\n"+
+		"python 3.12.4 3.11.7  # keep the fallback\n"+
+		"\n"+
+		"# tools\n"+
+		"terraform 1.7.5\n"+
+		"jq 1.8.0\n"+
+		"yq 4.44.1\n", string(data))
+}
+
+// TestCmdToolVersionsDiff verifies added, removed and changed pins, multi-version
+// lines and comments, the JSON form and the exit status.
+func TestCmdToolVersionsDiff(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.tool-versions")
+	newPath := filepath.Join(dir, "new.tool-versions")
+
+	require.NoError(t, os.WriteFile(oldPath,
+		[]byte("# tools\njq 1.7.1  # keep me\npython 3.12.1 3.11.7\nterraform 1.7.5\nyq 4.44.1\n"), 0o600))
+	require.NoError(t, os.WriteFile(newPath,
+		[]byte("jq 1.7.1\npython 3.12.1 3.11.8\nshellcheck 0.10.0\nterraform 1.8.0\n"), 0o600))
+
+	var out bytes.Buffer
+
+	err := cmdToolVersionsDiff(&out, oldPath, newPath, false)
+	require.ErrorIs(t, err, errToolVersionsDiffer)
+	require.Equal(t, "TOOL        CHANGE   OLD            NEW\n"+
+		"python      changed  3.12.1 3.11.7  3.12.1 3.11.8\n"+
+		"shellcheck  added    -              0.10.0\n"+
+		"terraform   changed  1.7.5          1.8.0\n"+
+		"yq          removed  4.44.1         -\n", out.String())
+
+	out.Reset()
+
+	require.ErrorIs(t, cmdToolVersionsDiff(&out, oldPath, newPath, true), errToolVersionsDiffer)
+
+	var changes []toolVersionsChange
+	require.NoError(t, json.Unmarshal(out.Bytes(), &changes))
+	require.Len(t, changes, 4)
+	require.Equal(t, toolVersionsChange{Tool: "shellcheck", Change: "added", New: "0.10.0"}, changes[1])
+
+	out.Reset()
+
+	require.NoError(t, cmdToolVersionsDiff(&out, oldPath, oldPath, false))
+	require.Equal(t, "No differences\n", out.String())
+
+	out.Reset()
+
+	require.NoError(t, cmdToolVersionsDiff(&out, oldPath, oldPath, true))
+	require.Equal(t, "[]\n", out.String())
+
+	require.Error(t, cmdToolVersionsDiff(&out, oldPath, filepath.Join(dir, "missing"), false))
+}
+
+// TestCmdToolVersionsDiffInstalled verifies pins are compared with the newest
+// installed version of each tool.
+func TestCmdToolVersionsDiffInstalled(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+
+	for _, install := range []string{"jq/1.7.1", "jq/1.10.0", "yq/4.44.1", "python/3.12.1", "node/22.1.0"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "installs", install, "bin"), asdf.CommonDirectoryPermission))
+	}
+
+	path := filepath.Join(t.TempDir(), ".tool-versions")
+	require.NoError(t, os.WriteFile(path,
+		[]byte("jq 1.7.1\nyq 4.44.1\npython 3.12.1 3.11.7\nterraform 1.7.5\nnode system\n"), 0o600))
+
+	var out bytes.Buffer
+
+	err := cmdToolVersionsDiffInstalled(&out, path, false)
+	require.ErrorIs(t, err, errToolVersionsDiffer)
+	require.Equal(t, "TOOL       CHANGE   OLD    NEW\n"+
+		"jq         changed  1.7.1  1.10.0\n"+
+		"terraform  removed  1.7.5  -\n", out.String())
+
+	require.NoError(t, os.WriteFile(path, []byte("jq 1.10.0\nyq 4.44.1\n"), 0o600))
+
+	out.Reset()
+
+	require.NoError(t, cmdToolVersionsDiffInstalled(&out, path, false))
+	require.Equal(t, "No differences\n", out.String())
+}
+
+// BenchmarkListBinPaths measures the CLI overhead of a list-bin-paths callback,
+// which asdf runs for every shim, excluding the exec of the binary itself.
+// It fails when an invocation takes more than listBinPathsBudget; it measured
+// about 0.09ms per invocation, and a whole process about 4ms including exec.
+func BenchmarkListBinPaths(b *testing.B) {
+	const listBinPathsBudget = 20 * time.Millisecond
+
+	devNull, err := os.Open(os.DevNull)
+	require.NoError(b, err)
+
+	defer devNull.Close()
+
+	stdout := os.Stdout
+	os.Stdout = devNull
+
+	defer func() { os.Stdout = stdout }()
+
+	for b.Loop() {
+		app := newCLIApp()
+		if err := app.Run(reorderFlags(app, []string{"universal-asdf-plugin", "list-bin-paths", "golang"})); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	if perOp := b.Elapsed() / time.Duration(b.N); perOp > listBinPathsBudget {
+		b.Errorf("list-bin-paths took %s per invocation, over the %s budget", perOp, listBinPathsBudget)
+	}
+}
+
+// TestExecEnvFormats verifies every exec-env shell quotes quotes, newlines and dollar signs literally.
+func TestExecEnvFormats(t *testing.T) {
+	t.Parallel()
+
+	const value = "it's \"$HOME\"\n\\n"
+
+	tests := map[string]string{
+		"bash": `export KEY='it'\''s "$HOME"` + "\n" + `\n'`,
+		"zsh":  `export KEY='it'\''s "$HOME"` + "\n" + `\n'`,
+		"fish": `set -gx KEY 'it\'s "$HOME"` + "\n" + `\\n'`,
+		"pwsh": `$env:KEY = 'it''s "$HOME"` + "\n" + `\n'`,
+		"env":  `KEY="it's \"\$HOME\"\n\\n"`,
+	}
+
+	for shell, expected := range tests {
+		require.Equal(t, expected, execEnvFormats[shell]("KEY", value), shell)
+	}
+
+	require.Equal(t, "PATH=/opt/go/bin", execEnvFormats["env"]("PATH", "/opt/go/bin"))
+
+	if _, err := exec.LookPath("bash"); err == nil {
+		output, err := exec.CommandContext(t.Context(), "bash", "-c", tests["bash"]+`; printf %s "$KEY"`).Output()
+		require.NoError(t, err)
+		require.Equal(t, value, string(output))
+	}
+}
+
+// TestCmdExecEnvShell verifies exec-env rejects shells it has no format for.
+func TestCmdExecEnvShell(t *testing.T) {
+	t.Parallel()
+
+	plugin, err := plugins.GetPlugin("golang")
+	require.NoError(t, err)
+
+	require.ErrorIs(t, cmdExecEnv(&bytes.Buffer{}, plugin, "/opt/go", "tcsh"), errExecEnvShellUnsupported)
+}
+
+// TestCmdSBOM verifies the SBOM of a canned installs tree lists complete
+// installs with their package URLs, checksums and download URLs, and
+// validates against the CycloneDX 1.5 schema.
+func TestCmdSBOM(t *testing.T) {
+	schema := compileCycloneDXSchema(t)
+	require.Error(t, schema.Validate(map[string]any{"bomFormat": "SPDX"}))
+
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+	t.Chdir(t.TempDir())
+
+	files := map[string]string{
+		"jq/1.7.1/bin/jq":             "jq",
+		"golang/1.22.0/go/bin/go":     "go",
+		"golang/1.22.0/go/VERSION":    "go1.22.0\ntime 2024-02-06T21:52:21Z\n",
+		"mytool/0.1.0/bin/mytool":     "mytool",
+		"kubectl/1.30.0/bin/.gitkeep": "",
+	}
+	for path, content := range files {
+		path = filepath.Join(dataDir, "installs", path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(path, []byte(content), asdf.CommonExecutablePermission))
+	}
+
+	require.NoError(t, os.Chmod(filepath.Join(dataDir, "installs", "kubectl", "1.30.0", "bin", ".gitkeep"), asdf.CommonFilePermission))
+
+	sum := "sha256:" + strings.Repeat("ab", 32)
+	require.NoError(t, writeToolSums(toolSumsFile, map[string]string{"jq:1.7.1": sum}))
+
+	var out bytes.Buffer
+	require.NoError(t, cmdSBOM(t.Context(), &out))
+
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(out.Bytes()))
+	require.NoError(t, err)
+	require.NoError(t, schema.Validate(document))
+
+	var sbom asdf.SBOM
+	require.NoError(t, json.Unmarshal(out.Bytes(), &sbom))
+	require.Equal(t, "1.5", sbom.SpecVersion)
+	require.Len(t, sbom.Components, 3, "the incomplete kubectl install is left out")
+
+	components := make(map[string]asdf.SBOMComponent, len(sbom.Components))
+	for _, component := range sbom.Components {
+		components[component.Name] = component
+	}
+
+	jq := components["jq"]
+	require.Equal(t, "pkg:github/jqlang/jq@1.7.1", jq.Purl)
+	require.Equal(t, []asdf.SBOMHash{{Alg: "SHA-256", Content: strings.Repeat("ab", 32)}}, jq.Hashes)
+	require.Len(t, jq.ExternalReferences, 1)
+	require.Equal(t, "distribution", jq.ExternalReferences[0].Type)
+	require.Contains(t, jq.ExternalReferences[0].URL, "https://github.com/jqlang/jq/releases/download/jq-1.7.1/")
+
+	require.Equal(t, []asdf.SBOMProperty{
+		{Name: "golang:version", Value: "go1.22.0"},
+		{Name: "golang:build-time", Value: "2024-02-06T21:52:21Z"},
+	}, components["golang"].Properties)
+
+	require.Equal(t, "pkg:generic/mytool@0.1.0", components["mytool"].Purl)
+	require.Empty(t, components["mytool"].Hashes)
+}
+
+// cycloneDXSchemaBaseURL is the URL below which CycloneDX publishes its
+// schemas, whose references to each other are relative to it.
+const cycloneDXSchemaBaseURL = "http://cyclonedx.org/schema/"
+
+// compileCycloneDXSchema compiles the CycloneDX 1.5 schema, with every schema
+// in testdata/cyclonedx registered below the schema URL of CycloneDX so that
+// references between them resolve offline.
+func compileCycloneDXSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join("testdata", "cyclonedx", "*.schema.json"))
+	require.NoError(t, err)
+
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+
+	for _, path := range paths {
+		file, err := os.Open(path)
+		require.NoError(t, err)
+
+		document, err := jsonschema.UnmarshalJSON(file)
+		require.NoError(t, file.Close())
+		require.NoError(t, err, path)
+
+		require.NoError(t, compiler.AddResource(cycloneDXSchemaBaseURL+filepath.Base(path), document))
+	}
+
+	schema, err := compiler.Compile(cycloneDXSchemaBaseURL + "bom-1.5.schema.json")
+	require.NoError(t, err)
+
+	return schema
+}
+
+// errPrefetchReleaseMissing is returned by a prefetchPlugin without content.
+var errPrefetchReleaseMissing = errors.New("release not found")
+
+// prefetchPlugin downloads a release archive of its version, counting the
+// downloads, or fails when it has no content.
+type prefetchPlugin struct {
+	asdf.Plugin
+
+	name      string
+	content   string
+	downloads atomic.Int32
+}
+
+func (plugin *prefetchPlugin) Name() string {
+	return plugin.name
+}
+
+func (plugin *prefetchPlugin) Download(_ context.Context, version, downloadPath string) error {
+	plugin.downloads.Add(1)
+
+	if plugin.content == "" {
+		return errPrefetchReleaseMissing
+	}
+
+	return os.WriteFile(filepath.Join(downloadPath, plugin.name+"-"+version+".tar.gz"),
+		[]byte(plugin.content), asdf.CommonFilePermission)
+}
+
+// TestPrefetch verifies prefetch downloads and records tool versions, leaves
+// the downloads matching .tool-sums alone and only fails on failed downloads.
+func TestPrefetch(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+	t.Chdir(t.TempDir())
+
+	layout, err := asdf.CurrentLayout()
+	require.NoError(t, err)
+
+	tool := &prefetchPlugin{name: "tool", content: "release archive"}
+	broken := &prefetchPlugin{name: "broken"}
+
+	targets := []prefetchTarget{
+		{plugin: tool, name: "tool", version: "1.0.0", downloadPath: layout.DownloadPath("tool", "1.0.0")},
+		{plugin: broken, name: "broken", version: "2.0.0", downloadPath: layout.DownloadPath("broken", "2.0.0")},
+	}
+
+	var out bytes.Buffer
+
+	err = prefetch(t.Context(), &out, targets, 2)
+	require.ErrorIs(t, err, errPrefetchFailed)
+	require.Regexp(t, `FETCHED\s+tool\s+1\.0\.0\s+15 B`, out.String())
+	require.Regexp(t, `FAILED\s+broken\s+2\.0\.0\s+error: release not found`, out.String())
+	require.Contains(t, out.String(), "Fetched: 1, Cached: 0, Failed: 1, Downloaded: 15 B")
+	require.FileExists(t, filepath.Join(layout.DownloadPath("tool", "1.0.0"), "tool-1.0.0.tar.gz"))
+
+	sums, err := readToolSums(toolSumsFile)
+	require.NoError(t, err)
+	require.Contains(t, sums, "tool:1.0.0")
+	require.NotContains(t, sums, "broken:2.0.0")
+
+	out.Reset()
+
+	require.NoError(t, prefetch(t.Context(), &out, targets[:1], 1))
+	require.Regexp(t, `CACHED\s+tool\s+1\.0\.0`, out.String())
+	require.Contains(t, out.String(), "Fetched: 0, Cached: 1, Failed: 0, Downloaded: 0 B")
+	require.Equal(t, int32(1), tool.downloads.Load())
+}
+
+// conformancePlugin lists fixed versions; the methods it does not override
+// panic through the nil asdf.Plugin.
+type conformancePlugin struct {
+	asdf.Plugin
+
+	name     string
+	versions []string
+}
+
+func (plugin *conformancePlugin) Name() string {
+	return plugin.name
+}
+
+func (plugin *conformancePlugin) ListAll(context.Context) ([]string, error) {
+	return plugin.versions, nil
+}
+
+func (plugin *conformancePlugin) LatestStable(context.Context, string) (string, error) {
+	return "9.9.9", nil
+}
+
+func (*conformancePlugin) ListBinPaths() string {
+	return "bin"
+}
+
+// TestConformance verifies a panicking plugin is reported as failed without
+// ending the run, and recorded versions are listed offline.
+func TestConformance(t *testing.T) {
+	goldenDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(goldenDir, "jq_list_all.golden"),
+		[]byte("1.6\n1.7\n1.7.1\n"), asdf.CommonFilePermission))
+
+	jq, err := plugins.GetPlugin("jq")
+	require.NoError(t, err)
+
+	targets := []conformanceTarget{
+		{plugin: jq, name: "jq"},
+		{plugin: &conformancePlugin{name: "tool", versions: []string{"1.0.0"}}, name: "tool"},
+	}
+
+	var out bytes.Buffer
+
+	err = conformance(t.Context(), &out, targets, 2, goldenDir, false)
+	require.ErrorIs(t, err, errConformanceFailed)
+	require.Regexp(t, `jq\s+ok\s+ok\s+ok\s+ok\s+ok\s+(ok|skip)\s`, out.String())
+	require.Regexp(t, `tool\s+skip\s+skip\s+ok\s+FAIL\s+FAIL\s+skip\s`, out.String())
+	require.Contains(t, out.String(), "tool help: panic:")
+	require.Contains(t, out.String(), "Passed: 1, Failed: 1")
+
+	out.Reset()
+
+	err = conformance(t.Context(), &out, targets[1:], 1, goldenDir, true)
+	require.ErrorIs(t, err, errConformanceFailed)
+	require.Contains(t, out.String(), "tool latest-stable: latest stable version not listed by ListAll: 9.9.9")
+}
+
+// TestCmdResolve verifies the resolved version and downloads are printed
+// without downloading, and that plugins unable to describe their downloads
+// are reported as such, and that refs of plugins unable to install them are
+// rejected.
+func TestCmdResolve(t *testing.T) {
+	t.Setenv(asdf.InstallTypeEnv, "")
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:             "tool",
+		RepoOwner:        "owner",
+		RepoName:         "tool",
+		BinaryName:       "tool",
+		FileNameTemplate: "tool-{{.Platform}}-{{.Arch}}.zip",
+		ArchiveType:      "zip",
+		OsMap:            map[string]string{"darwin": "unix", "linux": "unix"},
+		ArchMap:          map[string]string{"amd64": "x64", "arm64": "x64"},
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, cmdResolve(t.Context(), &out, plugin, "1.2.3"))
+	require.Equal(t, `Tool:          tool
+Requested:     1.2.3
+Resolved:      1.2.3
+Platform:      unix
+Arch:          x64
+Archive type:  zip
+Binary path:   bin/tool
+Artifact:      tool-unix-x64.zip
+URL:           https://github.com/owner/tool/releases/download/v1.2.3/tool-unix-x64.zip
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, cmdResolve(t.Context(), &out, &conformancePlugin{name: "opaque"}, "latest"))
+	require.Equal(t, `Tool:          opaque
+Requested:     latest
+Resolved:      9.9.9
+Artifacts:     unknown, opaque only selects its downloads while downloading
+`, out.String())
+
+	out.Reset()
+	err := cmdResolve(t.Context(), &out, plugin, "ref:main")
+	require.ErrorContains(t, err, "resolve ref:main needs ref-install, which tool does not implement")
+	require.NotContains(t, out.String(), "Ref:")
+
+	source := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		Name:       "source",
+		RepoOwner:  "owner",
+		RepoName:   "source",
+		RefInstall: true,
+	})
+
+	out.Reset()
+	require.NoError(t, cmdResolve(t.Context(), &out, source, "ref:main"))
+	require.Contains(t, out.String(), "Ref:           main\n")
+}
+
+// TestCmdChangelogUnsupported verifies plugins without release notes are
+// rejected before any version is resolved.
+func TestCmdChangelogUnsupported(t *testing.T) {
+	err := cmdChangelog(t.Context(), "python", "latest")
+	require.ErrorContains(t, err, "not supported by this plugin: changelog needs changelog, which python does not implement")
+}
+
+// TestCmdPrefetchSkipsUnmanagedTools verifies system pins, refs and tools
+// without a plugin are not prefetched.
+func TestCmdPrefetchSkipsUnmanagedTools(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+	t.Chdir(t.TempDir())
+
+	require.NoError(t, os.WriteFile(".tool-versions",
+		[]byte("golang system\nnodejs ref:main\nno-such-tool 1.0.0\n"), asdf.CommonFilePermission))
+
+	var out bytes.Buffer
+
+	require.NoError(t, cmdPrefetch(t.Context(), &out, ".tool-versions", 2))
+	require.Equal(t, "\nFetched: 0, Cached: 0, Failed: 0, Downloaded: 0 B\n", out.String())
+}
+
+// TestCmdGC verifies gc keeps the versions pinned by .tool-versions and
+// --project directories, lists removals in dry-run mode and reports the
+// reclaimed size.
+func TestCmdGC(t *testing.T) {
+	data, project := t.TempDir(), t.TempDir()
+	t.Setenv(asdf.DataDirEnv, data)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(asdf.ToolVersionEnv("golang"), "")
+	t.Chdir(t.TempDir())
+
+	for _, version := range []string{"1.20.0", "1.21.0", "1.22.0", "1.23.0"} {
+		binary := filepath.Join(data, "installs", "golang", version, "go", "bin", "go")
+		require.NoError(t, os.MkdirAll(filepath.Dir(binary), asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(binary, []byte(version), asdf.CommonExecutablePermission))
+	}
+
+	require.NoError(t, os.WriteFile(".tool-versions", []byte("golang 1.20.0\n"), asdf.CommonFilePermission))
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "tools"), asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "tools", ".tool-versions"),
+		[]byte("golang 1.21.0\n"), asdf.CommonFilePermission))
+
+	var out bytes.Buffer
+
+	require.NoError(t, cmdGC(t.Context(), &out, asdf.GCOptions{Keep: 1}, []string{project}, true))
+	require.Regexp(t, `install\s+6 B\s+\S+/golang/1\.22\.0\n`, out.String())
+	require.Contains(t, out.String(), "Would reclaim 6 B from 1 entries\n")
+	require.DirExists(t, filepath.Join(data, "installs", "golang", "1.22.0"))
+
+	out.Reset()
+
+	require.NoError(t, cmdGC(t.Context(), &out, asdf.GCOptions{Keep: 1}, nil, false))
+	require.Contains(t, out.String(), "Reclaimed 12 B\n")
+
+	for version, kept := range map[string]bool{"1.20.0": true, "1.21.0": false, "1.22.0": false, "1.23.0": true} {
+		_, err := os.Stat(filepath.Join(data, "installs", "golang", version))
+		require.Equal(t, kept, err == nil, version)
+	}
+}
+
+// TestCmdGCHome verifies gc protects the versions pinned anywhere below the
+// home directory, except in dependency directories and the data directory.
+func TestCmdGCHome(t *testing.T) {
+	home := t.TempDir()
+	data := filepath.Join(home, ".asdf")
+	t.Setenv(asdf.DataDirEnv, data)
+	t.Setenv("HOME", home)
+	t.Setenv(asdf.ToolVersionEnv("golang"), "")
+	t.Chdir(t.TempDir())
+
+	for _, version := range []string{"1.20.0", "1.21.0", "1.22.0", "1.23.0"} {
+		binary := filepath.Join(data, "installs", "golang", version, "go", "bin", "go")
+		require.NoError(t, os.MkdirAll(filepath.Dir(binary), asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(binary, []byte(version), asdf.CommonExecutablePermission))
+	}
+
+	for path, version := range map[string]string{
+		"src/app/.tool-versions":                         "1.20.0",
+		"src/app/node_modules/dep/.tool-versions":        "1.21.0",
+		".asdf/installs/golang/1.23.0/go/.tool-versions": "1.22.0",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(home, path)), asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(filepath.Join(home, path),
+			[]byte("golang "+version+"\n"), asdf.CommonFilePermission))
+	}
+
+	var out bytes.Buffer
+
+	require.NoError(t, cmdGC(t.Context(), &out, asdf.GCOptions{Keep: 1}, nil, false))
+
+	for version, kept := range map[string]bool{"1.20.0": true, "1.21.0": false, "1.22.0": false, "1.23.0": true} {
+		_, err := os.Stat(filepath.Join(data, "installs", "golang", version))
+		require.Equal(t, kept, err == nil, version)
+	}
+}
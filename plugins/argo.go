@@ -52,9 +52,13 @@ func NewArgoPlugin() asdf.Plugin {
 		RepoOwner:     "argoproj",
 		RepoName:      "argo-workflows",
 		VersionPrefix: "v",
+		RefInstall:    true,
 		UseTags:       useTags,
 		VersionFilter: `^3\.`,
 
+		// The UI build downloads npm packages; registry settings do not change the output.
+		BuildEnvPassthrough: append([]string{"NPM_CONFIG_*", "npm_config_*", "YARN_*"}, goModuleEnvPassthrough...),
+
 		Help: asdf.PluginHelp{
 			Overview: `Argo Workflows CLI - The workflow engine for Kubernetes.
 Argo is built from the official source archive using Go, which requires Go to be installed.`,
@@ -104,10 +108,10 @@ Source: https://github.com/argoproj/argo-workflows`,
 
 			nodeDir := filepath.Dir(nodePath)
 
-			baseEnv = append(baseEnv,
-				"PATH="+nodeDir+string(os.PathListSeparator)+os.Getenv("PATH"),
-				"CI=true", // Ensure non-interactive mode for build tools
-			)
+			execer := asdf.BuildExecerFromContext(ctx).WithEnv(map[string]string{
+				"PATH": nodeDir,
+				"CI":   "true", // Ensure non-interactive mode for build tools
+			})
 
 			// Ensure source directory has .tool-versions with golang
 			sourceToolVersions := filepath.Join(sourceDir, ".tool-versions")
@@ -122,7 +126,7 @@ Source: https://github.com/argoproj/argo-workflows`,
 
 			// Install global webpack as requested to avoid issues with yarn install/build
 			// We install webpack-cli as well since it's often required.
-			installWebpack := exec.CommandContext(
+			installWebpack := execer.Command(
 				ctx,
 				npmPath,
 				"install",
@@ -135,18 +139,16 @@ Source: https://github.com/argoproj/argo-workflows`,
 			installWebpack.Stdout = os.Stderr
 			installWebpack.Stderr = os.Stderr
 
-			installWebpack.Env = baseEnv
 			if err := installWebpack.Run(); err != nil {
 				return fmt.Errorf("installing global webpack: %w", err)
 			}
 
-			yarnInstall := exec.CommandContext(ctx, npmPath, "exec", "yarn", "install")
+			yarnInstall := execer.Command(ctx, npmPath, "exec", "yarn", "install")
 
 			yarnInstall.Dir = uiDir
 			yarnInstall.Stdout = os.Stderr
 			yarnInstall.Stderr = os.Stderr
 
-			yarnInstall.Env = baseEnv
 			if err := yarnInstall.Run(); err != nil {
 				return fmt.Errorf("installing argo UI dependencies with yarn: %w", err)
 			}
@@ -158,23 +160,18 @@ Source: https://github.com/argoproj/argo-workflows`,
 			// 1. local node_modules/.bin (for project-specific tools)
 			// 2. nodeDir (for global webpack/webpack-cli and node/npm)
 			// 3. original PATH (for system tools)
-			buildPath := nodeModulesBin + string(os.PathListSeparator) +
-				nodeDir + string(os.PathListSeparator) +
-				os.Getenv("PATH")
-
-			baseEnv = append(baseEnv,
-				"PATH="+buildPath,
-				"NODE_ENV=production",
-				"NODE_OPTIONS=--max-old-space-size=2048",
-			)
+			execer = execer.WithEnv(map[string]string{
+				"PATH":         nodeModulesBin,
+				"NODE_ENV":     "production",
+				"NODE_OPTIONS": "--max-old-space-size=2048",
+			})
 
-			yarnBuild := exec.CommandContext(ctx, npmPath, "exec", "yarn", "build")
+			yarnBuild := execer.Command(ctx, npmPath, "exec", "yarn", "build")
 
 			yarnBuild.Dir = uiDir
 			yarnBuild.Stdout = os.Stderr
 			yarnBuild.Stderr = os.Stderr
 
-			yarnBuild.Env = baseEnv
 			if err := yarnBuild.Run(); err != nil {
 				return fmt.Errorf("building argo UI with yarn build: %w", err)
 			}
@@ -186,7 +183,7 @@ Source: https://github.com/argoproj/argo-workflows`,
 
 			fmt.Fprintf(os.Stderr, "Building argo binary in %s from %s\n", binDir, sourceDir)
 
-			buildCmd := exec.CommandContext(
+			buildCmd := asdf.BuildCommand(
 				ctx,
 				goPath,
 				"build",
@@ -198,7 +195,6 @@ Source: https://github.com/argoproj/argo-workflows`,
 			buildCmd.Dir = sourceDir
 			buildCmd.Stdout = os.Stderr
 			buildCmd.Stderr = os.Stderr
-			buildCmd.Env = os.Environ()
 
 			if err := buildCmd.Run(); err != nil {
 				return fmt.Errorf("building argo: %w", err)
@@ -255,12 +251,7 @@ func (*ArgoPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy version file.
 func (*ArgoPlugin) ParseLegacyFile(path string) (string, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
-	}
-
-	return strings.TrimSpace(string(content)), nil
+	return asdf.ReadVersionFile(path)
 }
 
 // Uninstall removes an Argo installation.
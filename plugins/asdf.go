@@ -243,16 +243,12 @@ func (*AsdfPlugin) detectShell() string {
 
 // GetDataDir returns the asdf data directory.
 func (*AsdfPlugin) GetDataDir() string {
-	if dir := os.Getenv("ASDF_DATA_DIR"); dir != "" {
-		return dir
-	}
-
-	home, err := os.UserHomeDir()
+	layout, err := asdf.CurrentLayout()
 	if err != nil {
 		return ""
 	}
 
-	return filepath.Join(home, ".asdf")
+	return layout.DataDir
 }
 
 // GetShimsDir returns the asdf shims directory.
@@ -22,14 +22,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ulikunitz/xz"
 )
 
 const (
+	// ArchiveMaxBytesEnv caps the decompressed size of an extracted archive, in
+	// bytes with an optional binary K, M, G or T suffix such as "4G".
+	ArchiveMaxBytesEnv = "ASDF_ARCHIVE_MAX_BYTES"
 	// TarFilePermission is the file mode used for non-executable entries in the test Go tar archives.
 	TarFilePermission os.FileMode = 0o644
 	// TarLinkPermission is the file mode used for link entries in the test Go tar archives.
@@ -38,6 +45,14 @@ const (
 	maxArchiveBytes int64 = 1 << 30
 	// maxArchiveFileBytes is the maximum size in bytes permitted for a single extracted archive entry.
 	maxArchiveFileBytes int64 = 512 << 20
+	// maxArchiveLinkBytes bounds the target of a symlink stored as zip entry content.
+	maxArchiveLinkBytes = 4096
+	// extractPermMask keeps the read and execute bits of extracted entries but
+	// drops group and world write as well as setuid, setgid and sticky bits.
+	extractPermMask os.FileMode = 0o755
+	// extractDirOwnerPermission is always granted on extracted directories so
+	// that their contents can be written and the tree removed again.
+	extractDirOwnerPermission os.FileMode = 0o700
 )
 
 var (
@@ -51,13 +66,316 @@ var (
 	errInvalidArchiveSizeLimits = errors.New("invalid archive size limits")
 	// errArchiveSizeLimitExceeded indicates an archive exceeded one of the configured size limits.
 	errArchiveSizeLimitExceeded = errors.New("archive size limit exceeded")
+	// errArchiveUnsafeLink indicates a symlink or hardlink entry pointing outside the destination.
+	errArchiveUnsafeLink = errors.New("unsafe link in archive")
+	// errArchiveNoSingleRoot indicates an archive does not hold exactly one top-level directory.
+	errArchiveNoSingleRoot = errors.New("unexpected archive layout")
 )
 
+// archiveExtractor writes archive entries below a destination directory. All
+// file operations go through an os.Root, so no entry can be written outside
+// the destination, not even through a symlink created by an earlier entry.
+// With ReproducibleEnv set, entries get their ReproducibleMode and, once
+// extracted, their archive modification time or the Unix epoch.
+type archiveExtractor struct {
+	root         *os.Root
+	progress     io.Writer
+	mtimes       map[string]time.Time
+	links        []string
+	written      int64
+	maxTotal     int64
+	maxFile      int64
+	reproducible bool
+}
+
+// newArchiveExtractor creates destDir if needed and opens it for extraction.
+func newArchiveExtractor(destDir string) (*archiveExtractor, error) {
+	if err := os.MkdirAll(destDir, CommonDirectoryPermission); err != nil {
+		return nil, fmt.Errorf("creating directory %s: %w", destDir, err)
+	}
+
+	root, err := os.OpenRoot(destDir)
+	if err != nil {
+		return nil, fmt.Errorf("opening directory %s: %w", destDir, err)
+	}
+
+	maxTotal, maxFile := archiveSizeLimits()
+
+	return &archiveExtractor{
+		root:         root,
+		maxTotal:     maxTotal,
+		maxFile:      maxFile,
+		reproducible: Reproducible(),
+		mtimes:       make(map[string]time.Time),
+	}, nil
+}
+
+// Close releases the destination directory.
+func (extractor *archiveExtractor) Close() error {
+	return extractor.root.Close()
+}
+
+// mkdir creates the directory rel with the entry mode, keeping it writable by the owner.
+func (extractor *archiveExtractor) mkdir(rel string, mode os.FileMode) error {
+	perm := extractor.perm(mode)
+
+	if err := extractor.root.MkdirAll(rel, perm); err != nil {
+		return fmt.Errorf("creating directory %s: %w", rel, err)
+	}
+
+	if rel == "." {
+		return nil
+	}
+
+	if err := extractor.root.Chmod(rel, perm); err != nil {
+		return fmt.Errorf("setting mode of %s: %w", rel, err)
+	}
+
+	return nil
+}
+
+// writeFile writes the contents of reader to rel, enforcing the size limits,
+// and applies the entry permission bits independently of the umask.
+func (extractor *archiveExtractor) writeFile(
+	rel string,
+	mode os.FileMode,
+	reader io.Reader,
+	size int64,
+	errTooLarge error,
+) error {
+	if size > extractor.maxFile {
+		return fmt.Errorf("%w: %s: %d bytes", errTooLarge, rel, size)
+	}
+
+	if err := extractor.prepare(rel); err != nil {
+		return err
+	}
+
+	perm := extractor.perm(mode)
+
+	outFile, err := extractor.root.OpenFile(rel, os.O_CREATE|os.O_WRONLY|os.O_EXCL, perm)
+	if err != nil {
+		return fmt.Errorf("creating file %s: %w", rel, err)
+	}
+
+	var writer io.Writer = outFile
+	if extractor.progress != nil {
+		writer = io.MultiWriter(outFile, extractor.progress)
+	}
+
+	lw := &limitedArchiveWriter{
+		w:        writer,
+		total:    &extractor.written,
+		maxTotal: extractor.maxTotal,
+		maxFile:  extractor.maxFile,
+	}
+
+	//nolint:gosec // G110: decompressed size is bounded by limitedArchiveWriter
+	_, err = io.Copy(lw, reader)
+
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("writing file %s: %w", rel, err)
+	}
+
+	if err := extractor.root.Chmod(rel, perm); err != nil {
+		return fmt.Errorf("setting mode of %s: %w", rel, err)
+	}
+
+	return nil
+}
+
+// perm returns the permission of an extracted entry with mode: its read and
+// execute bits, keeping directories writable by the owner, or its
+// ReproducibleMode.
+func (extractor *archiveExtractor) perm(mode os.FileMode) os.FileMode {
+	switch {
+	case extractor.reproducible:
+		return ReproducibleMode(mode)
+	case mode.IsDir():
+		return mode.Perm()&extractPermMask | extractDirOwnerPermission
+	default:
+		return mode.Perm() & extractPermMask
+	}
+}
+
+// record keeps the archive modification time of rel for finish.
+func (extractor *archiveExtractor) record(rel string, modTime time.Time) {
+	extractor.mtimes[rel] = modTime
+}
+
+// finish verifies the extracted symlinks and, with ReproducibleEnv set, gives
+// every entry its archive modification time, or the Unix epoch for entries
+// without one such as implicitly created parent directories.
+func (extractor *archiveExtractor) finish() error {
+	if err := extractor.verifyLinks(); err != nil {
+		return err
+	}
+
+	if !extractor.reproducible {
+		return nil
+	}
+
+	return normalizeTree(extractor.root, func(rel string, _ fs.FileInfo) time.Time {
+		return reproducibleTime(extractor.mtimes[rel])
+	})
+}
+
+// symlink creates rel pointing at linkname. Absolute targets and targets that
+// climb out of the destination are rejected; targets that only escape through
+// other symlinks are caught by verifyLinks once every entry exists.
+func (extractor *archiveExtractor) symlink(rel, linkname string) error {
+	target := filepath.FromSlash(linkname)
+	resolved := filepath.Join(filepath.Dir(rel), target)
+
+	if target == "" || isAbsArchivePath(target) || escapesArchiveRoot(resolved) {
+		return fmt.Errorf("%w: %s -> %s", errArchiveUnsafeLink, rel, linkname)
+	}
+
+	if err := extractor.prepare(rel); err != nil {
+		return err
+	}
+
+	if err := extractor.root.Symlink(target, rel); err != nil {
+		return fmt.Errorf("creating symlink %s: %w", rel, err)
+	}
+
+	extractor.links = append(extractor.links, rel)
+
+	return nil
+}
+
+// hardlink links rel to linkname, an earlier regular file of the same archive.
+func (extractor *archiveExtractor) hardlink(rel, linkname string, errInvalid error) error {
+	source, err := archiveEntryPath(linkname, errInvalid)
+	if err != nil {
+		return err
+	}
+
+	info, err := extractor.root.Lstat(source)
+	if err != nil || !info.Mode().IsRegular() {
+		return fmt.Errorf("%w: %s => %s", errArchiveUnsafeLink, rel, linkname)
+	}
+
+	if err := extractor.prepare(rel); err != nil {
+		return err
+	}
+
+	if err := extractor.root.Link(source, rel); err != nil {
+		return fmt.Errorf("creating hardlink %s: %w", rel, err)
+	}
+
+	return nil
+}
+
+// prepare creates the parent directories of rel and removes an existing
+// non-directory at rel, so that writing it never follows an earlier symlink.
+func (extractor *archiveExtractor) prepare(rel string) error {
+	if err := extractor.root.MkdirAll(filepath.Dir(rel), CommonDirectoryPermission); err != nil {
+		return fmt.Errorf("creating parent directory of %s: %w", rel, err)
+	}
+
+	if info, err := extractor.root.Lstat(rel); err == nil && !info.IsDir() {
+		if err := extractor.root.Remove(rel); err != nil {
+			return fmt.Errorf("replacing %s: %w", rel, err)
+		}
+	}
+
+	return nil
+}
+
+// verifyLinks checks that every extracted symlink resolves within the
+// destination. A target such as "dir/../x" looks contained until dir turns
+// out to be a symlink itself, so this can only be decided after extraction.
+// Dangling links are kept; offending links are removed.
+func (extractor *archiveExtractor) verifyLinks() error {
+	for _, rel := range extractor.links {
+		_, err := extractor.root.Stat(rel)
+		if err == nil || errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if removeErr := extractor.root.Remove(rel); removeErr != nil {
+			Logger().Warn("removing unsafe symlink", "path", rel, "error", removeErr)
+		}
+
+		return fmt.Errorf("%w: %s: %w", errArchiveUnsafeLink, rel, err)
+	}
+
+	return nil
+}
+
+// archiveEntryPath returns the entry name as a path relative to the
+// destination, rejecting absolute names and names that climb out of it.
+func archiveEntryPath(name string, errInvalid error) (string, error) {
+	rel := filepath.Clean(filepath.FromSlash(name))
+	if name == "" || isAbsArchivePath(filepath.FromSlash(name)) || escapesArchiveRoot(rel) {
+		return "", fmt.Errorf("%w: %s", errInvalid, name)
+	}
+
+	return rel, nil
+}
+
+// isAbsArchivePath reports whether path is absolute on any platform we extract on.
+func isAbsArchivePath(path string) bool {
+	return filepath.IsAbs(path) || filepath.VolumeName(path) != "" ||
+		strings.HasPrefix(path, string(os.PathSeparator))
+}
+
+// escapesArchiveRoot reports whether the cleaned relative path leaves its root.
+func escapesArchiveRoot(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+// archiveSizeLimits returns the total and per-entry decompressed size limits.
+// ArchiveMaxBytesEnv replaces both, since no entry can exceed the total.
+func archiveSizeLimits() (total, file int64) {
+	value := os.Getenv(ArchiveMaxBytesEnv)
+	if value == "" {
+		return maxArchiveBytes, maxArchiveFileBytes
+	}
+
+	size, err := parseByteSize(value)
+	if err != nil {
+		Logger().Warn("ignoring invalid archive size limit", "env", ArchiveMaxBytesEnv, "value", value)
+
+		return maxArchiveBytes, maxArchiveFileBytes
+	}
+
+	return size, size
+}
+
+// parseByteSize parses a positive byte count with an optional binary K, M, G
+// or T suffix, optionally followed by "iB" or "B", e.g. "512M" or "2GiB".
+func parseByteSize(value string) (int64, error) {
+	digits := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "B"), "I")
+
+	shift := 0
+	if n := len(digits); n > 0 {
+		if idx := strings.IndexByte("KMGT", digits[n-1]); idx >= 0 {
+			shift = 10 * (idx + 1)
+			digits = digits[:n-1]
+		}
+	}
+
+	size, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || size <= 0 || size > math.MaxInt64>>shift {
+		return 0, fmt.Errorf("%w: %s", errInvalidArchiveSizeLimits, value)
+	}
+
+	return size << shift, nil
+}
+
 // extractTarEntries extracts all entries from a tar reader to the destination directory.
 func extractTarEntries(tr *tar.Reader, destDir string) error {
-	var totalWritten int64
-
-	cleanDestDir := filepath.Clean(destDir)
+	extractor, err := newArchiveExtractor(destDir)
+	if err != nil {
+		return err
+	}
+	defer extractor.Close()
 
 	for {
 		header, err := tr.Next()
@@ -69,74 +387,48 @@ func extractTarEntries(tr *tar.Reader, destDir string) error {
 			return fmt.Errorf("reading tar: %w", err)
 		}
 
-		target := filepath.Join(cleanDestDir, filepath.Clean(header.Name))
-		if !isPathWithinDir(target, cleanDestDir) {
-			return fmt.Errorf("%w: %s", errInvalidArchiveFilePathTar, header.Name)
+		rel, err := archiveEntryPath(header.Name, errInvalidArchiveFilePathTar)
+		if err != nil {
+			return err
 		}
 
 		switch header.Typeflag {
 		case tar.TypeDir:
-			err := os.MkdirAll(target, header.FileInfo().Mode().Perm())
-			if err != nil {
-				return fmt.Errorf("creating directory %s: %w", target, err)
-			}
-
+			err = extractor.mkdir(rel, header.FileInfo().Mode())
 		case tar.TypeReg:
-			if header.Size > maxArchiveFileBytes {
-				return fmt.Errorf("%w: %d bytes", errTarEntryTooLarge, header.Size)
-			}
-
-			if err := os.MkdirAll(filepath.Dir(target), CommonDirectoryPermission); err != nil {
-				return fmt.Errorf("creating parent directory: %w", err)
-			}
-
-			fileMode := header.FileInfo().Mode()
-
-			outFile, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, fileMode)
-			if err != nil {
-				return fmt.Errorf("creating file %s: %w", target, err)
-			}
-
-			lw := &limitedArchiveWriter{
-				w:        outFile,
-				total:    &totalWritten,
-				maxTotal: maxArchiveBytes,
-				maxFile:  maxArchiveFileBytes,
-			}
-
-			if _, err := io.Copy(lw, tr); err != nil {
-				outFile.Close()
-
-				return fmt.Errorf("writing file %s: %w", target, err)
-			}
-
-			outFile.Close()
-
+			err = extractor.writeFile(rel, header.FileInfo().Mode(), tr, header.Size, errTarEntryTooLarge)
 		case tar.TypeSymlink:
-			err := os.MkdirAll(filepath.Dir(target), CommonDirectoryPermission)
-			if err != nil {
-				return fmt.Errorf("creating parent directory: %w", err)
-			}
-
-			err = os.Symlink(header.Linkname, target)
-			if err != nil {
-				return fmt.Errorf("creating symlink %s: %w", target, err)
-			}
+			err = extractor.symlink(rel, header.Linkname)
+		case tar.TypeLink:
+			err = extractor.hardlink(rel, header.Linkname, errInvalidArchiveFilePathTar)
+		default:
+			Logger().Debug("skipping tar entry", "name", header.Name, "type", string(header.Typeflag))
 		}
+
+		if err != nil {
+			return err
+		}
+
+		extractor.record(rel, header.ModTime)
 	}
 
-	return nil
+	return extractor.finish()
 }
 
 // ExtractTarGz extracts a .tar.gz file to the destination directory.
 func ExtractTarGz(archivePath, destDir string) error {
+	Logger().Debug("extracting archive", "archive", archivePath, "dest", destDir)
+
 	f, err := os.Open(archivePath)
 	if err != nil {
 		return fmt.Errorf("opening archive: %w", err)
 	}
 	defer f.Close()
 
-	gzr, err := gzip.NewReader(f)
+	archiveReader, reporter := trackArchiveProgress(f)
+	defer reporter.Done()
+
+	gzr, err := gzip.NewReader(archiveReader)
 	if err != nil {
 		return fmt.Errorf("creating gzip reader: %w", err)
 	}
@@ -145,15 +437,69 @@ func ExtractTarGz(archivePath, destDir string) error {
 	return extractTarEntries(tar.NewReader(gzr), destDir)
 }
 
+// ExtractTarGzRoot extracts a .tar.gz file holding a single top-level
+// directory, such as "apache-maven-3.9.9/", into destDir without that directory.
+func ExtractTarGzRoot(archivePath, destDir string) error {
+	tempDir, err := os.MkdirTemp("", "asdf-extract-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := ExtractTarGz(archivePath, tempDir); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		return fmt.Errorf("reading extracted archive: %w", err)
+	}
+
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return fmt.Errorf("%w: %s does not hold a single top-level directory",
+			errArchiveNoSingleRoot, filepath.Base(archivePath))
+	}
+
+	srcDir := filepath.Join(tempDir, entries[0].Name())
+	if err := CopyDir(srcDir, destDir); err != nil {
+		return err
+	}
+
+	if !Reproducible() {
+		return nil
+	}
+
+	root, err := os.OpenRoot(destDir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	// Copying loses the modification times extracted from the archive.
+	return normalizeTree(root, func(rel string, _ fs.FileInfo) time.Time {
+		info, err := os.Lstat(filepath.Join(srcDir, rel))
+		if err != nil {
+			return time.Unix(0, 0)
+		}
+
+		return info.ModTime()
+	})
+}
+
 // ExtractTarXz extracts a .tar.xz file to the destination directory.
 func ExtractTarXz(archivePath, destDir string) error {
+	Logger().Debug("extracting archive", "archive", archivePath, "dest", destDir)
+
 	f, err := os.Open(archivePath)
 	if err != nil {
 		return fmt.Errorf("opening archive: %w", err)
 	}
 	defer f.Close()
 
-	xzr, err := xz.NewReader(f)
+	archiveReader, reporter := trackArchiveProgress(f)
+	defer reporter.Done()
+
+	xzr, err := xz.NewReader(archiveReader)
 	if err != nil {
 		return fmt.Errorf("creating xz reader: %w", err)
 	}
@@ -163,82 +509,103 @@ func ExtractTarXz(archivePath, destDir string) error {
 
 // ExtractZip extracts a .zip file to the destination directory.
 func ExtractZip(archivePath, destDir string) error {
+	Logger().Debug("extracting archive", "archive", archivePath, "dest", destDir)
+
 	reader, err := zip.OpenReader(archivePath)
 	if err != nil {
 		return fmt.Errorf("opening zip archive: %w", err)
 	}
 	defer reader.Close()
 
-	var totalWritten int64
+	extractor, err := newArchiveExtractor(destDir)
+	if err != nil {
+		return err
+	}
+	defer extractor.Close()
 
-	cleanDestDir := filepath.Clean(destDir)
+	var totalSize int64
 
 	for _, zipFile := range reader.File {
-		target := filepath.Join(cleanDestDir, filepath.Clean(zipFile.Name))
-		if !isPathWithinDir(target, cleanDestDir) {
-			return fmt.Errorf("%w: %s", errInvalidArchiveFilePathZip, zipFile.Name)
-		}
+		totalSize += int64(min(zipFile.UncompressedSize64, uint64(extractor.maxTotal)))
+	}
 
-		if zipFile.UncompressedSize64 > uint64(maxArchiveFileBytes) {
-			return fmt.Errorf("%w: %d bytes", errZipEntryTooLarge, zipFile.UncompressedSize64)
-		}
+	reporter := newProgressReporter("Extracting " + filepath.Base(archivePath))
+	reporter.Start(totalSize)
 
-		if zipFile.FileInfo().IsDir() {
-			err := os.MkdirAll(target, CommonDirectoryPermission)
-			if err != nil {
-				return fmt.Errorf("creating directory %s: %w", target, err)
-			}
+	defer reporter.Done()
 
-			continue
+	extractor.progress = &progressWriter{reporter: reporter}
+
+	for _, zipFile := range reader.File {
+		rel, err := archiveEntryPath(zipFile.Name, errInvalidArchiveFilePathZip)
+		if err != nil {
+			return err
 		}
 
-		if err := os.MkdirAll(filepath.Dir(target), CommonDirectoryPermission); err != nil {
-			return fmt.Errorf("creating parent directory: %w", err)
+		mode := zipFile.Mode()
+
+		switch {
+		case mode.IsDir():
+			err = extractor.mkdir(rel, mode)
+		case mode&os.ModeSymlink != 0:
+			err = extractZipSymlink(extractor, rel, zipFile)
+		default:
+			err = extractZipFile(extractor, rel, zipFile)
 		}
 
-		rc, err := zipFile.Open()
 		if err != nil {
-			return fmt.Errorf("opening file in archive: %w", err)
+			return err
 		}
 
-		outFile, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, zipFile.Mode())
-		if err != nil {
-			rc.Close()
+		extractor.record(rel, zipFile.Modified)
+	}
 
-			return fmt.Errorf("creating file %s: %w", target, err)
-		}
+	return extractor.finish()
+}
 
-		lw := &limitedArchiveWriter{
-			w:        outFile,
-			total:    &totalWritten,
-			maxTotal: maxArchiveBytes,
-			maxFile:  maxArchiveFileBytes,
-		}
+// extractZipFile writes a regular zip entry to rel.
+func extractZipFile(extractor *archiveExtractor, rel string, zipFile *zip.File) error {
+	rc, err := zipFile.Open()
+	if err != nil {
+		return fmt.Errorf("opening file in archive: %w", err)
+	}
+	defer rc.Close()
 
-		//nolint:gosec // G110: decompressed size is bounded by limitedArchiveWriter
-		if _, err := io.Copy(lw, rc); err != nil {
-			outFile.Close()
-			rc.Close()
+	size := int64(min(zipFile.UncompressedSize64, uint64(math.MaxInt64)))
 
-			return fmt.Errorf("writing file %s: %w", target, err)
-		}
+	return extractor.writeFile(rel, zipFile.Mode(), rc, size, errZipEntryTooLarge)
+}
 
-		outFile.Close()
-		rc.Close()
+// extractZipSymlink creates rel from a zip entry storing its link target as content.
+func extractZipSymlink(extractor *archiveExtractor, rel string, zipFile *zip.File) error {
+	rc, err := zipFile.Open()
+	if err != nil {
+		return fmt.Errorf("opening file in archive: %w", err)
 	}
+	defer rc.Close()
 
-	return nil
+	linkname, err := io.ReadAll(io.LimitReader(rc, maxArchiveLinkBytes))
+	if err != nil {
+		return fmt.Errorf("reading symlink %s: %w", rel, err)
+	}
+
+	return extractor.symlink(rel, string(linkname))
 }
 
 // ExtractGz extracts a .gz file to the destination path.
 func ExtractGz(gzPath, destPath string) error {
+	Logger().Debug("extracting archive", "archive", gzPath, "dest", destPath)
+
 	gzFile, err := os.Open(gzPath)
 	if err != nil {
 		return fmt.Errorf("opening gz file: %w", err)
 	}
 	defer gzFile.Close()
 
-	gzr, err := gzip.NewReader(gzFile)
+	archiveReader, reporter := trackArchiveProgress(gzFile)
+	defer reporter.Done()
+
+	gzr, err := gzip.NewReader(archiveReader)
 	if err != nil {
 		return fmt.Errorf("creating gzip reader: %w", err)
 	}
@@ -252,11 +619,13 @@ func ExtractGz(gzPath, destPath string) error {
 
 	var totalWritten int64
 
+	maxTotal, maxFile := archiveSizeLimits()
+
 	lw := &limitedArchiveWriter{
 		w:        outFile,
 		total:    &totalWritten,
-		maxTotal: maxArchiveBytes,
-		maxFile:  maxArchiveFileBytes,
+		maxTotal: maxTotal,
+		maxFile:  maxFile,
 	}
 
 	//nolint:gosec // G110: decompressed size is bounded by limitedArchiveWriter
@@ -264,9 +633,37 @@ func ExtractGz(gzPath, destPath string) error {
 		return fmt.Errorf("extracting gz: %w", err)
 	}
 
+	if !Reproducible() {
+		return nil
+	}
+
+	if err := outFile.Chmod(ReproducibleMode(CommonFilePermission)); err != nil {
+		return fmt.Errorf("setting mode of %s: %w", destPath, err)
+	}
+
+	modTime := reproducibleTime(gzr.ModTime)
+	if err := os.Chtimes(destPath, modTime, modTime); err != nil {
+		return fmt.Errorf("setting modification time of %s: %w", destPath, err)
+	}
+
 	return nil
 }
 
+// trackArchiveProgress wraps an opened archive so that reading it reports extraction
+// progress against the archive size. Callers must call Done on the returned reporter.
+func trackArchiveProgress(file *os.File) (io.Reader, ProgressReporter) {
+	reporter := newProgressReporter("Extracting " + filepath.Base(file.Name()))
+
+	total := int64(-1)
+	if info, err := file.Stat(); err == nil {
+		total = info.Size()
+	}
+
+	reporter.Start(total)
+
+	return &progressReader{reader: file, reporter: reporter}, reporter
+}
+
 // limitedArchiveWriter is a writer that limits the total size of the archive.
 type limitedArchiveWriter struct {
 	w        io.Writer
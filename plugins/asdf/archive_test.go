@@ -22,6 +22,7 @@ import (
 	"compress/gzip"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -203,6 +204,31 @@ func TestExtractTarGz(t *testing.T) {
 	})
 }
 
+// TestExtractTarGzRoot verifies the single top-level directory of an archive is stripped.
+func TestExtractTarGzRoot(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	archivePath := filepath.Join(dir, "tool.tar.gz")
+	CreateTestTarGz(t, archivePath, map[string]string{
+		"tool-1.0.0/bin/tool":     "binary",
+		"tool-1.0.0/conf/default": "config",
+	})
+
+	dest := filepath.Join(dir, "install")
+	require.NoError(t, asdf.ExtractTarGzRoot(archivePath, dest))
+	require.FileExists(t, filepath.Join(dest, "bin", "tool"))
+	require.FileExists(t, filepath.Join(dest, "conf", "default"))
+	require.NoDirExists(t, filepath.Join(dest, "tool-1.0.0"))
+
+	flatPath := filepath.Join(dir, "flat.tar.gz")
+	CreateTestTarGz(t, flatPath, map[string]string{"tool": "binary", "README": "readme"})
+
+	err := asdf.ExtractTarGzRoot(flatPath, filepath.Join(dir, "flat"))
+	require.ErrorIs(t, err, asdf.ErrArchiveNoSingleRootForTests())
+}
+
 func TestExtractZip(t *testing.T) {
 	t.Parallel()
 
@@ -748,3 +774,331 @@ func CreateTestTarXz(t *testing.T, path string, files map[string]string) {
 		require.NoError(t, err)
 	}
 }
+
+// testArchiveEntry describes one entry of a hand-crafted test archive.
+type testArchiveEntry struct {
+	name     string
+	linkname string
+	body     string
+	mode     os.FileMode
+	typeflag byte
+}
+
+func TestExtractMaliciousArchives(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr func() error
+		name    string
+		entries []testArchiveEntry
+	}{
+		{
+			name:    "absolute file path",
+			entries: []testArchiveEntry{{name: "/tmp/evil.txt", body: "evil"}},
+			wantErr: asdf.ErrInvalidArchiveFilePathTarForTests,
+		},
+		{
+			name:    "nested parent traversal",
+			entries: []testArchiveEntry{{name: "bin/../../evil.txt", body: "evil"}},
+			wantErr: asdf.ErrInvalidArchiveFilePathTarForTests,
+		},
+		{
+			name:    "absolute symlink",
+			entries: []testArchiveEntry{{name: "passwd", linkname: "/etc/passwd", typeflag: tar.TypeSymlink}},
+			wantErr: asdf.ErrArchiveUnsafeLinkForTests,
+		},
+		{
+			name:    "relative symlink escaping the destination",
+			entries: []testArchiveEntry{{name: "bin/up", linkname: "../../outside", typeflag: tar.TypeSymlink}},
+			wantErr: asdf.ErrArchiveUnsafeLinkForTests,
+		},
+		{
+			name: "symlink escaping through another symlink",
+			entries: []testArchiveEntry{
+				{name: "x", linkname: "d/../outside", typeflag: tar.TypeSymlink},
+				{name: "d", linkname: ".", typeflag: tar.TypeSymlink},
+			},
+			wantErr: asdf.ErrArchiveUnsafeLinkForTests,
+		},
+		{
+			name:    "hardlink outside the destination",
+			entries: []testArchiveEntry{{name: "shadow", linkname: "../../etc/shadow", typeflag: tar.TypeLink}},
+			wantErr: asdf.ErrInvalidArchiveFilePathTarForTests,
+		},
+		{
+			name: "hardlink to a symlink",
+			entries: []testArchiveEntry{
+				{name: "link", linkname: "missing", typeflag: tar.TypeSymlink},
+				{name: "hard", linkname: "link", typeflag: tar.TypeLink},
+			},
+			wantErr: asdf.ErrArchiveUnsafeLinkForTests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			archivePath := filepath.Join(tempDir, "evil.tar.gz")
+			createTestTarGzEntries(t, archivePath, tt.entries...)
+
+			destDir := filepath.Join(tempDir, "nested", "dest")
+			require.ErrorIs(t, asdf.ExtractTarGz(archivePath, destDir), tt.wantErr())
+
+			_, err := os.Lstat(filepath.Join(tempDir, "outside"))
+			require.ErrorIs(t, err, os.ErrNotExist)
+			_, err = os.Lstat(filepath.Join(tempDir, "nested", "evil.txt"))
+			require.ErrorIs(t, err, os.ErrNotExist)
+		})
+	}
+
+	t.Run("does not write through an existing symlink", func(t *testing.T) {
+		t.Parallel()
+
+		tempDir := t.TempDir()
+		outside := filepath.Join(tempDir, "outside")
+		destDir := filepath.Join(tempDir, "dest")
+		require.NoError(t, os.MkdirAll(outside, asdf.CommonDirectoryPermission))
+		require.NoError(t, os.MkdirAll(destDir, asdf.CommonDirectoryPermission))
+		require.NoError(t, os.Symlink(outside, filepath.Join(destDir, "lib")))
+
+		archivePath := filepath.Join(tempDir, "evil.tar.gz")
+		createTestTarGzEntries(t, archivePath, testArchiveEntry{name: "lib/evil.so", body: "evil"})
+
+		require.Error(t, asdf.ExtractTarGz(archivePath, destDir))
+
+		_, err := os.Stat(filepath.Join(outside, "evil.so"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("replaces a symlink instead of following it", func(t *testing.T) {
+		t.Parallel()
+
+		tempDir := t.TempDir()
+		archivePath := filepath.Join(tempDir, "archive.tar.gz")
+		createTestTarGzEntries(t, archivePath,
+			testArchiveEntry{name: "target.txt", body: "original"},
+			testArchiveEntry{name: "link", linkname: "target.txt", typeflag: tar.TypeSymlink},
+			testArchiveEntry{name: "link", body: "replaced"},
+		)
+
+		destDir := filepath.Join(tempDir, "dest")
+		require.NoError(t, asdf.ExtractTarGz(archivePath, destDir))
+
+		content, err := os.ReadFile(filepath.Join(destDir, "target.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "original", string(content))
+
+		info, err := os.Lstat(filepath.Join(destDir, "link"))
+		require.NoError(t, err)
+		require.True(t, info.Mode().IsRegular())
+	})
+
+	t.Run("extracts contained links", func(t *testing.T) {
+		t.Parallel()
+
+		tempDir := t.TempDir()
+		archivePath := filepath.Join(tempDir, "archive.tar.gz")
+		createTestTarGzEntries(t, archivePath,
+			testArchiveEntry{name: "lib/node_modules/npm/bin/npm-cli.js", body: "cli", mode: asdf.CommonExecutablePermission},
+			testArchiveEntry{name: "bin/npm", linkname: "../lib/node_modules/npm/bin/npm-cli.js", typeflag: tar.TypeSymlink},
+			testArchiveEntry{name: "bin/npm-hard", linkname: "lib/node_modules/npm/bin/npm-cli.js", typeflag: tar.TypeLink},
+			testArchiveEntry{name: "bin/dangling", linkname: "../share/missing", typeflag: tar.TypeSymlink},
+		)
+
+		destDir := filepath.Join(tempDir, "dest")
+		require.NoError(t, asdf.ExtractTarGz(archivePath, destDir))
+
+		for _, name := range []string{"npm", "npm-hard"} {
+			content, err := os.ReadFile(filepath.Join(destDir, "bin", name))
+			require.NoError(t, err)
+			require.Equal(t, "cli", string(content))
+		}
+
+		linkTarget, err := os.Readlink(filepath.Join(destDir, "bin", "dangling"))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join("..", "share", "missing"), linkTarget)
+	})
+
+	t.Run("zip entries", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tt := range map[string]struct {
+			wantErr func() error
+			entry   testArchiveEntry
+		}{
+			"absolute path":    {entry: testArchiveEntry{name: "/tmp/evil.txt", body: "evil"}, wantErr: asdf.ErrInvalidArchiveFilePathZipForTests},
+			"absolute symlink": {entry: testArchiveEntry{name: "passwd", body: "/etc/passwd", mode: os.ModeSymlink}, wantErr: asdf.ErrArchiveUnsafeLinkForTests},
+			"escaping symlink": {entry: testArchiveEntry{name: "a/b", body: "../../x", mode: os.ModeSymlink}, wantErr: asdf.ErrArchiveUnsafeLinkForTests},
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				archivePath := filepath.Join(t.TempDir(), "evil.zip")
+				createTestZipEntries(t, archivePath, tt.entry)
+
+				require.ErrorIs(t, asdf.ExtractZip(archivePath, t.TempDir()), tt.wantErr())
+			})
+		}
+	})
+}
+
+func TestExtractPreservesModes(t *testing.T) {
+	t.Parallel()
+
+	entries := []testArchiveEntry{
+		{name: "bin/tool", body: "#!/bin/sh\n", mode: asdf.CommonExecutablePermission},
+		{name: "etc/secret", body: "s", mode: 0o600},
+		{name: "etc/shared", body: "w", mode: 0o666},
+		{name: "bin/suid", body: "x", mode: os.ModeSetuid | asdf.CommonExecutablePermission},
+	}
+	want := map[string]os.FileMode{
+		"bin/tool":   asdf.CommonExecutablePermission,
+		"etc/secret": 0o600,
+		"etc/shared": asdf.TarFilePermission,
+		"bin/suid":   asdf.CommonExecutablePermission,
+	}
+
+	for name, extract := range map[string]func(t *testing.T, path, dest string) error{
+		"tar.gz": func(t *testing.T, path, dest string) error {
+			t.Helper()
+			createTestTarGzEntries(t, path, entries...)
+
+			return asdf.ExtractTarGz(path, dest)
+		},
+		"zip": func(t *testing.T, path, dest string) error {
+			t.Helper()
+			createTestZipEntries(t, path, entries...)
+
+			return asdf.ExtractZip(path, dest)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			destDir := filepath.Join(tempDir, "dest")
+			require.NoError(t, extract(t, filepath.Join(tempDir, "archive."+name), destDir))
+
+			for file, mode := range want {
+				info, err := os.Stat(filepath.Join(destDir, filepath.FromSlash(file)))
+				require.NoError(t, err)
+				require.Equal(t, mode, info.Mode(), file)
+			}
+		})
+	}
+}
+
+func TestExtractSizeLimit(t *testing.T) {
+	large := strings.Repeat("0", 600)
+
+	t.Run("caps the total decompressed size", func(t *testing.T) {
+		t.Setenv(asdf.ArchiveMaxBytesEnv, "1K")
+
+		tempDir := t.TempDir()
+
+		tarPath := filepath.Join(tempDir, "bomb.tar.gz")
+		createTestTarGzEntries(t, tarPath,
+			testArchiveEntry{name: "a", body: large}, testArchiveEntry{name: "b", body: large})
+		require.ErrorIs(t, asdf.ExtractTarGz(tarPath, filepath.Join(tempDir, "tar")),
+			asdf.ErrArchiveSizeLimitExceededForTests())
+
+		zipPath := filepath.Join(tempDir, "bomb.zip")
+		createTestZipEntries(t, zipPath,
+			testArchiveEntry{name: "a", body: large}, testArchiveEntry{name: "b", body: large})
+		require.ErrorIs(t, asdf.ExtractZip(zipPath, filepath.Join(tempDir, "zip")),
+			asdf.ErrArchiveSizeLimitExceededForTests())
+
+		gzPath := filepath.Join(tempDir, "bomb.gz")
+		CreateTestGz(t, gzPath, large+large)
+		require.ErrorIs(t, asdf.ExtractGz(gzPath, filepath.Join(tempDir, "out")),
+			asdf.ErrArchiveSizeLimitExceededForTests())
+	})
+
+	t.Run("rejects single entries above the limit", func(t *testing.T) {
+		t.Setenv(asdf.ArchiveMaxBytesEnv, "512B")
+
+		tempDir := t.TempDir()
+		archivePath := filepath.Join(tempDir, "bomb.tar.gz")
+		createTestTarGzEntries(t, archivePath, testArchiveEntry{name: "a", body: large})
+
+		err := asdf.ExtractTarGz(archivePath, filepath.Join(tempDir, "dest"))
+		require.ErrorContains(t, err, "tar entry too large")
+	})
+
+	t.Run("ignores an invalid limit", func(t *testing.T) {
+		t.Setenv(asdf.ArchiveMaxBytesEnv, "lots")
+
+		tempDir := t.TempDir()
+		archivePath := filepath.Join(tempDir, "archive.tar.gz")
+		createTestTarGzEntries(t, archivePath,
+			testArchiveEntry{name: "a", body: large}, testArchiveEntry{name: "b", body: large})
+
+		require.NoError(t, asdf.ExtractTarGz(archivePath, filepath.Join(tempDir, "dest")))
+	})
+}
+
+func createTestTarGzEntries(t *testing.T, path string, entries ...testArchiveEntry) {
+	t.Helper()
+	createArchive(t, path, func(tw *tar.Writer) {
+		for _, entry := range entries {
+			mode := entry.mode
+			if mode == 0 {
+				mode = asdf.TarFilePermission
+			}
+
+			typeflag := entry.typeflag
+			if typeflag == 0 {
+				typeflag = tar.TypeReg
+			}
+
+			header := &tar.Header{
+				Name:     entry.name,
+				Linkname: entry.linkname,
+				Typeflag: typeflag,
+				Mode:     int64(mode.Perm()),
+				Size:     int64(len(entry.body)),
+			}
+			if mode&os.ModeSetuid != 0 {
+				header.Mode |= 0o4000
+			}
+
+			require.NoError(t, tw.WriteHeader(header))
+
+			_, err := tw.Write([]byte(entry.body))
+			require.NoError(t, err)
+		}
+	})
+}
+
+func createTestZipEntries(t *testing.T, path string, entries ...testArchiveEntry) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	zipw := zip.NewWriter(file)
+	defer zipw.Close()
+
+	for _, entry := range entries {
+		mode := entry.mode
+		if mode.Perm() == 0 {
+			mode |= asdf.TarFilePermission
+			if mode&os.ModeSymlink != 0 {
+				mode |= asdf.TarLinkPermission
+			}
+		}
+
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		header.SetMode(mode)
+
+		f, err := zipw.CreateHeader(header)
+		require.NoError(t, err)
+
+		_, err = f.Write([]byte(entry.body))
+		require.NoError(t, err)
+	}
+}
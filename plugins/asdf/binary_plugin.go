@@ -19,8 +19,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	neturl "net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/sumicare/universal-asdf-plugin/plugins/github"
@@ -35,8 +38,19 @@ var (
 	errNoBinaryFound = errors.New("no binary found")
 	// errBinaryNotFoundInArchive is returned when the expected binary is missing from an extracted archive.
 	errBinaryNotFoundInArchive = errors.New("binary not found in archive")
+	// errReleaseNotFound is returned when no GitHub release is tagged with a version.
+	errReleaseNotFound = errors.New("release not found")
+	// errArchiveDirNotFound is returned when one of ArchiveDirs is missing from an extracted archive.
+	errArchiveDirNotFound = errors.New("directory not found in archive")
+	// errChecksumNotPublished is returned when the ChecksumFileTemplate asset lists no checksum for a download.
+	errChecksumNotPublished = errors.New("no published checksum")
 )
 
+// CompletionsDir is the slash-separated directory below an install path
+// holding the shell completions installed from its release archive, printed
+// by the completions-path command.
+const CompletionsDir = "share/completions"
+
 type (
 	// BinaryPlugin implements a generic asdf.Plugin for GitHub release binaries.
 	BinaryPlugin struct {
@@ -46,6 +60,9 @@ type (
 
 	// BinaryPluginConfig configures the BinaryPlugin.
 	BinaryPluginConfig struct {
+		// ArchMap maps GOARCH values to the architecture of asset names. A
+		// "<GOOS>/<GOARCH>" key, e.g. "darwin/arm64", takes precedence for
+		// assets named differently per platform (see MappedArch).
 		ArchMap             map[string]string
 		OsMap               map[string]string
 		Name                string
@@ -59,7 +76,109 @@ type (
 		ArchiveType         string
 		VersionFilter       string
 		RepoOwner           string
-		UseTags             bool
+		// TagPrefix selects the tags of one module in a repository tagging several,
+		// e.g. "kustomize/" for "kustomize/v5.4.3". Tags without it are skipped
+		// and it is stripped before VersionPrefix. Templates can use {{.TagPrefix}}.
+		TagPrefix string
+		// ReleaseURLTemplate is the URL of the release notes of a version, by
+		// default the GitHub release of {{.TagPrefix}}{{.VersionPrefix}}{{.Version}}.
+		ReleaseURLTemplate string
+		// BinaryPathInArchive is the slash-separated path of the binary inside the
+		// archive after StripComponents, e.g. "{{.BinaryName}}_{{.Version}}/{{.BinaryName}}".
+		// The binary is searched by name anywhere in the archive when empty.
+		BinaryPathInArchive string
+		// StripComponents is the number of leading directories removed from the
+		// archive member paths before matching BinaryPathInArchive, as tar --strip-components.
+		StripComponents int
+		UseTags         bool
+		// ProvenanceVerification checks the GitHub build provenance attestation of
+		// the downloaded artifact before installing it.
+		ProvenanceVerification bool
+		// ChecksumFileTemplate is the file name of a release asset listing the
+		// SHA256 of the release files in sha256sum format, e.g.
+		// "{{.BinaryName}}_{{.Version}}_checksums.txt". Downloads it lists no
+		// checksum for, or not matching theirs, fail.
+		ChecksumFileTemplate string
+		// IncludePrereleases lists prereleases and lets LatestStable select them.
+		IncludePrereleases bool
+		// ExtraBinaries are further binaries published in archives of their own
+		// in the same release, installed next to BinaryName.
+		ExtraBinaries []ReleaseBinary
+		// PostInstallCheck is a command run after install to prove the binary
+		// works, e.g. "{{.BinaryPath}} --version". The install fails and is
+		// removed when it exits non-zero or prints nothing.
+		PostInstallCheck string
+		// HashIgnoreGlobs lists install files left out of tool checksums (see HashIgnorer).
+		HashIgnoreGlobs []string
+		// ArchiveDirs are slash-separated directories of the archive, after
+		// StripComponents, copied into the install path next to bin, e.g. the
+		// include directory of protoc holding the well-known types.
+		ArchiveDirs []string
+		// ExtraInstallFiles select further archive files, such as shell
+		// completions and man pages, copied into the install path next to bin.
+		// Globs matching nothing are skipped, as not every release ships them.
+		ExtraInstallFiles []ExtraInstallFile
+		// APIBaseURL is the GitHub API the releases are listed from, e.g.
+		// "https://ghe.example.com/api/v3", github.APIURL when empty.
+		APIBaseURL string
+		// DownloadBaseURL replaces "https://github.com" in download and
+		// release URLs, e.g. "https://ghe.example.com", github.DownloadURL
+		// when empty.
+		DownloadBaseURL string
+		// ExpansionFactor is the install size divided by the download size,
+		// DefaultExpansionFactor when zero (see ExpansionHinter).
+		ExpansionFactor float64
+		// SupportedPlatforms restricts the OsMap and ArchMap combinations with a
+		// release asset to these "<os>/<arch>" pairs, e.g. when BSD builds are
+		// published for amd64 only. Every combination is supported when empty.
+		SupportedPlatforms []string
+		// PlatformFileNameTemplates replace FileNameTemplate on the platforms of
+		// their "<os>/<arch>" or "<os>" key, e.g. for assets packaged differently.
+		PlatformFileNameTemplates map[string]string
+		// PlatformArchiveTypes replace ArchiveType on the platforms of their
+		// "<os>/<arch>" or "<os>" key, e.g. "none" where a bare binary is
+		// published instead of the archive of other platforms.
+		PlatformArchiveTypes map[string]string
+	}
+
+	// ReleaseBinary is a binary published in its own archive of a release.
+	// The archive is unpacked the same way as the main one.
+	ReleaseBinary struct {
+		FileNameTemplate string
+		BinaryName       string
+	}
+
+	// ExtraInstallFile selects archive files installed next to the binary.
+	ExtraInstallFile struct {
+		// ArchivePathGlob is a slash-separated glob of archive member paths,
+		// after StripComponents, e.g. "completions/*" or "*/share/man/man1/*.1".
+		ArchivePathGlob string
+		// InstallRelDir is the slash-separated directory below the install
+		// path the matches are copied into, e.g. CompletionsDir.
+		InstallRelDir string
+	}
+
+	// assetPlatform is the running platform as GetPlatform and GetArch values
+	// and as named by the release assets.
+	assetPlatform struct {
+		os         string
+		arch       string
+		mappedOS   string
+		mappedArch string
+	}
+
+	// archiveMember selects the binary inside an extracted archive.
+	archiveMember struct {
+		// name is matched against the base name of every member when path is empty.
+		name string
+		// path is the slash-separated member path after removing strip directories.
+		path  string
+		strip int
+	}
+
+	// releasesWithAssets lists only the GitHub releases that have at least one asset.
+	releasesWithAssets struct {
+		*github.Client
 	}
 )
 
@@ -76,7 +195,11 @@ func NewBinaryPlugin(config *BinaryPluginConfig) *BinaryPlugin {
 	}
 
 	if cfg.DownloadURLTemplate == "" {
-		cfg.DownloadURLTemplate = "https://github.com/{{.RepoOwner}}/{{.RepoName}}/releases/download/v{{.Version}}/{{.FileName}}"
+		cfg.DownloadURLTemplate = "https://github.com/{{.RepoOwner}}/{{.RepoName}}/releases/download/{{.TagPrefix}}v{{.Version}}/{{.FileName}}"
+	}
+
+	if cfg.ReleaseURLTemplate == "" {
+		cfg.ReleaseURLTemplate = "https://github.com/{{.RepoOwner}}/{{.RepoName}}/releases/tag/{{.TagPrefix}}{{.VersionPrefix}}{{.Version}}"
 	}
 
 	if cfg.OsMap == nil {
@@ -93,9 +216,14 @@ func NewBinaryPlugin(config *BinaryPluginConfig) *BinaryPlugin {
 		}
 	}
 
+	client := github.NewClient()
+	if cfg.APIBaseURL != "" {
+		client = github.NewClientWithAPIURL(cfg.APIBaseURL)
+	}
+
 	return &BinaryPlugin{
 		Config: &cfg,
-		Github: github.NewClient(),
+		Github: client,
 	}
 }
 
@@ -111,59 +239,357 @@ func (plugin *BinaryPlugin) Name() string {
 	return plugin.Config.Name
 }
 
+// GitHubRepository returns the repository the releases are published from.
+func (plugin *BinaryPlugin) GitHubRepository() (owner, repo string) {
+	return plugin.Config.RepoOwner, plugin.Config.RepoName
+}
+
+// HashIgnoreGlobs returns the install files left out of tool checksums.
+func (plugin *BinaryPlugin) HashIgnoreGlobs() []string {
+	return plugin.Config.HashIgnoreGlobs
+}
+
+// ExpansionFactor returns the configured ExpansionFactor.
+func (plugin *BinaryPlugin) ExpansionFactor() float64 {
+	return plugin.Config.ExpansionFactor
+}
+
+// BinaryName returns the name of the installed binary.
+func (plugin *BinaryPlugin) BinaryName() string {
+	return plugin.Config.BinaryName
+}
+
 // ListAll lists all available versions.
 func (plugin *BinaryPlugin) ListAll(ctx context.Context) ([]string, error) {
-	return ListGitHubVersions(ctx, plugin.Github, &ListGitHubVersionsConfig{
-		RepoOwner:     plugin.Config.RepoOwner,
-		RepoName:      plugin.Config.RepoName,
-		VersionPrefix: plugin.Config.VersionPrefix,
-		VersionFilter: plugin.Config.VersionFilter,
-		UseTags:       plugin.Config.UseTags,
+	return plugin.listVersions(ctx, plugin.Github, plugin.Config.IncludePrereleases)
+}
+
+// listVersions lists the versions published through client, with prereleases
+// when includePrereleases is set. Missing repositories, rejected credentials
+// and exhausted rate limits are reported with the plugin name.
+func (plugin *BinaryPlugin) listVersions(ctx context.Context, client interface {
+	GetReleases(ctx context.Context, url string) ([]string, error)
+	GetTags(ctx context.Context, url string) ([]string, error)
+}, includePrereleases bool,
+) ([]string, error) {
+	versions, err := ListGitHubVersions(ctx, client, &ListGitHubVersionsConfig{
+		RepoOwner:          plugin.Config.RepoOwner,
+		RepoName:           plugin.Config.RepoName,
+		TagPrefix:          plugin.Config.TagPrefix,
+		VersionPrefix:      plugin.Config.VersionPrefix,
+		VersionFilter:      plugin.Config.VersionFilter,
+		UseTags:            plugin.Config.UseTags,
+		IncludePrereleases: includePrereleases,
 	})
+	if errors.Is(err, github.ErrRepoNotFound) || errors.Is(err, github.ErrRateLimited) ||
+		errors.Is(err, github.ErrUnauthorized) {
+		return nil, fmt.Errorf("%s: %w", plugin.Config.Name, err)
+	}
+
+	return versions, err
 }
 
-// Download downloads the specified version.
-func (plugin *BinaryPlugin) Download(ctx context.Context, version, downloadPath string) error {
+// assetPlatform returns the current platform and architecture, failing when
+// the plugin has no release asset for them.
+func (plugin *BinaryPlugin) assetPlatform() (assetPlatform, error) {
 	platform, err := GetPlatform()
 	if err != nil {
-		return err
+		return assetPlatform{}, err
 	}
 
 	arch, err := GetArch()
 	if err != nil {
-		return err
+		return assetPlatform{}, err
 	}
 
 	mappedPlatform, ok := plugin.Config.OsMap[platform]
 	if !ok {
-		return fmt.Errorf("%w: %s", errUnsupportedPlatform, platform)
+		return assetPlatform{}, UnsupportedPlatformError(errUnsupportedPlatform, platform, plugin.Config.Platforms())
 	}
 
-	mappedArch, ok := plugin.Config.ArchMap[arch]
+	mappedArch, ok := plugin.Config.MappedArch(platform, arch)
 	if !ok {
-		return fmt.Errorf("%w: %s", errUnsupportedArchitecture, arch)
+		return assetPlatform{}, fmt.Errorf("%w: %s (set %s to select another architecture)",
+			errUnsupportedArchitecture, arch, ForceArchEnv)
+	}
+
+	if !plugin.Config.supports(platform, arch) {
+		return assetPlatform{}, UnsupportedPlatformError(errUnsupportedPlatform, platform+"/"+arch, plugin.Config.Platforms())
 	}
 
-	fileName := plugin.Config.FileNameTemplate
+	return assetPlatform{os: platform, arch: arch, mappedOS: mappedPlatform, mappedArch: mappedArch}, nil
+}
+
+// supports reports whether SupportedPlatforms, when set, lists platform/arch.
+func (config *BinaryPluginConfig) supports(platform, arch string) bool {
+	return len(config.SupportedPlatforms) == 0 || slices.Contains(config.SupportedPlatforms, platform+"/"+arch)
+}
+
+// FileNameTemplateFor returns the template of the asset names on platform and
+// arch, GetPlatform and GetArch values: the PlatformFileNameTemplates entry of
+// "<platform>/<arch>", else of platform, else FileNameTemplate.
+func (config *BinaryPluginConfig) FileNameTemplateFor(platform, arch string) string {
+	if template, ok := config.PlatformFileNameTemplates[platform+"/"+arch]; ok {
+		return template
+	}
+
+	if template, ok := config.PlatformFileNameTemplates[platform]; ok {
+		return template
+	}
+
+	return config.FileNameTemplate
+}
+
+// ArchiveTypeFor returns the archive type of the assets on platform and arch,
+// GetPlatform and GetArch values: the PlatformArchiveTypes entry of
+// "<platform>/<arch>", else of platform, else ArchiveType.
+func (config *BinaryPluginConfig) ArchiveTypeFor(platform, arch string) string {
+	if archiveType, ok := config.PlatformArchiveTypes[platform+"/"+arch]; ok {
+		return archiveType
+	}
 
-	fileName = strings.ReplaceAll(fileName, "{{.Version}}", version)
-	fileName = strings.ReplaceAll(fileName, "{{.Platform}}", mappedPlatform)
-	fileName = strings.ReplaceAll(fileName, "{{.Arch}}", mappedArch)
-	fileName = strings.ReplaceAll(fileName, "{{.BinaryName}}", plugin.Config.BinaryName)
+	if archiveType, ok := config.PlatformArchiveTypes[platform]; ok {
+		return archiveType
+	}
+
+	return config.ArchiveType
+}
+
+// MappedArch returns the asset architecture of arch on platform: the ArchMap
+// entry of "<platform>/<arch>", else of arch.
+func (config *BinaryPluginConfig) MappedArch(platform, arch string) (string, bool) {
+	if mapped, ok := config.ArchMap[platform+"/"+arch]; ok {
+		return mapped, true
+	}
+
+	mapped, ok := config.ArchMap[arch]
+
+	return mapped, ok
+}
+
+// Platforms returns the sorted "<os>/<arch>" pairs with a release asset: each
+// OsMap platform with each ArchMap architecture, and the "<os>/<arch>" keys of
+// ArchMap whose platform is mapped, restricted to SupportedPlatforms.
+func (config *BinaryPluginConfig) Platforms() []string {
+	var platforms []string
+
+	for key := range config.ArchMap {
+		if platform, _, found := strings.Cut(key, "/"); found {
+			if _, ok := config.OsMap[platform]; ok {
+				platforms = append(platforms, key)
+			}
+
+			continue
+		}
+
+		for platform := range config.OsMap {
+			platforms = append(platforms, platform+"/"+key)
+		}
+	}
+
+	platforms = slices.DeleteFunc(platforms, func(platform string) bool {
+		return len(config.SupportedPlatforms) > 0 && !slices.Contains(config.SupportedPlatforms, platform)
+	})
+
+	slices.Sort(platforms)
+
+	return slices.Compact(platforms)
+}
+
+// renderTemplate replaces the version, platform, architecture and binary name placeholders.
+func (plugin *BinaryPlugin) renderTemplate(template, version, platform, arch string) string {
+	out := strings.ReplaceAll(template, "{{.Version}}", version)
+	out = strings.ReplaceAll(out, "{{.Platform}}", platform)
+	out = strings.ReplaceAll(out, "{{.Arch}}", arch)
+
+	return strings.ReplaceAll(out, "{{.BinaryName}}", plugin.Config.BinaryName)
+}
+
+// ArtifactNames returns the names of the files Download stores for version.
+func (plugin *BinaryPlugin) ArtifactNames(version string) ([]string, error) {
+	binaries := plugin.releaseBinaries()
+
+	names := make([]string, 0, len(binaries))
+	for _, binary := range binaries {
+		artifact, err := binary.artifact(version)
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, artifact.Name)
+	}
+
+	return names, nil
+}
+
+// ResolveArtifacts returns the files Download fetches for version, rendered
+// from FileNameTemplate and DownloadURLTemplate, one per release binary.
+func (plugin *BinaryPlugin) ResolveArtifacts(_ context.Context, version string) ([]Artifact, error) {
+	binaries := plugin.releaseBinaries()
+
+	artifacts := make([]Artifact, 0, len(binaries))
+	for _, binary := range binaries {
+		artifact, err := binary.artifact(version)
+		if err != nil {
+			return nil, err
+		}
+
+		artifacts = append(artifacts, artifact)
+	}
+
+	return artifacts, nil
+}
+
+// DescribeArtifacts returns the platform and architecture the asset names
+// are rendered with, the archive type and the path of the installed binary.
+func (plugin *BinaryPlugin) DescribeArtifacts(_ string) (ArtifactDescription, error) {
+	target, err := plugin.assetPlatform()
+	if err != nil {
+		return ArtifactDescription{}, err
+	}
+
+	archiveType := plugin.Config.ArchiveTypeFor(target.os, target.arch)
+	if archiveType == "" {
+		archiveType = "none"
+	}
+
+	return ArtifactDescription{
+		Platform:    target.mappedOS,
+		Arch:        target.mappedArch,
+		ArchiveType: archiveType,
+		BinaryPath:  path.Join(plugin.ListBinPaths(), plugin.Config.BinaryName),
+	}, nil
+}
+
+// archiveType returns the configured ArchiveType, "none" when unset.
+func (plugin *BinaryPlugin) archiveType() string {
+	if plugin.Config.ArchiveType == "" {
+		return "none"
+	}
+
+	return plugin.Config.ArchiveType
+}
+
+// releaseBinaries returns a plugin per binary of a release: plugin itself,
+// followed by one for each of ExtraBinaries.
+func (plugin *BinaryPlugin) releaseBinaries() []*BinaryPlugin {
+	binaries := []*BinaryPlugin{plugin}
+
+	for _, extra := range plugin.Config.ExtraBinaries {
+		cfg := *plugin.Config
+		cfg.FileNameTemplate, cfg.BinaryName, cfg.ExtraBinaries = extra.FileNameTemplate, extra.BinaryName, nil
+		cfg.PlatformFileNameTemplates = nil
+		cfg.ArchiveDirs, cfg.ExtraInstallFiles = nil, nil
+
+		binaries = append(binaries, &BinaryPlugin{Config: &cfg, Github: plugin.Github})
+	}
+
+	return binaries
+}
+
+// artifact renders the file name and download URL of version for the running platform.
+func (plugin *BinaryPlugin) artifact(version string) (Artifact, error) {
+	target, err := plugin.assetPlatform()
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	mappedPlatform, mappedArch := target.mappedOS, target.mappedArch
+
+	fileName := plugin.renderTemplate(
+		plugin.Config.FileNameTemplateFor(target.os, target.arch), version, mappedPlatform, mappedArch)
 
 	url := plugin.Config.DownloadURLTemplate
 
 	url = strings.ReplaceAll(url, "{{.RepoOwner}}", plugin.Config.RepoOwner)
 	url = strings.ReplaceAll(url, "{{.RepoName}}", plugin.Config.RepoName)
-	url = strings.ReplaceAll(url, "{{.Version}}", version)
+	url = strings.ReplaceAll(url, "{{.TagPrefix}}", neturl.PathEscape(plugin.Config.TagPrefix))
 	url = strings.ReplaceAll(url, "{{.FileName}}", fileName)
 
-	url = strings.ReplaceAll(url, "{{.Platform}}", mappedPlatform)
-	url = strings.ReplaceAll(url, "{{.Arch}}", mappedArch)
-	url = strings.ReplaceAll(url, "{{.BinaryName}}", plugin.Config.BinaryName)
+	url = plugin.rebaseDownloadURL(plugin.renderTemplate(url, version, mappedPlatform, mappedArch))
+
+	return Artifact{Name: fileName, URL: url}, nil
+}
+
+// downloadBaseURL returns DownloadBaseURL, or github.DownloadURL when unset.
+func (plugin *BinaryPlugin) downloadBaseURL() string {
+	if plugin.Config.DownloadBaseURL != "" {
+		return strings.TrimRight(plugin.Config.DownloadBaseURL, "/")
+	}
+
+	return github.DownloadURL()
+}
+
+// rebaseDownloadURL moves a github.com URL to the download base URL.
+func (plugin *BinaryPlugin) rebaseDownloadURL(url string) string {
+	rest, found := strings.CutPrefix(url, github.DefaultDownloadURL+"/")
+	if !found {
+		return url
+	}
+
+	return plugin.downloadBaseURL() + "/" + rest
+}
+
+// ReleaseURL returns the URL of the release notes of version.
+func (plugin *BinaryPlugin) ReleaseURL(version string) string {
+	url := strings.NewReplacer(
+		"{{.RepoOwner}}", plugin.Config.RepoOwner,
+		"{{.RepoName}}", plugin.Config.RepoName,
+		"{{.TagPrefix}}", plugin.Config.TagPrefix,
+		"{{.VersionPrefix}}", plugin.Config.VersionPrefix,
+	).Replace(plugin.Config.ReleaseURLTemplate)
+
+	return plugin.rebaseDownloadURL(plugin.renderTemplate(url, version, "", ""))
+}
+
+// ReleaseNotes returns the body of the GitHub release of version.
+func (plugin *BinaryPlugin) ReleaseNotes(ctx context.Context, version string) (string, error) {
+	repoURL := fmt.Sprintf("https://github.com/%s/%s", plugin.Config.RepoOwner, plugin.Config.RepoName)
+
+	releases, err := plugin.Github.GetReleaseDetails(ctx, repoURL)
+	if err != nil {
+		return "", err
+	}
+
+	tag := plugin.Config.TagPrefix + plugin.Config.VersionPrefix + version
+	for _, release := range releases {
+		if release.TagName == tag || release.TagName == version {
+			return release.Body, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s %s", errReleaseNotFound, plugin.Config.Name, tag)
+}
+
+// Download downloads the specified version.
+func (plugin *BinaryPlugin) Download(ctx context.Context, version, downloadPath string) error {
+	for _, binary := range plugin.releaseBinaries() {
+		if err := binary.download(ctx, version, downloadPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// download downloads the archive of the plugin binary.
+func (plugin *BinaryPlugin) download(ctx context.Context, version, downloadPath string) error {
+	artifact, err := plugin.artifact(version)
+	if err != nil {
+		return err
+	}
+
+	fileName, url := artifact.Name, plugin.releaseAssetURL(ctx, artifact.URL)
 
 	binaryPath := filepath.Join(downloadPath, fileName)
 
+	Logger().DebugContext(ctx, "resolved download URL",
+		"tool", plugin.Config.Name, "version", version, "url", url, "dest", binaryPath)
+
+	if plugin.Config.ChecksumFileTemplate != "" {
+		return plugin.downloadVerified(ctx, version, url, binaryPath)
+	}
+
 	if info, err := os.Stat(binaryPath); err == nil && info.Size() > 1024 {
 		Msgf("Using cached download for %s %s", plugin.Config.Name, version)
 
@@ -183,90 +609,305 @@ func (plugin *BinaryPlugin) Download(ctx context.Context, version, downloadPath
 	return nil
 }
 
+// downloadVerified downloads url to binaryPath, checked against the SHA256 the
+// ChecksumFileTemplate asset of the release lists for it. A cached download
+// matching its checksum sidecar is used without asking the release again.
+func (plugin *BinaryPlugin) downloadVerified(ctx context.Context, version, url, binaryPath string) error {
+	if VerifyChecksumSidecar(binaryPath) == nil {
+		Msgf("Using cached download for %s %s", plugin.Config.Name, version)
+
+		return nil
+	}
+
+	expected, err := plugin.publishedChecksum(ctx, version, filepath.Base(binaryPath))
+	if err != nil {
+		return err
+	}
+
+	Msgf("Downloading %s %s from %s", plugin.Config.Name, version, url)
+
+	if err := DownloadVerifiedFile(ctx, url, binaryPath, ExpectedFile{SHA256: expected}); err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+
+	if err := os.Chmod(binaryPath, CommonExecutablePermission); err != nil {
+		return fmt.Errorf("failed to make binary executable: %w", err)
+	}
+
+	return nil
+}
+
+// publishedChecksum returns the SHA256 the ChecksumFileTemplate asset of the
+// release of version lists for the release file fileName.
+func (plugin *BinaryPlugin) publishedChecksum(ctx context.Context, version, fileName string) (string, error) {
+	cfg := *plugin.Config
+	cfg.FileNameTemplate = cfg.ChecksumFileTemplate
+
+	checksums, err := (&BinaryPlugin{Config: &cfg, Github: plugin.Github}).artifact(version)
+	if err != nil {
+		return "", err
+	}
+
+	sums, err := DownloadString(ctx, plugin.releaseAssetURL(ctx, checksums.URL))
+	if err != nil {
+		return "", fmt.Errorf("downloading %s checksums: %w", plugin.Config.Name, err)
+	}
+
+	for line := range strings.SplitSeq(sums, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == fileName {
+			return fields[0], nil
+		}
+	}
+
+	return "", fmt.Errorf("%w for %s in %s", errChecksumNotPublished, fileName, checksums.Name)
+}
+
+// releaseAssetURL returns the browser_download_url the GitHub API lists for
+// a release download URL under the download base URL, so that downloads come
+// from the host the client talks to, e.g. GitHub Enterprise or a mock server.
+// Other URLs, and assets the API does not list, are returned unchanged.
+func (plugin *BinaryPlugin) releaseAssetURL(ctx context.Context, downloadURL string) string {
+	owner, repo, tag, name, ok := parseReleaseDownloadURL(downloadURL, plugin.downloadBaseURL())
+	if !ok || plugin.Github == nil {
+		return downloadURL
+	}
+
+	release, err := plugin.Github.GetReleaseByTag(ctx, "https://github.com/"+owner+"/"+repo, tag)
+	if err != nil {
+		Logger().DebugContext(ctx, "release assets unavailable, using the release download URL",
+			"tool", plugin.Config.Name, "url", downloadURL, "error", err)
+
+		return downloadURL
+	}
+
+	for _, asset := range release.Assets {
+		if asset.Name == name && asset.BrowserDownloadURL != "" {
+			return asset.BrowserDownloadURL
+		}
+	}
+
+	return downloadURL
+}
+
+// parseReleaseDownloadURL splits a
+// "<base>/<owner>/<repo>/releases/download/<tag>/<name>" URL.
+func parseReleaseDownloadURL(downloadURL, base string) (owner, repo, tag, name string, ok bool) {
+	rest, found := strings.CutPrefix(downloadURL, base+"/")
+	if !found {
+		return "", "", "", "", false
+	}
+
+	segments := strings.Split(rest, "/")
+	if len(segments) != 6 || segments[2] != "releases" || segments[3] != "download" {
+		return "", "", "", "", false
+	}
+
+	for i, segment := range segments {
+		unescaped, err := neturl.PathUnescape(segment)
+		if err != nil {
+			return "", "", "", "", false
+		}
+
+		segments[i] = unescaped
+	}
+
+	return segments[0], segments[1], segments[4], segments[5], true
+}
+
 // Install installs the downloaded version.
 func (plugin *BinaryPlugin) Install(
 	ctx context.Context,
 	version, downloadPath, installPath string,
 ) error {
-	entries, err := os.ReadDir(downloadPath)
-	if err != nil && !os.IsNotExist(err) {
+	archives, err := plugin.downloadedArchives(ctx, version, downloadPath)
+	if err != nil {
 		return err
 	}
 
-	var binaryName string
+	var provenance *ProvenanceResult
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			binaryName = entry.Name()
+	if plugin.Config.ProvenanceVerification {
+		client := plugin.Github
+		if client == nil {
+			client = github.NewClient()
+		}
+
+		for _, archive := range archives {
+			result, err := CheckProvenance(ctx, client, plugin.Config.RepoOwner, plugin.Config.RepoName, archive)
+			if err != nil {
+				return err
+			}
 
-			break
+			if provenance == nil {
+				provenance = &result
+			}
 		}
 	}
 
-	if binaryName == "" {
-		// Attempt to download if not found
-		err := plugin.Download(ctx, version, downloadPath)
+	Msgf("Installing %s %s to %s", plugin.Config.Name, version, installPath)
+
+	binDir := filepath.Join(installPath, "bin")
+	if err := EnsureDir(binDir); err != nil {
+		return err
+	}
+
+	for i, binary := range plugin.releaseBinaries() {
+		if err := binary.installBinary(version, archives[i], binDir); err != nil {
+			return err
+		}
+	}
+
+	if err := plugin.postInstallCheck(ctx, version, binDir); err != nil {
+		if removeErr := os.RemoveAll(installPath); removeErr != nil {
+			Logger().Warn("failed to remove broken install", "path", installPath, "error", removeErr)
+		}
+
+		return err
+	}
+
+	if provenance != nil {
+		if err := WriteProvenance(installPath, *provenance); err != nil {
+			return err
+		}
+	}
+
+	if err := plugin.writeInstallMetadata(ctx, version, archives, installPath); err != nil {
+		return err
+	}
+
+	Msgf("%s %s installed successfully", plugin.Config.Name, version)
+
+	return nil
+}
+
+// writeInstallMetadata records the archives of every release binary the
+// install of version was made from in its install metadata.
+func (plugin *BinaryPlugin) writeInstallMetadata(ctx context.Context, version string, archives []string, installPath string) error {
+	metadata := InstallMetadata{Plugin: plugin.Config.Name, Version: version}
+
+	for i, binary := range plugin.releaseBinaries() {
+		var url string
+		if artifact, err := binary.artifact(version); err == nil {
+			url = artifact.URL
+		}
+
+		artifact, err := NewInstallArtifact(archives[i], url)
 		if err != nil {
-			return fmt.Errorf("downloading %s %s: %w", plugin.Config.Name, version, err)
+			return fmt.Errorf("describing %s: %w", archives[i], err)
 		}
 
-		// Re-read directory
-		entries, err = os.ReadDir(downloadPath)
+		metadata.Artifacts = append(metadata.Artifacts, artifact)
+	}
+
+	return WriteInstallMetadata(ctx, installPath, metadata)
+}
+
+// downloadedArchives returns the downloaded archive of every release binary,
+// downloading version first when any is missing from downloadPath.
+func (plugin *BinaryPlugin) downloadedArchives(ctx context.Context, version, downloadPath string) ([]string, error) {
+	archives, err := plugin.findArchives(version, downloadPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if archives == nil {
+		// Attempt to download if not found
+		if err := plugin.Download(ctx, version, downloadPath); err != nil {
+			return nil, fmt.Errorf("downloading %s %s: %w", plugin.Config.Name, version, err)
+		}
+
+		archives, err = plugin.findArchives(version, downloadPath)
 		if err != nil {
-			return err
+			return nil, err
+		}
+	}
+
+	if archives == nil {
+		return nil, fmt.Errorf("%w in %s", errNoBinaryFound, downloadPath)
+	}
+
+	return archives, nil
+}
+
+// findArchives returns the archive of every release binary in downloadPath,
+// or nil when any is missing. A plugin without ExtraBinaries installs
+// whichever file is there.
+func (plugin *BinaryPlugin) findArchives(version, downloadPath string) ([]string, error) {
+	if len(plugin.Config.ExtraBinaries) == 0 {
+		entries, err := os.ReadDir(downloadPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
 		}
 
 		for _, entry := range entries {
 			if !entry.IsDir() {
-				binaryName = entry.Name()
-
-				break
+				return []string{filepath.Join(downloadPath, entry.Name())}, nil
 			}
 		}
+
+		return nil, nil
 	}
 
-	if binaryName == "" {
-		return fmt.Errorf("%w in %s", errNoBinaryFound, downloadPath)
+	names, err := plugin.ArtifactNames(version)
+	if err != nil {
+		return nil, err
 	}
 
-	binaryPath := filepath.Join(downloadPath, binaryName)
+	archives := make([]string, 0, len(names))
+	for _, name := range names {
+		archive := filepath.Join(downloadPath, name)
+		if _, err := os.Stat(archive); err != nil {
+			return nil, nil
+		}
 
-	Msgf("Installing %s %s to %s", plugin.Config.Name, version, installPath)
+		archives = append(archives, archive)
+	}
 
-	binDir := filepath.Join(installPath, "bin")
-	if err := EnsureDir(binDir); err != nil {
+	return archives, nil
+}
+
+// installBinary unpacks the plugin binary from archivePath into binDir.
+func (plugin *BinaryPlugin) installBinary(version, archivePath, binDir string) error {
+	destPath := filepath.Join(binDir, plugin.Config.BinaryName)
+
+	member := archiveMember{name: plugin.Config.BinaryName, strip: plugin.Config.StripComponents}
+
+	target, err := plugin.assetPlatform()
+	if err != nil {
 		return err
 	}
 
-	destPath := filepath.Join(binDir, plugin.Config.BinaryName)
+	if plugin.Config.BinaryPathInArchive != "" {
+		member.path = plugin.renderTemplate(plugin.Config.BinaryPathInArchive, version, target.mappedOS, target.mappedArch)
+	}
 
-	switch plugin.Config.ArchiveType {
+	switch plugin.Config.ArchiveTypeFor(target.os, target.arch) {
 	case "gz":
-		err := ExtractGz(binaryPath, destPath)
+		err := ExtractGz(archivePath, destPath)
 		if err != nil {
 			return fmt.Errorf("failed to extract gz: %w", err)
 		}
 
 	case "tar.gz":
-		err := extractAndCopyBinary(binaryPath, destPath, plugin.Config.BinaryName, ExtractTarGz)
+		err := extractAndCopyBinary(archivePath, destPath, member, plugin.Config.ArchiveDirs, plugin.Config.ExtraInstallFiles, ExtractTarGz)
 		if err != nil {
 			return err
 		}
 
 	case "tar.xz":
-		err := extractAndCopyBinary(binaryPath, destPath, plugin.Config.BinaryName, ExtractTarXz)
+		err := extractAndCopyBinary(archivePath, destPath, member, plugin.Config.ArchiveDirs, plugin.Config.ExtraInstallFiles, ExtractTarXz)
 		if err != nil {
 			return err
 		}
 
 	case "zip":
-		err := extractAndCopyBinary(binaryPath, destPath, plugin.Config.BinaryName, ExtractZip)
+		err := extractAndCopyBinary(archivePath, destPath, member, plugin.Config.ArchiveDirs, plugin.Config.ExtraInstallFiles, ExtractZip)
 		if err != nil {
 			return err
 		}
 
 	default:
-		err := CopyFile(binaryPath, destPath, CommonExecutablePermission)
+		err := CopyFile(archivePath, destPath, CommonExecutablePermission)
 		if err != nil {
 			return fmt.Errorf("failed to copy binary: %w", err)
 		}
@@ -276,14 +917,18 @@ func (plugin *BinaryPlugin) Install(
 		return fmt.Errorf("failed to make binary executable: %w", err)
 	}
 
-	Msgf("%s %s installed successfully", plugin.Config.Name, version)
-
 	return nil
 }
 
-// extractAndCopyBinary extracts an archive to a temp directory, finds the binary by name, and copies it to destPath.
+// extractAndCopyBinary extracts an archive to a temp directory, finds the binary
+// selected by member, and moves it to destPath. Each of dirs, after stripping
+// the leading directories of member, replaces its copy in the parent of the
+// directory of destPath, the install path.
 func extractAndCopyBinary(
-	archivePath, destPath, binaryName string,
+	archivePath, destPath string,
+	member archiveMember,
+	dirs []string,
+	files []ExtraInstallFile,
 	extractFn func(string, string) error,
 ) error {
 	tempDir, err := os.MkdirTemp("", "asdf-extract-*")
@@ -296,15 +941,48 @@ func extractAndCopyBinary(
 		return fmt.Errorf("failed to extract archive: %w", err)
 	}
 
+	installPath := filepath.Dir(filepath.Dir(destPath))
+
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(tempDir, strings.Repeat("*/", member.strip)+filepath.FromSlash(dir)))
+		if err != nil || len(matches) == 0 {
+			return fmt.Errorf("%w: %s in %s", errArchiveDirNotFound, dir, filepath.Base(archivePath))
+		}
+
+		target := filepath.Join(installPath, filepath.FromSlash(dir))
+		if err := os.RemoveAll(target); err != nil {
+			return err
+		}
+
+		if err := CopyDir(matches[0], target); err != nil {
+			return fmt.Errorf("copying %s from archive: %w", dir, err)
+		}
+	}
+
+	for _, file := range files {
+		if err := copyExtraInstallFile(tempDir, installPath, member.strip, file); err != nil {
+			return err
+		}
+	}
+
 	foundPath := ""
 
-	if err := filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
+	if err := filepath.Walk(tempDir, func(entryPath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if !info.IsDir() && info.Name() == binaryName {
-			foundPath = path
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(tempDir, entryPath)
+		if err != nil {
+			return err
+		}
+
+		if member.matches(filepath.ToSlash(relPath)) {
+			foundPath = entryPath
 
 			return filepath.SkipAll
 		}
@@ -315,7 +993,11 @@ func extractAndCopyBinary(
 	}
 
 	if foundPath == "" {
-		return fmt.Errorf("%w: %s", errBinaryNotFoundInArchive, binaryName)
+		return fmt.Errorf("%w: %s in %s", errBinaryNotFoundInArchive, member, filepath.Base(archivePath))
+	}
+
+	if err := os.Rename(foundPath, destPath); err == nil {
+		return nil
 	}
 
 	if err := CopyFile(foundPath, destPath, CommonExecutablePermission); err != nil {
@@ -325,6 +1007,77 @@ func extractAndCopyBinary(
 	return nil
 }
 
+// copyExtraInstallFile copies the members of the archive extracted in
+// tempDir matching file into its directory below installPath.
+func copyExtraInstallFile(tempDir, installPath string, strip int, file ExtraInstallFile) error {
+	matches, err := filepath.Glob(filepath.Join(tempDir, strings.Repeat("*/", strip)+filepath.FromSlash(file.ArchivePathGlob)))
+	if err != nil {
+		return fmt.Errorf("matching %s: %w", file.ArchivePathGlob, err)
+	}
+
+	if len(matches) == 0 {
+		Logger().Debug("no archive files to install", "glob", file.ArchivePathGlob)
+
+		return nil
+	}
+
+	targetDir := filepath.Join(installPath, filepath.FromSlash(file.InstallRelDir))
+	if err := EnsureDir(targetDir); err != nil {
+		return err
+	}
+
+	for _, match := range matches {
+		target := filepath.Join(targetDir, filepath.Base(match))
+
+		info, err := os.Stat(match)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			err = CopyDir(match, target)
+		} else {
+			err = CopyFile(match, target, CommonFilePermission)
+		}
+
+		if err != nil {
+			return fmt.Errorf("copying %s from archive: %w", filepath.Base(match), err)
+		}
+	}
+
+	return nil
+}
+
+// matches reports whether the slash-separated archive member path is the binary.
+func (member archiveMember) matches(relPath string) bool {
+	parts := strings.Split(relPath, "/")
+	if len(parts) <= member.strip {
+		return false
+	}
+
+	stripped := strings.Join(parts[member.strip:], "/")
+
+	if member.path != "" {
+		return stripped == path.Clean(member.path)
+	}
+
+	return path.Base(stripped) == member.name
+}
+
+// String describes the expected member for errors.
+func (member archiveMember) String() string {
+	expected := member.name
+	if member.path != "" {
+		expected = member.path
+	}
+
+	if member.strip > 0 {
+		return fmt.Sprintf("%s (after stripping %d leading directories)", expected, member.strip)
+	}
+
+	return expected
+}
+
 // Uninstall removes the specified version.
 func (*BinaryPlugin) Uninstall(_ context.Context, installPath string) error {
 	return os.RemoveAll(installPath)
@@ -347,26 +1100,65 @@ func (*BinaryPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy version file.
 func (*BinaryPlugin) ParseLegacyFile(path string) (string, error) {
-	return ReadLegacyVersionFile(path)
+	return ReadVersionFile(path)
 }
 
-// LatestStable returns the latest stable version.
+// LatestStable returns the latest stable version. When versions come from GitHub
+// releases, releases without assets are skipped since there is nothing to download.
+// The pattern is a VersionQuery; one starting with PrereleaseQueryPrefix, or
+// IncludePrereleases, admits prereleases.
 func (plugin *BinaryPlugin) LatestStable(ctx context.Context, pattern string) (string, error) {
-	versions, err := plugin.ListAll(ctx)
+	_, prereleaseQuery := ParseLatestQuery(pattern)
+	includePrereleases := plugin.Config.IncludePrereleases || prereleaseQuery
+
+	versions, err := plugin.listVersions(ctx, releasesWithAssets{plugin.Github}, includePrereleases)
 	if err != nil {
 		return "", err
 	}
 
-	return LatestVersion(versions, pattern), nil
+	return MatchLatestVersion(versions, pattern, includePrereleases)
 }
 
 // Help returns help information for the plugin.
 func (plugin *BinaryPlugin) Help() PluginHelp {
+	var entries []ConfigEntry
+	if plugin.Config.ProvenanceVerification {
+		entries = []ConfigEntry{
+			{
+				Name:        ProvenanceStrictEnv,
+				Description: "Set to 1 to fail the install when the build provenance attestation of the download cannot be verified",
+			},
+			{
+				Name:        ProvenanceBackendEnv,
+				Description: "Set to gh to verify with 'gh attestation verify' when the github-cli plugin is installed",
+			},
+		}
+	}
+
 	return PluginHelp{
 		Overview: fmt.Sprintf("%s - %s", plugin.Config.Name, plugin.Config.HelpDescription),
 		Deps:     "No additional dependencies required",
-		Config:   "No additional configuration required",
 		Links: fmt.Sprintf(`Documentation: %s
 GitHub: https://github.com/%s/%s`, plugin.Config.HelpLink, plugin.Config.RepoOwner, plugin.Config.RepoName),
+		HelpConfigEntries: entries,
+		Platforms:         plugin.Config.Platforms(),
+		ArchiveType:       plugin.archiveType(),
+	}
+}
+
+// GetReleases returns the tag names of the releases that have at least one asset.
+func (client releasesWithAssets) GetReleases(ctx context.Context, url string) ([]string, error) {
+	releases, err := client.GetReleaseDetails(ctx, url)
+	if err != nil {
+		return nil, err
 	}
+
+	tags := make([]string, 0, len(releases))
+	for _, release := range releases {
+		if len(release.Assets) > 0 {
+			tags = append(tags, release.TagName)
+		}
+	}
+
+	return tags, nil
 }
@@ -21,10 +21,15 @@ import (
 	"compress/gzip"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/github"
+	githubmock "github.com/sumicare/universal-asdf-plugin/plugins/github/mock"
 )
 
 // createTestTarGz creates a tar.gz archive containing a single file.
@@ -86,6 +91,7 @@ func TestBinaryPluginBasicMethods(t *testing.T) {
 	plugin := asdf.NewBinaryPlugin(&config)
 
 	require.Equal(t, "test-tool", plugin.Name())
+	require.Equal(t, "test-tool", asdf.BinaryNameOf(plugin))
 	require.Equal(t, "bin", plugin.ListBinPaths())
 	require.Empty(t, plugin.ExecEnv("/some/path"))
 	require.Empty(t, plugin.ListLegacyFilenames())
@@ -95,6 +101,17 @@ func TestBinaryPluginBasicMethods(t *testing.T) {
 	require.Contains(t, help.Links, "http://example.com")
 }
 
+func TestBinaryNameOf(t *testing.T) {
+	t.Parallel()
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{Name: "github-cli", BinaryName: "gh"})
+	require.Equal(t, "gh", asdf.BinaryNameOf(plugin))
+
+	require.Equal(t, "unnamed", asdf.BinaryNameOf(asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{Name: "unnamed"})))
+}
+
+// TestBinaryPluginInstall verifies the binary is installed and its download
+// recorded in the install metadata.
 func TestBinaryPluginInstall(t *testing.T) {
 	t.Parallel()
 
@@ -124,6 +141,15 @@ func TestBinaryPluginInstall(t *testing.T) {
 
 	_, err = os.Stat(filepath.Join(installPath, "bin", "test-tool"))
 	require.NoError(t, err)
+
+	metadata, err := asdf.ReadInstallMetadata(installPath)
+	require.NoError(t, err)
+	require.Equal(t, "test-tool", metadata.Plugin)
+	require.Equal(t, "1.0.0", metadata.Version)
+	require.Len(t, metadata.Artifacts, 1)
+	require.Equal(t, "some-binary", metadata.Artifacts[0].Name)
+	require.Equal(t, expectedFileFor([]byte("content")).SHA256, metadata.Artifacts[0].SHA256)
+	require.Contains(t, metadata.Artifacts[0].URL, "/owner/repo/releases/download/v1.0.0/")
 }
 
 func TestBinaryPluginUninstall(t *testing.T) {
@@ -168,6 +194,381 @@ func TestBinaryPluginWithGithubClient(t *testing.T) {
 	require.Same(t, plugin, result)
 }
 
+func TestBinaryPluginLatestStableSkipsUnusableReleases(t *testing.T) {
+	t.Parallel()
+
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	asset := []githubmock.AssetResponse{{Name: "test-tool-linux-amd64"}}
+	srv.AddReleaseResponses("owner", "repo", []githubmock.ReleaseResponse{
+		{TagName: "v1.4.0", Draft: true, Assets: asset},
+		{TagName: "untagged-0d5a4c2e", Draft: true, Assets: asset},
+		{TagName: "v1.3.1"},
+		{TagName: "v1.3.0", Assets: asset},
+		{TagName: "v1.2.0", Assets: asset},
+	})
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:       "test-tool",
+		RepoOwner:  "owner",
+		RepoName:   "repo",
+		BinaryName: "test-tool",
+	}).WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+
+	versions, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.2.0", "1.3.0", "1.3.1"}, versions)
+
+	latest, err := plugin.LatestStable(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, "1.3.0", latest)
+
+	latest, err = plugin.LatestStable(t.Context(), "1.2")
+	require.NoError(t, err)
+	require.Equal(t, "1.2.0", latest)
+}
+
+// TestBinaryPluginChangelog verifies release URLs and notes of GitHub releases.
+func TestBinaryPluginChangelog(t *testing.T) {
+	t.Parallel()
+
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddReleaseResponses("owner", "repo", []githubmock.ReleaseResponse{
+		{TagName: "v1.4.0", Body: "## Changes\n\n- faster"},
+		{TagName: "v1.3.0", Body: "initial"},
+	})
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:       "test-tool",
+		RepoOwner:  "owner",
+		RepoName:   "repo",
+		BinaryName: "test-tool",
+	}).WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+
+	require.True(t, asdf.HasCapability(plugin, asdf.CapabilityReleaseNotes))
+	require.Equal(t, "https://github.com/owner/repo/releases/tag/v1.4.0", plugin.ReleaseURL("1.4.0"))
+
+	notes, err := plugin.ReleaseNotes(t.Context(), "1.4.0")
+	require.NoError(t, err)
+	require.Equal(t, "## Changes\n\n- faster", notes)
+
+	_, err = plugin.ReleaseNotes(t.Context(), "1.5.0")
+	require.ErrorIs(t, err, asdf.ErrReleaseNotFoundForTests())
+
+	custom := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:               "test-tool",
+		RepoOwner:          "owner",
+		RepoName:           "repo",
+		VersionPrefix:      "release-",
+		ReleaseURLTemplate: "https://example.com/{{.RepoName}}/{{.VersionPrefix}}{{.Version}}.html",
+	})
+	require.Equal(t, "https://example.com/repo/release-2.0.html", custom.ReleaseURL("2.0"))
+}
+
+// TestBinaryPluginTagPrefix verifies only the tags of the configured module are listed and linked.
+func TestBinaryPluginTagPrefix(t *testing.T) {
+	t.Parallel()
+
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	asset := []githubmock.AssetResponse{{Name: "kustomize_v5.4.3_linux_amd64.tar.gz"}}
+	srv.AddReleaseResponses("owner", "repo", []githubmock.ReleaseResponse{
+		{TagName: "kustomize/v5.4.3", Body: "kustomize notes", Assets: asset},
+		{TagName: "kyaml/v0.17.2", Assets: asset},
+		{TagName: "api/v0.17.3", Assets: asset},
+		{TagName: "kustomize/v5.4.2", Assets: asset},
+	})
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:             "kustomize",
+		RepoOwner:        "owner",
+		RepoName:         "repo",
+		BinaryName:       "kustomize",
+		TagPrefix:        "kustomize/",
+		FileNameTemplate: "kustomize_v{{.Version}}_{{.Platform}}_{{.Arch}}.tar.gz",
+	}).WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+
+	versions, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []string{"5.4.2", "5.4.3"}, versions)
+
+	latest, err := plugin.LatestStable(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, "5.4.3", latest)
+
+	require.Equal(t, "https://github.com/owner/repo/releases/tag/kustomize/v5.4.3", plugin.ReleaseURL("5.4.3"))
+
+	notes, err := plugin.ReleaseNotes(t.Context(), "5.4.3")
+	require.NoError(t, err)
+	require.Equal(t, "kustomize notes", notes)
+
+	artifacts, err := plugin.ResolveArtifacts(t.Context(), "5.4.3")
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	require.Contains(t, artifacts[0].URL, "/releases/download/kustomize%2Fv5.4.3/kustomize_v5.4.3_")
+}
+
+// TestBinaryPluginDescribeArtifacts verifies the description names the
+// mapped platform and architecture the assets are rendered with.
+func TestBinaryPluginDescribeArtifacts(t *testing.T) {
+	t.Parallel()
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:             "tool",
+		RepoOwner:        "owner",
+		RepoName:         "tool",
+		BinaryName:       "tool",
+		FileNameTemplate: "tool-{{.Platform}}-{{.Arch}}.tar.gz",
+		ArchiveType:      "tar.gz",
+		OsMap:            map[string]string{"darwin": "unix", "linux": "unix"},
+		ArchMap:          map[string]string{"amd64": "x64", "arm64": "x64"},
+	})
+
+	description, err := plugin.DescribeArtifacts("1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, asdf.ArtifactDescription{
+		Platform: "unix", Arch: "x64", ArchiveType: "tar.gz", BinaryPath: "bin/tool",
+	}, description)
+
+	artifacts, err := plugin.ResolveArtifacts(t.Context(), "1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "tool-unix-x64.tar.gz", artifacts[0].Name)
+
+	plugin.Config.ArchiveType = ""
+
+	description, err = plugin.DescribeArtifacts("1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "none", description.ArchiveType)
+}
+
+// TestBinaryPluginDownloadReleaseAsset verifies downloads follow the
+// browser_download_url the GitHub API lists for the release asset.
+func TestBinaryPluginDownloadReleaseAsset(t *testing.T) {
+	t.Parallel()
+
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:             "kustomize",
+		RepoOwner:        "owner",
+		RepoName:         "repo",
+		BinaryName:       "kustomize",
+		TagPrefix:        "kustomize/",
+		FileNameTemplate: "kustomize_v{{.Version}}_{{.Platform}}_{{.Arch}}",
+		ArchiveType:      "none",
+	}).WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+
+	names, err := plugin.ArtifactNames("5.4.3")
+	require.NoError(t, err)
+
+	content := "#!/bin/sh\necho kustomize 5.4.3\n"
+	srv.AddReleaseAsset("owner", "repo", "kustomize/v5.4.3", names[0], []byte(content))
+
+	downloadPath := t.TempDir()
+	require.NoError(t, plugin.Download(t.Context(), "5.4.3", downloadPath))
+
+	data, err := os.ReadFile(filepath.Join(downloadPath, names[0]))
+	require.NoError(t, err)
+	require.Equal(t, content, string(data))
+}
+
+// TestBinaryPluginChecksumFile verifies downloads are checked against the
+// checksums asset of the release, and fail when it lists another checksum or
+// none for them.
+func TestBinaryPluginChecksumFile(t *testing.T) {
+	t.Parallel()
+
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:                 "tool",
+		RepoOwner:            "owner",
+		RepoName:             "repo",
+		BinaryName:           "tool",
+		FileNameTemplate:     "tool_{{.Version}}_{{.Platform}}_{{.Arch}}",
+		ChecksumFileTemplate: "tool_{{.Version}}_checksums.txt",
+		ArchiveType:          "none",
+	}).WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+
+	addRelease := func(version, checksums string) string {
+		names, err := plugin.ArtifactNames(version)
+		require.NoError(t, err)
+
+		content := "#!/bin/sh\necho tool " + version + "\n"
+		checksums = strings.ReplaceAll(checksums, "{{.SHA256}}", expectedFileFor([]byte(content)).SHA256)
+		checksums = strings.ReplaceAll(checksums, "{{.FileName}}", names[0])
+
+		srv.AddReleaseAsset("owner", "repo", "v"+version, names[0], []byte(content))
+		srv.AddReleaseAsset("owner", "repo", "v"+version, "tool_"+version+"_checksums.txt", []byte(checksums))
+
+		return names[0]
+	}
+
+	name := addRelease("1.0.0", "0000  tool_1.0.0_other.tar.gz\n{{.SHA256}}  {{.FileName}}\n")
+	addRelease("1.1.0", strings.Repeat("0", 64)+"  {{.FileName}}\n")
+	unlisted := addRelease("1.2.0", "{{.SHA256}}  tool_1.2.0_other.tar.gz\n")
+
+	downloadPath := t.TempDir()
+	require.NoError(t, plugin.Download(t.Context(), "1.0.0", downloadPath))
+	require.NoError(t, asdf.VerifyChecksumSidecar(filepath.Join(downloadPath, name)))
+
+	require.ErrorContains(t, plugin.Download(t.Context(), "1.1.0", t.TempDir()), "checksum mismatch")
+
+	downloadPath = t.TempDir()
+	require.ErrorIs(t, plugin.Download(t.Context(), "1.2.0", downloadPath), asdf.ErrChecksumNotPublishedForTests())
+	require.NoFileExists(t, filepath.Join(downloadPath, unlisted))
+}
+
+// TestBinaryPluginEnterpriseURLs verifies releases are listed from APIBaseURL
+// and downloaded from DownloadBaseURL, or the ASDF_GITHUB_* defaults.
+func TestBinaryPluginEnterpriseURLs(t *testing.T) {
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	config := &asdf.BinaryPluginConfig{
+		Name:             "internal-cli",
+		RepoOwner:        "tools",
+		RepoName:         "internal-cli",
+		BinaryName:       "internal-cli",
+		FileNameTemplate: "internal-cli-{{.Platform}}-{{.Arch}}",
+		ArchiveType:      "none",
+	}
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv(github.DownloadURLEnv, "https://ghe.example.com")
+
+		plugin := asdf.NewBinaryPlugin(config)
+
+		artifacts, err := plugin.ResolveArtifacts(t.Context(), "1.0.0")
+		require.NoError(t, err)
+		require.Len(t, artifacts, 1)
+		require.Equal(t, "https://ghe.example.com/tools/internal-cli/releases/download/v1.0.0/"+artifacts[0].Name, artifacts[0].URL)
+		require.Equal(t, "https://ghe.example.com/tools/internal-cli/releases/tag/v1.0.0", plugin.ReleaseURL("1.0.0"))
+	})
+
+	t.Run("plugin config", func(t *testing.T) {
+		t.Setenv(github.DownloadURLEnv, "https://ghe.example.com")
+
+		cfg := *config
+		cfg.APIBaseURL = srv.URL() + "/"
+		cfg.DownloadBaseURL = "https://ghe.internal.example.com/"
+
+		plugin := asdf.NewBinaryPlugin(&cfg)
+
+		names, err := plugin.ArtifactNames("1.0.0")
+		require.NoError(t, err)
+
+		content := "#!/bin/sh\necho internal-cli 1.0.0\n"
+		srv.AddReleaseAsset("tools", "internal-cli", "v1.0.0", names[0], []byte(content))
+
+		latest, err := plugin.LatestStable(t.Context(), "")
+		require.NoError(t, err)
+		require.Equal(t, "1.0.0", latest)
+
+		artifacts, err := plugin.ResolveArtifacts(t.Context(), "1.0.0")
+		require.NoError(t, err)
+		require.Equal(t, "https://ghe.internal.example.com/tools/internal-cli/releases/download/v1.0.0/"+names[0], artifacts[0].URL)
+
+		downloadPath := t.TempDir()
+		require.NoError(t, plugin.Download(t.Context(), "1.0.0", downloadPath))
+
+		data, err := os.ReadFile(filepath.Join(downloadPath, names[0]))
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	})
+}
+
+// TestBinaryPluginGitHubErrors verifies GitHub access failures are reported with the plugin name.
+func TestBinaryPluginGitHubErrors(t *testing.T) {
+	t.Parallel()
+
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.SetRateLimited("owner", "limited", time.Unix(1_900_000_000, 0))
+
+	newPlugin := func(repo string) *asdf.BinaryPlugin {
+		return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+			Name:             "tool",
+			RepoOwner:        "owner",
+			RepoName:         repo,
+			BinaryName:       "tool",
+			FileNameTemplate: "tool-{{.Platform}}-{{.Arch}}",
+		}).WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+	}
+
+	_, err := newPlugin("moved").ListAll(t.Context())
+	require.ErrorIs(t, err, github.ErrRepoNotFound)
+	require.ErrorContains(t, err, "tool: ")
+	require.ErrorContains(t, err, "github repo owner/moved not found (404) — has it moved?")
+
+	_, err = newPlugin("moved").LatestStable(t.Context(), "")
+	require.ErrorIs(t, err, github.ErrRepoNotFound)
+
+	_, err = newPlugin("limited").LatestStable(t.Context(), "")
+	require.ErrorIs(t, err, github.ErrRateLimited)
+	require.ErrorContains(t, err, "tool: ")
+}
+
+// TestBinaryPluginPrereleases verifies prereleases are only selected when configured or queried.
+func TestBinaryPluginPrereleases(t *testing.T) {
+	t.Parallel()
+
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	asset := []githubmock.AssetResponse{{Name: "kubectl-linux-amd64"}}
+	srv.AddReleaseResponses("owner", "repo", []githubmock.ReleaseResponse{
+		{TagName: "v1.30.0-rc.1", Assets: asset},
+		{TagName: "v1.29.3", Assets: asset},
+		{TagName: "v1.29.0", Assets: asset},
+	})
+
+	newPlugin := func(includePrereleases bool) *asdf.BinaryPlugin {
+		return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+			Name:               "kubectl",
+			RepoOwner:          "owner",
+			RepoName:           "repo",
+			BinaryName:         "kubectl",
+			IncludePrereleases: includePrereleases,
+		}).WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+	}
+
+	plugin := newPlugin(false)
+
+	versions, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.29.0", "1.29.3"}, versions)
+
+	latest, err := plugin.LatestStable(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, "1.29.3", latest)
+
+	latest, err = plugin.LatestStable(t.Context(), asdf.PrereleaseQueryPrefix)
+	require.NoError(t, err)
+	require.Equal(t, "1.30.0-rc.1", latest)
+
+	latest, err = plugin.LatestStable(t.Context(), asdf.PrereleaseQueryPrefix+"1.29")
+	require.NoError(t, err)
+	require.Equal(t, "1.29.3", latest)
+
+	plugin = newPlugin(true)
+
+	versions, err = plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.29.0", "1.29.3", "1.30.0-rc.1"}, versions)
+
+	latest, err = plugin.LatestStable(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, "1.30.0-rc.1", latest)
+}
+
 func TestBinaryPluginParseLegacyFile(t *testing.T) {
 	t.Parallel()
 
@@ -280,3 +681,374 @@ func createTestGz(t *testing.T, archivePath, content string) {
 	_, err = gzWriter.Write([]byte(content))
 	require.NoError(t, err)
 }
+
+// createNestedTestArchive creates an archive of the given type holding files.
+func createNestedTestArchive(t *testing.T, archiveType, archivePath string, files map[string]string) {
+	t.Helper()
+
+	if archiveType == "tar.xz" {
+		CreateTestTarXz(t, archivePath, files)
+
+		return
+	}
+
+	file, err := os.Create(archivePath)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	if archiveType == "zip" {
+		zipWriter := zip.NewWriter(file)
+		defer zipWriter.Close()
+
+		for name, content := range files {
+			writer, err := zipWriter.Create(name)
+			require.NoError(t, err)
+
+			_, err = writer.Write([]byte(content))
+			require.NoError(t, err)
+		}
+
+		return
+	}
+
+	gzWriter := gzip.NewWriter(file)
+	defer gzWriter.Close()
+
+	tarWriter := tar.NewWriter(gzWriter)
+	defer tarWriter.Close()
+
+	for name, content := range files {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}))
+
+		_, err := tarWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+}
+
+// TestBinaryPluginInstallNestedArchive verifies binaries are located below
+// versioned top-level directories in every archive format.
+func TestBinaryPluginInstallNestedArchive(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"test-tool_1.0.0/README.md":          "readme",
+		"test-tool_1.0.0/test-tool":          "binary content",
+		"test-tool_1.0.0/contrib/test-tool":  "completion helper",
+		"test-tool_1.0.0/docs/test-tool.txt": "docs",
+	}
+
+	tests := []struct {
+		name                string
+		binaryPathInArchive string
+		wantErr             string
+		stripComponents     int
+	}{
+		{
+			name:                "binary path template",
+			binaryPathInArchive: "{{.BinaryName}}_{{.Version}}/{{.BinaryName}}",
+		},
+		{
+			name:                "strip components",
+			binaryPathInArchive: "{{.BinaryName}}",
+			stripComponents:     1,
+		},
+		{
+			name:                "missing member",
+			binaryPathInArchive: "bin/{{.BinaryName}}",
+			stripComponents:     1,
+			wantErr:             "bin/test-tool (after stripping 1 leading directories) in test-tool.",
+		},
+	}
+
+	for _, archiveType := range []string{"tar.gz", "tar.xz", "zip"} {
+		for _, tt := range tests {
+			t.Run(archiveType+" "+tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				tempDir := t.TempDir()
+				downloadPath := filepath.Join(tempDir, "download")
+				installPath := filepath.Join(tempDir, "install")
+
+				require.NoError(t, os.MkdirAll(downloadPath, asdf.CommonDirectoryPermission))
+				createNestedTestArchive(t, archiveType, filepath.Join(downloadPath, "test-tool."+archiveType), files)
+
+				plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+					Name:                "test-tool",
+					RepoOwner:           "owner",
+					RepoName:            "repo",
+					BinaryName:          "test-tool",
+					ArchiveType:         archiveType,
+					BinaryPathInArchive: tt.binaryPathInArchive,
+					StripComponents:     tt.stripComponents,
+				})
+
+				err := plugin.Install(t.Context(), "1.0.0", downloadPath, installPath)
+				if tt.wantErr != "" {
+					require.ErrorIs(t, err, asdf.ErrBinaryNotFoundInArchiveForTests())
+					require.Contains(t, err.Error(), tt.wantErr)
+
+					return
+				}
+
+				require.NoError(t, err)
+
+				binaryPath := filepath.Join(installPath, "bin", "test-tool")
+
+				content, err := os.ReadFile(binaryPath)
+				require.NoError(t, err)
+				require.Equal(t, "binary content", string(content))
+
+				info, err := os.Stat(binaryPath)
+				require.NoError(t, err)
+				require.Equal(t, asdf.CommonExecutablePermission, info.Mode().Perm())
+			})
+		}
+	}
+}
+
+// TestBinaryPluginArchiveDirs verifies ArchiveDirs are copied next to bin,
+// replacing a previous copy, and that a missing one fails the install.
+func TestBinaryPluginArchiveDirs(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"test-tool_1.0.0/bin/test-tool":       "binary content",
+		"test-tool_1.0.0/include/a/b.proto":   "proto",
+		"test-tool_1.0.0/include/readme.txt":  "readme",
+		"test-tool_1.0.0/share/doc/test-tool": "docs",
+	}
+
+	for _, archiveType := range []string{"tar.gz", "zip"} {
+		t.Run(archiveType, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			downloadPath := filepath.Join(tempDir, "download")
+			installPath := filepath.Join(tempDir, "install")
+
+			require.NoError(t, os.MkdirAll(downloadPath, asdf.CommonDirectoryPermission))
+			createNestedTestArchive(t, archiveType, filepath.Join(downloadPath, "test-tool."+archiveType), files)
+
+			config := asdf.BinaryPluginConfig{
+				Name:                "test-tool",
+				RepoOwner:           "owner",
+				RepoName:            "repo",
+				BinaryName:          "test-tool",
+				ArchiveType:         archiveType,
+				BinaryPathInArchive: "bin/{{.BinaryName}}",
+				StripComponents:     1,
+				ArchiveDirs:         []string{"include", "share/doc"},
+			}
+
+			stale := filepath.Join(installPath, "include", "stale.proto")
+			require.NoError(t, os.MkdirAll(filepath.Dir(stale), asdf.CommonDirectoryPermission))
+			require.NoError(t, os.WriteFile(stale, []byte("stale"), asdf.CommonFilePermission))
+
+			require.NoError(t, asdf.NewBinaryPlugin(&config).Install(t.Context(), "1.0.0", downloadPath, installPath))
+
+			for name, want := range map[string]string{
+				"bin/test-tool":       "binary content",
+				"include/a/b.proto":   "proto",
+				"include/readme.txt":  "readme",
+				"share/doc/test-tool": "docs",
+			} {
+				content, err := os.ReadFile(filepath.Join(installPath, filepath.FromSlash(name)))
+				require.NoError(t, err)
+				require.Equal(t, want, string(content))
+			}
+
+			require.NoFileExists(t, stale)
+
+			config.ArchiveDirs = []string{"lib"}
+			err := asdf.NewBinaryPlugin(&config).Install(t.Context(), "1.0.0", downloadPath, t.TempDir())
+			require.ErrorIs(t, err, asdf.ErrArchiveDirNotFoundForTests())
+			require.Contains(t, err.Error(), "lib in test-tool."+archiveType)
+		})
+	}
+}
+
+// TestBinaryPluginExtraInstallFiles verifies files matching ExtraInstallFiles
+// are copied next to bin and that globs matching nothing are skipped.
+func TestBinaryPluginExtraInstallFiles(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	downloadPath := filepath.Join(tempDir, "download")
+	installPath := filepath.Join(tempDir, "install")
+
+	require.NoError(t, os.MkdirAll(downloadPath, asdf.CommonDirectoryPermission))
+	createNestedTestArchive(t, "tar.gz", filepath.Join(downloadPath, "test-tool.tar.gz"), map[string]string{
+		"test-tool_1.0.0/test-tool":                  "binary content",
+		"test-tool_1.0.0/completions/test-tool.bash": "bash completion",
+		"test-tool_1.0.0/completions/_test-tool":     "zsh completion",
+		"test-tool_1.0.0/test-tool.1":                "man page",
+	})
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:            "test-tool",
+		RepoOwner:       "owner",
+		RepoName:        "repo",
+		BinaryName:      "test-tool",
+		ArchiveType:     "tar.gz",
+		StripComponents: 1,
+		ExtraInstallFiles: []asdf.ExtraInstallFile{
+			{ArchivePathGlob: "completions/*", InstallRelDir: asdf.CompletionsDir},
+			{ArchivePathGlob: "*.1", InstallRelDir: "share/man/man1"},
+			{ArchivePathGlob: "doc/*.md", InstallRelDir: "share/doc"},
+		},
+	})
+
+	require.NoError(t, plugin.Install(t.Context(), "1.0.0", downloadPath, installPath))
+
+	for name, want := range map[string]string{
+		"bin/test-tool":                    "binary content",
+		"share/completions/test-tool.bash": "bash completion",
+		"share/completions/_test-tool":     "zsh completion",
+		"share/man/man1/test-tool.1":       "man page",
+	} {
+		content, err := os.ReadFile(filepath.Join(installPath, filepath.FromSlash(name)))
+		require.NoError(t, err)
+		require.Equal(t, want, string(content))
+	}
+
+	require.NoDirExists(t, filepath.Join(installPath, "share", "doc"))
+}
+
+// TestBinaryPluginExtraBinaries verifies every binary of a release is
+// installed from its own archive and that a missing one fails the install.
+func TestBinaryPluginExtraBinaries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		helperMember string
+		wantErr      bool
+	}{
+		{name: "both binaries", helperMember: "test-helper"},
+		{name: "missing extra binary", helperMember: "README.md", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tempDir := t.TempDir()
+			downloadPath := filepath.Join(tempDir, "download")
+			installPath := filepath.Join(tempDir, "install")
+
+			require.NoError(t, os.MkdirAll(downloadPath, asdf.CommonDirectoryPermission))
+			createTestTarGz(t, filepath.Join(downloadPath, "test-tool_v1.0.0.tar.gz"), "test-tool", "tool content")
+			createTestTarGz(t, filepath.Join(downloadPath, "test-helper_v1.0.0.tar.gz"), tt.helperMember, "helper content")
+
+			plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+				Name:             "test-tool",
+				RepoOwner:        "owner",
+				RepoName:         "repo",
+				BinaryName:       "test-tool",
+				FileNameTemplate: "{{.BinaryName}}_v{{.Version}}.tar.gz",
+				ArchiveType:      "tar.gz",
+				ExtraBinaries: []asdf.ReleaseBinary{
+					{FileNameTemplate: "{{.BinaryName}}_v{{.Version}}.tar.gz", BinaryName: "test-helper"},
+				},
+			})
+
+			names, err := plugin.ArtifactNames("1.0.0")
+			require.NoError(t, err)
+			require.Equal(t, []string{"test-tool_v1.0.0.tar.gz", "test-helper_v1.0.0.tar.gz"}, names)
+
+			err = plugin.Install(t.Context(), "1.0.0", downloadPath, installPath)
+			if tt.wantErr {
+				require.ErrorIs(t, err, asdf.ErrBinaryNotFoundInArchiveForTests())
+				require.Contains(t, err.Error(), "test-helper in test-helper_v1.0.0.tar.gz")
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			for binary, content := range map[string]string{"test-tool": "tool content", "test-helper": "helper content"} {
+				binaryPath := filepath.Join(installPath, "bin", binary)
+
+				data, err := os.ReadFile(binaryPath)
+				require.NoError(t, err)
+				require.Equal(t, content, string(data))
+
+				info, err := os.Stat(binaryPath)
+				require.NoError(t, err)
+				require.Equal(t, asdf.CommonExecutablePermission, info.Mode().Perm())
+			}
+		})
+	}
+}
+
+// TestBinaryPluginSupportedPlatforms verifies SupportedPlatforms restricts the
+// platforms with assets, PlatformFileNameTemplates rename them, and the
+// unsupported error lists the supported platforms.
+func TestBinaryPluginSupportedPlatforms(t *testing.T) {
+	t.Setenv(asdf.ForceArchEnv, "amd64")
+
+	config := &asdf.BinaryPluginConfig{
+		Name:             "tool",
+		BinaryName:       "tool",
+		FileNameTemplate: "tool_{{.Platform}}_{{.Arch}}",
+		OsMap:            map[string]string{"freebsd": "freebsd", "openbsd": "openbsd"},
+		SupportedPlatforms: []string{
+			runtime.GOOS + "/amd64", runtime.GOOS + "/arm64", "freebsd/amd64", "openbsd/amd64",
+		},
+		PlatformFileNameTemplates: map[string]string{
+			runtime.GOOS:    "tool_{{.Platform}}_{{.Arch}}.bin",
+			"openbsd/amd64": "tool_openbsd",
+		},
+	}
+
+	config.OsMap[runtime.GOOS] = runtime.GOOS
+
+	require.Equal(t, "tool_{{.Platform}}_{{.Arch}}.bin", config.FileNameTemplateFor(runtime.GOOS, "arm64"))
+	require.Equal(t, "tool_openbsd", config.FileNameTemplateFor("openbsd", "amd64"))
+	require.Equal(t, "tool_{{.Platform}}_{{.Arch}}", config.FileNameTemplateFor("freebsd", "amd64"))
+
+	plugin := asdf.NewBinaryPlugin(config)
+	require.ElementsMatch(t, []string{
+		"freebsd/amd64", "openbsd/amd64", runtime.GOOS + "/amd64", runtime.GOOS + "/arm64",
+	}, plugin.Help().Platforms)
+
+	artifacts, err := plugin.ResolveArtifacts(t.Context(), "1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "tool_"+runtime.GOOS+"_amd64.bin", artifacts[0].Name)
+
+	plugin.Config.SupportedPlatforms = []string{"freebsd/amd64", runtime.GOOS + "/arm64"}
+
+	_, err = plugin.ResolveArtifacts(t.Context(), "1.0.0")
+	require.EqualError(t, err, "unsupported platform: "+runtime.GOOS+"/amd64 (supported: freebsd/amd64, "+runtime.GOOS+"/arm64)")
+
+	plugin = asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{Name: "tool", OsMap: map[string]string{"netbsd": "netbsd"}})
+
+	_, err = plugin.ResolveArtifacts(t.Context(), "1.0.0")
+	require.EqualError(t, err, "unsupported platform: "+runtime.GOOS+" (supported: netbsd/amd64, netbsd/arm64)")
+}
+
+// TestBinaryPluginHelpPlatforms verifies the help lists the platforms of the
+// OsMap and ArchMap, including per-platform architectures, and the archive type.
+func TestBinaryPluginHelpPlatforms(t *testing.T) {
+	t.Parallel()
+
+	help := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{Name: "tool"}).Help()
+	require.Equal(t, []string{"darwin/amd64", "darwin/arm64", "linux/amd64", "linux/arm64"}, help.Platforms)
+	require.Equal(t, "none", help.ArchiveType)
+	require.Empty(t, help.HelpConfigEntries)
+
+	help = asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:        "tool",
+		OsMap:       map[string]string{"linux": "Linux", "darwin": "macOS"},
+		ArchMap:     map[string]string{"amd64": "x86_64", "darwin/arm64": "universal", "windows/arm64": "arm64"},
+		ArchiveType: "tar.gz",
+
+		ProvenanceVerification: true,
+	}).Help()
+	require.Equal(t, []string{"darwin/amd64", "darwin/arm64", "linux/amd64"}, help.Platforms)
+	require.Equal(t, "tar.gz", help.ArchiveType)
+	require.Equal(t, []string{asdf.ProvenanceStrictEnv, asdf.ProvenanceBackendEnv}, []string{
+		help.HelpConfigEntries[0].Name, help.HelpConfigEntries[1].Name,
+	})
+}
@@ -0,0 +1,171 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Capability names an optional interface a plugin implements.
+type Capability string
+
+const (
+	// CapabilityArtifacts reports that the plugin implements PluginWithArtifacts.
+	CapabilityArtifacts Capability = "artifacts"
+	// CapabilityArtifactResolver reports that the plugin implements PluginWithArtifactResolver.
+	CapabilityArtifactResolver Capability = "artifact-resolver"
+	// CapabilityChangelog reports that the plugin implements ChangelogProvider.
+	CapabilityChangelog Capability = "changelog"
+	// CapabilityDependencies reports that the plugin implements PluginWithDependencies.
+	CapabilityDependencies Capability = "dependencies"
+	// CapabilityInstallRoot reports that the plugin implements InstallRootProvider.
+	CapabilityInstallRoot Capability = "install-root"
+	// CapabilityInPlaceInstall reports that the plugin implements InPlaceInstaller.
+	CapabilityInPlaceInstall Capability = "in-place-install"
+	// CapabilityRefInstall reports that the plugin implements RefInstaller.
+	CapabilityRefInstall Capability = "ref-install"
+	// CapabilityReleaseNotes reports that the plugin implements ReleaseNotesProvider.
+	CapabilityReleaseNotes Capability = "release-notes"
+	// CapabilityVersionResolver reports that the plugin implements PluginWithVersionResolver.
+	CapabilityVersionResolver Capability = "version-resolver"
+)
+
+// errCapabilityUnsupported is returned when a plugin lacks the capability a command needs.
+var errCapabilityUnsupported = errors.New("not supported by this plugin")
+
+// capabilityChecks maps each capability to its type assertion. Optional
+// interfaces are only discovered here, so adding one is a single entry.
+var capabilityChecks = []struct { //nolint:gochecknoglobals // read-only lookup table
+	implements func(Plugin) bool
+	capability Capability
+}{
+	{
+		capability: CapabilityArtifacts,
+		implements: func(plugin Plugin) bool {
+			_, ok := plugin.(PluginWithArtifacts)
+
+			return ok
+		},
+	},
+	{
+		capability: CapabilityArtifactResolver,
+		implements: func(plugin Plugin) bool {
+			_, ok := plugin.(PluginWithArtifactResolver)
+
+			return ok
+		},
+	},
+	{
+		capability: CapabilityChangelog,
+		implements: func(plugin Plugin) bool {
+			_, ok := plugin.(ChangelogProvider)
+
+			return ok
+		},
+	},
+	{
+		capability: CapabilityDependencies,
+		implements: func(plugin Plugin) bool {
+			_, ok := plugin.(PluginWithDependencies)
+
+			return ok
+		},
+	},
+	{
+		capability: CapabilityInstallRoot,
+		implements: func(plugin Plugin) bool {
+			_, ok := plugin.(InstallRootProvider)
+
+			return ok
+		},
+	},
+	{
+		capability: CapabilityInPlaceInstall,
+		implements: InstallsInPlace,
+	},
+	{
+		capability: CapabilityRefInstall,
+		implements: func(plugin Plugin) bool {
+			installer, ok := plugin.(RefInstaller)
+
+			return ok && installer.SupportsRefs()
+		},
+	},
+	{
+		capability: CapabilityReleaseNotes,
+		implements: func(plugin Plugin) bool {
+			_, ok := plugin.(ReleaseNotesProvider)
+
+			return ok
+		},
+	},
+	{
+		capability: CapabilityVersionResolver,
+		implements: func(plugin Plugin) bool {
+			_, ok := plugin.(PluginWithVersionResolver)
+
+			return ok
+		},
+	},
+}
+
+// Capabilities returns the optional interfaces plugin implements, sorted by name.
+func Capabilities(plugin Plugin) []Capability {
+	capabilities := make([]Capability, 0, len(capabilityChecks))
+
+	for _, check := range capabilityChecks {
+		if check.implements(plugin) {
+			capabilities = append(capabilities, check.capability)
+		}
+	}
+
+	slices.Sort(capabilities)
+
+	return capabilities
+}
+
+// JoinCapabilities returns capabilities as a comma-separated list, or "none".
+func JoinCapabilities(capabilities []Capability) string {
+	if len(capabilities) == 0 {
+		return "none"
+	}
+
+	names := make([]string, 0, len(capabilities))
+	for _, capability := range capabilities {
+		names = append(names, string(capability))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// HasCapability reports whether plugin implements the optional interface of capability.
+func HasCapability(plugin Plugin, capability Capability) bool {
+	return slices.Contains(Capabilities(plugin), capability)
+}
+
+// RequireCapability returns an error naming plugin and what it lacks unless it
+// implements capability. feature describes the command or flag needing it.
+func RequireCapability(plugin Plugin, capability Capability, feature string) error {
+	if HasCapability(plugin, capability) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s needs %s, which %s does not implement",
+		errCapabilityUnsupported, feature, capability, plugin.Name())
+}
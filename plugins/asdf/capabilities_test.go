@@ -0,0 +1,127 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// resolverPluginWithDeps implements the dependency and version resolver interfaces.
+type resolverPluginWithDeps struct {
+	goModResolverPlugin
+}
+
+func (*resolverPluginWithDeps) Dependencies() []string {
+	return []string{"golang"}
+}
+
+// inPlacePlugin implements InPlaceInstaller, installing in place when inPlace is set.
+type inPlacePlugin struct {
+	mockPlugin
+
+	inPlace bool
+}
+
+func (plugin *inPlacePlugin) InstallsInPlace() bool {
+	return plugin.inPlace
+}
+
+// TestCapabilities verifies the reported capabilities follow the implemented interfaces.
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		plugin   asdf.Plugin
+		name     string
+		expected []asdf.Capability
+	}{
+		{name: "core interface only", plugin: &mockPlugin{}, expected: []asdf.Capability{}},
+		{
+			name:     "dependencies",
+			plugin:   &mockPluginWithDeps{},
+			expected: []asdf.Capability{asdf.CapabilityDependencies},
+		},
+		{
+			name:     "version resolver",
+			plugin:   &goModResolverPlugin{},
+			expected: []asdf.Capability{asdf.CapabilityVersionResolver},
+		},
+		{
+			name:     "artifacts",
+			plugin:   &artifactPlugin{},
+			expected: []asdf.Capability{asdf.CapabilityArtifacts},
+		},
+		{
+			name:     "artifact resolver",
+			plugin:   &resolvingPlugin{},
+			expected: []asdf.Capability{asdf.CapabilityArtifactResolver},
+		},
+		{
+			name:     "in-place install",
+			plugin:   &inPlacePlugin{inPlace: true},
+			expected: []asdf.Capability{asdf.CapabilityInPlaceInstall},
+		},
+		{name: "staged install", plugin: &inPlacePlugin{}, expected: []asdf.Capability{}},
+		{
+			name:     "dependencies and version resolver",
+			plugin:   &resolverPluginWithDeps{},
+			expected: []asdf.Capability{asdf.CapabilityDependencies, asdf.CapabilityVersionResolver},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, asdf.Capabilities(tt.plugin))
+
+			for _, capability := range []asdf.Capability{
+				asdf.CapabilityArtifacts, asdf.CapabilityArtifactResolver,
+				asdf.CapabilityChangelog, asdf.CapabilityDependencies, asdf.CapabilityInstallRoot,
+				asdf.CapabilityInPlaceInstall, asdf.CapabilityRefInstall, asdf.CapabilityReleaseNotes,
+				asdf.CapabilityVersionResolver,
+			} {
+				supported := asdf.HasCapability(tt.plugin, capability)
+				require.Equal(t, supported, asdf.RequireCapability(tt.plugin, capability, "test") == nil)
+			}
+		})
+	}
+}
+
+// TestRequireCapability verifies unsupported features name the plugin and the missing capability.
+func TestRequireCapability(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, asdf.RequireCapability(&mockPluginWithDeps{}, asdf.CapabilityDependencies, "install"))
+
+	err := asdf.RequireCapability(&mockPlugin{}, asdf.CapabilityVersionResolver, "install --resolve")
+	require.ErrorIs(t, err, asdf.ErrCapabilityUnsupportedForTests())
+	require.EqualError(t, err,
+		"not supported by this plugin: install --resolve needs version-resolver, which mock does not implement")
+}
+
+// TestJoinCapabilities verifies capability lists render for help output.
+func TestJoinCapabilities(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "none", asdf.JoinCapabilities(nil))
+	require.Equal(t, "dependencies, version-resolver",
+		asdf.JoinCapabilities([]asdf.Capability{asdf.CapabilityDependencies, asdf.CapabilityVersionResolver}))
+}
@@ -16,23 +16,27 @@
 package asdf
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
-	"sort"
+	"slices"
 	"strconv"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/github"
 )
 
 type (
@@ -49,6 +53,12 @@ type (
 		Download(ctx context.Context, version, downloadPath string) error
 
 		// Install installs the specified version from downloadPath to installPath.
+		// It must be idempotent: run again over an existing install of the same
+		// version, including one left by a crashed attempt, it succeeds and
+		// leaves identical files. The install command runs it in an empty
+		// staging directory renamed to installPath afterwards, unless the plugin
+		// is an InPlaceInstaller, whose installPath `install --force` empties
+		// with ResetDir beforehand.
 		Install(ctx context.Context, version, downloadPath, installPath string) error
 
 		// ListBinPaths returns the relative paths to directories containing binaries.
@@ -73,6 +83,44 @@ type (
 		Help() PluginHelp
 	}
 
+	// BinaryNamer is implemented by plugins whose main executable is not
+	// necessarily named after the plugin, e.g. github-cli installing gh.
+	BinaryNamer interface {
+		Plugin
+		// BinaryName returns the file name of the main executable.
+		BinaryName() string
+	}
+
+	// HashIgnorer is implemented by plugins whose installs gather files that do
+	// not change what is installed, such as bytecode caches, which tool
+	// checksums must leave out.
+	HashIgnorer interface {
+		Plugin
+		// HashIgnoreGlobs returns globs matched against the slash-separated
+		// path, relative to the install, and the base name of each entry.
+		HashIgnoreGlobs() []string
+	}
+
+	// ShimContributor is implemented by plugins whose installs gather
+	// executables outside their bin paths, such as apps installed by pipx or
+	// `uv tool install`, which reshim and which must also expose.
+	ShimContributor interface {
+		Plugin
+		// ExtraBinDirs returns the absolute executable directories of the
+		// install at installPath besides those of ListBinPaths.
+		ExtraBinDirs(installPath string) []string
+	}
+
+	// ExpansionHinter is implemented by plugins whose installs take more or
+	// less than DefaultExpansionFactor times the size of their downloads,
+	// which CheckDiskSpace requires to be free before installing.
+	ExpansionHinter interface {
+		Plugin
+		// ExpansionFactor returns the size of an install divided by the size
+		// of its downloads, DefaultExpansionFactor when not positive.
+		ExpansionFactor() float64
+	}
+
 	// PluginWithDependencies extends Plugin with dependency information.
 	PluginWithDependencies interface {
 		Plugin
@@ -82,6 +130,124 @@ type (
 		Dependencies() []string
 	}
 
+	// PluginWithVersionResolver extends Plugin for tools whose effective version
+	// depends on the project, e.g. a keyword resolved from go.mod.
+	PluginWithVersionResolver interface {
+		Plugin
+		// ResolveVersion maps the version pinned for a project in dir to the
+		// version to install and run.
+		ResolveVersion(dir, version string) (string, error)
+	}
+
+	// PluginWithArtifacts extends Plugin for tools whose download is a known set
+	// of files, so that pre-seeded downloads can be checked before going offline.
+	PluginWithArtifacts interface {
+		Plugin
+		// ArtifactNames returns the names of the files Download stores in the
+		// download path for version on the running platform.
+		ArtifactNames(version string) ([]string, error)
+	}
+
+	// PluginWithArtifactResolver extends Plugin for tools that can tell where
+	// their downloads come from, which is recorded in lockfiles.
+	PluginWithArtifactResolver interface {
+		Plugin
+		// ResolveArtifacts returns the files Download fetches for version on the
+		// running platform, with their URLs.
+		ResolveArtifacts(ctx context.Context, version string) ([]Artifact, error)
+	}
+
+	// ArtifactDescriber extends PluginWithArtifactResolver for tools that can
+	// tell how they select their downloads, printed by the resolve command.
+	ArtifactDescriber interface {
+		PluginWithArtifactResolver
+		// DescribeArtifacts returns how the downloads of version are selected
+		// on the running platform, without fetching anything.
+		DescribeArtifacts(version string) (ArtifactDescription, error)
+	}
+
+	// ChangelogProvider extends Plugin for tools that link what changed in a
+	// version, shown when update-tool-versions bumps them.
+	ChangelogProvider interface {
+		Plugin
+		// ReleaseURL returns the URL of the release notes of version.
+		ReleaseURL(version string) string
+	}
+
+	// ReleaseNotesProvider extends ChangelogProvider for tools whose release
+	// notes can be fetched, printed by the changelog command.
+	ReleaseNotesProvider interface {
+		ChangelogProvider
+		// ReleaseNotes returns the release notes of version.
+		ReleaseNotes(ctx context.Context, version string) (string, error)
+	}
+
+	// InstallRootProvider extends Plugin for tools that nest their real root
+	// below the install path, e.g. an SDK directory, printed by the where command.
+	InstallRootProvider interface {
+		Plugin
+		// InstallRoot returns the root of the tool installed at installPath.
+		InstallRoot(installPath string) string
+	}
+
+	// RefInstaller extends Plugin for tools that can be built from a git ref,
+	// installed by asdf when ASDF_INSTALL_TYPE is "ref".
+	RefInstaller interface {
+		Plugin
+		// SupportsRefs reports whether InstallRef can build refs, which plugins
+		// sharing an implementation that cannot build their sources may not.
+		SupportsRefs() bool
+		// InstallRef builds ref, a branch, tag or commit, into installPath.
+		InstallRef(ctx context.Context, ref, downloadPath, installPath string) error
+	}
+
+	// InPlaceInstaller extends Plugin for tools whose installs record their
+	// own path, e.g. in scripts, virtual environments or absolute symlinks,
+	// and so cannot be staged next to the install path and renamed into place.
+	InPlaceInstaller interface {
+		Plugin
+		// InstallsInPlace reports whether Install must write the final install path.
+		InstallsInPlace() bool
+	}
+
+	// GitHubReleaser extends Plugin for tools published from a GitHub
+	// repository, identified by pkg:github package URLs in SBOMs.
+	GitHubReleaser interface {
+		Plugin
+		// GitHubRepository returns the owner and name of the repository, empty
+		// when the plugin is not configured with one.
+		GitHubRepository() (owner, repo string)
+	}
+
+	// SBOMEnricher extends Plugin for tools that can describe an install
+	// beyond its version, e.g. the version embedded in a Go toolchain.
+	SBOMEnricher interface {
+		Plugin
+		// EnrichSBOM adds details of the install at installPath to component.
+		EnrichSBOM(installPath string, component *SBOMComponent) error
+	}
+
+	// Artifact is a file a plugin downloads to install a version.
+	Artifact struct {
+		// Name is the file name in the download path.
+		Name string
+		// URL is where the file is downloaded from.
+		URL string
+	}
+
+	// ArtifactDescription tells how a plugin selects the downloads of a version.
+	ArtifactDescription struct {
+		// Platform and Arch are the operating system and architecture as named
+		// by the downloads, e.g. "Darwin" and "x86_64".
+		Platform string
+		Arch     string
+		// ArchiveType is the format of the downloads, "none" for a bare binary.
+		ArchiveType string
+		// BinaryPath is the slash-separated path of the main executable below
+		// the install path.
+		BinaryPath string
+	}
+
 	// PluginHelp contains help information for a plugin.
 	PluginHelp struct {
 		// Overview is a general description of the plugin and tool.
@@ -92,6 +258,24 @@ type (
 		Config string
 		// Links provides useful links for the tool.
 		Links string
+		// HelpConfigEntries lists the environment variables of the plugin, which
+		// HelpConfig renders as a table before Config.
+		HelpConfigEntries []ConfigEntry
+		// Platforms lists the supported "<os>/<arch>" pairs, when known.
+		Platforms []string
+		// ArchiveType is the format of the downloaded release, "none" for a bare
+		// binary, when known.
+		ArchiveType string
+	}
+
+	// ConfigEntry describes an environment variable in PluginHelp.
+	ConfigEntry struct {
+		// Name is the variable name, e.g. ASDF_GOLANG_SET_GOBIN.
+		Name string
+		// Default is the value used when the variable is unset.
+		Default string
+		// Description explains the variable.
+		Description string
 	}
 
 	// InstallConfig holds configuration for installation.
@@ -109,6 +293,10 @@ type (
 		VersionPrefix string
 		VersionFilter string
 		UseTags       bool
+		// TagPrefix must start every tag and is stripped before VersionPrefix.
+		TagPrefix string
+		// IncludePrereleases keeps prereleases in the list even when stable versions exist.
+		IncludePrereleases bool
 	}
 )
 
@@ -116,6 +304,10 @@ var (
 	// httpClient is the HTTP client used by the package functions.
 	// It can be overridden for testing purposes.
 	httpClient atomic.Value //nolint:gochecknoglobals // used to lock the client
+	// muslLoaderGlob matches the dynamic loader shipped by musl based distributions.
+	muslLoaderGlob = "/lib/ld-musl-*" //nolint:gochecknoglobals // used for mocking
+	// processTranslated reports whether the process runs under Rosetta 2 translation.
+	processTranslated = isProcessTranslated //nolint:gochecknoglobals // used for mocking
 
 	// errPlatformNotSupported is returned when the running OS cannot be mapped to a supported platform.
 	errPlatformNotSupported = errors.New("platform not supported")
@@ -133,7 +325,7 @@ var (
 
 func init() { //nolint:gochecknoinits // used to lock the client
 	httpClient.Store(&http.Client{
-		Timeout: 30 * time.Minute,
+		Timeout: HTTPTimeout(),
 	})
 }
 
@@ -146,26 +338,103 @@ const (
 	CommonExecutablePermission os.FileMode = 0o755
 	// ExecutablePermissionMask is the mask used to set executable permissions.
 	ExecutablePermissionMask os.FileMode = 0o111
+
+	// PrereleaseQueryPrefix opts a latest-stable query into prereleases, e.g.
+	// "prerelease:1.30" returns the newest 1.30 version even if it is an -rc.
+	PrereleaseQueryPrefix = "prerelease:"
+
+	// utf8BOM is the byte order mark Windows editors may start UTF-8 files with.
+	utf8BOM = "\ufeff"
 )
 
-// GetPlatform returns the current platform (linux, darwin, freebsd).
+// BinaryNameOf returns the main executable name of plugin: its BinaryName when
+// it implements BinaryNamer, else the plugin name.
+func BinaryNameOf(plugin Plugin) string {
+	if namer, ok := plugin.(BinaryNamer); ok && namer.BinaryName() != "" {
+		return namer.BinaryName()
+	}
+
+	return plugin.Name()
+}
+
+// BinDirsOf returns the executable directories of the install of plugin at
+// installPath: its bin paths, "bin" when it lists none, followed by the extra
+// directories of a ShimContributor.
+func BinDirsOf(plugin Plugin, installPath string) []string {
+	binPaths := strings.Fields(plugin.ListBinPaths())
+	if len(binPaths) == 0 {
+		binPaths = []string{"bin"}
+	}
+
+	dirs := make([]string, 0, len(binPaths))
+	for _, binPath := range binPaths {
+		dirs = append(dirs, filepath.Join(installPath, binPath))
+	}
+
+	if contributor, ok := plugin.(ShimContributor); ok {
+		dirs = append(dirs, contributor.ExtraBinDirs(installPath)...)
+	}
+
+	return dirs
+}
+
+// HashIgnoreGlobsOf returns the HashTree ignore globs of the installs of
+// plugin, nil unless it implements HashIgnorer.
+func HashIgnoreGlobsOf(plugin Plugin) []string {
+	if ignorer, ok := plugin.(HashIgnorer); ok {
+		return ignorer.HashIgnoreGlobs()
+	}
+
+	return nil
+}
+
+// ForcePlatformEnv overrides the platform used to select downloads, e.g. to
+// fill a download cache for macOS hosts from Linux.
+const ForcePlatformEnv = "ASDF_FORCE_PLATFORM"
+
+// GetPlatform returns the current platform: linux, darwin, freebsd, openbsd,
+// netbsd or illumos. Plugins still decide which of them have downloads.
+// ASDF_FORCE_PLATFORM takes precedence over detection.
 func GetPlatform() (string, error) {
 	platform := strings.ToLower(runtime.GOOS)
+	if platformOverride := os.Getenv(ForcePlatformEnv); platformOverride != "" {
+		platform = strings.ToLower(platformOverride)
+	}
+
 	switch platform {
-	case "linux", "darwin", "freebsd":
+	case "linux", "darwin", "freebsd", "openbsd", "netbsd", "illumos":
 		return platform, nil
 	default:
 		return "", fmt.Errorf("%w: %s", errPlatformNotSupported, platform)
 	}
 }
 
-// GetArch returns the current architecture in Go download format.
-func GetArch() (string, error) {
-	archOverride := os.Getenv("ASDF_OVERWRITE_ARCH")
+// UnsupportedPlatformError wraps err for platform, e.g. "freebsd/amd64",
+// listing the "<os>/<arch>" pairs the plugin has downloads for.
+func UnsupportedPlatformError(err error, platform string, supported []string) error {
+	return fmt.Errorf("%w: %s (supported: %s)", err, platform, strings.Join(supported, ", "))
+}
+
+// ForceArchEnv overrides the architecture used to select downloads, e.g. to
+// install Intel binaries on Apple Silicon or native ones under Rosetta.
+const ForceArchEnv = "ASDF_FORCE_ARCH"
 
+// GetArch returns the current architecture in Go download format. An amd64
+// process translated by Rosetta 2 reports the arm64 hardware, so that native
+// downloads are preferred. ASDF_FORCE_ARCH (or the older ASDF_OVERWRITE_ARCH)
+// takes precedence over detection.
+func GetArch() (string, error) {
 	arch := runtime.GOARCH
-	if archOverride != "" {
+
+	if archOverride := os.Getenv(ForceArchEnv); archOverride != "" {
 		arch = archOverride
+	} else if archOverride := os.Getenv("ASDF_OVERWRITE_ARCH"); archOverride != "" {
+		arch = archOverride
+	} else if arch == "amd64" && processTranslated() {
+		Logger().Info("running under Rosetta 2, selecting native arm64 downloads",
+			"override", ForceArchEnv+"=amd64")
+
+		arch = "arm64"
 	}
 
 	switch arch {
@@ -188,20 +457,57 @@ func GetArch() (string, error) {
 	}
 }
 
+// Libc names returned by GetLibc.
+const (
+	// LibcGlibc is the GNU C library.
+	LibcGlibc = "glibc"
+	// LibcMusl is the musl C library used by Alpine and similar distributions.
+	LibcMusl = "musl"
+)
+
+// GetLibc returns the C library of the current Linux system. The value of the
+// overrideEnv variable (glibc or musl) wins when set; otherwise musl is detected
+// by the presence of its dynamic loader. Non-Linux systems report "".
+func GetLibc(overrideEnv string) string {
+	if override := strings.ToLower(os.Getenv(overrideEnv)); override != "" {
+		return override
+	}
+
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+
+	if matches, err := filepath.Glob(muslLoaderGlob); err == nil && len(matches) > 0 {
+		return LibcMusl
+	}
+
+	return LibcGlibc
+}
+
+// DefaultHTTPTimeout bounds a whole download made with HTTPClient, unless
+// ASDF_HTTP_TIMEOUT overrides it.
+const DefaultHTTPTimeout = 30 * time.Minute
+
+// HTTPTimeout returns the timeout of HTTPClient: ASDF_HTTP_TIMEOUT when set,
+// else DefaultHTTPTimeout.
+func HTTPTimeout() time.Duration {
+	return github.HTTPTimeout(DefaultHTTPTimeout)
+}
+
 // HTTPClient returns the HTTP client used by the package functions.
 func HTTPClient() *http.Client {
 	if client, ok := httpClient.Load().(*http.Client); ok && client != nil {
 		return client
 	}
 
-	return &http.Client{Timeout: 30 * time.Minute}
+	return &http.Client{Timeout: HTTPTimeout()}
 }
 
 // WithHTTPClient sets the HTTP client used by the package functions.
 // This is intended for testing purposes only.
 func WithHTTPClient(client *http.Client) {
 	if client == nil {
-		client = &http.Client{Timeout: 30 * time.Minute}
+		client = &http.Client{Timeout: HTTPTimeout()}
 	}
 
 	httpClient.Store(client)
@@ -220,10 +526,19 @@ func DownloadFile(ctx context.Context, url, destPath string) error {
 	}
 	defer resp.Body.Close()
 
+	LogHTTPResponse(ctx, resp)
+
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("%w with status %d for %s", errDownloadFailed, resp.StatusCode, url)
 	}
 
+	return SaveResponse(ctx, resp, destPath)
+}
+
+// SaveResponse writes the body of resp to destPath through a temporary file
+// renamed on success, so that a failed or cancelled download never leaves a
+// partial file under the final name.
+func SaveResponse(ctx context.Context, resp *http.Response, destPath string) error {
 	// Download to a temporary file first
 	// Use os.CreateTemp to avoid race conditions with multiple processes downloading the same file
 	// and to ensure the file is on the same filesystem as the destination for atomic rename.
@@ -247,7 +562,7 @@ func DownloadFile(ctx context.Context, url, destPath string) error {
 		}
 	}()
 
-	_, err = io.Copy(tempFile, resp.Body)
+	_, err = CopyWithProgress(ctx, filepath.Base(destPath), tempFile, resp.Body, resp.ContentLength)
 	if err != nil {
 		return fmt.Errorf("writing file %s: %w", destPath, err)
 	}
@@ -264,6 +579,20 @@ func DownloadFile(ctx context.Context, url, destPath string) error {
 	return nil
 }
 
+// LogHTTPResponse logs the request URL and response status at debug level. The
+// URL is taken from the final request, so redirects and proxy rewrites show up.
+func LogHTTPResponse(ctx context.Context, resp *http.Response) {
+	if resp == nil || resp.Request == nil {
+		return
+	}
+
+	Logger().DebugContext(ctx, "http request",
+		"method", resp.Request.Method,
+		"url", resp.Request.URL.String(),
+		"status", resp.StatusCode,
+	)
+}
+
 // DownloadString downloads content from URL and returns it as a string.
 func DownloadString(ctx context.Context, url string) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
@@ -277,6 +606,8 @@ func DownloadString(ctx context.Context, url string) (string, error) {
 	}
 	defer resp.Body.Close()
 
+	LogHTTPResponse(ctx, resp)
+
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("%w with status %d for %s", errDownloadFailed, resp.StatusCode, url)
 	}
@@ -289,20 +620,28 @@ func DownloadString(ctx context.Context, url string) (string, error) {
 	return string(body), nil
 }
 
-// VerifySHA256 verifies the SHA256 checksum of a file.
-func VerifySHA256(filePath, expectedHash string) error {
+// FileSHA256 returns the hex encoded SHA256 checksum of a file.
+func FileSHA256(filePath string) (string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("opening file for checksum: %w", err)
+		return "", fmt.Errorf("opening file for checksum: %w", err)
 	}
 	defer file.Close()
 
 	h := sha256.New()
 	if _, err := io.Copy(h, file); err != nil {
-		return fmt.Errorf("computing checksum: %w", err)
+		return "", fmt.Errorf("computing checksum: %w", err)
 	}
 
-	actualHash := hex.EncodeToString(h.Sum(nil))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifySHA256 verifies the SHA256 checksum of a file.
+func VerifySHA256(filePath, expectedHash string) error {
+	actualHash, err := FileSHA256(filePath)
+	if err != nil {
+		return err
+	}
 
 	trimmedExpectedHash := strings.TrimSpace(strings.Split(expectedHash, " ")[0])
 
@@ -323,6 +662,24 @@ func EnsureDir(path string) error {
 	return os.MkdirAll(path, CommonDirectoryPermission)
 }
 
+// ResetDir empties the directory at path, creating it when missing. The
+// directory itself is kept, so shared or mounted install paths keep their
+// ownership and permissions.
+func ResetDir(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(path, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return EnsureDir(path)
+}
+
 // Msgf prints a success message to stderr with formatting.
 func Msgf(format string, args ...any) {
 	// Skip output during testing to avoid interfering with test runner
@@ -343,36 +700,203 @@ func Errf(format string, args ...any) {
 	fmt.Fprintf(os.Stderr, "\033[31m"+format+"\033[39m\n", args...)
 }
 
-// SortVersions sorts version strings in semver order.
+// SortVersions sorts version strings in ascending CompareVersions order.
+// Versions of equal precedence keep their relative order.
 func SortVersions(versions []string) {
-	sort.Slice(versions, func(i, j int) bool {
-		return CompareVersions(versions[i], versions[j]) < 0
-	})
+	slices.SortStableFunc(versions, CompareVersions)
 }
 
 // CompareVersions compares two version strings.
 // Returns negative if a < b, positive if a > b, zero if equal.
+//
+// Numeric components compare by value whatever their length, missing ones
+// count as zero and a leading "v" is ignored. A suffix such as "-rc.1",
+// "beta2" or "-dev.5" marks a prerelease ordered before its release, with
+// dev < alpha < beta < pre < rc, while "post" and "p" suffixes order after
+// it. Build metadata after "+" only breaks ties.
 func CompareVersions(a, b string) int {
-	partsA := ParseVersionParts(a)
-	partsB := ParseVersionParts(b)
-
-	maxLen := max(len(partsA), len(partsB))
-	for i := range maxLen {
-		var partA, partB int
-		if i < len(partsA) {
-			partA = partsA[i]
+	keyA, keyB := parseVersionKey(a), parseVersionKey(b)
+
+	if result := strings.Compare(keyA.prefix, keyB.prefix); result != 0 {
+		return result
+	}
+
+	for i := range max(len(keyA.release), len(keyB.release)) {
+		partA, partB := "0", "0"
+		if i < len(keyA.release) {
+			partA = keyA.release[i]
+		}
+
+		if i < len(keyB.release) {
+			partB = keyB.release[i]
+		}
+
+		if result := compareNumericIdentifiers(partA, partB); result != 0 {
+			return result
+		}
+	}
+
+	if result := cmp.Compare(keyA.stage, keyB.stage); result != 0 {
+		return result
+	}
+
+	if result := compareVersionIdentifiers(keyA.suffix, keyB.suffix); result != 0 {
+		return result
+	}
+
+	return compareVersionIdentifiers(keyA.build, keyB.build)
+}
+
+type (
+	// versionKey is a version string split into the parts CompareVersions orders by.
+	versionKey struct {
+		// prefix is any text before the first digit, such as "jdk-".
+		prefix string
+		// release are the dot-separated numeric components.
+		release []string
+		// suffix are the identifiers following the release.
+		suffix []string
+		// build are the identifiers of the build metadata.
+		build []string
+		// stage orders prereleases (-1) before releases (0) before post-releases (1).
+		stage int
+	}
+)
+
+var (
+	// versionLabelRanks orders well-known prerelease labels; unknown labels sort first.
+	versionLabelRanks = map[string]int{ //nolint:gochecknoglobals // read-only lookup table
+		"dev": 1, "snapshot": 1, "nightly": 1,
+		"alpha": 2, "a": 2,
+		"beta": 3, "b": 3,
+		"pre": 4, "preview": 4,
+		"rc": 5, "c": 5,
+	}
+	// postReleaseLabels are suffixes marking a release after the plain version.
+	postReleaseLabels = []string{"post", "p", "patch"} //nolint:gochecknoglobals // read-only lookup table
+)
+
+// parseVersionKey splits version for comparison.
+func parseVersionKey(version string) versionKey {
+	version, build, _ := strings.Cut(strings.TrimSpace(version), "+")
+
+	key := versionKey{build: splitVersionIdentifiers(build)}
+
+	start := strings.IndexFunc(version, isASCIIDigit)
+	if start < 0 {
+		key.prefix = version
+
+		return key
+	}
+
+	if prefix := version[:start]; prefix != "v" && prefix != "V" {
+		key.prefix = prefix
+	}
+
+	end := start
+
+	for {
+		digits := end
+		for end < len(version) && isASCIIDigit(rune(version[end])) {
+			end++
 		}
 
-		if i < len(partsB) {
-			partB = partsB[i]
+		key.release = append(key.release, version[digits:end])
+
+		if end+1 >= len(version) || version[end] != '.' || !isASCIIDigit(rune(version[end+1])) {
+			break
 		}
 
-		if partA != partB {
-			return partA - partB
+		end++
+	}
+
+	key.suffix = splitVersionIdentifiers(version[end:])
+	if len(key.suffix) > 0 {
+		key.stage = -1
+		if slices.Contains(postReleaseLabels, strings.ToLower(key.suffix[0])) {
+			key.stage = 1
 		}
 	}
 
-	return 0
+	return key
+}
+
+// splitVersionIdentifiers splits a version suffix at separators and at
+// boundaries between letters and digits, so "rc.1", "-rc1" and "rc-1" agree.
+func splitVersionIdentifiers(suffix string) []string {
+	var identifiers []string
+
+	current := ""
+	for _, char := range suffix {
+		switch {
+		case char == '.' || char == '-' || char == '_':
+			if current != "" {
+				identifiers = append(identifiers, current)
+			}
+
+			current = ""
+		case current != "" && isASCIIDigit(char) != isASCIIDigit(rune(current[len(current)-1])):
+			identifiers = append(identifiers, current)
+			current = string(char)
+		default:
+			current += string(char)
+		}
+	}
+
+	if current != "" {
+		identifiers = append(identifiers, current)
+	}
+
+	return identifiers
+}
+
+// compareVersionIdentifiers compares suffix identifiers pairwise; numeric
+// identifiers order before labels and a shorter list orders first.
+func compareVersionIdentifiers(a, b []string) int {
+	for i := range min(len(a), len(b)) {
+		numericA, numericB := isNumericIdentifier(a[i]), isNumericIdentifier(b[i])
+
+		var result int
+
+		switch {
+		case numericA && numericB:
+			result = compareNumericIdentifiers(a[i], b[i])
+		case numericA:
+			result = -1
+		case numericB:
+			result = 1
+		default:
+			labelA, labelB := strings.ToLower(a[i]), strings.ToLower(b[i])
+
+			result = cmp.Or(
+				cmp.Compare(versionLabelRanks[labelA], versionLabelRanks[labelB]),
+				strings.Compare(labelA, labelB),
+			)
+		}
+
+		if result != 0 {
+			return result
+		}
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+// isNumericIdentifier reports whether identifier only has digits.
+func isNumericIdentifier(identifier string) bool {
+	return identifier != "" && strings.IndexFunc(identifier, func(char rune) bool { return !isASCIIDigit(char) }) < 0
+}
+
+// compareNumericIdentifiers compares digit strings of any length by value.
+func compareNumericIdentifiers(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+
+	return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+}
+
+// isASCIIDigit reports whether char is 0-9.
+func isASCIIDigit(char rune) bool {
+	return char >= '0' && char <= '9'
 }
 
 // ParseVersionParts extracts numeric parts from a version string.
@@ -402,14 +926,60 @@ func FilterVersions(versions []string, predicate func(string) bool) []string {
 	return result
 }
 
-// ReadLegacyVersionFile reads and parses a legacy version file.
-func ReadLegacyVersionFile(path string) (string, error) {
+// ReadTextFile reads the text file at path without the UTF-8 byte order mark
+// Windows editors may start it with, and with CRLF line endings turned into LF.
+func ReadTextFile(path string) (string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return "", err
 	}
 
-	return strings.TrimSpace(string(data)), nil
+	return NormalizeText(string(data)), nil
+}
+
+// NormalizeText drops a leading UTF-8 byte order mark from content and turns
+// its CRLF line endings into LF.
+func NormalizeText(content string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(content, utf8BOM), "\r\n", "\n")
+}
+
+// VersionFileLines yields the meaningful lines of version file content, such
+// as a .tool-versions or .nvmrc, with their 1-based line numbers. A leading
+// UTF-8 byte order mark is dropped, lines are trimmed of surrounding
+// whitespace, including the CR of CRLF line endings, and blank lines and
+// lines starting with # are skipped.
+func VersionFileLines(content string) iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		lineNumber := 0
+
+		for line := range strings.Lines(strings.TrimPrefix(content, utf8BOM)) {
+			lineNumber++
+
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			if !yield(lineNumber, line) {
+				return
+			}
+		}
+	}
+}
+
+// ReadVersionFile returns the first meaningful line of the version file at
+// path (see VersionFileLines), or "" when it has none.
+func ReadVersionFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range VersionFileLines(string(data)) {
+		return line, nil
+	}
+
+	return "", nil
 }
 
 // ListGitHubVersions lists versions from a GitHub repository.
@@ -449,6 +1019,13 @@ func ListGitHubVersions(ctx context.Context, client interface {
 
 	versions := make([]string, 0, len(tags))
 	for _, tag := range tags {
+		if cfg.TagPrefix != "" {
+			var ok bool
+			if tag, ok = strings.CutPrefix(tag, cfg.TagPrefix); !ok {
+				continue
+			}
+		}
+
 		if cfg.VersionPrefix != "" {
 			if cfg.UseTags && !strings.HasPrefix(tag, cfg.VersionPrefix) {
 				continue
@@ -470,11 +1047,13 @@ func ListGitHubVersions(ctx context.Context, client interface {
 
 	SortVersions(versions)
 
+	if cfg.IncludePrereleases {
+		return versions, nil
+	}
+
 	// Prefer stable versions in list-all output when possible, but keep
 	// prereleases when no stable versions exist.
-	stable := FilterVersions(versions, func(v string) bool {
-		return !IsPrereleaseVersion(v)
-	})
+	stable := FilterVersions(versions, IsStableVersion)
 
 	if len(stable) > 0 {
 		return stable, nil
@@ -484,55 +1063,129 @@ func ListGitHubVersions(ctx context.Context, client interface {
 }
 
 // IsPrereleaseVersion reports whether a version string represents a prerelease.
-// It matches common markers like "rc", "alpha", "beta" and "-pre".
+// It is the negation of IsStableVersion.
 func IsPrereleaseVersion(version string) bool {
-	lower := strings.ToLower(version)
+	return !IsStableVersion(version)
+}
+
+// IsStableVersion reports whether version is a release rather than a prerelease
+// or a nightly build. Suffixes such as "-rc.1", "-beta", "-alpha", "-dev" and
+// ".pre" in any spelling, e.g. "v1.30.0-rc.1" or "3.13.0b1", and date stamps
+// such as "1.2.0-20240501" or "nightly-2024-05-01" mark unstable versions.
+func IsStableVersion(version string) bool {
+	key := parseVersionKey(version)
+
+	for _, identifier := range slices.Concat(splitVersionIdentifiers(key.prefix), key.suffix) {
+		if _, ok := versionLabelRanks[strings.ToLower(identifier)]; ok {
+			return false
+		}
+	}
+
+	return !hasVersionDateStamp(key.suffix)
+}
+
+// hasVersionDateStamp reports whether identifiers contain a YYYYMMDD or
+// YYYY, MM, DD date stamp.
+func hasVersionDateStamp(identifiers []string) bool {
+	isYear := func(identifier string) bool {
+		return len(identifier) == 4 && (strings.HasPrefix(identifier, "19") || strings.HasPrefix(identifier, "20"))
+	}
+
+	for i, identifier := range identifiers {
+		if len(identifier) == 8 && isYear(identifier[:4]) && isNumericIdentifier(identifier) {
+			return true
+		}
+
+		if isYear(identifier) && isNumericIdentifier(identifier) && i+2 < len(identifiers) &&
+			len(identifiers[i+1]) == 2 && isNumericIdentifier(identifiers[i+1]) &&
+			len(identifiers[i+2]) == 2 && isNumericIdentifier(identifiers[i+2]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseLatestQuery splits a latest-stable query into the version query and
+// whether it opts into prereleases with PrereleaseQueryPrefix.
+func ParseLatestQuery(query string) (string, bool) {
+	pattern, found := strings.CutPrefix(query, PrereleaseQueryPrefix)
+
+	return pattern, found
+}
 
-	return strings.Contains(lower, "rc") ||
-		strings.Contains(lower, "alpha") ||
-		strings.Contains(lower, "beta") ||
-		strings.Contains(lower, "-pre")
+// MinorSeriesQuery narrows a two-component query such as "1.29" to the patch
+// releases of that minor series, so it no longer matches "1.290.0". Other
+// queries, including the PrereleaseQueryPrefix, are returned unchanged.
+func MinorSeriesQuery(query string) string {
+	pattern, prereleaseQuery := ParseLatestQuery(query)
+
+	major, minor, found := strings.Cut(pattern, ".")
+	if !found || !isNumericIdentifier(major) || !isNumericIdentifier(minor) {
+		return query
+	}
+
+	if prereleaseQuery {
+		return PrereleaseQueryPrefix + pattern + "."
+	}
+
+	return pattern + "."
 }
 
 // LatestVersion returns the latest stable version from a list, optionally filtered by pattern.
 //
-// A version is considered a prerelease if it contains common pre-release markers
-// such as "rc", "alpha", "beta" or "-pre". When both stable and prerelease
-// versions are present, the latest stable version is returned. If only
-// prerelease versions are available, the latest prerelease is returned.
+// When both stable and prerelease versions are present, the latest stable
+// version is returned. If only prerelease versions are available, the latest
+// prerelease is returned. A pattern starting with PrereleaseQueryPrefix
+// considers prereleases as well.
 func LatestVersion(versions []string, pattern string) string {
-	filtered := versions
-	if pattern != "" {
-		filtered = FilterVersions(versions, func(v string) bool {
-			return strings.HasPrefix(v, pattern)
-		})
+	return SelectLatestVersion(versions, pattern, false)
+}
+
+// SelectLatestVersion returns the latest version matching query as
+// LatestVersion does, considering prereleases as well when includePrereleases
+// is set or the query starts with PrereleaseQueryPrefix. An invalid query
+// matches nothing.
+func SelectLatestVersion(versions []string, query string, includePrereleases bool) string {
+	latest, _ := MatchLatestVersion(versions, query, includePrereleases) //nolint:errcheck // an invalid query matches nothing
+
+	return latest
+}
+
+// MatchLatestVersion returns the latest version matching query (see
+// VersionQuery) as SelectLatestVersion does, or an error when query is invalid.
+func MatchLatestVersion(versions []string, query string, includePrereleases bool) (string, error) {
+	parsed, err := ParseVersionQuery(query)
+	if err != nil {
+		return "", err
 	}
 
+	filtered := WithoutSystemVersion(FilterVersions(versions, parsed.Match))
+
 	if len(filtered) == 0 {
-		return ""
+		return "", nil
 	}
 
-	// Prefer stable versions over prereleases when possible.
-	stable := FilterVersions(filtered, func(v string) bool {
-		return !IsPrereleaseVersion(v)
-	})
+	SortVersions(filtered)
 
-	if len(stable) > 0 {
-		SortVersions(stable)
+	if includePrereleases || parsed.Prerelease {
+		return filtered[len(filtered)-1], nil
+	}
 
-		return stable[len(stable)-1]
+	// Prefer stable versions over prereleases when possible.
+	stable := FilterVersions(filtered, IsStableVersion)
+	if len(stable) > 0 {
+		return stable[len(stable)-1], nil
 	}
 
 	// Fall back to prerelease versions if no stable ones exist.
-	SortVersions(filtered)
-
-	return filtered[len(filtered)-1]
+	return filtered[len(filtered)-1], nil
 }
 
 // LatestStableWithQuery provides a generic implementation for finding the
-// latest stable version from a list of versions, with optional query prefix
-// filtering. It filters out prerelease versions and returns the newest stable
-// version matching the query.
+// latest stable version from a list of versions, with optional query
+// filtering (see VersionQuery). It filters out prerelease versions and returns the newest stable
+// version matching the query, unless the query starts with PrereleaseQueryPrefix.
 func LatestStableWithQuery(
 	ctx context.Context,
 	query string,
@@ -545,28 +1198,14 @@ func LatestStableWithQuery(
 		return "", errNoVersions
 	}
 
-	filteredVersions := versions
-	if query != "" {
-		filteredVersions = nil
-
-		for _, v := range versions {
-			if strings.HasPrefix(v, query) {
-				filteredVersions = append(filteredVersions, v)
-			}
-		}
+	latest, err := MatchLatestVersion(versions, query, false)
+	if err != nil {
+		return "", err
 	}
 
-	if len(filteredVersions) == 0 {
+	if latest == "" {
 		return "", fmt.Errorf("%w: %s", errNoMatching, query)
 	}
 
-	stable := FilterVersions(filteredVersions, func(v string) bool {
-		return !IsPrereleaseVersion(v)
-	})
-
-	if len(stable) == 0 {
-		return filteredVersions[len(filteredVersions)-1], nil
-	}
-
-	return stable[len(stable)-1], nil
+	return latest, nil
 }
@@ -16,12 +16,16 @@
 package asdf_test
 
 import (
+	"context"
 	"errors"
+	"math/rand/v2"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -35,7 +39,20 @@ func TestGetPlatform(t *testing.T) {
 
 	platform, err := asdf.GetPlatform()
 	require.NoError(t, err)
-	require.Contains(t, []string{"linux", "darwin", "windows", "freebsd"}, platform)
+	require.Contains(t, []string{"linux", "darwin", "freebsd", "openbsd", "netbsd", "illumos"}, platform)
+}
+
+func TestGetPlatformForce(t *testing.T) {
+	t.Setenv(asdf.ForcePlatformEnv, "Darwin")
+
+	platform, err := asdf.GetPlatform()
+	require.NoError(t, err)
+	require.Equal(t, "darwin", platform)
+
+	t.Setenv(asdf.ForcePlatformEnv, "plan9")
+
+	_, err = asdf.GetPlatform()
+	require.Error(t, err)
 }
 
 func TestGetArch(t *testing.T) {
@@ -85,6 +102,72 @@ func TestGetArch(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestGetArchRosetta(t *testing.T) {
+	native, err := asdf.GetArch()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		force      string
+		expected   string
+		translated bool
+	}{
+		{name: "native process keeps its arch", expected: native},
+		{name: "translated amd64 process selects arm64", translated: true, expected: "arm64"},
+		{name: "force amd64 under Rosetta", translated: true, force: "amd64", expected: "amd64"},
+		{name: "force arm64 natively", force: "aarch64", expected: "arm64"},
+		{name: "force x86_64 natively", force: "x86_64", expected: "amd64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.translated && runtime.GOARCH != "amd64" {
+				t.Skip("Rosetta translation only applies to amd64 processes")
+			}
+
+			t.Setenv("ASDF_OVERWRITE_ARCH", "")
+			t.Setenv(asdf.ForceArchEnv, tt.force)
+			asdf.SetProcessTranslatedForTests(t, tt.translated)
+
+			arch, err := asdf.GetArch()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, arch)
+		})
+	}
+
+	t.Run("ASDF_FORCE_ARCH wins over ASDF_OVERWRITE_ARCH", func(t *testing.T) {
+		t.Setenv("ASDF_OVERWRITE_ARCH", "arm64")
+		t.Setenv(asdf.ForceArchEnv, "amd64")
+
+		arch, err := asdf.GetArch()
+		require.NoError(t, err)
+		require.Equal(t, "amd64", arch)
+	})
+}
+
+func TestGetLibc(t *testing.T) {
+	const overrideEnv = "ASDF_TEST_LIBC"
+
+	t.Setenv(overrideEnv, "MUSL")
+	require.Equal(t, asdf.LibcMusl, asdf.GetLibc(overrideEnv))
+
+	t.Setenv(overrideEnv, "")
+
+	if runtime.GOOS != "linux" {
+		require.Empty(t, asdf.GetLibc(overrideEnv))
+
+		return
+	}
+
+	libDir := t.TempDir()
+	asdf.SetMuslLoaderGlobForTests(t, filepath.Join(libDir, "ld-musl-*"))
+
+	require.Equal(t, asdf.LibcGlibc, asdf.GetLibc(overrideEnv))
+
+	require.NoError(t, os.WriteFile(filepath.Join(libDir, "ld-musl-x86_64.so.1"), nil, asdf.CommonFilePermission))
+	require.Equal(t, asdf.LibcMusl, asdf.GetLibc(overrideEnv))
+}
+
 func TestHTTPClient(t *testing.T) {
 	t.Parallel()
 
@@ -243,6 +326,98 @@ func TestSortVersions(t *testing.T) {
 	require.Equal(t, []string{"1.0.0", "1.1.0", "2.0.0", "10.0.0"}, versions)
 }
 
+// TestIsStableVersion verifies prerelease and nightly versions are not stable.
+func TestIsStableVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version string
+		stable  bool
+	}{
+		{version: "1.30.0", stable: true},
+		{version: "v1.30.0", stable: true},
+		{version: "2024.1.0", stable: true},
+		{version: "1.0.0.post1", stable: true},
+		{version: "17.0.2+8", stable: true},
+		{version: "v1.30.0-rc.1"},
+		{version: "3.13.0b1"},
+		{version: "3.13.0a2"},
+		{version: "1.21rc1"},
+		{version: "2.0.0-beta"},
+		{version: "2.0.0-alpha.3"},
+		{version: "0.14.0-dev.3451+d8d2aa9af"},
+		{version: "1.0.0.pre"},
+		{version: "1.0.0-preview2"},
+		{version: "1.2.0-20240501"},
+		{version: "1.2.0-nightly.2024.05.01"},
+		{version: "nightly-2024-05-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.stable, asdf.IsStableVersion(tt.version))
+			require.Equal(t, !tt.stable, asdf.IsPrereleaseVersion(tt.version))
+		})
+	}
+}
+
+// TestSortVersionsOrdering verifies any shuffle of a known-good ordering sorts back into it.
+func TestSortVersionsOrdering(t *testing.T) {
+	t.Parallel()
+
+	ordered := []string{
+		"0.9.0",
+		"0.10.0-dev.3451+d8d2aa9af",
+		"0.10.0",
+		"1.0.0-dev",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.0.post1",
+		"1.0.1",
+		"1.9.0",
+		"1.10.0",
+		"1.21rc1",
+		"1.21.0",
+		"1.21.1",
+		"2.15.2",
+		"2.15.10",
+		"2.15.10.1",
+		"v3.0.0",
+		"3.13.0a1",
+		"3.13.0b1",
+		"3.13.0rc2",
+		"3.13.0",
+		"10.0.0",
+	}
+
+	for i, version := range ordered {
+		require.Zero(t, asdf.CompareVersions(version, version), version)
+
+		for _, later := range ordered[i+1:] {
+			require.Negative(t, asdf.CompareVersions(version, later), "%s < %s", version, later)
+			require.Positive(t, asdf.CompareVersions(later, version), "%s > %s", later, version)
+		}
+	}
+
+	random := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // deterministic shuffles
+	for range 100 {
+		shuffled := slices.Clone(ordered)
+		random.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+
+		asdf.SortVersions(shuffled)
+		require.Equal(t, ordered, shuffled)
+	}
+}
+
 func TestCompareVersions(t *testing.T) {
 	t.Parallel()
 
@@ -257,6 +432,16 @@ func TestCompareVersions(t *testing.T) {
 		{"1.0.0 == 1.0.0", "1.0.0", "1.0.0", 0},
 		{"1.9 < 1.10", "1.9", "1.10", -1},
 		{"1.21.0 > 1.20.0", "1.21.0", "1.20.0", 1},
+		{"2.15.9 < 2.15.10", "2.15.9", "2.15.10", -1},
+		{"v prefix ignored", "v1.2.3", "1.2.3", 0},
+		{"missing parts are zero", "1.2", "1.2.0", 0},
+		{"rc before release", "1.0.0-rc1", "1.0.0", -1},
+		{"beta before rc", "1.21beta2", "1.21rc1", -1},
+		{"dev before alpha", "0.14.0-dev.1", "0.14.0-alpha", -1},
+		{"numeric prerelease", "1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"post after release", "1.0.0.post1", "1.0.0", 1},
+		{"build metadata breaks ties", "17.0.2+8", "17.0.2+10", -1},
+		{"long components", "1.99999999999999999999", "1.100000000000000000000", -1},
 	}
 
 	for _, tt := range tests {
@@ -299,52 +484,62 @@ func TestParseVersionParts(t *testing.T) {
 	}
 }
 
-func TestReadLegacyVersionFile(t *testing.T) {
+func TestReadVersionFile(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name     string
-		setup    func(*testing.T) string
+		content  string
 		expected string
-		wantErr  bool
 	}{
-		{
-			name: "reads and trims version file",
-			setup: func(t *testing.T) string {
-				t.Helper()
-
-				path := filepath.Join(t.TempDir(), ".version")
-				require.NoError(
-					t,
-					os.WriteFile(path, []byte("  1.21.0  \n"), asdf.CommonFilePermission),
-				)
-
-				return path
-			},
-			expected: "1.21.0",
-		},
-		{
-			name:    "returns error for nonexistent file",
-			setup:   func(*testing.T) string { return "/nonexistent/file" },
-			wantErr: true,
-		},
+		{name: "trims surrounding whitespace", content: "  1.21.0  \n", expected: "1.21.0"},
+		{name: "drops the byte order mark", content: "\ufeff1.21.0\n", expected: "1.21.0"},
+		{name: "drops the CR of CRLF line endings", content: "1.21.0\r\n", expected: "1.21.0"},
+		{name: "keeps the first meaningful line", content: "\ufeff# pinned\r\n\r\n1.21.0 \r\n1.20.0\r\n", expected: "1.21.0"},
+		{name: "returns nothing for empty files", content: "", expected: ""},
+		{name: "returns nothing for blank files", content: "\ufeff \r\n\r\n", expected: ""},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			path := tt.setup(t)
+			path := filepath.Join(t.TempDir(), ".version")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), asdf.CommonFilePermission))
 
-			version, err := asdf.ReadLegacyVersionFile(path)
-			if tt.wantErr {
-				require.Error(t, err)
-			} else {
-				require.NoError(t, err)
-				require.Equal(t, tt.expected, version)
-			}
+			version, err := asdf.ReadVersionFile(path)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, version)
 		})
 	}
+
+	_, err := asdf.ReadVersionFile("/nonexistent/file")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// TestVersionFileLines verifies meaningful lines keep their line numbers.
+func TestVersionFileLines(t *testing.T) {
+	t.Parallel()
+
+	var lines []string
+
+	for number, line := range asdf.VersionFileLines("\ufeffgolang 1.22.5\r\n\r\n# comment\r\n  zig 0.14.0  \r\n") {
+		lines = append(lines, strconv.Itoa(number)+":"+line)
+	}
+
+	require.Equal(t, []string{"1:golang 1.22.5", "4:zig 0.14.0"}, lines)
+}
+
+// TestReadTextFile verifies the byte order mark and CRLF line endings are normalized.
+func TestReadTextFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".sdkmanrc")
+	require.NoError(t, os.WriteFile(path, []byte("\ufeffjava=21.0.4-tem\r\nmaven=3.9.9\r\n"), asdf.CommonFilePermission))
+
+	content, err := asdf.ReadTextFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "java=21.0.4-tem\nmaven=3.9.9\n", content)
 }
 
 func TestMsgAndErr(t *testing.T) {
@@ -442,6 +637,72 @@ func TestDownloadFile(t *testing.T) {
 	}
 }
 
+func TestDownloadFileCancelled(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1048576")
+		_, _ = w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		close(started)
+
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	destDir := t.TempDir()
+	destPath := filepath.Join(destDir, "archive.tar.gz")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	begin := time.Now()
+	err := asdf.DownloadFile(ctx, server.URL+"/archive.tar.gz", destPath)
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, time.Since(begin), time.Second)
+	require.NoFileExists(t, destPath)
+
+	entries, err := os.ReadDir(destDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestContextReader(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	reader := asdf.ContextReader(ctx, strings.NewReader("content"))
+
+	buf := make([]byte, 3)
+	n, err := reader.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "con", string(buf[:n]))
+
+	cancel()
+
+	_, err = reader.Read(buf)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	t.Setenv("ASDF_HTTP_TIMEOUT", "")
+	require.Equal(t, asdf.DefaultHTTPTimeout, asdf.HTTPTimeout())
+
+	t.Setenv("ASDF_HTTP_TIMEOUT", "90")
+	require.Equal(t, 90*time.Second, asdf.HTTPTimeout())
+
+	t.Setenv("ASDF_HTTP_TIMEOUT", "2m")
+	require.Equal(t, 2*time.Minute, asdf.HTTPTimeout())
+
+	t.Setenv("ASDF_HTTP_TIMEOUT", "soon")
+	require.Equal(t, asdf.DefaultHTTPTimeout, asdf.HTTPTimeout())
+}
+
 func TestDownloadString(t *testing.T) {
 	t.Parallel()
 
@@ -536,7 +797,54 @@ func TestLatestVersion(t *testing.T) {
 		{
 			name:     "falls back to prereleases when no stable versions exist",
 			versions: []string{"1.1.0-rc1", "1.1.0-beta1"},
-			expected: "1.1.0-beta1",
+			expected: "1.1.0-rc1",
+		},
+		{
+			name:     "filters python-style prereleases",
+			versions: []string{"3.12.4", "3.13.0b1"},
+			expected: "3.12.4",
+		},
+		{
+			name:     "prerelease query includes prereleases",
+			versions: []string{"1.29.3", "1.30.0-rc.1"},
+			pattern:  asdf.PrereleaseQueryPrefix,
+			expected: "1.30.0-rc.1",
+		},
+		{
+			name:     "prerelease query keeps the version prefix",
+			versions: []string{"1.29.3", "1.30.0-rc.1"},
+			pattern:  asdf.PrereleaseQueryPrefix + "1.29",
+			expected: "1.29.3",
+		},
+		{
+			name:     "regexp query matches anywhere in the version",
+			versions: []string{"0.12.1", "0.13.0", "0.14.0", "1.0.13.2"},
+			pattern:  asdf.RegexpQueryPrefix + `^0\.13\.`,
+			expected: "0.13.0",
+		},
+		{
+			name:     "exclusion drops matching versions",
+			versions: []string{"1.4.2", "1.5.0", "1.5.3", "2.0.0"},
+			pattern:  "1. !1.5.",
+			expected: "1.4.2",
+		},
+		{
+			name:     "regexp exclusion",
+			versions: []string{"1.60.1", "1.61.0", "1.61.0-rc.1"},
+			pattern:  "!" + asdf.RegexpQueryPrefix + `\.61\.`,
+			expected: "1.60.1",
+		},
+		{
+			name:     "exclusion with prerelease query",
+			versions: []string{"1.29.3", "1.30.0-rc.1", "1.31.0-alpha.1"},
+			pattern:  asdf.PrereleaseQueryPrefix + "1. !1.31",
+			expected: "1.30.0-rc.1",
+		},
+		{
+			name:     "invalid regexp matches nothing",
+			versions: []string{"1.0.0"},
+			pattern:  asdf.RegexpQueryPrefix + "1.(",
+			expected: "",
 		},
 		{
 			name:     "returns empty string if no match",
@@ -561,6 +869,44 @@ func TestLatestVersion(t *testing.T) {
 	}
 }
 
+// TestMinorSeriesQuery verifies two-component queries select a single minor series.
+func TestMinorSeriesQuery(t *testing.T) {
+	t.Parallel()
+
+	versions := []string{"1.2.9", "1.29.0", "1.29.4", "1.290.0", "1.30.0-rc.1"}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+		latest   string
+	}{
+		{name: "minor series", query: "1.29", expected: "1.29.", latest: "1.29.4"},
+		{name: "short minor series", query: "1.2", expected: "1.2.", latest: "1.2.9"},
+		{name: "major only", query: "1", expected: "1", latest: "1.290.0"},
+		{name: "full version", query: "1.29.0", expected: "1.29.0", latest: "1.29.0"},
+		{name: "trailing dot", query: "1.29.", expected: "1.29.", latest: "1.29.4"},
+		{name: "non-numeric", query: "1.x", expected: "1.x", latest: ""},
+		{name: "empty", query: "", expected: "", latest: "1.290.0"},
+		{
+			name:     "prerelease minor series",
+			query:    asdf.PrereleaseQueryPrefix + "1.30",
+			expected: asdf.PrereleaseQueryPrefix + "1.30.",
+			latest:   "1.30.0-rc.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			query := asdf.MinorSeriesQuery(tt.query)
+			require.Equal(t, tt.expected, query)
+			require.Equal(t, tt.latest, asdf.LatestVersion(versions, query))
+		})
+	}
+}
+
 var (
 	errTestNoVersions = errors.New("no versions found")
 	errTestNoMatching = errors.New("no matching versions")
@@ -641,3 +987,40 @@ func TestLatestStableWithQuery(t *testing.T) {
 		})
 	}
 }
+
+// shimContributorPlugin is a Plugin with an extra apps directory.
+type shimContributorPlugin struct {
+	asdf.Plugin
+}
+
+func (shimContributorPlugin) ListBinPaths() string { return "" }
+
+func (shimContributorPlugin) ExtraBinDirs(installPath string) []string {
+	return []string{filepath.Join(installPath, "apps", "bin")}
+}
+
+// TestBinDirsOf verifies bin paths default to bin and are followed by the extra directories.
+func TestBinDirsOf(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"/opt/tool/bin", "/opt/tool/apps/bin"}, asdf.BinDirsOf(shimContributorPlugin{}, "/opt/tool"))
+}
+
+// TestResetDir verifies a directory is emptied in place, or created when missing.
+func TestResetDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bin", "tool"), nil, asdf.CommonExecutablePermission))
+
+	require.NoError(t, asdf.ResetDir(dir))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	missing := filepath.Join(dir, "missing")
+	require.NoError(t, asdf.ResetDir(missing))
+	require.DirExists(t, missing)
+}
@@ -0,0 +1,102 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ConcurrencyEnv sets the number of jobs source builds run in parallel,
+	// e.g. "4", or "auto" for the number of CPUs, the default.
+	ConcurrencyEnv = "ASDF_CONCURRENCY"
+	// MaxConcurrencyEnv caps the build parallelism, e.g. on builders with
+	// little memory per CPU, where every compiler job needs its share.
+	MaxConcurrencyEnv = "ASDF_MAX_CONCURRENCY"
+
+	// concurrencyAuto selects the number of CPUs in ConcurrencyEnv.
+	concurrencyAuto = "auto"
+)
+
+// buildConcurrencyContextKey is the context key of the build parallelism.
+type buildConcurrencyContextKey struct{}
+
+// BuildConcurrency returns the number of jobs source builds run in parallel:
+// ConcurrencyEnv, or the number of CPUs when unset, "auto" or invalid, capped
+// at MaxConcurrencyEnv.
+func BuildConcurrency() int {
+	return capConcurrency(positiveEnvInt(ConcurrencyEnv, runtime.NumCPU()))
+}
+
+// capConcurrency returns jobs capped at MaxConcurrencyEnv, and at least one.
+func capConcurrency(jobs int) int {
+	if limit := positiveEnvInt(MaxConcurrencyEnv, 0); limit > 0 && jobs > limit {
+		jobs = limit
+	}
+
+	return max(jobs, 1)
+}
+
+// positiveEnvInt returns the positive integer in the variable name, or
+// fallback when it is unset, "auto" or not a positive integer, which is logged.
+func positiveEnvInt(name string, fallback int) int {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" || strings.EqualFold(value, concurrencyAuto) {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		Logger().Warn("ignoring invalid build concurrency", "variable", name, "value", value)
+
+		return fallback
+	}
+
+	return parsed
+}
+
+// WithBuildConcurrency returns a context carrying the build parallelism jobs,
+// read by BuildVersion callbacks through BuildConcurrencyFromContext.
+func WithBuildConcurrency(ctx context.Context, jobs int) context.Context {
+	return context.WithValue(ctx, buildConcurrencyContextKey{}, jobs)
+}
+
+// BuildConcurrencyFromContext returns the build parallelism attached to ctx,
+// or BuildConcurrency when none is attached.
+func BuildConcurrencyFromContext(ctx context.Context) int {
+	if jobs, ok := ctx.Value(buildConcurrencyContextKey{}).(int); ok && jobs > 0 {
+		return jobs
+	}
+
+	return BuildConcurrency()
+}
+
+// BuildConcurrencyEnv returns the variables passing the build parallelism
+// jobs to make: MAKEFLAGS, and MAKE_OPTS read by python-build and node-build
+// unless the caller sets MAKE_OPTS itself.
+func BuildConcurrencyEnv(jobs int) map[string]string {
+	env := map[string]string{"MAKEFLAGS": "-j" + strconv.Itoa(jobs)}
+
+	if os.Getenv("MAKE_OPTS") == "" {
+		env["MAKE_OPTS"] = "-j " + strconv.Itoa(jobs)
+	}
+
+	return env
+}
@@ -0,0 +1,71 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestBuildConcurrency verifies ASDF_CONCURRENCY parsing, its fallback and its cap.
+func TestBuildConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency string
+		maximum     string
+		expected    int
+	}{
+		{name: "defaults to the number of CPUs", expected: runtime.NumCPU()},
+		{name: "auto", concurrency: "auto", expected: runtime.NumCPU()},
+		{name: "explicit", concurrency: " 3 ", expected: 3},
+		{name: "invalid falls back", concurrency: "many", expected: runtime.NumCPU()},
+		{name: "zero falls back", concurrency: "0", expected: runtime.NumCPU()},
+		{name: "capped", concurrency: "16", maximum: "4", expected: 4},
+		{name: "below the cap", concurrency: "2", maximum: "4", expected: 2},
+		{name: "invalid cap ignored", concurrency: "16", maximum: "-1", expected: 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(asdf.ConcurrencyEnv, tt.concurrency)
+			t.Setenv(asdf.MaxConcurrencyEnv, tt.maximum)
+
+			require.Equal(t, tt.expected, asdf.BuildConcurrency())
+		})
+	}
+}
+
+// TestBuildConcurrencyFromContext verifies the context value and its fallback.
+func TestBuildConcurrencyFromContext(t *testing.T) {
+	t.Setenv(asdf.ConcurrencyEnv, "7")
+	t.Setenv(asdf.MaxConcurrencyEnv, "")
+
+	require.Equal(t, 7, asdf.BuildConcurrencyFromContext(t.Context()))
+	require.Equal(t, 2, asdf.BuildConcurrencyFromContext(asdf.WithBuildConcurrency(t.Context(), 2)))
+}
+
+// TestBuildConcurrencyEnv verifies MAKE_OPTS set by the user is left alone.
+func TestBuildConcurrencyEnv(t *testing.T) {
+	t.Setenv("MAKE_OPTS", "")
+	require.Equal(t, map[string]string{"MAKEFLAGS": "-j4", "MAKE_OPTS": "-j 4"}, asdf.BuildConcurrencyEnv(4))
+
+	t.Setenv("MAKE_OPTS", "-j 1")
+	require.Equal(t, map[string]string{"MAKEFLAGS": "-j4"}, asdf.BuildConcurrencyEnv(4))
+}
@@ -0,0 +1,155 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ConfigFileEnv overrides the location of the asdf config file.
+	ConfigFileEnv = "ASDF_CONFIG_FILE"
+	// ConfigFileName is the asdf config file read from the home directory.
+	ConfigFileName = ".asdfrc"
+	// NoHooksEnv disables every configured install hook when set to 1.
+	NoHooksEnv = "ASDF_NO_HOOKS"
+	// LegacyVersionFileKey enables version files of other version managers,
+	// such as .nvmrc, when set to "yes".
+	LegacyVersionFileKey = "legacy_version_file"
+
+	// HookPreInstall runs before a version is installed; a failure aborts the install.
+	HookPreInstall HookEvent = "pre_install"
+	// HookPostInstall runs after a version is installed.
+	HookPostInstall HookEvent = "post_install"
+	// HookPostDownload runs after a version is downloaded.
+	HookPostDownload HookEvent = "post_download"
+)
+
+// errHookFailed is returned when a configured hook command exits with an error.
+var errHookFailed = errors.New("hook failed")
+
+type (
+	// HookEvent is the point of an install at which a hook runs.
+	HookEvent string
+
+	// Config holds the settings of an .asdfrc style config file.
+	Config struct {
+		// Values maps each configured key to its value.
+		Values map[string]string
+	}
+)
+
+// LoadConfig reads the config file named by ASDF_CONFIG_FILE, or ~/.asdfrc.
+// A missing file yields an empty config.
+func LoadConfig() (*Config, error) {
+	path := os.Getenv(ConfigFileEnv)
+	if path == "" {
+		home, err := osUserHomeDir()
+		if err != nil {
+			return &Config{Values: map[string]string{}}, nil //nolint:nilerr // no home directory means no config file
+		}
+
+		path = filepath.Join(home, ConfigFileName)
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{Values: map[string]string{}}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+	defer file.Close()
+
+	config, err := ParseConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// ParseConfig parses "key = value" lines, skipping blank lines, comments and
+// lines without a key.
+func ParseConfig(reader io.Reader) (*Config, error) {
+	config := &Config{Values: map[string]string{}}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if key = strings.TrimSpace(key); !found || key == "" {
+			continue
+		}
+
+		config.Values[key] = strings.TrimSpace(value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LegacyVersionFile reports whether legacy_version_file = yes is configured.
+func (config *Config) LegacyVersionFile() bool {
+	return strings.EqualFold(config.Values[LegacyVersionFileKey], "yes")
+}
+
+// Hook returns the shell command configured as <event>_<tool>, or "".
+func (config *Config) Hook(event HookEvent, tool string) string {
+	return config.Values[string(event)+"_"+tool]
+}
+
+// RunHook runs the <event>_<tool> hook with sh, exporting ASDF_INSTALL_VERSION,
+// ASDF_INSTALL_PATH and ASDF_DOWNLOAD_PATH from the non-empty arguments. It does
+// nothing when no hook is configured or ASDF_NO_HOOKS=1 is set.
+func (config *Config) RunHook(ctx context.Context, event HookEvent, tool, version, downloadPath, installPath string) error {
+	command := config.Hook(event, tool)
+	if command == "" || os.Getenv(NoHooksEnv) == "1" {
+		return nil
+	}
+
+	env := map[string]string{"ASDF_INSTALL_VERSION": version}
+	if downloadPath != "" {
+		env["ASDF_DOWNLOAD_PATH"] = downloadPath
+	}
+
+	if installPath != "" {
+		env["ASDF_INSTALL_PATH"] = installPath
+	}
+
+	Logger().DebugContext(ctx, "running hook", "hook", string(event)+"_"+tool)
+
+	if err := RunCommand(ctx, env, "sh", "-c", command); err != nil {
+		return fmt.Errorf("%w: %s_%s: %w", errHookFailed, event, tool, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestParseConfig verifies key = value lines are read and comments are skipped.
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := asdf.ParseConfig(strings.NewReader(`# hooks
+legacy_version_file = yes
+
+pre_install_nodejs = ./compliance.sh --strict
+= orphan
+not a setting
+`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"legacy_version_file": "yes",
+		"pre_install_nodejs":  "./compliance.sh --strict",
+	}, config.Values)
+	require.Equal(t, "./compliance.sh --strict", config.Hook(asdf.HookPreInstall, "nodejs"))
+	require.Empty(t, config.Hook(asdf.HookPostInstall, "nodejs"))
+}
+
+// TestLoadConfig verifies the config file location and that a missing file is empty.
+func TestLoadConfig(t *testing.T) {
+	home := t.TempDir()
+	asdf.MockOSForTests(t, "", home)
+
+	t.Setenv(asdf.ConfigFileEnv, "")
+
+	config, err := asdf.LoadConfig()
+	require.NoError(t, err)
+	require.Empty(t, config.Values)
+
+	require.NoError(t, os.WriteFile(filepath.Join(home, asdf.ConfigFileName), []byte("post_install_jq = echo home\n"), 0o600))
+
+	config, err = asdf.LoadConfig()
+	require.NoError(t, err)
+	require.Equal(t, "echo home", config.Hook(asdf.HookPostInstall, "jq"))
+
+	override := filepath.Join(t.TempDir(), "asdfrc")
+	require.NoError(t, os.WriteFile(override, []byte("post_install_jq = echo override\n"), 0o600))
+	t.Setenv(asdf.ConfigFileEnv, override)
+
+	config, err = asdf.LoadConfig()
+	require.NoError(t, err)
+	require.Equal(t, "echo override", config.Hook(asdf.HookPostInstall, "jq"))
+}
+
+// TestConfigRunHook verifies hooks run with sh and the install variables exported.
+func TestConfigRunHook(t *testing.T) {
+	config := &asdf.Config{Values: map[string]string{"pre_install_jq": "./compliance.sh jq"}}
+
+	t.Run("runs configured hook", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "hook.log")
+		t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+		t.Setenv("ASDF_MOCK_COMMAND_LOG_ENV", "ASDF_INSTALL_VERSION ASDF_DOWNLOAD_PATH ASDF_INSTALL_PATH")
+		t.Setenv("ASDF_INSTALL_PATH", "")
+		t.Setenv(asdf.NoHooksEnv, "")
+
+		asdf.MockExecForTests(t, nil)
+
+		require.NoError(t, config.RunHook(t.Context(), asdf.HookPreInstall, "jq", "1.7.1", "/downloads/jq", "/installs/jq"))
+
+		logged, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		require.Equal(t, "sh -c ./compliance.sh jq\n"+
+			"ASDF_INSTALL_VERSION=1.7.1\nASDF_DOWNLOAD_PATH=/downloads/jq\nASDF_INSTALL_PATH=/installs/jq\n", string(logged))
+	})
+
+	t.Run("skips unconfigured and disabled hooks", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "hook.log")
+		t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+
+		asdf.MockExecForTests(t, nil)
+
+		t.Setenv(asdf.NoHooksEnv, "")
+		require.NoError(t, config.RunHook(t.Context(), asdf.HookPostInstall, "jq", "1.7.1", "", ""))
+
+		t.Setenv(asdf.NoHooksEnv, "1")
+		require.NoError(t, config.RunHook(t.Context(), asdf.HookPreInstall, "jq", "1.7.1", "", ""))
+
+		require.NoFileExists(t, logFile)
+	})
+
+	t.Run("reports failing hook", func(t *testing.T) {
+		t.Setenv("ASDF_MOCK_COMMAND_STDERR", "not compliant")
+		t.Setenv(asdf.NoHooksEnv, "")
+
+		asdf.MockExecForTests(t, nil)
+
+		err := config.RunHook(t.Context(), asdf.HookPreInstall, "jq", "1.7.1", "", "")
+		require.ErrorIs(t, err, asdf.ErrHookFailedForTests())
+		require.ErrorContains(t, err, "pre_install_jq")
+		require.ErrorContains(t, err, "not compliant")
+	})
+}
@@ -0,0 +1,180 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNoLegacyPin is returned by ParseLegacyFile when a legacy file exists but
+	// pins no version, e.g. a terragrunt.hcl without terragrunt_version_constraint.
+	// Version resolution moves on to the next file, and parse-legacy-file prints nothing.
+	ErrNoLegacyPin = errors.New("no version pinned")
+
+	// errUnsupportedConstraint is returned when a version constraint cannot be parsed.
+	errUnsupportedConstraint = errors.New("unsupported version constraint")
+
+	// errUnsatisfiableConstraint is returned when no released version satisfies
+	// a version constraint.
+	errUnsatisfiableConstraint = errors.New("no versions satisfying constraint")
+)
+
+// constraintClause matches one clause of a Terraform style version constraint,
+// an optional operator followed by a version of one to three numeric parts.
+var constraintClause = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~>)?\s*v?(\d+(?:\.\d+){0,2})$`)
+
+// VersionConstraint matches versions against a parsed Terraform style version
+// constraint, e.g. ">= 0.50" or "~> 1.5.2, != 1.5.4". A version matches when it
+// satisfies every clause; prereleases never match.
+type VersionConstraint struct {
+	clauses []func(string) bool
+	// exact is the version pinned by an "=" clause naming all three parts.
+	exact string
+}
+
+// ParseVersionConstraint parses a Terraform style version constraint, failing
+// with an error naming the constraint when a clause cannot be parsed.
+func ParseVersionConstraint(constraint string) (*VersionConstraint, error) {
+	parsed := &VersionConstraint{}
+
+	for clause := range strings.SplitSeq(constraint, ",") {
+		match := constraintClause.FindStringSubmatch(strings.TrimSpace(clause))
+		if match == nil {
+			return nil, fmt.Errorf("%w %q", errUnsupportedConstraint, constraint)
+		}
+
+		operator, version := match[1], match[2]
+
+		term, err := constraintTerm(operator, version)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", errUnsupportedConstraint, constraint, err)
+		}
+
+		parsed.clauses = append(parsed.clauses, term)
+
+		if (operator == "" || operator == "=") && strings.Count(version, ".") == 2 {
+			parsed.exact = version
+		}
+	}
+
+	return parsed, nil
+}
+
+// constraintTerm returns the matcher of a single constraint clause.
+func constraintTerm(operator, version string) (func(string) bool, error) {
+	compare := func(accept func(int) bool) func(string) bool {
+		return func(candidate string) bool {
+			return accept(CompareVersions(candidate, version))
+		}
+	}
+
+	switch operator {
+	case "!=":
+		return compare(func(result int) bool { return result != 0 }), nil
+	case ">":
+		return compare(func(result int) bool { return result > 0 }), nil
+	case ">=":
+		return compare(func(result int) bool { return result >= 0 }), nil
+	case "<":
+		return compare(func(result int) bool { return result < 0 }), nil
+	case "<=":
+		return compare(func(result int) bool { return result <= 0 }), nil
+	case "~>":
+		upper, err := pessimisticUpperBound(version)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(candidate string) bool {
+			return CompareVersions(candidate, version) >= 0 && CompareVersions(candidate, upper) < 0
+		}, nil
+	}
+
+	return compare(func(result int) bool { return result == 0 }), nil
+}
+
+// pessimisticUpperBound returns the exclusive upper bound of "~> version",
+// which allows only the rightmost part of version to increase: "~> 1.5.2"
+// stops before 1.6 and "~> 1.5" before 2.
+func pessimisticUpperBound(version string) (string, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+	}
+
+	last, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return "", err
+	}
+
+	parts[len(parts)-1] = strconv.Itoa(last + 1)
+
+	return strings.Join(parts, "."), nil
+}
+
+// Match reports whether version is a release satisfying every clause of the
+// constraint.
+func (constraint *VersionConstraint) Match(version string) bool {
+	if !IsStableVersion(version) {
+		return false
+	}
+
+	for _, clause := range constraint.clauses {
+		if !clause(version) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ResolveConstraint resolves a Terraform style version constraint to the
+// newest version of plugin satisfying it, selected from the recorded version
+// catalog when offline. A constraint pinning an exact version resolves to it
+// without listing versions, provided it satisfies the other clauses.
+func ResolveConstraint(ctx context.Context, plugin Plugin, constraint string) (string, error) {
+	parsed, err := ParseVersionConstraint(constraint)
+	if err != nil {
+		return "", err
+	}
+
+	if parsed.exact != "" {
+		if !parsed.Match(parsed.exact) {
+			return "", fmt.Errorf("%w %q", errUnsatisfiableConstraint, constraint)
+		}
+
+		return parsed.exact, nil
+	}
+
+	versions, err := ListAllVersions(ctx, plugin)
+	if err != nil {
+		return "", err
+	}
+
+	matching := FilterVersions(versions, parsed.Match)
+	if len(matching) == 0 {
+		return "", fmt.Errorf("%w %q for %s", errUnsatisfiableConstraint, constraint, plugin.Name())
+	}
+
+	return slices.MaxFunc(matching, CompareVersions), nil
+}
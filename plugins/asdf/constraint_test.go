@@ -0,0 +1,106 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestVersionConstraintMatch verifies every clause of a version constraint,
+// upper bounds and exclusions included, is evaluated.
+func TestVersionConstraintMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		constraint string
+		version    string
+		match      bool
+	}{
+		{constraint: "0.53.0", version: "0.53.0", match: true},
+		{constraint: "= 0.53.0", version: "0.53.1"},
+		{constraint: "v0.53.0", version: "v0.53.0", match: true},
+		{constraint: ">= 0.50", version: "0.50.0", match: true},
+		{constraint: "> 0.50.1", version: "0.50.1"},
+		{constraint: "< 0.60", version: "0.59.9", match: true},
+		{constraint: "<= 0.60", version: "0.60.1"},
+		{constraint: "~> 0.53", version: "0.99.0", match: true},
+		{constraint: "~> 0.53", version: "1.0.0"},
+		{constraint: "~> 0.53.0", version: "0.53.9", match: true},
+		{constraint: "~> 0.53.0", version: "0.54.0"},
+		{constraint: "~> 1", version: "2.0.0"},
+		{constraint: ">= 0.67.0, < 0.68.0, != 0.67.3", version: "0.67.2", match: true},
+		{constraint: ">= 0.67.0, < 0.68.0, != 0.67.3", version: "0.67.3"},
+		{constraint: ">= 0.67.0, < 0.68.0, != 0.67.3", version: "0.68.0"},
+		{constraint: ">= 0.67.0", version: "0.68.0-rc.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			t.Parallel()
+
+			constraint, err := asdf.ParseVersionConstraint(tt.constraint)
+			require.NoError(t, err)
+			require.Equal(t, tt.match, constraint.Match(tt.version))
+		})
+	}
+
+	for _, constraint := range []string{"", "^1.2.0", ">= 1.2.0 || < 1.0"} {
+		_, err := asdf.ParseVersionConstraint(constraint)
+		require.ErrorIs(t, err, asdf.ErrUnsupportedConstraintForTests())
+	}
+}
+
+// TestResolveConstraint verifies constraints resolve to the newest version
+// satisfying every clause, and fail when no version does.
+func TestResolveConstraint(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+	t.Setenv(asdf.OfflineEnv, "1")
+
+	plugin := &mockPlugin{}
+	require.NoError(t, asdf.RecordVersionCatalog(plugin.Name(), []string{
+		"0.53.0", "0.53.4", "0.67.2", "0.67.3", "0.68.0-rc.1", "0.68.0", "1.0.0",
+	}))
+
+	tests := []struct {
+		constraint string
+		expected   string
+	}{
+		{constraint: "0.53.0", expected: "0.53.0"},
+		{constraint: "= 0.42.0", expected: "0.42.0"},
+		{constraint: ">= 0.50", expected: "1.0.0"},
+		{constraint: "~> 0.53.0", expected: "0.53.4"},
+		{constraint: "~> 0.53.0, != 0.53.4", expected: "0.53.0"},
+		{constraint: "~> 0.53", expected: "0.68.0"},
+		{constraint: ">= 0.67.0, < 0.68.0, != 0.67.3", expected: "0.67.2"},
+		{constraint: "< 0.60", expected: "0.53.4"},
+	}
+
+	for _, tt := range tests {
+		version, err := asdf.ResolveConstraint(t.Context(), plugin, tt.constraint)
+		require.NoError(t, err, tt.constraint)
+		require.Equal(t, tt.expected, version, tt.constraint)
+	}
+
+	_, err := asdf.ResolveConstraint(t.Context(), plugin, ">= 1.1")
+	require.ErrorIs(t, err, asdf.ErrUnsatisfiableConstraintForTests())
+
+	_, err = asdf.ResolveConstraint(t.Context(), plugin, "= 0.53.0, != 0.53.0")
+	require.ErrorIs(t, err, asdf.ErrUnsatisfiableConstraintForTests())
+}
@@ -22,6 +22,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 var (
@@ -36,7 +37,7 @@ var (
 )
 
 // InstallWithDependencies installs a tool and its dependencies.
-// It resolves ASDF_DATA_DIR, determines the latest stable version,
+// It resolves the data layout (see CurrentLayout), determines the latest stable version,
 // creates necessary directories, and calls the plugin's Install method.
 //
 // If the plugin implements PluginWithDependencies, it will automatically
@@ -44,34 +45,35 @@ var (
 // from the project configuration) and installed before proceeding with the
 // plugin installation.
 func InstallWithDependencies(ctx context.Context, pluginName string, plugin Plugin) error {
+	release, err := EnterInstall(pluginName)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// Handle dependencies if the plugin declares them
 	if depsPlugin, ok := plugin.(PluginWithDependencies); ok {
 		dependencies := depsPlugin.Dependencies()
 		if len(dependencies) > 0 {
-			err := installDependencies(ctx, dependencies...)
+			err = installDependencies(ctx, dependencies...)
 			if err != nil {
 				return fmt.Errorf("installing dependencies for %s: %w", pluginName, err)
 			}
 		}
 	}
 
-	dataDir := os.Getenv("ASDF_DATA_DIR")
-	if dataDir == "" {
-		home, err := osUserHomeDir()
-		if err != nil {
-			return fmt.Errorf("determining home directory for ASDF_DATA_DIR fallback: %w", err)
-		}
-
-		dataDir = filepath.Join(home, ".asdf")
+	layout, err := CurrentLayout()
+	if err != nil {
+		return err
 	}
 
-	version, err := plugin.LatestStable(ctx, "")
+	version, err := LatestStableVersion(ctx, plugin, "")
 	if err != nil || version == "" {
 		return fmt.Errorf("determining latest version for %s: %w", pluginName, err)
 	}
 
-	installPath := filepath.Join(dataDir, "installs", pluginName, version)
-	downloadPath := filepath.Join(dataDir, "downloads", pluginName, version)
+	installPath := layout.InstallPath(pluginName, version)
+	downloadPath := layout.DownloadPath(pluginName, version)
 
 	if err := os.MkdirAll(downloadPath, CommonDirectoryPermission); err != nil {
 		return fmt.Errorf("creating download directory for %s: %w", pluginName, err)
@@ -81,10 +83,20 @@ func InstallWithDependencies(ctx context.Context, pluginName string, plugin Plug
 		return fmt.Errorf("creating install directory for %s: %w", pluginName, err)
 	}
 
+	started := time.Now()
+
 	if err := plugin.Install(ctx, version, downloadPath, installPath); err != nil {
 		return fmt.Errorf("installing %s %s: %w", pluginName, version, err)
 	}
 
+	if err := NormalizeInstall(installPath, started); err != nil {
+		return fmt.Errorf("normalizing %s %s: %w", pluginName, version, err)
+	}
+
+	if err := ShareTree(installPath); err != nil {
+		return fmt.Errorf("sharing %s %s with the group: %w", pluginName, version, err)
+	}
+
 	return nil
 }
 
@@ -101,6 +113,8 @@ func installDependencies(ctx context.Context, tools ...string) error {
 		return err
 	}
 
+	managedTools := make([]Toolchain, 0, len(tools))
+
 	for _, tool := range tools {
 		version := resolveVersionFromProjectToolVersions(tool)
 
@@ -108,24 +122,30 @@ func installDependencies(ctx context.Context, tools ...string) error {
 		if err != nil {
 			return err
 		}
-	}
 
-	var asdfPath string
-	if path, err := execLookPath("asdf"); err == nil {
-		asdfPath = path
-	} else {
-		return nil
+		// A "system" pin means the host toolchain is used, so there is nothing to install.
+		if version == SystemVersion {
+			Logger().DebugContext(ctx, "using system toolchain", "tool", tool)
+
+			continue
+		}
+
+		if err := CheckInstallRecursion(tool); err != nil {
+			return err
+		}
+
+		managedTools = append(managedTools, Toolchain{Tool: tool, Version: version})
 	}
 
-	for _, tool := range tools {
-		cmd := execCommandContext(ctx, asdfPath, "install", tool)
+	if _, err := execLookPath("asdf"); err != nil {
+		Logger().DebugContext(ctx, "asdf not found, skipping toolchain bootstrap", "tools", managedTools)
 
-		cmd.Stdout = os.Stderr
-		cmd.Stderr = os.Stderr
+		return nil
+	}
 
-		err := cmd.Run()
-		if err != nil {
-			return fmt.Errorf("running asdf install %s: %w", tool, err)
+	for _, toolchain := range managedTools {
+		if err := toolchains.Ensure(ctx, toolchain); err != nil {
+			return err
 		}
 	}
 
@@ -150,6 +170,8 @@ func EnsureToolVersionsFile(ctx context.Context, path string, tools ...string) e
 		}
 	}
 
+	managedTools := make([]Toolchain, 0, len(tools))
+
 	for _, tool := range tools {
 		version := resolveVersionFromProjectToolVersions(tool)
 
@@ -157,40 +179,39 @@ func EnsureToolVersionsFile(ctx context.Context, path string, tools ...string) e
 		if err != nil {
 			return err
 		}
+
+		if version == SystemVersion {
+			Logger().DebugContext(ctx, "using system toolchain", "tool", tool)
+		} else if err := CheckInstallRecursion(tool); err != nil {
+			return err
+		}
+
+		managedTools = append(managedTools, Toolchain{Tool: tool, Version: version, Dir: filepath.Dir(path)})
 	}
 
-	var asdfPath string
-	if path, err := execLookPath("asdf"); err == nil {
-		asdfPath = path
-	} else {
+	if _, err := execLookPath("asdf"); err != nil {
 		return nil
 	}
 
-	dirPath := filepath.Dir(path)
-
-	for _, tool := range tools {
-		cmd := execCommandContext(ctx, asdfPath, "install", tool)
-
-		cmd.Dir = dirPath
-		cmd.Stdout = os.Stderr
-		cmd.Stderr = os.Stderr
-
-		err := cmd.Run()
-		if err != nil {
-			return fmt.Errorf("running asdf install %s in %s: %w", tool, dirPath, err)
+	for _, toolchain := range managedTools {
+		if err := toolchains.Ensure(ctx, toolchain); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-// ResolveToolVersionsPath returns the path to the .tool-versions file to use
-// for installing toolchains. It prefers the current working directory and
-// falls back to $HOME/.tool-versions, creating an empty file there if needed.
+// ResolveToolVersionsPath returns the path to the tool versions file to use
+// for installing toolchains (see ToolVersionsFilename). It prefers the current
+// working directory and falls back to the home directory, creating an empty
+// file there if needed.
 func ResolveToolVersionsPath() (string, error) {
+	name := ToolVersionsFilename()
+
 	cwd, err := osGetwd()
 	if err == nil {
-		p := filepath.Join(cwd, ".tool-versions")
+		p := filepath.Join(cwd, name)
 		if _, statErr := os.Stat(p); statErr == nil {
 			return p, nil
 		}
@@ -198,10 +219,10 @@ func ResolveToolVersionsPath() (string, error) {
 
 	home, err := osUserHomeDir()
 	if err != nil {
-		return "", fmt.Errorf("determining home directory for .tool-versions: %w", err)
+		return "", fmt.Errorf("determining home directory for %s: %w", name, err)
 	}
 
-	path := filepath.Join(home, ".tool-versions")
+	path := filepath.Join(home, name)
 	if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
 		writeErr := os.WriteFile(path, []byte(""), CommonFilePermission)
 		if writeErr != nil {
@@ -213,29 +234,11 @@ func ResolveToolVersionsPath() (string, error) {
 }
 
 // resolveVersionFromProjectToolVersions reads the version for a tool from the
-// project's .tool-versions file. It returns "latest" if the file or tool entry
-// is not found.
+// nearest tool versions file pinning it, expanding variables when enabled. It
+// returns "latest" if no file pins the tool or the pin cannot be expanded.
 func resolveVersionFromProjectToolVersions(tool string) string {
-	toolVersionsPath, err := ResolveToolVersionsPath()
-	if err != nil {
-		return "latest"
-	}
-
-	data, err := os.ReadFile(toolVersionsPath)
-	if err != nil {
-		return "latest"
-	}
-
-	lines := strings.SplitSeq(string(data), "\n")
-	for line := range lines {
-		if !strings.HasPrefix(line, tool+" ") {
-			continue
-		}
-
-		parts := strings.Fields(line)
-		if len(parts) >= 2 {
-			return parts[1]
-		}
+	if version := pinnedToolVersion(tool); version != "" {
+		return version
 	}
 
 	return "latest"
@@ -249,8 +252,7 @@ func ensureToolVersionLine(path, tool, version string) error {
 		return fmt.Errorf("reading %s: %w", path, err)
 	}
 
-	lines := strings.SplitSeq(string(data), "\n")
-	for line := range lines {
+	for _, line := range VersionFileLines(string(data)) {
 		if strings.HasPrefix(line, tool+" ") {
 			return nil
 		}
@@ -298,7 +300,9 @@ func EnsureGitRepo(ctx context.Context, repoPath, gitURL, installMsg, successMsg
 	} else if err == nil {
 		cmd := exec.CommandContext(ctx, "git", "-C", repoPath, "pull", "--ff-only")
 
-		_ = cmd.Run()
+		if err := cmd.Run(); err != nil {
+			Logger().DebugContext(ctx, "updating git repository failed", "path", repoPath, "error", err)
+		}
 	} else {
 		return fmt.Errorf("checking %s: %w", repoPath, err)
 	}
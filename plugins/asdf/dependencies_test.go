@@ -62,7 +62,7 @@ func TestDependencies(
 			homeDir := filepath.Join(tempDir, "home")
 			require.NoError(t, os.MkdirAll(homeDir, asdf.CommonDirectoryPermission))
 
-			asdf.MockOSForTests(t, "", homeDir)
+			asdf.MockOSForTests(t, tempDir, homeDir)
 
 			toolVersionsPath := filepath.Join(homeDir, ".tool-versions")
 			require.NoError(t, asdf.EnsureToolVersionsFile(t.Context(), toolVersionsPath, "golang"))
@@ -151,6 +151,7 @@ func TestDependencies(
 
 			tempDir := t.TempDir()
 			toolVersionsPath := filepath.Join(tempDir, ".tool-versions")
+			asdf.MockOSForTests(t, tempDir, t.TempDir())
 
 			require.NoError(t, asdf.EnsureToolVersionsFile(t.Context(), toolVersionsPath, "python"))
 
@@ -0,0 +1,158 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// DataDirEnv is the environment variable holding the asdf data directory.
+	DataDirEnv = "ASDF_DATA_DIR"
+	// DirEnv is the environment variable holding the asdf installation, which
+	// doubles as the data directory when ASDF_DATA_DIR is unset.
+	DirEnv = "ASDF_DIR"
+	// DownloadsDirEnv overrides the location of downloaded artifacts.
+	DownloadsDirEnv = "ASDF_DOWNLOADS_DIR"
+	// InstallsDirEnv overrides the location of installed tool versions.
+	InstallsDirEnv = "ASDF_INSTALLS_DIR"
+	// CacheDirEnv overrides the location of cached metadata (version lists, sources, ...).
+	CacheDirEnv = "ASDF_CACHE_DIR"
+)
+
+// DataLayout describes where the plugins read and write their state.
+//
+// Every directory defaults to a child of DataDir, but downloads, installs and
+// cache can be moved independently, e.g. onto BuildKit cache mounts so that
+// image layers only contain the installs.
+type DataLayout struct {
+	// DataDir is the asdf data directory (see DataDir).
+	DataDir string
+	// DownloadsDir holds downloaded artifacts, one directory per tool and version.
+	DownloadsDir string
+	// InstallsDir holds installed tools, one directory per tool and version.
+	InstallsDir string
+	// CacheDir holds cached metadata that can be dropped at any time.
+	CacheDir string
+	// ShimsDir holds the generated shims.
+	ShimsDir string
+	// PluginsDir holds the installed asdf plugin wrappers.
+	PluginsDir string
+}
+
+// DataDir returns the asdf data directory the way asdf resolves it:
+// ASDF_DATA_DIR, then ASDF_DIR, then ~/.asdf. It fails instead of guessing a
+// location when neither variable is set and the home directory is unknown.
+func DataDir() (string, error) {
+	for _, key := range []string{DataDirEnv, DirEnv} {
+		if dir := os.Getenv(key); dir != "" {
+			return dir, nil
+		}
+	}
+
+	home, err := osUserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf(
+			"determining home directory for %s fallback, set %s or %s: %w",
+			DataDirEnv,
+			DataDirEnv,
+			DirEnv,
+			err,
+		)
+	}
+
+	return filepath.Join(home, ".asdf"), nil
+}
+
+// CurrentLayout resolves the data layout from the environment.
+// This is the single place where the data directory and its overrides are read.
+func CurrentLayout() (DataLayout, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return DataLayout{}, err
+	}
+
+	return DataLayout{
+		DataDir:      dataDir,
+		DownloadsDir: dirFromEnv(DownloadsDirEnv, filepath.Join(dataDir, "downloads")),
+		InstallsDir:  dirFromEnv(InstallsDirEnv, filepath.Join(dataDir, "installs")),
+		CacheDir:     dirFromEnv(CacheDirEnv, filepath.Join(dataDir, "cache")),
+		ShimsDir:     filepath.Join(dataDir, "shims"),
+		PluginsDir:   filepath.Join(dataDir, "plugins"),
+	}, nil
+}
+
+// DownloadPath returns the download directory for the given tool version.
+func (layout DataLayout) DownloadPath(toolName, version string) string {
+	return filepath.Join(layout.DownloadsDir, toolName, version)
+}
+
+// InstallPath returns the install directory for the given tool version.
+func (layout DataLayout) InstallPath(toolName, version string) string {
+	return filepath.Join(layout.InstallsDir, toolName, version)
+}
+
+// ToolCacheDir returns the cache directory for the given tool.
+func (layout DataLayout) ToolCacheDir(toolName string) string {
+	return filepath.Join(layout.CacheDir, toolName)
+}
+
+// SourceCacheDir returns the persistent cache directory for the sources of a
+// repository (or tool) version, shared by every build of that version.
+func (layout DataLayout) SourceCacheDir(key, version string) string {
+	return filepath.Join(layout.CacheDir, "sources", key, version)
+}
+
+// HistoryFile returns the path of the install history log.
+func (layout DataLayout) HistoryFile() string {
+	return filepath.Join(layout.DataDir, HistoryFileName)
+}
+
+// LocksDir returns the directory holding the lock files listed by `locks list`.
+func (layout DataLayout) LocksDir() string {
+	return filepath.Join(layout.DataDir, "locks")
+}
+
+// VersionCatalogFile returns the file recording the versions listed for the
+// given tool, used to resolve versions in offline mode.
+func (layout DataLayout) VersionCatalogFile(toolName string) string {
+	return filepath.Join(layout.DataDir, "catalog", toolName+versionCatalogSuffix)
+}
+
+// dirFromEnv returns the value of the given environment variable or the fallback when unset.
+func dirFromEnv(key, fallback string) string {
+	if dir := os.Getenv(key); dir != "" {
+		return dir
+	}
+
+	return fallback
+}
+
+// DockerfileHelp documents the recommended container layering for downloads and installs.
+const DockerfileHelp = `Container builds:
+  Downloads, installs and cache locations can be moved independently of
+  ASDF_DATA_DIR so that downloads live on a BuildKit cache mount and only
+  installs end up in the image layer:
+
+    ENV ASDF_DATA_DIR=/opt/asdf \
+        ASDF_DOWNLOADS_DIR=/var/cache/asdf/downloads \
+        ASDF_CACHE_DIR=/var/cache/asdf/cache
+    RUN --mount=type=cache,target=/var/cache/asdf \
+        asdf install
+
+  ASDF_INSTALLS_DIR can be set as well to place installs outside ASDF_DATA_DIR.`
@@ -0,0 +1,211 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// writingPlugin is a mock plugin whose Install writes into the download and install paths.
+type writingPlugin struct {
+	mockPlugin
+}
+
+func (*writingPlugin) Install(_ context.Context, _, downloadPath, installPath string) error {
+	if err := os.WriteFile(filepath.Join(downloadPath, "artifact.tar.gz"), []byte("archive"), asdf.CommonFilePermission); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(installPath, "bin"), asdf.CommonDirectoryPermission); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(installPath, "bin", "tool"), []byte("#!/bin/sh\n"), asdf.CommonExecutablePermission)
+}
+
+// listFiles returns all regular files below root, relative to root.
+func listFiles(t *testing.T, root string) []string {
+	t.Helper()
+
+	var files []string
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+
+			files = append(files, rel)
+		}
+
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	require.NoError(t, err)
+
+	return files
+}
+
+func TestCurrentLayout(t *testing.T) {
+	t.Run("defaults to children of ASDF_DATA_DIR", func(t *testing.T) {
+		dataDir := t.TempDir()
+		t.Setenv("ASDF_DATA_DIR", dataDir)
+		t.Setenv("ASDF_DOWNLOADS_DIR", "")
+		t.Setenv("ASDF_INSTALLS_DIR", "")
+		t.Setenv("ASDF_CACHE_DIR", "")
+
+		layout, err := asdf.CurrentLayout()
+		require.NoError(t, err)
+		require.Equal(t, dataDir, layout.DataDir)
+		require.Equal(t, filepath.Join(dataDir, "downloads"), layout.DownloadsDir)
+		require.Equal(t, filepath.Join(dataDir, "installs"), layout.InstallsDir)
+		require.Equal(t, filepath.Join(dataDir, "cache"), layout.CacheDir)
+		require.Equal(t, filepath.Join(dataDir, "shims"), layout.ShimsDir)
+		require.Equal(t, filepath.Join(dataDir, "plugins"), layout.PluginsDir)
+		require.Equal(t, filepath.Join(dataDir, "installs", "jq", "1.7"), layout.InstallPath("jq", "1.7"))
+		require.Equal(t, filepath.Join(dataDir, "downloads", "jq", "1.7"), layout.DownloadPath("jq", "1.7"))
+		require.Equal(t, filepath.Join(dataDir, "cache", "jq"), layout.ToolCacheDir("jq"))
+	})
+
+	t.Run("ASDF_DATA_DIR takes precedence over ASDF_DIR", func(t *testing.T) {
+		dataDir := t.TempDir()
+		t.Setenv("ASDF_DATA_DIR", dataDir)
+		t.Setenv("ASDF_DIR", t.TempDir())
+
+		layout, err := asdf.CurrentLayout()
+		require.NoError(t, err)
+		require.Equal(t, dataDir, layout.DataDir)
+	})
+
+	t.Run("falls back to ASDF_DIR", func(t *testing.T) {
+		asdfDir := t.TempDir()
+		t.Setenv("ASDF_DATA_DIR", "")
+		t.Setenv("ASDF_DIR", asdfDir)
+		t.Setenv("ASDF_INSTALLS_DIR", "")
+
+		restore := asdf.SetOSUserHomeDirForTests(
+			func() (string, error) { return "", errTestHomeError },
+		)
+		defer restore()
+
+		layout, err := asdf.CurrentLayout()
+		require.NoError(t, err)
+		require.Equal(t, asdfDir, layout.DataDir)
+		require.Equal(t, filepath.Join(asdfDir, "installs"), layout.InstallsDir)
+	})
+
+	t.Run("falls back to HOME/.asdf", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("ASDF_DATA_DIR", "")
+		t.Setenv("ASDF_DIR", "")
+
+		layout, err := asdf.CurrentLayout()
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(home, ".asdf"), layout.DataDir)
+	})
+
+	t.Run("returns an error when HOME cannot be determined", func(t *testing.T) {
+		t.Setenv("ASDF_DATA_DIR", "")
+		t.Setenv("ASDF_DIR", "")
+
+		restore := asdf.SetOSUserHomeDirForTests(
+			func() (string, error) { return "", errTestHomeError },
+		)
+		defer restore()
+
+		_, err := asdf.CurrentLayout()
+		require.ErrorIs(t, err, errTestHomeError)
+		require.ErrorContains(t, err, "set ASDF_DATA_DIR or ASDF_DIR")
+
+		_, err = asdf.GetPluginsDir()
+		require.ErrorIs(t, err, errTestHomeError)
+
+		_, err = asdf.NewPluginInstaller(os.Args[0], "")
+		require.ErrorIs(t, err, errTestHomeError)
+	})
+
+	t.Run("overrides are independent", func(t *testing.T) {
+		dataDir := t.TempDir()
+		downloadsDir := t.TempDir()
+		cacheDir := t.TempDir()
+
+		t.Setenv("ASDF_DATA_DIR", dataDir)
+		t.Setenv("ASDF_DOWNLOADS_DIR", downloadsDir)
+		t.Setenv("ASDF_INSTALLS_DIR", "")
+		t.Setenv("ASDF_CACHE_DIR", cacheDir)
+
+		layout, err := asdf.CurrentLayout()
+		require.NoError(t, err)
+		require.Equal(t, downloadsDir, layout.DownloadsDir)
+		require.Equal(t, filepath.Join(dataDir, "installs"), layout.InstallsDir)
+		require.Equal(t, cacheDir, layout.CacheDir)
+		pluginsDir, err := asdf.GetPluginsDir()
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dataDir, "plugins"), pluginsDir)
+	})
+}
+
+func TestInstallWithDependenciesRespectsLayout(t *testing.T) {
+	dataDir := t.TempDir()
+	downloadsDir := t.TempDir()
+	installsDir := t.TempDir()
+	cacheDir := t.TempDir()
+
+	t.Setenv("ASDF_DATA_DIR", dataDir)
+	t.Setenv("ASDF_DOWNLOADS_DIR", downloadsDir)
+	t.Setenv("ASDF_INSTALLS_DIR", installsDir)
+	t.Setenv("ASDF_CACHE_DIR", cacheDir)
+
+	plugin := &writingPlugin{mockPlugin: mockPlugin{latestVersion: "1.2.3"}}
+
+	require.NoError(t, asdf.InstallWithDependencies(t.Context(), "test-tool", plugin))
+
+	require.Empty(t, listFiles(t, dataDir))
+	require.Empty(t, listFiles(t, cacheDir))
+	require.Equal(t,
+		[]string{filepath.Join("test-tool", "1.2.3", "artifact.tar.gz")},
+		listFiles(t, downloadsDir),
+	)
+	require.Equal(t,
+		[]string{filepath.Join("test-tool", "1.2.3", "bin", "tool")},
+		listFiles(t, installsDir),
+	)
+
+	entries, err := os.ReadDir(dataDir)
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		require.False(t, strings.HasPrefix(entry.Name(), "downloads"), "unexpected %s", entry.Name())
+		require.False(t, strings.HasPrefix(entry.Name(), "installs"), "unexpected %s", entry.Name())
+	}
+}
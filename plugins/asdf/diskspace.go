@@ -0,0 +1,162 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+const (
+	// SkipDiskSpaceCheckEnv skips CheckDiskSpace when set to 1, e.g. when
+	// the filesystem reports less free space than it can actually hold.
+	SkipDiskSpaceCheckEnv = "ASDF_SKIP_DISK_SPACE_CHECK"
+
+	// DefaultExpansionFactor is how many times the size of its downloads an
+	// install is assumed to take, unless the plugin is an ExpansionHinter.
+	DefaultExpansionFactor = 4.0
+)
+
+// errInsufficientDiskSpace is returned when a download or install would not fit on its filesystem.
+var errInsufficientDiskSpace = errors.New("not enough disk space")
+
+// ExpansionFactorOf returns the expansion factor of the installs of plugin,
+// DefaultExpansionFactor unless it is an ExpansionHinter with a positive hint.
+func ExpansionFactorOf(plugin Plugin) float64 {
+	if hinter, ok := plugin.(ExpansionHinter); ok && hinter.ExpansionFactor() > 0 {
+		return hinter.ExpansionFactor()
+	}
+
+	return DefaultExpansionFactor
+}
+
+// CheckDiskSpace fails early, before anything is written, when the artifacts
+// of version missing from downloadPath would not fit on its filesystem or,
+// with installPath, the install would not fit on the filesystem of
+// installPath. The install is assumed to take ExpansionFactorOf times the
+// size of the artifacts, which is taken from the Content-Length of HEAD
+// requests. The check is skipped, and nil returned, when the plugin does not
+// resolve its artifacts or their sizes or the free space are unknown.
+func CheckDiskSpace(ctx context.Context, plugin Plugin, version, downloadPath, installPath string) error {
+	resolver, ok := plugin.(PluginWithArtifactResolver)
+	if !ok || os.Getenv(SkipDiskSpaceCheckEnv) == "1" {
+		return nil
+	}
+
+	if _, isRef := ParseRefVersion(version); isRef {
+		return nil
+	}
+
+	artifacts, err := resolver.ResolveArtifacts(ctx, version)
+	if err != nil {
+		Logger().DebugContext(ctx, "skipping disk space check", "tool", plugin.Name(), "error", err)
+
+		return nil
+	}
+
+	var total, pending int64
+
+	for _, artifact := range artifacts {
+		size, known := contentLength(ctx, artifact.URL)
+		if !known {
+			Logger().DebugContext(ctx, "skipping disk space check, download size unknown",
+				"tool", plugin.Name(), "url", artifact.URL)
+
+			return nil
+		}
+
+		total += size
+
+		if _, err := os.Stat(filepath.Join(downloadPath, artifact.Name)); err != nil {
+			pending += size
+		}
+	}
+
+	if err := requireDiskSpace(plugin.Name(), version, downloadPath, pending); err != nil {
+		return err
+	}
+
+	if installPath == "" {
+		return nil
+	}
+
+	return requireDiskSpace(plugin.Name(), version, installPath, int64(float64(total)*ExpansionFactorOf(plugin)))
+}
+
+// requireDiskSpace returns errInsufficientDiskSpace when the filesystem of
+// path has less than required bytes available.
+func requireDiskSpace(tool, version, path string, required int64) error {
+	if required <= 0 {
+		return nil
+	}
+
+	available, known := availableDiskSpace(existingAncestor(path))
+	if !known || available >= uint64(required) {
+		return nil
+	}
+
+	return fmt.Errorf("%w for %s %s in %s: %d bytes (%s) required, %d bytes (%s) available (set %s=1 to skip)",
+		errInsufficientDiskSpace, tool, version, path, required, FormatBytes(required),
+		available, FormatBytes(int64(available)), SkipDiskSpaceCheckEnv) //nolint:gosec // free space fits in int64
+}
+
+// contentLength returns the Content-Length url answers a HEAD request with,
+// following redirects, and whether it is known.
+func contentLength(ctx context.Context, url string) (int64, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, http.NoBody)
+	if err != nil {
+		return 0, false
+	}
+
+	resp, err := HTTPClient().Do(req)
+	if err != nil {
+		return 0, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.ContentLength <= 0 {
+		return 0, false
+	}
+
+	return resp.ContentLength, true
+}
+
+// existingAncestor returns path or its closest existing parent directory.
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+
+		path = parent
+	}
+}
+
+// IsNoSpace reports whether err is caused by a full filesystem, after which
+// a partial download or install is removed.
+func IsNoSpace(err error) bool {
+	return errors.Is(err, syscall.ENOSPC)
+}
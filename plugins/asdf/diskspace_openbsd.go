@@ -0,0 +1,30 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build openbsd
+
+package asdf
+
+import "golang.org/x/sys/unix"
+
+// availableDiskSpace returns the bytes available to unprivileged users on the
+// filesystem of path, and whether they could be determined.
+func availableDiskSpace(path string) (uint64, bool) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil || stat.F_bavail < 0 {
+		return 0, false
+	}
+
+	return uint64(stat.F_bavail) * uint64(stat.F_bsize), true
+}
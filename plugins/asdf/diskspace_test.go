@@ -0,0 +1,121 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestCheckDiskSpace verifies downloads and installs that cannot fit are
+// refused before anything is written, and unknown sizes are let through.
+func TestCheckDiskSpace(t *testing.T) {
+	const huge = int64(1) << 58
+
+	sizes := map[string]int64{"/small.tar.gz": 1024, "/huge.tar.gz": huge}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		size, ok := sizes[request.URL.Path]
+		if !ok {
+			http.NotFound(writer, request)
+
+			return
+		}
+
+		writer.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}))
+	t.Cleanup(server.Close)
+
+	newPlugin := func(fileName string) *asdf.BinaryPlugin {
+		return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+			Name:                "tool",
+			BinaryName:          "tool",
+			FileNameTemplate:    fileName,
+			DownloadURLTemplate: server.URL + "/{{.FileName}}",
+		})
+	}
+
+	t.Run("fits", func(t *testing.T) {
+		require.NoError(t, asdf.CheckDiskSpace(t.Context(), newPlugin("small.tar.gz"), "1.0.0", t.TempDir(), t.TempDir()))
+	})
+
+	t.Run("download does not fit", func(t *testing.T) {
+		downloadPath := filepath.Join(t.TempDir(), "downloads", "tool", "1.0.0")
+
+		err := asdf.CheckDiskSpace(t.Context(), newPlugin("huge.tar.gz"), "1.0.0", downloadPath, "")
+		require.ErrorIs(t, err, asdf.ErrInsufficientDiskSpaceForTests())
+		require.ErrorContains(t, err, "for tool 1.0.0 in "+downloadPath+": "+strconv.FormatInt(huge, 10)+" bytes (256.0 PiB) required")
+		require.ErrorContains(t, err, "available (set ASDF_SKIP_DISK_SPACE_CHECK=1 to skip)")
+		require.NoDirExists(t, downloadPath)
+	})
+
+	t.Run("install does not fit", func(t *testing.T) {
+		downloadPath, installPath := t.TempDir(), t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(downloadPath, "huge.tar.gz"), nil, asdf.CommonFilePermission))
+
+		plugin := newPlugin("huge.tar.gz")
+		require.NoError(t, asdf.CheckDiskSpace(t.Context(), plugin, "1.0.0", downloadPath, ""))
+
+		err := asdf.CheckDiskSpace(t.Context(), plugin, "1.0.0", downloadPath, installPath)
+		require.ErrorIs(t, err, asdf.ErrInsufficientDiskSpaceForTests())
+		require.ErrorContains(t, err, "in "+installPath+": "+strconv.FormatInt(4*huge, 10)+" bytes (1.0 EiB) required")
+	})
+
+	t.Run("unknown size", func(t *testing.T) {
+		require.NoError(t, asdf.CheckDiskSpace(t.Context(), newPlugin("missing.tar.gz"), "1.0.0", t.TempDir(), t.TempDir()))
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		t.Setenv(asdf.SkipDiskSpaceCheckEnv, "1")
+
+		require.NoError(t, asdf.CheckDiskSpace(t.Context(), newPlugin("huge.tar.gz"), "1.0.0", t.TempDir(), t.TempDir()))
+	})
+}
+
+// TestExpansionFactorOf verifies the expansion hint of a plugin config
+// replaces the default.
+func TestExpansionFactorOf(t *testing.T) {
+	t.Parallel()
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{Name: "tool"})
+	require.InDelta(t, asdf.DefaultExpansionFactor, asdf.ExpansionFactorOf(plugin), 0)
+
+	plugin.Config.ExpansionFactor = 1.5
+	require.InDelta(t, 1.5, asdf.ExpansionFactorOf(plugin), 0)
+
+	source := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{Name: "tool", ExpansionFactor: 12})
+	require.InDelta(t, 12, asdf.ExpansionFactorOf(source), 0)
+}
+
+// TestIsNoSpace verifies wrapped ENOSPC errors are recognized.
+func TestIsNoSpace(t *testing.T) {
+	t.Parallel()
+
+	err := fmt.Errorf("extracting archive: %w", &os.PathError{Op: "write", Path: "/data/bin/tool", Err: syscall.ENOSPC})
+	require.True(t, asdf.IsNoSpace(err))
+	require.False(t, asdf.IsNoSpace(os.ErrPermission))
+	require.False(t, asdf.IsNoSpace(nil))
+}
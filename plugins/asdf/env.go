@@ -0,0 +1,137 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxPathLengthEnv overrides the PATH length above which a warning is logged.
+const MaxPathLengthEnv = "ASDF_MAX_PATH_LENGTH"
+
+// defaultMaxPathLength is the PATH length above which a warning is logged.
+const defaultMaxPathLength = 8192
+
+// listEnvVars are the variables holding os.PathListSeparator separated lists
+// that plugins prepend to rather than replace.
+var listEnvVars = []string{ //nolint:gochecknoglobals // read-only lookup table
+	"PATH",
+	"MANPATH",
+	"LD_LIBRARY_PATH",
+	"DYLD_LIBRARY_PATH",
+	"LIBRARY_PATH",
+	"CPATH",
+	"PKG_CONFIG_PATH",
+	"PYTHONPATH",
+	"NODE_PATH",
+}
+
+// IsListEnvVar reports whether key holds a list of directories.
+func IsListEnvVar(key string) bool {
+	return slices.Contains(listEnvVars, key)
+}
+
+// PrependPathList prepends dirs to the list value, removing any existing
+// occurrence of the same directories first. Composing the result again with the
+// same dirs returns it unchanged, so nested invocations do not grow the list.
+func PrependPathList(value string, dirs ...string) string {
+	added := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if dir != "" && !slices.Contains(added, dir) {
+			added = append(added, dir)
+		}
+	}
+
+	if value == "" {
+		return strings.Join(added, string(os.PathListSeparator))
+	}
+
+	kept := slices.Clone(added)
+	for _, entry := range strings.Split(value, string(os.PathListSeparator)) {
+		if !slices.Contains(added, entry) {
+			kept = append(kept, entry)
+		}
+	}
+
+	return strings.Join(kept, string(os.PathListSeparator))
+}
+
+// PrependEnvList returns a copy of env, in os.Environ form, with dirs prepended
+// to key using PrependPathList. Duplicate entries for key are collapsed into one.
+func PrependEnvList(env []string, key string, dirs ...string) []string {
+	var current string
+
+	result := make([]string, 0, len(env)+1)
+
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		if name == key {
+			current = value
+
+			continue
+		}
+
+		result = append(result, entry)
+	}
+
+	composed := PrependPathList(current, dirs...)
+	if key == "PATH" {
+		checkPathLength(composed)
+	}
+
+	return append(result, key+"="+composed)
+}
+
+// ComposeExecEnv merges a plugin ExecEnv with the current environment. Values of
+// list variables such as PATH are prepended to the current value returned by
+// getenv without duplicating directories; other values are used as they are.
+func ComposeExecEnv(env map[string]string, getenv func(string) string) map[string]string {
+	composed := make(map[string]string, len(env))
+
+	for key, value := range env {
+		if !IsListEnvVar(key) {
+			composed[key] = value
+
+			continue
+		}
+
+		composed[key] = PrependPathList(getenv(key), strings.Split(value, string(os.PathListSeparator))...)
+		if key == "PATH" {
+			checkPathLength(composed[key])
+		}
+	}
+
+	return composed
+}
+
+// checkPathLength logs a warning when path is longer than ASDF_MAX_PATH_LENGTH.
+func checkPathLength(path string) {
+	limit := defaultMaxPathLength
+
+	if value := os.Getenv(MaxPathLengthEnv); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	if len(path) > limit {
+		Logger().Warn("PATH is unusually long, nested shims may be prepending to it",
+			"length", len(path), "limit", limit, "hint", "raise "+MaxPathLengthEnv+" to silence")
+	}
+}
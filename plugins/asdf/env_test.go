@@ -0,0 +1,139 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// pathList joins directories with the platform list separator.
+func pathList(dirs ...string) string {
+	return strings.Join(dirs, string(os.PathListSeparator))
+}
+
+func TestPrependPathList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		dirs     []string
+	}{
+		{
+			name:     "empty value",
+			dirs:     []string{"/opt/node/bin"},
+			expected: "/opt/node/bin",
+		},
+		{
+			name:     "prepends new directories",
+			value:    pathList("/usr/bin", "/bin"),
+			dirs:     []string{"/opt/node/bin", "/opt/go/bin"},
+			expected: pathList("/opt/node/bin", "/opt/go/bin", "/usr/bin", "/bin"),
+		},
+		{
+			name:     "moves existing directories to the front",
+			value:    pathList("/usr/bin", "/opt/go/bin", "/bin", "/opt/go/bin"),
+			dirs:     []string{"/opt/go/bin"},
+			expected: pathList("/opt/go/bin", "/usr/bin", "/bin"),
+		},
+		{
+			name:     "keeps unrelated duplicates and empty entries",
+			value:    pathList("/usr/bin", "", "/usr/bin"),
+			dirs:     []string{"/opt/go/bin", "/opt/go/bin"},
+			expected: pathList("/opt/go/bin", "/usr/bin", "", "/usr/bin"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			once := asdf.PrependPathList(tt.value, tt.dirs...)
+			require.Equal(t, tt.expected, once)
+			require.Equal(t, once, asdf.PrependPathList(once, tt.dirs...))
+		})
+	}
+}
+
+func TestPrependEnvListIsStable(t *testing.T) {
+	t.Parallel()
+
+	env := []string{"HOME=/home/user", "PATH=" + pathList("/usr/bin", "/bin"), "PATH=/ignored"}
+
+	once := asdf.PrependEnvList(env, "PATH", "/opt/node_modules/.bin", "/opt/node/bin")
+	twice := asdf.PrependEnvList(once, "PATH", "/opt/node_modules/.bin", "/opt/node/bin")
+
+	require.Equal(t, once, twice)
+	require.Equal(t, []string{
+		"HOME=/home/user",
+		"PATH=" + pathList("/opt/node_modules/.bin", "/opt/node/bin", "/ignored"),
+	}, once)
+}
+
+func TestComposeExecEnv(t *testing.T) {
+	t.Parallel()
+
+	pluginEnv := map[string]string{
+		"PATH":              pathList("/opt/tool/bin", "/opt/tool/libexec"),
+		"LD_LIBRARY_PATH":   "/opt/tool/lib",
+		"CLOUDSDK_ROOT_DIR": "/opt/tool",
+	}
+
+	current := map[string]string{
+		"PATH":            pathList("/usr/bin", "/bin"),
+		"LD_LIBRARY_PATH": "",
+	}
+
+	once := asdf.ComposeExecEnv(pluginEnv, func(key string) string { return current[key] })
+
+	require.Equal(t, pathList("/opt/tool/bin", "/opt/tool/libexec", "/usr/bin", "/bin"), once["PATH"])
+	require.Equal(t, "/opt/tool/lib", once["LD_LIBRARY_PATH"])
+	require.Equal(t, "/opt/tool", once["CLOUDSDK_ROOT_DIR"])
+
+	// A nested shim sees the composed environment and composes it again.
+	twice := asdf.ComposeExecEnv(pluginEnv, func(key string) string { return once[key] })
+	require.Equal(t, once, twice)
+}
+
+func TestPathLengthWarning(t *testing.T) {
+	asdf.RestoreLoggingForTests(t)
+	t.Setenv(asdf.LogEnv, "")
+	t.Setenv(asdf.MaxPathLengthEnv, "32")
+
+	var out bytes.Buffer
+	require.NoError(t, asdf.ConfigureLogging(&out, false))
+
+	asdf.PrependEnvList([]string{"PATH=/usr/bin"}, "PATH", "/opt/a/bin")
+	require.Empty(t, out.String())
+
+	asdf.PrependEnvList([]string{"PATH=/usr/bin"}, "PATH", "/opt/a-very-long-directory-name/bin")
+	require.Contains(t, out.String(), "PATH is unusually long")
+	require.Contains(t, out.String(), "limit=32")
+
+	out.Reset()
+	asdf.ComposeExecEnv(
+		map[string]string{"PATH": "/opt/a-very-long-directory-name/bin"},
+		func(string) string { return "/usr/bin" },
+	)
+	require.Contains(t, out.String(), "PATH is unusually long")
+}
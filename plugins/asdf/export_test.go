@@ -17,8 +17,14 @@ package asdf
 
 import (
 	"context"
+	"encoding/hex"
 	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func LockTestGlobalsForTests(t *testing.T) {
@@ -94,3 +100,292 @@ func ErrSourceBuildNoVersionsFoundForTests() error {
 func ErrSourceBuildNoVersionsMatchingForTests() error {
 	return errSourceBuildNoVersionsMatching
 }
+
+func NewTerminalProgressForTests(w io.Writer, label string) ProgressReporter {
+	return &terminalProgress{out: w, label: label}
+}
+
+func SetProgressReporterFactoryForTests(t *testing.T, fn func(label string) ProgressReporter) {
+	t.Helper()
+	lockTestGlobals(t)
+
+	orig := newProgressReporter
+	newProgressReporter = fn
+
+	t.Cleanup(func() { newProgressReporter = orig })
+}
+
+func ErrRecursiveInstallForTests() error {
+	return errRecursiveInstall
+}
+
+// RestoreLoggingForTests restores the default logger and level when t finishes.
+func RestoreLoggingForTests(t *testing.T) {
+	t.Helper()
+	lockTestGlobals(t)
+
+	origLogger, origLevel := slog.Default(), logLevel.Level()
+
+	t.Cleanup(func() {
+		slog.SetDefault(origLogger)
+		logLevel.Set(origLevel)
+	})
+}
+
+func ErrInvalidLogLevelForTests() error {
+	return errInvalidLogLevel
+}
+
+func ErrCommandFailedForTests() error {
+	return errCommandFailed
+}
+
+func ErrDefaultPackagesFailedForTests() error {
+	return errDefaultPackagesFailed
+}
+
+func SetMuslLoaderGlobForTests(t *testing.T, pattern string) {
+	t.Helper()
+	lockTestGlobals(t)
+
+	orig := muslLoaderGlob
+	muslLoaderGlob = pattern
+
+	t.Cleanup(func() { muslLoaderGlob = orig })
+}
+
+func ErrSizeMismatchForTests() error {
+	return errSizeMismatch
+}
+
+func SetFileOwnerForTests(t *testing.T, fn func(info os.FileInfo) (uid, gid uint32, ok bool)) {
+	t.Helper()
+	lockTestGlobals(t)
+
+	orig := statOwner
+	statOwner = fn
+
+	t.Cleanup(func() { statOwner = orig })
+}
+
+func ErrProvenanceNotVerifiedForTests() error {
+	return errProvenanceNotVerified
+}
+
+func SetProcessTranslatedForTests(t *testing.T, translated bool) {
+	t.Helper()
+	lockTestGlobals(t)
+
+	orig := processTranslated
+	processTranslated = func() bool { return translated }
+
+	t.Cleanup(func() { processTranslated = orig })
+}
+
+func SetSourceCacheDirForTests(t *testing.T, dir string) {
+	t.Helper()
+	lockTestGlobals(t)
+
+	t.Setenv(DataDirEnv, filepath.Join(dir, "data"))
+
+	orig := sourceCacheDir
+	sourceCacheDir = func(_ *SourceBuildPluginConfig, version string) string {
+		return filepath.Join(dir, version)
+	}
+
+	t.Cleanup(func() { sourceCacheDir = orig })
+}
+
+func SetHistoryRotateSizeForTests(t *testing.T, size int64) {
+	t.Helper()
+	lockTestGlobals(t)
+
+	orig := historyRotateSize
+	historyRotateSize = size
+
+	t.Cleanup(func() { historyRotateSize = orig })
+}
+
+func ErrPatchHunkFailedForTests() error {
+	return errPatchHunkFailed
+}
+
+func ErrPatchMalformedForTests() error {
+	return errPatchMalformed
+}
+
+func ErrGoModNotFoundForTests() error {
+	return errGoModNotFound
+}
+
+func ErrGoModRequireMissingForTests() error {
+	return errGoModRequireMissing
+}
+
+func ErrBinaryNotFoundInArchiveForTests() error {
+	return errBinaryNotFoundInArchive
+}
+
+func ErrArchiveDirNotFoundForTests() error {
+	return errArchiveDirNotFound
+}
+
+func ErrArchiveUnsafeLinkForTests() error {
+	return errArchiveUnsafeLink
+}
+
+func ErrArchiveNoSingleRootForTests() error {
+	return errArchiveNoSingleRoot
+}
+
+func ErrArchiveSizeLimitExceededForTests() error {
+	return errArchiveSizeLimitExceeded
+}
+
+func ErrInvalidArchiveFilePathTarForTests() error {
+	return errInvalidArchiveFilePathTar
+}
+
+func ErrInvalidArchiveFilePathZipForTests() error {
+	return errInvalidArchiveFilePathZip
+}
+
+func ErrCapabilityUnsupportedForTests() error {
+	return errCapabilityUnsupported
+}
+
+func ErrRefInstallUnsupportedForTests() error {
+	return errRefInstallUnsupported
+}
+
+func ErrInstallTypeUnknownForTests() error {
+	return errInstallTypeUnknown
+}
+
+func ErrOfflineDownloadMissingForTests() error {
+	return errOfflineDownloadMissing
+}
+
+func ErrOfflineCatalogMissingForTests() error {
+	return errOfflineCatalogMissing
+}
+
+func ErrOfflineNoVersionMatchingForTests() error {
+	return errOfflineNoVersionMatching
+}
+
+func ErrLockfileSchemaUnsupportedForTests() error {
+	return errLockfileSchemaUnsupported
+}
+
+func ErrLockfileToolMissingForTests() error {
+	return errLockfileToolMissing
+}
+
+func ErrLockfileDriftForTests() error {
+	return errLockfileDrift
+}
+
+func ErrLockfileUnhashedForTests() error {
+	return errLockfileUnhashed
+}
+
+func ErrServerVersionUnavailableForTests() error {
+	return errServerVersionUnavailable
+}
+
+func ErrReleaseNotFoundForTests() error {
+	return errReleaseNotFound
+}
+
+func ErrToolVersionUndefinedVariableForTests() error {
+	return errToolVersionUndefinedVariable
+}
+
+func ErrToolVersionMalformedVariableForTests() error {
+	return errToolVersionMalformedVariable
+}
+
+func ErrHookFailedForTests() error {
+	return errHookFailed
+}
+
+func ErrInvalidVersionQueryForTests() error {
+	return errInvalidVersionQuery
+}
+
+func ErrBuildDependenciesMissingForTests() error {
+	return errBuildDependenciesMissing
+}
+
+func SetOSReleasePathForTests(t *testing.T, path string) {
+	t.Helper()
+	lockTestGlobals(t)
+
+	orig := osReleasePath
+	osReleasePath = path
+
+	t.Cleanup(func() { osReleasePath = orig })
+}
+
+func ErrSystemExecutableNotFoundForTests() error {
+	return errSystemExecutableNotFound
+}
+
+func SetPostInstallCheckRunnerForTests(
+	t *testing.T,
+	fn func(ctx context.Context, name string, args ...string) ([]byte, error),
+) {
+	t.Helper()
+	lockTestGlobals(t)
+
+	orig := runPostInstallCheck
+	runPostInstallCheck = fn
+
+	t.Cleanup(func() { runPostInstallCheck = orig })
+}
+
+func ErrPostInstallCheckFailedForTests() error {
+	return errPostInstallCheckFailed
+}
+
+// ErrInsufficientDiskSpaceForTests exposes errInsufficientDiskSpace for external-package tests.
+func ErrInsufficientDiskSpaceForTests() error {
+	return errInsufficientDiskSpace
+}
+
+func ErrUnsupportedConstraintForTests() error {
+	return errUnsupportedConstraint
+}
+
+func ErrUnsatisfiableConstraintForTests() error {
+	return errUnsatisfiableConstraint
+}
+
+func ErrChecksumNotPublishedForTests() error {
+	return errChecksumNotPublished
+}
+
+func PGPFingerprintsForTests(publicKey []byte) ([]string, error) {
+	keys, err := parsePGPPublicKeys(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints := make([]string, 0, len(keys))
+	for _, key := range keys {
+		fingerprints = append(fingerprints, strings.ToUpper(hex.EncodeToString(key.fingerprint[:])))
+	}
+
+	return fingerprints, nil
+}
+
+func SetPGPTimeForTests(t *testing.T, now time.Time) {
+	t.Helper()
+	lockTestGlobals(t)
+
+	orig := pgpNow
+	pgpNow = func() time.Time { return now }
+
+	t.Cleanup(func() { pgpNow = orig })
+}
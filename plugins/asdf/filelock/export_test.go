@@ -0,0 +1,36 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filelock
+
+import "testing"
+
+func SetProcessAliveForTests(t *testing.T, alive bool) {
+	t.Helper()
+
+	orig := processAlive
+	processAlive = func(int) bool { return alive }
+
+	t.Cleanup(func() { processAlive = orig })
+}
+
+func SetHostnameForTests(t *testing.T, name string) {
+	t.Helper()
+
+	orig := hostname
+	hostname = func() (string, error) { return name, nil }
+
+	t.Cleanup(func() { hostname = orig })
+}
@@ -0,0 +1,329 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filelock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Suffix is the file extension of lock files.
+const Suffix = ".lock"
+
+// defaultPollInterval is how often a held lock is retried.
+const defaultPollInterval = 100 * time.Millisecond
+
+var (
+	// ErrLocked is returned when a lock is held by another process.
+	ErrLocked = errors.New("lock is held by another process")
+	// ErrHolderAlive is returned when breaking a lock whose holder is still running.
+	ErrHolderAlive = errors.New("lock holder is still running")
+
+	// processAlive reports whether pid is a running process on this host.
+	processAlive = isProcessAlive //nolint:gochecknoglobals // used for mocking
+	// hostname returns the name of this host.
+	hostname = os.Hostname //nolint:gochecknoglobals // used for mocking
+)
+
+type (
+	// Lock is an acquired lock; Release it when done.
+	Lock struct {
+		file   *os.File
+		path   string
+		shared bool
+	}
+
+	// Options configures Acquire.
+	Options struct {
+		// Purpose describes why the lock is held, e.g. "install golang 1.25.0".
+		Purpose string
+		// PollInterval is how often a held lock is retried. Defaults to 100ms.
+		PollInterval time.Duration
+		// Shared takes a shared lock, which only excludes exclusive holders.
+		// Shared holders do not record metadata.
+		Shared bool
+	}
+
+	// Holder describes the process holding an exclusive lock.
+	Holder struct {
+		Started  time.Time `json:"started"`
+		Hostname string    `json:"hostname"`
+		Command  string    `json:"command"`
+		Purpose  string    `json:"purpose,omitempty"`
+		PID      int       `json:"pid"`
+	}
+
+	// Status describes a lock file found by List.
+	Status struct {
+		// Holder is the recorded holder, nil when none was recorded.
+		Holder *Holder
+		Path   string
+		// Held reports whether a process currently holds the lock.
+		Held bool
+	}
+
+	// HeldError is returned when a lock could not be acquired before the
+	// context was done. It wraps ErrLocked and the context error.
+	HeldError struct {
+		Holder *Holder
+		Err    error
+		Path   string
+	}
+)
+
+// Error describes who holds the lock.
+func (err *HeldError) Error() string {
+	if err.Holder == nil {
+		return fmt.Sprintf("%s: %s (%v)", ErrLocked, err.Path, err.Err)
+	}
+
+	return fmt.Sprintf("%s: %s held by %s (%v)", ErrLocked, err.Path, err.Holder, err.Err)
+}
+
+// Unwrap returns ErrLocked and the context error.
+func (err *HeldError) Unwrap() []error {
+	return []error{ErrLocked, err.Err}
+}
+
+// String describes the holder for diagnostics.
+func (holder *Holder) String() string {
+	description := fmt.Sprintf("pid %d on %s since %s", holder.PID, holder.Hostname, holder.Started.Format(time.RFC3339))
+	if holder.Purpose != "" {
+		description += " for " + holder.Purpose
+	}
+
+	if holder.Command != "" {
+		description += " (" + holder.Command + ")"
+	}
+
+	return description
+}
+
+// Acquire takes the lock at path, waiting until it is free or ctx is done.
+// Exclusive holders record their PID, host and start time in the lock file.
+func Acquire(ctx context.Context, path string, opts Options) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating lock directory: %w", err)
+	}
+
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	for {
+		lock, err := tryAcquire(path, opts.Shared)
+		if err != nil {
+			return nil, err
+		}
+
+		if lock != nil {
+			if opts.Shared {
+				// Metadata left by an exclusive holder that died without
+				// releasing must not be taken for a holder of this lock.
+				_ = lock.file.Truncate(0)
+			} else {
+				lock.recordHolder(opts.Purpose)
+			}
+
+			return lock, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, &HeldError{Path: path, Holder: ReadHolder(path), Err: ctx.Err()}
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Release clears the holder metadata, unlocks and closes the lock file.
+func (lock *Lock) Release() error {
+	if lock == nil || lock.file == nil {
+		return nil
+	}
+
+	if !lock.shared {
+		_ = lock.file.Truncate(0)
+	}
+
+	err := unlockFile(lock.file)
+	closeErr := lock.file.Close()
+	lock.file = nil
+
+	return errors.Join(err, closeErr)
+}
+
+// Path returns the path of the lock file.
+func (lock *Lock) Path() string {
+	return lock.path
+}
+
+// ReadHolder returns the holder recorded in the lock file at path, or nil.
+func ReadHolder(path string) *Holder {
+	data, err := os.ReadFile(path)
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+
+	var holder Holder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return nil
+	}
+
+	return &holder
+}
+
+// Probe reports the status of the lock file at path without waiting.
+func Probe(path string) (Status, error) {
+	status := Status{Path: path, Holder: ReadHolder(path)}
+
+	lock, err := tryAcquire(path, false)
+	if err != nil {
+		return status, err
+	}
+
+	if lock == nil {
+		status.Held = true
+
+		return status, nil
+	}
+
+	// Probing must not clear the metadata of a stale holder.
+	unlockErr := unlockFile(lock.file)
+	closeErr := lock.file.Close()
+
+	return status, errors.Join(unlockErr, closeErr)
+}
+
+// List returns the status of every lock file in dir, sorted by path.
+func List(dir string) ([]Status, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+Suffix))
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(paths)
+
+	statuses := make([]Status, 0, len(paths))
+
+	for _, path := range paths {
+		status, err := Probe(path)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Break removes the lock file at path so that new acquirers use a fresh file.
+// It refuses when the lock is held by a process that is still running on this
+// host, or by an unknown holder, such as a shared one, which records no
+// metadata. Holders on other hosts cannot be checked and are assumed stale,
+// which covers locks left behind on network file systems.
+func Break(path string) (Status, error) {
+	status, err := Probe(path)
+	if err != nil {
+		return status, err
+	}
+
+	if status.Held && status.Holder == nil {
+		return status, fmt.Errorf("%w: %s held by an unknown holder", ErrHolderAlive, path)
+	}
+
+	if status.Held {
+		host, err := hostname()
+		if err != nil {
+			return status, fmt.Errorf("determining hostname: %w", err)
+		}
+
+		if status.Holder.Hostname == host && processAlive(status.Holder.PID) {
+			return status, fmt.Errorf("%w: %s held by %s", ErrHolderAlive, path, status.Holder)
+		}
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return status, fmt.Errorf("breaking lock %s: %w", path, err)
+	}
+
+	return status, nil
+}
+
+// tryAcquire attempts to lock path once. It returns a nil lock when another
+// process holds it.
+func tryAcquire(path string, shared bool) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock %s: %w", path, err)
+	}
+
+	locked, err := tryLockFile(file, shared)
+	if err != nil || !locked {
+		_ = file.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("locking %s: %w", path, err)
+		}
+
+		return nil, nil //nolint:nilnil // nil lock reports contention
+	}
+
+	// The lock file may have been broken and replaced while we waited for it,
+	// in which case we locked an orphaned file.
+	current, statErr := os.Stat(path)
+	opened, fileErr := file.Stat()
+
+	if statErr != nil || fileErr != nil || !os.SameFile(current, opened) {
+		_ = unlockFile(file)
+		_ = file.Close()
+
+		return nil, nil //nolint:nilnil // nil lock reports contention
+	}
+
+	return &Lock{file: file, path: path, shared: shared}, nil
+}
+
+// recordHolder writes the metadata of this process into the lock file.
+func (lock *Lock) recordHolder(purpose string) {
+	host, _ := hostname()
+
+	data, err := json.Marshal(Holder{
+		PID:      os.Getpid(),
+		Hostname: host,
+		Started:  time.Now().UTC(),
+		Command:  strings.Join(os.Args, " "),
+		Purpose:  purpose,
+	})
+	if err != nil {
+		return
+	}
+
+	if err := lock.file.Truncate(0); err != nil {
+		return
+	}
+
+	_, _ = lock.file.WriteAt(append(data, '\n'), 0)
+}
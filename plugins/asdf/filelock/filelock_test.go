@@ -0,0 +1,257 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filelock_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/filelock"
+)
+
+// TestAcquireTimeout verifies a held lock times out with the holder described.
+func TestAcquireTimeout(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "install-golang-1.25.0"+filelock.Suffix)
+
+	held, err := filelock.Acquire(t.Context(), path, filelock.Options{Purpose: "install golang 1.25.0"})
+	require.NoError(t, err)
+
+	defer held.Release()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 150*time.Millisecond)
+	defer cancel()
+
+	_, err = filelock.Acquire(ctx, path, filelock.Options{PollInterval: 10 * time.Millisecond})
+	require.ErrorIs(t, err, filelock.ErrLocked)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var heldErr *filelock.HeldError
+	require.ErrorAs(t, err, &heldErr)
+	require.NotNil(t, heldErr.Holder)
+	require.Equal(t, os.Getpid(), heldErr.Holder.PID)
+	require.Contains(t, err.Error(), "for install golang 1.25.0")
+}
+
+// TestAcquireCancel verifies waiting stops when the context is canceled.
+func TestAcquireCancel(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "tool-sums"+filelock.Suffix)
+
+	held, err := filelock.Acquire(t.Context(), path, filelock.Options{})
+	require.NoError(t, err)
+
+	defer held.Release()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err = filelock.Acquire(ctx, path, filelock.Options{PollInterval: 10 * time.Millisecond})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// TestAcquireContention verifies goroutines never hold an exclusive lock together.
+func TestAcquireContention(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "contended"+filelock.Suffix)
+
+	var (
+		inside  atomic.Int32
+		overlap atomic.Bool
+		count   atomic.Int32
+		wg      sync.WaitGroup
+	)
+
+	for range 16 {
+		wg.Go(func() {
+			lock, err := filelock.Acquire(t.Context(), path, filelock.Options{PollInterval: time.Millisecond})
+			require.NoError(t, err)
+
+			if inside.Add(1) > 1 {
+				overlap.Store(true)
+			}
+
+			count.Add(1)
+
+			time.Sleep(time.Millisecond)
+			inside.Add(-1)
+
+			require.NoError(t, lock.Release())
+		})
+	}
+
+	wg.Wait()
+
+	require.False(t, overlap.Load())
+	require.Equal(t, int32(16), count.Load())
+}
+
+// TestAcquireShared verifies shared holders coexist and exclude exclusive ones.
+func TestAcquireShared(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "shared"+filelock.Suffix)
+
+	first, err := filelock.Acquire(t.Context(), path, filelock.Options{Shared: true})
+	require.NoError(t, err)
+
+	second, err := filelock.Acquire(t.Context(), path, filelock.Options{Shared: true})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = filelock.Acquire(ctx, path, filelock.Options{PollInterval: 10 * time.Millisecond})
+	require.ErrorIs(t, err, filelock.ErrLocked)
+
+	require.NoError(t, first.Release())
+	require.NoError(t, second.Release())
+
+	exclusive, err := filelock.Acquire(t.Context(), path, filelock.Options{})
+	require.NoError(t, err)
+	require.NoError(t, exclusive.Release())
+}
+
+// TestListReportsHolders verifies the metadata reported for held and free locks.
+func TestListReportsHolders(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	held, err := filelock.Acquire(t.Context(), filepath.Join(dir, "b"+filelock.Suffix), filelock.Options{Purpose: "install jq 1.7"})
+	require.NoError(t, err)
+
+	free, err := filelock.Acquire(t.Context(), filepath.Join(dir, "a"+filelock.Suffix), filelock.Options{})
+	require.NoError(t, err)
+	require.NoError(t, free.Release())
+
+	statuses, err := filelock.List(dir)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	require.Equal(t, filepath.Join(dir, "a"+filelock.Suffix), statuses[0].Path)
+	require.False(t, statuses[0].Held)
+	require.Nil(t, statuses[0].Holder)
+
+	require.True(t, statuses[1].Held)
+	require.NotNil(t, statuses[1].Holder)
+	require.Equal(t, os.Getpid(), statuses[1].Holder.PID)
+	require.Equal(t, "install jq 1.7", statuses[1].Holder.Purpose)
+
+	host, err := os.Hostname()
+	require.NoError(t, err)
+	require.Equal(t, host, statuses[1].Holder.Hostname)
+
+	require.NoError(t, held.Release())
+
+	status, err := filelock.Probe(held.Path())
+	require.NoError(t, err)
+	require.False(t, status.Held)
+	require.Nil(t, status.Holder)
+}
+
+// TestBreak verifies locks of running holders on this host cannot be broken.
+func TestBreak(t *testing.T) {
+	t.Run("refuses running holders on this host", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "install"+filelock.Suffix)
+
+		held, err := filelock.Acquire(t.Context(), path, filelock.Options{})
+		require.NoError(t, err)
+
+		defer held.Release()
+
+		_, err = filelock.Break(path)
+		require.ErrorIs(t, err, filelock.ErrHolderAlive)
+		require.FileExists(t, path)
+	})
+
+	t.Run("refuses unknown holders", func(t *testing.T) {
+		filelock.SetProcessAliveForTests(t, false)
+
+		path := filepath.Join(t.TempDir(), "install"+filelock.Suffix)
+
+		// A dead exclusive holder left its metadata behind.
+		require.NoError(t, os.WriteFile(path, []byte(`{"pid":1,"hostname":"x"}`+"\n"), 0o600))
+
+		held, err := filelock.Acquire(t.Context(), path, filelock.Options{Shared: true})
+		require.NoError(t, err)
+
+		defer held.Release()
+
+		status, err := filelock.Break(path)
+		require.ErrorIs(t, err, filelock.ErrHolderAlive)
+		require.True(t, status.Held)
+		require.Nil(t, status.Holder)
+		require.FileExists(t, path)
+	})
+
+	t.Run("breaks locks of dead holders", func(t *testing.T) {
+		filelock.SetProcessAliveForTests(t, false)
+
+		path := filepath.Join(t.TempDir(), "install"+filelock.Suffix)
+
+		held, err := filelock.Acquire(t.Context(), path, filelock.Options{})
+		require.NoError(t, err)
+
+		defer held.Release()
+
+		status, err := filelock.Break(path)
+		require.NoError(t, err)
+		require.True(t, status.Held)
+		require.NoFileExists(t, path)
+
+		// The broken holder keeps its orphaned file; new acquirers get a fresh one.
+		fresh, err := filelock.Acquire(t.Context(), path, filelock.Options{})
+		require.NoError(t, err)
+		require.NoError(t, fresh.Release())
+	})
+
+	t.Run("breaks locks held from other hosts", func(t *testing.T) {
+		filelock.SetHostnameForTests(t, "build-runner-1")
+
+		path := filepath.Join(t.TempDir(), "install"+filelock.Suffix)
+
+		held, err := filelock.Acquire(t.Context(), path, filelock.Options{})
+		require.NoError(t, err)
+
+		defer held.Release()
+
+		filelock.SetHostnameForTests(t, "build-runner-2")
+
+		_, err = filelock.Break(path)
+		require.NoError(t, err)
+		require.NoFileExists(t, path)
+	})
+
+	t.Run("removes free lock files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "install"+filelock.Suffix)
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+		status, err := filelock.Break(path)
+		require.NoError(t, err)
+		require.False(t, status.Held)
+		require.NoFileExists(t, path)
+	})
+}
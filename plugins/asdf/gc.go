@@ -0,0 +1,472 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Kinds of the data directory entries removed by CollectGarbage.
+const (
+	// GCKindInstall is an installed tool version.
+	GCKindInstall = "install"
+	// GCKindStaging is the staging or previous install directory an
+	// interrupted install left behind, see StageInstall.
+	GCKindStaging = "staging"
+	// GCKindDownload is the download directory of a tool version.
+	GCKindDownload = "download"
+	// GCKindShim is a shim whose executable no longer exists.
+	GCKindShim = "shim"
+)
+
+// gcShimPluginMarker starts the lines of asdf shim scripts naming the tool
+// versions they run, as in "# asdf-plugin: nodejs 20.11.0".
+const gcShimPluginMarker = "# asdf-plugin:"
+
+// gcSkippedDirs are not searched for tool versions files below a project.
+var gcSkippedDirs = []string{".git", "node_modules", "vendor"} //nolint:gochecknoglobals // read-only lookup table
+
+type (
+	// GCOptions selects the entries of the data directory PlanGC removes.
+	GCOptions struct {
+		// Now is the time the age of downloads is measured from.
+		Now time.Time
+		// Protected holds the versions of each tool that are never removed,
+		// see ReferencedToolVersions.
+		Protected map[string]map[string]bool
+		// Keep retains the Keep newest installed versions of each tool, and the
+		// protected ones; zero keeps every version.
+		Keep int
+		// DownloadsOlderThan removes the downloads last modified longer ago;
+		// zero keeps every download.
+		DownloadsOlderThan time.Duration
+		// PruneShims removes the shims whose executable no longer exists,
+		// including those of the installs removed along.
+		PruneShims bool
+	}
+
+	// GCEntry is an entry of the data directory selected for removal.
+	GCEntry struct {
+		// Kind is GCKindInstall, GCKindStaging, GCKindDownload or GCKindShim.
+		Kind string
+		// Tool and Version name the tool version, empty for shims.
+		Tool    string
+		Version string
+		// Path is the removed file or directory.
+		Path string
+		// Size is the total size of the files below Path.
+		Size int64
+	}
+)
+
+// PlanGC returns the entries of the data layout (see CurrentLayout) to
+// remove according to options: installs, then the leftovers of interrupted
+// installs, which are always removed, then downloads, then shims.
+func PlanGC(options GCOptions) ([]GCEntry, error) {
+	layout, err := CurrentLayout()
+	if err != nil {
+		return nil, err
+	}
+
+	installs, err := planInstallsGC(options)
+	if err != nil {
+		return nil, err
+	}
+
+	leftovers, err := planLeftoversGC(layout)
+	if err != nil {
+		return nil, err
+	}
+
+	downloads, err := planDownloadsGC(layout, options)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := slices.Concat(installs, leftovers, downloads)
+
+	if options.PruneShims {
+		removed := make([]string, 0, len(installs))
+		for _, entry := range installs {
+			removed = append(removed, entry.Path)
+		}
+
+		shims, err := planShimsGC(layout, removed)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, shims...)
+	}
+
+	return entries, nil
+}
+
+// planInstallsGC selects the installed versions of each tool older than the
+// options.Keep newest ones that are not protected.
+func planInstallsGC(options GCOptions) ([]GCEntry, error) {
+	if options.Keep <= 0 {
+		return nil, nil
+	}
+
+	tools, err := InstalledTools()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []GCEntry
+
+	for _, tool := range tools {
+		installed, err := ListInstalled(tool, "", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, version := range installed[min(options.Keep, len(installed)):] {
+			if options.Protected[tool][version.Version] {
+				continue
+			}
+
+			entries = append(entries, GCEntry{
+				Kind: GCKindInstall, Tool: tool, Version: version.Version,
+				Path: version.Path, Size: TreeSize(version.Path),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// planLeftoversGC selects the staging and previous install directories of
+// every tool, see IsInstallLeftover.
+func planLeftoversGC(layout DataLayout) ([]GCEntry, error) {
+	tools, err := InstalledTools()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []GCEntry
+
+	for _, tool := range tools {
+		dir := filepath.Join(layout.InstallsDir, tool)
+
+		names, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("reading installs of %s: %w", tool, err)
+		}
+
+		for _, name := range names {
+			version, ok := installLeftoverVersion(name.Name())
+			if !ok {
+				continue
+			}
+
+			path := filepath.Join(dir, name.Name())
+			entries = append(entries, GCEntry{
+				Kind: GCKindStaging, Tool: tool, Version: version,
+				Path: path, Size: TreeSize(path),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// planDownloadsGC selects the unprotected download directories whose newest
+// file is older than options.DownloadsOlderThan.
+func planDownloadsGC(layout DataLayout, options GCOptions) ([]GCEntry, error) {
+	if options.DownloadsOlderThan <= 0 {
+		return nil, nil
+	}
+
+	cutoff := options.Now.Add(-options.DownloadsOlderThan)
+
+	tools, err := os.ReadDir(layout.DownloadsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", layout.DownloadsDir, err)
+	}
+
+	var entries []GCEntry
+
+	for _, tool := range tools {
+		if !tool.IsDir() {
+			continue
+		}
+
+		versions, err := os.ReadDir(filepath.Join(layout.DownloadsDir, tool.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading downloads of %s: %w", tool.Name(), err)
+		}
+
+		for _, version := range versions {
+			if !version.IsDir() || options.Protected[tool.Name()][version.Name()] {
+				continue
+			}
+
+			path := layout.DownloadPath(tool.Name(), version.Name())
+			if !lastModified(path).Before(cutoff) {
+				continue
+			}
+
+			entries = append(entries, GCEntry{
+				Kind: GCKindDownload, Tool: tool.Name(), Version: version.Name(),
+				Path: path, Size: TreeSize(path),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// lastModified returns the newest modification time below root, root included.
+func lastModified(root string) time.Time {
+	var newest time.Time
+
+	_ = filepath.WalkDir(root, func(_ string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // unreadable entries do not count
+		}
+
+		if info, err := dirEntry.Info(); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+
+		return nil
+	})
+
+	return newest
+}
+
+// planShimsGC selects the shims whose executable no longer exists or lies
+// below one of the removed install paths.
+func planShimsGC(layout DataLayout, removed []string) ([]GCEntry, error) {
+	shims, err := os.ReadDir(layout.ShimsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", layout.ShimsDir, err)
+	}
+
+	var entries []GCEntry
+
+	for _, shim := range shims {
+		path := filepath.Join(layout.ShimsDir, shim.Name())
+
+		if shim.IsDir() || !orphanedShim(layout, path, removed) {
+			continue
+		}
+
+		entries = append(entries, GCEntry{Kind: GCKindShim, Path: path, Size: TreeSize(path)})
+	}
+
+	return entries, nil
+}
+
+// orphanedShim reports whether the shim at path runs nothing anymore: a
+// symlink whose target is missing or removed, or an asdf shim script none
+// of whose tool versions remains installed. Other files are kept.
+func orphanedShim(layout DataLayout, path string, removed []string) bool {
+	if target, err := os.Readlink(path); err == nil {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+
+		if _, err := os.Stat(target); err != nil {
+			return true
+		}
+
+		return slices.ContainsFunc(removed, func(installPath string) bool {
+			return isPathWithinDir(target, installPath)
+		})
+	}
+
+	versions := shimToolVersions(path)
+	if len(versions) == 0 {
+		return false
+	}
+
+	for _, version := range versions {
+		installPath := layout.InstallPath(version[0], version[1])
+		if version[1] == SystemVersion || slices.Contains(removed, installPath) {
+			continue
+		}
+
+		if _, err := os.Stat(installPath); err == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// shimToolVersions returns the tool and version pairs listed by the
+// gcShimPluginMarker lines of the shim script at path.
+func shimToolVersions(path string) [][2]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var versions [][2]string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		rest, ok := strings.CutPrefix(scanner.Text(), gcShimPluginMarker)
+		if !ok {
+			continue
+		}
+
+		if fields := strings.Fields(rest); len(fields) == 2 {
+			versions = append(versions, [2]string{fields[0], fields[1]})
+		}
+	}
+
+	return versions
+}
+
+// RemoveGCEntries removes entries and returns the total size of those
+// removed. Installs are removed holding their install lock; failures are
+// joined and do not stop the removal of the other entries.
+func RemoveGCEntries(ctx context.Context, entries []GCEntry) (int64, error) {
+	var (
+		reclaimed int64
+		errs      []error
+	)
+
+	for _, entry := range entries {
+		if err := removeGCEntry(ctx, entry); err != nil {
+			errs = append(errs, fmt.Errorf("removing %s: %w", entry.Path, err))
+
+			continue
+		}
+
+		reclaimed += entry.Size
+	}
+
+	return reclaimed, errors.Join(errs...)
+}
+
+// removeGCEntry removes the file or directory of entry. Installs and their
+// leftovers are removed holding the install lock of their version, which an
+// install in progress holds while it stages.
+func removeGCEntry(ctx context.Context, entry GCEntry) error {
+	if entry.Kind == GCKindInstall || entry.Kind == GCKindStaging {
+		lock, err := AcquireLock(ctx, InstallLockName(entry.Tool, entry.Version),
+			fmt.Sprintf("gc %s %s", entry.Tool, entry.Version), false)
+		if err != nil {
+			return err
+		}
+		defer releaseLock(lock)
+	}
+
+	return os.RemoveAll(entry.Path)
+}
+
+// ReferencedToolVersions returns every version listed in the tool versions
+// files, keyed by tool, including the fallback versions of lines listing
+// several. Refs are keyed by their install directory name, see RefVersionDir.
+// Missing or unreadable files list nothing.
+func ReferencedToolVersions(files ...string) map[string]map[string]bool {
+	referenced := make(map[string]map[string]bool)
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		for _, line := range VersionFileLines(string(data)) {
+			line, _, _ = strings.Cut(line, "#")
+
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+
+			if referenced[fields[0]] == nil {
+				referenced[fields[0]] = make(map[string]bool)
+			}
+
+			for _, version := range fields[1:] {
+				if expanded, err := ExpandToolVersion(version); err == nil {
+					version = expanded
+				}
+
+				if ref, ok := ParseRefVersion(version); ok {
+					version = RefVersionDir(ref)
+				}
+
+				referenced[fields[0]][version] = true
+			}
+		}
+	}
+
+	return referenced
+}
+
+// ProjectToolVersionsFiles returns the tool versions files of the project in
+// dir: those below dir, except in version control and dependency
+// directories, unreadable directories and the data directory, and those
+// consulted from dir, see ToolVersionsFiles.
+func ProjectToolVersionsFiles(dir string) ([]string, error) {
+	name := ToolVersionsFilename()
+	dataDir, _ := DataDir()
+
+	var files []string
+
+	err := filepath.WalkDir(dir, func(path string, dirEntry fs.DirEntry, err error) error {
+		switch {
+		case err != nil && path != dir && errors.Is(err, fs.ErrPermission):
+			return filepath.SkipDir
+		case err != nil:
+			return err
+		case dirEntry.IsDir() && path != dir &&
+			(slices.Contains(gcSkippedDirs, dirEntry.Name()) || path == filepath.Clean(dataDir)):
+			return filepath.SkipDir
+		case !dirEntry.IsDir() && dirEntry.Name() == name:
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching %s for %s files: %w", dir, name, err)
+	}
+
+	for _, path := range ToolVersionsFiles(dir) {
+		if !slices.Contains(files, path) {
+			files = append(files, path)
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,184 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestPlanGC verifies old installs, stale downloads and orphaned shims are
+// selected while the newest and protected versions stay, and that removing
+// them reports the reclaimed size.
+func TestPlanGC(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, data)
+	t.Setenv(asdf.DownloadsDirEnv, "")
+	t.Setenv(asdf.InstallsDirEnv, "")
+
+	writeTree(t, data, map[string]string{
+		"installs/tool/1.0.0/bin/tool":  "1.0.0",
+		"installs/tool/1.1.0/bin/tool":  "1.1.0",
+		"installs/tool/1.2.0/bin/tool":  "1.2.0",
+		"installs/tool/2.0.0/bin/tool":  "2.0.0",
+		"installs/other/3.0.0/bin/x":    "3.0.0",
+		"downloads/tool/1.0.0/tool.tgz": "old archive",
+		"downloads/tool/1.1.0/tool.tgz": "protected archive",
+		"downloads/tool/2.0.0/tool.tgz": "new archive",
+		"shims/tool":                    "->" + filepath.Join(data, "installs/tool/2.0.0/bin/tool"),
+		"shims/old":                     "->" + filepath.Join(data, "installs/tool/1.0.0/bin/tool"),
+		"shims/gone":                    "->" + filepath.Join(data, "installs/gone/1.0.0/bin/gone"),
+		"shims/script":                  "#!/bin/sh\n# asdf-plugin: tool 0.9.0\nexec asdf exec script\n",
+		"shims/current-script":          "#!/bin/sh\n# asdf-plugin: tool 0.9.0\n# asdf-plugin: tool 2.0.0\n",
+		"shims/plain":                   "#!/bin/sh\n",
+	})
+
+	stale := time.Now().Add(-48 * time.Hour)
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		for _, path := range []string{"downloads/tool/" + version + "/tool.tgz", "downloads/tool/" + version} {
+			require.NoError(t, os.Chtimes(filepath.Join(data, path), stale, stale))
+		}
+	}
+
+	entries, err := asdf.PlanGC(asdf.GCOptions{
+		Now:                time.Now(),
+		Protected:          map[string]map[string]bool{"tool": {"1.1.0": true}},
+		Keep:               2,
+		DownloadsOlderThan: 24 * time.Hour,
+		PruneShims:         true,
+	})
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		rel, err := filepath.Rel(data, entry.Path)
+		require.NoError(t, err)
+
+		paths = append(paths, entry.Kind+" "+filepath.ToSlash(rel))
+	}
+
+	require.Equal(t, []string{
+		"install installs/tool/1.0.0",
+		"download downloads/tool/1.0.0",
+		"shim shims/gone",
+		"shim shims/old",
+		"shim shims/script",
+	}, paths)
+	require.Equal(t, asdf.GCEntry{
+		Kind: asdf.GCKindDownload, Tool: "tool", Version: "1.0.0",
+		Path: filepath.Join(data, "downloads/tool/1.0.0"), Size: int64(len("old archive")),
+	}, entries[1])
+
+	require.Equal(t, int64(len("1.0.0")), entries[0].Size)
+	require.Zero(t, entries[2].Size)
+
+	var planned int64
+	for _, entry := range entries {
+		planned += entry.Size
+	}
+
+	reclaimed, err := asdf.RemoveGCEntries(t.Context(), entries)
+	require.NoError(t, err)
+	require.Equal(t, planned, reclaimed)
+
+	for _, entry := range entries {
+		require.NoFileExists(t, entry.Path)
+		require.NoDirExists(t, entry.Path)
+	}
+
+	for _, path := range []string{"installs/tool/1.1.0", "installs/tool/1.2.0", "installs/other/3.0.0", "downloads/tool/1.1.0"} {
+		require.DirExists(t, filepath.Join(data, path))
+	}
+
+	entries, err = asdf.PlanGC(asdf.GCOptions{Now: time.Now()})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+// TestPlanGCInstallLeftovers verifies the leftovers of interrupted installs
+// are always selected, without the installs themselves.
+func TestPlanGCInstallLeftovers(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, data)
+	t.Setenv(asdf.DownloadsDirEnv, "")
+	t.Setenv(asdf.InstallsDirEnv, "")
+
+	writeTree(t, data, map[string]string{
+		"installs/tool/2.0.0/bin/tool":              "2.0.0",
+		"installs/tool/2.0.0.staging-1234/bin/tool": "partial",
+		"installs/other/3.0.0.trash-5678/bin/other": "previous",
+		"installs/other/3.0.0/bin/other":            "3.0.0",
+	})
+
+	entries, err := asdf.PlanGC(asdf.GCOptions{Now: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, []asdf.GCEntry{
+		{
+			Kind: asdf.GCKindStaging, Tool: "other", Version: "3.0.0",
+			Path: filepath.Join(data, "installs/other/3.0.0.trash-5678"), Size: int64(len("previous")),
+		},
+		{
+			Kind: asdf.GCKindStaging, Tool: "tool", Version: "2.0.0",
+			Path: filepath.Join(data, "installs/tool/2.0.0.staging-1234"), Size: int64(len("partial")),
+		},
+	}, entries)
+
+	_, err = asdf.RemoveGCEntries(t.Context(), entries)
+	require.NoError(t, err)
+	require.NoDirExists(t, entries[0].Path)
+	require.NoDirExists(t, entries[1].Path)
+	require.DirExists(t, filepath.Join(data, "installs/tool/2.0.0"))
+}
+
+// TestProjectToolVersionsFiles verifies the tool versions files of a project
+// are found below it, outside dependency directories, with every version
+// they reference.
+func TestProjectToolVersionsFiles(t *testing.T) {
+	t.Setenv(asdf.ToolVersionsFilenameEnv, "")
+
+	project, home := t.TempDir(), t.TempDir()
+	asdf.MockOSForTests(t, "", home)
+
+	writeTree(t, project, map[string]string{
+		".tool-versions":                    "tool 1.1.0 1.0.5  # fallback\nnodejs ref:main\n",
+		"services/api/.tool-versions":       "other 3.0.0\n",
+		"node_modules/dep/.tool-versions":   "tool 0.1.0\n",
+		".git/modules/sub/.tool-versions":   "tool 0.2.0\n",
+		"services/api/testdata/ignored.txt": "tool 0.3.0\n",
+	})
+	writeTree(t, home, map[string]string{".tool-versions": "tool 0.9.0\n"})
+
+	files, err := asdf.ProjectToolVersionsFiles(project)
+	require.NoError(t, err)
+	require.Subset(t, files, []string{
+		filepath.Join(project, ".tool-versions"),
+		filepath.Join(project, "services", "api", ".tool-versions"),
+		filepath.Join(home, ".tool-versions"),
+	})
+	require.NotContains(t, files, filepath.Join(project, "node_modules", "dep", ".tool-versions"))
+
+	require.Equal(t, map[string]map[string]bool{
+		"tool":   {"1.1.0": true, "1.0.5": true, "0.9.0": true},
+		"nodejs": {asdf.RefVersionDir("main"): true},
+		"other":  {"3.0.0": true},
+	}, asdf.ReferencedToolVersions(files...))
+}
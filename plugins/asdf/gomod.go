@@ -0,0 +1,215 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	// errGoModNotFound is returned when no go.mod exists in a directory or its parents.
+	errGoModNotFound = errors.New("no go.mod found")
+	// errGoModRequireMissing is returned when go.mod does not require a module.
+	errGoModRequireMissing = errors.New("go.mod does not require module")
+
+	// goModCache caches the parsed nearest go.mod per directory.
+	goModCache sync.Map //nolint:gochecknoglobals // per-process parse cache
+)
+
+type (
+	// GoModVersion resolves a tool version from the version of a Go module
+	// required by the nearest go.mod, for tools that must match a library.
+	GoModVersion struct {
+		// Module is the required module path, e.g. github.com/onsi/ginkgo/v2.
+		Module string
+		// Keyword is the pinned version that selects go.mod resolution.
+		Keyword string
+		// ForceEnv selects go.mod resolution for every version when set to 1.
+		ForceEnv string
+	}
+
+	// goModRequires is the parsed require section of a go.mod file.
+	goModRequires struct {
+		err      error
+		versions map[string]string
+		path     string
+	}
+)
+
+// Resolve returns the module version required by the nearest go.mod above dir,
+// without its "v" prefix, when version is the keyword or ForceEnv is set, and
+// version unchanged otherwise.
+func (resolver GoModVersion) Resolve(dir, version string) (string, error) {
+	forced := resolver.ForceEnv != "" && os.Getenv(resolver.ForceEnv) == "1"
+	if version != resolver.Keyword && !forced {
+		return version, nil
+	}
+
+	required, err := GoModRequireVersion(dir, resolver.Module)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimPrefix(required, "v"), nil
+}
+
+// GoModRequireVersion returns the version of module required, directly or
+// indirectly, by the nearest go.mod in dir or its parents. Parsed go.mod files
+// are cached per directory for the lifetime of the process.
+func GoModRequireVersion(dir, module string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	cached, ok := goModCache.Load(absDir)
+	if !ok {
+		cached, _ = goModCache.LoadOrStore(absDir, parseNearestGoMod(absDir))
+	}
+
+	requires, _ := cached.(goModRequires)
+	if requires.err != nil {
+		return "", requires.err
+	}
+
+	version, ok := requires.versions[module]
+	if !ok {
+		return "", fmt.Errorf("%w: %s does not require %s", errGoModRequireMissing, requires.path, module)
+	}
+
+	return version, nil
+}
+
+// parseNearestGoMod finds and parses the go.mod in dir or its closest parent.
+func parseNearestGoMod(dir string) goModRequires {
+	for current := dir; ; current = filepath.Dir(current) {
+		path := filepath.Join(current, "go.mod")
+
+		versions, err := parseGoModRequires(path)
+		if err == nil {
+			return goModRequires{path: path, versions: versions}
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return goModRequires{path: path, err: err}
+		}
+
+		if filepath.Dir(current) == current {
+			return goModRequires{err: fmt.Errorf("%w in %s or its parents", errGoModNotFound, dir)}
+		}
+	}
+}
+
+// parseGoModRequires returns the module versions listed in the require
+// directives of the go.mod at path.
+func parseGoModRequires(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	versions := make(map[string]string)
+	inBlock := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock && len(fields) >= 2:
+			versions[fields[0]] = fields[1]
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "require" && len(fields) >= 3:
+			versions[fields[1]] = fields[2]
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return versions, nil
+}
+
+// ResolveEffectiveVersion returns the version of plugin to install and run for
+// a project in dir that pins version.
+func ResolveEffectiveVersion(plugin Plugin, dir, version string) (string, error) {
+	resolver, ok := plugin.(PluginWithVersionResolver)
+	if !ok {
+		return version, nil
+	}
+
+	resolved, err := resolver.ResolveVersion(dir, version)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s version %s: %w", plugin.Name(), version, err)
+	}
+
+	if resolved != version {
+		Logger().Debug("resolved effective version", "tool", plugin.Name(), "pinned", version, "version", resolved)
+	}
+
+	return resolved, nil
+}
+
+// ResolveToolVersion returns the effective version of plugin selected like asdf
+// does: by ASDF_<TOOL>_VERSION, else by the nearest .tool-versions file of the
+// working directory, its parents or the home directory, else, with
+// legacy_version_file = yes, by the nearest legacy version file of the
+// plugin. It returns "" when the tool is not pinned.
+func ResolveToolVersion(plugin Plugin) (string, error) {
+	dir, err := osGetwd()
+	if err != nil {
+		return "", err
+	}
+
+	version := os.Getenv(ToolVersionEnv(plugin.Name()))
+	if version == "" {
+		version = pinnedToolVersion(plugin.Name())
+	}
+
+	if version == "" {
+		config, err := LoadConfig()
+		if err != nil {
+			return "", err
+		}
+
+		if config.LegacyVersionFile() {
+			version, err = legacyToolVersion(plugin, dir)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if version == "" {
+		return "", nil
+	}
+
+	return ResolveEffectiveVersion(plugin, dir, version)
+}
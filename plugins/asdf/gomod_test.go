@@ -0,0 +1,239 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+const (
+	testGinkgoModule      = "github.com/onsi/ginkgo/v2"
+	testGoModFromGoModEnv = "ASDF_TEST_FROM_GOMOD"
+)
+
+// goModResolverPlugin resolves "project" from the ginkgo require of go.mod.
+type goModResolverPlugin struct {
+	mockPlugin
+}
+
+func (*goModResolverPlugin) ResolveVersion(dir, version string) (string, error) {
+	resolver := asdf.GoModVersion{
+		Module:   testGinkgoModule,
+		Keyword:  "project",
+		ForceEnv: testGoModFromGoModEnv,
+	}
+
+	return resolver.Resolve(dir, version)
+}
+
+// TestGoModRequireVersion verifies require lookups in fixture go.mod files.
+func TestGoModRequireVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		wantErr error
+		name    string
+		goMod   string
+		want    string
+	}{
+		{
+			name: "direct require block",
+			goMod: "module example.com/app\n\ngo 1.25\n\nrequire (\n" +
+				"\tgithub.com/onsi/ginkgo/v2 v2.19.0\n\tgithub.com/onsi/gomega v1.33.1\n)\n",
+			want: "v2.19.0",
+		},
+		{
+			name: "indirect require",
+			goMod: "module example.com/app\n\nrequire github.com/onsi/gomega v1.33.1\n\n" +
+				"require (\n\tgithub.com/onsi/ginkgo/v2 v2.17.1 // indirect\n)\n",
+			want: "v2.17.1",
+		},
+		{
+			name:    "missing dependency",
+			goMod:   "module example.com/app\n\nrequire github.com/onsi/ginkgo v1.16.5\n",
+			wantErr: asdf.ErrGoModRequireMissingForTests(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			writeTree(t, dir, map[string]string{"go.mod": tt.goMod, "pkg/suite/.keep": ""})
+
+			version, err := asdf.GoModRequireVersion(filepath.Join(dir, "pkg", "suite"), testGinkgoModule)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Contains(t, err.Error(), filepath.Join(dir, "go.mod"))
+				require.Contains(t, err.Error(), testGinkgoModule)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, version)
+		})
+	}
+
+	t.Run("caches the parse per directory", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{"go.mod": "module m\n\nrequire github.com/onsi/ginkgo/v2 v2.19.0\n"})
+
+		version, err := asdf.GoModRequireVersion(dir, testGinkgoModule)
+		require.NoError(t, err)
+		require.Equal(t, "v2.19.0", version)
+
+		writeTree(t, dir, map[string]string{"go.mod": "module m\n\nrequire github.com/onsi/ginkgo/v2 v2.20.0\n"})
+
+		version, err = asdf.GoModRequireVersion(dir, testGinkgoModule)
+		require.NoError(t, err)
+		require.Equal(t, "v2.19.0", version)
+	})
+}
+
+// TestResolveToolVersionFromGoMod verifies project keywords resolve through go.mod.
+func TestResolveToolVersionFromGoMod(t *testing.T) {
+	goMod := "module example.com/app\n\nrequire github.com/onsi/ginkgo/v2 v2.19.0\n"
+
+	t.Run("resolves the project keyword", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{".tool-versions": "mock project\n", "go.mod": goMod})
+		asdf.MockOSForTests(t, dir, t.TempDir())
+
+		version, err := asdf.ResolveToolVersion(&goModResolverPlugin{})
+		require.NoError(t, err)
+		require.Equal(t, "2.19.0", version)
+	})
+
+	t.Run("keeps concrete versions", func(t *testing.T) {
+		t.Setenv(testGoModFromGoModEnv, "")
+
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{".tool-versions": "mock 2.1.0\n", "go.mod": goMod})
+		asdf.MockOSForTests(t, dir, t.TempDir())
+
+		version, err := asdf.ResolveToolVersion(&goModResolverPlugin{})
+		require.NoError(t, err)
+		require.Equal(t, "2.1.0", version)
+	})
+
+	t.Run("forces go.mod resolution from the environment", func(t *testing.T) {
+		t.Setenv(testGoModFromGoModEnv, "1")
+
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{".tool-versions": "mock 2.1.0\n", "go.mod": goMod})
+		asdf.MockOSForTests(t, dir, t.TempDir())
+
+		version, err := asdf.ResolveToolVersion(&goModResolverPlugin{})
+		require.NoError(t, err)
+		require.Equal(t, "2.19.0", version)
+	})
+
+	t.Run("reports a missing go.mod", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{".tool-versions": "mock project\n"})
+		asdf.MockOSForTests(t, dir, t.TempDir())
+
+		_, err := asdf.ResolveToolVersion(&goModResolverPlugin{})
+		require.ErrorIs(t, err, asdf.ErrGoModNotFoundForTests())
+		require.Contains(t, err.Error(), "resolving mock version project")
+	})
+
+	t.Run("returns nothing for unpinned tools", func(t *testing.T) {
+		dir := t.TempDir()
+		asdf.MockOSForTests(t, dir, t.TempDir())
+
+		version, err := asdf.ResolveToolVersion(&goModResolverPlugin{})
+		require.NoError(t, err)
+		require.Empty(t, version)
+	})
+
+	t.Run("leaves plugins without resolver alone", func(t *testing.T) {
+		version, err := asdf.ResolveEffectiveVersion(&mockPlugin{}, t.TempDir(), "project")
+		require.NoError(t, err)
+		require.Equal(t, "project", version)
+	})
+}
+
+// legacyFilePlugin pins versions with .mock-version files, except those
+// holding "none".
+type legacyFilePlugin struct {
+	mockPlugin
+}
+
+func (*legacyFilePlugin) ListLegacyFilenames() []string { return []string{".mock-version"} }
+
+func (*legacyFilePlugin) ParseLegacyFile(path string) (string, error) {
+	version, err := asdf.ReadVersionFile(path)
+	if version == "none" {
+		return "", asdf.ErrNoLegacyPin
+	}
+
+	return version, err
+}
+
+// TestResolveToolVersionPrecedence verifies ASDF_<TOOL>_VERSION wins over the
+// nearest .tool-versions, which wins over the nearest legacy version file,
+// read only with legacy_version_file = yes.
+func TestResolveToolVersionPrecedence(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "project", "nested")
+	writeTree(t, root, map[string]string{
+		".mock-version":          "1.0.0\n",
+		"project/nested/.keep":   "",
+		"other/.mock-version":    "3.0.0\n",
+		"pinned/.tool-versions":  "mock 4.0.0\n",
+		"pinned/a/.mock-version": "5.0.0\n",
+		"unpinned/.mock-version": "none\n",
+	})
+
+	configPath := filepath.Join(root, ".asdfrc")
+	t.Setenv(asdf.ConfigFileEnv, configPath)
+	t.Setenv(asdf.ToolVersionEnv("mock"), "")
+
+	resolve := func(t *testing.T, dir string) string {
+		t.Helper()
+
+		asdf.MockOSForTests(t, dir, t.TempDir())
+
+		version, err := asdf.ResolveToolVersion(&legacyFilePlugin{})
+		require.NoError(t, err)
+
+		return version
+	}
+
+	require.Empty(t, resolve(t, nested), "legacy files are ignored by default")
+
+	writeTree(t, root, map[string]string{".asdfrc": "legacy_version_file = yes\n"})
+	require.Equal(t, "1.0.0", resolve(t, nested), "nearest legacy file in a parent")
+	require.Equal(t, "3.0.0", resolve(t, filepath.Join(root, "other")), "nearest legacy file")
+	require.Equal(t, "4.0.0", resolve(t, filepath.Join(root, "pinned", "a")), ".tool-versions wins over a nearer legacy file")
+	require.Equal(t, "1.0.0", resolve(t, filepath.Join(root, "unpinned")), "legacy files pinning nothing are skipped")
+
+	writeTree(t, root, map[string]string{".asdfrc": "legacy_version_file = no\n"})
+	require.Empty(t, resolve(t, filepath.Join(root, "other")))
+
+	t.Setenv(asdf.ToolVersionEnv("mock"), "6.0.0")
+	require.Equal(t, "6.0.0", resolve(t, filepath.Join(root, "pinned")), "the environment wins over .tool-versions")
+	require.Equal(t, "ASDF_GITHUB_CLI_VERSION", asdf.ToolVersionEnv("github-cli"))
+}
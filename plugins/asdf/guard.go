@@ -0,0 +1,101 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// InstallGuardEnv lists the tools currently being installed by this process and
+// its ancestors, separated by commas. Nested invocations inherit it through the
+// environment, which lets them detect install loops.
+const InstallGuardEnv = "UNIVERSAL_ASDF_IN_INSTALL"
+
+// SystemVersion is the version keyword selecting the tool installed on the host.
+const SystemVersion = "system"
+
+// errRecursiveInstall is returned when a tool is installed or executed while it is being installed.
+var errRecursiveInstall = errors.New("recursive install detected")
+
+// ToolsBeingInstalled returns the tools currently being installed according to the environment.
+func ToolsBeingInstalled() []string {
+	value := os.Getenv(InstallGuardEnv)
+	if value == "" {
+		return nil
+	}
+
+	tools := make([]string, 0, strings.Count(value, ",")+1)
+	for tool := range strings.SplitSeq(value, ",") {
+		if tool = strings.TrimSpace(tool); tool != "" {
+			tools = append(tools, tool)
+		}
+	}
+
+	return tools
+}
+
+// CheckInstallRecursion fails when toolName is already being installed by this
+// process or one of its ancestors. It is meant to be called before installing or
+// executing toolName, e.g. when a build toolchain resolves to a shim of the tool
+// that is being installed.
+func CheckInstallRecursion(toolName string) error {
+	if !slices.Contains(ToolsBeingInstalled(), toolName) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: %s is already being installed (%s=%s); the build toolchain probably resolved "+
+			"to a shim of %s, install %s on the host and pin it with '%s %s' in .tool-versions",
+		errRecursiveInstall,
+		toolName,
+		InstallGuardEnv,
+		os.Getenv(InstallGuardEnv),
+		toolName,
+		toolName,
+		toolName,
+		SystemVersion,
+	)
+}
+
+// EnterInstall marks toolName as being installed for this process and every
+// child process it spawns. It fails when toolName is already being installed.
+// The returned function restores the previous marker.
+func EnterInstall(toolName string) (func(), error) {
+	if err := CheckInstallRecursion(toolName); err != nil {
+		return nil, err
+	}
+
+	previous, hadPrevious := os.LookupEnv(InstallGuardEnv)
+
+	tools := append(ToolsBeingInstalled(), toolName)
+	if err := os.Setenv(InstallGuardEnv, strings.Join(tools, ",")); err != nil {
+		return nil, fmt.Errorf("setting %s: %w", InstallGuardEnv, err)
+	}
+
+	return func() {
+		if hadPrevious {
+			_ = os.Setenv(InstallGuardEnv, previous)
+
+			return
+		}
+
+		_ = os.Unsetenv(InstallGuardEnv)
+	}, nil
+}
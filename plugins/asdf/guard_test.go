@@ -0,0 +1,148 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+func TestEnterInstall(t *testing.T) {
+	t.Run("sets and restores the marker", func(t *testing.T) {
+		t.Setenv(asdf.InstallGuardEnv, "")
+		require.NoError(t, os.Unsetenv(asdf.InstallGuardEnv))
+
+		release, err := asdf.EnterInstall("golang")
+		require.NoError(t, err)
+		require.Equal(t, "golang", os.Getenv(asdf.InstallGuardEnv))
+
+		releaseNested, err := asdf.EnterInstall("ginkgo")
+		require.NoError(t, err)
+		require.Equal(t, []string{"golang", "ginkgo"}, asdf.ToolsBeingInstalled())
+
+		releaseNested()
+		require.Equal(t, "golang", os.Getenv(asdf.InstallGuardEnv))
+
+		release()
+
+		_, set := os.LookupEnv(asdf.InstallGuardEnv)
+		require.False(t, set)
+	})
+
+	t.Run("fails fast when the tool is already being installed", func(t *testing.T) {
+		t.Setenv(asdf.InstallGuardEnv, "python,golang")
+
+		release, err := asdf.EnterInstall("golang")
+		require.ErrorIs(t, err, asdf.ErrRecursiveInstallForTests())
+		require.Nil(t, release)
+		require.Contains(t, err.Error(), "golang system")
+		require.Equal(t, "python,golang", os.Getenv(asdf.InstallGuardEnv))
+	})
+
+	t.Run("allows unrelated tools", func(t *testing.T) {
+		t.Setenv(asdf.InstallGuardEnv, "python")
+
+		require.NoError(t, asdf.CheckInstallRecursion("golang"))
+	})
+}
+
+func TestInstallWithDependenciesRecursionGuard(t *testing.T) {
+	t.Run("refuses to install a tool that is being installed", func(t *testing.T) {
+		t.Setenv("ASDF_DATA_DIR", t.TempDir())
+		t.Setenv(asdf.InstallGuardEnv, "golang")
+
+		plugin := &mockPlugin{latestVersion: "1.22.0"}
+
+		err := asdf.InstallWithDependencies(t.Context(), "golang", plugin)
+		require.ErrorIs(t, err, asdf.ErrRecursiveInstallForTests())
+		require.False(t, plugin.installCalled)
+	})
+
+	t.Run("nested installs inherit the marker", func(t *testing.T) {
+		tempDir := t.TempDir()
+		markerFile := filepath.Join(tempDir, "marker")
+
+		t.Setenv("ASDF_DATA_DIR", tempDir)
+		t.Setenv("ASDF_MOCK_MARKER_FILE", markerFile)
+		t.Setenv(asdf.InstallGuardEnv, "")
+		require.NoError(t, os.Unsetenv(asdf.InstallGuardEnv))
+
+		asdf.MockExecForTests(t, nil)
+		asdf.MockOSForTests(t, tempDir, tempDir)
+
+		plugin := &mockPluginWithDeps{
+			mockPlugin: mockPlugin{latestVersion: "2.0.0"},
+			deps:       []string{"golang"},
+		}
+
+		require.NoError(t, asdf.InstallWithDependencies(t.Context(), "ginkgo", plugin))
+		require.True(t, plugin.installCalled)
+
+		marker, err := os.ReadFile(markerFile)
+		require.NoError(t, err)
+		require.Equal(t, "ginkgo", string(marker))
+
+		_, set := os.LookupEnv(asdf.InstallGuardEnv)
+		require.False(t, set)
+	})
+
+	t.Run("dependency loop fails before running asdf", func(t *testing.T) {
+		tempDir := t.TempDir()
+		markerFile := filepath.Join(tempDir, "marker")
+
+		t.Setenv("ASDF_DATA_DIR", tempDir)
+		t.Setenv("ASDF_MOCK_MARKER_FILE", markerFile)
+		t.Setenv(asdf.InstallGuardEnv, "golang")
+
+		asdf.MockExecForTests(t, nil)
+		asdf.MockOSForTests(t, tempDir, tempDir)
+
+		err := asdf.InstallDependenciesForTests(t.Context(), "golang")
+		require.ErrorIs(t, err, asdf.ErrRecursiveInstallForTests())
+		require.NoFileExists(t, markerFile)
+	})
+
+	t.Run("system pins skip the nested install", func(t *testing.T) {
+		tempDir := t.TempDir()
+		markerFile := filepath.Join(tempDir, "marker")
+
+		t.Setenv("ASDF_DATA_DIR", tempDir)
+		t.Setenv("ASDF_MOCK_MARKER_FILE", markerFile)
+		t.Setenv(asdf.InstallGuardEnv, "golang")
+
+		require.NoError(t, os.WriteFile(
+			filepath.Join(tempDir, ".tool-versions"),
+			[]byte("golang system\n"),
+			asdf.CommonFilePermission,
+		))
+
+		asdf.MockExecForTests(t, nil)
+		asdf.MockOSForTests(t, tempDir, tempDir)
+
+		require.NoError(t, asdf.InstallDependenciesForTests(t.Context(), "golang"))
+		require.NoFileExists(t, markerFile)
+	})
+}
+
+// TestHelperProcess registers the exec fake so that mocked commands run it.
+func TestHelperProcess(t *testing.T) {
+	t.Helper()
+	asdf.TestHelperProcess(t)
+}
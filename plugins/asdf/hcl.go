@@ -0,0 +1,218 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"strings"
+	"unicode"
+)
+
+// hclTokenKind classifies the tokens of hclTokens.
+type hclTokenKind int
+
+const (
+	// hclIdentifier is a bare word such as an attribute or block name.
+	hclIdentifier hclTokenKind = iota
+	// hclString is a quoted string without interpolations, holding its value.
+	hclString
+	// hclTemplate is a quoted string with interpolations or a heredoc.
+	hclTemplate
+	// hclNewline ends an attribute.
+	hclNewline
+	// hclPunctuation is any other single character, e.g. "=" or "{".
+	hclPunctuation
+)
+
+// hclToken is a token of an HCL file.
+type hclToken struct {
+	text string
+	kind hclTokenKind
+}
+
+// HCLStringAttribute returns the value of the first attribute called name
+// holding a plain string in the HCL file at path, at any block depth, and
+// whether there is one. It is a lightweight tokenizer skipping comments,
+// heredocs and interpolations rather than a full HCL parser, enough for
+// version pins such as required_version.
+func HCLStringAttribute(path, name string) (string, bool, error) {
+	data, err := ReadTextFile(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	tokens := hclTokens(data)
+
+	for index := 0; index+2 < len(tokens); index++ {
+		if tokens[index].kind != hclIdentifier || tokens[index].text != name {
+			continue
+		}
+
+		if index > 0 && tokens[index-1].kind != hclNewline && tokens[index-1].text != "{" {
+			continue
+		}
+
+		if tokens[index+1].text == "=" && tokens[index+2].kind == hclString {
+			return tokens[index+2].text, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// hclTokens splits HCL source into tokens, dropping comments and whitespace.
+func hclTokens(source string) []hclToken {
+	var tokens []hclToken
+
+	for index := 0; index < len(source); {
+		char := source[index]
+
+		switch {
+		case char == '\n':
+			tokens = append(tokens, hclToken{kind: hclNewline, text: "\n"})
+			index++
+		case char == ' ' || char == '\t' || char == '\r':
+			index++
+		case char == '#' || strings.HasPrefix(source[index:], "//"):
+			index = skipHCLLine(source, index)
+		case strings.HasPrefix(source[index:], "/*"):
+			end := strings.Index(source[index+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+
+			index += end + len("/**/")
+		case char == '"':
+			var token hclToken
+
+			token, index = readHCLString(source, index+1)
+			tokens = append(tokens, token)
+		case strings.HasPrefix(source[index:], "<<"):
+			tokens = append(tokens, hclToken{kind: hclTemplate})
+			index = skipHCLHeredoc(source, index)
+		case isHCLIdentifier(rune(char)):
+			start := index
+			for index < len(source) && isHCLIdentifier(rune(source[index])) {
+				index++
+			}
+
+			tokens = append(tokens, hclToken{kind: hclIdentifier, text: source[start:index]})
+		default:
+			tokens = append(tokens, hclToken{kind: hclPunctuation, text: string(char)})
+			index++
+		}
+	}
+
+	return tokens
+}
+
+// isHCLIdentifier reports whether char may appear in an identifier.
+func isHCLIdentifier(char rune) bool {
+	return char == '_' || char == '-' || char == '.' || unicode.IsLetter(char) || unicode.IsDigit(char)
+}
+
+// skipHCLLine returns the index of the newline ending the line at index.
+func skipHCLLine(source string, index int) int {
+	if end := strings.IndexByte(source[index:], '\n'); end >= 0 {
+		return index + end
+	}
+
+	return len(source)
+}
+
+// readHCLString reads the quoted string starting at index, just after its
+// opening quote, returning it and the index after its closing quote. Strings
+// with ${...} or %{...} sequences are templates whose value is not kept.
+func readHCLString(source string, index int) (hclToken, int) {
+	var value strings.Builder
+
+	template := false
+
+	for index < len(source) {
+		char := source[index]
+
+		switch {
+		case char == '"' || char == '\n':
+			kind := hclString
+			if template {
+				kind = hclTemplate
+			}
+
+			return hclToken{kind: kind, text: value.String()}, index + 1
+		case char == '\\' && index+1 < len(source):
+			value.WriteByte(unescapeHCL(source[index+1]))
+			index += 2
+		case (char == '$' || char == '%') && strings.HasPrefix(source[index+1:], "{"):
+			template = true
+			index = skipHCLBraces(source, index+1)
+		default:
+			value.WriteByte(char)
+			index++
+		}
+	}
+
+	return hclToken{kind: hclTemplate}, index
+}
+
+// unescapeHCL returns the character of the escape sequence "\<char>".
+func unescapeHCL(char byte) byte {
+	switch char {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	default:
+		return char
+	}
+}
+
+// skipHCLBraces returns the index after the brace closing the one at index.
+func skipHCLBraces(source string, index int) int {
+	depth := 0
+
+	for ; index < len(source); index++ {
+		switch source[index] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return index + 1
+			}
+		}
+	}
+
+	return index
+}
+
+// skipHCLHeredoc returns the index of the newline ending the heredoc starting
+// at index, i.e. after the line holding only its delimiter.
+func skipHCLHeredoc(source string, index int) int {
+	end := skipHCLLine(source, index)
+	delimiter := strings.TrimSpace(strings.TrimLeft(source[index:end], "<-"))
+
+	for index = end; index < len(source); {
+		lineEnd := skipHCLLine(source, index+1)
+		if strings.TrimSpace(source[index+1:lineEnd]) == delimiter {
+			return lineEnd
+		}
+
+		index = lineEnd
+	}
+
+	return len(source)
+}
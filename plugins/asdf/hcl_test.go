@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestHCLStringAttribute verifies string attributes are found at any block
+// depth while comments, heredocs, templates and nested values are skipped.
+func TestHCLStringAttribute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		found    bool
+	}{
+		{name: "top level", content: "version = \"1.2.3\"\n", expected: "1.2.3", found: true},
+		{name: "block", content: "tflint {\n  version = \">= 0.50\"\n}\n", expected: ">= 0.50", found: true},
+		{name: "one line block", content: "tflint { version = \"1.0\" }", expected: "1.0", found: true},
+		{name: "escapes", content: `version = "a\"b"`, expected: `a"b`, found: true},
+		{
+			name:     "comments",
+			content:  "# version = \"1\"\n// version = \"2\"\n/* version = \"3\"\n*/\nversion = \"4\" # trailing\n",
+			expected: "4",
+			found:    true,
+		},
+		{
+			name:     "heredoc",
+			content:  "text = <<-EOT\n  version = \"1\"\n  EOT\nversion = \"2\"\n",
+			expected: "2",
+			found:    true,
+		},
+		{
+			name:     "template",
+			content:  "version = \"${local.version}\"\nother = \"${format(\"%s\", \"x\")}\"\n",
+			expected: "",
+			found:    false,
+		},
+		{name: "nested value", content: "inputs = { name = other.version }\nlabel = \"version = 1\"\n"},
+		{name: "absent", content: "other = \"1.2.3\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config.hcl")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), asdf.CommonFilePermission))
+
+			value, found, err := asdf.HCLStringAttribute(path, "version")
+			require.NoError(t, err)
+			require.Equal(t, tt.found, found)
+			require.Equal(t, tt.expected, value)
+		})
+	}
+
+	_, _, err := asdf.HCLStringAttribute(filepath.Join(t.TempDir(), "missing.hcl"), "version")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
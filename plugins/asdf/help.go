@@ -0,0 +1,78 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// noConfigHelp is the configuration help of plugins without any.
+const noConfigHelp = "No additional configuration required"
+
+// HelpConfig renders the configuration help section of plugin: a table of its
+// HelpConfigEntries and of the settings registered with PluginEnv under its
+// name, followed by the free-text Config.
+func HelpConfig(plugin Plugin) string {
+	help := plugin.Help()
+
+	entries := slices.Clone(help.HelpConfigEntries)
+	for _, entry := range PluginEnv(plugin.Name()).ConfigEntries() {
+		if !slices.ContainsFunc(entries, func(listed ConfigEntry) bool { return listed.Name == entry.Name }) {
+			entries = append(entries, entry)
+		}
+	}
+
+	var sections []string
+	if len(entries) > 0 {
+		sections = append(sections, RenderConfigEntries(entries))
+	}
+
+	if config := strings.TrimSpace(help.Config); config != "" {
+		sections = append(sections, config)
+	}
+
+	if len(sections) == 0 {
+		return noConfigHelp
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+// RenderConfigEntries renders entries as the aligned "Environment variables:"
+// table of HelpConfig, showing "-" for variables without a default.
+func RenderConfigEntries(entries []ConfigEntry) string {
+	var builder strings.Builder
+
+	builder.WriteString("Environment variables:\n")
+
+	table := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	_, _ = table.Write([]byte("  NAME\tDEFAULT\tDESCRIPTION\n"))
+
+	for _, entry := range entries {
+		defaultValue := entry.Default
+		if defaultValue == "" {
+			defaultValue = "-"
+		}
+
+		_, _ = table.Write([]byte("  " + entry.Name + "\t" + defaultValue + "\t" + entry.Description + "\n"))
+	}
+
+	_ = table.Flush()
+
+	return strings.TrimRight(builder.String(), "\n ")
+}
@@ -0,0 +1,69 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// helpPlugin is a plugin named name with the given help.
+type helpPlugin struct {
+	mockPlugin
+
+	name string
+	help asdf.PluginHelp
+}
+
+func (plugin *helpPlugin) Name() string { return plugin.name }
+
+func (plugin *helpPlugin) Help() asdf.PluginHelp { return plugin.help }
+
+// TestHelpConfig verifies the configuration help lists HelpConfigEntries and
+// the PluginEnv settings once in an aligned table before the free text, and
+// falls back to the free text, or a default, without entries.
+func TestHelpConfig(t *testing.T) {
+	t.Parallel()
+
+	asdf.PluginEnv("helpconfig").Document(
+		asdf.EnvSetting{Key: "MIRROR", Type: asdf.EnvTypeString, Description: "Download mirror"},
+		asdf.EnvSetting{Key: "VERIFY", Type: asdf.EnvTypeBool, Default: "true", Description: "Verify downloads"},
+	)
+
+	plugin := &helpPlugin{name: "helpconfig", help: asdf.PluginHelp{
+		Config: "Reads .helpconfig-version files.\n",
+		HelpConfigEntries: []asdf.ConfigEntry{
+			{Name: "HELPCONFIG_HOME", Default: "~/.helpconfig", Description: "State directory"},
+			{Name: "ASDF_HELPCONFIG_MIRROR", Description: "Mirror of the downloads"},
+		},
+	}}
+
+	require.Equal(t, `Environment variables:
+  NAME                    DEFAULT        DESCRIPTION
+  HELPCONFIG_HOME         ~/.helpconfig  State directory
+  ASDF_HELPCONFIG_MIRROR  -              Mirror of the downloads
+  ASDF_HELPCONFIG_VERIFY  true           Verify downloads
+
+Reads .helpconfig-version files.`, asdf.HelpConfig(plugin))
+
+	legacy := &helpPlugin{name: "helpconfig-legacy", help: asdf.PluginHelp{Config: "Free text only"}}
+	require.Equal(t, "Free text only", asdf.HelpConfig(legacy))
+
+	require.Equal(t, "No additional configuration required", asdf.HelpConfig(&helpPlugin{name: "helpconfig-none"}))
+}
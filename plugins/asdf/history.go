@@ -0,0 +1,322 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HistoryDisableEnv disables the install history log when set to 1.
+	HistoryDisableEnv = "ASDF_HISTORY_DISABLE"
+	// HistoryFileName is the name of the history log below the data dir.
+	HistoryFileName = "history.jsonl"
+
+	// HistoryOperationInstall records an install.
+	HistoryOperationInstall = "install"
+	// HistoryOperationUninstall records an uninstall.
+	HistoryOperationUninstall = "uninstall"
+	// HistoryOperationUpdateToolVersions records a version pinned by update-tool-versions.
+	HistoryOperationUpdateToolVersions = "update-tool-versions"
+
+	// HistoryOutcomeSuccess marks a successful operation.
+	HistoryOutcomeSuccess = "success"
+	// HistoryOutcomeFailure marks a failed operation.
+	HistoryOutcomeFailure = "failure"
+
+	// historyKeptFiles is the number of rotated history files kept next to the log.
+	historyKeptFiles = 3
+	// historyErrorMaxLength bounds the error summary stored per entry.
+	historyErrorMaxLength = 200
+)
+
+// historyRotateSize is the size above which the history log is rotated.
+var historyRotateSize int64 = 1 << 20 //nolint:gochecknoglobals // used for mocking
+
+type (
+	// HistoryEntry is one line of the history log.
+	HistoryEntry struct {
+		Time       time.Time `json:"time"`
+		Operation  string    `json:"operation"`
+		Tool       string    `json:"tool"`
+		Version    string    `json:"version,omitempty"`
+		Outcome    string    `json:"outcome"`
+		Error      string    `json:"error,omitempty"`
+		User       string    `json:"user,omitempty"`
+		Command    string    `json:"command"`
+		DurationMS int64     `json:"durationMs"`
+	}
+
+	// HistoryFilter selects history entries; zero fields match everything.
+	HistoryFilter struct {
+		Since      time.Time
+		Tool       string
+		Operation  string
+		Limit      int
+		FailedOnly bool
+	}
+)
+
+// NewHistoryEntry describes an operation started at started that ended with err.
+func NewHistoryEntry(operation, tool, version string, started time.Time, err error) HistoryEntry {
+	entry := HistoryEntry{
+		Time:       started.UTC(),
+		Operation:  operation,
+		Tool:       tool,
+		Version:    version,
+		Outcome:    HistoryOutcomeSuccess,
+		Command:    strings.Join(os.Args, " "),
+		DurationMS: time.Since(started).Milliseconds(),
+	}
+
+	if current, userErr := user.Current(); userErr == nil {
+		entry.User = current.Username
+	}
+
+	if err != nil {
+		entry.Outcome = HistoryOutcomeFailure
+		entry.Error = summarizeHistoryError(err)
+	}
+
+	return entry
+}
+
+// RecordHistory appends an entry for the operation to the history log of the
+// current data layout unless HistoryDisableEnv is set. Failures to record are
+// logged and never fail the operation itself.
+func RecordHistory(operation, tool, version string, started time.Time, err error) {
+	if os.Getenv(HistoryDisableEnv) == "1" {
+		return
+	}
+
+	layout, layoutErr := CurrentLayout()
+	if layoutErr != nil {
+		Logger().Warn("failed to record history", "error", layoutErr)
+
+		return
+	}
+
+	entry := NewHistoryEntry(operation, tool, version, started, err)
+	if appendErr := AppendHistory(layout.HistoryFile(), entry); appendErr != nil {
+		Logger().Warn("failed to record history", "path", layout.HistoryFile(), "error", appendErr)
+	}
+}
+
+// AppendHistory appends entry to the history log at path. Writers serialize on
+// a lock file next to the log, which is rotated once it grows too large.
+func AppendHistory(path string, entry HistoryEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	line = append(line, '\n')
+
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("creating history directory: %w", err)
+	}
+
+	lock, err := acquireLockFile(context.Background(), path+".lock", "append history", false)
+	if err != nil {
+		return fmt.Errorf("locking history: %w", err)
+	}
+	defer releaseLock(lock)
+
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > historyRotateSize {
+		if err := rotateHistory(path); err != nil {
+			return err
+		}
+	}
+
+	file, err := OpenSharedFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, CommonFilePermission)
+	if err != nil {
+		return fmt.Errorf("opening history: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+
+	return nil
+}
+
+// ReadHistory returns the entries of the history log at path and its rotated
+// files, oldest first. Malformed lines are skipped; a missing log is empty.
+func ReadHistory(path string) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+
+	for index := historyKeptFiles; index >= 0; index-- {
+		fileEntries, err := readHistoryFile(rotatedHistoryPath(path, index))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		entries = append(entries, fileEntries...)
+	}
+
+	return entries, nil
+}
+
+// FilterHistory returns the entries matching filter, newest first.
+func FilterHistory(entries []HistoryEntry, filter HistoryFilter) []HistoryEntry {
+	var matched []HistoryEntry
+
+	for index := len(entries) - 1; index >= 0; index-- {
+		entry := entries[index]
+
+		switch {
+		case filter.Tool != "" && entry.Tool != filter.Tool,
+			filter.Operation != "" && entry.Operation != filter.Operation,
+			filter.FailedOnly && entry.Outcome != HistoryOutcomeFailure,
+			!filter.Since.IsZero() && entry.Time.Before(filter.Since):
+			continue
+		}
+
+		matched = append(matched, entry)
+
+		if filter.Limit > 0 && len(matched) == filter.Limit {
+			break
+		}
+	}
+
+	return matched
+}
+
+// WriteHistory renders entries to out, as indented JSON when asJSON is set and
+// as one line per entry, followed by its error when it failed, otherwise.
+func WriteHistory(out io.Writer, entries []HistoryEntry, asJSON bool) error {
+	if asJSON {
+		if entries == nil {
+			entries = []HistoryEntry{}
+		}
+
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(entries)
+	}
+
+	for _, entry := range entries {
+		duration := (time.Duration(entry.DurationMS) * time.Millisecond).Round(time.Millisecond)
+
+		_, err := fmt.Fprintf(out, "%s  %-20s %-20s %-12s %-7s %8s  %s\n",
+			entry.Time.Local().Format(time.DateTime),
+			entry.Operation,
+			entry.Tool,
+			entry.Version,
+			entry.Outcome,
+			duration,
+			entry.Command,
+		)
+		if err != nil {
+			return err
+		}
+
+		if entry.Error != "" {
+			if _, err := fmt.Fprintf(out, "    error: %s\n", entry.Error); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readHistoryFile parses the entries of a single history file.
+func readHistoryFile(path string) ([]HistoryEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []HistoryEntry
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			Logger().Debug("skipping malformed history line", "path", path, "error", err)
+
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading history %s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+// rotateHistory shifts path to path.1, path.1 to path.2 and so on, dropping
+// the oldest rotated file.
+func rotateHistory(path string) error {
+	if err := os.Remove(rotatedHistoryPath(path, historyKeptFiles)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("rotating history: %w", err)
+	}
+
+	for index := historyKeptFiles - 1; index >= 0; index-- {
+		err := os.Rename(rotatedHistoryPath(path, index), rotatedHistoryPath(path, index+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rotating history: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// rotatedHistoryPath returns the path of the index-th rotated history file,
+// the log itself for index 0.
+func rotatedHistoryPath(path string, index int) string {
+	if index == 0 {
+		return path
+	}
+
+	return path + "." + strconv.Itoa(index)
+}
+
+// summarizeHistoryError returns the first line of err, truncated.
+func summarizeHistoryError(err error) string {
+	summary, _, _ := strings.Cut(err.Error(), "\n")
+	summary = strings.TrimSpace(summary)
+
+	if runes := []rune(summary); len(runes) > historyErrorMaxLength {
+		summary = string(runes[:historyErrorMaxLength]) + "..."
+	}
+
+	return summary
+}
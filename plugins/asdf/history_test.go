@@ -0,0 +1,218 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+var errTestHistoryInstallFailed = errors.New("checksum mismatch for terraform_1.9.2.zip\nsee log for details")
+
+// TestAppendHistoryConcurrent verifies concurrent appends never interleave lines.
+func TestAppendHistoryConcurrent(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), asdf.HistoryFileName)
+
+	const writers = 32
+
+	var wg sync.WaitGroup
+	for index := range writers {
+		wg.Go(func() {
+			entry := asdf.NewHistoryEntry(asdf.HistoryOperationInstall, "tool", strconv.Itoa(index), time.Now(), nil)
+			require.NoError(t, asdf.AppendHistory(path, entry))
+		})
+	}
+
+	wg.Wait()
+
+	entries, err := asdf.ReadHistory(path)
+	require.NoError(t, err)
+	require.Len(t, entries, writers)
+
+	versions := make(map[string]bool, writers)
+	for _, entry := range entries {
+		versions[entry.Version] = true
+	}
+
+	require.Len(t, versions, writers)
+}
+
+// TestAppendHistoryRotates verifies the log is rotated and old files are dropped.
+func TestAppendHistoryRotates(t *testing.T) {
+	asdf.SetHistoryRotateSizeForTests(t, 1)
+
+	path := filepath.Join(t.TempDir(), asdf.HistoryFileName)
+
+	for index := range 6 {
+		entry := asdf.NewHistoryEntry(asdf.HistoryOperationInstall, "tool", strconv.Itoa(index), time.Now(), nil)
+		require.NoError(t, asdf.AppendHistory(path, entry))
+	}
+
+	require.FileExists(t, path+".1")
+	require.FileExists(t, path+".3")
+	require.NoFileExists(t, path+".4")
+
+	entries, err := asdf.ReadHistory(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.Equal(t, "2", entries[0].Version)
+	require.Equal(t, "5", entries[3].Version)
+}
+
+// TestFilterHistory verifies filtering, limits and newest-first ordering.
+func TestFilterHistory(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []asdf.HistoryEntry{
+		{Time: base, Operation: asdf.HistoryOperationInstall, Tool: "terraform", Version: "1.9.1", Outcome: asdf.HistoryOutcomeSuccess},
+		{Time: base.Add(time.Hour), Operation: asdf.HistoryOperationInstall, Tool: "golang", Version: "1.25.0", Outcome: asdf.HistoryOutcomeFailure},
+		{Time: base.Add(2 * time.Hour), Operation: asdf.HistoryOperationUninstall, Tool: "terraform", Version: "1.9.1", Outcome: asdf.HistoryOutcomeSuccess},
+		{Time: base.Add(3 * time.Hour), Operation: asdf.HistoryOperationInstall, Tool: "terraform", Version: "1.9.2", Outcome: asdf.HistoryOutcomeSuccess},
+	}
+
+	versions := func(matched []asdf.HistoryEntry) []string {
+		out := make([]string, 0, len(matched))
+		for _, entry := range matched {
+			out = append(out, entry.Operation+" "+entry.Tool+" "+entry.Version)
+		}
+
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		filter asdf.HistoryFilter
+		want   []string
+	}{
+		{
+			name: "everything newest first",
+			want: []string{
+				"install terraform 1.9.2", "uninstall terraform 1.9.1",
+				"install golang 1.25.0", "install terraform 1.9.1",
+			},
+		},
+		{
+			name:   "by tool",
+			filter: asdf.HistoryFilter{Tool: "terraform"},
+			want:   []string{"install terraform 1.9.2", "uninstall terraform 1.9.1", "install terraform 1.9.1"},
+		},
+		{
+			name:   "by operation",
+			filter: asdf.HistoryFilter{Operation: asdf.HistoryOperationUninstall},
+			want:   []string{"uninstall terraform 1.9.1"},
+		},
+		{
+			name:   "failed only",
+			filter: asdf.HistoryFilter{FailedOnly: true},
+			want:   []string{"install golang 1.25.0"},
+		},
+		{
+			name:   "since and limit",
+			filter: asdf.HistoryFilter{Since: base.Add(time.Hour), Limit: 2},
+			want:   []string{"install terraform 1.9.2", "uninstall terraform 1.9.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, versions(asdf.FilterHistory(entries, tt.filter)))
+		})
+	}
+}
+
+// TestRecordHistory verifies operations are recorded in the data dir, including failures.
+func TestRecordHistory(t *testing.T) {
+	t.Run("records failed installs with an error summary", func(t *testing.T) {
+		dataDir := t.TempDir()
+		t.Setenv(asdf.DataDirEnv, dataDir)
+		t.Setenv(asdf.HistoryDisableEnv, "")
+
+		started := time.Now().Add(-1500 * time.Millisecond)
+		asdf.RecordHistory(asdf.HistoryOperationInstall, "terraform", "1.9.2", started, errTestHistoryInstallFailed)
+
+		entries, err := asdf.ReadHistory(filepath.Join(dataDir, asdf.HistoryFileName))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		entry := entries[0]
+		require.Equal(t, asdf.HistoryOperationInstall, entry.Operation)
+		require.Equal(t, "terraform", entry.Tool)
+		require.Equal(t, "1.9.2", entry.Version)
+		require.Equal(t, asdf.HistoryOutcomeFailure, entry.Outcome)
+		require.Equal(t, "checksum mismatch for terraform_1.9.2.zip", entry.Error)
+		require.Equal(t, strings.Join(os.Args, " "), entry.Command)
+		require.GreaterOrEqual(t, entry.DurationMS, int64(1500))
+	})
+
+	t.Run("can be disabled", func(t *testing.T) {
+		dataDir := t.TempDir()
+		t.Setenv(asdf.DataDirEnv, dataDir)
+		t.Setenv(asdf.HistoryDisableEnv, "1")
+
+		asdf.RecordHistory(asdf.HistoryOperationUninstall, "terraform", "1.9.2", time.Now(), nil)
+
+		require.NoFileExists(t, filepath.Join(dataDir, asdf.HistoryFileName))
+	})
+}
+
+// TestWriteHistory verifies the text and JSON renderings.
+func TestWriteHistory(t *testing.T) {
+	t.Parallel()
+
+	entries := []asdf.HistoryEntry{{
+		Time:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Operation:  asdf.HistoryOperationInstall,
+		Tool:       "terraform",
+		Version:    "1.9.2",
+		Outcome:    asdf.HistoryOutcomeFailure,
+		Error:      "boom",
+		Command:    "universal-asdf-plugin install terraform 1.9.2",
+		DurationMS: 1250,
+	}}
+
+	var text bytes.Buffer
+	require.NoError(t, asdf.WriteHistory(&text, entries, false))
+	require.Contains(t, text.String(), "terraform")
+	require.Contains(t, text.String(), "1.25s")
+	require.Contains(t, text.String(), "    error: boom\n")
+
+	var encoded bytes.Buffer
+	require.NoError(t, asdf.WriteHistory(&encoded, entries, true))
+
+	var decoded []asdf.HistoryEntry
+	require.NoError(t, json.Unmarshal(encoded.Bytes(), &decoded))
+	require.Equal(t, entries, decoded)
+
+	encoded.Reset()
+	require.NoError(t, asdf.WriteHistory(&encoded, nil, true))
+	require.Equal(t, "[]\n", encoded.String())
+}
@@ -43,7 +43,7 @@ type PluginInstaller struct {
 }
 
 // NewPluginInstaller creates a new PluginInstaller with the given executable path.
-// If pluginsDir is empty, it will be determined from ASDF_DATA_DIR or ~/.asdf/plugins.
+// If pluginsDir is empty, it is the plugins directory of the data directory.
 func NewPluginInstaller(execPath, pluginsDir string) (*PluginInstaller, error) {
 	resolved, err := filepath.EvalSymlinks(execPath)
 	if err != nil {
@@ -52,7 +52,10 @@ func NewPluginInstaller(execPath, pluginsDir string) (*PluginInstaller, error) {
 
 	pluginsDirToUse := pluginsDir
 	if pluginsDir == "" {
-		pluginsDirToUse = GetPluginsDir()
+		pluginsDirToUse, err = GetPluginsDir()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &PluginInstaller{
@@ -61,19 +64,14 @@ func NewPluginInstaller(execPath, pluginsDir string) (*PluginInstaller, error) {
 	}, nil
 }
 
-// GetPluginsDir returns the asdf plugins directory.
-// It checks ASDF_DATA_DIR first, then falls back to ~/.asdf/plugins.
-func GetPluginsDir() string {
-	if dataDir := os.Getenv("ASDF_DATA_DIR"); dataDir != "" {
-		return filepath.Join(dataDir, "plugins")
-	}
-
-	home, err := os.UserHomeDir()
+// GetPluginsDir returns the plugins directory of the asdf data directory (see DataDir).
+func GetPluginsDir() (string, error) {
+	layout, err := CurrentLayout()
 	if err != nil {
-		return filepath.Join(".", ".asdf", "plugins")
+		return "", err
 	}
 
-	return filepath.Join(home, ".asdf", "plugins")
+	return layout.PluginsDir, nil
 }
 
 // Install installs the specified plugin by creating wrapper scripts in the bin directory.
@@ -201,69 +199,3 @@ export ASDF_PLUGIN_NAME="%s"
 exec "%s" "%s" "$@"
 `, pluginName, pi.ExecPath, command)
 }
-
-// AvailablePlugins returns the list of plugins that can be installed.
-func AvailablePlugins() []string {
-	return []string{
-		"argo",
-		"argocd",
-		"argo-rollouts",
-		"aws-nuke",
-		"aws-sso-cli",
-		"awscli",
-		"buf",
-		"checkov",
-		"cmake",
-		"cosign",
-		"doctl",
-		"gcloud",
-		"ginkgo",
-		"github-cli",
-		"gitleaks",
-		"gitsign",
-		"golang",
-		"golangci-lint",
-		"goreleaser",
-		"grype",
-		"helm",
-		"jq",
-		"k9s",
-		"kind",
-		"ko",
-		"kubectl",
-		"lazygit",
-		"linkerd",
-		"nerdctl",
-		"nodejs",
-		"opentofu",
-		"protoc",
-		"protoc-gen-go",
-		"protoc-gen-go-grpc",
-		"protoc-gen-grpc-web",
-		"pipx",
-		"protolint",
-		"python",
-		"rust",
-		"sccache",
-		"shellcheck",
-		"shfmt",
-		"sops",
-		"sqlc",
-		"syft",
-		"tekton-cli",
-		"telepresence",
-		"terraform",
-		"terragrunt",
-		"terrascan",
-		"tfupdate",
-		"tflint",
-		"traefik",
-		"trivy",
-		"upx",
-		"uv",
-		"velero",
-		"vultr-cli",
-		"yq",
-		"zig",
-	}
-}
@@ -127,15 +127,31 @@ func TestNewPluginInstaller(t *testing.T) {
 func TestGetPluginsDir(t *testing.T) {
 	t.Run("uses ASDF_DATA_DIR when set", func(t *testing.T) {
 		t.Setenv("ASDF_DATA_DIR", "/custom/asdf")
-		require.Equal(t, "/custom/asdf/plugins", asdf.GetPluginsDir())
+
+		pluginsDir, err := asdf.GetPluginsDir()
+		require.NoError(t, err)
+		require.Equal(t, "/custom/asdf/plugins", pluginsDir)
+	})
+
+	t.Run("uses ASDF_DIR when ASDF_DATA_DIR is unset", func(t *testing.T) {
+		t.Setenv("ASDF_DATA_DIR", "")
+		t.Setenv("ASDF_DIR", "/opt/asdf")
+
+		pluginsDir, err := asdf.GetPluginsDir()
+		require.NoError(t, err)
+		require.Equal(t, "/opt/asdf/plugins", pluginsDir)
 	})
 
 	t.Run("falls back to ~/.asdf/plugins", func(t *testing.T) {
 		t.Setenv("ASDF_DATA_DIR", "")
+		t.Setenv("ASDF_DIR", "")
 
 		home, err := os.UserHomeDir()
 		require.NoError(t, err)
-		require.Equal(t, filepath.Join(home, ".asdf", "plugins"), asdf.GetPluginsDir())
+
+		pluginsDir, err := asdf.GetPluginsDir()
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(home, ".asdf", "plugins"), pluginsDir)
 	})
 }
 
@@ -325,15 +341,6 @@ func TestPluginInstallerOtherMethods(t *testing.T) {
 		require.ElementsMatch(t, []string{"golang", "nodejs"}, plugins)
 	})
 
-	t.Run("AvailablePlugins", func(t *testing.T) {
-		t.Parallel()
-
-		plugins := asdf.AvailablePlugins()
-		require.Contains(t, plugins, "golang")
-		require.Contains(t, plugins, "python")
-		require.GreaterOrEqual(t, len(plugins), 40)
-	})
-
 	t.Run("InstallAll error", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,165 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// InstalledVersion is a version of a tool found in the installs directory.
+type InstalledVersion struct {
+	// Version is the name of the install directory.
+	Version string
+	// Path is the install directory.
+	Path string
+	// Current reports whether the nearest .tool-versions selects this version.
+	Current bool
+	// Incomplete reports that no executable was found in any bin path, e.g.
+	// because the install was interrupted.
+	Incomplete bool
+}
+
+// InstalledTools returns the tools with an installs directory, sorted by name.
+func InstalledTools() ([]string, error) {
+	layout, err := CurrentLayout()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(layout.InstallsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", layout.InstallsDir, err)
+	}
+
+	tools := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			tools = append(tools, entry.Name())
+		}
+	}
+
+	slices.Sort(tools)
+
+	return tools, nil
+}
+
+// ListInstalled returns the installed versions of tool, newest first. current
+// is the selected version, binPaths the install relative directories searched
+// for executables; "bin" is used when empty.
+func ListInstalled(tool, current string, binPaths []string) ([]InstalledVersion, error) {
+	layout, err := CurrentLayout()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(layout.InstallsDir, tool))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading installs of %s: %w", tool, err)
+	}
+
+	if len(binPaths) == 0 {
+		binPaths = []string{"bin"}
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && !IsInstallLeftover(entry.Name()) {
+			versions = append(versions, entry.Name())
+		}
+	}
+
+	SortVersions(versions)
+	slices.Reverse(versions)
+
+	installed := make([]InstalledVersion, 0, len(versions))
+	for _, version := range versions {
+		installPath := layout.InstallPath(tool, version)
+
+		installed = append(installed, InstalledVersion{
+			Version:    version,
+			Path:       installPath,
+			Current:    version == current,
+			Incomplete: !hasExecutable(installPath, binPaths),
+		})
+	}
+
+	return installed, nil
+}
+
+// hasExecutable reports whether any of the bin paths below installPath holds
+// an executable file.
+func hasExecutable(installPath string, binPaths []string) bool {
+	for _, binPath := range binPaths {
+		entries, err := os.ReadDir(filepath.Join(installPath, binPath))
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || info.IsDir() {
+				continue
+			}
+
+			if runtime.GOOS == "windows" || info.Mode()&ExecutablePermissionMask != 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// WriteInstalled prints versions in the asdf list format, marking the current
+// version with an asterisk and incomplete installs with a note.
+func WriteInstalled(out io.Writer, versions []InstalledVersion) error {
+	var builder strings.Builder
+
+	for _, installed := range versions {
+		marker := " "
+		if installed.Current {
+			marker = "*"
+		}
+
+		builder.WriteString(" " + marker + installed.Version)
+
+		if installed.Incomplete {
+			builder.WriteString(" (incomplete)")
+		}
+
+		builder.WriteString("\n")
+	}
+
+	if _, err := io.WriteString(out, builder.String()); err != nil {
+		return fmt.Errorf("writing installed versions: %w", err)
+	}
+
+	return nil
+}
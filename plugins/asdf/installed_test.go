@@ -0,0 +1,100 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+func TestListInstalled(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("ASDF_DATA_DIR", dataDir)
+	t.Setenv("ASDF_INSTALLS_DIR", "")
+
+	tools, err := asdf.InstalledTools()
+	require.NoError(t, err)
+	require.Empty(t, tools)
+
+	installs := filepath.Join(dataDir, "installs")
+	for _, version := range []string{"1.9.0", "1.10.0", "1.2.0"} {
+		bin := filepath.Join(installs, "golang", version, "go", "bin")
+		require.NoError(t, os.MkdirAll(bin, asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "go"), nil, asdf.CommonExecutablePermission))
+	}
+
+	// An interrupted install leaves the directory without executables.
+	require.NoError(t, os.MkdirAll(filepath.Join(installs, "golang", "1.11.0", "go", "bin"), asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(filepath.Join(installs, "golang", "1.11.0", "go", "bin", "README"),
+		nil, asdf.CommonFilePermission))
+	require.NoError(t, os.MkdirAll(filepath.Join(installs, "jq", "1.7.1"), asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(filepath.Join(installs, "stray-file"), nil, asdf.CommonFilePermission))
+
+	tools, err = asdf.InstalledTools()
+	require.NoError(t, err)
+	require.Equal(t, []string{"golang", "jq"}, tools)
+
+	versions, err := asdf.ListInstalled("golang", "1.10.0", []string{filepath.Join("go", "bin")})
+	require.NoError(t, err)
+	require.Len(t, versions, 4)
+	require.Equal(t, filepath.Join(installs, "golang", "1.11.0"), versions[0].Path)
+
+	var out bytes.Buffer
+	require.NoError(t, asdf.WriteInstalled(&out, versions))
+	require.Equal(t, "  1.11.0 (incomplete)\n *1.10.0\n  1.9.0\n  1.2.0\n", out.String())
+
+	versions, err = asdf.ListInstalled("jq", "", nil)
+	require.NoError(t, err)
+	require.Equal(t, []asdf.InstalledVersion{{
+		Version:    "1.7.1",
+		Path:       filepath.Join(installs, "jq", "1.7.1"),
+		Incomplete: true,
+	}}, versions)
+
+	versions, err = asdf.ListInstalled("kubectl", "", nil)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
+
+func TestToolchainBinaryNearestToolVersions(t *testing.T) {
+	dataDir, project := t.TempDir(), t.TempDir()
+	t.Setenv("ASDF_DATA_DIR", dataDir)
+	t.Setenv("ASDF_INSTALLS_DIR", "")
+
+	wd := filepath.Join(project, "cmd", "server")
+	require.NoError(t, os.MkdirAll(wd, asdf.CommonDirectoryPermission))
+	asdf.MockOSForTests(t, wd, t.TempDir())
+
+	for _, version := range []string{"1.9.0", "1.10.0"} {
+		bin := filepath.Join(dataDir, "installs", "jq", version, "bin")
+		require.NoError(t, os.MkdirAll(bin, asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "jq"), nil, asdf.CommonExecutablePermission))
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".tool-versions"),
+		[]byte("jq 1.9.0\n"), asdf.CommonFilePermission))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "cmd", ".tool-versions"),
+		[]byte("golang 1.22.0\n"), asdf.CommonFilePermission))
+
+	require.Equal(t,
+		filepath.Join(dataDir, "installs", "jq", "1.9.0", "bin", "jq"),
+		asdf.ToolchainBinary("jq", filepath.Join("bin", "jq")))
+}
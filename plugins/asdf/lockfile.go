@@ -0,0 +1,238 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// ToolVersionsLockFileName is the lockfile pinning the tools of .tool-versions.
+	ToolVersionsLockFileName = ".tool-versions.lock"
+	// LockfileSchemaVersion is the lockfile format written by WriteLockfile.
+	LockfileSchemaVersion = 1
+)
+
+var (
+	// errLockfileSchemaUnsupported is returned for lockfiles written by a newer format.
+	errLockfileSchemaUnsupported = errors.New("unsupported lockfile schema")
+	// errLockfileToolMissing is returned when a pinned tool is not in the lockfile.
+	errLockfileToolMissing = errors.New("not in lockfile")
+	// errLockfileDrift is returned when an install does not match the lockfile.
+	errLockfileDrift = errors.New("lockfile drift")
+	// errLockfileUnhashed is returned for locked tools without the hashes their
+	// download is verified against.
+	errLockfileUnhashed = errors.New("no locked hash")
+)
+
+type (
+	// Lockfile pins the exact versions, download URLs and hashes of the tools
+	// of a .tool-versions file.
+	Lockfile struct {
+		// Schema is the lockfile format version, see LockfileSchemaVersion.
+		Schema int `json:"schema"`
+		// Tools are the locked tools, sorted by name.
+		Tools []LockedTool `json:"tools"`
+	}
+
+	// LockedTool is a tool version pinned in a Lockfile.
+	LockedTool struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		// SHA256 is the hash of the download as recorded in .tool-sums.
+		SHA256 string `json:"sha256,omitempty"`
+		// Artifacts are the downloaded files, sorted by name, when the plugin
+		// implements PluginWithArtifactResolver.
+		Artifacts []LockedArtifact `json:"artifacts,omitempty"`
+	}
+
+	// LockedArtifact is a downloaded file of a LockedTool.
+	LockedArtifact struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+		// SHA256 is the hex encoded checksum, known once the file was downloaded.
+		SHA256 string `json:"sha256,omitempty"`
+	}
+)
+
+// LockTool returns the lockfile entry of plugin version. The checksums of the
+// artifacts already in downloadPath are computed; downloadHash is the hash of
+// the whole download as recorded in .tool-sums, if known.
+func LockTool(ctx context.Context, plugin Plugin, version, downloadPath, downloadHash string) (LockedTool, error) {
+	tool := LockedTool{Name: plugin.Name(), Version: version, SHA256: downloadHash}
+
+	resolver, ok := plugin.(PluginWithArtifactResolver)
+	if !ok {
+		return tool, nil
+	}
+
+	artifacts, err := resolver.ResolveArtifacts(ctx, version)
+	if err != nil {
+		return LockedTool{}, fmt.Errorf("resolving %s %s artifacts: %w", plugin.Name(), version, err)
+	}
+
+	for _, artifact := range artifacts {
+		locked := LockedArtifact{Name: artifact.Name, URL: artifact.URL}
+
+		if sum, err := FileSHA256(filepath.Join(downloadPath, artifact.Name)); err == nil {
+			locked.SHA256 = sum
+		}
+
+		tool.Artifacts = append(tool.Artifacts, locked)
+	}
+
+	return tool, nil
+}
+
+// CheckHashed returns an error unless tool records the hash of its download
+// and of each of its artifacts, which an install from the lockfile needs.
+func (tool *LockedTool) CheckHashed() error {
+	if tool.SHA256 == "" {
+		return fmt.Errorf("%w: %s %s, run lock again", errLockfileUnhashed, tool.Name, tool.Version)
+	}
+
+	for _, artifact := range tool.Artifacts {
+		if artifact.SHA256 == "" {
+			return fmt.Errorf("%w: %s %s artifact %s, run lock again", errLockfileUnhashed, tool.Name, tool.Version, artifact.Name)
+		}
+	}
+
+	return nil
+}
+
+// VerifyLockedTool checks that tool is hashed, that plugin still resolves its
+// artifacts and that the files in downloadPath have the locked checksums.
+func VerifyLockedTool(ctx context.Context, plugin Plugin, tool LockedTool, downloadPath string) error {
+	if err := tool.CheckHashed(); err != nil {
+		return err
+	}
+
+	current, err := LockTool(ctx, plugin, tool.Version, downloadPath, tool.SHA256)
+	if err != nil {
+		return err
+	}
+
+	for _, locked := range tool.Artifacts {
+		index := slices.IndexFunc(current.Artifacts, func(artifact LockedArtifact) bool {
+			return artifact.Name == locked.Name
+		})
+		if index < 0 {
+			return fmt.Errorf("%w: %s %s no longer downloads %s", errLockfileDrift, tool.Name, tool.Version, locked.Name)
+		}
+
+		artifact := current.Artifacts[index]
+		if artifact.URL != locked.URL {
+			return fmt.Errorf("%w: %s %s downloads %s from %s, locked %s",
+				errLockfileDrift, tool.Name, tool.Version, locked.Name, artifact.URL, locked.URL)
+		}
+
+		if artifact.SHA256 != locked.SHA256 {
+			return fmt.Errorf("%w: %s %s artifact %s has checksum %q, locked %s",
+				errLockfileDrift, tool.Name, tool.Version, locked.Name, artifact.SHA256, locked.SHA256)
+		}
+	}
+
+	return nil
+}
+
+// Tool returns the locked entry of the named tool.
+func (lockfile *Lockfile) Tool(name string) (LockedTool, bool) {
+	index := slices.IndexFunc(lockfile.Tools, func(tool LockedTool) bool {
+		return tool.Name == name
+	})
+	if index < 0 {
+		return LockedTool{}, false
+	}
+
+	return lockfile.Tools[index], true
+}
+
+// CheckPinned returns an error naming the tools of pinned, e.g. parsed from
+// .tool-versions, that are missing from the lockfile.
+func (lockfile *Lockfile) CheckPinned(pinned []string) error {
+	var missing []string
+
+	for _, name := range pinned {
+		if _, ok := lockfile.Tool(name); !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	slices.Sort(missing)
+
+	return fmt.Errorf("%w: %s, run lock to add them", errLockfileToolMissing, strings.Join(missing, ", "))
+}
+
+// WriteLockfile writes lockfile to path, sorted so that it diffs cleanly.
+func WriteLockfile(path string, lockfile Lockfile) error {
+	lockfile.Schema = LockfileSchemaVersion
+	lockfile.Tools = slices.Clone(lockfile.Tools)
+
+	for i := range lockfile.Tools {
+		lockfile.Tools[i].Artifacts = slices.Clone(lockfile.Tools[i].Artifacts)
+		slices.SortFunc(lockfile.Tools[i].Artifacts, func(a, b LockedArtifact) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+	}
+
+	slices.SortFunc(lockfile.Tools, func(a, b LockedTool) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	data, err := json.MarshalIndent(lockfile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // the lockfile is committed and world readable
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ReadLockfile reads the lockfile at path.
+func ReadLockfile(path string) (Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Lockfile{}, err
+	}
+
+	var lockfile Lockfile
+	if err := json.Unmarshal(data, &lockfile); err != nil {
+		return Lockfile{}, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	if lockfile.Schema > LockfileSchemaVersion {
+		return Lockfile{}, fmt.Errorf("%w: %s has schema %d, newest supported is %d",
+			errLockfileSchemaUnsupported, path, lockfile.Schema, LockfileSchemaVersion)
+	}
+
+	return lockfile, nil
+}
@@ -0,0 +1,172 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// resolvingPlugin downloads a single archive from a configurable mirror.
+type resolvingPlugin struct {
+	mockPlugin
+
+	mirror string
+}
+
+func (plugin *resolvingPlugin) ResolveArtifacts(_ context.Context, version string) ([]asdf.Artifact, error) {
+	name := "tool-" + version + ".tar.gz"
+
+	return []asdf.Artifact{{Name: name, URL: plugin.mirror + "/" + name}}, nil
+}
+
+// TestWriteLockfile verifies lockfiles are written sorted and read back unchanged.
+func TestWriteLockfile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), asdf.ToolVersionsLockFileName)
+
+	require.NoError(t, asdf.WriteLockfile(path, asdf.Lockfile{Tools: []asdf.LockedTool{
+		{Name: "zig", Version: "0.13.0"},
+		{Name: "gcloud", Version: "480.0.0", Artifacts: []asdf.LockedArtifact{
+			{Name: "b.tar.gz", URL: "https://example.com/b.tar.gz"},
+			{Name: "a.tar.gz", URL: "https://example.com/a.tar.gz", SHA256: "abc"},
+		}},
+	}}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "schema": 1,
+  "tools": [
+    {
+      "name": "gcloud",
+      "version": "480.0.0",
+      "artifacts": [
+        {
+          "name": "a.tar.gz",
+          "url": "https://example.com/a.tar.gz",
+          "sha256": "abc"
+        },
+        {
+          "name": "b.tar.gz",
+          "url": "https://example.com/b.tar.gz"
+        }
+      ]
+    },
+    {
+      "name": "zig",
+      "version": "0.13.0"
+    }
+  ]
+}
+`, string(data))
+
+	lockfile, err := asdf.ReadLockfile(path)
+	require.NoError(t, err)
+
+	tool, ok := lockfile.Tool("zig")
+	require.True(t, ok)
+	require.Equal(t, "0.13.0", tool.Version)
+
+	_, ok = lockfile.Tool("golang")
+	require.False(t, ok)
+
+	require.NoError(t, lockfile.CheckPinned([]string{"zig", "gcloud"}))
+
+	err = lockfile.CheckPinned([]string{"zig", "nodejs", "golang"})
+	require.ErrorIs(t, err, asdf.ErrLockfileToolMissingForTests())
+	require.Contains(t, err.Error(), "golang, nodejs")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"schema": 2, "tools": []}`), 0o600))
+
+	_, err = asdf.ReadLockfile(path)
+	require.ErrorIs(t, err, asdf.ErrLockfileSchemaUnsupportedForTests())
+}
+
+// TestLockTool verifies locked artifacts carry checksums and detect drift.
+func TestLockTool(t *testing.T) {
+	t.Parallel()
+
+	downloadPath := t.TempDir()
+	plugin := &resolvingPlugin{mirror: "https://mirror.example.com"}
+
+	tool, err := asdf.LockTool(t.Context(), plugin, "1.2.3", downloadPath, "sha256:tree")
+	require.NoError(t, err)
+	require.Equal(t, asdf.LockedTool{
+		Name:    "mock",
+		Version: "1.2.3",
+		SHA256:  "sha256:tree",
+		Artifacts: []asdf.LockedArtifact{
+			{Name: "tool-1.2.3.tar.gz", URL: "https://mirror.example.com/tool-1.2.3.tar.gz"},
+		},
+	}, tool)
+
+	archive := filepath.Join(downloadPath, "tool-1.2.3.tar.gz")
+	require.NoError(t, os.WriteFile(archive, []byte("archive"), 0o600))
+
+	tool, err = asdf.LockTool(t.Context(), plugin, "1.2.3", downloadPath, "sha256:tree")
+	require.NoError(t, err)
+	require.Equal(t, "0eb3e36bfb24dcd9bb1d1bece1531216b59539a8fde17ee80224af0653c92aa3", tool.Artifacts[0].SHA256)
+	require.NoError(t, asdf.VerifyLockedTool(t.Context(), plugin, tool, downloadPath))
+
+	require.NoError(t, os.WriteFile(archive, []byte("tampered"), 0o600))
+
+	err = asdf.VerifyLockedTool(t.Context(), plugin, tool, downloadPath)
+	require.ErrorIs(t, err, asdf.ErrLockfileDriftForTests())
+	require.Contains(t, err.Error(), "tool-1.2.3.tar.gz has checksum")
+
+	moved := &resolvingPlugin{mirror: "https://other.example.com"}
+
+	err = asdf.VerifyLockedTool(t.Context(), moved, tool, downloadPath)
+	require.ErrorIs(t, err, asdf.ErrLockfileDriftForTests())
+	require.Contains(t, err.Error(), "from https://other.example.com/tool-1.2.3.tar.gz")
+
+	tool, err = asdf.LockTool(t.Context(), &mockPlugin{}, "1.2.3", downloadPath, "")
+	require.NoError(t, err)
+	require.Empty(t, tool.Artifacts)
+}
+
+// TestVerifyLockedToolUnhashed verifies tools locked without the hash of their
+// download or of an artifact are refused.
+func TestVerifyLockedToolUnhashed(t *testing.T) {
+	t.Parallel()
+
+	downloadPath := t.TempDir()
+	plugin := &resolvingPlugin{mirror: "https://mirror.example.com"}
+
+	require.NoError(t, os.WriteFile(filepath.Join(downloadPath, "tool-1.2.3.tar.gz"), []byte("archive"), 0o600))
+
+	tool, err := asdf.LockTool(t.Context(), plugin, "1.2.3", downloadPath, "")
+	require.NoError(t, err)
+
+	err = asdf.VerifyLockedTool(t.Context(), plugin, tool, downloadPath)
+	require.ErrorIs(t, err, asdf.ErrLockfileUnhashedForTests())
+	require.ErrorContains(t, err, "mock 1.2.3, run lock again")
+
+	tool.SHA256 = "sha256:tree"
+	tool.Artifacts[0].SHA256 = ""
+
+	err = asdf.VerifyLockedTool(t.Context(), plugin, tool, downloadPath)
+	require.ErrorIs(t, err, asdf.ErrLockfileUnhashedForTests())
+	require.ErrorContains(t, err, "artifact tool-1.2.3.tar.gz")
+}
@@ -0,0 +1,108 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/filelock"
+)
+
+const (
+	// LockTimeoutEnv bounds how long to wait for a lock, as a Go duration.
+	LockTimeoutEnv = "ASDF_LOCK_TIMEOUT"
+	// defaultLockTimeout is the lock wait used when LockTimeoutEnv is unset or invalid.
+	defaultLockTimeout = 10 * time.Minute
+)
+
+// LockTimeout returns how long to wait for a lock held by another process.
+func LockTimeout() time.Duration {
+	value := os.Getenv(LockTimeoutEnv)
+	if value == "" {
+		return defaultLockTimeout
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		Logger().Warn("ignoring invalid lock timeout", "env", LockTimeoutEnv, "value", value)
+
+		return defaultLockTimeout
+	}
+
+	return timeout
+}
+
+// InstallLockName returns the name of the lock serializing installs of a tool version.
+func InstallLockName(tool, version string) string {
+	return "install-" + tool + "-" + version
+}
+
+// AcquireLock takes the named lock in the data layout locks directory, waiting
+// at most LockTimeout. Errors name the holder and how to break a stale lock.
+func AcquireLock(ctx context.Context, name, purpose string, shared bool) (*filelock.Lock, error) {
+	layout, err := CurrentLayout()
+	if err != nil {
+		return nil, err
+	}
+
+	return acquireLockFile(ctx, filepath.Join(layout.LocksDir(), LockFileName(name)), purpose, shared)
+}
+
+// LockFileName returns the file name of the named lock.
+func LockFileName(name string) string {
+	return strings.ReplaceAll(name, string(os.PathSeparator), "_") + filelock.Suffix
+}
+
+// acquireLockFile takes the lock at path, waiting at most LockTimeout.
+func acquireLockFile(ctx context.Context, path, purpose string, shared bool) (*filelock.Lock, error) {
+	if err := EnsureSharedDir(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("creating lock directory: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, LockTimeout())
+	defer cancel()
+
+	lock, err := filelock.Acquire(ctx, path, filelock.Options{Purpose: purpose, Shared: shared})
+	if err != nil {
+		var heldErr *filelock.HeldError
+		if errors.As(err, &heldErr) {
+			return nil, fmt.Errorf(
+				"%w; set %s to wait longer, or run 'universal-asdf-plugin locks break %s' if the holder is gone",
+				err, LockTimeoutEnv, strings.TrimSuffix(filepath.Base(path), filelock.Suffix))
+		}
+
+		return nil, err
+	}
+
+	if IsGroupShared(filepath.Dir(path)) {
+		shareEntry(path, groupReadWriteExecute)
+	}
+
+	return lock, nil
+}
+
+// releaseLock releases lock, logging failures.
+func releaseLock(lock *filelock.Lock) {
+	if err := lock.Release(); err != nil {
+		Logger().Warn("failed to release lock", "path", lock.Path(), "error", err)
+	}
+}
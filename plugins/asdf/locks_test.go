@@ -0,0 +1,62 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/filelock"
+)
+
+// TestAcquireLock verifies named locks live in the data dir and time out with a hint.
+func TestAcquireLock(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+	t.Setenv(asdf.LockTimeoutEnv, "100ms")
+
+	name := asdf.InstallLockName("jq", "1.7")
+
+	lock, err := asdf.AcquireLock(t.Context(), name, "install jq 1.7", false)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dataDir, "locks", "install-jq-1.7.lock"), lock.Path())
+
+	_, err = asdf.AcquireLock(t.Context(), name, "install jq 1.7", false)
+	require.ErrorIs(t, err, filelock.ErrLocked)
+	require.Contains(t, err.Error(), "for install jq 1.7")
+	require.Contains(t, err.Error(), "locks break install-jq-1.7")
+
+	require.NoError(t, lock.Release())
+
+	lock, err = asdf.AcquireLock(t.Context(), name, "install jq 1.7", false)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+// TestLockTimeout verifies the lock timeout environment variable.
+func TestLockTimeout(t *testing.T) {
+	t.Setenv(asdf.LockTimeoutEnv, "")
+	require.Equal(t, 10*time.Minute, asdf.LockTimeout())
+
+	t.Setenv(asdf.LockTimeoutEnv, "30s")
+	require.Equal(t, 30*time.Second, asdf.LockTimeout())
+
+	t.Setenv(asdf.LockTimeoutEnv, "soon")
+	require.Equal(t, 10*time.Minute, asdf.LockTimeout())
+}
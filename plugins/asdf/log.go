@@ -0,0 +1,142 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// LogEnv selects the log level (debug, info, warn or error).
+const LogEnv = "ASDF_LOG"
+
+// errInvalidLogLevel is returned when a log level name is not recognized.
+var errInvalidLogLevel = errors.New("invalid log level")
+
+// logLevel is the level shared by every logger created by ConfigureLogging.
+var logLevel = new(slog.LevelVar) //nolint:gochecknoglobals // process-wide log level
+
+// ParseLogLevel converts a level name such as "debug" or "WARN" to a slog.Level.
+func ParseLogLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug", "trace":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("%w: %q", errInvalidLogLevel, name)
+	}
+}
+
+// ConfigureLogging installs the process-wide logger writing to out. The level is
+// taken from ASDF_LOG and defaults to info; verbose forces debug. The logger is
+// also installed as the slog default so that packages which cannot import asdf
+// (such as the github client) log through it.
+func ConfigureLogging(out io.Writer, verbose bool) error {
+	level, err := ParseLogLevel(os.Getenv(LogEnv))
+	if verbose {
+		level, err = slog.LevelDebug, nil
+	}
+
+	logLevel.Set(level)
+	slog.SetDefault(slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: logLevel})))
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", LogEnv, err)
+	}
+
+	return nil
+}
+
+// Logger returns the process-wide logger.
+func Logger() *slog.Logger {
+	return slog.Default()
+}
+
+// logLineWriter logs every line written to it at debug level and keeps the
+// last lines so that they can be reported when a command fails.
+type logLineWriter struct {
+	ctx     context.Context //nolint:containedctx // scoped to a single command
+	command string
+	partial []byte
+	tail    []string
+	mu      sync.Mutex
+}
+
+// logLineWriterTail is the number of trailing lines kept by logLineWriter.
+const logLineWriterTail = 20
+
+// Write implements io.Writer.
+func (writer *logLineWriter) Write(buff []byte) (int, error) {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	writer.partial = append(writer.partial, buff...)
+
+	for {
+		line, rest, found := bytes.Cut(writer.partial, []byte("\n"))
+		if !found {
+			break
+		}
+
+		writer.logLine(string(line))
+		writer.partial = rest
+	}
+
+	return len(buff), nil
+}
+
+// Flush logs a trailing line without newline.
+func (writer *logLineWriter) Flush() {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	if len(writer.partial) > 0 {
+		writer.logLine(string(writer.partial))
+		writer.partial = nil
+	}
+}
+
+// Tail returns the last lines written.
+func (writer *logLineWriter) Tail() string {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	return strings.Join(writer.tail, "\n")
+}
+
+// logLine logs a single line; callers must hold mu.
+func (writer *logLineWriter) logLine(line string) {
+	line = strings.TrimRight(line, "\r")
+
+	Logger().DebugContext(writer.ctx, line, "command", writer.command)
+
+	writer.tail = append(writer.tail, line)
+	if len(writer.tail) > logLineWriterTail {
+		writer.tail = writer.tail[len(writer.tail)-logLineWriterTail:]
+	}
+}
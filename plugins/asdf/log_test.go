@@ -0,0 +1,117 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected slog.Level
+	}{
+		{name: "", expected: slog.LevelInfo},
+		{name: "debug", expected: slog.LevelDebug},
+		{name: "INFO", expected: slog.LevelInfo},
+		{name: "warning", expected: slog.LevelWarn},
+		{name: " error ", expected: slog.LevelError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			level, err := asdf.ParseLogLevel(tt.name)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, level)
+		})
+	}
+
+	_, err := asdf.ParseLogLevel("chatty")
+	require.ErrorIs(t, err, asdf.ErrInvalidLogLevelForTests())
+}
+
+func TestConfigureLogging(t *testing.T) {
+	t.Run("ASDF_LOG selects the level", func(t *testing.T) {
+		asdf.RestoreLoggingForTests(t)
+		t.Setenv(asdf.LogEnv, "warn")
+
+		var out bytes.Buffer
+		require.NoError(t, asdf.ConfigureLogging(&out, false))
+
+		asdf.Logger().Info("hidden")
+		asdf.Logger().Warn("shown")
+
+		require.NotContains(t, out.String(), "hidden")
+		require.Contains(t, out.String(), "shown")
+	})
+
+	t.Run("verbose overrides ASDF_LOG", func(t *testing.T) {
+		asdf.RestoreLoggingForTests(t)
+		t.Setenv(asdf.LogEnv, "error")
+
+		var out bytes.Buffer
+		require.NoError(t, asdf.ConfigureLogging(&out, true))
+
+		slog.Debug("from another package")
+
+		require.Contains(t, out.String(), "from another package")
+	})
+
+	t.Run("invalid ASDF_LOG falls back to info", func(t *testing.T) {
+		asdf.RestoreLoggingForTests(t)
+		t.Setenv(asdf.LogEnv, "chatty")
+
+		var out bytes.Buffer
+		require.ErrorIs(t, asdf.ConfigureLogging(&out, false), asdf.ErrInvalidLogLevelForTests())
+
+		asdf.Logger().Debug("hidden")
+		asdf.Logger().Info("shown")
+
+		require.NotContains(t, out.String(), "hidden")
+		require.Contains(t, out.String(), "shown")
+	})
+}
+
+func TestDownloadFileLogsRequests(t *testing.T) {
+	asdf.RestoreLoggingForTests(t)
+	t.Setenv(asdf.LogEnv, "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	require.NoError(t, asdf.ConfigureLogging(&out, true))
+
+	err := asdf.DownloadFile(t.Context(), server.URL+"/artifact.tar.gz", filepath.Join(t.TempDir(), "artifact"))
+	require.Error(t, err)
+
+	require.Contains(t, out.String(), "url="+server.URL+"/artifact.tar.gz")
+	require.Contains(t, out.String(), "status=418")
+}
@@ -0,0 +1,167 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// InstallMetadataFileName is the file at the root of an install recording
+// which artifacts were installed and by which build of the plugin binary.
+// HashTree leaves it out, so recording it never changes tool checksums.
+const InstallMetadataFileName = ".asdf-install-metadata.json"
+
+type (
+	// InstallMetadata records how an install came to be, see WriteInstallMetadata.
+	InstallMetadata struct {
+		// Plugin is the name of the plugin that installed the tool.
+		Plugin string `json:"plugin"`
+		// RequestedVersion is the version asked for, e.g. "latest" or "1.2".
+		RequestedVersion string `json:"requested_version"`
+		// Version is the version it resolved to and that was installed.
+		Version string `json:"version"`
+		// Artifacts are the downloads the install was made from.
+		Artifacts []InstallArtifact `json:"artifacts,omitempty"`
+		// InstallerVersion is the version of the universal plugin binary that installed the tool.
+		InstallerVersion string `json:"installer_version"`
+		// InstallerCommit is the commit the universal plugin binary was built from.
+		InstallerCommit string `json:"installer_commit"`
+	}
+
+	// InstallArtifact describes one download of an install.
+	InstallArtifact struct {
+		// DownloadedAt is the modification time of the download, left out
+		// with ReproducibleEnv set.
+		DownloadedAt time.Time `json:"downloaded_at,omitzero"`
+		// Name is the file name of the download.
+		Name string `json:"name"`
+		// URL is the URL it was downloaded from.
+		URL string `json:"url,omitempty"`
+		// SHA256 is the hex encoded checksum of the download.
+		SHA256 string `json:"sha256"`
+	}
+
+	// InstallRequest carries what an install command knows about an install
+	// beyond the version a plugin is asked to install.
+	InstallRequest struct {
+		// RequestedVersion is the version given to the command, before resolving.
+		RequestedVersion string
+		// InstallerVersion and InstallerCommit identify the universal plugin binary.
+		InstallerVersion string
+		InstallerCommit  string
+	}
+
+	// installRequestContextKey is the context key under which an InstallRequest is stored.
+	installRequestContextKey struct{}
+)
+
+// WithInstallRequest returns a context carrying request, read by WriteInstallMetadata.
+func WithInstallRequest(ctx context.Context, request InstallRequest) context.Context {
+	return context.WithValue(ctx, installRequestContextKey{}, request)
+}
+
+// InstallRequestFromContext returns the InstallRequest attached to ctx, or
+// the zero InstallRequest when none is attached.
+func InstallRequestFromContext(ctx context.Context) InstallRequest {
+	request, _ := ctx.Value(installRequestContextKey{}).(InstallRequest)
+
+	return request
+}
+
+// NewInstallArtifact describes the download at path fetched from url, with
+// its checksum and modification time.
+func NewInstallArtifact(path, url string) (InstallArtifact, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return InstallArtifact{}, err
+	}
+
+	sha256, err := FileSHA256(path)
+	if err != nil {
+		return InstallArtifact{}, err
+	}
+
+	return InstallArtifact{
+		DownloadedAt: info.ModTime().UTC(),
+		Name:         filepath.Base(path),
+		URL:          url,
+		SHA256:       sha256,
+	}, nil
+}
+
+// WriteInstallMetadata records metadata in InstallMetadataFileName of
+// installPath. BinaryPlugin installs record it on their own; other plugins
+// call it at the end of Install. The requested version and the installer
+// build come from the InstallRequest of ctx unless metadata sets them, and
+// the requested version defaults to the installed one. Download times are
+// left out with ReproducibleEnv set, as they depend on the machine.
+func WriteInstallMetadata(ctx context.Context, installPath string, metadata InstallMetadata) error {
+	request := InstallRequestFromContext(ctx)
+
+	if metadata.RequestedVersion == "" {
+		metadata.RequestedVersion = request.RequestedVersion
+	}
+
+	if metadata.RequestedVersion == "" {
+		metadata.RequestedVersion = metadata.Version
+	}
+
+	if metadata.InstallerVersion == "" {
+		metadata.InstallerVersion = request.InstallerVersion
+	}
+
+	if metadata.InstallerCommit == "" {
+		metadata.InstallerCommit = request.InstallerCommit
+	}
+
+	if Reproducible() {
+		metadata.Artifacts = slices.Clone(metadata.Artifacts)
+
+		for index := range metadata.Artifacts {
+			metadata.Artifacts[index].DownloadedAt = time.Time{}
+		}
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(installPath, InstallMetadataFileName)
+	if err := os.WriteFile(path, append(data, '\n'), CommonFilePermission); err != nil {
+		return fmt.Errorf("recording install metadata: %w", err)
+	}
+
+	return nil
+}
+
+// ReadInstallMetadata returns the install metadata recorded in installPath.
+func ReadInstallMetadata(installPath string) (InstallMetadata, error) {
+	var metadata InstallMetadata
+
+	data, err := os.ReadFile(filepath.Join(installPath, InstallMetadataFileName))
+	if err != nil {
+		return metadata, err
+	}
+
+	return metadata, json.Unmarshal(data, &metadata)
+}
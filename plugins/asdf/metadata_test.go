@@ -0,0 +1,101 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestWriteInstallMetadata verifies the install request of the context fills
+// in the metadata, which HashTree leaves out of the install hash.
+func TestWriteInstallMetadata(t *testing.T) {
+	t.Parallel()
+
+	installPath := t.TempDir()
+	writeTree(t, installPath, map[string]string{"bin/cosign": "binary"})
+
+	before, err := asdf.HashTree(installPath)
+	require.NoError(t, err)
+
+	download := filepath.Join(t.TempDir(), "cosign-linux-amd64")
+	require.NoError(t, os.WriteFile(download, []byte("binary"), asdf.CommonFilePermission))
+
+	artifact, err := asdf.NewInstallArtifact(download, "https://example.com/cosign-linux-amd64")
+	require.NoError(t, err)
+	require.Equal(t, expectedFileFor([]byte("binary")).SHA256, artifact.SHA256)
+	require.False(t, artifact.DownloadedAt.IsZero())
+
+	ctx := asdf.WithInstallRequest(t.Context(), asdf.InstallRequest{
+		RequestedVersion: "latest:2",
+		InstallerVersion: "1.4.0",
+		InstallerCommit:  "abc123",
+	})
+	require.NoError(t, asdf.WriteInstallMetadata(ctx, installPath, asdf.InstallMetadata{
+		Plugin:    "cosign",
+		Version:   "2.4.1",
+		Artifacts: []asdf.InstallArtifact{artifact},
+	}))
+
+	metadata, err := asdf.ReadInstallMetadata(installPath)
+	require.NoError(t, err)
+	require.Equal(t, "latest:2", metadata.RequestedVersion)
+	require.Equal(t, "2.4.1", metadata.Version)
+	require.Equal(t, "1.4.0", metadata.InstallerVersion)
+	require.Equal(t, "abc123", metadata.InstallerCommit)
+	require.Len(t, metadata.Artifacts, 1)
+	require.Equal(t, artifact.URL, metadata.Artifacts[0].URL)
+	require.True(t, artifact.DownloadedAt.Equal(metadata.Artifacts[0].DownloadedAt))
+
+	after, err := asdf.HashTree(installPath)
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+
+	require.NoError(t, asdf.WriteInstallMetadata(t.Context(), installPath, asdf.InstallMetadata{Plugin: "cosign", Version: "2.4.1"}))
+
+	metadata, err = asdf.ReadInstallMetadata(installPath)
+	require.NoError(t, err)
+	require.Equal(t, "2.4.1", metadata.RequestedVersion)
+	require.Empty(t, metadata.InstallerVersion)
+}
+
+// TestWriteInstallMetadataReproducible verifies download times are left out
+// of reproducible installs.
+func TestWriteInstallMetadataReproducible(t *testing.T) {
+	t.Setenv(asdf.ReproducibleEnv, "1")
+
+	download := filepath.Join(t.TempDir(), "gitsign")
+	require.NoError(t, os.WriteFile(download, []byte("binary"), asdf.CommonFilePermission))
+
+	artifact, err := asdf.NewInstallArtifact(download, "")
+	require.NoError(t, err)
+
+	installPath := t.TempDir()
+	require.NoError(t, asdf.WriteInstallMetadata(t.Context(), installPath, asdf.InstallMetadata{
+		Plugin:    "gitsign",
+		Version:   "0.11.0",
+		Artifacts: []asdf.InstallArtifact{artifact},
+	}))
+
+	data, err := os.ReadFile(filepath.Join(installPath, asdf.InstallMetadataFileName))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "downloaded_at")
+	require.False(t, artifact.DownloadedAt.IsZero())
+}
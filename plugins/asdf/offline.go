@@ -0,0 +1,222 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// OfflineEnv makes commands never touch the network when set to 1: downloads
+	// must be pre-seeded and versions come from the recorded version catalog.
+	OfflineEnv = "ASDF_OFFLINE"
+	// versionCatalogSuffix is the file suffix of the recorded version catalogs.
+	versionCatalogSuffix = ".versions"
+)
+
+var (
+	// errOfflineDownloadMissing is returned when an offline download is not pre-seeded.
+	errOfflineDownloadMissing = errors.New("offline mode: download not pre-seeded")
+	// errOfflineCatalogMissing is returned when no versions were recorded for an offline lookup.
+	errOfflineCatalogMissing = errors.New("offline mode: no recorded versions")
+	// errOfflineNoVersionMatching is returned when no recorded version matches an offline query.
+	errOfflineNoVersionMatching = errors.New("offline mode: no recorded version matching query")
+)
+
+// Offline reports whether OfflineEnv selects offline mode.
+func Offline() bool {
+	return os.Getenv(OfflineEnv) == "1"
+}
+
+// MissingArtifacts returns the files plugin needs in downloadPath to install
+// version without downloading, that are not there yet. Plugins that do not
+// implement PluginWithArtifacts need a non-empty downloadPath.
+func MissingArtifacts(plugin Plugin, version, downloadPath string) ([]string, error) {
+	artifacts, ok := plugin.(PluginWithArtifacts)
+	if !ok {
+		entries, err := os.ReadDir(downloadPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		if len(entries) > 0 {
+			return nil, nil
+		}
+
+		return []string{downloadPath + string(os.PathSeparator)}, nil
+	}
+
+	names, err := artifacts.ArtifactNames(version)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s %s artifacts: %w", plugin.Name(), version, err)
+	}
+
+	var missing []string
+
+	for _, name := range names {
+		path := filepath.Join(downloadPath, name)
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			missing = append(missing, path)
+		}
+	}
+
+	return missing, nil
+}
+
+// CheckOfflineDownload returns an error naming every file that has to be
+// pre-seeded before plugin can install version from downloadPath offline.
+func CheckOfflineDownload(plugin Plugin, version, downloadPath string) error {
+	missing, err := MissingArtifacts(plugin, version, downloadPath)
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		return offlineDownloadMissing(plugin.Name(), version, missing)
+	}
+
+	return nil
+}
+
+// OfflineDownloadError returns, in offline mode, the error naming the files
+// toolName would have to download to install version, and nil otherwise.
+// Plugins that reach the network outside of Download, or re-download from
+// Install, return it instead.
+func OfflineDownloadError(toolName, version string, missing ...string) error {
+	if !Offline() {
+		return nil
+	}
+
+	return offlineDownloadMissing(toolName, version, missing)
+}
+
+// offlineDownloadMissing returns the error naming the files toolName misses
+// to install version offline.
+func offlineDownloadMissing(toolName, version string, missing []string) error {
+	return fmt.Errorf("%w: %s %s requires %s", errOfflineDownloadMissing,
+		toolName, version, strings.Join(missing, ", "))
+}
+
+// ListAllVersions lists the versions of plugin. Online, the list is recorded in
+// the version catalog of the data layout; offline, it is read from there.
+func ListAllVersions(ctx context.Context, plugin Plugin) ([]string, error) {
+	if Offline() {
+		return readVersionCatalog(plugin.Name())
+	}
+
+	versions, err := plugin.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions = WithoutSystemVersion(versions)
+
+	if err := RecordVersionCatalog(plugin.Name(), versions); err != nil {
+		Logger().Warn("failed to record version catalog", "tool", plugin.Name(), "error", err)
+	}
+
+	return versions, nil
+}
+
+// LatestStableVersion returns the latest stable version of plugin matching
+// query, selected from the recorded version catalog when offline.
+func LatestStableVersion(ctx context.Context, plugin Plugin, query string) (string, error) {
+	if !Offline() {
+		return plugin.LatestStable(ctx, query)
+	}
+
+	versions, err := readVersionCatalog(plugin.Name())
+	if err != nil {
+		return "", err
+	}
+
+	latest, err := MatchLatestVersion(versions, query, false)
+	if err != nil {
+		return "", err
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("%w: %s %s", errOfflineNoVersionMatching, plugin.Name(), query)
+	}
+
+	return latest, nil
+}
+
+// RecordVersionCatalog replaces the recorded version catalog of toolName with versions.
+func RecordVersionCatalog(toolName string, versions []string) error {
+	layout, err := CurrentLayout()
+	if err != nil {
+		return err
+	}
+
+	path := layout.VersionCatalogFile(toolName)
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("creating catalog directory: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating catalog: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	content := strings.Join(versions, "\n") + "\n"
+	if _, err := temp.WriteString(content); err != nil {
+		temp.Close()
+
+		return fmt.Errorf("writing catalog: %w", err)
+	}
+
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("writing catalog: %w", err)
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("replacing catalog: %w", err)
+	}
+
+	if IsGroupShared(filepath.Dir(path)) {
+		shareEntry(path, groupReadWriteExecute)
+	}
+
+	return nil
+}
+
+// readVersionCatalog returns the versions recorded for toolName.
+func readVersionCatalog(toolName string) ([]string, error) {
+	layout, err := CurrentLayout()
+	if err != nil {
+		return nil, err
+	}
+
+	path := layout.VersionCatalogFile(toolName)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w for %s in %s, run list-all online first", errOfflineCatalogMissing, toolName, path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(data)), nil
+}
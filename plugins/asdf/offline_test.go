@@ -0,0 +1,133 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// catalogPlugin lists fixed versions and counts the lookups reaching it.
+type catalogPlugin struct {
+	mockPlugin
+
+	versions []string
+	lookups  int
+}
+
+func (plugin *catalogPlugin) ListAll(_ context.Context) ([]string, error) {
+	plugin.lookups++
+
+	return plugin.versions, nil
+}
+
+func (plugin *catalogPlugin) LatestStable(_ context.Context, query string) (string, error) {
+	plugin.lookups++
+
+	return asdf.LatestVersion(plugin.versions, query), nil
+}
+
+// artifactPlugin downloads a single archive named after the version.
+type artifactPlugin struct {
+	mockPlugin
+}
+
+func (*artifactPlugin) ArtifactNames(version string) ([]string, error) {
+	return []string{"tool-" + version + ".tar.gz"}, nil
+}
+
+// TestVersionCatalog verifies online listings are recorded and used offline.
+func TestVersionCatalog(t *testing.T) {
+	t.Setenv("ASDF_DATA_DIR", t.TempDir())
+	t.Setenv(asdf.OfflineEnv, "")
+
+	plugin := &catalogPlugin{versions: []string{"1.29.0", "1.29.3", "1.30.0-rc.1"}}
+
+	t.Setenv(asdf.OfflineEnv, "1")
+
+	_, err := asdf.ListAllVersions(t.Context(), plugin)
+	require.ErrorIs(t, err, asdf.ErrOfflineCatalogMissingForTests())
+	require.Contains(t, err.Error(), filepath.Join("catalog", "mock.versions"))
+
+	t.Setenv(asdf.OfflineEnv, "")
+
+	versions, err := asdf.ListAllVersions(t.Context(), plugin)
+	require.NoError(t, err)
+	require.Equal(t, plugin.versions, versions)
+
+	latest, err := asdf.LatestStableVersion(t.Context(), plugin, "")
+	require.NoError(t, err)
+	require.Equal(t, "1.29.3", latest)
+	require.Equal(t, 2, plugin.lookups)
+
+	t.Setenv(asdf.OfflineEnv, "1")
+
+	versions, err = asdf.ListAllVersions(t.Context(), plugin)
+	require.NoError(t, err)
+	require.Equal(t, plugin.versions, versions)
+
+	latest, err = asdf.LatestStableVersion(t.Context(), plugin, "")
+	require.NoError(t, err)
+	require.Equal(t, "1.29.3", latest)
+
+	latest, err = asdf.LatestStableVersion(t.Context(), plugin, asdf.PrereleaseQueryPrefix+"1.30")
+	require.NoError(t, err)
+	require.Equal(t, "1.30.0-rc.1", latest)
+
+	_, err = asdf.LatestStableVersion(t.Context(), plugin, "2")
+	require.ErrorIs(t, err, asdf.ErrOfflineNoVersionMatchingForTests())
+	require.Equal(t, 2, plugin.lookups, "offline lookups must not reach the plugin")
+}
+
+// TestCheckOfflineDownload verifies missing pre-seeded downloads are named precisely.
+func TestCheckOfflineDownload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("plugin with artifacts", func(t *testing.T) {
+		t.Parallel()
+
+		downloadPath := t.TempDir()
+		plugin := &artifactPlugin{}
+
+		err := asdf.CheckOfflineDownload(plugin, "1.2.3", downloadPath)
+		require.ErrorIs(t, err, asdf.ErrOfflineDownloadMissingForTests())
+		require.Contains(t, err.Error(), "mock 1.2.3 requires "+filepath.Join(downloadPath, "tool-1.2.3.tar.gz"))
+
+		require.NoError(t, os.WriteFile(filepath.Join(downloadPath, "tool-1.2.3.tar.gz"), []byte("seeded"), 0o600))
+		require.NoError(t, asdf.CheckOfflineDownload(plugin, "1.2.3", downloadPath))
+	})
+
+	t.Run("plugin without artifacts", func(t *testing.T) {
+		t.Parallel()
+
+		downloadPath := filepath.Join(t.TempDir(), "1.2.3")
+		plugin := &mockPlugin{}
+
+		err := asdf.CheckOfflineDownload(plugin, "1.2.3", downloadPath)
+		require.ErrorIs(t, err, asdf.ErrOfflineDownloadMissingForTests())
+		require.Contains(t, err.Error(), downloadPath)
+
+		require.NoError(t, os.MkdirAll(downloadPath, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(downloadPath, "tool.zip"), []byte("seeded"), 0o600))
+		require.NoError(t, asdf.CheckOfflineDownload(plugin, "1.2.3", downloadPath))
+	})
+}
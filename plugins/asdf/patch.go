@@ -0,0 +1,403 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// devNull is the file name unified diffs use for created and deleted files.
+const devNull = "/dev/null"
+
+var (
+	// errPatchMalformed is returned when a patch is not a valid unified diff.
+	errPatchMalformed = errors.New("malformed patch")
+	// errPatchHunkFailed is returned when a hunk does not match the patched file.
+	errPatchHunkFailed = errors.New("patch hunk does not apply")
+
+	// hunkHeaderPattern matches "@@ -oldStart[,oldCount] +newStart[,newCount] @@".
+	hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+)
+
+type (
+	// SourcePatch is a patch applied to extracted sources before they are built.
+	// Exactly one of Content or URL is set; URL patches need SHA256.
+	SourcePatch struct {
+		// AppliesTo restricts the patch to some versions; nil applies it to all.
+		AppliesTo func(version string) bool
+		// Strip is the number of leading path components removed from the file
+		// names in the patch, as patch -p. Defaults to 1.
+		Strip *int
+		// Name identifies the patch in logs and errors.
+		Name string
+		// Content is an embedded unified diff.
+		Content string
+		// URL locates a unified diff to download.
+		URL string
+		// SHA256 is the expected checksum of the patch content.
+		SHA256 string
+	}
+
+	// filePatch holds the hunks of a single file in a unified diff.
+	filePatch struct {
+		oldName string
+		newName string
+		hunks   []patchHunk
+	}
+
+	// patchHunk is a single "@@" section. Lines keep their line terminator.
+	patchHunk struct {
+		header   string
+		oldLines []string
+		newLines []string
+		oldStart int
+	}
+
+	// patchedFile is the result of applying a filePatch, written once every
+	// file of the patch applied cleanly.
+	patchedFile struct {
+		path    string
+		content string
+		mode    os.FileMode
+		remove  bool
+	}
+)
+
+// ForVersionsBefore returns a SourcePatch.AppliesTo selector matching versions
+// older than limit.
+func ForVersionsBefore(limit string) func(version string) bool {
+	return func(version string) bool {
+		return CompareVersions(version, limit) < 0
+	}
+}
+
+// ApplyPatch applies the unified diff content to the files below dir, removing
+// strip leading path components from its file names. Nothing is written unless
+// every hunk applies; name identifies the patch in errors.
+func ApplyPatch(dir, name, content string, strip int) error {
+	files, err := parseUnifiedDiff(content)
+	if err != nil {
+		return fmt.Errorf("applying patch %s: %w", name, err)
+	}
+
+	results := make([]patchedFile, 0, len(files))
+
+	for _, file := range files {
+		result, err := applyFilePatch(dir, name, file, strip)
+		if err != nil {
+			return err
+		}
+
+		results = append(results, result)
+	}
+
+	for _, result := range results {
+		if result.remove {
+			if err := os.Remove(result.path); err != nil {
+				return fmt.Errorf("applying patch %s: %w", name, err)
+			}
+
+			continue
+		}
+
+		if err := EnsureDir(filepath.Dir(result.path)); err != nil {
+			return fmt.Errorf("applying patch %s: %w", name, err)
+		}
+
+		if err := os.WriteFile(result.path, []byte(result.content), result.mode); err != nil {
+			return fmt.Errorf("applying patch %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyFilePatch applies the hunks of file in memory.
+func applyFilePatch(dir, name string, file filePatch, strip int) (patchedFile, error) {
+	target := file.newName
+	if target == devNull {
+		target = file.oldName
+	}
+
+	relPath, err := stripPatchPath(target, strip)
+	if err != nil {
+		return patchedFile{}, fmt.Errorf("applying patch %s: %w", name, err)
+	}
+
+	path := filepath.Join(dir, filepath.FromSlash(relPath))
+	if !isPathWithinDir(path, dir) {
+		return patchedFile{}, fmt.Errorf("%w: %s: %s escapes the source directory", errPatchMalformed, name, target)
+	}
+
+	result := patchedFile{path: path, mode: CommonFilePermission, remove: file.newName == devNull}
+
+	var lines []string
+
+	if file.oldName != devNull {
+		info, err := os.Stat(path)
+		if err != nil {
+			return patchedFile{}, fmt.Errorf("applying patch %s: %w", name, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return patchedFile{}, fmt.Errorf("applying patch %s: %w", name, err)
+		}
+
+		result.mode = info.Mode().Perm()
+		lines = splitPatchLines(string(data))
+	} else if _, err := os.Lstat(path); err == nil {
+		return patchedFile{}, fmt.Errorf("%w: %s: hunk #1 of %s: file already exists",
+			errPatchHunkFailed, name, relPath)
+	}
+
+	offset, floor := 0, 0
+
+	for index, hunk := range file.hunks {
+		expected := hunk.oldStart - 1
+		if len(hunk.oldLines) == 0 {
+			expected = hunk.oldStart
+		}
+
+		at := findHunk(lines, hunk.oldLines, expected+offset, floor)
+		if at < 0 {
+			return patchedFile{}, fmt.Errorf("%w: %s: hunk #%d of %s (%s)",
+				errPatchHunkFailed, name, index+1, relPath, hunk.header)
+		}
+
+		patched := make([]string, 0, len(lines)-len(hunk.oldLines)+len(hunk.newLines))
+		patched = append(patched, lines[:at]...)
+		patched = append(patched, hunk.newLines...)
+		patched = append(patched, lines[at+len(hunk.oldLines):]...)
+		lines = patched
+
+		offset = at - expected + len(hunk.newLines) - len(hunk.oldLines)
+		floor = at + len(hunk.newLines)
+	}
+
+	result.content = strings.Join(lines, "")
+
+	if result.remove && result.content != "" {
+		return patchedFile{}, fmt.Errorf("%w: %s: %s is not empty after removing its content",
+			errPatchHunkFailed, name, relPath)
+	}
+
+	return result, nil
+}
+
+// findHunk returns the position of want in lines closest to expected and not
+// before floor, or -1.
+func findHunk(lines, want []string, expected, floor int) int {
+	last := len(lines) - len(want)
+	if last < floor {
+		return -1
+	}
+
+	for distance := 0; ; distance++ {
+		before, after := expected-distance, expected+distance
+		if before < floor && after > last {
+			return -1
+		}
+
+		if after >= floor && after <= last && hunkMatches(lines[after:], want) {
+			return after
+		}
+
+		if before >= floor && before <= last && hunkMatches(lines[before:], want) {
+			return before
+		}
+	}
+}
+
+// hunkMatches reports whether lines starts with want.
+func hunkMatches(lines, want []string) bool {
+	for index, line := range want {
+		if lines[index] != line {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseUnifiedDiff parses the file sections of a unified diff, ignoring any
+// text around them such as git headers.
+func parseUnifiedDiff(content string) ([]filePatch, error) {
+	lines := splitPatchLines(content)
+
+	var files []filePatch
+
+	for index := 0; index < len(lines); index++ {
+		line := lines[index]
+
+		switch {
+		case strings.HasPrefix(line, "--- ") && index+1 < len(lines) && strings.HasPrefix(lines[index+1], "+++ "):
+			files = append(files, filePatch{
+				oldName: patchFileName(line),
+				newName: patchFileName(lines[index+1]),
+			})
+			index++
+
+		case strings.HasPrefix(line, "@@ ") && len(files) > 0:
+			file := &files[len(files)-1]
+
+			hunk, next, err := parseHunk(lines, index)
+			if err != nil {
+				return nil, fmt.Errorf("hunk #%d of %s: %w", len(file.hunks)+1, file.newName, err)
+			}
+
+			file.hunks = append(file.hunks, hunk)
+			index = next - 1
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no file changes found", errPatchMalformed)
+	}
+
+	return files, nil
+}
+
+// parseHunk parses the hunk whose header is lines[start] and returns the index
+// of the first line after it.
+func parseHunk(lines []string, start int) (patchHunk, int, error) {
+	header := strings.TrimRight(lines[start], "\r\n")
+
+	match := hunkHeaderPattern.FindStringSubmatch(header)
+	if match == nil {
+		return patchHunk{}, 0, fmt.Errorf("%w: invalid header %q", errPatchMalformed, header)
+	}
+
+	oldStart, _ := strconv.Atoi(match[1])
+	oldCount := hunkCount(match[2])
+	newCount := hunkCount(match[4])
+
+	hunk := patchHunk{header: header, oldStart: oldStart}
+
+	var last byte
+
+	index := start + 1
+	for ; index < len(lines); index++ {
+		line := lines[index]
+
+		if strings.HasPrefix(line, `\`) {
+			trimNoNewline(&hunk, last)
+
+			continue
+		}
+
+		if oldCount == 0 && newCount == 0 {
+			break
+		}
+
+		kind, text := line[0], line[1:]
+		if line == "\n" || line == "\r\n" {
+			kind, text = ' ', line
+		}
+
+		switch kind {
+		case ' ':
+			hunk.oldLines = append(hunk.oldLines, text)
+			hunk.newLines = append(hunk.newLines, text)
+			oldCount--
+			newCount--
+		case '-':
+			hunk.oldLines = append(hunk.oldLines, text)
+			oldCount--
+		case '+':
+			hunk.newLines = append(hunk.newLines, text)
+			newCount--
+		default:
+			return patchHunk{}, 0, fmt.Errorf("%w: unexpected line %q", errPatchMalformed, strings.TrimRight(line, "\r\n"))
+		}
+
+		if oldCount < 0 || newCount < 0 {
+			return patchHunk{}, 0, fmt.Errorf("%w: more lines than announced by %q", errPatchMalformed, header)
+		}
+
+		last = kind
+	}
+
+	if oldCount != 0 || newCount != 0 {
+		return patchHunk{}, 0, fmt.Errorf("%w: truncated, %q announces more lines", errPatchMalformed, header)
+	}
+
+	return hunk, index, nil
+}
+
+// trimNoNewline handles a "\ No newline at end of file" marker following a
+// line of the given kind.
+func trimNoNewline(hunk *patchHunk, kind byte) {
+	trim := func(lines []string) {
+		if len(lines) > 0 {
+			lines[len(lines)-1] = strings.TrimSuffix(lines[len(lines)-1], "\n")
+		}
+	}
+
+	if kind != '+' {
+		trim(hunk.oldLines)
+	}
+
+	if kind != '-' {
+		trim(hunk.newLines)
+	}
+}
+
+// hunkCount parses an optional hunk line count, which defaults to 1.
+func hunkCount(value string) int {
+	if value == "" {
+		return 1
+	}
+
+	count, _ := strconv.Atoi(value)
+
+	return count
+}
+
+// patchFileName returns the file name of a "---" or "+++" line without its
+// timestamp.
+func patchFileName(line string) string {
+	name := strings.TrimRight(line[len("--- "):], "\r\n")
+	name, _, _ = strings.Cut(name, "\t")
+
+	return strings.TrimSpace(name)
+}
+
+// stripPatchPath removes strip leading components from a patch file name.
+func stripPatchPath(name string, strip int) (string, error) {
+	parts := strings.Split(name, "/")
+	if strip >= len(parts) {
+		return "", fmt.Errorf("%w: cannot strip %d components from %s", errPatchMalformed, strip, name)
+	}
+
+	return strings.Join(parts[strip:], "/"), nil
+}
+
+// splitPatchLines splits content into lines that keep their terminator.
+func splitPatchLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
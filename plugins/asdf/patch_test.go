@@ -0,0 +1,173 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// testSourcePatch adds, modifies and deletes files of the tree written by writePatchTree.
+const testSourcePatch = `diff --git a/configure b/configure
+index 1111111..2222222 100755
+--- a/configure
++++ b/configure
+@@ -1,4 +1,4 @@
+ #!/bin/sh
+-OPENSSL=1.1
++OPENSSL=3
+ echo configuring
+ echo one
+@@ -8,3 +8,4 @@
+ echo five
+ echo six
+ echo done
++echo patched
+diff --git a/Modules/_ssl_compat.c b/Modules/_ssl_compat.c
+new file mode 100644
+--- /dev/null
++++ b/Modules/_ssl_compat.c
+@@ -0,0 +1,2 @@
++/* OpenSSL 3 compatibility */
++int ssl_compat = 1;
+diff --git a/obsolete.txt b/obsolete.txt
+deleted file mode 100644
+--- a/obsolete.txt
++++ /dev/null
+@@ -1,2 +0,0 @@
+-remove
+-me
+`
+
+// writePatchTree writes the tiny source tree patched by testSourcePatch.
+func writePatchTree(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"configure": "#!/bin/sh\nOPENSSL=1.1\necho configuring\necho one\n" +
+			"echo two\necho three\necho four\necho five\necho six\necho done\n",
+		"obsolete.txt": "remove\nme\n",
+	})
+
+	return dir
+}
+
+// TestApplyPatch verifies add, modify and delete hunks of a unified diff.
+func TestApplyPatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("applies add, modify and delete hunks", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writePatchTree(t)
+		require.NoError(t, asdf.ApplyPatch(dir, "openssl3.patch", testSourcePatch, 1))
+
+		configure, err := os.ReadFile(filepath.Join(dir, "configure"))
+		require.NoError(t, err)
+		require.Equal(t, "#!/bin/sh\nOPENSSL=3\necho configuring\necho one\n"+
+			"echo two\necho three\necho four\necho five\necho six\necho done\necho patched\n", string(configure))
+
+		info, err := os.Stat(filepath.Join(dir, "configure"))
+		require.NoError(t, err)
+		require.Equal(t, asdf.CommonExecutablePermission, info.Mode().Perm())
+
+		compat, err := os.ReadFile(filepath.Join(dir, "Modules", "_ssl_compat.c"))
+		require.NoError(t, err)
+		require.Equal(t, "/* OpenSSL 3 compatibility */\nint ssl_compat = 1;\n", string(compat))
+
+		require.NoFileExists(t, filepath.Join(dir, "obsolete.txt"))
+	})
+
+	t.Run("tolerates shifted hunks", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writePatchTree(t)
+
+		configure := filepath.Join(dir, "configure")
+		content, err := os.ReadFile(configure)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(configure, append([]byte("# generated\n\n"), content...), 0o600))
+
+		require.NoError(t, asdf.ApplyPatch(dir, "openssl3.patch", testSourcePatch, 1))
+
+		patched, err := os.ReadFile(configure)
+		require.NoError(t, err)
+		require.Contains(t, string(patched), "\nOPENSSL=3\n")
+		require.Contains(t, string(patched), "echo done\necho patched\n")
+	})
+
+	t.Run("handles missing newlines at end of file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		writeTree(t, dir, map[string]string{"VERSION": "1.0"})
+
+		patch := "--- VERSION\n+++ VERSION\n@@ -1 +1 @@\n-1.0\n\\ No newline at end of file\n+1.0-patched\n"
+		require.NoError(t, asdf.ApplyPatch(dir, "version.patch", patch, 0))
+
+		content, err := os.ReadFile(filepath.Join(dir, "VERSION"))
+		require.NoError(t, err)
+		require.Equal(t, "1.0-patched\n", string(content))
+	})
+
+	t.Run("reports the failing hunk and writes nothing", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writePatchTree(t)
+		writeTree(t, dir, map[string]string{"configure": "#!/bin/sh\nOPENSSL=1.1\necho configuring\necho one\n"})
+
+		err := asdf.ApplyPatch(dir, "openssl3.patch", testSourcePatch, 1)
+		require.ErrorIs(t, err, asdf.ErrPatchHunkFailedForTests())
+		require.Contains(t, err.Error(), "openssl3.patch: hunk #2 of configure (@@ -8,3 +8,4 @@)")
+
+		require.FileExists(t, filepath.Join(dir, "obsolete.txt"))
+		require.NoFileExists(t, filepath.Join(dir, "Modules", "_ssl_compat.c"))
+
+		configure, err := os.ReadFile(filepath.Join(dir, "configure"))
+		require.NoError(t, err)
+		require.Contains(t, string(configure), "OPENSSL=1.1")
+	})
+
+	t.Run("rejects malformed patches", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writePatchTree(t)
+
+		for _, patch := range []string{
+			"not a patch\n",
+			"--- a/configure\n+++ b/configure\n@@ -1,3 +1,3 @@\n #!/bin/sh\n",
+			"--- a/../escape\n+++ b/../escape\n@@ -0,0 +1 @@\n+x\n",
+		} {
+			err := asdf.ApplyPatch(dir, "bad.patch", patch, 1)
+			require.ErrorIs(t, err, asdf.ErrPatchMalformedForTests(), patch)
+		}
+	})
+}
+
+// TestForVersionsBefore verifies the version selector for patches.
+func TestForVersionsBefore(t *testing.T) {
+	t.Parallel()
+
+	before := asdf.ForVersionsBefore("3.8")
+	require.True(t, before("3.7.17"))
+	require.False(t, before("3.8.0"))
+	require.False(t, before("3.12.1"))
+}
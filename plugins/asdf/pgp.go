@@ -0,0 +1,789 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // OpenPGP v4 key IDs are defined over SHA1
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSignatureInvalid is returned when a detached OpenPGP signature does not
+	// verify against any of the trusted keys.
+	ErrSignatureInvalid = errors.New("signature verification failed")
+	// ErrPGPKeyInvalid is returned when the key that made a signature is
+	// expired, revoked or not bound to its certificate.
+	ErrPGPKeyInvalid = errors.New("OpenPGP key not valid")
+	// errPGPMalformed is returned for OpenPGP data that cannot be parsed.
+	errPGPMalformed = errors.New("malformed OpenPGP data")
+	// errPGPUnsupported is returned for OpenPGP features the verifier does not implement.
+	errPGPUnsupported = errors.New("unsupported OpenPGP data")
+	// errPGPNoPublicKey is returned when the key data holds no RSA public key.
+	errPGPNoPublicKey = errors.New("no RSA public key found")
+
+	// pgpNow returns the time keys have to be valid at.
+	pgpNow = time.Now //nolint:gochecknoglobals // replaced in tests
+)
+
+const (
+	// pgpTagSignature is the OpenPGP packet tag of a signature.
+	pgpTagSignature = 2
+	// pgpTagPublicKey is the OpenPGP packet tag of a primary public key.
+	pgpTagPublicKey = 6
+	// pgpTagUserID is the OpenPGP packet tag of a user ID.
+	pgpTagUserID = 13
+	// pgpTagPublicSubkey is the OpenPGP packet tag of a public subkey.
+	pgpTagPublicSubkey = 14
+	// pgpSubpacketCreationTime is the signature subpacket holding its creation time.
+	pgpSubpacketCreationTime = 2
+	// pgpSubpacketSignatureExpiration is the signature subpacket holding its lifetime.
+	pgpSubpacketSignatureExpiration = 3
+	// pgpSubpacketKeyExpiration is the signature subpacket holding the lifetime of the key.
+	pgpSubpacketKeyExpiration = 9
+	// pgpSubpacketIssuer is the signature subpacket holding the issuer key ID.
+	pgpSubpacketIssuer = 16
+	// pgpSubpacketKeyFlags is the signature subpacket holding what the key may be used for.
+	pgpSubpacketKeyFlags = 27
+	// pgpSubpacketEmbeddedSignature is the signature subpacket holding the
+	// primary key binding signature made by a signing subkey.
+	pgpSubpacketEmbeddedSignature = 32
+	// pgpSubpacketIssuerFingerprint is the signature subpacket holding the issuer fingerprint.
+	pgpSubpacketIssuerFingerprint = 33
+	// pgpSignatureBinary is the signature type of a binary document.
+	pgpSignatureBinary = 0x00
+	// pgpSignatureGenericCertification is the first signature type certifying a user ID.
+	pgpSignatureGenericCertification = 0x10
+	// pgpSignaturePositiveCertification is the last signature type certifying a user ID.
+	pgpSignaturePositiveCertification = 0x13
+	// pgpSignatureSubkeyBinding is the signature type binding a subkey to its primary key.
+	pgpSignatureSubkeyBinding = 0x18
+	// pgpSignaturePrimaryKeyBinding is the signature type a signing subkey makes over its primary key.
+	pgpSignaturePrimaryKeyBinding = 0x19
+	// pgpSignatureDirectKey is the signature type made over the primary key alone.
+	pgpSignatureDirectKey = 0x1f
+	// pgpSignatureKeyRevocation is the signature type revoking the primary key.
+	pgpSignatureKeyRevocation = 0x20
+	// pgpSignatureSubkeyRevocation is the signature type revoking a subkey.
+	pgpSignatureSubkeyRevocation = 0x28
+	// pgpKeyFlagSign is the key flag allowing a key to sign data.
+	pgpKeyFlagSign = 0x02
+)
+
+type (
+	// pgpPacket is an OpenPGP packet with its tag and body.
+	pgpPacket struct {
+		body []byte
+		tag  byte
+	}
+
+	// pgpPublicKey is an RSA key or subkey of an OpenPGP certificate.
+	pgpPublicKey struct {
+		created time.Time
+		key     *rsa.PublicKey
+		// invalid is why the key may not verify signatures, nil when it may.
+		invalid error
+		// packet is the body of the key packet, which self-signatures are made over.
+		packet      []byte
+		keyID       uint64
+		fingerprint [sha1.Size]byte
+	}
+
+	// pgpSignature is a v4 signature.
+	pgpSignature struct {
+		created time.Time
+		// hashed is the signed part of the packet: version to hashed subpackets.
+		hashed []byte
+		value  []byte
+		// embedded is the body of the embedded primary key binding signature.
+		embedded []byte
+		issuer   uint64
+		hash     crypto.Hash
+		// lifetime and keyLifetime are the seconds the signature and the key it
+		// binds stay valid after their creation, 0 for ever.
+		lifetime    uint32
+		keyLifetime uint32
+		sigType     byte
+		keyFlags    byte
+		hasKeyFlags bool
+		left16      [2]byte
+	}
+
+	// pgpCertSignature is a self-signature with the packets it is made over.
+	pgpCertSignature struct {
+		signed [][]byte
+		sig    pgpSignature
+	}
+
+	// pgpComponent is a key of a certificate with the self-signatures binding
+	// it, which are user ID certifications or direct key signatures for the
+	// primary key, and those revoking it.
+	pgpComponent struct {
+		bindings    []pgpCertSignature
+		revocations []pgpCertSignature
+		key         pgpPublicKey
+	}
+)
+
+// pgpHashes maps the OpenPGP hash algorithm IDs accepted in signatures to
+// their implementations. SHA-1 (2), MD5 (1) and RIPEMD-160 (3) are collision
+// prone and rejected, like any ID missing here.
+var pgpHashes = map[byte]crypto.Hash{ //nolint:gochecknoglobals // fixed algorithm table
+	8:  crypto.SHA256,
+	9:  crypto.SHA384,
+	10: crypto.SHA512,
+	11: crypto.SHA224,
+}
+
+// VerifyPGPSignature verifies the detached OpenPGP signature of the file at
+// path with the RSA keys and subkeys of publicKey. Both may be ASCII armored or
+// binary. Only v4 RSA signatures of binary documents are supported, which is
+// what release signing keys use. The signing key has to be valid now: bound
+// by a self-signature, neither expired nor revoked, and for a subkey bound to
+// its primary key in both directions; ErrPGPKeyInvalid tells a rotated key has
+// to replace publicKey.
+func VerifyPGPSignature(path string, signature, publicKey []byte) error {
+	keys, err := parsePGPPublicKeys(publicKey)
+	if err != nil {
+		return err
+	}
+
+	signatures, err := parsePGPSignatures(signature)
+	if err != nil {
+		return err
+	}
+
+	var keyErr error
+
+	for _, sig := range signatures {
+		for _, key := range keys {
+			if sig.issuer != 0 && sig.issuer != key.keyID {
+				continue
+			}
+
+			if key.invalid != nil {
+				keyErr = key.invalid
+
+				continue
+			}
+
+			if err := sig.verify(path, key.key); err == nil {
+				return nil
+			} else if !errors.Is(err, ErrSignatureInvalid) {
+				return err
+			}
+		}
+	}
+
+	if keyErr != nil {
+		return keyErr
+	}
+
+	return fmt.Errorf("%w: %s", ErrSignatureInvalid, path)
+}
+
+// verify checks sig over the file at path with key.
+func (sig pgpSignature) verify(path string, key *rsa.PublicKey) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hasher := sig.hash.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return sig.check(hasher, key)
+}
+
+// verifyPackets checks sig over the certificate packets in signed with key.
+func (sig pgpSignature) verifyPackets(key *rsa.PublicKey, signed [][]byte) error {
+	hasher := sig.hash.New()
+	for _, data := range signed {
+		hasher.Write(data)
+	}
+
+	return sig.check(hasher, key)
+}
+
+// check completes hasher, fed with the signed data, with the signature
+// trailer and checks the result against sig with key.
+func (sig pgpSignature) check(hasher hash.Hash, key *rsa.PublicKey) error {
+	hasher.Write(sig.hashed)
+
+	trailer := []byte{4, 0xff, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(trailer[2:], uint32(len(sig.hashed))) //nolint:gosec // bounded by the packet length
+	hasher.Write(trailer)
+
+	digest := hasher.Sum(nil)
+	if digest[0] != sig.left16[0] || digest[1] != sig.left16[1] {
+		return ErrSignatureInvalid
+	}
+
+	// The signature MPI drops leading zeros, PKCS #1 expects the modulus size.
+	value := make([]byte, max(key.Size(), len(sig.value)))
+	copy(value[len(value)-len(sig.value):], sig.value)
+
+	if rsa.VerifyPKCS1v15(key, sig.hash, digest, value) != nil {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// liveAt reports whether sig was made before now and has not expired.
+func (sig pgpSignature) liveAt(now time.Time) bool {
+	if sig.created.After(now) {
+		return false
+	}
+
+	return sig.lifetime == 0 || now.Before(sig.created.Add(time.Duration(sig.lifetime)*time.Second))
+}
+
+// parsePGPPublicKeys returns the RSA keys and subkeys of the OpenPGP
+// certificates in data, each marked invalid unless it may sign now.
+func parsePGPPublicKeys(data []byte) ([]pgpPublicKey, error) {
+	packets, err := readPGPPackets(dearmorPGP(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		keys       []pgpPublicKey
+		components []*pgpComponent
+		// signed is what the next signatures are made over, and component the
+		// key they belong to; nil for packets whose signatures are ignored.
+		signed    [][]byte
+		component *pgpComponent
+		primary   *pgpComponent
+	)
+
+	flush := func() {
+		keys = append(keys, validatePGPCertificate(components, pgpNow())...)
+		components, primary, component = nil, nil, nil
+	}
+
+	for _, packet := range packets {
+		switch packet.tag {
+		case pgpTagPublicKey:
+			flush()
+
+			key, ok, err := parsePGPPublicKey(packet.body)
+			if err != nil {
+				return nil, err
+			}
+
+			// Without an RSA primary key the certificate cannot be validated.
+			if ok {
+				primary = &pgpComponent{key: key}
+				components = append(components, primary)
+				component, signed = primary, [][]byte{pgpHashedKey(key.packet)}
+			}
+		case pgpTagUserID:
+			if primary != nil {
+				component, signed = primary, [][]byte{pgpHashedKey(primary.key.packet), pgpHashedUserID(packet.body)}
+			}
+		case pgpTagPublicSubkey:
+			component = nil
+
+			key, ok, err := parsePGPPublicKey(packet.body)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok && primary != nil {
+				component = &pgpComponent{key: key}
+				components = append(components, component)
+				signed = [][]byte{pgpHashedKey(primary.key.packet), pgpHashedKey(key.packet)}
+			}
+		case pgpTagSignature:
+			if component == nil {
+				continue
+			}
+
+			// Self-signatures the verifier cannot check do not bind anything.
+			sig, err := parsePGPSignature(packet.body)
+			if err != nil || (sig.issuer != 0 && sig.issuer != primary.key.keyID) {
+				continue
+			}
+
+			component.addSignature(pgpCertSignature{sig: sig, signed: signed}, len(signed) == 2 && component == primary)
+		default:
+			// User attributes and others: their signatures are not needed.
+			component = nil
+		}
+	}
+
+	flush()
+
+	if len(keys) == 0 {
+		return nil, errPGPNoPublicKey
+	}
+
+	return keys, nil
+}
+
+// addSignature records a self-signature over component, made after a user ID
+// when certifiesUserID, keeping only the types that bind or revoke it.
+func (component *pgpComponent) addSignature(sig pgpCertSignature, certifiesUserID bool) {
+	switch sigType := sig.sig.sigType; {
+	case certifiesUserID:
+		if sigType >= pgpSignatureGenericCertification && sigType <= pgpSignaturePositiveCertification {
+			component.bindings = append(component.bindings, sig)
+		}
+	case sigType == pgpSignatureDirectKey || sigType == pgpSignatureSubkeyBinding:
+		component.bindings = append(component.bindings, sig)
+	case sigType == pgpSignatureKeyRevocation || sigType == pgpSignatureSubkeyRevocation:
+		component.revocations = append(component.revocations, sig)
+	}
+}
+
+// validatePGPCertificate returns the keys of a certificate, its primary key
+// first, with invalid set on those that may not sign at now.
+func validatePGPCertificate(components []*pgpComponent, now time.Time) []pgpPublicKey {
+	if len(components) == 0 {
+		return nil
+	}
+
+	primary := components[0]
+	keys := make([]pgpPublicKey, 0, len(components))
+
+	// A primary key that only certifies still makes its subkeys valid.
+	primarySigns, certErr := primary.validity(primary.key, now, false)
+
+	for i, component := range components {
+		key := component.key
+		signs, err := primarySigns, certErr
+
+		if i > 0 {
+			if certErr != nil {
+				err = fmt.Errorf("subkey %016X: %w", key.keyID, certErr)
+			} else {
+				signs, err = component.validity(primary.key, now, true)
+			}
+		}
+
+		if err == nil && !signs {
+			err = fmt.Errorf("%w: key %016X may not sign", ErrPGPKeyInvalid, key.keyID)
+		}
+
+		key.invalid = err
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// validity returns whether component may sign, according to its latest
+// binding, or why it is not valid at now, given its primary key. A subkey
+// binding only counts together with the primary key binding signature the
+// subkey embeds in it.
+func (component *pgpComponent) validity(primary pgpPublicKey, now time.Time, subkey bool) (bool, error) {
+	key := component.key
+
+	for _, revocation := range component.revocations {
+		if revocation.sig.verifyPackets(primary.key, revocation.signed) == nil {
+			return false, fmt.Errorf("%w: key %016X is revoked", ErrPGPKeyInvalid, key.keyID)
+		}
+	}
+
+	var latest *pgpSignature
+
+	for _, binding := range component.bindings {
+		sig := binding.sig
+		if !sig.liveAt(now) || sig.verifyPackets(primary.key, binding.signed) != nil {
+			continue
+		}
+
+		if subkey && !sig.backSigned(key.key, binding.signed) {
+			continue
+		}
+
+		if latest == nil || sig.created.After(latest.created) {
+			latest = &sig
+		}
+	}
+
+	if latest == nil {
+		return false, fmt.Errorf("%w: key %016X has no valid self-signature", ErrPGPKeyInvalid, key.keyID)
+	}
+
+	if latest.keyLifetime != 0 {
+		expiry := key.created.Add(time.Duration(latest.keyLifetime) * time.Second)
+		if !now.Before(expiry) {
+			return false, fmt.Errorf("%w: key %016X expired on %s", ErrPGPKeyInvalid, key.keyID, expiry.UTC().Format(time.DateOnly))
+		}
+	}
+
+	return !latest.hasKeyFlags || latest.keyFlags&pgpKeyFlagSign != 0, nil
+}
+
+// backSigned reports whether the subkey binding sig embeds a primary key
+// binding signature made by subkey over signed.
+func (sig pgpSignature) backSigned(subkey *rsa.PublicKey, signed [][]byte) bool {
+	if sig.embedded == nil {
+		return false
+	}
+
+	back, err := parsePGPSignature(sig.embedded)
+	if err != nil || back.sigType != pgpSignaturePrimaryKeyBinding {
+		return false
+	}
+
+	return back.verifyPackets(subkey, signed) == nil
+}
+
+// parsePGPPublicKey parses the body of a key packet, reporting false for keys
+// that are not v4 RSA keys.
+func parsePGPPublicKey(body []byte) (pgpPublicKey, bool, error) {
+	// version 4, creation time, algorithm 1 to 3 (RSA)
+	if len(body) < 6 || body[0] != 4 || body[5] < 1 || body[5] > 3 {
+		return pgpPublicKey{}, false, nil
+	}
+
+	modulus, rest, err := readPGPMPI(body[6:])
+	if err != nil {
+		return pgpPublicKey{}, false, err
+	}
+
+	exponent, _, err := readPGPMPI(rest)
+	if err != nil {
+		return pgpPublicKey{}, false, err
+	}
+
+	if len(exponent) > 4 {
+		return pgpPublicKey{}, false, fmt.Errorf("%w: RSA exponent too large", errPGPUnsupported)
+	}
+
+	fingerprint := sha1.New() //nolint:gosec // OpenPGP v4 key IDs are defined over SHA1
+	fingerprint.Write(pgpHashedKey(body))
+
+	key := pgpPublicKey{
+		key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		},
+		packet:  body,
+		created: time.Unix(int64(binary.BigEndian.Uint32(body[1:])), 0),
+	}
+	copy(key.fingerprint[:], fingerprint.Sum(nil))
+	key.keyID = binary.BigEndian.Uint64(key.fingerprint[12:])
+
+	return key, true, nil
+}
+
+// pgpHashedKey returns a key packet body the way key fingerprints and
+// certificate signatures hash it.
+func pgpHashedKey(body []byte) []byte {
+	return append([]byte{0x99, byte(len(body) >> 8), byte(len(body))}, body...)
+}
+
+// pgpHashedUserID returns a user ID packet body the way certifications hash it.
+func pgpHashedUserID(body []byte) []byte {
+	hashed := []byte{0xb4, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(hashed[1:], uint32(len(body))) //nolint:gosec // bounded by the packet length
+
+	return append(hashed, body...)
+}
+
+// parsePGPSignatures returns the v4 RSA binary document signatures in data.
+func parsePGPSignatures(data []byte) ([]pgpSignature, error) {
+	packets, err := readPGPPackets(dearmorPGP(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var signatures []pgpSignature
+
+	for _, packet := range packets {
+		if packet.tag != pgpTagSignature {
+			continue
+		}
+
+		sig, err := parsePGPSignature(packet.body)
+		if err != nil {
+			return nil, err
+		}
+
+		if sig.sigType != pgpSignatureBinary {
+			return nil, fmt.Errorf("%w: signature type %d", errPGPUnsupported, sig.sigType)
+		}
+
+		signatures = append(signatures, sig)
+	}
+
+	if len(signatures) == 0 {
+		return nil, fmt.Errorf("%w: no signature found", errPGPMalformed)
+	}
+
+	return signatures, nil
+}
+
+// parsePGPSignature parses the body of a v4 RSA signature packet.
+func parsePGPSignature(body []byte) (pgpSignature, error) {
+	if len(body) < 6 || body[0] != 4 {
+		return pgpSignature{}, fmt.Errorf("%w: signature version", errPGPUnsupported)
+	}
+
+	if body[2] < 1 || body[2] > 3 {
+		return pgpSignature{}, fmt.Errorf("%w: signature algorithm %d", errPGPUnsupported, body[2])
+	}
+
+	algorithm, ok := pgpHashes[body[3]]
+	if !ok {
+		return pgpSignature{}, fmt.Errorf("%w: hash algorithm %d", errPGPUnsupported, body[3])
+	}
+
+	hashedEnd := 6 + int(binary.BigEndian.Uint16(body[4:]))
+	if len(body) < hashedEnd+2 {
+		return pgpSignature{}, errPGPMalformed
+	}
+
+	unhashedEnd := hashedEnd + 2 + int(binary.BigEndian.Uint16(body[hashedEnd:]))
+	if len(body) < unhashedEnd+2 {
+		return pgpSignature{}, errPGPMalformed
+	}
+
+	value, _, err := readPGPMPI(body[unhashedEnd+2:])
+	if err != nil {
+		return pgpSignature{}, err
+	}
+
+	sig := pgpSignature{
+		hashed:  body[:hashedEnd],
+		value:   value,
+		hash:    algorithm,
+		sigType: body[1],
+		left16:  [2]byte{body[unhashedEnd], body[unhashedEnd+1]},
+	}
+
+	if err := sig.readSubpackets(body[6:hashedEnd], true); err != nil {
+		return pgpSignature{}, err
+	}
+
+	if err := sig.readSubpackets(body[hashedEnd+2:unhashedEnd], false); err != nil {
+		return pgpSignature{}, err
+	}
+
+	return sig, nil
+}
+
+// readSubpackets reads the issuer and embedded signature subpackets, and from
+// the signed, hashed area the creation time, lifetimes and key flags.
+func (sig *pgpSignature) readSubpackets(subpackets []byte, hashed bool) error {
+	for len(subpackets) > 0 {
+		length, header := int(subpackets[0]), 1
+
+		switch {
+		case length >= 255:
+			if len(subpackets) < 5 {
+				return errPGPMalformed
+			}
+
+			length, header = int(binary.BigEndian.Uint32(subpackets[1:])), 5
+		case length >= 192:
+			if len(subpackets) < 2 {
+				return errPGPMalformed
+			}
+
+			length, header = (length-192)<<8+int(subpackets[1])+192, 2
+		}
+
+		if length < 1 || len(subpackets) < header+length {
+			return errPGPMalformed
+		}
+
+		subpacket := subpackets[header : header+length]
+
+		switch data := subpacket[1:]; subpacket[0] & 0x7f {
+		case pgpSubpacketIssuer:
+			if len(data) == 8 {
+				sig.issuer = binary.BigEndian.Uint64(data)
+			}
+		case pgpSubpacketIssuerFingerprint:
+			// version 4 followed by the 20 byte fingerprint
+			if len(data) == 21 && data[0] == 4 {
+				sig.issuer = binary.BigEndian.Uint64(data[13:])
+			}
+		case pgpSubpacketEmbeddedSignature:
+			sig.embedded = data
+		case pgpSubpacketCreationTime:
+			if hashed && len(data) == 4 {
+				sig.created = time.Unix(int64(binary.BigEndian.Uint32(data)), 0)
+			}
+		case pgpSubpacketSignatureExpiration:
+			if hashed && len(data) == 4 {
+				sig.lifetime = binary.BigEndian.Uint32(data)
+			}
+		case pgpSubpacketKeyExpiration:
+			if hashed && len(data) == 4 {
+				sig.keyLifetime = binary.BigEndian.Uint32(data)
+			}
+		case pgpSubpacketKeyFlags:
+			if hashed && len(data) > 0 {
+				sig.keyFlags, sig.hasKeyFlags = data[0], true
+			}
+		}
+
+		subpackets = subpackets[header+length:]
+	}
+
+	return nil
+}
+
+// readPGPPackets splits OpenPGP data into packets, in old or new format.
+func readPGPPackets(data []byte) ([]pgpPacket, error) {
+	var packets []pgpPacket
+
+	for len(data) > 0 {
+		header := data[0]
+		if header&0x80 == 0 {
+			return nil, fmt.Errorf("%w: packet header", errPGPMalformed)
+		}
+
+		var (
+			tag    byte
+			length int
+			offset int
+		)
+
+		if header&0x40 != 0 {
+			tag = header & 0x3f
+
+			if len(data) < 2 {
+				return nil, errPGPMalformed
+			}
+
+			switch first := int(data[1]); {
+			case first < 192:
+				length, offset = first, 2
+			case first < 224:
+				if len(data) < 3 {
+					return nil, errPGPMalformed
+				}
+
+				length, offset = (first-192)<<8+int(data[2])+192, 3
+			case first == 255:
+				if len(data) < 6 {
+					return nil, errPGPMalformed
+				}
+
+				length, offset = int(binary.BigEndian.Uint32(data[2:])), 6
+			default:
+				return nil, fmt.Errorf("%w: partial body length", errPGPUnsupported)
+			}
+		} else {
+			tag = (header >> 2) & 0x0f
+
+			switch header & 0x03 {
+			case 0:
+				if len(data) < 2 {
+					return nil, errPGPMalformed
+				}
+
+				length, offset = int(data[1]), 2
+			case 1:
+				if len(data) < 3 {
+					return nil, errPGPMalformed
+				}
+
+				length, offset = int(binary.BigEndian.Uint16(data[1:])), 3
+			case 2:
+				if len(data) < 5 {
+					return nil, errPGPMalformed
+				}
+
+				length, offset = int(binary.BigEndian.Uint32(data[1:])), 5
+			default:
+				length, offset = len(data)-1, 1
+			}
+		}
+
+		if length < 0 || len(data) < offset+length {
+			return nil, fmt.Errorf("%w: truncated packet", errPGPMalformed)
+		}
+
+		packets = append(packets, pgpPacket{tag: tag, body: data[offset : offset+length]})
+		data = data[offset+length:]
+	}
+
+	return packets, nil
+}
+
+// readPGPMPI reads a multiprecision integer and returns its bytes and the rest of data.
+func readPGPMPI(data []byte) ([]byte, []byte, error) {
+	if len(data) < 2 {
+		return nil, nil, fmt.Errorf("%w: truncated integer", errPGPMalformed)
+	}
+
+	size := (int(binary.BigEndian.Uint16(data)) + 7) / 8
+	if len(data) < 2+size {
+		return nil, nil, fmt.Errorf("%w: truncated integer", errPGPMalformed)
+	}
+
+	return data[2 : 2+size], data[2+size:], nil
+}
+
+// dearmorPGP returns the binary data of the first ASCII armored block in data,
+// or data itself when it is not armored.
+func dearmorPGP(data []byte) []byte {
+	begin := bytes.Index(data, []byte("-----BEGIN PGP "))
+	if begin < 0 {
+		return data
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data[begin:]), "\r\n", "\n"), "\n")[1:]
+
+	// Armor headers such as "Version:" end at the first empty line.
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines = lines[i+1:]
+
+			break
+		}
+	}
+
+	var encoded strings.Builder
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "=") || strings.HasPrefix(line, "-----END PGP ") {
+			break
+		}
+
+		encoded.WriteString(line)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return nil
+	}
+
+	return decoded
+}
@@ -0,0 +1,158 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// readPGPTestdata returns the content of a file in testdata/pgp.
+func readPGPTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "pgp", name))
+	require.NoError(t, err)
+
+	return data
+}
+
+// TestVerifyPGPSignature verifies gpg made signatures of the primary key and
+// its subkey, binary and armored, against the armored test key.
+func TestVerifyPGPSignature(t *testing.T) {
+	t.Parallel()
+
+	key := readPGPTestdata(t, "test-key.asc")
+	document := filepath.Join("testdata", "pgp", "document.txt")
+
+	for _, name := range []string{"document.txt.sig", "document.txt.asc"} {
+		require.NoError(t, asdf.VerifyPGPSignature(document, readPGPTestdata(t, name), key), name)
+	}
+}
+
+// TestVerifyPGPSignatureRejected verifies tampered files, foreign keys and
+// garbage are rejected.
+func TestVerifyPGPSignatureRejected(t *testing.T) {
+	t.Parallel()
+
+	key := readPGPTestdata(t, "test-key.asc")
+	signature := readPGPTestdata(t, "document.txt.sig")
+
+	tampered := filepath.Join(t.TempDir(), "document.txt")
+	require.NoError(t, os.WriteFile(tampered, []byte("release artifact!\n"), asdf.CommonFilePermission))
+
+	err := asdf.VerifyPGPSignature(tampered, signature, key)
+	require.ErrorIs(t, err, asdf.ErrSignatureInvalid)
+
+	document := filepath.Join("testdata", "pgp", "document.txt")
+
+	err = asdf.VerifyPGPSignature(document, readPGPTestdata(t, "document.txt.other.sig"), key)
+	require.ErrorIs(t, err, asdf.ErrSignatureInvalid)
+
+	err = asdf.VerifyPGPSignature(document, signature, []byte("not a key"))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, asdf.ErrSignatureInvalid)
+
+	err = asdf.VerifyPGPSignature(document, []byte("not a signature"), key)
+	require.Error(t, err)
+}
+
+// TestVerifyPGPSignatureWeakHash verifies signatures over SHA-1 or an unknown
+// hash algorithm are refused before any verification.
+func TestVerifyPGPSignatureWeakHash(t *testing.T) {
+	t.Parallel()
+
+	key := readPGPTestdata(t, "test-key.asc")
+	document := filepath.Join("testdata", "pgp", "document.txt")
+
+	for _, hash := range []byte{2, 99} {
+		signature := readPGPTestdata(t, "document.txt.sig")
+		// An old format packet with a two byte length, then version, type,
+		// public key algorithm and hash algorithm.
+		require.Equal(t, byte(0x89), signature[0])
+		signature[6] = hash
+
+		err := asdf.VerifyPGPSignature(document, signature, key)
+		require.ErrorContains(t, err, fmt.Sprintf("unsupported OpenPGP data: hash algorithm %d", hash))
+		require.NotErrorIs(t, err, asdf.ErrSignatureInvalid)
+	}
+}
+
+// TestVerifyPGPSignatureKeyValidity verifies signatures made by expired or
+// revoked keys, by expired subkeys and by subkeys without a binding signature
+// are refused, while the same signatures pass with the valid key.
+func TestVerifyPGPSignatureKeyValidity(t *testing.T) {
+	t.Parallel()
+
+	document := filepath.Join("testdata", "pgp", "document.txt")
+
+	tests := []struct {
+		name      string
+		key       string
+		signature string
+		reason    string
+	}{
+		{"expired", "expired-key.asc", "document.txt.expired.sig", "key E795353DB2C194B1 expired on 2020-12-31"},
+		{"revoked", "revoked-key.asc", "document.txt.revoked.sig", "key 3F0CD8DC23A16352 is revoked"},
+		{"expired subkey", "expired-subkey-key.asc", "document.txt.expired-subkey.sig", "key 1B794A510E4CE9D9 expired on 2020-12-31"},
+		{"unbound subkey", "unbound-subkey-key.gpg", "document.txt.asc", "key FE19F543E6EA40CE has no valid self-signature"},
+	}
+
+	for _, tt := range tests {
+		err := asdf.VerifyPGPSignature(document, readPGPTestdata(t, tt.signature), readPGPTestdata(t, tt.key))
+		require.ErrorIs(t, err, asdf.ErrPGPKeyInvalid, tt.name)
+		require.ErrorContains(t, err, tt.reason, tt.name)
+	}
+
+	// The primary key of the unbound subkey still signs.
+	err := asdf.VerifyPGPSignature(document, readPGPTestdata(t, "document.txt.sig"), readPGPTestdata(t, "unbound-subkey-key.gpg"))
+	require.NoError(t, err)
+}
+
+// TestVerifyPGPSignatureBeforeExpiry verifies keys that expired since are
+// accepted at a time they were valid.
+func TestVerifyPGPSignatureBeforeExpiry(t *testing.T) {
+	asdf.SetPGPTimeForTests(t, time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC))
+
+	document := filepath.Join("testdata", "pgp", "document.txt")
+
+	for key, signature := range map[string]string{
+		"expired-key.asc":        "document.txt.expired.sig",
+		"expired-subkey-key.asc": "document.txt.expired-subkey.sig",
+	} {
+		require.NoError(t, asdf.VerifyPGPSignature(document, readPGPTestdata(t, signature), readPGPTestdata(t, key)), key)
+	}
+}
+
+// TestAwscliPublicKey verifies the AWS CLI team key embedded by the awscli
+// plugin is the one published in the AWS CLI installation guide.
+func TestAwscliPublicKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := os.ReadFile(filepath.Join("..", "awscli-public-key.asc"))
+	require.NoError(t, err)
+
+	fingerprints, err := asdf.PGPFingerprintsForTests(key)
+	require.NoError(t, err)
+	require.Equal(t, []string{"FB5DB77FD5C118B80511ADA8A6310ACC4672475C"}, fingerprints)
+}
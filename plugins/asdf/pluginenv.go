@@ -0,0 +1,218 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Types of the plugin settings read through PluginEnvironment.
+const (
+	// EnvTypeBool is a setting read with PluginEnvironment.Bool.
+	EnvTypeBool = "bool"
+	// EnvTypeInt is a setting read with PluginEnvironment.Int.
+	EnvTypeInt = "int"
+	// EnvTypeString is a setting read with PluginEnvironment.String.
+	EnvTypeString = "string"
+)
+
+type (
+	// PluginEnvironment reads the ASDF_<PLUGIN>_<KEY> settings of a plugin and
+	// records every key read, so that its help can list them.
+	PluginEnvironment struct {
+		accessed   map[string]EnvSetting
+		documented []EnvSetting
+		prefix     string
+		mu         sync.Mutex
+	}
+
+	// EnvSetting describes a plugin setting.
+	EnvSetting struct {
+		// Key is the setting name without the ASDF_<PLUGIN>_ prefix.
+		Key string
+		// Type is EnvTypeBool, EnvTypeInt or EnvTypeString.
+		Type string
+		// Default is the value used when the variable is unset, as shown in help.
+		Default string
+		// Description explains the setting in help.
+		Description string
+	}
+)
+
+var (
+	// pluginEnvs holds the PluginEnvironment of each plugin name.
+	pluginEnvs   = make(map[string]*PluginEnvironment) //nolint:gochecknoglobals // per-plugin access records
+	pluginEnvsMu sync.Mutex                            //nolint:gochecknoglobals // guards pluginEnvs
+)
+
+// PluginEnv returns the settings of plugin, read from the variables prefixed
+// with ASDF_ and the upper-cased plugin name, e.g. ASDF_GOLANG_ for golang.
+func PluginEnv(plugin string) *PluginEnvironment {
+	pluginEnvsMu.Lock()
+	defer pluginEnvsMu.Unlock()
+
+	if env, ok := pluginEnvs[plugin]; ok {
+		return env
+	}
+
+	env := &PluginEnvironment{
+		prefix:   "ASDF_" + strings.ToUpper(strings.ReplaceAll(plugin, "-", "_")) + "_",
+		accessed: make(map[string]EnvSetting),
+	}
+	pluginEnvs[plugin] = env
+
+	return env
+}
+
+// Name returns the variable holding the setting key.
+func (env *PluginEnvironment) Name(key string) string {
+	return env.prefix + key
+}
+
+// Document describes settings for ConfigEntries, including settings read by other
+// means than this accessor, such as BuildPreflight.SkipEnv.
+func (env *PluginEnvironment) Document(settings ...EnvSetting) *PluginEnvironment {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+
+	for _, setting := range settings {
+		index := slices.IndexFunc(env.documented, func(documented EnvSetting) bool {
+			return documented.Key == setting.Key
+		})
+		if index >= 0 {
+			env.documented[index] = setting
+
+			continue
+		}
+
+		env.documented = append(env.documented, setting)
+	}
+
+	return env
+}
+
+// Bool returns the setting key as a boolean, accepting 1, true, yes and on
+// and their opposites, or fallback when it is unset or invalid.
+func (env *PluginEnvironment) Bool(key string, fallback bool) bool {
+	value := env.lookup(key, EnvTypeBool, strconv.FormatBool(fallback))
+
+	switch strings.ToLower(value) {
+	case "":
+		return fallback
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	default:
+		env.invalid(key, value)
+
+		return fallback
+	}
+}
+
+// Int returns the setting key as an integer, or fallback when it is unset or invalid.
+func (env *PluginEnvironment) Int(key string, fallback int) int {
+	value := env.lookup(key, EnvTypeInt, strconv.Itoa(fallback))
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		env.invalid(key, value)
+
+		return fallback
+	}
+
+	return parsed
+}
+
+// String returns the setting key, or fallback when it is unset or empty.
+func (env *PluginEnvironment) String(key, fallback string) string {
+	if value := env.lookup(key, EnvTypeString, fallback); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// Accessed returns the settings read so far, sorted by key.
+func (env *PluginEnvironment) Accessed() []EnvSetting {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+
+	settings := make([]EnvSetting, 0, len(env.accessed))
+	for _, setting := range env.accessed {
+		settings = append(settings, setting)
+	}
+
+	slices.SortFunc(settings, func(a, b EnvSetting) int { return strings.Compare(a.Key, b.Key) })
+
+	return settings
+}
+
+// ConfigEntries returns the documented settings, then any other setting read
+// so far, as the entries HelpConfig renders.
+func (env *PluginEnvironment) ConfigEntries() []ConfigEntry {
+	settings := env.settings()
+	entries := make([]ConfigEntry, 0, len(settings))
+
+	for _, setting := range settings {
+		entries = append(entries, ConfigEntry{
+			Name:        env.Name(setting.Key),
+			Default:     setting.Default,
+			Description: setting.Description,
+		})
+	}
+
+	return entries
+}
+
+// settings returns the documented settings followed by the other settings read so far.
+func (env *PluginEnvironment) settings() []EnvSetting {
+	accessed := env.Accessed()
+
+	env.mu.Lock()
+	defer env.mu.Unlock()
+
+	settings := slices.Clone(env.documented)
+
+	for _, setting := range accessed {
+		if !slices.ContainsFunc(settings, func(documented EnvSetting) bool { return documented.Key == setting.Key }) {
+			settings = append(settings, setting)
+		}
+	}
+
+	return settings
+}
+
+// lookup records that key was read as settingType with fallback and returns its value.
+func (env *PluginEnvironment) lookup(key, settingType, fallback string) string {
+	env.mu.Lock()
+	env.accessed[key] = EnvSetting{Key: key, Type: settingType, Default: fallback}
+	env.mu.Unlock()
+
+	return strings.TrimSpace(os.Getenv(env.Name(key)))
+}
+
+// invalid logs that the setting key holds a value of the wrong type.
+func (env *PluginEnvironment) invalid(key, value string) {
+	Logger().Warn("ignoring invalid setting", "variable", env.Name(key), "value", value)
+}
@@ -0,0 +1,98 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+func TestPluginEnvName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "ASDF_EXAMPLE_TOOL_DEBUG", asdf.PluginEnv("example-tool").Name("DEBUG"))
+	require.Same(t, asdf.PluginEnv("example-tool"), asdf.PluginEnv("example-tool"))
+}
+
+func TestPluginEnvBool(t *testing.T) {
+	env := asdf.PluginEnv("envbool")
+
+	for value, want := range map[string]bool{
+		"1": true, "true": true, "YES": true, "on": true,
+		"0": false, "false": false, "No": false, "off": false,
+	} {
+		t.Setenv(env.Name("FLAG"), value)
+		require.Equal(t, want, env.Bool("FLAG", !want), "value %q", value)
+	}
+
+	t.Setenv(env.Name("FLAG"), "")
+	require.True(t, env.Bool("FLAG", true))
+
+	t.Setenv(env.Name("FLAG"), "maybe")
+	require.True(t, env.Bool("FLAG", true))
+	require.False(t, env.Bool("FLAG", false))
+}
+
+func TestPluginEnvInt(t *testing.T) {
+	env := asdf.PluginEnv("envint")
+
+	require.Equal(t, 3, env.Int("JOBS", 3))
+
+	t.Setenv(env.Name("JOBS"), " 8 ")
+	require.Equal(t, 8, env.Int("JOBS", 3))
+
+	t.Setenv(env.Name("JOBS"), "many")
+	require.Equal(t, 3, env.Int("JOBS", 3))
+}
+
+func TestPluginEnvString(t *testing.T) {
+	env := asdf.PluginEnv("envstring")
+
+	require.Equal(t, "fallback", env.String("MIRROR", "fallback"))
+
+	t.Setenv(env.Name("MIRROR"), "https://example.com")
+	require.Equal(t, "https://example.com", env.String("MIRROR", "fallback"))
+}
+
+func TestPluginEnvAccessedAndConfigEntries(t *testing.T) {
+	t.Parallel()
+
+	env := asdf.PluginEnv("envconfig")
+	require.Empty(t, env.ConfigEntries())
+
+	env.Document(
+		asdf.EnvSetting{Key: "MIRROR", Type: asdf.EnvTypeString, Description: "old"},
+		asdf.EnvSetting{Key: "VERIFY", Type: asdf.EnvTypeBool, Default: "true", Description: "Verify downloads"},
+	)
+	env.Document(asdf.EnvSetting{Key: "MIRROR", Type: asdf.EnvTypeString, Description: "Download mirror"})
+
+	env.Int("JOBS", 4)
+	env.String("MIRROR", "")
+
+	require.Equal(t, []asdf.EnvSetting{
+		{Key: "JOBS", Type: asdf.EnvTypeInt, Default: "4"},
+		{Key: "MIRROR", Type: asdf.EnvTypeString},
+	}, env.Accessed())
+
+	require.Equal(t, []asdf.ConfigEntry{
+		{Name: "ASDF_ENVCONFIG_MIRROR", Description: "Download mirror"},
+		{Name: "ASDF_ENVCONFIG_VERIFY", Default: "true", Description: "Verify downloads"},
+		{Name: "ASDF_ENVCONFIG_JOBS", Default: "4"},
+	}, env.ConfigEntries())
+}
@@ -0,0 +1,231 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// startAwscliDownloads serves zip as every Linux installer, with the
+// signature made by the test key for the testdata installer, and returns a
+// plugin downloading from it.
+func startAwscliDownloads(t *testing.T, zip []byte) *p.AwscliPlugin {
+	t.Helper()
+
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		t.Skip("the signed awscli installers are Linux zips")
+	}
+
+	signature, err := os.ReadFile(filepath.Join("testdata", "awscli", "awscli-exe-linux.zip.sig"))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch {
+		case strings.HasSuffix(request.URL.Path, ".zip"):
+			_, _ = writer.Write(zip)
+		case strings.HasSuffix(request.URL.Path, ".zip.sig"):
+			_, _ = writer.Write(signature)
+		default:
+			http.NotFound(writer, request)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("ASDF_AWSCLI_LIBC", "glibc")
+	t.Setenv("ASDF_AWSCLI_PGP_KEY", filepath.Join("testdata", "awscli", "test-key.asc"))
+	t.Setenv("ASDF_AWSCLI_SKIP_VERIFY", "")
+
+	plugin := p.NewAwscliPlugin().(*p.AwscliPlugin)
+	plugin.DownloadURL = server.URL
+
+	return plugin
+}
+
+// readAwscliInstaller returns the testdata installer signed by the test key.
+func readAwscliInstaller(t *testing.T) []byte {
+	t.Helper()
+
+	zip, err := os.ReadFile(filepath.Join("testdata", "awscli", "awscli-exe-linux.zip"))
+	require.NoError(t, err)
+
+	return zip
+}
+
+// TestAwscliDownloadSignature verifies a signed installer is extracted, with
+// the key given as a path or armored.
+func TestAwscliDownloadSignature(t *testing.T) {
+	plugin := startAwscliDownloads(t, readAwscliInstaller(t))
+
+	downloadPath := t.TempDir()
+	require.NoError(t, plugin.Download(t.Context(), "2.17.0", downloadPath))
+	require.FileExists(t, filepath.Join(downloadPath, "aws", "install"))
+	require.NoFileExists(t, filepath.Join(downloadPath, "awscli-exe-linux-"+awscliArch()+"-2.17.0.zip.sig"))
+
+	key, err := os.ReadFile(filepath.Join("testdata", "awscli", "test-key.asc"))
+	require.NoError(t, err)
+	t.Setenv("ASDF_AWSCLI_PGP_KEY", string(key))
+
+	require.NoError(t, plugin.Download(t.Context(), "2.17.0", t.TempDir()))
+}
+
+// TestAwscliDownloadTampered verifies an installer not matching its signature
+// is rejected and deleted, unless verification is skipped.
+func TestAwscliDownloadTampered(t *testing.T) {
+	zip := readAwscliInstaller(t)
+	tampered := append(zip[:len(zip):len(zip)], 0)
+
+	plugin := startAwscliDownloads(t, tampered)
+
+	downloadPath := t.TempDir()
+
+	err := plugin.Download(t.Context(), "2.17.0", downloadPath)
+	require.ErrorIs(t, err, asdf.ErrSignatureInvalid)
+	require.NoFileExists(t, filepath.Join(downloadPath, "awscli-exe-linux-"+awscliArch()+"-2.17.0.zip"))
+
+	t.Setenv("ASDF_AWSCLI_SKIP_VERIFY", "1")
+	require.NoError(t, plugin.Download(t.Context(), "2.17.0", downloadPath))
+	require.FileExists(t, filepath.Join(downloadPath, "aws", "install"))
+}
+
+// TestAwscliDownloadCached verifies a cached installer is verified again
+// before use, and replaced by a fresh download when it does not match.
+func TestAwscliDownloadCached(t *testing.T) {
+	zip := readAwscliInstaller(t)
+	plugin := startAwscliDownloads(t, zip)
+
+	downloadPath := t.TempDir()
+	installer := filepath.Join(downloadPath, "awscli-exe-linux-"+awscliArch()+"-2.17.0.zip")
+
+	tampered := append(zip[:len(zip):len(zip)], 0)
+	require.NoError(t, os.WriteFile(installer, tampered, asdf.CommonFilePermission))
+
+	require.NoError(t, plugin.Download(t.Context(), "2.17.0", downloadPath))
+	require.FileExists(t, filepath.Join(downloadPath, "aws", "install"))
+
+	cached, err := os.ReadFile(installer)
+	require.NoError(t, err)
+	require.Equal(t, zip, cached)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(downloadPath, "aws")))
+	require.NoError(t, plugin.Download(t.Context(), "2.17.0", downloadPath))
+	require.FileExists(t, filepath.Join(downloadPath, "aws", "install"))
+}
+
+// awscliArch returns the architecture in the name of the Linux installers.
+func awscliArch() string {
+	if runtime.GOARCH == "arm64" {
+		return "aarch64"
+	}
+
+	return "x86_64"
+}
+
+// TestHelperProcess registers the exec fake so that mocked commands run it.
+func TestHelperProcess(t *testing.T) {
+	t.Helper()
+	asdf.TestHelperProcess(t)
+}
+
+// TestAwscliInstall verifies each platform installs through the expected
+// commands, run by the exec fake.
+func TestAwscliInstall(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		libc     string
+		setup    func(t *testing.T, downloadPath string)
+		commands func(downloadPath, installPath string) string
+		links    []string
+	}{
+		{
+			name:     "glibc",
+			platform: "linux",
+			libc:     "glibc",
+			setup: func(t *testing.T, downloadPath string) {
+				t.Helper()
+
+				installer := filepath.Join(downloadPath, "aws", "install")
+				require.NoError(t, os.MkdirAll(filepath.Dir(installer), asdf.CommonDirectoryPermission))
+				require.NoError(t, os.WriteFile(installer, []byte("#!/bin/sh\n"), asdf.CommonFilePermission))
+			},
+			commands: func(_, installPath string) string {
+				return "install --install-dir " + filepath.Join(installPath, "aws-cli") +
+					" --bin-dir " + filepath.Join(installPath, "bin") + " --update\n"
+			},
+		},
+		{
+			name:     "musl",
+			platform: "linux",
+			libc:     "musl",
+			commands: func(downloadPath, installPath string) string {
+				venv := filepath.Join(installPath, "aws-cli")
+
+				return "python3 -m venv " + venv + "\n" +
+					"pip install " + filepath.Join(downloadPath, "awscli-2.17.0.tar.gz") + "\n"
+			},
+			links: []string{"aws", "aws_completer"},
+		},
+		{
+			name:     "windows",
+			platform: "windows",
+			commands: func(downloadPath, installPath string) string {
+				return "msiexec /a " + filepath.Join(downloadPath, "AWSCLIV2-2.17.0.msi") +
+					" /qn TARGETDIR=" + filepath.Join(installPath, "aws-cli") + "\n"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asdf.MockExec(t)
+
+			commandLog := filepath.Join(t.TempDir(), "commands.log")
+			t.Setenv("ASDF_MOCK_COMMAND_LOG", commandLog)
+			t.Setenv("ASDF_DATA_DIR", t.TempDir())
+			t.Setenv(asdf.ForcePlatformEnv, tt.platform)
+			t.Setenv("ASDF_AWSCLI_LIBC", tt.libc)
+
+			downloadPath, installPath := t.TempDir(), t.TempDir()
+			if tt.setup != nil {
+				tt.setup(t, downloadPath)
+			}
+
+			plugin := p.NewAwscliPlugin()
+			require.NoError(t, plugin.Install(t.Context(), "2.17.0", downloadPath, installPath))
+
+			commands, err := os.ReadFile(commandLog)
+			require.NoError(t, err)
+			require.Equal(t, tt.commands(downloadPath, installPath), string(commands))
+
+			for _, name := range tt.links {
+				target, err := os.Readlink(filepath.Join(installPath, "bin", name))
+				require.NoError(t, err)
+				require.Equal(t, filepath.Join(installPath, "aws-cli", "bin", name), target)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// TestBunLegacyFiles verifies bun reads .bun-version files and maps the
+// engines.bun range of package.json to a version or latest query.
+func TestBunLegacyFiles(t *testing.T) {
+	t.Parallel()
+
+	plugin, err := plugins.GetPlugin("bun")
+	require.NoError(t, err)
+	require.Equal(t, []string{".bun-version", "package.json"}, plugin.ListLegacyFilenames())
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{name: "bun-version", file: ".bun-version", content: "1.1.8\n", expected: "1.1.8"},
+		{name: "bun-version tag", file: ".bun-version", content: "bun-v1.1.8\n", expected: "1.1.8"},
+		{name: "exact engine", file: "package.json", content: `{"engines":{"bun":"1.1.8"}}`, expected: "1.1.8"},
+		{name: "caret engine", file: "package.json", content: `{"engines":{"bun":"^1.1.0"}}`, expected: "latest:1."},
+		{name: "caret zero engine", file: "package.json", content: `{"engines":{"bun":"^0.8.1"}}`, expected: "latest:0.8."},
+		{name: "tilde engine", file: "package.json", content: `{"engines":{"bun":"~1.1.3"}}`, expected: "latest:1.1."},
+		{name: "wildcard engine", file: "package.json", content: `{"engines":{"bun":"1.x"}}`, expected: "latest:1."},
+		{name: "minimum engine", file: "package.json", content: `{"engines":{"bun":">=1.0.0"}}`, expected: "latest"},
+		{name: "no engine", file: "package.json", content: `{"engines":{"node":">=20"}}`, expected: ""},
+		{name: "unsupported range", file: "package.json", content: `{"engines":{"bun":">=1.0 <1.2"}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), asdf.CommonFilePermission))
+
+			version, err := plugin.ParseLegacyFile(path)
+			if tt.wantErr {
+				require.ErrorContains(t, err, "unsupported engines.bun range")
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
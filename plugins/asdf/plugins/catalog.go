@@ -0,0 +1,170 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"bytes"
+	_ "embed" // plugins.json is embedded into the binary
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+//go:generate go run ./gen -json plugins.json -readme ../../../README.md
+
+// README markers delimiting the generated plugin table.
+const (
+	// ReadmeStartMarker precedes the generated plugin table in README.md.
+	ReadmeStartMarker = "<!-- plugins:start -->"
+	// ReadmeEndMarker follows the generated plugin table in README.md.
+	ReadmeEndMarker = "<!-- plugins:end -->"
+)
+
+// errReadmeMarkersMissing is returned when README.md lacks the plugin table markers.
+var errReadmeMarkersMissing = errors.New("plugin table markers not found")
+
+// catalogJSON is the plugin catalog generated from the registry by go generate.
+//
+//go:embed plugins.json
+var catalogJSON []byte
+
+// CatalogEntry is the machine-readable description of a registered plugin.
+type CatalogEntry struct {
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	Aliases      []string          `json:"aliases,omitempty"`
+	Capabilities []asdf.Capability `json:"capabilities,omitempty"`
+}
+
+// Catalog returns the registry entries sorted by name.
+func (r *Registry) Catalog() []CatalogEntry {
+	entries := make([]CatalogEntry, 0, len(r.all))
+
+	for _, entry := range r.all {
+		entries = append(entries, CatalogEntry{
+			Name:         entry.Names[0],
+			Description:  entry.Description,
+			Aliases:      entry.Names[1:],
+			Capabilities: asdf.Capabilities(entry.Factory()),
+		})
+	}
+
+	return sortCatalog(entries)
+}
+
+// EmbeddedCatalogJSON returns the plugins.json document embedded in the binary.
+func EmbeddedCatalogJSON() []byte {
+	return slices.Clone(catalogJSON)
+}
+
+// EmbeddedCatalog returns the plugin catalog embedded in the binary.
+func EmbeddedCatalog() ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	if err := json.Unmarshal(catalogJSON, &entries); err != nil {
+		return nil, fmt.Errorf("decoding embedded plugins.json: %w", err)
+	}
+
+	return entries, nil
+}
+
+// CatalogJSON renders entries as the plugins.json document. The output does
+// not depend on the order of entries or aliases.
+func CatalogJSON(entries []CatalogEntry) ([]byte, error) {
+	data, err := json.MarshalIndent(sortCatalog(entries), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+// WriteListing writes the human readable plugin listing shown by the CLI.
+func WriteListing(out io.Writer, entries []CatalogEntry) error {
+	if _, err := fmt.Fprintln(out, "Available plugins:"); err != nil {
+		return err
+	}
+
+	for _, entry := range sortCatalog(entries) {
+		if _, err := fmt.Fprintf(out, "  %-13s - %s\n", entry.Name, entry.Description); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadmeTable renders the markdown plugin table of README.md.
+func ReadmeTable(entries []CatalogEntry) string {
+	var table strings.Builder
+
+	table.WriteString("| Tool | Description |\n")
+	table.WriteString("|------|-------------|\n")
+
+	for _, entry := range sortCatalog(entries) {
+		fmt.Fprintf(&table, "| [`%s`](plugins/asdf_plugin_%s) | %s |\n",
+			entry.Name, strings.ReplaceAll(entry.Name, "-", "_"), entry.Description)
+	}
+
+	return table.String()
+}
+
+// UpdateReadme replaces the content between the README markers with table.
+func UpdateReadme(readme []byte, table string) ([]byte, error) {
+	start := bytes.Index(readme, []byte(ReadmeStartMarker))
+	end := bytes.Index(readme, []byte(ReadmeEndMarker))
+
+	if start < 0 || end < start {
+		return nil, errReadmeMarkersMissing
+	}
+
+	var updated bytes.Buffer
+
+	updated.Write(readme[:start+len(ReadmeStartMarker)])
+	updated.WriteString("\n\n" + table + "\n")
+	updated.Write(readme[end:])
+
+	return updated.Bytes(), nil
+}
+
+// sortCatalog returns a copy of entries sorted by name, with sorted aliases.
+func sortCatalog(entries []CatalogEntry) []CatalogEntry {
+	sorted := make([]CatalogEntry, 0, len(entries))
+
+	for _, entry := range entries {
+		if len(entry.Capabilities) == 0 {
+			entry.Capabilities = nil
+		}
+
+		if len(entry.Aliases) == 0 {
+			entry.Aliases = nil
+		} else {
+			entry.Aliases = slices.Sorted(slices.Values(entry.Aliases))
+		}
+
+		sorted = append(sorted, entry)
+	}
+
+	slices.SortFunc(sorted, func(a, b CatalogEntry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return sorted
+}
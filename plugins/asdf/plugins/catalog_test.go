@@ -0,0 +1,133 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// staleHint is appended to staleness failures.
+const staleHint = "generated plugin catalog is stale, run: go generate ./plugins/asdf/plugins"
+
+func TestCatalogIsUpToDate(t *testing.T) {
+	t.Parallel()
+
+	catalog := plugins.NewRegistry().Catalog()
+
+	for _, entry := range catalog {
+		require.NotEmpty(t, entry.Description, "plugin %s has no description", entry.Name)
+	}
+
+	expected, err := plugins.CatalogJSON(catalog)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(plugins.EmbeddedCatalogJSON()), staleHint)
+
+	embedded, err := plugins.EmbeddedCatalog()
+	require.NoError(t, err)
+	require.Equal(t, catalog, embedded, staleHint)
+
+	readme, err := os.ReadFile(filepath.Join("..", "..", "..", "README.md"))
+	require.NoError(t, err)
+
+	updated, err := plugins.UpdateReadme(readme, plugins.ReadmeTable(catalog))
+	require.NoError(t, err)
+	require.Equal(t, string(readme), string(updated), staleHint)
+}
+
+func TestCatalogJSONIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	entries := []plugins.CatalogEntry{
+		{Name: "zig", Description: "Zig"},
+		{Name: "golang", Description: "Go", Aliases: []string{"go", "golang-go"}},
+		{Name: "aws-nuke", Description: "AWS cleanup"},
+		{Name: "awscli", Description: "AWS CLI"},
+	}
+
+	expected, err := plugins.CatalogJSON(entries)
+	require.NoError(t, err)
+
+	for range 10 {
+		shuffled := make([]plugins.CatalogEntry, len(entries))
+		copy(shuffled, entries)
+
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		for i := range shuffled {
+			aliases := append([]string(nil), shuffled[i].Aliases...)
+			rand.Shuffle(len(aliases), func(a, b int) { aliases[a], aliases[b] = aliases[b], aliases[a] })
+			shuffled[i].Aliases = aliases
+		}
+
+		actual, err := plugins.CatalogJSON(shuffled)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(actual))
+	}
+
+	order := make([]string, 0, len(entries))
+	for _, line := range strings.Split(string(expected), "\n") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(line), `"name": "`); ok {
+			order = append(order, strings.TrimSuffix(name, `",`))
+		}
+	}
+
+	require.Equal(t, []string{"aws-nuke", "awscli", "golang", "zig"}, order)
+	require.Contains(t, string(expected), "\"go\",\n      \"golang-go\"")
+	require.Equal(t, "golang", entries[1].Name, "input must not be reordered")
+}
+
+func TestWriteListing(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, plugins.WriteListing(&out, []plugins.CatalogEntry{
+		{Name: "protoc-gen-go-grpc", Description: "gRPC Go protoc plugin"},
+		{Name: "jq", Description: "JSON processor"},
+	}))
+
+	require.Equal(t,
+		"Available plugins:\n"+
+			"  jq            - JSON processor\n"+
+			"  protoc-gen-go-grpc - gRPC Go protoc plugin\n",
+		out.String())
+}
+
+func TestUpdateReadme(t *testing.T) {
+	t.Parallel()
+
+	readme := []byte("# Title\n\n" + plugins.ReadmeStartMarker + "\nold\n" + plugins.ReadmeEndMarker + "\nfooter\n")
+
+	updated, err := plugins.UpdateReadme(readme, "| new |\n")
+	require.NoError(t, err)
+	require.Equal(t,
+		"# Title\n\n"+plugins.ReadmeStartMarker+"\n\n| new |\n\n"+plugins.ReadmeEndMarker+"\nfooter\n",
+		string(updated))
+
+	again, err := plugins.UpdateReadme(updated, "| new |\n")
+	require.NoError(t, err)
+	require.Equal(t, string(updated), string(again))
+
+	_, err = plugins.UpdateReadme([]byte("no markers"), "| new |\n")
+	require.Error(t, err)
+}
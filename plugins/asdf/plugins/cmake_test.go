@@ -0,0 +1,201 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+	"github.com/sumicare/universal-asdf-plugin/plugins/github"
+	githubmock "github.com/sumicare/universal-asdf-plugin/plugins/github/mock"
+)
+
+// cmakeRelease describes the files index published with a synthesized CMake
+// release, listing the archive with its sha256 inline or in a SHA-256 hash
+// file.
+type cmakeRelease struct {
+	version   string
+	sum       string
+	hashFile  bool
+	unindexed bool
+}
+
+// cmakeHarness returns an install harness for release, whose archive keeps
+// the files below its top-level directory, publishing the files index of the
+// release next to it.
+func cmakeHarness(t *testing.T, release cmakeRelease) testutil.InstallHarness {
+	t.Helper()
+
+	plugin, ok := p.NewCmakePlugin().(*p.CmakePlugin)
+	require.True(t, ok)
+	root := "cmake-{{.Version}}-{{.Platform}}-{{.Arch}}/"
+
+	return testutil.InstallHarness{
+		Config:        testutil.BinaryPluginTestConfig{Name: "cmake", Factory: func() asdf.Plugin { return plugin }},
+		Version:       release.version,
+		BinaryPath:    root + plugin.ListBinPaths() + "/cmake",
+		ArchiveFiles:  map[string]string{root + "share/cmake-3.30/Modules/FindZLIB.cmake": "# FindZLIB\n"},
+		ExpectedFiles: []string{"share/cmake-3.30/Modules/FindZLIB.cmake"},
+		Publish: func(assetsURL string, archives map[string][]byte) map[string][]byte {
+			plugin.IndexURLTemplate = assetsURL + "/cmake-{{.Version}}-files-v1.json"
+
+			files := map[string][]byte{}
+			index := map[string]any{}
+
+			for name, archive := range archives {
+				digest := sha256.Sum256(archive)
+
+				sum := release.sum
+				if sum == "" {
+					sum = hex.EncodeToString(digest[:])
+				}
+
+				entry := map[string]any{"name": name, "class": "archive", "os": []string{"linux", "macos"}}
+				if release.unindexed {
+					entry["name"] = "cmake-" + release.version + "-windows-x86_64.zip"
+				}
+
+				if release.hashFile {
+					hashName := "cmake-" + release.version + "-SHA-256.txt"
+					index["hashFiles"] = []any{map[string]any{"name": hashName, "algorithm": []string{"SHA-256"}}}
+					files["/"+hashName] = []byte(sum + "  " + name + "\n")
+				} else {
+					entry["sha256"] = sum
+				}
+
+				index["files"] = []any{entry}
+			}
+
+			data, err := json.Marshal(index)
+			require.NoError(t, err)
+
+			files["/cmake-"+release.version+"-files-v1.json"] = data
+
+			return files
+		},
+	}
+}
+
+// TestCmakeInstall verifies the archive is checked against the files index
+// and installed without its top-level directory, over and over.
+func TestCmakeInstall(t *testing.T) {
+	t.Parallel()
+
+	for _, release := range []cmakeRelease{
+		{version: "3.30.1"},
+		{version: "3.31.0-rc1", hashFile: true},
+	} {
+		t.Run(release.version, func(t *testing.T) {
+			t.Parallel()
+
+			harness := cmakeHarness(t, release)
+			harness.Reinstall = true
+
+			installPath := harness.Run(t)
+
+			plugin := harness.Config.Factory()
+			require.Equal(t, []string{filepath.Join(installPath, filepath.FromSlash(plugin.ListBinPaths()))},
+				asdf.BinDirsOf(plugin, installPath))
+
+			entries, err := os.ReadDir(installPath)
+			require.NoError(t, err)
+
+			for _, entry := range entries {
+				require.False(t, strings.HasPrefix(entry.Name(), "cmake-"), "top-level directory %s kept", entry.Name())
+			}
+		})
+	}
+}
+
+// TestCmakeDownloadVerification verifies downloads that do not match the
+// files index, or are missing from it, are rejected.
+func TestCmakeDownloadVerification(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		wantErr string
+		release cmakeRelease
+	}{
+		{
+			name:    "inline sum mismatch",
+			release: cmakeRelease{version: "3.30.1", sum: strings.Repeat("0", 64)},
+			wantErr: "checksum mismatch",
+		},
+		{
+			name:    "unindexed",
+			release: cmakeRelease{version: "3.30.1", unindexed: true},
+			wantErr: "not listed in the release files index",
+		},
+		{
+			name:    "hash file sum mismatch",
+			release: cmakeRelease{version: "3.30.1", hashFile: true, sum: strings.Repeat("f", 64)},
+			wantErr: "checksum mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			harness := cmakeHarness(t, tt.release)
+			harness.WantErr = tt.wantErr
+			harness.Run(t)
+		})
+	}
+}
+
+// TestCmakeReleaseCandidates verifies release candidates are skipped by
+// LatestStable unless the query opts into prereleases.
+func TestCmakeReleaseCandidates(t *testing.T) {
+	t.Parallel()
+
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddReleases("Kitware", "CMake", []string{"v3.31.0-rc2", "v3.30.1", "v3.30.0", "v3.30.0-rc1"})
+
+	plugin, ok := p.NewCmakePlugin().(*p.CmakePlugin)
+	require.True(t, ok)
+
+	plugin.WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+
+	versions, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []string{"3.30.0", "3.30.1"}, versions)
+
+	latest, err := plugin.LatestStable(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, "3.30.1", latest)
+
+	latest, err = plugin.LatestStable(t.Context(), asdf.PrereleaseQueryPrefix)
+	require.NoError(t, err)
+	require.Equal(t, "3.31.0-rc2", latest)
+
+	latest, err = plugin.LatestStable(t.Context(), asdf.PrereleaseQueryPrefix+"3.30")
+	require.NoError(t, err)
+	require.Equal(t, "3.30.1", latest)
+}
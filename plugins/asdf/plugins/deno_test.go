@@ -0,0 +1,45 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// TestDenoLegacyFileAndExecEnv verifies deno reads .dvmrc and keeps installed scripts in its install.
+func TestDenoLegacyFileAndExecEnv(t *testing.T) {
+	t.Parallel()
+
+	plugin, err := plugins.GetPlugin("deno")
+	require.NoError(t, err)
+	require.Equal(t, []string{".dvmrc"}, plugin.ListLegacyFilenames())
+
+	path := filepath.Join(t.TempDir(), ".dvmrc")
+	require.NoError(t, os.WriteFile(path, []byte("v1.46.3\n"), asdf.CommonFilePermission))
+
+	version, err := plugin.ParseLegacyFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "1.46.3", version)
+
+	require.Equal(t, map[string]string{"DENO_INSTALL_ROOT": "/opt/deno/2.1.4"}, plugin.ExecEnv("/opt/deno/2.1.4"))
+}
@@ -0,0 +1,243 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// errNoPython stands in for python3 missing from PATH.
+var errNoPython = errors.New("executable file not found in $PATH")
+
+// TestGcloudDependenciesSystemPython verifies the python toolchain is only
+// installed when no suitable interpreter is available or it is forced.
+func TestGcloudDependenciesSystemPython(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        string
+		err           error
+		cloudsdk      string
+		force         string
+		want          []string
+		wantProbeRuns int
+	}{
+		{name: "recent python3", output: "Python 3.11.4\n", wantProbeRuns: 1},
+		{name: "minimum python3", output: "Python 3.9.0\n", wantProbeRuns: 1},
+		{name: "old python3", output: "Python 3.8.10\n", want: []string{"python"}, wantProbeRuns: 1},
+		{name: "no python3", err: errNoPython, want: []string{"python"}, wantProbeRuns: 1},
+		{name: "unexpected output", output: "not python\n", want: []string{"python"}, wantProbeRuns: 1},
+		{name: "CLOUDSDK_PYTHON", err: errNoPython, cloudsdk: "/usr/bin/python3.12"},
+		{name: "forced", output: "Python 3.12.1\n", force: "1", want: []string{"python"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CLOUDSDK_PYTHON", tt.cloudsdk)
+			t.Setenv("ASDF_GCLOUD_FORCE_MANAGED_PYTHON", tt.force)
+
+			probeRuns := 0
+
+			plugin := p.NewGcloudPlugin().(*p.GcloudPlugin)
+			plugin.ExecOutput = func(_ context.Context, name string, args ...string) ([]byte, error) {
+				probeRuns++
+
+				require.Equal(t, "python3", name)
+				require.Equal(t, []string{"--version"}, args)
+
+				return []byte(tt.output), tt.err
+			}
+
+			require.Equal(t, tt.want, plugin.Dependencies())
+			require.Equal(t, tt.wantProbeRuns, probeRuns)
+		})
+	}
+}
+
+// TestGcloudDescribeArtifacts verifies the described platform names the
+// archive ResolveArtifacts returns.
+func TestGcloudDescribeArtifacts(t *testing.T) {
+	t.Parallel()
+
+	plugin := p.NewGcloudPlugin().(*p.GcloudPlugin)
+
+	description, err := plugin.DescribeArtifacts("500.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "tar.gz", description.ArchiveType)
+	require.Equal(t, "google-cloud-sdk/bin/gcloud", description.BinaryPath)
+
+	artifacts, err := plugin.ResolveArtifacts(t.Context(), "500.0.0")
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	require.Equal(t,
+		"google-cloud-sdk-500.0.0-"+description.Platform+"-"+description.Arch+".tar.gz", artifacts[0].Name)
+	require.Contains(t, artifacts[0].URL, artifacts[0].Name)
+}
+
+// seedGcloudArchive writes a synthesized SDK archive of version to a new
+// download directory, large enough for Download to reuse it, and returns the
+// directory.
+func seedGcloudArchive(t *testing.T, plugin *p.GcloudPlugin, version string) string {
+	t.Helper()
+
+	names, err := plugin.ArtifactNames(version)
+	require.NoError(t, err)
+
+	padding := make([]byte, 4096)
+	random := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // reproducible filler, not a secret
+	for i := range padding {
+		padding[i] = byte('a' + random.IntN(26))
+	}
+
+	downloadPath := t.TempDir()
+	archive := testutil.SynthesizeArchive(t, "tar.gz", map[string]string{
+		"google-cloud-sdk/bin/gcloud": "#!/bin/sh\n",
+		"google-cloud-sdk/README":     string(padding),
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(downloadPath, names[0]), archive, asdf.CommonFilePermission))
+
+	return downloadPath
+}
+
+// TestGcloudInstallDefaultComponents verifies the components listed in
+// $HOME/.default-cloud-sdk-components are installed with the installed
+// gcloud, without prompts, and that a failing install fails the install.
+func TestGcloudInstallDefaultComponents(t *testing.T) {
+	tests := []struct {
+		name       string
+		components string
+		failArg    string
+		wantLog    string
+		wantErr    string
+	}{
+		{name: "no components file"},
+		{
+			name:       "components",
+			components: "# tools\nkubectl\nalpha beta # channels\n",
+			wantLog:    "gcloud components install kubectl alpha beta --quiet\nCLOUDSDK_CORE_DISABLE_PROMPTS=1\n",
+		},
+		{
+			name:       "failing install",
+			components: "kubectl\n",
+			failArg:    "kubectl",
+			wantErr:    "installing gcloud components",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv(asdf.DataDirEnv, t.TempDir())
+
+			if tt.components != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(home, ".default-cloud-sdk-components"),
+					[]byte(tt.components), asdf.CommonFilePermission))
+			}
+
+			commandLog := filepath.Join(t.TempDir(), "commands.log")
+
+			asdf.MockExec(t)
+			t.Setenv("ASDF_MOCK_COMMAND_LOG", commandLog)
+			t.Setenv("ASDF_MOCK_COMMAND_LOG_ENV", "CLOUDSDK_CORE_DISABLE_PROMPTS")
+			t.Setenv("ASDF_MOCK_COMMAND_FAIL_ARG", tt.failArg)
+
+			plugin, ok := p.NewGcloudPlugin().(*p.GcloudPlugin)
+			require.True(t, ok)
+
+			downloadPath := seedGcloudArchive(t, plugin, "500.0.0")
+			installPath := t.TempDir()
+
+			err := plugin.Install(t.Context(), "500.0.0", downloadPath, installPath)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.FileExists(t, filepath.Join(installPath, "google-cloud-sdk", "bin", "gcloud"))
+
+			data, err := os.ReadFile(commandLog)
+			if tt.wantLog == "" {
+				require.ErrorIs(t, err, os.ErrNotExist)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantLog, string(data))
+		})
+	}
+}
+
+// TestGcloudExecEnvPython verifies CLOUDSDK_PYTHON is left to the user when
+// set, and otherwise points at PYTHON_ROOT before the python toolchain.
+func TestGcloudExecEnvPython(t *testing.T) {
+	tests := []struct {
+		name          string
+		cloudsdk      string
+		root          string
+		want          string
+		toolchain     bool
+		wantToolchain bool
+	}{
+		{name: "nothing installed"},
+		{name: "toolchain", toolchain: true, wantToolchain: true},
+		{name: "PYTHON_ROOT", root: "/opt/python", toolchain: true, want: filepath.Join("/opt/python", "bin", "python3")},
+		{name: "CLOUDSDK_PYTHON", cloudsdk: "/usr/bin/python3", root: "/opt/python", toolchain: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataDir := t.TempDir()
+			t.Setenv(asdf.DataDirEnv, dataDir)
+			t.Setenv(asdf.ToolVersionEnv("python"), "")
+			t.Setenv("CLOUDSDK_PYTHON", tt.cloudsdk)
+			t.Setenv("PYTHON_ROOT", tt.root)
+			t.Chdir(t.TempDir())
+
+			toolchainPython := filepath.Join(dataDir, "installs", "python", "3.12.1", "bin", "python3")
+			if tt.toolchain {
+				require.NoError(t, os.MkdirAll(filepath.Dir(toolchainPython), asdf.CommonDirectoryPermission))
+				require.NoError(t, os.WriteFile(toolchainPython, []byte("#!/bin/sh\n"), asdf.CommonExecutablePermission))
+			}
+
+			want := tt.want
+			if tt.wantToolchain {
+				want = toolchainPython
+			}
+
+			installPath := t.TempDir()
+			env := p.NewGcloudPlugin().ExecEnv(installPath)
+			require.Equal(t, filepath.Join(installPath, "google-cloud-sdk"), env["CLOUDSDK_ROOT_DIR"])
+
+			python, ok := env["CLOUDSDK_PYTHON"]
+			require.Equal(t, want != "", ok)
+			require.Equal(t, want, python)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command gen regenerates plugins.json and the README plugin table from the
+// plugin registry. It is run through go generate in the registry package.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+func main() {
+	jsonPath := flag.String("json", "plugins.json", "path of the generated plugins.json")
+	readmePath := flag.String("readme", "", "path of the README.md whose plugin table is updated")
+
+	flag.Parse()
+
+	if err := generate(*jsonPath, *readmePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate writes the catalog JSON and, when readmePath is set, the README table.
+func generate(jsonPath, readmePath string) error {
+	catalog := plugins.NewRegistry().Catalog()
+
+	data, err := plugins.CatalogJSON(catalog)
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // generated file is committed and world readable
+	if err := os.WriteFile(jsonPath, data, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", jsonPath, err)
+	}
+
+	if readmePath == "" {
+		return nil
+	}
+
+	readme, err := os.ReadFile(readmePath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", readmePath, err)
+	}
+
+	updated, err := plugins.UpdateReadme(readme, plugins.ReadmeTable(catalog))
+	if err != nil {
+		return fmt.Errorf("updating %s: %w", readmePath, err)
+	}
+
+	//nolint:gosec // README is committed and world readable
+	if err := os.WriteFile(readmePath, updated, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", readmePath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// TestGolangExecEnv verifies GOROOT and GOPATH point into the install, and
+// GOBIN only with ASDF_GOLANG_SET_GOBIN.
+func TestGolangExecEnv(t *testing.T) {
+	t.Setenv("GOROOT", "")
+	t.Setenv("GOPATH", "")
+	t.Setenv("GOBIN", "")
+
+	plugin, err := plugins.GetPlugin("golang")
+	require.NoError(t, err)
+
+	t.Setenv("ASDF_GOLANG_SET_GOBIN", "")
+	require.Equal(t, map[string]string{
+		"GOROOT": "/opt/golang/1.23.4/go",
+		"GOPATH": "/opt/golang/1.23.4/packages",
+	}, plugin.ExecEnv("/opt/golang/1.23.4"))
+
+	t.Setenv("ASDF_GOLANG_SET_GOBIN", "1")
+	require.Equal(t, map[string]string{
+		"GOROOT": "/opt/golang/1.23.4/go",
+		"GOPATH": "/opt/golang/1.23.4/packages",
+		"GOBIN":  "/opt/golang/1.23.4/bin",
+	}, plugin.ExecEnv("/opt/golang/1.23.4"))
+
+	t.Setenv("GOPATH", "/home/gopher/go")
+	t.Setenv("GOBIN", "/home/gopher/bin")
+	require.Equal(t, map[string]string{"GOROOT": "/opt/golang/1.23.4/go"}, plugin.ExecEnv("/opt/golang/1.23.4"))
+}
+
+// TestGolangGoInstallBinDir verifies binaries built with go install get shims
+// once the GOPATH bin directory of the install exists.
+func TestGolangGoInstallBinDir(t *testing.T) {
+	t.Parallel()
+
+	plugin, err := plugins.GetPlugin("golang")
+	require.NoError(t, err)
+
+	installPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(installPath, "go", "bin"), asdf.CommonDirectoryPermission))
+
+	require.Equal(t, []string{
+		filepath.Join(installPath, "go", "bin"),
+		filepath.Join(installPath, "bin"),
+	}, asdf.BinDirsOf(plugin, installPath))
+
+	goBin := filepath.Join(installPath, "packages", "bin")
+	require.NoError(t, os.MkdirAll(goBin, asdf.CommonDirectoryPermission))
+
+	require.Equal(t, []string{
+		filepath.Join(installPath, "go", "bin"),
+		filepath.Join(installPath, "bin"),
+		goBin,
+	}, asdf.BinDirsOf(plugin, installPath))
+	require.Equal(t, []string{"packages"}, asdf.HashIgnoreGlobsOf(plugin))
+}
+
+// startGoDist serves a go.dev/dl listing publishing checksum for the go1.23.4
+// archive of the running platform, and the archive itself. It returns a
+// golang plugin using it and the number of requests served.
+func startGoDist(t *testing.T, checksum string) (*p.GolangPlugin, *atomic.Int32) {
+	t.Helper()
+
+	arch, err := asdf.GetArch()
+	require.NoError(t, err)
+
+	fileName := fmt.Sprintf("go1.23.4.%s-%s.tar.gz", runtime.GOOS, arch)
+	archive := testutil.SynthesizeArchive(t, "tar.gz", map[string]string{"go/bin/go": "#!/bin/sh\necho go1.23.4\n"})
+
+	if checksum == "" {
+		sum := sha256.Sum256(archive)
+		checksum = hex.EncodeToString(sum[:])
+	}
+
+	listing := fmt.Sprintf(`[{"version": "go1.23.4", "stable": true, "files": [
+		{"filename": "go1.23.4.src.tar.gz", "os": "", "arch": "", "sha256": "00", "kind": "source"},
+		{"filename": %q, "os": %q, "arch": %q, "sha256": %q, "size": %d, "kind": "archive"}
+	]}]`, fileName, runtime.GOOS, arch, checksum, len(archive))
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+
+		switch request.URL.Path {
+		case "/dl/":
+			_, _ = writer.Write([]byte(listing))
+		case "/go/" + fileName:
+			_, _ = writer.Write(archive)
+		default:
+			http.NotFound(writer, request)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	plugin := p.NewGolangPlugin().(*p.GolangPlugin)
+	plugin.DownloadURL = server.URL + "/go"
+	plugin.DownloadListingURL = server.URL + "/dl/?mode=json&include=all"
+
+	return plugin, &requests
+}
+
+// TestGolangInstallVerifiedCache verifies the archive is checked against the
+// listing before extraction, and that later installs reuse the verified
+// archive without any request until it changes.
+func TestGolangInstallVerifiedCache(t *testing.T) {
+	t.Setenv("ASDF_GOLANG_SKIP_CHECKSUM", "")
+	t.Setenv("ASDF_GOLANG_DEFAULT_PACKAGES_FILE", filepath.Join(t.TempDir(), "none"))
+
+	plugin, requests := startGoDist(t, "")
+	downloadPath := filepath.Join(t.TempDir(), "downloads", "golang", "1.23.4")
+
+	installPath := t.TempDir()
+	require.NoError(t, plugin.Install(t.Context(), "1.23.4", downloadPath, installPath))
+	require.FileExists(t, filepath.Join(installPath, "go", "bin", "go"))
+	require.FileExists(t, filepath.Join(downloadPath, "archive.tar.gz"+asdf.ChecksumSidecarSuffix))
+	require.Equal(t, int32(2), requests.Load())
+
+	installPath = t.TempDir()
+	require.NoError(t, plugin.Install(t.Context(), "1.23.4", downloadPath, installPath))
+	require.FileExists(t, filepath.Join(installPath, "go", "bin", "go"))
+	require.Equal(t, int32(2), requests.Load())
+
+	require.NoError(t, os.WriteFile(filepath.Join(downloadPath, "archive.tar.gz"), []byte("tampered"), 0o600))
+
+	require.NoError(t, plugin.Install(t.Context(), "1.23.4", downloadPath, t.TempDir()))
+	require.Equal(t, int32(4), requests.Load())
+}
+
+// TestGolangInstallTampered verifies an archive not matching the listing is
+// deleted and never extracted.
+func TestGolangInstallTampered(t *testing.T) {
+	t.Setenv("ASDF_GOLANG_SKIP_CHECKSUM", "")
+
+	plugin, _ := startGoDist(t, "0000000000000000000000000000000000000000000000000000000000000000")
+	downloadPath, installPath := t.TempDir(), t.TempDir()
+
+	err := plugin.Install(t.Context(), "1.23.4", downloadPath, installPath)
+	require.ErrorContains(t, err, "checksum mismatch")
+	require.NoFileExists(t, filepath.Join(downloadPath, "archive.tar.gz"))
+	require.NoDirExists(t, filepath.Join(installPath, "go"))
+
+	err = plugin.Download(t.Context(), "1.22.0", downloadPath)
+	require.ErrorContains(t, err, "no Go archive published: 1.22.0")
+}
+
+// TestGolangInstallOffline verifies a pre-seeded archive is installed offline
+// without any request while its recorded checksum matches, and that offline
+// installs fail instead of asking the listing once the checksum is missing.
+func TestGolangInstallOffline(t *testing.T) {
+	t.Setenv("ASDF_GOLANG_SKIP_CHECKSUM", "")
+	t.Setenv("ASDF_GOLANG_DEFAULT_PACKAGES_FILE", filepath.Join(t.TempDir(), "none"))
+
+	plugin, requests := startGoDist(t, "")
+	downloadPath := t.TempDir()
+
+	require.NoError(t, plugin.Download(t.Context(), "1.23.4", downloadPath))
+	require.Equal(t, int32(2), requests.Load())
+
+	t.Setenv(asdf.OfflineEnv, "1")
+	require.NoError(t, asdf.CheckOfflineDownload(plugin, "1.23.4", downloadPath))
+	require.NoError(t, plugin.Install(t.Context(), "1.23.4", downloadPath, t.TempDir()))
+
+	sidecar := filepath.Join(downloadPath, "archive.tar.gz"+asdf.ChecksumSidecarSuffix)
+	require.NoError(t, os.Remove(sidecar))
+
+	err := asdf.CheckOfflineDownload(plugin, "1.23.4", downloadPath)
+	require.ErrorContains(t, err, "offline mode: download not pre-seeded: golang 1.23.4 requires "+sidecar)
+
+	err = plugin.Install(t.Context(), "1.23.4", downloadPath, t.TempDir())
+	require.ErrorContains(t, err, "offline mode: download not pre-seeded")
+	require.Equal(t, int32(2), requests.Load())
+}
@@ -0,0 +1,126 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+	"github.com/sumicare/universal-asdf-plugin/plugins/github"
+)
+
+// TestRegistryPluginsInstallHarness downloads and installs binary plugins of
+// every archive type from synthesized releases.
+func TestRegistryPluginsInstallHarness(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"bun", "deno", "kubectl", "kubectx", "k9s", "kustomize", "shellcheck", "terraform"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			plugin, err := plugins.GetPlugin(name)
+			require.NoError(t, err)
+
+			testutil.InstallHarness{
+				Config: testutil.BinaryPluginTestConfig{Name: name, Factory: func() asdf.Plugin { return plugin }},
+			}.Run(t)
+		})
+	}
+}
+
+// TestKubernetesPluginsPlatformAssets installs the kubernetes plugins from
+// synthesized assets of Apple Silicon and arm64 Linux, whose names and
+// packaging differ between plugins, and checks the asset names downloaded.
+func TestKubernetesPluginsPlatformAssets(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		artifact string
+	}{
+		{name: "kind", platform: "darwin/arm64", artifact: "kind-darwin-arm64"},
+		{name: "kind", platform: "linux/arm64", artifact: "kind-linux-arm64"},
+		{name: "k9s", platform: "darwin/arm64", artifact: "k9s_Darwin_arm64.tar.gz"},
+		{name: "k9s", platform: "linux/arm64", artifact: "k9s_Linux_arm64.tar.gz"},
+		{name: "nerdctl", platform: "linux/arm64", artifact: "nerdctl-1.2.3-linux-arm64.tar.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.platform, func(t *testing.T) {
+			plugin, err := plugins.GetPlugin(tt.name)
+			require.NoError(t, err)
+
+			testutil.InstallHarness{
+				Config:   testutil.BinaryPluginTestConfig{Name: tt.name, Factory: func() asdf.Plugin { return plugin }},
+				Platform: tt.platform,
+			}.Run(t)
+
+			names, err := plugin.(asdf.PluginWithArtifacts).ArtifactNames("1.2.3")
+			require.NoError(t, err)
+			require.Equal(t, []string{tt.artifact}, names)
+		})
+	}
+}
+
+// TestNerdctlPluginNoDarwinAssets verifies nerdctl, published for Linux only,
+// reports the platforms it supports on macOS.
+func TestNerdctlPluginNoDarwinAssets(t *testing.T) {
+	t.Setenv(asdf.ForcePlatformEnv, "darwin")
+	t.Setenv(asdf.ForceArchEnv, "arm64")
+
+	plugin, err := plugins.GetPlugin("nerdctl")
+	require.NoError(t, err)
+
+	_, err = plugin.(asdf.PluginWithArtifacts).ArtifactNames("1.2.3")
+	require.ErrorContains(t, err, "darwin (supported: linux/amd64, linux/arm64)")
+}
+
+// TestRegistryPluginsReinstall verifies every binary plugin installs again over
+// an existing install and ends up with identical files.
+func TestRegistryPluginsReinstall(t *testing.T) {
+	t.Parallel()
+
+	// Plugins whose downloads need more than the synthesized archive, each
+	// covered by its own test.
+	skip := map[string]string{
+		"cmake": "verifies downloads against the release files index",
+		"sqlc":  "verifies downloads against the release checksums asset",
+	}
+
+	for _, entry := range plugins.GetPluginRegistry().All() {
+		plugin := entry.Factory()
+		if _, skipped := skip[plugin.Name()]; skipped {
+			continue
+		}
+
+		if _, ok := plugin.(interface {
+			WithGithubClient(client *github.Client) *asdf.BinaryPlugin
+		}); !ok {
+			continue
+		}
+
+		t.Run(plugin.Name(), func(t *testing.T) {
+			t.Parallel()
+
+			testutil.InstallHarness{
+				Config:    testutil.BinaryPluginTestConfig{Name: plugin.Name(), Factory: entry.Factory},
+				Reinstall: true,
+			}.Run(t)
+		})
+	}
+}
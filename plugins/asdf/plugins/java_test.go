@@ -0,0 +1,220 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// javaReleaseNames are the canned Adoptium release names, more than one page.
+var javaReleaseNames = []string{ //nolint:gochecknoglobals // test fixture
+	"jdk8u402-b06", "jdk8u412-b08",
+	"jdk-11.0.22+7", "jdk-11.0.23+9",
+	"jdk-17.0.1+12", "jdk-17.0.2+8", "jdk-17.0.3+7", "jdk-17.0.4+8", "jdk-17.0.5+8",
+	"jdk-17.0.6+10", "jdk-17.0.7+7", "jdk-17.0.8+7", "jdk-17.0.9+9", "jdk-17.0.10+7", "jdk-17.0.11+9",
+	"jdk-21+35", "jdk-21.0.1+12", "jdk-21.0.2+13", "jdk-21.0.3+9",
+	"jdk-22+36", "jdk-22.0.1+8",
+}
+
+// startAdoptiumAPI serves the canned release names, paginated like the
+// Adoptium API, and the JDK 21.0.3+9 binary with its checksum for the
+// current platform, and returns the API URL.
+func startAdoptiumAPI(t *testing.T) string {
+	t.Helper()
+
+	home := "jdk-21.0.3+9/"
+	if runtime.GOOS == "darwin" {
+		home += "Contents/Home/"
+	}
+
+	archive := testutil.SynthesizeArchive(t, "tar.gz", map[string]string{
+		home + "bin/java": "#!/bin/sh\necho openjdk 21.0.3\n",
+		home + "release":  "JAVA_VERSION=\"21.0.3\"\n",
+	})
+	sum := sha256.Sum256(archive)
+
+	osName := map[string]string{"linux": "linux", "darwin": "mac"}[runtime.GOOS]
+	archName := map[string]string{"amd64": "x64", "arm64": "aarch64"}[runtime.GOARCH]
+	binaryPath := "/version/jdk-21.0.3+9/" + osName + "/" + archName + "/jdk/hotspot/normal/eclipse"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/info/release_names", func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+		if query.Get("os") != osName || query.Get("architecture") != archName || query.Get("release_type") != "ga" {
+			http.Error(writer, "unexpected query "+request.URL.RawQuery, http.StatusBadRequest)
+
+			return
+		}
+
+		page, _ := strconv.Atoi(query.Get("page"))
+		size, _ := strconv.Atoi(query.Get("page_size"))
+
+		start := page * size
+		if start >= len(javaReleaseNames) {
+			http.NotFound(writer, request)
+
+			return
+		}
+
+		_ = json.NewEncoder(writer).Encode(map[string][]string{
+			"releases": javaReleaseNames[start:min(start+size, len(javaReleaseNames))],
+		})
+	})
+	mux.HandleFunc("/v3/binary"+binaryPath, func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write(archive)
+	})
+	mux.HandleFunc("/v3/checksum"+binaryPath, func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(hex.EncodeToString(sum[:]) + "  OpenJDK21U-jdk.tar.gz\n"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+// newTestJavaPlugin returns a java plugin listing from a canned Adoptium API.
+func newTestJavaPlugin(t *testing.T) *p.JavaPlugin {
+	t.Helper()
+
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skipf("temurin builds are not listed for %s", runtime.GOOS)
+	}
+
+	plugin := p.NewJavaPlugin().(*p.JavaPlugin)
+	plugin.APIURL = startAdoptiumAPI(t)
+
+	return plugin
+}
+
+// TestJavaListAll verifies every page of release names is listed as temurin versions.
+func TestJavaListAll(t *testing.T) {
+	t.Parallel()
+
+	plugin := newTestJavaPlugin(t)
+
+	versions, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Len(t, versions, len(javaReleaseNames))
+	require.Equal(t, []string{"temurin-8.0.402+6", "temurin-8.0.412+8", "temurin-11.0.22+7"}, versions[:3])
+	require.Equal(t, "temurin-22.0.1+8", versions[len(versions)-1])
+
+	for query, expected := range map[string]string{
+		"":                "temurin-22.0.1+8",
+		"21":              "temurin-21.0.3+9",
+		"17.0.1":          "temurin-17.0.11+9",
+		"temurin-17.0.1+": "temurin-17.0.1+12",
+		"8":               "temurin-8.0.412+8",
+	} {
+		latest, err := plugin.LatestStable(t.Context(), query)
+		require.NoError(t, err, query)
+		require.Equal(t, expected, latest, query)
+	}
+}
+
+// TestJavaInstall verifies the verified JDK is installed without its
+// top-level directory and JAVA_HOME points at the JDK home.
+func TestJavaInstall(t *testing.T) {
+	t.Parallel()
+
+	plugin := newTestJavaPlugin(t)
+
+	downloadPath, installPath := t.TempDir(), t.TempDir()
+
+	require.NoError(t, plugin.Download(t.Context(), "temurin-21.0.3+9", downloadPath))
+	require.NoError(t, plugin.Install(t.Context(), "temurin-21.0.3+9", downloadPath, installPath))
+
+	java := filepath.Join(installPath, filepath.FromSlash(plugin.ListBinPaths()), "java")
+	info, err := os.Stat(java)
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode().Perm()&0o111)
+
+	home := plugin.ExecEnv(installPath)["JAVA_HOME"]
+	require.Equal(t, filepath.Dir(filepath.Dir(java)), home)
+	require.FileExists(t, filepath.Join(home, "release"))
+	require.Equal(t, home, plugin.InstallRoot(installPath))
+
+	err = plugin.Download(t.Context(), "21.0.3", t.TempDir())
+	require.ErrorContains(t, err, "not a temurin version")
+}
+
+// TestJavaLegacyFiles verifies .java-version and .sdkmanrc pins map to temurin versions.
+func TestJavaLegacyFiles(t *testing.T) {
+	t.Parallel()
+
+	plugin := p.NewJavaPlugin()
+	require.Equal(t, []string{".java-version", ".sdkmanrc"}, plugin.ListLegacyFilenames())
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+		wantErr  string
+	}{
+		{name: "exact build", file: ".java-version", content: "21.0.3+9\n", expected: "temurin-21.0.3+9"},
+		{name: "temurin version", file: ".java-version", content: "temurin-17.0.11+9\n", expected: "temurin-17.0.11+9"},
+		{name: "patch release", file: ".java-version", content: "21.0.3\n", expected: "latest:temurin-21.0.3+"},
+		{name: "feature release", file: ".java-version", content: "21\n", expected: "latest:temurin-21"},
+		{
+			name:     "sdkmanrc temurin",
+			file:     ".sdkmanrc",
+			content:  "# Enable auto-env through the sdkman_auto_env config\nmaven=3.9.9\njava=21.0.3-tem\n",
+			expected: "latest:temurin-21.0.3+",
+		},
+		{name: "sdkmanrc without java", file: ".sdkmanrc", content: "maven=3.9.9\n", expected: ""},
+		{
+			name:    "sdkmanrc other vendor",
+			file:    ".sdkmanrc",
+			content: "java=21.0.3-zulu\n",
+			wantErr: "unsupported .sdkmanrc java distribution: 21.0.3-zulu",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), asdf.CommonFilePermission))
+
+			version, err := plugin.ParseLegacyFile(path)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
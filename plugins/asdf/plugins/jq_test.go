@@ -0,0 +1,42 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// TestJqDownloadInstall verifies jq downloads and installs its raw
+// release binary from the install harness into bin.
+func TestJqDownloadInstall(t *testing.T) {
+	t.Parallel()
+
+	plugin := p.NewJqPlugin()
+
+	installPath := testutil.InstallHarness{
+		Config:  testutil.BinaryPluginTestConfig{Name: "jq", Factory: func() asdf.Plugin { return plugin }},
+		Version: "1.7.1",
+	}.Run(t)
+
+	require.Equal(t, []string{filepath.Join(installPath, "bin")}, asdf.BinDirsOf(plugin, installPath))
+}
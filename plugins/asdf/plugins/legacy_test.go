@@ -0,0 +1,73 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// TestLegacyFilesWindowsEncoding verifies legacy version files saved with a
+// UTF-8 byte order mark, CRLF line endings or trailing spaces pin the same
+// version as plain ones, and empty files pin nothing.
+func TestLegacyFilesWindowsEncoding(t *testing.T) {
+	t.Parallel()
+
+	const bom = "\ufeff"
+
+	tests := []struct {
+		name     string
+		tool     string
+		file     string
+		content  string
+		expected string
+	}{
+		{name: "zig newline", tool: "zig", file: ".zig-version", content: "0.14.0\n", expected: "0.14.0"},
+		{name: "zig bom crlf", tool: "zig", file: ".zig-version", content: bom + "0.14.0\r\n", expected: "0.14.0"},
+		{name: "zig empty", tool: "zig", file: ".zig-version", content: "", expected: ""},
+		{name: "nodejs bom crlf", tool: "nodejs", file: ".nvmrc", content: bom + "v20.11.1\r\n", expected: "20.11.1"},
+		{name: "nodejs empty", tool: "nodejs", file: ".nvmrc", content: "\r\n", expected: ""},
+		{name: "golang trailing spaces", tool: "golang", file: ".go-version", content: "1.22.5  \r\n", expected: "1.22.5"},
+		{name: "golang bom go.mod", tool: "golang", file: "go.mod", content: bom + "module example.com/app\r\n\r\ngo 1.22.5\r\n", expected: "1.22.5"},
+		{name: "rust crlf", tool: "rust", file: "rust-toolchain", content: "1.80.0\r\n", expected: "1.80.0"},
+		{name: "rust bom toml", tool: "rust", file: "rust-toolchain.toml", content: bom + "[toolchain]\r\nchannel = \"1.80.0\"\r\n", expected: "1.80.0"},
+		{name: "java bom sdkmanrc", tool: "java", file: ".sdkmanrc", content: bom + "java=21.0.3+9-tem\r\n", expected: "temurin-21.0.3+9"},
+		{name: "bun bom package.json", tool: "bun", file: "package.json", content: bom + "{\"engines\": {\"bun\": \"1.1.20\"}}\r\n", expected: "1.1.20"},
+		{name: "opentofu bom", tool: "opentofu", file: ".opentofu-version", content: bom + "1.8.1 \r\n", expected: "1.8.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			plugin, err := plugins.GetPlugin(tt.tool)
+			require.NoError(t, err)
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), asdf.CommonFilePermission))
+
+			version, err := plugin.ParseLegacyFile(path)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+)
+
+// TestLinkerdServerVersion verifies the control plane version is read from
+// captured `linkerd version` outputs.
+func TestLinkerdServerVersion(t *testing.T) {
+	tests := []struct {
+		sample string
+		want   string
+	}{
+		{sample: "linkerd-stable.txt", want: "2.14.10"},
+		{sample: "linkerd-enterprise.txt", want: "2.15.4"},
+		{sample: "linkerd-unavailable.txt", want: ""},
+	}
+
+	skew := p.NewLinkerdPlugin().(*p.LinkerdPlugin).VersionSkew()
+
+	for _, tt := range tests {
+		t.Run(tt.sample, func(t *testing.T) {
+			output, err := os.ReadFile(filepath.Join("testdata", "skew", tt.sample))
+			require.NoError(t, err)
+
+			got, err := skew.ServerVersion(output)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// mavenIndexPage is a canned Apache directory index of a Maven release series.
+const mavenIndexPage = `<html><body><h1>Index of /dist/maven/maven-3</h1><pre>
+<a href="../">Parent Directory</a>
+<a href="3.8.8/">3.8.8/</a>   2023-03-08 14:02    -
+<a href="3.9.9/">3.9.9/</a>   2024-08-17 20:12    -
+</pre></body></html>`
+
+// startMavenDist serves the maven-3 and maven-4 indexes and the 3.9.9 binary
+// distribution, published with checksum, and returns the dist URL.
+func startMavenDist(t *testing.T, checksum string) string {
+	t.Helper()
+
+	archive := testutil.SynthesizeArchive(t, "tar.gz", map[string]string{
+		"apache-maven-3.9.9/bin/mvn":           "#!/bin/sh\necho maven 3.9.9\n",
+		"apache-maven-3.9.9/conf/settings.xml": "<settings/>",
+	})
+
+	if checksum == "" {
+		sum := sha512.Sum512(archive)
+		checksum = hex.EncodeToString(sum[:])
+	}
+
+	files := map[string]string{
+		"/maven-3/": mavenIndexPage,
+		"/maven-4/": `<a href="4.0.0-rc-2/">4.0.0-rc-2/</a>`,
+		"/maven-3/3.9.9/binaries/apache-maven-3.9.9-bin.tar.gz":        string(archive),
+		"/maven-3/3.9.9/binaries/apache-maven-3.9.9-bin.tar.gz.sha512": checksum + "  apache-maven-3.9.9-bin.tar.gz\n",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		content, ok := files[request.URL.Path]
+		if !ok {
+			http.NotFound(writer, request)
+
+			return
+		}
+
+		_, _ = writer.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL + "/"
+}
+
+// TestMavenListAll verifies Maven 3 and 4 versions are scraped from the dist indexes.
+func TestMavenListAll(t *testing.T) {
+	t.Parallel()
+
+	plugin := p.NewMavenPlugin().(*p.MavenPlugin)
+	plugin.DistURL = startMavenDist(t, "")
+
+	versions, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []string{"3.8.8", "3.9.9", "4.0.0-rc-2"}, versions)
+
+	latest, err := plugin.LatestStable(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, "3.9.9", latest)
+}
+
+// TestMavenInstall verifies the verified archive is installed without its top-level directory.
+func TestMavenInstall(t *testing.T) {
+	t.Parallel()
+
+	plugin := p.NewMavenPlugin().(*p.MavenPlugin)
+	plugin.DistURL = startMavenDist(t, "")
+
+	downloadPath, installPath := t.TempDir(), t.TempDir()
+
+	require.NoError(t, plugin.Download(t.Context(), "3.9.9", downloadPath))
+	require.NoError(t, plugin.Install(t.Context(), "3.9.9", downloadPath, installPath))
+
+	info, err := os.Stat(filepath.Join(installPath, "bin", "mvn"))
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode().Perm()&0o111)
+	require.FileExists(t, filepath.Join(installPath, "conf", "settings.xml"))
+	require.NoDirExists(t, filepath.Join(installPath, "apache-maven-3.9.9"))
+
+	require.Equal(t, map[string]string{"M2_HOME": installPath}, plugin.ExecEnv(installPath))
+}
+
+// TestMavenDownloadChecksumMismatch verifies an archive not matching its SHA512 is rejected and deleted.
+func TestMavenDownloadChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	plugin := p.NewMavenPlugin().(*p.MavenPlugin)
+	plugin.DistURL = startMavenDist(t, "deadbeef")
+
+	downloadPath := t.TempDir()
+
+	err := plugin.Download(t.Context(), "3.9.9", downloadPath)
+	require.ErrorContains(t, err, "checksum mismatch")
+	require.NoFileExists(t, filepath.Join(downloadPath, "apache-maven-3.9.9-bin.tar.gz"))
+}
+
+// TestMavenWrapperProperties verifies the version is read from the wrapper distributionUrl.
+func TestMavenWrapperProperties(t *testing.T) {
+	t.Parallel()
+
+	plugin := p.NewMavenPlugin()
+	require.Equal(t, []string{".mvn/wrapper/maven-wrapper.properties"}, plugin.ListLegacyFilenames())
+
+	path := filepath.Join(t.TempDir(), "maven-wrapper.properties")
+	require.NoError(t, os.WriteFile(path, []byte(`wrapperVersion=3.3.2
+distributionType=only-script
+distributionUrl=https://repo.maven.apache.org/maven2/org/apache/maven/apache-maven/3.9.9/apache-maven-3.9.9-bin.zip
+`), asdf.CommonFilePermission))
+
+	version, err := plugin.ParseLegacyFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "3.9.9", version)
+
+	require.NoError(t, os.WriteFile(path, []byte("wrapperVersion=3.3.2\n"), asdf.CommonFilePermission))
+
+	_, err = plugin.ParseLegacyFile(path)
+	require.ErrorContains(t, err, "no maven distributionUrl found")
+}
@@ -0,0 +1,55 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// TestPluginEnvSettingsDocumented checks that the help of the plugins reading
+// settings through asdf.PluginEnv names every setting they read.
+func TestPluginEnvSettingsDocumented(t *testing.T) {
+	t.Parallel()
+
+	for name, keys := range map[string][]string{
+		"golang": {"DEFAULT_PACKAGES_FILE", "SKIP_CHECKSUM"},
+		"nodejs": {"AUTO_ENABLE_COREPACK"},
+		"python": {"DEFAULT_PACKAGES_FILE", "PATCH_URL", "PATCHES_DIRECTORY", "SKIP_PREFLIGHT"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			plugin, err := plugins.GetPlugin(name)
+			require.NoError(t, err)
+
+			env := asdf.PluginEnv(name)
+			config := asdf.HelpConfig(plugin)
+
+			for _, key := range keys {
+				require.Contains(t, config, env.Name(key))
+			}
+
+			for _, setting := range env.Accessed() {
+				require.Contains(t, config, env.Name(setting.Key))
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// timestampProto stands in for a well-known type shipped in protoc archives.
+const timestampProto = "include/google/protobuf/timestamp.proto"
+
+// TestProtocInstallIncludes verifies the well-known types are installed next to bin.
+func TestProtocInstallIncludes(t *testing.T) {
+	t.Parallel()
+
+	installPath := testutil.InstallHarness{
+		Config:        testutil.BinaryPluginTestConfig{Name: "protoc", Factory: p.NewProtocPlugin},
+		ArchiveFiles:  map[string]string{timestampProto: "syntax = \"proto3\";\n"},
+		ExpectedFiles: []string{"bin/protoc", timestampProto},
+		Reinstall:     true,
+	}.Run(t)
+
+	data, err := os.ReadFile(filepath.Join(installPath, filepath.FromSlash(timestampProto)))
+	require.NoError(t, err)
+	require.Equal(t, "syntax = \"proto3\";\n", string(data))
+}
+
+// TestProtocExecEnv verifies PROTOC_INCLUDE points at the installed includes while PATH only gets bin.
+func TestProtocExecEnv(t *testing.T) {
+	t.Parallel()
+
+	plugin := p.NewProtocPlugin()
+
+	require.Equal(t, map[string]string{"PROTOC_INCLUDE": "/opt/protoc/29.3/include"}, plugin.ExecEnv("/opt/protoc/29.3"))
+	require.Nil(t, plugin.ExecEnv(""))
+	require.Equal(t, "bin", plugin.ListBinPaths())
+}
+
+// TestProtocArchMap verifies the asset architectures, including the macOS universal binary.
+func TestProtocArchMap(t *testing.T) {
+	t.Parallel()
+
+	plugin, ok := p.NewProtocPlugin().(*p.ProtocPlugin)
+	require.True(t, ok)
+
+	for _, tc := range []struct {
+		goos, goarch, want string
+	}{
+		{"linux", "amd64", "x86_64"},
+		{"linux", "arm64", "aarch_64"},
+		{"darwin", "amd64", "universal_binary"},
+		{"darwin", "arm64", "universal_binary"},
+	} {
+		arch, ok := plugin.Config.MappedArch(tc.goos, tc.goarch)
+		require.True(t, ok, "%s/%s", tc.goos, tc.goarch)
+		require.Equal(t, tc.want, arch, "%s/%s", tc.goos, tc.goarch)
+	}
+}
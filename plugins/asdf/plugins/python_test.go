@@ -0,0 +1,54 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// TestPythonExecEnv verifies PYTHON_ROOT locates the interpreter of an
+// install, and PYTHONUSERBASE points into it unless the user configured one.
+func TestPythonExecEnv(t *testing.T) {
+	plugin, err := plugins.GetPlugin("python")
+	require.NoError(t, err)
+
+	installPath := t.TempDir()
+	interpreter := filepath.Join(installPath, "bin", "python3")
+	require.NoError(t, os.MkdirAll(filepath.Dir(interpreter), asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(interpreter, []byte("#!/bin/sh\n"), asdf.CommonExecutablePermission))
+
+	t.Setenv("PYTHONUSERBASE", "")
+
+	env := plugin.ExecEnv(installPath)
+	require.Equal(t, map[string]string{
+		"PYTHON_ROOT":    installPath,
+		"PYTHONUSERBASE": installPath,
+	}, env)
+	require.FileExists(t, filepath.Join(env["PYTHON_ROOT"], "bin", "python3"))
+	require.Equal(t, []string{filepath.Join(installPath, "bin")}, asdf.BinDirsOf(plugin, installPath))
+
+	t.Setenv("PYTHONUSERBASE", "/home/pythonista/.local")
+	require.Equal(t, map[string]string{"PYTHON_ROOT": installPath}, plugin.ExecEnv(installPath))
+
+	require.Nil(t, plugin.ExecEnv(""))
+}
@@ -18,6 +18,7 @@ package plugins
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	p "github.com/sumicare/universal-asdf-plugin/plugins"
@@ -26,9 +27,12 @@ import (
 
 type (
 	// PluginEntry represents a single plugin registration with its names and factory.
+	// Factories only run on lookup, so each CLI invocation constructs the one
+	// plugin it uses rather than all of them.
 	PluginEntry struct {
-		Factory func() asdf.Plugin
-		Names   []string
+		Factory     func() asdf.Plugin
+		Description string
+		Names       []string
 	}
 
 	// Registry holds all registered plugins and provides lookup by name.
@@ -49,248 +53,339 @@ func NewRegistry() *Registry {
 
 	// Register all plugins
 	registry.register(&PluginEntry{
-		Names:   []string{"argo"},
-		Factory: p.NewArgoPlugin,
+		Names:       []string{"argo"},
+		Description: "Argo Workflows CLI",
+		Factory:     p.NewArgoPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"argocd"},
-		Factory: p.NewArgoCDPlugin,
+		Names:       []string{"argocd"},
+		Description: "Argo CD CLI",
+		Factory:     p.NewArgoCDPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"argo-rollouts"},
-		Factory: p.NewArgoRolloutsPlugin,
+		Names:       []string{"argo-rollouts"},
+		Description: "Argo Rollouts CLI",
+		Factory:     p.NewArgoRolloutsPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"checkov"},
-		Factory: p.NewCheckovPlugin,
+		Names:       []string{"checkov"},
+		Description: "Infrastructure as Code scanner",
+		Factory:     p.NewCheckovPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"cmake"},
-		Factory: p.NewCmakePlugin,
+		Names:       []string{"cmake"},
+		Description: "Cross-platform build system",
+		Factory:     p.NewCmakePlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"cosign"},
-		Factory: p.NewCosignPlugin,
+		Names:       []string{"cosign"},
+		Description: "Container signing",
+		Factory:     p.NewCosignPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"doctl"},
-		Factory: p.NewDoctlPlugin,
+		Names:       []string{"deno"},
+		Description: "JavaScript and TypeScript runtime",
+		Factory:     p.NewDenoPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"jq"},
-		Factory: p.NewJqPlugin,
+		Names:       []string{"doctl"},
+		Description: "DigitalOcean CLI",
+		Factory:     p.NewDoctlPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"k9s"},
-		Factory: p.NewK9sPlugin,
+		Names:       []string{"java"},
+		Description: "Eclipse Temurin JDK",
+		Factory:     p.NewJavaPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"kind"},
-		Factory: p.NewKindPlugin,
+		Names:       []string{"jq"},
+		Description: "JSON processor",
+		Factory:     p.NewJqPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"ko"},
-		Factory: p.NewKoPlugin,
+		Names:       []string{"k9s"},
+		Description: "Kubernetes CLI UI",
+		Factory:     p.NewK9sPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"kubectl"},
-		Factory: p.NewKubectlPlugin,
+		Names:       []string{"kind"},
+		Description: "Kubernetes in Docker",
+		Factory:     p.NewKindPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"lazygit"},
-		Factory: p.NewLazygitPlugin,
+		Names:       []string{"ko"},
+		Description: "Container image builder for Go",
+		Factory:     p.NewKoPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"linkerd"},
-		Factory: p.NewLinkerdPlugin,
+		Names:       []string{"kubectl"},
+		Description: "Kubernetes CLI",
+		Factory:     p.NewKubectlPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"nerdctl"},
-		Factory: p.NewNerdctlPlugin,
+		Names:       []string{"kubectx"},
+		Description: "Kubernetes context and namespace switcher",
+		Factory:     p.NewKubectxPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"ginkgo"},
-		Factory: p.NewGinkgoPlugin,
+		Names:       []string{"kustomize"},
+		Description: "Kubernetes configuration management",
+		Factory:     p.NewKustomizePlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"github-cli", "gh"},
-		Factory: p.NewGithubCliPlugin,
+		Names:       []string{"lazygit"},
+		Description: "Git terminal UI",
+		Factory:     p.NewLazygitPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"gitsign"},
-		Factory: p.NewGitsignPlugin,
+		Names:       []string{"linkerd"},
+		Description: "Service mesh CLI",
+		Factory:     p.NewLinkerdPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"gitleaks"},
-		Factory: p.NewGitleaksPlugin,
+		Names:       []string{"maven"},
+		Description: "Java build tool",
+		Factory:     p.NewMavenPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"goreleaser"},
-		Factory: p.NewGoreleaserPlugin,
+		Names:       []string{"nerdctl"},
+		Description: "containerd CLI",
+		Factory:     p.NewNerdctlPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"golang", "go"},
-		Factory: p.NewGolangPlugin,
+		Names:       []string{"ginkgo"},
+		Description: "Go testing framework",
+		Factory:     p.NewGinkgoPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"golangci-lint"},
-		Factory: p.NewGolangciLintPlugin,
+		Names:       []string{"github-cli", "gh"},
+		Description: "GitHub CLI",
+		Factory:     p.NewGithubCliPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"grype"},
-		Factory: p.NewGrypePlugin,
+		Names:       []string{"gitsign"},
+		Description: "Git commit signing",
+		Factory:     p.NewGitsignPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"gcloud"},
-		Factory: p.NewGcloudPlugin,
+		Names:       []string{"gitleaks"},
+		Description: "Detect secrets in code",
+		Factory:     p.NewGitleaksPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"aws-nuke"},
-		Factory: func() asdf.Plugin { return p.NewAwsNukePlugin() },
+		Names:       []string{"goreleaser"},
+		Description: "Release automation",
+		Factory:     p.NewGoreleaserPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"aws-sso-cli"},
-		Factory: p.NewAwsSsoCliPlugin,
+		Names:       []string{"golang", "go"},
+		Description: "Go programming language",
+		Factory:     p.NewGolangPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"awscli"},
-		Factory: p.NewAwscliPlugin,
+		Names:       []string{"golangci-lint"},
+		Description: "Go linters aggregator",
+		Factory:     p.NewGolangciLintPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"buf"},
-		Factory: p.NewBufPlugin,
+		Names:       []string{"grype"},
+		Description: "Vulnerability scanner",
+		Factory:     p.NewGrypePlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"helm"},
-		Factory: p.NewHelmPlugin,
+		Names:       []string{"gcloud"},
+		Description: "Google Cloud SDK",
+		Factory:     p.NewGcloudPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"python"},
-		Factory: p.NewPythonPlugin,
+		Names:       []string{"aws-nuke"},
+		Description: "AWS resource cleanup",
+		Factory:     func() asdf.Plugin { return p.NewAwsNukePlugin() },
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"pipx"},
-		Factory: p.NewPipxPlugin,
+		Names:       []string{"aws-sso-cli"},
+		Description: "AWS SSO CLI",
+		Factory:     p.NewAwsSsoCliPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"rust"},
-		Factory: p.NewRustPlugin,
+		Names:       []string{"awscli"},
+		Description: "AWS Command Line Interface",
+		Factory:     p.NewAwscliPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"sccache"},
-		Factory: p.NewSccachePlugin,
+		Names:       []string{"buf"},
+		Description: "Protobuf tooling",
+		Factory:     p.NewBufPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"shellcheck"},
-		Factory: p.NewShellcheckPlugin,
+		Names:       []string{"bun"},
+		Description: "JavaScript runtime and toolkit",
+		Factory:     p.NewBunPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"sops"},
-		Factory: p.NewSopsPlugin,
+		Names:       []string{"helm"},
+		Description: "Kubernetes package manager",
+		Factory:     p.NewHelmPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"shfmt"},
-		Factory: p.NewShfmtPlugin,
+		Names:       []string{"python"},
+		Description: "Python runtime",
+		Factory:     p.NewPythonPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"syft"},
-		Factory: p.NewSyftPlugin,
+		Names:       []string{"pipx"},
+		Description: "Python app installer",
+		Factory:     p.NewPipxPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"terraform"},
-		Factory: p.NewTerraformPlugin,
+		Names:       []string{"rust"},
+		Description: "Rust toolchain",
+		Factory:     p.NewRustPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"terragrunt"},
-		Factory: p.NewTerragruntPlugin,
+		Names:       []string{"sccache"},
+		Description: "Shared compilation cache",
+		Factory:     p.NewSccachePlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"terrascan"},
-		Factory: p.NewTerrascanPlugin,
+		Names:       []string{"shellcheck"},
+		Description: "Shell script analyzer",
+		Factory:     p.NewShellcheckPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"tfupdate"},
-		Factory: p.NewTfupdatePlugin,
+		Names:       []string{"sops"},
+		Description: "Secrets manager",
+		Factory:     p.NewSopsPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"tflint"},
-		Factory: p.NewTflintPlugin,
+		Names:       []string{"shfmt"},
+		Description: "Shell formatter",
+		Factory:     p.NewShfmtPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"trivy"},
-		Factory: p.NewTrivyPlugin,
+		Names:       []string{"syft"},
+		Description: "SBOM generator",
+		Factory:     p.NewSyftPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"vultr-cli"},
-		Factory: p.NewVultrCliPlugin,
+		Names:       []string{"terraform"},
+		Description: "Infrastructure as Code",
+		Factory:     p.NewTerraformPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"nodejs", "node"},
-		Factory: p.NewNodejsPlugin,
+		Names:       []string{"terragrunt"},
+		Description: "Terraform wrapper",
+		Factory:     p.NewTerragruntPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"opentofu"},
-		Factory: p.NewOpentofuPlugin,
+		Names:       []string{"terrascan"},
+		Description: "IaC security scanner",
+		Factory:     p.NewTerrascanPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"protoc"},
-		Factory: p.NewProtocPlugin,
+		Names:       []string{"tfupdate"},
+		Description: "Terraform updater",
+		Factory:     p.NewTfupdatePlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"protoc-gen-go"},
-		Factory: p.NewProtocGenGoPlugin,
+		Names:       []string{"tflint"},
+		Description: "Terraform linter",
+		Factory:     p.NewTflintPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"protoc-gen-go-grpc", "asdf-protoc-gen-go-grpc"},
-		Factory: p.NewProtocGenGoGrpcPlugin,
+		Names:       []string{"trivy"},
+		Description: "Security scanner",
+		Factory:     p.NewTrivyPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"protoc-gen-grpc-web"},
-		Factory: p.NewProtocGenGrpcWebPlugin,
+		Names:       []string{"vultr-cli"},
+		Description: "Vultr CLI",
+		Factory:     p.NewVultrCliPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"protolint"},
-		Factory: p.NewProtolintPlugin,
+		Names:       []string{"nodejs", "node"},
+		Description: "Node.js runtime",
+		Factory:     p.NewNodejsPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"sqlc"},
-		Factory: p.NewSqlcPlugin,
+		Names:       []string{"opentofu"},
+		Description: "Terraform fork",
+		Factory:     p.NewOpentofuPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"tekton-cli"},
-		Factory: p.NewTektonCliPlugin,
+		Names:       []string{"protoc"},
+		Description: "Protocol Buffers compiler",
+		Factory:     p.NewProtocPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"telepresence"},
-		Factory: p.NewTelepresencePlugin,
+		Names:       []string{"protoc-gen-go"},
+		Description: "Go protobuf generator",
+		Factory:     p.NewProtocGenGoPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"traefik"},
-		Factory: p.NewTraefikPlugin,
+		Names:       []string{"protoc-gen-go-grpc", "asdf-protoc-gen-go-grpc"},
+		Description: "gRPC Go protoc plugin",
+		Factory:     p.NewProtocGenGoGrpcPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"velero"},
-		Factory: p.NewVeleroPlugin,
+		Names:       []string{"protoc-gen-grpc-web"},
+		Description: "gRPC-Web protoc plugin",
+		Factory:     p.NewProtocGenGrpcWebPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"upx"},
-		Factory: p.NewUpxPlugin,
+		Names:       []string{"protolint"},
+		Description: "Protocol Buffers linter",
+		Factory:     p.NewProtolintPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"uv"},
-		Factory: p.NewUvPlugin,
+		Names:       []string{"sqlc"},
+		Description: "SQL compiler",
+		Factory:     p.NewSqlcPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"yq"},
-		Factory: p.NewYqPlugin,
+		Names:       []string{"tekton-cli"},
+		Description: "Tekton Pipelines CLI",
+		Factory:     p.NewTektonCliPlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"zig"},
-		Factory: p.NewZigPlugin,
+		Names:       []string{"telepresence"},
+		Description: "Kubernetes dev tool",
+		Factory:     p.NewTelepresencePlugin,
 	})
 	registry.register(&PluginEntry{
-		Names:   []string{"asdf"},
-		Factory: p.NewAsdfPlugin,
+		Names:       []string{"traefik"},
+		Description: "Cloud-native proxy",
+		Factory:     p.NewTraefikPlugin,
+	})
+	registry.register(&PluginEntry{
+		Names:       []string{"velero"},
+		Description: "Kubernetes backup",
+		Factory:     p.NewVeleroPlugin,
+	})
+	registry.register(&PluginEntry{
+		Names:       []string{"upx"},
+		Description: "Executable packer",
+		Factory:     p.NewUpxPlugin,
+	})
+	registry.register(&PluginEntry{
+		Names:       []string{"uv"},
+		Description: "Python package manager",
+		Factory:     p.NewUvPlugin,
+	})
+	registry.register(&PluginEntry{
+		Names:       []string{"yq"},
+		Description: "YAML processor",
+		Factory:     p.NewYqPlugin,
+	})
+	registry.register(&PluginEntry{
+		Names:       []string{"zig"},
+		Description: "Zig programming language",
+		Factory:     p.NewZigPlugin,
+	})
+	registry.register(&PluginEntry{
+		Names:       []string{"asdf"},
+		Description: "asdf version manager (self-management)",
+		Factory:     p.NewAsdfPlugin,
 	})
 
 	return registry
@@ -341,6 +436,23 @@ func GetPlugin(name string) (asdf.Plugin, error) {
 	return plugin, nil
 }
 
+// AvailablePlugins returns the sorted primary names of the registered plugins
+// install-plugin installs when none are named: all of them but asdf, which is
+// only bootstrapped when asked for.
+func AvailablePlugins() []string {
+	names := make([]string, 0, len(DefaultRegistry.All()))
+
+	for _, entry := range DefaultRegistry.All() {
+		if len(entry.Names) > 0 && entry.Names[0] != "asdf" {
+			names = append(names, entry.Names[0])
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
 // GetPluginRegistry returns the global plugin registry for iteration and testing.
 func GetPluginRegistry() *Registry {
 	return DefaultRegistry
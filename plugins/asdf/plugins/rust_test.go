@@ -0,0 +1,118 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// fakeRustupInit installs stub rustc and cargo binaries and a rustup that logs
+// its arguments and rejects anything named "missing-*".
+const fakeRustupInit = `#!/bin/sh
+set -e
+mkdir -p "$CARGO_HOME/bin"
+printf '#!/bin/sh\necho rustc\n' > "$CARGO_HOME/bin/rustc"
+printf '#!/bin/sh\necho cargo\n' > "$CARGO_HOME/bin/cargo"
+cat > "$CARGO_HOME/bin/rustup" <<'RUSTUP'
+#!/bin/sh
+case "$5" in missing-*) echo "error: $5 is unavailable" >&2; exit 1 ;; esac
+echo "$@" >> "$RUSTUP_HOME/rustup.log"
+RUSTUP
+chmod +x "$CARGO_HOME/bin/rustc" "$CARGO_HOME/bin/cargo" "$CARGO_HOME/bin/rustup"
+`
+
+// newRustTestPlugin returns a rust plugin downloading fakeRustupInit from a
+// local server.
+func newRustTestPlugin(t *testing.T) *p.RustPlugin {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(fakeRustupInit))
+	}))
+	t.Cleanup(server.Close)
+
+	plugin, ok := p.NewRustPlugin().(*p.RustPlugin)
+	require.True(t, ok)
+
+	plugin.RustupURL = server.URL
+
+	return plugin
+}
+
+// writeRustDefaults writes the default components and targets files and points
+// the plugin settings at them.
+func writeRustDefaults(t *testing.T, components, targets string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for key, content := range map[string]string{
+		"ASDF_RUST_DEFAULT_COMPONENTS_FILE": components,
+		"ASDF_RUST_DEFAULT_TARGETS_FILE":    targets,
+	} {
+		path := filepath.Join(dir, key)
+		require.NoError(t, os.WriteFile(path, []byte(content), asdf.CommonFilePermission))
+		t.Setenv(key, path)
+	}
+}
+
+// TestRustInstallDefaultComponents verifies default components and targets are
+// added to the new toolchain with the install's own rustup.
+func TestRustInstallDefaultComponents(t *testing.T) {
+	writeRustDefaults(t, "# linters\nrustfmt clippy\n", "wasm32-unknown-unknown\n")
+
+	plugin := newRustTestPlugin(t)
+	installPath := t.TempDir()
+
+	require.NoError(t, plugin.Install(t.Context(), "1.80.0", "", installPath))
+	require.FileExists(t, filepath.Join(installPath, "bin", "cargo"))
+
+	log, err := os.ReadFile(filepath.Join(installPath, "rustup.log"))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"component add --toolchain 1.80.0 rustfmt",
+		"component add --toolchain 1.80.0 clippy",
+		"target add --toolchain 1.80.0 wasm32-unknown-unknown",
+	}, strings.Split(strings.TrimSpace(string(log)), "\n"))
+
+	require.Equal(t, map[string]string{"CARGO_HOME": installPath, "RUSTUP_HOME": installPath}, plugin.ExecEnv(installPath))
+}
+
+// TestRustInstallDefaultComponentsFailure verifies a component or target
+// rustup cannot add fails the install and is named in the error.
+func TestRustInstallDefaultComponentsFailure(t *testing.T) {
+	plugin := newRustTestPlugin(t)
+
+	writeRustDefaults(t, "rustfmt\nmissing-miri\n", "")
+
+	err := plugin.Install(t.Context(), "1.80.0", "", t.TempDir())
+	require.ErrorContains(t, err, "installing rust component missing-miri")
+
+	writeRustDefaults(t, "", "missing-avr-none\n")
+
+	err = plugin.Install(t.Context(), "1.80.0", "", t.TempDir())
+	require.ErrorContains(t, err, "installing rust target missing-avr-none")
+}
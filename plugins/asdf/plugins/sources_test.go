@@ -0,0 +1,47 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// TestVersionSourcesGoldie lists the recorded kubectl versions from a GitHub
+// releases source; zig and gcloud list theirs from index and listing sources
+// in their own tests.
+func TestVersionSourcesGoldie(t *testing.T) {
+	t.Parallel()
+
+	source := testutil.StartVersionSource(t, testutil.NewGitHubReleasesSource("kubernetes", "kubernetes", "v"), nil)
+	versions := testutil.SetupVersionsFromGoldie(t, source, "testdata", "kubectl")
+
+	plugin := p.NewKubectlPlugin()
+	require.True(t, testutil.UseGitHubClient(plugin, source.Client()))
+
+	listed, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, versions, listed)
+
+	latest, err := plugin.LatestStable(t.Context(), "1.29")
+	require.NoError(t, err)
+	series := testutil.GoldieFilterPattern(t, "testdata", "kubectl", `^1\.29\.`)
+	require.Equal(t, series[len(series)-1], latest)
+}
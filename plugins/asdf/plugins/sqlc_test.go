@@ -0,0 +1,120 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// sqlcChecksums returns a Publish hook listing the synthesized archives in
+// the checksums file of the release, with the sums returned by sum.
+func sqlcChecksums(version string, sum func(archive []byte) string) func(string, map[string][]byte) map[string][]byte {
+	return func(_ string, archives map[string][]byte) map[string][]byte {
+		var checksums strings.Builder
+
+		for name, archive := range archives {
+			checksums.WriteString(sum(archive) + "  " + name + "\n")
+		}
+
+		return map[string][]byte{"/sqlc_" + version + "_checksums.txt": []byte(checksums.String())}
+	}
+}
+
+// TestSqlcDownloadVerifiesChecksums verifies sqlc downloads and installs a
+// release listed in its checksums asset, and refuses an archive the checksums
+// asset does not match.
+func TestSqlcDownloadVerifiesChecksums(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sum     func(archive []byte) string
+		name    string
+		wantErr string
+	}{
+		{
+			name: "listed",
+			sum: func(archive []byte) string {
+				sum := sha256.Sum256(archive)
+
+				return hex.EncodeToString(sum[:])
+			},
+		},
+		{
+			name:    "tampered",
+			sum:     func([]byte) string { return strings.Repeat("0", sha256.Size*2) },
+			wantErr: "checksum mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			testutil.InstallHarness{
+				Config:  testutil.BinaryPluginTestConfig{Name: "sqlc", Factory: p.NewSqlcPlugin},
+				Version: "1.27.0",
+				Publish: sqlcChecksums("1.27.0", tt.sum),
+				WantErr: tt.wantErr,
+			}.Run(t)
+		})
+	}
+}
+
+// TestSqlcLegacyFiles verifies sqlc reads the version of the sqlc.yaml and
+// sqlc.json samples when it names a release, and pins nothing when it is the
+// configuration schema version or missing.
+func TestSqlcLegacyFiles(t *testing.T) {
+	t.Parallel()
+
+	plugin, err := plugins.GetPlugin("sqlc")
+	require.NoError(t, err)
+	require.Equal(t, []string{"sqlc.yaml", "sqlc.json"}, plugin.ListLegacyFilenames())
+
+	version, err := plugin.ParseLegacyFile(filepath.Join("testdata", "sqlc", "pinned-yaml", "sqlc.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "1.27.0", version)
+
+	version, err = plugin.ParseLegacyFile(filepath.Join("testdata", "sqlc", "pinned-json", "sqlc.json"))
+	require.NoError(t, err)
+	require.Equal(t, "1.26.0", version)
+
+	for _, path := range []string{
+		filepath.Join("testdata", "sqlc", "schema-yaml", "sqlc.yaml"),
+		filepath.Join("testdata", "sqlc", "schema-json", "sqlc.json"),
+		filepath.Join("testdata", "sqlc", "none", "sqlc.yaml"),
+	} {
+		_, err = plugin.ParseLegacyFile(path)
+		require.ErrorIs(t, err, asdf.ErrNoLegacyPin, path)
+	}
+
+	path := filepath.Join(t.TempDir(), "sqlc.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": ["1.27.0"]}`), asdf.CommonFilePermission))
+
+	_, err = plugin.ParseLegacyFile(path)
+	require.ErrorContains(t, err, "parsing "+path+": version is neither a string nor a number")
+}
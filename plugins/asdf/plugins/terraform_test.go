@@ -0,0 +1,75 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// TestOpentofuLegacyFiles verifies opentofu prefers its own version file and reads terraform's verbatim.
+func TestOpentofuLegacyFiles(t *testing.T) {
+	t.Parallel()
+
+	plugin, err := plugins.GetPlugin("opentofu")
+	require.NoError(t, err)
+	require.Equal(t, []string{".opentofu-version", ".terraform-version"}, plugin.ListLegacyFilenames())
+
+	path := filepath.Join(t.TempDir(), ".terraform-version")
+	require.NoError(t, os.WriteFile(path, []byte("1.6.2\n"), asdf.CommonFilePermission))
+
+	version, err := plugin.ParseLegacyFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "1.6.2", version)
+}
+
+// TestTerraformPluginCacheEnv verifies terraform and opentofu share a provider cache below the data directory.
+func TestTerraformPluginCacheEnv(t *testing.T) {
+	for _, name := range []string{"terraform", "opentofu"} {
+		t.Run(name, func(t *testing.T) {
+			plugin, err := plugins.GetPlugin(name)
+			require.NoError(t, err)
+
+			dataDir := t.TempDir()
+			t.Setenv(asdf.DataDirEnv, dataDir)
+			t.Setenv(asdf.CacheDirEnv, "")
+			t.Setenv("TF_PLUGIN_CACHE_DIR", "")
+			t.Setenv("ASDF_TF_PLUGIN_CACHE_DIR", "")
+
+			cacheDir := filepath.Join(dataDir, "cache", "terraform-plugins")
+			require.Equal(t, map[string]string{"TF_PLUGIN_CACHE_DIR": cacheDir}, plugin.ExecEnv(t.TempDir()))
+			require.DirExists(t, cacheDir)
+
+			override := filepath.Join(t.TempDir(), "providers")
+			t.Setenv("ASDF_TF_PLUGIN_CACHE_DIR", override)
+			require.Equal(t, map[string]string{"TF_PLUGIN_CACHE_DIR": override}, plugin.ExecEnv(t.TempDir()))
+			require.DirExists(t, override)
+
+			t.Setenv("ASDF_TF_PLUGIN_CACHE_DIR", "-")
+			require.Empty(t, plugin.ExecEnv(t.TempDir()))
+
+			t.Setenv("ASDF_TF_PLUGIN_CACHE_DIR", "")
+			t.Setenv("TF_PLUGIN_CACHE_DIR", "/srv/providers")
+			require.Empty(t, plugin.ExecEnv(t.TempDir()))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// TestTerragruntLegacyFiles verifies terragrunt reads .terragrunt-version
+// verbatim and resolves the terragrunt_version_constraint of the
+// terragrunt.hcl samples to the newest version satisfying it, pinning nothing
+// without one.
+func TestTerragruntLegacyFiles(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+	t.Setenv(asdf.OfflineEnv, "1")
+	require.NoError(t, asdf.RecordVersionCatalog("terragrunt", []string{"0.66.9", "0.67.4", "0.67.16", "0.68.0"}))
+
+	plugin, err := plugins.GetPlugin("terragrunt")
+	require.NoError(t, err)
+	require.Equal(t, []string{".terragrunt-version", "terragrunt.hcl"}, plugin.ListLegacyFilenames())
+
+	path := filepath.Join(t.TempDir(), ".terragrunt-version")
+	require.NoError(t, os.WriteFile(path, []byte("0.67.4\n"), asdf.CommonFilePermission))
+
+	version, err := plugin.ParseLegacyFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "0.67.4", version)
+
+	version, err = plugin.ParseLegacyFile(filepath.Join("testdata", "hcl", "terragrunt-root", "terragrunt.hcl"))
+	require.NoError(t, err)
+	require.Equal(t, "0.67.16", version)
+
+	_, err = plugin.ParseLegacyFile(filepath.Join("testdata", "hcl", "terragrunt-module", "terragrunt.hcl"))
+	require.ErrorIs(t, err, asdf.ErrNoLegacyPin)
+}
@@ -0,0 +1,12 @@
+include "root" {
+  path = find_in_parent_folders()
+}
+
+terraform {
+  source = "tfr:///terraform-aws-modules/vpc/aws?version=5.8.1"
+}
+
+inputs = {
+  name = "main"
+  cidr = "10.0.0.0/16"
+}
@@ -0,0 +1,31 @@
+terraform_version_constraint  = ">= 1.5.0"
+terragrunt_version_constraint = ">= 0.67.0, < 0.68.0"
+
+locals {
+  account_vars = read_terragrunt_config(find_in_parent_folders("account.hcl"))
+  region       = "eu-west-1"
+}
+
+remote_state {
+  backend = "s3"
+  generate = {
+    path      = "backend.tf"
+    if_exists = "overwrite_terragrunt"
+  }
+  config = {
+    bucket  = "terraform-state-${local.account_vars.locals.account_id}"
+    key     = "${path_relative_to_include()}/terraform.tfstate"
+    region  = local.region
+    encrypt = true
+  }
+}
+
+generate "provider" {
+  path      = "provider.tf"
+  if_exists = "overwrite_terragrunt"
+  contents  = <<EOF
+provider "aws" {
+  region = "${local.region}"
+}
+EOF
+}
@@ -0,0 +1,16 @@
+tflint {
+  required_version = ">= 0.50"
+}
+
+config {
+  format = "compact"
+  plugin_dir = "~/.tflint.d/plugins"
+
+  call_module_type = "local"
+  force = false
+}
+
+plugin "terraform" {
+  enabled = true
+  preset  = "recommended"
+}
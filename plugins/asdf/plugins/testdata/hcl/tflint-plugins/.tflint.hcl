@@ -0,0 +1,22 @@
+// Pin TFLint so CI and local runs report the same findings.
+tflint {
+  # required_version = ">= 0.40"
+  required_version = "~> 0.53.0"
+}
+
+plugin "aws" {
+  enabled = true
+  version = "0.34.0"
+  source  = "github.com/terraform-linters/tflint-ruleset-aws"
+
+  deep_check = false
+}
+
+rule "terraform_naming_convention" {
+  enabled = true
+  format  = "snake_case"
+}
+
+rule "aws_instance_invalid_type" {
+  enabled = false
+}
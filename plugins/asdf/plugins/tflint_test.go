@@ -0,0 +1,57 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// TestTflintLegacyFiles verifies tflint resolves the required_version of the
+// .tflint.hcl samples to the newest version satisfying it, pins nothing
+// without one, and rejects constraints no version satisfies.
+func TestTflintLegacyFiles(t *testing.T) {
+	t.Setenv(asdf.DataDirEnv, t.TempDir())
+	t.Setenv(asdf.OfflineEnv, "1")
+
+	plugin, err := plugins.GetPlugin("tflint")
+	require.NoError(t, err)
+	require.Equal(t, []string{".tflint.hcl"}, plugin.ListLegacyFilenames())
+	require.NoError(t, asdf.RecordVersionCatalog("tflint", []string{"0.49.0", "0.53.0", "0.53.2", "0.54.0"}))
+
+	version, err := plugin.ParseLegacyFile(filepath.Join("testdata", "hcl", "tflint-minimum", ".tflint.hcl"))
+	require.NoError(t, err)
+	require.Equal(t, "0.54.0", version)
+
+	version, err = plugin.ParseLegacyFile(filepath.Join("testdata", "hcl", "tflint-plugins", ".tflint.hcl"))
+	require.NoError(t, err)
+	require.Equal(t, "0.53.2", version)
+
+	_, err = plugin.ParseLegacyFile(filepath.Join("testdata", "hcl", "tflint-none", ".tflint.hcl"))
+	require.ErrorIs(t, err, asdf.ErrNoLegacyPin)
+
+	path := filepath.Join(t.TempDir(), ".tflint.hcl")
+	require.NoError(t, os.WriteFile(path, []byte("tflint {\n  required_version = \"< 0.49\"\n}\n"), asdf.CommonFilePermission))
+
+	_, err = plugin.ParseLegacyFile(path)
+	require.ErrorContains(t, err, `parsing required_version of `+path+`: no versions satisfying constraint "< 0.49" for tflint`)
+}
@@ -0,0 +1,40 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// TestUvToolDirs verifies tools installed with `uv tool install` stay in the install and get shims.
+func TestUvToolDirs(t *testing.T) {
+	t.Parallel()
+
+	plugin, err := plugins.GetPlugin("uv")
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{
+		"UV_TOOL_DIR":     "/opt/uv/0.5.0/tools",
+		"UV_TOOL_BIN_DIR": "/opt/uv/0.5.0/tools/bin",
+	}, plugin.ExecEnv("/opt/uv/0.5.0"))
+	require.Equal(t, []string{"/opt/uv/0.5.0/bin", "/opt/uv/0.5.0/tools/bin"}, asdf.BinDirsOf(plugin, "/opt/uv/0.5.0"))
+	require.Equal(t, []string{"tools"}, asdf.HashIgnoreGlobsOf(plugin))
+}
@@ -0,0 +1,199 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// zigMasterDevVersion is the dev version of the master entry of startZigIndex.
+const zigMasterDevVersion = "0.15.0-dev.123+abc"
+
+// startZigIndex publishes 0.13.0, 0.14.0 and master from a keyed index
+// source, every build pointing at a synthesized x86_64 tarball, and returns a
+// zig plugin using it.
+func startZigIndex(t *testing.T) *p.ZigPlugin {
+	t.Helper()
+
+	t.Setenv(asdf.ForceArchEnv, "amd64")
+	t.Setenv("ASDF_ZIG_SKIP_VERIFY", "")
+
+	tarball := testutil.SynthesizeArchive(t, "tar.xz", map[string]string{
+		"zig-x86_64-linux-" + zigMasterDevVersion + "/zig": "#!/bin/sh\necho " + zigMasterDevVersion + "\n",
+	})
+	sum := sha256.Sum256(tarball)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write(tarball)
+	}))
+	t.Cleanup(server.Close)
+
+	source := testutil.StartVersionSource(t, testutil.NewKeyedIndexSource(func(version string) any {
+		build := map[string]string{
+			"tarball": server.URL + "/zig-" + version + ".tar.xz",
+			"shasum":  hex.EncodeToString(sum[:]),
+			"size":    strconv.Itoa(len(tarball)),
+		}
+		entry := map[string]any{"date": "2025-03-05", "x86_64-linux": build, "x86_64-darwin": build}
+
+		if version == "master" {
+			entry["version"] = zigMasterDevVersion
+		}
+
+		return entry
+	}), []string{"0.13.0", "0.14.0", "master"})
+
+	plugin, ok := p.NewZigPlugin().(*p.ZigPlugin)
+	require.True(t, ok)
+
+	plugin.ZigIndexURL = source.URL()
+
+	return plugin
+}
+
+// TestZigListAllGoldie lists the recorded zig versions from a keyed index
+// source and resolves the tarball of the latest one.
+func TestZigListAllGoldie(t *testing.T) {
+	t.Parallel()
+
+	plugin, ok := p.NewZigPlugin().(*p.ZigPlugin)
+	require.True(t, ok)
+
+	description, err := plugin.DescribeArtifacts("")
+	require.NoError(t, err)
+
+	platformKey := description.Arch + "-" + description.Platform
+	tarball := func(version string) string {
+		return "https://ziglang.org/download/" + version + "/zig-" + platformKey + "-" + version + ".tar.xz"
+	}
+
+	source := testutil.StartVersionSource(t, testutil.NewKeyedIndexSource(func(version string) any {
+		return map[string]any{
+			"date":      "2025-01-01",
+			platformKey: map[string]string{"tarball": tarball(version)},
+		}
+	}), nil)
+	versions := testutil.SetupVersionsFromGoldie(t, source, "testdata", "zig")
+
+	plugin.ZigIndexURL = source.URL()
+
+	listed, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, versions, listed)
+
+	latest, err := plugin.LatestStable(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, testutil.GoldieLatest(t, "testdata", "zig"), latest)
+
+	artifacts, err := plugin.ResolveArtifacts(t.Context(), latest)
+	require.NoError(t, err)
+	require.Equal(t, []asdf.Artifact{{Name: "zig.tar.xz", URL: tarball(latest)}}, artifacts)
+}
+
+// TestZigMasterListed verifies master is listed after the stable versions, and
+// only returned by LatestStable when queried exactly.
+func TestZigMasterListed(t *testing.T) {
+	plugin := startZigIndex(t)
+
+	versions, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []string{"0.13.0", "0.14.0", "master"}, versions)
+
+	latest, err := plugin.LatestStable(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, "0.14.0", latest)
+
+	latest, err = plugin.LatestStable(t.Context(), "0.13")
+	require.NoError(t, err)
+	require.Equal(t, "0.13.0", latest)
+
+	latest, err = plugin.LatestStable(t.Context(), "master")
+	require.NoError(t, err)
+	require.Equal(t, "master", latest)
+
+	_, err = plugin.LatestStable(t.Context(), "mast")
+	require.ErrorContains(t, err, "no versions matching query")
+}
+
+// TestZigMasterDownloadInstall verifies master is downloaded from the nested
+// master entry and that its dev version is recorded in the download and the
+// install.
+func TestZigMasterDownloadInstall(t *testing.T) {
+	t.Setenv("ASDF_DATA_DIR", t.TempDir())
+
+	plugin := startZigIndex(t)
+	if _, err := plugin.ResolveArtifacts(t.Context(), "master"); err != nil {
+		t.Skipf("the canned index has no build for this platform: %v", err)
+	}
+
+	downloadPath, installPath := t.TempDir(), t.TempDir()
+	require.NoError(t, plugin.Download(t.Context(), "master", downloadPath))
+	require.FileExists(t, filepath.Join(downloadPath, "zig.tar.xz"))
+
+	resolved, err := os.ReadFile(filepath.Join(downloadPath, ".zig-resolved-version"))
+	require.NoError(t, err)
+	require.Equal(t, zigMasterDevVersion+"\n", string(resolved))
+
+	require.NoError(t, plugin.Install(t.Context(), "master", downloadPath, installPath))
+	require.FileExists(t, filepath.Join(installPath, "zig"))
+
+	resolved, err = os.ReadFile(filepath.Join(installPath, ".zig-resolved-version"))
+	require.NoError(t, err)
+	require.Equal(t, zigMasterDevVersion+"\n", string(resolved))
+}
+
+// TestZigInstallOffline verifies offline installs fail instead of reaching
+// the index when the recorded checksum of the tarball is missing, and that
+// the offline check asks for it.
+func TestZigInstallOffline(t *testing.T) {
+	t.Setenv("ASDF_DATA_DIR", t.TempDir())
+
+	plugin := startZigIndex(t)
+	if _, err := plugin.ResolveArtifacts(t.Context(), "0.14.0"); err != nil {
+		t.Skipf("the canned index has no build for this platform: %v", err)
+	}
+
+	downloadPath := t.TempDir()
+	require.NoError(t, plugin.Download(t.Context(), "0.14.0", downloadPath))
+
+	t.Setenv(asdf.OfflineEnv, "1")
+	plugin.ZigIndexURL = "http://127.0.0.1:0/index.json"
+
+	require.NoError(t, asdf.CheckOfflineDownload(plugin, "0.14.0", downloadPath))
+	require.NoError(t, plugin.Install(t.Context(), "0.14.0", downloadPath, t.TempDir()))
+
+	sidecar := filepath.Join(downloadPath, "zig.tar.xz"+asdf.ChecksumSidecarSuffix)
+	require.NoError(t, os.Remove(sidecar))
+
+	err := asdf.CheckOfflineDownload(plugin, "0.14.0", downloadPath)
+	require.ErrorContains(t, err, "offline mode: download not pre-seeded: zig 0.14.0 requires "+sidecar)
+
+	err = plugin.Install(t.Context(), "0.14.0", downloadPath, t.TempDir())
+	require.ErrorContains(t, err, "offline mode: download not pre-seeded")
+}
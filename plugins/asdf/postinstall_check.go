@@ -0,0 +1,82 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SkipPostInstallCheckEnv skips BinaryPluginConfig.PostInstallCheck when set
+// to 1, e.g. for image builds installing binaries of another architecture.
+const SkipPostInstallCheckEnv = "ASDF_SKIP_POST_INSTALL_CHECK"
+
+// postInstallCheckTimeout bounds the run of a PostInstallCheck command.
+const postInstallCheckTimeout = 10 * time.Second
+
+var (
+	// errPostInstallCheckFailed is returned when the installed binary fails its PostInstallCheck.
+	errPostInstallCheckFailed = errors.New("post-install check failed")
+
+	// runPostInstallCheck runs a PostInstallCheck command and returns its combined output.
+	runPostInstallCheck = func(ctx context.Context, name string, args ...string) ([]byte, error) { //nolint:gochecknoglobals // used for mocking
+		return execCommandContext(ctx, name, args...).CombinedOutput()
+	}
+)
+
+// postInstallCheck runs the PostInstallCheck command of the plugin against the
+// binary installed in binDir, unless ASDF_SKIP_POST_INSTALL_CHECK is 1.
+func (plugin *BinaryPlugin) postInstallCheck(ctx context.Context, version, binDir string) error {
+	if plugin.Config.PostInstallCheck == "" {
+		return nil
+	}
+
+	if os.Getenv(SkipPostInstallCheckEnv) == "1" {
+		Logger().Debug("skipping post-install check", "tool", plugin.Config.Name)
+
+		return nil
+	}
+
+	// The command is split before substituting the binary path, which may
+	// contain spaces, e.g. below C:\Users\First Last.
+	binaryPath := filepath.Join(binDir, plugin.Config.BinaryName)
+
+	args := strings.Fields(plugin.Config.PostInstallCheck)
+	for i, arg := range args {
+		args[i] = strings.ReplaceAll(plugin.renderTemplate(arg, version, "", ""), "{{.BinaryPath}}", binaryPath)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postInstallCheckTimeout)
+	defer cancel()
+
+	output, err := runPostInstallCheck(ctx, args[0], args[1:]...)
+	if err != nil {
+		return fmt.Errorf("%w: %s %s: %w: %s (set %s=1 to skip)", errPostInstallCheckFailed,
+			plugin.Config.Name, version, err, strings.TrimSpace(string(output)), SkipPostInstallCheckEnv)
+	}
+
+	if strings.TrimSpace(string(output)) == "" {
+		return fmt.Errorf("%w: %s %s: %q printed nothing (set %s=1 to skip)", errPostInstallCheckFailed,
+			plugin.Config.Name, version, strings.Join(args, " "), SkipPostInstallCheckEnv)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+func TestBinaryPluginPostInstallCheck(t *testing.T) {
+	errCrashed := errors.New("signal: segmentation fault")
+
+	tests := []struct {
+		runErr  error
+		name    string
+		skip    string
+		output  string
+		wantErr bool
+	}{
+		{name: "passes when the binary prints its version", output: "test-tool 1.0.0\n"},
+		{name: "fails when the binary exits non-zero", runErr: errCrashed, wantErr: true},
+		{name: "fails when the binary prints nothing", output: " \n", wantErr: true},
+		{name: "skipped with ASDF_SKIP_POST_INSTALL_CHECK", runErr: errCrashed, skip: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(asdf.SkipPostInstallCheckEnv, tt.skip)
+
+			var ran []string
+
+			asdf.SetPostInstallCheckRunnerForTests(t, func(ctx context.Context, name string, args ...string) ([]byte, error) {
+				_, hasDeadline := ctx.Deadline()
+				require.True(t, hasDeadline)
+
+				ran = append([]string{name}, args...)
+
+				return []byte(tt.output), tt.runErr
+			})
+
+			tempDir := t.TempDir()
+			downloadPath := filepath.Join(tempDir, "download")
+			installPath := filepath.Join(tempDir, "install")
+
+			require.NoError(t, os.MkdirAll(downloadPath, asdf.CommonDirectoryPermission))
+			require.NoError(t, os.WriteFile(filepath.Join(downloadPath, "test-tool"), []byte("binary"),
+				asdf.CommonExecutablePermission))
+
+			plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+				Name:             "test-tool",
+				RepoOwner:        "owner",
+				RepoName:         "repo",
+				BinaryName:       "test-tool",
+				PostInstallCheck: "{{.BinaryPath}} --version",
+			})
+
+			err := plugin.Install(t.Context(), "1.0.0", downloadPath, installPath)
+
+			if tt.skip == "1" {
+				require.NoError(t, err)
+				require.Nil(t, ran)
+
+				return
+			}
+
+			require.Equal(t, []string{filepath.Join(installPath, "bin", "test-tool"), "--version"}, ran)
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				require.FileExists(t, filepath.Join(installPath, "bin", "test-tool"))
+
+				return
+			}
+
+			require.ErrorIs(t, err, asdf.ErrPostInstallCheckFailedForTests())
+			require.NoDirExists(t, installPath)
+		})
+	}
+}
+
+// TestBinaryPluginPostInstallCheckPathWithSpaces verifies a binary path with
+// spaces is passed as a single argument.
+func TestBinaryPluginPostInstallCheckPathWithSpaces(t *testing.T) {
+	var ran []string
+
+	asdf.SetPostInstallCheckRunnerForTests(t, func(_ context.Context, name string, args ...string) ([]byte, error) {
+		ran = append([]string{name}, args...)
+
+		return []byte("test-tool 1.0.0\n"), nil
+	})
+
+	tempDir := filepath.Join(t.TempDir(), "First Last", ".asdf")
+	downloadPath := filepath.Join(tempDir, "download")
+	installPath := filepath.Join(tempDir, "install")
+
+	require.NoError(t, os.MkdirAll(downloadPath, asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadPath, "test-tool"), []byte("binary"),
+		asdf.CommonExecutablePermission))
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:             "test-tool",
+		RepoOwner:        "owner",
+		RepoName:         "repo",
+		BinaryName:       "test-tool",
+		PostInstallCheck: "{{.BinaryPath}} version --short {{.Version}}",
+	})
+
+	require.NoError(t, plugin.Install(t.Context(), "1.0.0", downloadPath, installPath))
+	require.Equal(t, []string{filepath.Join(installPath, "bin", "test-tool"), "version", "--short", "1.0.0"}, ran)
+}
@@ -0,0 +1,246 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PackageManager identifies the system package manager of a distro family.
+type PackageManager string
+
+// Supported package managers.
+const (
+	PackageManagerApt  PackageManager = "apt"
+	PackageManagerDnf  PackageManager = "dnf"
+	PackageManagerApk  PackageManager = "apk"
+	PackageManagerBrew PackageManager = "brew"
+)
+
+var (
+	// errBuildDependenciesMissing is returned when a pre-flight check finds missing build dependencies.
+	errBuildDependenciesMissing = errors.New("missing build dependencies")
+
+	// osReleasePath is the file identifying the Linux distribution.
+	osReleasePath = "/etc/os-release" //nolint:gochecknoglobals // used for mocking
+
+	// packageManagerInstallCommands are the commands installing packages with each package manager.
+	packageManagerInstallCommands = map[PackageManager]string{ //nolint:gochecknoglobals // lookup table
+		PackageManagerApt:  "sudo apt-get install -y",
+		PackageManagerDnf:  "sudo dnf install -y",
+		PackageManagerApk:  "sudo apk add",
+		PackageManagerBrew: "brew install",
+	}
+)
+
+// BuildDependency is a development library a source build needs.
+type BuildDependency struct {
+	// Packages name the package providing the library for each package manager.
+	Packages map[PackageManager]string
+	// Name is the library name shown to users, e.g. OpenSSL.
+	Name string
+	// PkgConfig is the pkg-config module of the library, if it ships one.
+	PkgConfig string
+	// Header is a header the library installs, e.g. openssl/ssl.h.
+	Header string
+}
+
+// BuildPreflight checks build dependencies before a long source build so that
+// missing ones are reported up front, together with the packages to install.
+type BuildPreflight struct {
+	// Probe reports whether a dependency is available, ProbeBuildDependency when nil.
+	Probe func(ctx context.Context, dependency BuildDependency) bool
+	// SkipEnv disables the check when set to 1.
+	SkipEnv string
+	// PackageManager names the packages to install, detected when empty.
+	PackageManager PackageManager
+	// Dependencies are the libraries the build needs.
+	Dependencies []BuildDependency
+}
+
+// Check probes every dependency and returns one error naming all missing ones
+// and the command installing them with the detected package manager.
+func (preflight BuildPreflight) Check(ctx context.Context) error {
+	if preflight.SkipEnv != "" && os.Getenv(preflight.SkipEnv) == "1" {
+		return nil
+	}
+
+	probe := preflight.Probe
+	if probe == nil {
+		probe = ProbeBuildDependency
+	}
+
+	var missing []BuildDependency
+
+	for _, dependency := range preflight.Dependencies {
+		if !probe(ctx, dependency) {
+			missing = append(missing, dependency)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	manager := preflight.PackageManager
+	if manager == "" {
+		manager = DetectPackageManager()
+	}
+
+	names := make([]string, 0, len(missing))
+	packages := make([]string, 0, len(missing))
+
+	for _, dependency := range missing {
+		names = append(names, dependency.Name)
+
+		if pkg, ok := dependency.Packages[manager]; ok {
+			packages = append(packages, pkg)
+		}
+	}
+
+	hint := "install their development headers"
+	if command, ok := packageManagerInstallCommands[manager]; ok && len(packages) == len(missing) {
+		hint = "install them with: " + command + " " + strings.Join(packages, " ")
+	}
+
+	if preflight.SkipEnv != "" {
+		hint += ", or set " + preflight.SkipEnv + "=1 to skip this check"
+	}
+
+	return fmt.Errorf("%w: %s; %s", errBuildDependenciesMissing, strings.Join(names, ", "), hint)
+}
+
+// ProbeBuildDependency reports whether dependency is available: its pkg-config
+// module exists, its Homebrew package is installed, or its header preprocesses
+// with $CC (cc by default) and $CPPFLAGS. Without a compiler the header cannot
+// be probed and the dependency is assumed to be available.
+func ProbeBuildDependency(ctx context.Context, dependency BuildDependency) bool {
+	if dependency.PkgConfig != "" {
+		if pkgConfig, err := execLookPath("pkg-config"); err == nil {
+			if execCommandContext(ctx, pkgConfig, "--exists", dependency.PkgConfig).Run() == nil {
+				return true
+			}
+		}
+	}
+
+	if pkg, ok := dependency.Packages[PackageManagerBrew]; ok && runtime.GOOS == "darwin" {
+		if _, err := os.Stat(filepath.Join(homebrewPrefix(), "opt", pkg)); err == nil {
+			return true
+		}
+	}
+
+	if dependency.Header == "" {
+		return false
+	}
+
+	compiler := os.Getenv("CC")
+	if compiler == "" {
+		compiler = "cc"
+	}
+
+	compilerPath, err := execLookPath(compiler)
+	if err != nil {
+		return true
+	}
+
+	args := append(strings.Fields(os.Getenv("CPPFLAGS")), "-E", "-x", "c", "-o", os.DevNull, "-")
+
+	cmd := execCommandContext(ctx, compilerPath, args...)
+	cmd.Stdin = strings.NewReader("#include <" + dependency.Header + ">\n")
+
+	return cmd.Run() == nil
+}
+
+// DetectPackageManager returns the package manager of the running system from
+// the ID and ID_LIKE fields of /etc/os-release, falling back to the package
+// managers found on PATH. It returns an empty string when none is recognized.
+func DetectPackageManager() PackageManager {
+	if runtime.GOOS == "darwin" {
+		return PackageManagerBrew
+	}
+
+	if manager := osReleasePackageManager(); manager != "" {
+		return manager
+	}
+
+	for _, candidate := range []struct {
+		manager PackageManager
+		binary  string
+	}{
+		{PackageManagerApt, "apt-get"},
+		{PackageManagerDnf, "dnf"},
+		{PackageManagerApk, "apk"},
+		{PackageManagerBrew, "brew"},
+	} {
+		if _, err := execLookPath(candidate.binary); err == nil {
+			return candidate.manager
+		}
+	}
+
+	return ""
+}
+
+// osReleasePackageManager maps the distribution ids in /etc/os-release to a package manager.
+func osReleasePackageManager() PackageManager {
+	file, err := os.Open(osReleasePath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	var ids []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if ok && (key == "ID" || key == "ID_LIKE") {
+			ids = append(ids, strings.Fields(strings.Trim(value, `"'`))...)
+		}
+	}
+
+	for _, id := range ids {
+		switch id {
+		case "debian", "ubuntu":
+			return PackageManagerApt
+		case "fedora", "rhel", "centos":
+			return PackageManagerDnf
+		case "alpine":
+			return PackageManagerApk
+		}
+	}
+
+	return ""
+}
+
+// homebrewPrefix returns $HOMEBREW_PREFIX or the default prefix for the architecture.
+func homebrewPrefix() string {
+	if prefix := os.Getenv("HOMEBREW_PREFIX"); prefix != "" {
+		return prefix
+	}
+
+	if runtime.GOARCH == "arm64" {
+		return "/opt/homebrew"
+	}
+
+	return "/usr/local"
+}
@@ -0,0 +1,141 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// preflightDependencies are OpenSSL and zlib as the python plugin declares them.
+var preflightDependencies = []asdf.BuildDependency{
+	{
+		Name: "OpenSSL", PkgConfig: "openssl", Header: "openssl/ssl.h",
+		Packages: map[asdf.PackageManager]string{asdf.PackageManagerApt: "libssl-dev", asdf.PackageManagerApk: "openssl-dev"},
+	},
+	{
+		Name: "zlib", PkgConfig: "zlib", Header: "zlib.h",
+		Packages: map[asdf.PackageManager]string{asdf.PackageManagerApt: "zlib1g-dev"},
+	},
+}
+
+// TestBuildPreflightCheck verifies missing dependencies are aggregated into one actionable error.
+func TestBuildPreflightCheck(t *testing.T) {
+	probed := map[string]bool{}
+	missing := func(names ...string) func(context.Context, asdf.BuildDependency) bool {
+		return func(_ context.Context, dependency asdf.BuildDependency) bool {
+			probed[dependency.Name] = true
+
+			for _, name := range names {
+				if dependency.Name == name {
+					return false
+				}
+			}
+
+			return true
+		}
+	}
+
+	preflight := asdf.BuildPreflight{
+		Probe:          missing(),
+		SkipEnv:        "ASDF_TEST_SKIP_PREFLIGHT",
+		PackageManager: asdf.PackageManagerApt,
+		Dependencies:   preflightDependencies,
+	}
+	require.NoError(t, preflight.Check(t.Context()))
+	require.Equal(t, map[string]bool{"OpenSSL": true, "zlib": true}, probed)
+
+	preflight.Probe = missing("OpenSSL", "zlib")
+	err := preflight.Check(t.Context())
+	require.ErrorIs(t, err, asdf.ErrBuildDependenciesMissingForTests())
+	require.EqualError(t, err, "missing build dependencies: OpenSSL, zlib; install them with: "+
+		"sudo apt-get install -y libssl-dev zlib1g-dev, or set ASDF_TEST_SKIP_PREFLIGHT=1 to skip this check")
+
+	preflight.PackageManager = asdf.PackageManagerApk
+	require.EqualError(t, preflight.Check(t.Context()), "missing build dependencies: OpenSSL, zlib; "+
+		"install their development headers, or set ASDF_TEST_SKIP_PREFLIGHT=1 to skip this check")
+
+	t.Setenv("ASDF_TEST_SKIP_PREFLIGHT", "1")
+	require.NoError(t, preflight.Check(t.Context()))
+}
+
+// TestDetectPackageManager verifies the distro family comes from os-release, then from PATH.
+func TestDetectPackageManager(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("macOS always uses brew")
+	}
+
+	tests := []struct {
+		name      string
+		osRelease string
+		onPath    string
+		expected  asdf.PackageManager
+	}{
+		{name: "ubuntu", osRelease: "NAME=\"Ubuntu\"\nID=ubuntu\nID_LIKE=debian\n", expected: asdf.PackageManagerApt},
+		{name: "rocky", osRelease: "ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n", expected: asdf.PackageManagerDnf},
+		{name: "alpine", osRelease: "ID=alpine\n", expected: asdf.PackageManagerApk},
+		{name: "unknown distro", osRelease: "ID=nixos\n", onPath: "dnf", expected: asdf.PackageManagerDnf},
+		{name: "no os-release", onPath: "brew", expected: asdf.PackageManagerBrew},
+		{name: "nothing found", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "os-release")
+			if tt.osRelease != "" {
+				require.NoError(t, os.WriteFile(path, []byte(tt.osRelease), asdf.CommonFilePermission))
+			}
+
+			asdf.SetOSReleasePathForTests(t, path)
+			asdf.MockExecForTests(t, func(file string) (string, error) {
+				if file == tt.onPath {
+					return "/usr/bin/" + file, nil
+				}
+
+				return "", os.ErrNotExist
+			})
+
+			require.Equal(t, tt.expected, asdf.DetectPackageManager())
+		})
+	}
+}
+
+// TestProbeBuildDependency verifies the probe asks pkg-config, then preprocesses the header.
+func TestProbeBuildDependency(t *testing.T) {
+	asdf.MockExecForTests(t, nil)
+
+	logFile := filepath.Join(t.TempDir(), "commands.log")
+	t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+	t.Setenv("CC", "")
+	t.Setenv("CPPFLAGS", "-I/opt/ssl/include")
+
+	require.True(t, asdf.ProbeBuildDependency(t.Context(), preflightDependencies[0]))
+
+	t.Setenv("ASDF_MOCK_COMMAND_STDERR", "not found")
+	require.False(t, asdf.ProbeBuildDependency(t.Context(), preflightDependencies[0]))
+
+	log, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	require.Equal(t, "pkg-config --exists openssl\npkg-config --exists openssl\n"+
+		"cc -I/opt/ssl/include -E -x c -o "+os.DevNull+" -\n", string(log))
+}
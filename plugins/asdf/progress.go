@@ -0,0 +1,239 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// NoProgressEnv disables progress output when set to "1".
+const NoProgressEnv = "ASDF_NO_PROGRESS"
+
+// progressRefreshInterval is the minimum delay between two progress line redraws.
+const progressRefreshInterval = 100 * time.Millisecond
+
+type (
+	// ProgressReporter receives progress updates for long running operations
+	// such as downloads and archive extraction.
+	ProgressReporter interface {
+		// Start is called once the total size is known; total is -1 when unknown.
+		Start(total int64)
+		// Advance reports that n more bytes have been processed.
+		Advance(n int64)
+		// Done is called when the operation finished, successfully or not.
+		Done()
+	}
+
+	// progressContextKey is the context key under which a ProgressReporter is stored.
+	progressContextKey struct{}
+
+	// contextReader is the reader returned by ContextReader.
+	contextReader struct {
+		ctx context.Context //nolint:containedctx // bound to a single copy
+		src io.Reader
+	}
+
+	// noopProgress is a ProgressReporter that discards all updates.
+	noopProgress struct{}
+
+	// terminalProgress renders a single-line progress bar to a terminal.
+	terminalProgress struct {
+		lastDraw time.Time
+		out      io.Writer
+		label    string
+		total    int64
+		current  int64
+		mu       sync.Mutex
+	}
+
+	// progressWriter forwards written byte counts to a ProgressReporter.
+	progressWriter struct {
+		reporter ProgressReporter
+	}
+
+	// progressReader forwards read byte counts to a ProgressReporter.
+	progressReader struct {
+		reader   io.Reader
+		reporter ProgressReporter
+	}
+)
+
+// newProgressReporter is the constructor used when no reporter is attached to the context.
+var newProgressReporter = NewProgressReporter //nolint:gochecknoglobals // used for mocking
+
+// NewProgressReporter returns the default reporter for the given label. It draws a
+// progress bar on stderr when stderr is a terminal and ASDF_NO_PROGRESS is not set,
+// and is silent otherwise.
+func NewProgressReporter(label string) ProgressReporter {
+	if testing.Testing() || os.Getenv(NoProgressEnv) == "1" || !isTerminal(os.Stderr) {
+		return noopProgress{}
+	}
+
+	return &terminalProgress{out: os.Stderr, label: label}
+}
+
+// WithProgressReporter returns a context carrying the given reporter.
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressContextKey{}, reporter)
+}
+
+// ProgressReporterFromContext returns the reporter attached to ctx, or the default
+// reporter for label when none is attached.
+func ProgressReporterFromContext(ctx context.Context, label string) ProgressReporter {
+	if reporter, ok := ctx.Value(progressContextKey{}).(ProgressReporter); ok && reporter != nil {
+		return reporter
+	}
+
+	return newProgressReporter(label)
+}
+
+// CopyWithProgress copies src to dst reporting progress to the reporter from ctx.
+// total is the expected number of bytes, or -1 when unknown.
+func CopyWithProgress(
+	ctx context.Context,
+	label string,
+	dst io.Writer,
+	src io.Reader,
+	total int64,
+) (int64, error) {
+	reporter := ProgressReporterFromContext(ctx, label)
+
+	reporter.Start(total)
+	defer reporter.Done()
+
+	return io.Copy(io.MultiWriter(dst, &progressWriter{reporter: reporter}), ContextReader(ctx, src))
+}
+
+// ContextReader returns a reader over src that fails with the context error
+// once ctx is done, so that a copy stops between two reads on cancellation.
+func ContextReader(ctx context.Context, src io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, src: src}
+}
+
+// Read implements io.Reader.
+func (reader *contextReader) Read(buf []byte) (int, error) {
+	if err := reader.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return reader.src.Read(buf)
+}
+
+// Start implements ProgressReporter.
+func (noopProgress) Start(int64) {}
+
+// Advance implements ProgressReporter.
+func (noopProgress) Advance(int64) {}
+
+// Done implements ProgressReporter.
+func (noopProgress) Done() {}
+
+// Start implements ProgressReporter.
+func (progress *terminalProgress) Start(total int64) {
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+
+	progress.total = total
+	progress.current = 0
+	progress.lastDraw = time.Time{}
+	progress.draw()
+}
+
+// Advance implements ProgressReporter.
+func (progress *terminalProgress) Advance(n int64) {
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+
+	progress.current += n
+	if time.Since(progress.lastDraw) >= progressRefreshInterval {
+		progress.draw()
+	}
+}
+
+// Done implements ProgressReporter.
+func (progress *terminalProgress) Done() {
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+
+	progress.draw()
+	_, _ = fmt.Fprintln(progress.out)
+}
+
+// draw renders the progress line; callers must hold mu.
+func (progress *terminalProgress) draw() {
+	progress.lastDraw = time.Now()
+
+	if progress.total > 0 {
+		percent := min(progress.current*100/progress.total, 100)
+
+		_, _ = fmt.Fprintf(progress.out, "\r\033[K%s %3d%% (%s/%s)",
+			progress.label, percent, FormatBytes(progress.current), FormatBytes(progress.total))
+
+		return
+	}
+
+	_, _ = fmt.Fprintf(progress.out, "\r\033[K%s %s", progress.label, FormatBytes(progress.current))
+}
+
+// Write implements io.Writer.
+func (writer *progressWriter) Write(buff []byte) (int, error) {
+	writer.reporter.Advance(int64(len(buff)))
+
+	return len(buff), nil
+}
+
+// Read implements io.Reader.
+func (reader *progressReader) Read(buff []byte) (int, error) {
+	n, err := reader.reader.Read(buff)
+	if n > 0 {
+		reader.reporter.Advance(int64(n))
+	}
+
+	return n, err
+}
+
+// FormatBytes renders a byte count using binary units.
+func FormatBytes(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// isTerminal reports whether the file is attached to a character device.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,192 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// recordingProgress is a ProgressReporter that records every callback.
+type recordingProgress struct {
+	starts   []int64
+	advanced int64
+	done     int
+	mu       sync.Mutex
+}
+
+func (progress *recordingProgress) Start(total int64) {
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+
+	progress.starts = append(progress.starts, total)
+}
+
+func (progress *recordingProgress) Advance(n int64) {
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+
+	progress.advanced += n
+}
+
+func (progress *recordingProgress) Done() {
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+
+	progress.done++
+}
+
+func TestDownloadFileReportsProgress(t *testing.T) {
+	t.Parallel()
+
+	payload := bytes.Repeat([]byte("x"), 64*1024)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		_, _ = w.Write(payload)
+	}))
+	defer server.Close()
+
+	reporter := &recordingProgress{}
+	ctx := asdf.WithProgressReporter(t.Context(), reporter)
+
+	destPath := filepath.Join(t.TempDir(), "artifact.bin")
+	require.NoError(t, asdf.DownloadFile(ctx, server.URL, destPath))
+
+	require.Equal(t, []int64{int64(len(payload))}, reporter.starts)
+	require.Equal(t, int64(len(payload)), reporter.advanced)
+	require.Equal(t, 1, reporter.done)
+}
+
+func TestDownloadFileReportsUnknownTotal(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+
+		_, _ = w.Write([]byte("chunk-one"))
+		flusher.Flush()
+		_, _ = w.Write([]byte("chunk-two"))
+	}))
+	defer server.Close()
+
+	reporter := &recordingProgress{}
+	ctx := asdf.WithProgressReporter(t.Context(), reporter)
+
+	require.NoError(t, asdf.DownloadFile(ctx, server.URL, filepath.Join(t.TempDir(), "chunked")))
+
+	require.Equal(t, []int64{-1}, reporter.starts)
+	require.Equal(t, int64(len("chunk-onechunk-two")), reporter.advanced)
+	require.Equal(t, 1, reporter.done)
+}
+
+func TestExtractReportsProgress(t *testing.T) {
+	reporter := &recordingProgress{}
+	asdf.SetProgressReporterFactoryForTests(t, func(string) asdf.ProgressReporter { return reporter })
+
+	tempDir := t.TempDir()
+
+	t.Run("tar.gz reports archive size", func(t *testing.T) {
+		archivePath := filepath.Join(tempDir, "tool.tar.gz")
+		createTestTarGz(t, archivePath, "tool", "binary content")
+
+		info, err := os.Stat(archivePath)
+		require.NoError(t, err)
+
+		require.NoError(t, asdf.ExtractTarGz(archivePath, filepath.Join(tempDir, "targz")))
+		require.Equal(t, info.Size(), reporter.starts[len(reporter.starts)-1])
+		require.Equal(t, info.Size(), reporter.advanced)
+		require.Equal(t, 1, reporter.done)
+	})
+
+	t.Run("zip reports uncompressed size", func(t *testing.T) {
+		*reporter = recordingProgress{}
+
+		archivePath := filepath.Join(tempDir, "tool.zip")
+		createTestZip(t, archivePath, "tool", "binary content")
+
+		require.NoError(t, asdf.ExtractZip(archivePath, filepath.Join(tempDir, "zip")))
+		require.Equal(t, []int64{int64(len("binary content"))}, reporter.starts)
+		require.Equal(t, int64(len("binary content")), reporter.advanced)
+		require.Equal(t, 1, reporter.done)
+	})
+}
+
+func TestTerminalProgress(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	reporter := asdf.NewTerminalProgressForTests(&out, "Downloading jq")
+	reporter.Start(2048)
+	reporter.Advance(1024)
+	reporter.Advance(1024)
+	reporter.Done()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\r")
+	require.Contains(t, lines[len(lines)-1], "Downloading jq 100% (2.0 KiB/2.0 KiB)")
+	require.True(t, strings.HasSuffix(out.String(), "\n"))
+}
+
+func TestProgressReporterFromContext(t *testing.T) {
+	t.Parallel()
+
+	reporter := &recordingProgress{}
+
+	require.Same(t, reporter, asdf.ProgressReporterFromContext(
+		asdf.WithProgressReporter(t.Context(), reporter), "label",
+	))
+
+	fallback := asdf.ProgressReporterFromContext(t.Context(), "label")
+	require.NotNil(t, fallback)
+
+	_, isRecording := fallback.(*recordingProgress)
+	require.False(t, isRecording)
+}
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expected string
+		size     int64
+	}{
+		{expected: "0 B", size: 0},
+		{expected: "1023 B", size: 1023},
+		{expected: "1.0 KiB", size: 1024},
+		{expected: "1.5 MiB", size: 1536 * 1024},
+		{expected: "2.0 GiB", size: 2 << 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, asdf.FormatBytes(tt.size))
+		})
+	}
+}
@@ -0,0 +1,176 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/github"
+)
+
+const (
+	// ProvenanceStrictEnv makes a missing or failed provenance verification fail
+	// the install when set to "1"; otherwise it only produces a warning.
+	ProvenanceStrictEnv = "ASDF_PROVENANCE_STRICT"
+	// ProvenanceBackendEnv selects the verification backend. When set to "gh" and
+	// the github-cli plugin is installed, `gh attestation verify` is used instead
+	// of the GitHub attestations API.
+	ProvenanceBackendEnv = "ASDF_PROVENANCE_BACKEND"
+	// ProvenanceFileName is the install metadata file recording the verification result.
+	ProvenanceFileName = ".provenance.json"
+
+	// provenanceBackendAPI identifies verification through the GitHub attestations API.
+	provenanceBackendAPI = "github-api"
+	// provenanceBackendGh identifies verification through `gh attestation verify`.
+	provenanceBackendGh = "gh"
+)
+
+// errProvenanceNotVerified is returned in strict mode when an artifact has no verified provenance.
+var errProvenanceNotVerified = errors.New("provenance not verified")
+
+// ProvenanceResult records the outcome of a build provenance verification.
+type ProvenanceResult struct {
+	// Artifact is the file name of the verified artifact.
+	Artifact string `json:"artifact"`
+	// Digest is the artifact digest, e.g. "sha256:<hex>".
+	Digest string `json:"digest"`
+	// Repository is the owner/repo the attestation must come from.
+	Repository string `json:"repository"`
+	// Backend is the backend that performed the verification.
+	Backend string `json:"backend"`
+	// Detail explains why verification failed.
+	Detail string `json:"detail,omitempty"`
+	// Verified reports whether a matching attestation was found.
+	Verified bool `json:"verified"`
+}
+
+// VerifyProvenance checks that owner/repo published a build provenance
+// attestation for the artifact. The GitHub attestations API is used unless
+// ASDF_PROVENANCE_BACKEND=gh selects an installed gh. A missing attestation is
+// reported in the result; the error is only set when verification could not run.
+func VerifyProvenance(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, artifactPath string,
+) (ProvenanceResult, error) {
+	digest, err := FileSHA256(artifactPath)
+	if err != nil {
+		return ProvenanceResult{}, err
+	}
+
+	result := ProvenanceResult{
+		Artifact:   filepath.Base(artifactPath),
+		Digest:     "sha256:" + digest,
+		Repository: owner + "/" + repo,
+	}
+
+	if os.Getenv(ProvenanceBackendEnv) == provenanceBackendGh {
+		if gh := ToolchainBinary("github-cli", filepath.Join("bin", "gh")); gh != "" {
+			result.Backend = provenanceBackendGh
+
+			err := RunCommand(ctx, nil, gh, "attestation", "verify", artifactPath, "--repo", result.Repository)
+			if err != nil {
+				result.Detail = err.Error()
+			}
+
+			result.Verified = err == nil
+
+			return result, nil
+		}
+
+		Logger().WarnContext(ctx, "gh is not installed, verifying provenance through the GitHub API")
+	}
+
+	result.Backend = provenanceBackendAPI
+
+	attestations, err := client.GetAttestations(ctx, owner, repo, result.Digest)
+	if err != nil {
+		return result, err
+	}
+
+	result.Verified = len(attestations) > 0
+	if !result.Verified {
+		result.Detail = "no attestation found for " + result.Digest
+	}
+
+	return result, nil
+}
+
+// CheckProvenance verifies the artifact provenance and applies the policy of
+// ASDF_PROVENANCE_STRICT: failures are returned in strict mode and only logged
+// otherwise. The result is returned in both cases so it can be recorded.
+func CheckProvenance(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, artifactPath string,
+) (ProvenanceResult, error) {
+	result, err := VerifyProvenance(ctx, client, owner, repo, artifactPath)
+	if err != nil {
+		result.Detail = err.Error()
+	}
+
+	if result.Verified {
+		Msgf("Verified build provenance of %s (%s)", result.Artifact, result.Backend)
+
+		return result, nil
+	}
+
+	if os.Getenv(ProvenanceStrictEnv) == "1" {
+		return result, fmt.Errorf("%w: %s: %s", errProvenanceNotVerified, result.Artifact, result.Detail)
+	}
+
+	Errf("Warning: build provenance of %s not verified: %s", result.Artifact, result.Detail)
+
+	return result, nil
+}
+
+// WriteProvenance records result in the install metadata of installPath.
+// Nothing is recorded with ReproducibleEnv set, as the verification backend
+// and its outcome depend on the machine.
+func WriteProvenance(installPath string, result ProvenanceResult) error {
+	if Reproducible() {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(installPath, ProvenanceFileName)
+	if err := os.WriteFile(path, append(data, '\n'), CommonFilePermission); err != nil {
+		return fmt.Errorf("recording provenance: %w", err)
+	}
+
+	return nil
+}
+
+// ReadProvenance returns the provenance result recorded in installPath.
+func ReadProvenance(installPath string) (ProvenanceResult, error) {
+	var result ProvenanceResult
+
+	data, err := os.ReadFile(filepath.Join(installPath, ProvenanceFileName))
+	if err != nil {
+		return result, err
+	}
+
+	return result, json.Unmarshal(data, &result)
+}
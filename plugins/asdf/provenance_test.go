@@ -0,0 +1,156 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/github"
+	githubmock "github.com/sumicare/universal-asdf-plugin/plugins/github/mock"
+)
+
+// writeArtifact writes a downloaded artifact and returns its path and digest.
+func writeArtifact(t *testing.T, dir string) (path, digest string) {
+	t.Helper()
+
+	path = filepath.Join(dir, "tool_1.0.0_linux_amd64.tar.gz")
+	require.NoError(t, os.WriteFile(path, []byte("artifact"), asdf.CommonFilePermission))
+
+	sum, err := asdf.FileSHA256(path)
+	require.NoError(t, err)
+
+	return path, "sha256:" + sum
+}
+
+func TestCheckProvenance(t *testing.T) {
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	client := github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL())
+
+	artifact, digest := writeArtifact(t, t.TempDir())
+	srv.AddAttestation("owner", "attested", digest)
+
+	t.Run("accepts artifacts with an attestation", func(t *testing.T) {
+		t.Setenv(asdf.ProvenanceStrictEnv, "1")
+		t.Setenv(asdf.ProvenanceBackendEnv, "")
+
+		result, err := asdf.CheckProvenance(t.Context(), client, "owner", "attested", artifact)
+		require.NoError(t, err)
+		require.Equal(t, asdf.ProvenanceResult{
+			Artifact:   filepath.Base(artifact),
+			Digest:     digest,
+			Repository: "owner/attested",
+			Backend:    "github-api",
+			Verified:   true,
+		}, result)
+	})
+
+	t.Run("warns about missing attestations", func(t *testing.T) {
+		t.Setenv(asdf.ProvenanceStrictEnv, "")
+		t.Setenv(asdf.ProvenanceBackendEnv, "")
+
+		result, err := asdf.CheckProvenance(t.Context(), client, "owner", "plain", artifact)
+		require.NoError(t, err)
+		require.False(t, result.Verified)
+		require.Contains(t, result.Detail, "no attestation found for "+digest)
+	})
+
+	t.Run("fails on missing attestations in strict mode", func(t *testing.T) {
+		t.Setenv(asdf.ProvenanceStrictEnv, "1")
+		t.Setenv(asdf.ProvenanceBackendEnv, "")
+
+		result, err := asdf.CheckProvenance(t.Context(), client, "owner", "plain", artifact)
+		require.ErrorIs(t, err, asdf.ErrProvenanceNotVerifiedForTests())
+		require.False(t, result.Verified)
+	})
+}
+
+func TestVerifyProvenanceWithGh(t *testing.T) {
+	dataDir := t.TempDir()
+	gh := filepath.Join(dataDir, "installs", "github-cli", "2.60.0", "bin", "gh")
+	require.NoError(t, os.MkdirAll(filepath.Dir(gh), asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(gh, []byte("#!/bin/sh\n"), asdf.CommonExecutablePermission))
+
+	t.Setenv("ASDF_DATA_DIR", dataDir)
+	t.Setenv("ASDF_INSTALLS_DIR", "")
+	t.Setenv(asdf.ProvenanceBackendEnv, "gh")
+
+	artifact, digest := writeArtifact(t, t.TempDir())
+
+	t.Run("passes the artifact and repository to gh", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "gh.log")
+		t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+
+		asdf.MockExecForTests(t, nil)
+		asdf.MockOSForTests(t, dataDir, dataDir)
+
+		result, err := asdf.VerifyProvenance(t.Context(), nil, "cli", "cli", artifact)
+		require.NoError(t, err)
+		require.True(t, result.Verified)
+		require.Equal(t, "gh", result.Backend)
+		require.Equal(t, digest, result.Digest)
+
+		logged, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		require.Equal(t, "gh attestation verify "+artifact+" --repo cli/cli\n", string(logged))
+	})
+
+	t.Run("reports gh failures", func(t *testing.T) {
+		t.Setenv("ASDF_MOCK_COMMAND_STDERR", "X Verification failed: no attestations found")
+
+		asdf.MockExecForTests(t, nil)
+		asdf.MockOSForTests(t, dataDir, dataDir)
+
+		result, err := asdf.VerifyProvenance(t.Context(), nil, "cli", "cli", artifact)
+		require.NoError(t, err)
+		require.False(t, result.Verified)
+		require.Contains(t, result.Detail, "no attestations found")
+	})
+}
+
+func TestBinaryPluginInstallRecordsProvenance(t *testing.T) {
+	t.Setenv(asdf.ProvenanceStrictEnv, "")
+	t.Setenv(asdf.ProvenanceBackendEnv, "")
+
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	downloadPath, installPath := t.TempDir(), t.TempDir()
+
+	_, digest := writeArtifact(t, downloadPath)
+	srv.AddAttestation("owner", "repo", digest)
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:                   "test-tool",
+		RepoOwner:              "owner",
+		RepoName:               "repo",
+		BinaryName:             "test-tool",
+		ProvenanceVerification: true,
+	}).WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+
+	require.NoError(t, plugin.Install(t.Context(), "1.0.0", downloadPath, installPath))
+
+	result, err := asdf.ReadProvenance(installPath)
+	require.NoError(t, err)
+	require.True(t, result.Verified)
+	require.Equal(t, digest, result.Digest)
+	require.Equal(t, "owner/repo", result.Repository)
+}
@@ -0,0 +1,139 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// RegexpQueryPrefix marks a latest-stable query term as a Go regular
+	// expression matched against the full version, e.g. `re:^0\.13\.`.
+	RegexpQueryPrefix = "re:"
+	// ExcludeQueryPrefix marks a latest-stable query term excluding the versions
+	// it matches, e.g. "1. !1.5." for the newest 1.x that is not a 1.5 release.
+	ExcludeQueryPrefix = "!"
+	// LatestVersionPrefix selects the latest stable version matching the query
+	// that follows it in a pinned version, e.g. "latest:1.22".
+	LatestVersionPrefix = "latest:"
+)
+
+// errInvalidVersionQuery is returned when a latest-stable query cannot be parsed.
+var errInvalidVersionQuery = errors.New("invalid version query")
+
+// VersionQuery matches versions against a parsed latest-stable query.
+//
+// A query is a whitespace separated list of terms, optionally preceded by
+// PrereleaseQueryPrefix. A term is a version prefix, or a regular expression
+// after RegexpQueryPrefix; terms starting with ExcludeQueryPrefix exclude the
+// versions they match. A version matches when it matches every other term and
+// none of the exclusions, so an empty query matches every version.
+type VersionQuery struct {
+	include []func(string) bool
+	exclude []func(string) bool
+	// Prerelease reports whether the query opts into prereleases.
+	Prerelease bool
+}
+
+// ParseVersionQuery parses a latest-stable query, failing with an error naming
+// the query when one of its regular expressions is invalid.
+func ParseVersionQuery(query string) (*VersionQuery, error) {
+	pattern, prerelease := ParseLatestQuery(query)
+	parsed := &VersionQuery{Prerelease: prerelease}
+
+	for _, term := range strings.Fields(pattern) {
+		term, excluded := strings.CutPrefix(term, ExcludeQueryPrefix)
+
+		match, err := versionQueryTerm(term)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", errInvalidVersionQuery, query, err)
+		}
+
+		if excluded {
+			parsed.exclude = append(parsed.exclude, match)
+		} else {
+			parsed.include = append(parsed.include, match)
+		}
+	}
+
+	return parsed, nil
+}
+
+// versionQueryTerm returns the matcher of a single query term.
+func versionQueryTerm(term string) (func(string) bool, error) {
+	expression, isRegexp := strings.CutPrefix(term, RegexpQueryPrefix)
+	if !isRegexp {
+		return func(version string) bool {
+			return strings.HasPrefix(version, term)
+		}, nil
+	}
+
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return compiled.MatchString, nil
+}
+
+// Match reports whether version is selected by the query.
+func (query *VersionQuery) Match(version string) bool {
+	for _, include := range query.include {
+		if !include(version) {
+			return false
+		}
+	}
+
+	for _, exclude := range query.exclude {
+		if exclude(version) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseLatestVersion reports whether a pinned version asks for the latest
+// stable version, either as "latest" or as LatestVersionPrefix followed by a
+// query, and returns that query.
+func ParseLatestVersion(version string) (string, bool) {
+	if version == "latest" {
+		return "", true
+	}
+
+	query, latest := strings.CutPrefix(version, LatestVersionPrefix)
+	if !latest {
+		return "", false
+	}
+
+	return query, true
+}
+
+// ResolveLatestVersion resolves "latest" and "latest:<query>" pinned versions
+// to the latest stable version of plugin matching the query, and returns other
+// versions unchanged.
+func ResolveLatestVersion(ctx context.Context, plugin Plugin, version string) (string, error) {
+	query, latest := ParseLatestVersion(version)
+	if !latest {
+		return version, nil
+	}
+
+	return LatestStableVersion(ctx, plugin, query)
+}
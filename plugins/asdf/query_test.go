@@ -0,0 +1,95 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestMatchLatestVersionInvalidQuery verifies invalid regular expressions name the query.
+func TestMatchLatestVersionInvalidQuery(t *testing.T) {
+	t.Parallel()
+
+	_, err := asdf.MatchLatestVersion([]string{"1.0.0"}, "1. !re:1.(", false)
+	require.ErrorIs(t, err, asdf.ErrInvalidVersionQueryForTests())
+	require.ErrorContains(t, err, `invalid version query "1. !re:1.(": error parsing regexp`)
+
+	_, err = asdf.LatestStableWithQuery(t.Context(), "re:[", []string{"1.0.0"}, errors.New("none"), errors.New("no match"))
+	require.ErrorIs(t, err, asdf.ErrInvalidVersionQueryForTests())
+}
+
+// TestVersionQueryRegexp verifies re: terms match anywhere in the version,
+// unless the expression anchors itself.
+func TestVersionQueryRegexp(t *testing.T) {
+	t.Parallel()
+
+	query, err := asdf.ParseVersionQuery(`re:^0\.13\.`)
+	require.NoError(t, err)
+	require.True(t, query.Match("0.13.0"))
+	require.False(t, query.Match("1.0.13.2"))
+
+	query, err = asdf.ParseVersionQuery(`re:\.13\.`)
+	require.NoError(t, err)
+	require.True(t, query.Match("0.13.0"))
+	require.True(t, query.Match("1.0.13.2"))
+}
+
+// TestParseLatestVersion verifies "latest" and "latest:<query>" pins are recognized.
+func TestParseLatestVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version string
+		query   string
+		latest  bool
+	}{
+		{version: "latest", latest: true},
+		{version: "latest:1.22", query: "1.22", latest: true},
+		{version: `latest:re:^0\.13\.`, query: `re:^0\.13\.`, latest: true},
+		{version: "1.22.0"},
+		{version: "latest-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			t.Parallel()
+
+			query, latest := asdf.ParseLatestVersion(tt.version)
+			require.Equal(t, tt.latest, latest)
+			require.Equal(t, tt.query, query)
+		})
+	}
+}
+
+// TestResolveLatestVersion verifies latest pins resolve through the plugin and others pass through.
+func TestResolveLatestVersion(t *testing.T) {
+	t.Setenv(asdf.OfflineEnv, "")
+
+	plugin := &mockPlugin{latestVersion: "2.0.0"}
+
+	version, err := asdf.ResolveLatestVersion(t.Context(), plugin, "latest:2")
+	require.NoError(t, err)
+	require.Equal(t, "2.0.0", version)
+
+	version, err = asdf.ResolveLatestVersion(t.Context(), plugin, "1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "1.0.0", version)
+}
@@ -0,0 +1,116 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// InstallTypeEnv is the variable asdf sets to the install type of bin/download and bin/install.
+	InstallTypeEnv = "ASDF_INSTALL_TYPE"
+	// InstallTypeVersion installs a released version, the default.
+	InstallTypeVersion = "version"
+	// InstallTypeRef installs a git ref, a branch, tag or commit, built from source.
+	InstallTypeRef = "ref"
+	// RefVersionPrefix marks a version as a git ref, as in "golang ref:master".
+	RefVersionPrefix = "ref:"
+	// RefVersionDirPrefix starts the directory names of ref installs.
+	RefVersionDirPrefix = "ref-"
+)
+
+var (
+	// refUnsafeChars matches the characters RefVersionDir replaces.
+	refUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+	// errRefInstallUnsupported is returned when a plugin cannot build a git ref.
+	errRefInstallUnsupported = errors.New("ref installs not supported")
+	// errInstallTypeUnknown is returned for an install type other than version or ref.
+	errInstallTypeUnknown = errors.New("unknown install type")
+)
+
+// ParseInstallType returns the install type named by value, InstallTypeVersion when empty.
+func ParseInstallType(value string) (string, error) {
+	switch strings.TrimSpace(value) {
+	case "", InstallTypeVersion:
+		return InstallTypeVersion, nil
+	case InstallTypeRef:
+		return InstallTypeRef, nil
+	default:
+		return "", fmt.Errorf("%w: %q (use %s or %s)", errInstallTypeUnknown, value, InstallTypeVersion, InstallTypeRef)
+	}
+}
+
+// ParseRefVersion returns the git ref named by version: the rest of a
+// "ref:<ref>" version, or version itself when ASDF_INSTALL_TYPE is ref.
+func ParseRefVersion(version string) (string, bool) {
+	if ref, ok := strings.CutPrefix(version, RefVersionPrefix); ok {
+		return ref, true
+	}
+
+	if os.Getenv(InstallTypeEnv) == InstallTypeRef {
+		return version, true
+	}
+
+	return "", false
+}
+
+// RefVersionDir returns the directory name of ref in the data layout: "ref-"
+// followed by the ref with every character but letters, digits, dots,
+// dashes and underscores replaced by a dash.
+func RefVersionDir(ref string) string {
+	return RefVersionDirPrefix + refUnsafeChars.ReplaceAllString(ref, "-")
+}
+
+// RefInstallPlugin returns plugin adapted to install git refs passed as the
+// version: Download does nothing, since the sources of a ref are fetched when
+// building it, and Install calls InstallRef. Plugins that are not
+// RefInstallers get an error naming them.
+func RefInstallPlugin(plugin Plugin) (Plugin, error) {
+	installer, ok := plugin.(RefInstaller)
+	if !ok || !installer.SupportsRefs() {
+		return nil, fmt.Errorf("%w: %s only installs released versions", errRefInstallUnsupported, plugin.Name())
+	}
+
+	return &refPlugin{RefInstaller: installer}, nil
+}
+
+// refPlugin installs the git ref passed as the version through a RefInstaller.
+type refPlugin struct {
+	RefInstaller
+}
+
+// Download does nothing, InstallRef fetches the sources of the ref.
+func (*refPlugin) Download(_ context.Context, _, _ string) error {
+	return nil
+}
+
+// Install builds ref into installPath.
+func (plugin *refPlugin) Install(ctx context.Context, ref, downloadPath, installPath string) error {
+	return plugin.InstallRef(ctx, ref, downloadPath, installPath)
+}
+
+// GitHubRefArchiveURL returns the URL of the source archive of ref in the
+// GitHub repository owner/repo. GitHub serves archives of branches, tags and
+// commits alike, and of fully qualified refs such as "refs/heads/main".
+func GitHubRefArchiveURL(owner, repo, ref string) string {
+	return "https://github.com/" + owner + "/" + repo + "/archive/" + ref + ".tar.gz"
+}
@@ -0,0 +1,115 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// refPlugin is a mock plugin that records the refs it installs.
+type refPlugin struct {
+	mockPlugin
+
+	installedRef string
+}
+
+func (*refPlugin) SupportsRefs() bool { return true }
+
+func (plugin *refPlugin) InstallRef(_ context.Context, ref, _, _ string) error {
+	plugin.installedRef = ref
+
+	return nil
+}
+
+// TestParseInstallType verifies install types default to version and reject unknown ones.
+func TestParseInstallType(t *testing.T) {
+	t.Parallel()
+
+	for value, expected := range map[string]string{
+		"":        asdf.InstallTypeVersion,
+		"version": asdf.InstallTypeVersion,
+		"ref":     asdf.InstallTypeRef,
+	} {
+		installType, err := asdf.ParseInstallType(value)
+		require.NoError(t, err)
+		require.Equal(t, expected, installType)
+	}
+
+	_, err := asdf.ParseInstallType("path")
+	require.ErrorIs(t, err, asdf.ErrInstallTypeUnknownForTests())
+}
+
+// TestRefInstallPlugin verifies refs are installed through InstallRef, and
+// refused by plugins that only install released versions.
+func TestRefInstallPlugin(t *testing.T) {
+	t.Parallel()
+
+	_, err := asdf.RefInstallPlugin(&mockPlugin{})
+	require.ErrorIs(t, err, asdf.ErrRefInstallUnsupportedForTests())
+	require.EqualError(t, err, "ref installs not supported: mock only installs released versions")
+
+	installer := &refPlugin{}
+
+	plugin, err := asdf.RefInstallPlugin(installer)
+	require.NoError(t, err)
+	require.Equal(t, "mock", plugin.Name())
+	require.NoError(t, plugin.Download(t.Context(), "main", t.TempDir()))
+	require.NoError(t, plugin.Install(t.Context(), "main", t.TempDir(), t.TempDir()))
+	require.Equal(t, "main", installer.installedRef)
+	require.False(t, installer.installCalled)
+}
+
+// TestGitHubRefArchiveURL verifies the archive URL of branches and fully qualified refs.
+func TestGitHubRefArchiveURL(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "https://github.com/golang/go/archive/master.tar.gz",
+		asdf.GitHubRefArchiveURL("golang", "go", "master"))
+	require.Equal(t, "https://github.com/golang/go/archive/refs/heads/master.tar.gz",
+		asdf.GitHubRefArchiveURL("golang", "go", "refs/heads/master"))
+}
+
+// TestParseRefVersion verifies refs are read from "ref:" versions and ASDF_INSTALL_TYPE.
+func TestParseRefVersion(t *testing.T) {
+	t.Setenv(asdf.InstallTypeEnv, "")
+
+	ref, isRef := asdf.ParseRefVersion("ref:refs/heads/main")
+	require.True(t, isRef)
+	require.Equal(t, "refs/heads/main", ref)
+
+	_, isRef = asdf.ParseRefVersion("1.22.0")
+	require.False(t, isRef)
+
+	t.Setenv(asdf.InstallTypeEnv, asdf.InstallTypeRef)
+
+	ref, isRef = asdf.ParseRefVersion("main")
+	require.True(t, isRef)
+	require.Equal(t, "main", ref)
+}
+
+// TestRefVersionDir verifies refs are sanitized into a single directory name.
+func TestRefVersionDir(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "ref-main", asdf.RefVersionDir("main"))
+	require.Equal(t, "ref-refs-heads-release-1.22", asdf.RefVersionDir("refs/heads/release-1.22"))
+	require.Equal(t, "ref-feature-x-y", asdf.RefVersionDir("feature:x y"))
+}
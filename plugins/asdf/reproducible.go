@@ -0,0 +1,124 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// ReproducibleEnv, set to 1, makes repeated installs of a version produce
+	// identical trees, e.g. for container layer caching: extracted entries keep
+	// the modification time of the archive, other entries get the Unix epoch,
+	// permissions are normalized and no machine-specific metadata is recorded.
+	ReproducibleEnv = "ASDF_REPRODUCIBLE"
+	// reproducibleFilePermission is the mode of non-executable files in reproducible trees.
+	reproducibleFilePermission os.FileMode = 0o644
+	// reproducibleExecutablePermission is the mode of directories and
+	// executable files in reproducible trees.
+	reproducibleExecutablePermission os.FileMode = 0o755
+	// mtimeGranularity covers filesystems storing modification times in
+	// coarse steps, 2 seconds on FAT, when telling new entries from old ones.
+	mtimeGranularity = 2 * time.Second
+)
+
+// Reproducible reports whether ReproducibleEnv is set to 1.
+func Reproducible() bool {
+	return os.Getenv(ReproducibleEnv) == "1"
+}
+
+// ReproducibleMode returns the permission of an entry with mode in a
+// reproducible tree: 0755 for directories and files executable by anyone,
+// 0644 for other files.
+func ReproducibleMode(mode os.FileMode) os.FileMode {
+	if mode.IsDir() || mode.Perm()&0o111 != 0 {
+		return reproducibleExecutablePermission
+	}
+
+	return reproducibleFilePermission
+}
+
+// reproducibleTime returns modTime, or the Unix epoch when it is unset or older.
+func reproducibleTime(modTime time.Time) time.Time {
+	epoch := time.Unix(0, 0)
+	if modTime.Before(epoch) {
+		return epoch
+	}
+
+	return modTime
+}
+
+// NormalizeInstall gives the entries of installPath their ReproducibleMode,
+// and the Unix epoch as modification time to those modified since the install
+// started, when Reproducible is set. Older entries keep the time extracted
+// from their archive. Symlinks are left untouched.
+func NormalizeInstall(installPath string, since time.Time) error {
+	if !Reproducible() {
+		return nil
+	}
+
+	root, err := os.OpenRoot(installPath)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	cutoff := since.Add(-mtimeGranularity)
+
+	return normalizeTree(root, func(_ string, info fs.FileInfo) time.Time {
+		if info.ModTime().Before(cutoff) {
+			return info.ModTime()
+		}
+
+		return time.Unix(0, 0)
+	})
+}
+
+// normalizeTree gives every entry of root but symlinks its ReproducibleMode
+// and the modification time returned by mtime for its relative path.
+func normalizeTree(root *os.Root, mtime func(rel string, info fs.FileInfo) time.Time) error {
+	return fs.WalkDir(root.FS(), ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		rel := filepath.FromSlash(name)
+
+		if err := root.Chmod(rel, ReproducibleMode(info.Mode())); err != nil {
+			return fmt.Errorf("setting mode of %s: %w", rel, err)
+		}
+
+		modTime := mtime(rel, info)
+		if err := root.Chtimes(rel, modTime, modTime); err != nil {
+			return fmt.Errorf("setting modification time of %s: %w", rel, err)
+		}
+
+		return nil
+	})
+}
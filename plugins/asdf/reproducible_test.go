@@ -0,0 +1,142 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"archive/tar"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// treeMetadata lists the path, mode and modification time of every entry
+// below root but symlinks, which HashTree leaves out.
+func treeMetadata(t *testing.T, root string) []string {
+	t.Helper()
+
+	var entries []string
+
+	require.NoError(t, filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		require.NoError(t, err)
+
+		info, err := os.Lstat(path)
+		require.NoError(t, err)
+
+		rel, err := filepath.Rel(root, path)
+		require.NoError(t, err)
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			entries = append(entries, rel+" "+info.Mode().String()+" "+info.ModTime().UTC().String())
+		}
+
+		return nil
+	}))
+
+	return entries
+}
+
+// TestReproducibleInstall verifies that installing the same archive twice
+// with ASDF_REPRODUCIBLE=1 yields identical trees, keeping the archive
+// modification times and normalizing permissions and machine-made entries.
+func TestReproducibleInstall(t *testing.T) {
+	t.Setenv(asdf.ReproducibleEnv, "1")
+
+	released := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+	archivePath := filepath.Join(t.TempDir(), "tool.tar.gz")
+	createArchive(t, archivePath, func(tw *tar.Writer) {
+		for _, header := range []*tar.Header{
+			{Name: "tool/", Typeflag: tar.TypeDir, Mode: 0o775, ModTime: released},
+			{Name: "tool/bin/tool", Typeflag: tar.TypeReg, Mode: 0o700, Size: 3, ModTime: released.Add(time.Hour)},
+			{Name: "tool/README", Typeflag: tar.TypeReg, Mode: 0o600, Size: 3},
+			{Name: "tool/latest", Typeflag: tar.TypeSymlink, Linkname: "bin/tool", ModTime: released},
+		} {
+			require.NoError(t, tw.WriteHeader(header))
+
+			if header.Size > 0 {
+				_, err := tw.Write([]byte("abc"))
+				require.NoError(t, err)
+			}
+		}
+	})
+
+	install := func(t *testing.T) string {
+		t.Helper()
+
+		installPath := t.TempDir()
+		started := time.Now()
+
+		require.NoError(t, asdf.ExtractTarGz(archivePath, installPath))
+		require.NoError(t, os.WriteFile(filepath.Join(installPath, "tool", "bin", "shim"), []byte("#!/bin/sh\n"), 0o700))
+		require.NoError(t, asdf.NormalizeInstall(installPath, started))
+
+		return installPath
+	}
+
+	first, second := install(t), install(t)
+
+	firstHash, err := asdf.HashTree(first)
+	require.NoError(t, err)
+
+	secondHash, err := asdf.HashTree(second)
+	require.NoError(t, err)
+
+	require.Equal(t, firstHash.Hash, secondHash.Hash)
+	require.Equal(t, treeMetadata(t, first), treeMetadata(t, second))
+
+	for rel, want := range map[string]struct {
+		modTime time.Time
+		mode    os.FileMode
+	}{
+		"tool":          {mode: fs.ModeDir | 0o755, modTime: released},
+		"tool/bin":      {mode: fs.ModeDir | 0o755, modTime: time.Unix(0, 0)},
+		"tool/bin/tool": {mode: 0o755, modTime: released.Add(time.Hour)},
+		"tool/bin/shim": {mode: 0o755, modTime: time.Unix(0, 0)},
+		"tool/README":   {mode: 0o644, modTime: time.Unix(0, 0)},
+	} {
+		info, err := os.Stat(filepath.Join(first, filepath.FromSlash(rel)))
+		require.NoError(t, err)
+		require.Equal(t, want.mode, info.Mode(), rel)
+		require.True(t, want.modTime.Equal(info.ModTime()), "%s: %s", rel, info.ModTime())
+	}
+}
+
+// TestReproducibleInstallMetadata verifies that no machine-specific install
+// metadata is recorded with ASDF_REPRODUCIBLE=1, and that installs are left
+// alone without it.
+func TestReproducibleInstallMetadata(t *testing.T) {
+	installPath := t.TempDir()
+	file := filepath.Join(installPath, "file")
+	require.NoError(t, os.WriteFile(file, []byte("x"), 0o600))
+
+	require.NoError(t, asdf.NormalizeInstall(installPath, time.Now()))
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode())
+
+	t.Setenv(asdf.ReproducibleEnv, "1")
+
+	require.NoError(t, asdf.WriteBuildEnv(installPath, asdf.BuildExecer{}))
+	require.NoError(t, asdf.WriteProvenance(installPath, asdf.ProvenanceResult{Verified: true}))
+	require.NoFileExists(t, filepath.Join(installPath, asdf.BuildEnvFileName))
+	require.NoFileExists(t, filepath.Join(installPath, asdf.ProvenanceFileName))
+}
@@ -0,0 +1,28 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin
+
+package asdf
+
+import "golang.org/x/sys/unix"
+
+// isProcessTranslated reports whether the process is an Intel binary translated
+// by Rosetta 2 on Apple Silicon.
+func isProcessTranslated() bool {
+	translated, err := unix.SysctlUint32("sysctl.proc_translated")
+
+	return err == nil && translated == 1
+}
@@ -0,0 +1,193 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// SBOMSpecVersion is the CycloneDX specification version of generated SBOMs.
+	SBOMSpecVersion = "1.5"
+
+	// sbomToolName names this program in the metadata of generated SBOMs.
+	sbomToolName = "universal-asdf-plugin"
+)
+
+// sha256Hex matches a hex encoded SHA-256 digest, the only form CycloneDX accepts.
+var sha256Hex = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
+type (
+	// SBOM is a CycloneDX JSON document listing installed tools.
+	SBOM struct {
+		BOMFormat    string          `json:"bomFormat"`
+		SpecVersion  string          `json:"specVersion"`
+		SerialNumber string          `json:"serialNumber"`
+		Version      int             `json:"version"`
+		Metadata     SBOMMetadata    `json:"metadata"`
+		Components   []SBOMComponent `json:"components"`
+	}
+
+	// SBOMMetadata records when and by what an SBOM was generated.
+	SBOMMetadata struct {
+		Timestamp string    `json:"timestamp"`
+		Tools     SBOMTools `json:"tools"`
+	}
+
+	// SBOMTools lists the tools that generated an SBOM.
+	SBOMTools struct {
+		Components []SBOMComponent `json:"components"`
+	}
+
+	// SBOMComponent is an installed tool version.
+	SBOMComponent struct {
+		Type               string          `json:"type"`
+		BOMRef             string          `json:"bom-ref,omitempty"`
+		Name               string          `json:"name"`
+		Version            string          `json:"version,omitempty"`
+		Purl               string          `json:"purl,omitempty"`
+		Hashes             []SBOMHash      `json:"hashes,omitempty"`
+		ExternalReferences []SBOMReference `json:"externalReferences,omitempty"`
+		Properties         []SBOMProperty  `json:"properties,omitempty"`
+	}
+
+	// SBOMHash is a checksum of a component.
+	SBOMHash struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	}
+
+	// SBOMReference links a component to an external resource, such as the
+	// "distribution" it was downloaded from.
+	SBOMReference struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	}
+
+	// SBOMProperty is a name/value detail of a component.
+	SBOMProperty struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+)
+
+// NewSBOM returns a CycloneDX document listing components.
+func NewSBOM(components []SBOMComponent) SBOM {
+	if components == nil {
+		components = []SBOMComponent{}
+	}
+
+	return SBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  SBOMSpecVersion,
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: SBOMMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools:     SBOMTools{Components: []SBOMComponent{{Type: "application", Name: sbomToolName}}},
+		},
+		Components: components,
+	}
+}
+
+// Write prints the SBOM as indented JSON.
+func (sbom SBOM) Write(out io.Writer) error {
+	data, err := json.MarshalIndent(sbom, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, string(data))
+
+	return err
+}
+
+// SBOMComponentOf describes version of tool installed at installPath. plugin
+// may be nil for tools without one, which get a pkg:generic package URL.
+// sum is the checksum of the install recorded in .tool-sums, if known. The
+// download URLs come from PluginWithArtifactResolver, and SBOMEnricher
+// plugins add their own details.
+func SBOMComponentOf(ctx context.Context, plugin Plugin, tool, version, installPath, sum string) (SBOMComponent, error) {
+	component := SBOMComponent{
+		Type:    "application",
+		BOMRef:  tool + "@" + version,
+		Name:    tool,
+		Version: version,
+		Purl:    "pkg:generic/" + purlEscape(tool) + "@" + purlEscape(version),
+	}
+
+	if digest, ok := strings.CutPrefix(sum, "sha256:"); ok && sha256Hex.MatchString(digest) {
+		component.Hashes = []SBOMHash{{Alg: "SHA-256", Content: strings.ToLower(digest)}}
+	}
+
+	if plugin == nil {
+		return component, nil
+	}
+
+	if releaser, ok := plugin.(GitHubReleaser); ok {
+		if owner, repo := releaser.GitHubRepository(); owner != "" && repo != "" {
+			component.Purl = "pkg:github/" + strings.ToLower(owner) + "/" + strings.ToLower(repo) +
+				"@" + purlEscape(version)
+		}
+	}
+
+	// Ref installs have no release to point at.
+	if resolver, ok := plugin.(PluginWithArtifactResolver); ok && !strings.HasPrefix(version, RefVersionDirPrefix) {
+		artifacts, err := resolver.ResolveArtifacts(ctx, version)
+		if err != nil {
+			Logger().Warn("cannot resolve download URLs for the SBOM", "tool", tool, "version", version, "error", err)
+		}
+
+		for _, artifact := range artifacts {
+			component.ExternalReferences = append(component.ExternalReferences,
+				SBOMReference{Type: "distribution", URL: artifact.URL})
+		}
+	}
+
+	if enricher, ok := plugin.(SBOMEnricher); ok {
+		if err := enricher.EnrichSBOM(installPath, &component); err != nil {
+			return SBOMComponent{}, fmt.Errorf("describing %s %s: %w", tool, version, err)
+		}
+	}
+
+	return component, nil
+}
+
+// purlEscape percent-encodes a package URL segment, including the "+" of
+// build metadata, which package URLs reserve.
+func purlEscape(segment string) string {
+	return strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	var uuid [16]byte
+
+	_, _ = rand.Read(uuid[:])
+
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
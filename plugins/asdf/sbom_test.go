@@ -0,0 +1,64 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestSBOMComponentOf verifies package URLs, checksums and download URLs of SBOM components.
+func TestSBOMComponentOf(t *testing.T) {
+	t.Parallel()
+
+	component, err := asdf.SBOMComponentOf(t.Context(), nil, "mytool", "1.0.0+build", "/opt/mytool", "sha256:not-hex")
+	require.NoError(t, err)
+	require.Equal(t, asdf.SBOMComponent{
+		Type:    "application",
+		BOMRef:  "mytool@1.0.0+build",
+		Name:    "mytool",
+		Version: "1.0.0+build",
+		Purl:    "pkg:generic/mytool@1.0.0%2Bbuild",
+	}, component)
+
+	plugin := asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:                "tool",
+		RepoOwner:           "Owner",
+		RepoName:            "Tool",
+		BinaryName:          "tool",
+		FileNameTemplate:    "tool-{{.Version}}",
+		DownloadURLTemplate: "https://example.com/{{.FileName}}",
+		OsMap:               map[string]string{"linux": "linux", "darwin": "darwin", "windows": "windows"},
+		ArchMap:             map[string]string{"amd64": "amd64", "arm64": "arm64"},
+	})
+
+	component, err = asdf.SBOMComponentOf(t.Context(), plugin, "tool", "2.0.0", "/opt/tool", "")
+	require.NoError(t, err)
+	require.Equal(t, "pkg:github/owner/tool@2.0.0", component.Purl)
+	require.Equal(t, []asdf.SBOMReference{{Type: "distribution", URL: "https://example.com/tool-2.0.0"}},
+		component.ExternalReferences)
+
+	component, err = asdf.SBOMComponentOf(t.Context(), plugin, "tool", asdf.RefVersionDir("main"), "/opt/tool", "")
+	require.NoError(t, err)
+	require.Empty(t, component.ExternalReferences, "ref installs have no release")
+
+	sbom := asdf.NewSBOM(nil)
+	require.Regexp(t, `^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, sbom.SerialNumber)
+	require.NotNil(t, sbom.Components)
+}
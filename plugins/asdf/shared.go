@@ -0,0 +1,267 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SharedDataDirHelp documents the supported model for sharing ASDF_DATA_DIR between users.
+const SharedDataDirHelp = `Shared data directories:
+  ASDF_DATA_DIR can be shared by several users through a common group. Give
+  the directory to that group and set the setgid bit so that everything
+  created below it inherits the group:
+
+    chgrp -R tools /opt/asdf
+    chmod -R g+rwX /opt/asdf
+    find /opt/asdf -type d -exec chmod g+s {} +
+
+  Below a setgid directory, lock files and directories are created
+  group-writable, directories keep the setgid bit, and the files of
+  installs, install metadata and shims are made group-readable (and
+  executable where the owner can execute them), regardless of the umask of
+  the installing user. Run 'doctor' to find entries that other members of
+  the group cannot use.`
+
+const (
+	// groupReadWriteExecute mirrors every owner permission bit to the group.
+	groupReadWriteExecute os.FileMode = 0o070
+	// groupReadExecute mirrors the owner read and execute bits to the group.
+	groupReadExecute os.FileMode = 0o050
+	// doctorMaxDepth limits how deep doctor inspects the data dir (installs/<tool>/<version>).
+	doctorMaxDepth = 3
+)
+
+// OwnershipIssue describes a data dir entry that other users sharing it cannot use.
+type OwnershipIssue struct {
+	// Path is the offending file or directory.
+	Path string
+	// Problem explains why the entry is not usable by the group.
+	Problem string
+}
+
+// statOwner returns the owning user and group ids of a file, ok is false when unsupported.
+var statOwner = fileOwner //nolint:gochecknoglobals // used for mocking
+
+// IsGroupShared reports whether dir has the setgid bit set, which marks it as
+// shared with its group.
+func IsGroupShared(dir string) bool {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+
+	return info.IsDir() && info.Mode()&os.ModeSetgid != 0
+}
+
+// SharedMode returns mode with the owner bits selected by mask mirrored to the group.
+func SharedMode(mode, mask os.FileMode) os.FileMode {
+	return mode | (mode&0o700)>>3&mask
+}
+
+// OpenSharedFile opens path like os.OpenFile. When the parent directory is group
+// shared the file is made group-readable and -writable, so that lock files can be
+// taken by every member of the group.
+func OpenSharedFile(path string, flag int, perm os.FileMode) (*os.File, error) {
+	file, err := os.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsGroupShared(filepath.Dir(path)) {
+		shareEntry(path, groupReadWriteExecute)
+	}
+
+	return file, nil
+}
+
+// EnsureSharedDir creates a directory like EnsureDir, sharing every directory
+// it creates below a group shared directory with the group (see shareDir).
+func EnsureSharedDir(path string) error {
+	var created []string
+
+	for dir := path; filepath.Dir(dir) != dir; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			break
+		}
+
+		created = append(created, dir)
+	}
+
+	if err := EnsureDir(path); err != nil {
+		return err
+	}
+
+	for _, dir := range slices.Backward(created) {
+		if IsGroupShared(filepath.Dir(dir)) {
+			shareDir(dir)
+		}
+	}
+
+	return nil
+}
+
+// ShareTree makes the directories of root, root included, group-writable and
+// setgid, and its files group-readable, and executable where the owner can
+// execute, when root's parent directory is group shared. Symlinks are left
+// untouched.
+func ShareTree(root string) error {
+	if !IsGroupShared(filepath.Dir(root)) {
+		return nil
+	}
+
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.IsDir():
+			shareDir(path)
+		case entry.Type()&os.ModeSymlink == 0:
+			shareEntry(path, groupReadExecute)
+		}
+
+		return nil
+	})
+}
+
+// shareEntry adds the group bits selected by mask to path. Entries owned by other
+// users cannot be changed; this is logged rather than failing the operation.
+func shareEntry(path string, mask os.FileMode) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return
+	}
+
+	mode := info.Mode().Perm()
+	if shared := SharedMode(mode, mask); shared != mode {
+		if err := os.Chmod(path, shared|info.Mode()&(os.ModeSetgid|os.ModeSetuid|os.ModeSticky)); err != nil {
+			Logger().Debug("cannot share file with group", "path", path, "error", err)
+		}
+	}
+}
+
+// shareDir makes the directory at path g+rwxs, so that every member of the
+// group can add and remove entries and those entries inherit the group.
+func shareDir(path string) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return
+	}
+
+	mode := info.Mode() & (os.ModePerm | os.ModeSetgid | os.ModeSetuid | os.ModeSticky)
+	if shared := SharedMode(mode, groupReadWriteExecute) | os.ModeSetgid; shared != mode {
+		if err := os.Chmod(path, shared); err != nil {
+			Logger().Debug("cannot share directory with group", "path", path, "error", err)
+		}
+	}
+}
+
+// CheckOwnership inspects the data dir and returns the entries that other users
+// cannot use. In a group shared data dir every entry must belong to the data dir
+// group and be group-readable, directories group-writable too; otherwise every entry must have the data dir owner.
+func CheckOwnership(dataDir string) ([]OwnershipIssue, error) {
+	info, err := os.Stat(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	rootUID, rootGID, ok := statOwner(info)
+	if !ok {
+		return nil, nil
+	}
+
+	shared := info.Mode()&os.ModeSetgid != 0
+
+	var issues []OwnershipIssue
+
+	err = filepath.WalkDir(dataDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == dataDir || entry.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dataDir, path)
+		if err != nil {
+			return err
+		}
+
+		if problem := ownershipProblem(path, rootUID, rootGID, shared); problem != "" {
+			issues = append(issues, OwnershipIssue{Path: path, Problem: problem})
+		}
+
+		if entry.IsDir() && strings.Count(rel, string(filepath.Separator)) >= doctorMaxDepth-1 {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+
+	return issues, err
+}
+
+// ownershipProblem returns why path is unusable by the data dir owner or group, or "".
+func ownershipProblem(path string, rootUID, rootGID uint32, shared bool) string {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err.Error()
+	}
+
+	uid, gid, ok := statOwner(info)
+	if !ok {
+		return ""
+	}
+
+	if !shared {
+		if uid != rootUID {
+			return fmt.Sprintf("owned by uid %d, data dir is owned by uid %d", uid, rootUID)
+		}
+
+		return ""
+	}
+
+	if gid != rootGID {
+		return fmt.Sprintf("group is gid %d, data dir group is gid %d", gid, rootGID)
+	}
+
+	required := os.FileMode(0o040)
+	if info.IsDir() {
+		required = 0o050
+	}
+
+	if info.Mode().Perm()&required != required {
+		return fmt.Sprintf("mode %s is not group-readable", info.Mode().Perm())
+	}
+
+	if info.IsDir() && info.Mode().Perm()&0o020 == 0 {
+		return fmt.Sprintf("mode %s is not group-writable", info.Mode().Perm())
+	}
+
+	if info.IsDir() && info.Mode()&os.ModeSetgid == 0 {
+		return "directory is missing the setgid bit"
+	}
+
+	return ""
+}
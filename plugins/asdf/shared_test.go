@@ -0,0 +1,241 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// makeSharedDir creates a setgid directory, as used for group shared data dirs.
+func makeSharedDir(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "shared")
+	require.NoError(t, os.Mkdir(dir, asdf.CommonDirectoryPermission))
+	require.NoError(t, os.Chmod(dir, os.ModeSetgid|0o770))
+
+	return dir
+}
+
+// fakeOwners reports ownership by file name, falling back to the given root ids.
+func fakeOwners(owners map[string][2]uint32) func(os.FileInfo) (uint32, uint32, bool) {
+	return func(info os.FileInfo) (uint32, uint32, bool) {
+		ids, ok := owners[info.Name()]
+		if !ok {
+			ids = owners[""]
+		}
+
+		return ids[0], ids[1], true
+	}
+}
+
+func TestSharedMode(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, os.FileMode(0o660), asdf.SharedMode(0o600, 0o070))
+	require.Equal(t, os.FileMode(0o750), asdf.SharedMode(0o700, 0o050))
+	require.Equal(t, os.FileMode(0o640), asdf.SharedMode(0o600, 0o050))
+	require.Equal(t, os.FileMode(0o755), asdf.SharedMode(0o755, 0o050))
+}
+
+func TestOpenSharedFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lock files are group-writable below setgid directories", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(makeSharedDir(t), ".tool-sums")
+
+		file, err := asdf.OpenSharedFile(path, os.O_RDWR|os.O_CREATE, asdf.CommonFilePermission)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+	})
+
+	t.Run("private directories keep the requested mode", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), ".tool-sums")
+
+		file, err := asdf.OpenSharedFile(path, os.O_RDWR|os.O_CREATE, asdf.CommonFilePermission)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, asdf.CommonFilePermission, info.Mode().Perm())
+	})
+}
+
+func TestShareTree(t *testing.T) {
+	t.Parallel()
+
+	t.Run("shares directories g+rwxs and files g+rX below setgid directories", func(t *testing.T) {
+		t.Parallel()
+
+		installPath := filepath.Join(makeSharedDir(t), "1.0.0")
+		writeTree(t, installPath, map[string]string{
+			"bin/tool":    "#!/bin/sh\n",
+			"share/notes": "notes",
+			"bin/link":    "->tool",
+		})
+		require.NoError(t, os.Chmod(filepath.Join(installPath, "bin", "tool"), 0o700))
+		require.NoError(t, os.Chmod(filepath.Join(installPath, "share", "notes"), 0o600))
+		require.NoError(t, os.Chmod(filepath.Join(installPath, "bin"), 0o700))
+
+		require.NoError(t, asdf.ShareTree(installPath))
+
+		for path, mode := range map[string]os.FileMode{
+			"":            os.ModeSetgid | 0o775,
+			"bin":         os.ModeSetgid | 0o770,
+			"bin/tool":    0o750,
+			"share/notes": 0o640,
+		} {
+			info, err := os.Stat(filepath.Join(installPath, path))
+			require.NoError(t, err)
+			require.Equal(t, mode, info.Mode()&(os.ModePerm|os.ModeSetgid), path)
+		}
+	})
+
+	t.Run("leaves private installs untouched", func(t *testing.T) {
+		t.Parallel()
+
+		installPath := filepath.Join(t.TempDir(), "1.0.0")
+		writeTree(t, installPath, map[string]string{"bin/tool": "#!/bin/sh\n"})
+		require.NoError(t, os.Chmod(filepath.Join(installPath, "bin", "tool"), 0o700))
+
+		require.NoError(t, asdf.ShareTree(installPath))
+
+		info, err := os.Stat(filepath.Join(installPath, "bin", "tool"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	})
+}
+
+func TestEnsureSharedDir(t *testing.T) {
+	t.Parallel()
+
+	t.Run("creates directories g+rwxs below setgid directories", func(t *testing.T) {
+		t.Parallel()
+
+		sharedDir := makeSharedDir(t)
+		require.NoError(t, asdf.EnsureSharedDir(filepath.Join(sharedDir, "locks", "nested")))
+
+		for _, path := range []string{"locks", "locks/nested"} {
+			info, err := os.Stat(filepath.Join(sharedDir, path))
+			require.NoError(t, err)
+			require.Equal(t, os.ModeSetgid|0o775, info.Mode()&(os.ModePerm|os.ModeSetgid), path)
+		}
+	})
+
+	t.Run("private directories keep the default mode", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "locks")
+		require.NoError(t, asdf.EnsureSharedDir(path))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Zero(t, info.Mode()&os.ModeSetgid)
+	})
+}
+
+func TestWriteChecksumSidecarShared(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(makeSharedDir(t), "tool.tar.gz")
+	require.NoError(t, os.WriteFile(path, []byte("archive"), asdf.CommonFilePermission))
+	require.NoError(t, asdf.WriteChecksumSidecar(path, "abc"))
+
+	info, err := os.Stat(path + asdf.ChecksumSidecarSuffix)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+}
+
+func TestCheckOwnership(t *testing.T) {
+	t.Run("shared data dir accepts other owners in the same group", func(t *testing.T) {
+		dataDir := makeSharedDir(t)
+		writeTree(t, dataDir, map[string]string{"installs/jq/1.7/bin/jq": "jq"})
+		require.NoError(t, asdf.ShareTree(filepath.Join(dataDir, "installs")))
+		require.NoError(t, os.Chmod(filepath.Join(dataDir, "installs"), os.ModeSetgid|0o770))
+
+		asdf.SetFileOwnerForTests(t, fakeOwners(map[string][2]uint32{
+			"":    {1000, 500},
+			"1.7": {1001, 500},
+		}))
+
+		issues, err := asdf.CheckOwnership(dataDir)
+		require.NoError(t, err)
+		require.Empty(t, issues)
+	})
+
+	t.Run("shared data dir reports foreign groups, private modes and read-only directories", func(t *testing.T) {
+		dataDir := makeSharedDir(t)
+		writeTree(t, dataDir, map[string]string{
+			"installs/jq/1.7/bin/jq": "jq",
+			"shims/jq":               "->../installs/jq/1.7/bin/jq",
+		})
+		require.NoError(t, os.Chmod(filepath.Join(dataDir, "installs"), os.ModeSetgid|0o750))
+		require.NoError(t, os.Chmod(filepath.Join(dataDir, "installs", "jq"), 0o700))
+		require.NoError(t, os.Chmod(filepath.Join(dataDir, "shims"), os.ModeSetgid|0o750))
+
+		asdf.SetFileOwnerForTests(t, fakeOwners(map[string][2]uint32{
+			"":    {1000, 500},
+			"1.7": {1001, 1001},
+		}))
+
+		issues, err := asdf.CheckOwnership(dataDir)
+		require.NoError(t, err)
+
+		problems := make(map[string]string, len(issues))
+		for _, issue := range issues {
+			rel, err := filepath.Rel(dataDir, issue.Path)
+			require.NoError(t, err)
+
+			problems[rel] = issue.Problem
+		}
+
+		require.Len(t, problems, 4)
+		require.Contains(t, problems["installs"], "not group-writable")
+		require.Contains(t, problems["shims"], "not group-writable")
+		require.Contains(t, problems[filepath.Join("installs", "jq")], "not group-readable")
+		require.Contains(t, problems[filepath.Join("installs", "jq", "1.7")], "gid 1001")
+	})
+
+	t.Run("private data dir reports entries of other users", func(t *testing.T) {
+		dataDir := t.TempDir()
+		writeTree(t, dataDir, map[string]string{"installs/jq/1.7/bin/jq": "jq"})
+
+		asdf.SetFileOwnerForTests(t, fakeOwners(map[string][2]uint32{
+			"":   {1000, 1000},
+			"jq": {0, 0},
+		}))
+
+		issues, err := asdf.CheckOwnership(dataDir)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		require.Equal(t, filepath.Join(dataDir, "installs", "jq"), issues[0].Path)
+		require.Contains(t, issues[0].Problem, "uid 0")
+	})
+}
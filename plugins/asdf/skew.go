@@ -0,0 +1,168 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultSkewProbeTimeout bounds the server version probe of a VersionSkew
+// without ProbeTimeout, so that an unreachable server cannot stall an install.
+const DefaultSkewProbeTimeout = 10 * time.Second
+
+// errServerVersionUnavailable is returned when the server version probe fails.
+var errServerVersionUnavailable = errors.New("server version unavailable")
+
+// VersionSkew warns when an installed client is further from the version of
+// the server it talks to than the supported skew, e.g. kubectl and the
+// Kubernetes API server, which support one minor version apart.
+type VersionSkew struct {
+	// ServerVersion extracts the server version from the probe output.
+	ServerVersion func(output []byte) (string, error)
+	// EnableEnv enables the check when set to 1.
+	EnableEnv string
+	// ConfigEnv must be set for a server to be configured, e.g. KUBECONFIG.
+	ConfigEnv string
+	// ConfigFile, relative to the home directory, configures a server when
+	// ConfigEnv is unset, e.g. .kube/config. ConfigEnv is required when empty.
+	ConfigFile string
+	// ProbeArgs make the client binary print the server version.
+	ProbeArgs []string
+	// TimeoutFlag, when set, passes the probe timeout to the client, e.g.
+	// --request-timeout for kubectl, which then gives up on the server itself.
+	TimeoutFlag string
+	// ProbeTimeout bounds the probe, DefaultSkewProbeTimeout when zero.
+	ProbeTimeout time.Duration
+	// MaxMinorSkew is the largest supported distance between minor versions.
+	MaxMinorSkew int
+}
+
+// Enabled reports whether EnableEnv is set to 1 and a server is configured,
+// by ConfigEnv or an existing ConfigFile.
+func (skew VersionSkew) Enabled() bool {
+	if os.Getenv(skew.EnableEnv) != "1" {
+		return false
+	}
+
+	if os.Getenv(skew.ConfigEnv) != "" {
+		return true
+	}
+
+	if skew.ConfigFile == "" {
+		return false
+	}
+
+	home, err := osUserHomeDir()
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(filepath.Join(home, skew.ConfigFile))
+
+	return err == nil
+}
+
+// Warn runs Check when the check is Enabled and prints its warning followed
+// by advice. The check is advisory: a server that cannot be reached is
+// reported as a skipped check and never fails the install.
+func (skew VersionSkew) Warn(ctx context.Context, tool, binaryPath, clientVersion, advice string) {
+	if !skew.Enabled() {
+		return
+	}
+
+	warning, err := skew.Check(ctx, binaryPath, clientVersion)
+	if err != nil {
+		Logger().Warn("skipping version skew check", "tool", tool, "error", err)
+
+		return
+	}
+
+	if warning != "" {
+		Logger().Warn(tool+" "+warning+", "+advice, "tool", tool)
+	}
+}
+
+// Check probes the server with the client at binaryPath and returns a warning
+// when clientVersion is more than MaxMinorSkew minor versions away from it,
+// or an empty string when the versions are within the supported skew. The
+// probe is killed after ProbeTimeout.
+func (skew VersionSkew) Check(ctx context.Context, binaryPath, clientVersion string) (string, error) {
+	timeout := skew.ProbeTimeout
+	if timeout <= 0 {
+		timeout = DefaultSkewProbeTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := skew.ProbeArgs
+	if skew.TimeoutFlag != "" {
+		args = append(slices.Clone(args), skew.TimeoutFlag+"="+timeout.String())
+	}
+
+	cmd := execCommandContext(ctx, binaryPath, args...)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errServerVersionUnavailable, err)
+	}
+
+	serverVersion, err := skew.ServerVersion(output)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errServerVersionUnavailable, err)
+	}
+
+	serverVersion = strings.TrimPrefix(serverVersion, "v")
+	if serverVersion == "" {
+		return "", fmt.Errorf("%w: not reported by %s", errServerVersionUnavailable, binaryPath)
+	}
+
+	distance, comparable := MinorVersionDistance(clientVersion, serverVersion)
+	if comparable && distance <= skew.MaxMinorSkew {
+		return "", nil
+	}
+
+	if skew.MaxMinorSkew == 0 {
+		return fmt.Sprintf("client version %s is a different minor version than server version %s",
+			clientVersion, serverVersion), nil
+	}
+
+	return fmt.Sprintf("client version %s is more than %d minor version(s) away from server version %s",
+		clientVersion, skew.MaxMinorSkew, serverVersion), nil
+}
+
+// MinorVersionDistance returns how many minor versions a and b are apart. It
+// reports false when the major versions differ or either version is unparsable.
+func MinorVersionDistance(a, b string) (int, bool) {
+	partsA, partsB := ParseVersionParts(a), ParseVersionParts(b)
+	if len(partsA) < 2 || len(partsB) < 2 || partsA[0] != partsB[0] {
+		return 0, false
+	}
+
+	distance := partsA[1] - partsB[1]
+	if distance < 0 {
+		distance = -distance
+	}
+
+	return distance, true
+}
@@ -0,0 +1,210 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// kubectlSkew checks kubectl against the server reported by 'kubectl version -o json'.
+func kubectlSkew() asdf.VersionSkew {
+	return asdf.VersionSkew{
+		EnableEnv:    "ASDF_KUBECTL_SKEW_CHECK",
+		ConfigEnv:    "KUBECONFIG",
+		ProbeArgs:    []string{"version", "-o", "json"},
+		MaxMinorSkew: 1,
+		ServerVersion: func(output []byte) (string, error) {
+			var version struct {
+				ServerVersion struct {
+					GitVersion string `json:"gitVersion"`
+				} `json:"serverVersion"`
+			}
+
+			err := json.Unmarshal(output, &version)
+
+			return version.ServerVersion.GitVersion, err
+		},
+	}
+}
+
+// TestVersionSkewEnabled verifies the check needs both the opt-in and a server configuration.
+func TestVersionSkewEnabled(t *testing.T) {
+	skew := kubectlSkew()
+
+	t.Setenv("ASDF_KUBECTL_SKEW_CHECK", "1")
+	t.Setenv("KUBECONFIG", "")
+	require.False(t, skew.Enabled())
+
+	t.Setenv("KUBECONFIG", "/tmp/kubeconfig")
+	require.True(t, skew.Enabled())
+
+	t.Setenv("ASDF_KUBECTL_SKEW_CHECK", "0")
+	require.False(t, skew.Enabled())
+}
+
+// TestVersionSkewEnabledConfigFile verifies a default configuration file in
+// the home directory enables the check without ConfigEnv.
+func TestVersionSkewEnabledConfigFile(t *testing.T) {
+	home := t.TempDir()
+	asdf.MockOSForTests(t, t.TempDir(), home)
+
+	skew := kubectlSkew()
+	skew.ConfigFile = filepath.Join(".kube", "config")
+
+	t.Setenv("ASDF_KUBECTL_SKEW_CHECK", "1")
+	t.Setenv("KUBECONFIG", "")
+	require.False(t, skew.Enabled())
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".kube"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".kube", "config"), []byte("apiVersion: v1\n"), 0o600))
+	require.True(t, skew.Enabled())
+}
+
+// TestVersionSkewCheck verifies warnings against simulated server versions.
+func TestVersionSkewCheck(t *testing.T) {
+	asdf.MockExecForTests(t, nil)
+
+	tests := []struct {
+		name    string
+		client  string
+		server  string
+		warning string
+	}{
+		{name: "same minor", client: "1.29.3", server: "v1.29.1"},
+		{name: "client one minor newer", client: "1.30.0", server: "v1.29.1"},
+		{name: "client one minor older", client: "1.28.4", server: "v1.29.1-gke.1589000"},
+		{
+			name:    "client two minors newer",
+			client:  "1.31.0",
+			server:  "v1.29.1",
+			warning: "client version 1.31.0 is more than 1 minor version(s) away from server version 1.29.1",
+		},
+		{
+			name:    "client two minors older",
+			client:  "1.27.0",
+			server:  "v1.29.1",
+			warning: "client version 1.27.0 is more than 1 minor version(s) away from server version 1.29.1",
+		},
+		{
+			name:    "different major",
+			client:  "2.0.0",
+			server:  "v1.29.1",
+			warning: "client version 2.0.0 is more than 1 minor version(s) away from server version 1.29.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ASDF_MOCK_COMMAND_STDOUT",
+				`{"clientVersion":{"gitVersion":"v`+tt.client+`"},"serverVersion":{"gitVersion":"`+tt.server+`"}}`)
+
+			warning, err := kubectlSkew().Check(t.Context(), "/bin/kubectl", tt.client)
+			require.NoError(t, err)
+			require.Equal(t, tt.warning, warning)
+		})
+	}
+}
+
+// TestVersionSkewCheckProbeFailure verifies unreachable servers are reported as errors.
+func TestVersionSkewCheckProbeFailure(t *testing.T) {
+	asdf.MockExecForTests(t, nil)
+
+	t.Setenv("ASDF_MOCK_COMMAND_STDERR", "The connection to the server was refused")
+
+	_, err := kubectlSkew().Check(t.Context(), "/bin/kubectl", "1.29.0")
+	require.ErrorIs(t, err, asdf.ErrServerVersionUnavailableForTests())
+
+	t.Setenv("ASDF_MOCK_COMMAND_STDERR", "")
+	t.Setenv("ASDF_MOCK_COMMAND_STDOUT", "not json")
+
+	_, err = kubectlSkew().Check(t.Context(), "/bin/kubectl", "1.29.0")
+	require.ErrorIs(t, err, asdf.ErrServerVersionUnavailableForTests())
+
+	t.Setenv("ASDF_MOCK_COMMAND_STDOUT", `{"clientVersion":{"gitVersion":"v1.29.0"}}`)
+
+	_, err = kubectlSkew().Check(t.Context(), "/bin/kubectl", "1.29.0")
+	require.ErrorIs(t, err, asdf.ErrServerVersionUnavailableForTests())
+}
+
+// TestVersionSkewCheckTimeout verifies the probe passes its timeout to the
+// client and is killed when the server does not answer in time.
+func TestVersionSkewCheckTimeout(t *testing.T) {
+	asdf.MockExecForTests(t, nil)
+
+	commandLog := filepath.Join(t.TempDir(), "commands.log")
+	t.Setenv("ASDF_MOCK_COMMAND_LOG", commandLog)
+	t.Setenv("ASDF_MOCK_COMMAND_STDOUT", `{"serverVersion":{"gitVersion":"v1.29.1"}}`)
+
+	skew := kubectlSkew()
+	skew.TimeoutFlag = "--request-timeout"
+
+	_, err := skew.Check(t.Context(), "/bin/kubectl", "1.29.0")
+	require.NoError(t, err)
+
+	commands, err := os.ReadFile(commandLog)
+	require.NoError(t, err)
+	require.Equal(t, "kubectl version -o json --request-timeout=10s\n", string(commands))
+
+	t.Setenv("ASDF_MOCK_COMMAND_SLEEP", "1m")
+
+	skew.ProbeTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	_, err = skew.Check(t.Context(), "/bin/kubectl", "1.29.0")
+	require.ErrorIs(t, err, asdf.ErrServerVersionUnavailableForTests())
+	require.Less(t, time.Since(start), 30*time.Second)
+}
+
+// TestVersionSkewCheckSameMinor verifies tools without minor version skew
+// support warn on any minor version difference.
+func TestVersionSkewCheckSameMinor(t *testing.T) {
+	asdf.MockExecForTests(t, nil)
+
+	skew := kubectlSkew()
+	skew.MaxMinorSkew = 0
+
+	t.Setenv("ASDF_MOCK_COMMAND_STDOUT", `{"serverVersion":{"gitVersion":"v2.14.3"}}`)
+
+	warning, err := skew.Check(t.Context(), "/bin/tool", "2.14.0")
+	require.NoError(t, err)
+	require.Empty(t, warning)
+
+	warning, err = skew.Check(t.Context(), "/bin/tool", "2.15.0")
+	require.NoError(t, err)
+	require.Equal(t, "client version 2.15.0 is a different minor version than server version 2.14.3", warning)
+}
+
+// TestVersionSkewWarnProbeFailure verifies an unreachable server never panics
+// or fails the caller.
+func TestVersionSkewWarnProbeFailure(t *testing.T) {
+	asdf.MockExecForTests(t, nil)
+
+	t.Setenv("ASDF_KUBECTL_SKEW_CHECK", "1")
+	t.Setenv("KUBECONFIG", "/tmp/kubeconfig")
+	t.Setenv("ASDF_MOCK_COMMAND_STDERR", "The connection to the server was refused")
+
+	require.NotPanics(t, func() {
+		kubectlSkew().Warn(t.Context(), "kubectl", "/bin/kubectl", "1.29.0", "upgrade the server")
+	})
+}
@@ -17,12 +17,17 @@ package asdf
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/sumicare/universal-asdf-plugin/plugins/github"
 )
@@ -42,20 +47,38 @@ var (
 	errSourceBuildNoBuildStep = errors.New("no build step configured")
 	// errSourceBuildUnsupportedArchiveType is returned when the archive type is not supported.
 	errSourceBuildUnsupportedArchiveType = errors.New("unsupported archive type")
+	// errSourceBuildCommandFailed is returned when a build command exits unsuccessfully.
+	errSourceBuildCommandFailed = errors.New("build command failed")
+	// errSourceBuildPatchChecksumMissing is returned when a downloaded patch has no checksum.
+	errSourceBuildPatchChecksumMissing = errors.New("patch URL requires a sha256 checksum")
+
+	// sourceCacheDir returns the persistent source cache directory for a build, or
+	// "" when sources must not be cached.
+	sourceCacheDir = defaultSourceCacheDir //nolint:gochecknoglobals // used for mocking
 )
 
+// sourceCacheMarker records, next to the extracted sources, the SHA256 of the
+// archive they were extracted from.
+const sourceCacheMarker = "src" + ChecksumSidecarSuffix
+
 type (
 	// SourceBuildPlugin implements a generic asdf.Plugin for tools built from source archives.
 	SourceBuildPlugin struct {
 		Github *github.Client
 		Config *SourceBuildPluginConfig
+		// ref is set when building a git ref, whose sources are never cached
+		// since branches move.
+		ref bool
 	}
 
 	// SourceBuildPluginConfig configures the SourceBuildPlugin.
 	SourceBuildPluginConfig struct {
-		MinArchiveSize           *int64
-		PostInstallVersion       func(ctx context.Context, version, installPath string) error
-		BuildVersion             func(ctx context.Context, version, sourceDir, installPath string) error
+		MinArchiveSize     *int64
+		PostInstallVersion func(ctx context.Context, version, installPath string) error
+		BuildVersion       func(ctx context.Context, version, sourceDir, installPath string) error
+		// BuildEnv is merged over the build environment of commands created with
+		// BuildCommand or RunBuildCommand.
+		BuildEnv                 map[string]string
 		PreBuildVersion          func(ctx context.Context, version, sourceDir string) error
 		SourceURLFunc            func(ctx context.Context, version string) (string, error)
 		DownloadFile             func(ctx context.Context, url, destPath string) error
@@ -73,10 +96,34 @@ type (
 		SourceURLTemplate        string
 		LegacyFilenames          []string
 		ExpectedArtifacts        []string
-		UseTags                  bool
-		SkipExtract              bool
-		SkipDownload             bool
-		AutoDetectExtractedDir   bool
+		// Patches are applied to the extracted sources before PreBuildVersion.
+		Patches []SourcePatch
+		// BuildEnvPassthrough names variables, or prefixes ending in "*", passed
+		// to build commands in addition to the default allowlist, see BuildExecer.
+		BuildEnvPassthrough []string
+		// NumJobs is the build parallelism, exposed to templates as {{.Jobs}},
+		// to build commands as MAKEFLAGS and MAKE_OPTS, and to BuildVersion
+		// through BuildConcurrencyFromContext. It defaults to BuildConcurrency
+		// and is capped at MaxConcurrencyEnv.
+		NumJobs                int
+		UseTags                bool
+		SkipExtract            bool
+		SkipDownload           bool
+		AutoDetectExtractedDir bool
+		// IncludePrereleases lists prereleases and lets LatestStable select them.
+		IncludePrereleases bool
+		// RefInstall lets InstallRef build git refs, branches, tags or commits,
+		// from the archive at SourceRefURLTemplate.
+		RefInstall bool
+		// SourceRefURLTemplate is the URL of the source archive of {{.Ref}}, by
+		// default the GitHub archive of the ref in RepoOwner/RepoName.
+		SourceRefURLTemplate string
+		// ExpansionFactor is the install size divided by the download size,
+		// DefaultExpansionFactor when zero (see ExpansionHinter).
+		ExpansionFactor float64
+		// NoSourceCache extracts the sources into the download directory
+		// instead of the persistent source cache of the data layout.
+		NoSourceCache bool
 	}
 )
 
@@ -134,20 +181,36 @@ func (plugin *SourceBuildPlugin) Name() string {
 	return plugin.Config.Name
 }
 
+// GitHubRepository returns the repository the sources are tagged in.
+func (plugin *SourceBuildPlugin) GitHubRepository() (owner, repo string) {
+	return plugin.Config.RepoOwner, plugin.Config.RepoName
+}
+
 // ListAll lists all available versions.
 func (plugin *SourceBuildPlugin) ListAll(ctx context.Context) ([]string, error) {
+	return plugin.listVersions(ctx, plugin.Config.IncludePrereleases)
+}
+
+// listVersions lists the published versions, with prereleases when includePrereleases is set.
+func (plugin *SourceBuildPlugin) listVersions(ctx context.Context, includePrereleases bool) ([]string, error) {
 	return ListGitHubVersions(ctx, plugin.Github, &ListGitHubVersionsConfig{
-		RepoOwner:     plugin.Config.RepoOwner,
-		RepoName:      plugin.Config.RepoName,
-		VersionPrefix: plugin.Config.VersionPrefix,
-		VersionFilter: plugin.Config.VersionFilter,
-		UseTags:       plugin.Config.UseTags,
+		RepoOwner:          plugin.Config.RepoOwner,
+		RepoName:           plugin.Config.RepoName,
+		VersionPrefix:      plugin.Config.VersionPrefix,
+		VersionFilter:      plugin.Config.VersionFilter,
+		UseTags:            plugin.Config.UseTags,
+		IncludePrereleases: includePrereleases,
 	})
 }
 
-// LatestStable returns the latest stable version matching the query.
+// LatestStable returns the latest stable version matching the query (see
+// VersionQuery). A query starting with PrereleaseQueryPrefix, or
+// IncludePrereleases, admits prereleases.
 func (plugin *SourceBuildPlugin) LatestStable(ctx context.Context, query string) (string, error) {
-	versions, err := plugin.ListAll(ctx)
+	_, prereleaseQuery := ParseLatestQuery(query)
+	includePrereleases := plugin.Config.IncludePrereleases || prereleaseQuery
+
+	versions, err := plugin.listVersions(ctx, includePrereleases)
 	if err != nil {
 		return "", err
 	}
@@ -156,7 +219,11 @@ func (plugin *SourceBuildPlugin) LatestStable(ctx context.Context, query string)
 		return "", errSourceBuildNoVersionsFound
 	}
 
-	latest := LatestVersion(versions, query)
+	latest, err := MatchLatestVersion(versions, query, includePrereleases)
+	if err != nil {
+		return "", err
+	}
+
 	if latest == "" {
 		return "", fmt.Errorf("%w: %s", errSourceBuildNoVersionsMatching, query)
 	}
@@ -181,6 +248,10 @@ func (plugin *SourceBuildPlugin) Install(
 		return errSourceBuildNoBuildStep
 	}
 
+	if ref, isRef := ParseRefVersion(version); isRef && !plugin.ref {
+		return plugin.InstallRef(ctx, ref, downloadPath, installPath)
+	}
+
 	err := EnsureDir(installPath)
 	if err != nil {
 		return fmt.Errorf("creating install directory: %w", err)
@@ -226,6 +297,29 @@ func (plugin *SourceBuildPlugin) Install(
 
 	cleanup := func() {}
 
+	cached := false
+	releaseCache := func() {}
+
+	if !plugin.Config.SkipDownload && !plugin.ref {
+		if cacheDir := sourceCacheDir(plugin.Config, version); cacheDir != "" {
+			// Download and extract into the persistent cache so that repeated
+			// installs of the same version reuse them. The lock keeps
+			// concurrent installs from extracting over each other until the
+			// sources are copied into a build directory of their own.
+			lock, err := AcquireLock(ctx, sourceCacheLockName(plugin.Config, version),
+				"caching "+plugin.Config.Name+" "+version+" sources", false)
+			if err != nil {
+				return err
+			}
+
+			releaseCache = sync.OnceFunc(func() { releaseLock(lock) })
+			defer releaseCache()
+
+			workDir = cacheDir
+			cached = true
+		}
+	}
+
 	if workDir == "" {
 		tmp, err := os.MkdirTemp("", "asdf-src-*")
 		if err != nil {
@@ -264,15 +358,50 @@ func (plugin *SourceBuildPlugin) Install(
 		}
 	}
 
-	if !plugin.Config.SkipExtract {
-		var err error
+	patches, err := plugin.resolvePatches(ctx, version, workDir)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case cached:
+		if !plugin.Config.SkipExtract {
+			sourceDir, err = plugin.extractCachedSource(version, workDir, archivePath)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Build in a copy, so that the cached sources stay pristine for the
+		// next install, whatever the patches and the build change.
+		buildDir, err := copySourceTree(sourceDir)
+		if err != nil {
+			return err
+		}
 
+		defer func() { _ = os.RemoveAll(filepath.Dir(buildDir)) }()
+
+		sourceDir = buildDir
+
+		releaseCache()
+
+	case !plugin.Config.SkipExtract:
 		sourceDir, err = plugin.extractSource(version, workDir, archivePath)
 		if err != nil {
 			return err
 		}
 	}
 
+	if err := applySourcePatches(sourceDir, patches); err != nil {
+		return err
+	}
+
+	jobs := sourceBuildJobs(plugin.Config)
+	execer := plugin.BuildExecer()
+	ctx = WithBuildExecer(WithBuildConcurrency(ctx, jobs), execer)
+
+	Logger().InfoContext(ctx, "build concurrency", "tool", plugin.Config.Name, "version", version, "jobs", jobs)
+
 	if plugin.Config.PreBuildVersion != nil {
 		err := plugin.Config.PreBuildVersion(ctx, version, sourceDir)
 		if err != nil {
@@ -280,6 +409,9 @@ func (plugin *SourceBuildPlugin) Install(
 		}
 	}
 
+	Logger().DebugContext(ctx, "building from source",
+		"tool", plugin.Config.Name, "version", version, "source", sourceDir, "install", installPath)
+
 	err = plugin.Config.BuildVersion(ctx, version, sourceDir, installPath)
 	if err != nil {
 		return err
@@ -303,7 +435,49 @@ func (plugin *SourceBuildPlugin) Install(
 		}
 	}
 
-	return nil
+	return WriteBuildEnv(installPath, execer)
+}
+
+// SupportsRefs reports whether RefInstall is set and the sources are
+// downloaded from an archive, whose archive of a ref InstallRef builds
+// instead. Plugins that download their sources themselves cannot install refs.
+func (plugin *SourceBuildPlugin) SupportsRefs() bool {
+	if !plugin.Config.RefInstall || plugin.Config.SkipDownload {
+		return false
+	}
+
+	return plugin.Config.SourceRefURLTemplate != "" || plugin.Config.RepoOwner != "" && plugin.Config.RepoName != ""
+}
+
+// InstallRef downloads the source archive of ref and builds it like a
+// version, without the patches, which target released versions.
+func (plugin *SourceBuildPlugin) InstallRef(ctx context.Context, ref, downloadPath, installPath string) error {
+	if !plugin.SupportsRefs() {
+		return fmt.Errorf("%w: %s is not built from a source archive", errRefInstallUnsupported, plugin.Config.Name)
+	}
+
+	cfg := *plugin.Config
+	cfg.SourceURLFunc = func(context.Context, string) (string, error) {
+		if cfg.SourceRefURLTemplate == "" {
+			return GitHubRefArchiveURL(cfg.RepoOwner, cfg.RepoName, ref), nil
+		}
+
+		return strings.ReplaceAll(renderSourceBuildTemplate(cfg.SourceRefURLTemplate, &cfg, ref), "{{.Ref}}", ref), nil
+	}
+	cfg.ArchiveType = "tar.gz"
+	cfg.ArchiveNameTemplate = "{{.RepoName}}-ref.tar.gz"
+	cfg.SkipExtract = false
+	cfg.AutoDetectExtractedDir = true
+	cfg.Patches = nil
+
+	refBuild := &SourceBuildPlugin{Github: plugin.Github, Config: &cfg, ref: true}
+
+	return refBuild.Install(ctx, ref, downloadPath, installPath)
+}
+
+// ExpansionFactor returns the configured ExpansionFactor.
+func (plugin *SourceBuildPlugin) ExpansionFactor() float64 {
+	return plugin.Config.ExpansionFactor
 }
 
 // ListBinPaths returns the relative paths to directories containing binaries.
@@ -328,7 +502,7 @@ func (plugin *SourceBuildPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy version file and returns the version.
 func (*SourceBuildPlugin) ParseLegacyFile(path string) (string, error) {
-	return ReadLegacyVersionFile(path)
+	return ReadVersionFile(path)
 }
 
 // Help returns help information for the plugin.
@@ -336,14 +510,95 @@ func (plugin *SourceBuildPlugin) Help() PluginHelp {
 	return plugin.Config.Help
 }
 
-// downloadSource downloads the source archive if it doesn't exist or is too small.
+// BuildExecer returns the BuildExecer applying BuildEnv and BuildEnvPassthrough.
+func (plugin *SourceBuildPlugin) BuildExecer() BuildExecer {
+	execer := BuildExecer{Passthrough: plugin.Config.BuildEnvPassthrough}
+
+	return execer.WithEnv(BuildConcurrencyEnv(sourceBuildJobs(plugin.Config))).WithEnv(plugin.Config.BuildEnv)
+}
+
+// sourceBuildJobs returns the build parallelism of cfg, NumJobs capped at
+// MaxConcurrencyEnv, or BuildConcurrency when NumJobs is unset.
+func sourceBuildJobs(cfg *SourceBuildPluginConfig) int {
+	if cfg.NumJobs > 0 {
+		return capConcurrency(cfg.NumJobs)
+	}
+
+	return BuildConcurrency()
+}
+
+// RunBuildCommand runs name with args in dir in the build environment. The
+// output is streamed to the logger at debug level and its last lines are
+// included in the error when the command fails.
+func (plugin *SourceBuildPlugin) RunBuildCommand(ctx context.Context, dir, name string, args ...string) error {
+	cmd := plugin.BuildExecer().Command(ctx, name, args...)
+	cmd.Dir = dir
+
+	output := &logLineWriter{ctx: ctx, command: filepath.Base(name)}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	Logger().DebugContext(ctx, "running build command", "command", name, "args", args, "dir", dir)
+
+	err := cmd.Run()
+
+	output.Flush()
+
+	if err != nil {
+		return fmt.Errorf("%w: %s %s: %w\n%s",
+			errSourceBuildCommandFailed, filepath.Base(name), strings.Join(args, " "), err, output.Tail())
+	}
+
+	return nil
+}
+
+// defaultSourceCacheDir returns the source cache directory for version below
+// the data layout cache, or "" when the config opts out of caching.
+func defaultSourceCacheDir(cfg *SourceBuildPluginConfig, version string) string {
+	if cfg.NoSourceCache {
+		return ""
+	}
+
+	layout, err := CurrentLayout()
+	if err != nil {
+		return ""
+	}
+
+	return layout.SourceCacheDir(sourceCacheKey(cfg), strings.ReplaceAll(version, string(os.PathSeparator), "_"))
+}
+
+// sourceCacheKey returns the directory below the source cache holding the
+// versions of cfg: "<owner>-<repo>", or the plugin name.
+func sourceCacheKey(cfg *SourceBuildPluginConfig) string {
+	key := cfg.Name
+	if cfg.RepoOwner != "" && cfg.RepoName != "" {
+		key = cfg.RepoOwner + "-" + cfg.RepoName
+	}
+
+	return strings.ReplaceAll(key, string(os.PathSeparator), "_")
+}
+
+// sourceCacheLockName returns the name of the lock guarding the cached sources of version.
+func sourceCacheLockName(cfg *SourceBuildPluginConfig, version string) string {
+	return "source-" + sourceCacheKey(cfg) + "-" + version
+}
+
+// downloadSource downloads the source archive unless it exists, is large enough
+// and matches the checksum recorded when it was downloaded.
 func (plugin *SourceBuildPlugin) downloadSource(
 	ctx context.Context,
 	version, workDir, archivePath string,
 	minSize int64,
 ) error {
 	if info, err := os.Stat(archivePath); err == nil && info.Size() > minSize {
-		return nil
+		err := VerifyChecksumSidecar(archivePath)
+		if err == nil || errors.Is(err, os.ErrNotExist) {
+			Logger().DebugContext(ctx, "using cached source archive", "path", archivePath)
+
+			return nil
+		}
+
+		Logger().DebugContext(ctx, "cached source archive changed", "path", archivePath, "error", err)
 	}
 
 	var srcURL string
@@ -360,6 +615,8 @@ func (plugin *SourceBuildPlugin) downloadSource(
 	}
 
 	Msgf("Downloading %s %s source from %s", plugin.Config.Name, version, srcURL)
+	Logger().DebugContext(ctx, "resolved source URL",
+		"tool", plugin.Config.Name, "version", version, "url", srcURL)
 
 	downloadDest := archivePath
 
@@ -388,6 +645,155 @@ func (plugin *SourceBuildPlugin) downloadSource(
 		}
 	}
 
+	sha, err := FileSHA256(archivePath)
+	if err != nil {
+		return err
+	}
+
+	return WriteChecksumSidecar(archivePath, sha)
+}
+
+// extractCachedSource returns the unpatched sources of archivePath extracted
+// below workDir, extracting them only when the previous extraction came from
+// a different archive.
+func (plugin *SourceBuildPlugin) extractCachedSource(version, workDir, archivePath string) (string, error) {
+	sha, err := FileSHA256(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errSourceBuildArchiveMissing, archivePath)
+	}
+
+	markerPath := filepath.Join(workDir, sourceCacheMarker)
+
+	if marker, err := os.ReadFile(markerPath); err == nil && strings.TrimSpace(string(marker)) == sha {
+		if sourceDir, err := plugin.locateExtractedDir(version, filepath.Join(workDir, "src")); err == nil {
+			Logger().Debug("using cached extracted sources", "path", sourceDir)
+
+			return sourceDir, nil
+		}
+	}
+
+	_ = os.Remove(markerPath)
+
+	sourceDir, err := plugin.extractSource(version, workDir, archivePath)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(markerPath, []byte(sha+"\n"), CommonFilePermission); err != nil {
+		return "", fmt.Errorf("recording extracted sources: %w", err)
+	}
+
+	return sourceDir, nil
+}
+
+// copySourceTree copies sourceDir into a new temporary directory and returns
+// the path of the copy, named like sourceDir.
+func copySourceTree(sourceDir string) (string, error) {
+	tmp, err := os.MkdirTemp("", "asdf-build-*")
+	if err != nil {
+		return "", fmt.Errorf("creating build directory: %w", err)
+	}
+
+	buildDir := filepath.Join(tmp, filepath.Base(sourceDir))
+	if err := CopyDir(sourceDir, buildDir); err != nil {
+		_ = os.RemoveAll(tmp)
+
+		return "", fmt.Errorf("copying cached sources: %w", err)
+	}
+
+	return buildDir, nil
+}
+
+// resolvedPatch is a SourcePatch selected for a version, with its content.
+type resolvedPatch struct {
+	name    string
+	content string
+	sha256  string
+	strip   int
+}
+
+// resolvePatches returns the patches applying to version, downloading URL
+// patches into workDir and verifying their checksums.
+func (plugin *SourceBuildPlugin) resolvePatches(
+	ctx context.Context,
+	version, workDir string,
+) ([]resolvedPatch, error) {
+	var resolved []resolvedPatch
+
+	for _, patch := range plugin.Config.Patches {
+		if patch.AppliesTo != nil && !patch.AppliesTo(version) {
+			continue
+		}
+
+		result := resolvedPatch{name: patch.Name, content: patch.Content, strip: 1}
+		if patch.Strip != nil {
+			result.strip = *patch.Strip
+		}
+
+		if patch.URL != "" {
+			if result.name == "" {
+				result.name = path.Base(patch.URL)
+			}
+
+			if patch.SHA256 == "" {
+				return nil, fmt.Errorf("%w: %s", errSourceBuildPatchChecksumMissing, result.name)
+			}
+
+			content, err := plugin.downloadPatch(ctx, patch.URL, filepath.Join(workDir, "patches", result.name))
+			if err != nil {
+				return nil, fmt.Errorf("downloading patch %s: %w", result.name, err)
+			}
+
+			result.content = content
+		}
+
+		sum := sha256.Sum256([]byte(result.content))
+		result.sha256 = hex.EncodeToString(sum[:])
+
+		if patch.SHA256 != "" && !strings.EqualFold(patch.SHA256, result.sha256) {
+			return nil, fmt.Errorf("%w: patch %s: expected %s, got %s",
+				errChecksumMismatchGeneric, result.name, patch.SHA256, result.sha256)
+		}
+
+		resolved = append(resolved, result)
+	}
+
+	return resolved, nil
+}
+
+// downloadPatch downloads the patch at patchURL to dest and returns its content.
+func (plugin *SourceBuildPlugin) downloadPatch(ctx context.Context, patchURL, dest string) (string, error) {
+	if err := EnsureDir(filepath.Dir(dest)); err != nil {
+		return "", err
+	}
+
+	downloadFunc := plugin.Config.DownloadFile
+	if downloadFunc == nil {
+		downloadFunc = DownloadFile
+	}
+
+	if err := downloadFunc(ctx, patchURL, dest); err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(dest)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// applySourcePatches applies patches to sourceDir in order.
+func applySourcePatches(sourceDir string, patches []resolvedPatch) error {
+	for _, patch := range patches {
+		Logger().Debug("applying patch", "patch", patch.name, "source", sourceDir)
+
+		if err := ApplyPatch(sourceDir, patch.name, patch.content, patch.strip); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -435,6 +841,11 @@ func (plugin *SourceBuildPlugin) extractSource(
 		)
 	}
 
+	return plugin.locateExtractedDir(version, srcRoot)
+}
+
+// locateExtractedDir returns the directory below srcRoot holding the extracted sources.
+func (plugin *SourceBuildPlugin) locateExtractedDir(version, srcRoot string) (string, error) {
 	extractedDir := renderSourceBuildTemplate(
 		plugin.Config.ExtractedDirNameTemplate,
 		plugin.Config,
@@ -448,12 +859,27 @@ func (plugin *SourceBuildPlugin) extractSource(
 			return "", err
 		}
 
+		// GitHub names the top directory of archives "<repo>-<ref or sha>".
+		detected := ""
+
 		for _, entry := range entries {
-			if entry.IsDir() {
-				candidate = filepath.Join(srcRoot, entry.Name())
+			if !entry.IsDir() {
+				continue
+			}
+
+			if strings.HasPrefix(entry.Name(), plugin.Config.RepoName+"-") {
+				detected = entry.Name()
 
 				break
 			}
+
+			if detected == "" {
+				detected = entry.Name()
+			}
+		}
+
+		if detected != "" {
+			candidate = filepath.Join(srcRoot, detected)
 		}
 	}
 
@@ -478,5 +904,7 @@ func renderSourceBuildTemplate(
 	out = strings.ReplaceAll(out, "{{.Version}}", version)
 	out = strings.ReplaceAll(out, "{{.VersionPrefix}}", cfg.VersionPrefix)
 
+	out = strings.ReplaceAll(out, "{{.Jobs}}", strconv.Itoa(sourceBuildJobs(cfg)))
+
 	return out
 }
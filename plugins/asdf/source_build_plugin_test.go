@@ -17,12 +17,18 @@ package asdf_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -35,6 +41,7 @@ var (
 	errTestDownloadFailed    = errors.New("download failed")
 	errTestPreBuildFailed    = errors.New("pre-build failed")
 	errTestPostInstallFailed = errors.New("post-install failed")
+	errTestBuildNotPristine  = errors.New("building over a previous build")
 )
 
 // TestSourceBuildPluginListAllUsesReleases verifies ListAll lists stable versions from releases.
@@ -249,6 +256,515 @@ func TestRenderSourceBuildTemplate(t *testing.T) {
 	require.Equal(t, "owner/repo/plugin-v1.2.3", result)
 }
 
+// TestRenderSourceBuildTemplateJobs verifies {{.Jobs}} renders NumJobs, its cap and its default.
+func TestRenderSourceBuildTemplateJobs(t *testing.T) {
+	t.Setenv(asdf.ConcurrencyEnv, "")
+	t.Setenv(asdf.MaxConcurrencyEnv, "")
+
+	result := asdf.RenderSourceBuildTemplateForTests("make -j{{.Jobs}}", &asdf.SourceBuildPluginConfig{NumJobs: 3}, "1.0.0")
+	require.Equal(t, "make -j3", result)
+
+	result = asdf.RenderSourceBuildTemplateForTests("make -j{{.Jobs}}", &asdf.SourceBuildPluginConfig{}, "1.0.0")
+	require.Equal(t, "make -j"+strconv.Itoa(runtime.NumCPU()), result)
+
+	t.Setenv(asdf.ConcurrencyEnv, "6")
+	t.Setenv(asdf.MaxConcurrencyEnv, "2")
+
+	result = asdf.RenderSourceBuildTemplateForTests("make -j{{.Jobs}}", &asdf.SourceBuildPluginConfig{NumJobs: 3}, "1.0.0")
+	require.Equal(t, "make -j2", result)
+
+	result = asdf.RenderSourceBuildTemplateForTests("make -j{{.Jobs}}", &asdf.SourceBuildPluginConfig{}, "1.0.0")
+	require.Equal(t, "make -j2", result)
+}
+
+// TestSourceBuildPluginInstallConcurrency verifies the build parallelism
+// reaches BuildVersion and its build commands.
+func TestSourceBuildPluginInstallConcurrency(t *testing.T) {
+	t.Setenv(asdf.ConcurrencyEnv, "5")
+	t.Setenv(asdf.MaxConcurrencyEnv, "")
+	t.Setenv("MAKE_OPTS", "")
+
+	logFile := filepath.Join(t.TempDir(), "make.log")
+	t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+	t.Setenv("ASDF_MOCK_COMMAND_LOG_ENV", "MAKEFLAGS MAKE_OPTS")
+
+	asdf.MockExecForTests(t, nil)
+
+	var jobs int
+
+	plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		Name:         "tool",
+		SkipDownload: true,
+		SkipExtract:  true,
+		BuildVersion: func(ctx context.Context, _, _, installPath string) error {
+			jobs = asdf.BuildConcurrencyFromContext(ctx)
+
+			if err := asdf.BuildCommand(ctx, "make").Run(); err != nil {
+				return err
+			}
+
+			return os.MkdirAll(filepath.Join(installPath, "bin"), asdf.CommonDirectoryPermission)
+		},
+	})
+
+	require.NoError(t, plugin.Install(t.Context(), "1.0.0", "", t.TempDir()))
+	require.Equal(t, 5, jobs)
+
+	logged, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	require.Equal(t, "make\nMAKEFLAGS=-j5\nMAKE_OPTS=-j 5\n", string(logged))
+}
+
+// TestSourceBuildPluginSourceCache verifies repeated installs reuse cached sources.
+func TestSourceBuildPluginSourceCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	asdf.SetSourceCacheDirForTests(t, cacheDir)
+
+	downloads := 0
+	minSize := int64(0)
+
+	plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		RepoName:          "repo",
+		SourceURLTemplate: "https://example.invalid/{{.Version}}",
+		MinArchiveSize:    &minSize,
+		DownloadFile: func(_ context.Context, _, dest string) error {
+			downloads++
+
+			createTestTarGz(t, dest, "repo-1.0.0/configure", "#!/bin/sh\n")
+
+			return nil
+		},
+		BuildVersion: func(_ context.Context, _, sourceDir, installPath string) error {
+			require.FileExists(t, filepath.Join(sourceDir, "configure"))
+
+			return os.WriteFile(filepath.Join(installPath, "built"), []byte("ok"), 0o600)
+		},
+	})
+
+	for range 2 {
+		installPath := t.TempDir()
+		require.NoError(t, plugin.Install(t.Context(), "1.0.0", "", installPath))
+		require.FileExists(t, filepath.Join(installPath, "built"))
+	}
+
+	require.Equal(t, 1, downloads)
+	require.FileExists(t, filepath.Join(cacheDir, "1.0.0", "repo-1.0.0.tar.gz.sha256"))
+
+	archivePath := filepath.Join(cacheDir, "1.0.0", "repo-1.0.0.tar.gz")
+	require.NoError(t, os.WriteFile(archivePath, []byte("corrupted"), 0o600))
+
+	require.NoError(t, plugin.Install(t.Context(), "1.0.0", "", t.TempDir()))
+	require.Equal(t, 2, downloads)
+}
+
+// TestSourceBuildPluginSourceCachePristine verifies every install builds in a
+// fresh copy of the cached sources: build outputs and patches never reach the
+// cache, and a changed patch applies without downloading again.
+func TestSourceBuildPluginSourceCachePristine(t *testing.T) {
+	cacheDir := t.TempDir()
+	asdf.SetSourceCacheDirForTests(t, cacheDir)
+
+	var downloads atomic.Int32
+
+	minSize := int64(0)
+
+	newPlugin := func(from, to string) *asdf.SourceBuildPlugin {
+		return asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			RepoName:          "repo",
+			SourceURLTemplate: "https://example.invalid/{{.Version}}",
+			MinArchiveSize:    &minSize,
+			Patches: []asdf.SourcePatch{{
+				Name:    "openssl.patch",
+				Content: "--- a/configure\n+++ b/configure\n@@ -1 +1 @@\n-OPENSSL=" + from + "\n+OPENSSL=" + to + "\n",
+			}},
+			DownloadFile: func(_ context.Context, _, dest string) error {
+				downloads.Add(1)
+
+				createTestTarGz(t, dest, "repo-1.0.0/configure", "OPENSSL=1.1\n")
+
+				return nil
+			},
+			BuildVersion: func(_ context.Context, _, sourceDir, installPath string) error {
+				if _, err := os.Stat(filepath.Join(sourceDir, "configure.o")); err == nil {
+					return errTestBuildNotPristine
+				}
+
+				if err := os.WriteFile(filepath.Join(sourceDir, "configure.o"), nil, 0o600); err != nil {
+					return err
+				}
+
+				return asdf.CopyFile(filepath.Join(sourceDir, "configure"), filepath.Join(installPath, "configure"), 0o600)
+			},
+		})
+	}
+
+	cachedConfigure := filepath.Join(cacheDir, "1.0.0", "src", "repo-1.0.0", "configure")
+
+	for _, tt := range []struct {
+		plugin   *asdf.SourceBuildPlugin
+		expected string
+	}{
+		{plugin: newPlugin("1.1", "3"), expected: "OPENSSL=3\n"},
+		{plugin: newPlugin("1.1", "3"), expected: "OPENSSL=3\n"},
+		{plugin: newPlugin("1.1", "3.5"), expected: "OPENSSL=3.5\n"},
+	} {
+		installPath := t.TempDir()
+		require.NoError(t, tt.plugin.Install(t.Context(), "1.0.0", "", installPath))
+
+		installed, err := os.ReadFile(filepath.Join(installPath, "configure"))
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, string(installed))
+
+		cached, err := os.ReadFile(cachedConfigure)
+		require.NoError(t, err)
+		require.Equal(t, "OPENSSL=1.1\n", string(cached))
+		require.NoFileExists(t, filepath.Join(filepath.Dir(cachedConfigure), "configure.o"))
+	}
+
+	require.Equal(t, int32(1), downloads.Load())
+}
+
+// TestSourceBuildPluginSourceCacheSkipExtract verifies patches of plugins
+// extracting the sources themselves apply to a copy of the cache.
+func TestSourceBuildPluginSourceCacheSkipExtract(t *testing.T) {
+	cacheDir := t.TempDir()
+	asdf.SetSourceCacheDirForTests(t, cacheDir)
+
+	minSize := int64(0)
+
+	plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		RepoName:          "repo",
+		SourceURLTemplate: "https://example.invalid/{{.Version}}",
+		MinArchiveSize:    &minSize,
+		SkipExtract:       true,
+		Patches: []asdf.SourcePatch{{
+			Name:    "readme.patch",
+			Content: "--- /dev/null\n+++ b/README\n@@ -0,0 +1 @@\n+patched\n",
+		}},
+		DownloadFile: func(_ context.Context, _, dest string) error {
+			return os.WriteFile(dest, []byte("archive"), 0o600)
+		},
+		BuildVersion: func(_ context.Context, _, sourceDir, installPath string) error {
+			return asdf.CopyFile(filepath.Join(sourceDir, "README"), filepath.Join(installPath, "README"), 0o600)
+		},
+	})
+
+	for range 2 {
+		installPath := t.TempDir()
+		require.NoError(t, plugin.Install(t.Context(), "1.0.0", "", installPath))
+		require.FileExists(t, filepath.Join(installPath, "README"))
+		require.NoFileExists(t, filepath.Join(cacheDir, "1.0.0", "README"))
+	}
+}
+
+// TestSourceBuildPluginSourceCacheConcurrent verifies concurrent installs of
+// a version share one download of the cached sources.
+func TestSourceBuildPluginSourceCacheConcurrent(t *testing.T) {
+	asdf.SetSourceCacheDirForTests(t, t.TempDir())
+
+	var downloads atomic.Int32
+
+	minSize := int64(0)
+
+	plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		RepoName:          "repo",
+		SourceURLTemplate: "https://example.invalid/{{.Version}}",
+		MinArchiveSize:    &minSize,
+		DownloadFile: func(_ context.Context, _, dest string) error {
+			downloads.Add(1)
+
+			createTestTarGz(t, dest, "repo-1.0.0/configure", "#!/bin/sh\n")
+
+			return nil
+		},
+		BuildVersion: func(_ context.Context, _, sourceDir, installPath string) error {
+			return asdf.CopyFile(filepath.Join(sourceDir, "configure"), filepath.Join(installPath, "configure"), 0o600)
+		},
+	})
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, 4)
+	for i := range errs {
+		installPath := t.TempDir()
+
+		wg.Go(func() {
+			errs[i] = plugin.Install(t.Context(), "1.0.0", "", installPath)
+		})
+	}
+
+	wg.Wait()
+	require.NoError(t, errors.Join(errs...))
+	require.Equal(t, int32(1), downloads.Load())
+}
+
+// TestSourceBuildPluginInstallRef verifies refs are built from the GitHub
+// archive of the ref, bypassing the source cache and the patches.
+func TestSourceBuildPluginInstallRef(t *testing.T) {
+	cacheDir := t.TempDir()
+	asdf.SetSourceCacheDirForTests(t, cacheDir)
+
+	minSize := int64(0)
+
+	var (
+		downloadedURL string
+		builtVersion  string
+	)
+
+	plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		Name:           "tool",
+		RepoOwner:      "owner",
+		RepoName:       "repo",
+		RefInstall:     true,
+		MinArchiveSize: &minSize,
+		Patches:        []asdf.SourcePatch{{Name: "broken.patch", Content: "not a patch"}},
+		DownloadFile: func(_ context.Context, url, dest string) error {
+			downloadedURL = url
+
+			createTestTarGz(t, dest, "repo-main/configure", "#!/bin/sh\n")
+
+			return nil
+		},
+		BuildVersion: func(_ context.Context, version, sourceDir, installPath string) error {
+			builtVersion = version
+
+			require.FileExists(t, filepath.Join(sourceDir, "configure"))
+
+			return os.WriteFile(filepath.Join(installPath, "built"), []byte("ok"), 0o600)
+		},
+	})
+
+	require.True(t, asdf.HasCapability(plugin, asdf.CapabilityRefInstall))
+
+	installPath := t.TempDir()
+	require.NoError(t, plugin.InstallRef(t.Context(), "refs/heads/main", t.TempDir(), installPath))
+	require.FileExists(t, filepath.Join(installPath, "built"))
+	require.Equal(t, "https://github.com/owner/repo/archive/refs/heads/main.tar.gz", downloadedURL)
+	require.Equal(t, "refs/heads/main", builtVersion)
+
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	for name, config := range map[string]asdf.SourceBuildPluginConfig{
+		"unsupported without RefInstall": {Name: "tool", RepoOwner: "owner", RepoName: "repo"},
+		"unsupported without a source archive": {
+			Name: "tool", RepoOwner: "owner", RepoName: "repo", RefInstall: true, SkipDownload: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			plugin := asdf.NewSourceBuildPlugin(&config)
+
+			require.False(t, asdf.HasCapability(plugin, asdf.CapabilityRefInstall))
+
+			err := plugin.InstallRef(t.Context(), "main", t.TempDir(), t.TempDir())
+			require.ErrorIs(t, err, asdf.ErrRefInstallUnsupportedForTests())
+		})
+	}
+}
+
+// TestSourceBuildPluginInstallRefVersion verifies "ref:" versions are built
+// from the codeload-style archive of the ref, whose "<repo>-<sha>" directory
+// is detected, and never listed among the versions.
+func TestSourceBuildPluginInstallRefVersion(t *testing.T) {
+	asdf.SetSourceCacheDirForTests(t, t.TempDir())
+
+	srv := githubmock.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddTags("owner", "repo", []string{"v1.0.0", "v1.1.0"})
+
+	archivePath := filepath.Join(t.TempDir(), "archive.tar.gz")
+	createTestTarGz(t, archivePath, "repo-9fceb02d0ae598e95dc970b74767f19372d61af8/configure", "#!/bin/sh\n")
+
+	archive, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+
+	srv.AddRefArchive("owner", "repo", "9fceb02", archive)
+
+	minSize := int64(0)
+
+	var builtVersion string
+
+	plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		Name:                 "tool",
+		RepoOwner:            "owner",
+		RepoName:             "repo",
+		UseTags:              true,
+		RefInstall:           true,
+		SourceRefURLTemplate: srv.RefArchiveURLTemplate(),
+		MinArchiveSize:       &minSize,
+		BuildVersion: func(_ context.Context, version, sourceDir, installPath string) error {
+			builtVersion = version
+
+			require.Equal(t, "repo-9fceb02d0ae598e95dc970b74767f19372d61af8", filepath.Base(sourceDir))
+
+			return os.WriteFile(filepath.Join(installPath, "built"), []byte("ok"), 0o600)
+		},
+	})
+	plugin.WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+
+	installPath := filepath.Join(t.TempDir(), asdf.RefVersionDir("9fceb02"))
+	require.NoError(t, plugin.Install(t.Context(), "ref:9fceb02", t.TempDir(), installPath))
+	require.FileExists(t, filepath.Join(installPath, "built"))
+	require.Equal(t, "9fceb02", builtVersion)
+
+	versions, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.0.0", "1.1.0"}, versions)
+
+	latest, err := plugin.LatestStable(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, "1.1.0", latest)
+
+	require.NoError(t, plugin.Uninstall(t.Context(), installPath))
+	require.NoDirExists(t, installPath)
+
+	err = plugin.Install(t.Context(), "ref:missing", t.TempDir(), t.TempDir())
+	require.ErrorContains(t, err, "404")
+}
+
+// TestSourceBuildPluginPatches verifies patches are selected per version and
+// applied before PreBuildVersion.
+func TestSourceBuildPluginPatches(t *testing.T) {
+	t.Parallel()
+
+	minSize := int64(0)
+	patch := "--- a/configure\n+++ b/configure\n@@ -1 +1 @@\n-OPENSSL=1.1\n+OPENSSL=3\n"
+
+	newPlugin := func(patches ...asdf.SourcePatch) *asdf.SourceBuildPlugin {
+		return asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:     true,
+			RepoName:          "repo",
+			SourceURLTemplate: "https://example.invalid/{{.Version}}.tar.gz",
+			MinArchiveSize:    &minSize,
+			Patches:           patches,
+			DownloadFile: func(_ context.Context, url, dest string) error {
+				if strings.HasSuffix(url, ".patch") {
+					return os.WriteFile(dest, []byte(patch), 0o600)
+				}
+
+				version := strings.TrimSuffix(filepath.Base(url), ".tar.gz")
+				createTestTarGz(t, dest, "repo-"+version+"/configure", "OPENSSL=1.1\n")
+
+				return nil
+			},
+			PreBuildVersion: func(_ context.Context, _, sourceDir string) error {
+				content, err := os.ReadFile(filepath.Join(sourceDir, "configure"))
+				if err != nil {
+					return err
+				}
+
+				return os.WriteFile(filepath.Join(sourceDir, "seen"), content, 0o600)
+			},
+			BuildVersion: func(_ context.Context, _, sourceDir, installPath string) error {
+				content, err := os.ReadFile(filepath.Join(sourceDir, "seen"))
+				if err != nil {
+					return err
+				}
+
+				return os.WriteFile(filepath.Join(installPath, "configure"), content, 0o600)
+			},
+		})
+	}
+
+	installed := func(t *testing.T, plugin *asdf.SourceBuildPlugin, version string) string {
+		t.Helper()
+
+		installPath := t.TempDir()
+		require.NoError(t, plugin.Install(t.Context(), version, t.TempDir(), installPath))
+
+		content, err := os.ReadFile(filepath.Join(installPath, "configure"))
+		require.NoError(t, err)
+
+		return string(content)
+	}
+
+	t.Run("applies embedded patches to matching versions", func(t *testing.T) {
+		t.Parallel()
+
+		plugin := newPlugin(asdf.SourcePatch{
+			Name:      "openssl3.patch",
+			Content:   patch,
+			AppliesTo: asdf.ForVersionsBefore("3.8"),
+		})
+
+		require.Equal(t, "OPENSSL=3\n", installed(t, plugin, "3.7.0"))
+		require.Equal(t, "OPENSSL=1.1\n", installed(t, plugin, "3.8.0"))
+	})
+
+	t.Run("downloads and verifies URL patches", func(t *testing.T) {
+		t.Parallel()
+
+		sum := sha256.Sum256([]byte(patch))
+		plugin := newPlugin(asdf.SourcePatch{
+			URL:    "https://example.invalid/openssl3.patch",
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+
+		require.Equal(t, "OPENSSL=3\n", installed(t, plugin, "3.7.0"))
+	})
+
+	t.Run("rejects URL patches with a wrong or missing checksum", func(t *testing.T) {
+		t.Parallel()
+
+		for _, checksum := range []string{"", "deadbeef"} {
+			plugin := newPlugin(asdf.SourcePatch{URL: "https://example.invalid/openssl3.patch", SHA256: checksum})
+
+			err := plugin.Install(t.Context(), "3.7.0", t.TempDir(), t.TempDir())
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "openssl3.patch")
+		}
+	})
+
+	t.Run("reports patches that do not apply", func(t *testing.T) {
+		t.Parallel()
+
+		plugin := newPlugin(asdf.SourcePatch{
+			Name:    "broken.patch",
+			Content: "--- a/configure\n+++ b/configure\n@@ -1 +1 @@\n-OPENSSL=0.9\n+OPENSSL=3\n",
+		})
+
+		err := plugin.Install(t.Context(), "3.7.0", t.TempDir(), t.TempDir())
+		require.ErrorIs(t, err, asdf.ErrPatchHunkFailedForTests())
+		require.Contains(t, err.Error(), "broken.patch: hunk #1 of configure")
+	})
+}
+
+// TestSourceBuildPluginRunBuildCommand verifies build commands get BuildEnv and report output.
+func TestSourceBuildPluginRunBuildCommand(t *testing.T) {
+	plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+		BuildEnv: map[string]string{"CFLAGS": "-O2"},
+	})
+
+	t.Run("applies BuildEnv", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "make.log")
+		t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+		t.Setenv("ASDF_MOCK_COMMAND_LOG_ENV", "CFLAGS")
+
+		asdf.MockExecForTests(t, nil)
+
+		require.NoError(t, plugin.RunBuildCommand(t.Context(), t.TempDir(), "make", "-j2"))
+
+		logged, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		require.Equal(t, "make -j2\nCFLAGS=-O2\n", string(logged))
+	})
+
+	t.Run("includes output on failure", func(t *testing.T) {
+		t.Setenv("ASDF_MOCK_COMMAND_STDERR", "compiler exploded")
+
+		asdf.MockExecForTests(t, nil)
+
+		err := plugin.RunBuildCommand(t.Context(), t.TempDir(), "make", "install")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "make install")
+		require.Contains(t, err.Error(), "compiler exploded")
+	})
+}
+
 func TestSourceBuildPluginInstall(t *testing.T) {
 	t.Parallel()
 
@@ -293,6 +809,7 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 
 		buildCalled := false
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:     true,
 			Name:              "tool",
 			RepoName:          "repo",
 			ArchiveType:       "tar.gz",
@@ -343,6 +860,7 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 
 		minSize := int64(0)
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:     true,
 			RepoName:          "repo",
 			ArchiveType:       "tar.gz",
 			SourceURLTemplate: server.URL + "/should-not-call",
@@ -388,8 +906,9 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 		defer server.Close()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
-			RepoName:    "repo",
-			ArchiveType: "tar.gz",
+			NoSourceCache: true,
+			RepoName:      "repo",
+			ArchiveType:   "tar.gz",
 			SourceURLFunc: func(_ context.Context, version string) (string, error) {
 				return server.URL + "/custom/" + version + ".tar.gz", nil
 			},
@@ -424,6 +943,7 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 		defer server.Close()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:       true,
 			RepoName:            "repo",
 			ArchiveType:         "zip",
 			ArchiveNameTemplate: "repo-{{.Version}}.zip",
@@ -463,6 +983,7 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 		defer server.Close()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:          true,
 			RepoName:               "repo",
 			ArchiveType:            "tar.gz",
 			AutoDetectExtractedDir: true,
@@ -507,6 +1028,7 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 		defer server.Close()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:     true,
 			RepoName:          "repo",
 			SourceURLTemplate: server.URL,
 			BuildVersion:      func(_ context.Context, _, _, _ string) error { return nil },
@@ -532,6 +1054,7 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 		require.NoError(t, os.Chmod(filepath.Join(binDir, "tool"), 0o755))
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache: true,
 			BuildVersion: func(_ context.Context, _, _, _ string) error {
 				return os.ErrExist // Should not be called
 			},
@@ -546,7 +1069,8 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 		t.Parallel()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
-			RepoName: "repo",
+			NoSourceCache: true,
+			RepoName:      "repo",
 			DownloadFile: func(_ context.Context, _, _ string) error {
 				return errTestDownloadFailed
 			},
@@ -587,6 +1111,7 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 		defer server.Close()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:     true,
 			RepoName:          "repo",
 			SourceURLTemplate: server.URL,
 			BuildVersion:      func(_ context.Context, _, _, _ string) error { return nil },
@@ -619,6 +1144,7 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 		defer server.Close()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:     true,
 			RepoName:          "repo",
 			SourceURLTemplate: server.URL,
 			PreBuildVersion: func(_ context.Context, _, _ string) error {
@@ -654,6 +1180,7 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 		defer server.Close()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:     true,
 			RepoName:          "repo",
 			SourceURLTemplate: server.URL,
 			BuildVersion:      func(_ context.Context, _, _, _ string) error { return nil },
@@ -687,6 +1214,7 @@ func TestSourceBuildPluginInstall(t *testing.T) {
 		defer server.Close()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:     true,
 			RepoName:          "repo",
 			SourceURLTemplate: server.URL,
 			BuildVersion:      func(_ context.Context, _, _, _ string) error { return nil },
@@ -721,6 +1249,35 @@ func TestSourceBuildPluginLatestStable(t *testing.T) {
 		require.Equal(t, "1.1.0", version)
 	})
 
+	t.Run("selects prereleases when configured or queried", func(t *testing.T) {
+		t.Parallel()
+
+		srv := githubmock.NewServer()
+		defer srv.Close()
+
+		srv.AddReleases("o", "r", []string{"v3.12.4", "v3.13.0b1"})
+
+		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			RepoOwner: "o",
+			RepoName:  "r",
+		})
+		plugin.WithGithubClient(github.NewClientWithHTTP(srv.HTTPServer.Client(), srv.URL()))
+
+		version, err := plugin.LatestStable(t.Context(), "")
+		require.NoError(t, err)
+		require.Equal(t, "3.12.4", version)
+
+		version, err = plugin.LatestStable(t.Context(), asdf.PrereleaseQueryPrefix+"3.13")
+		require.NoError(t, err)
+		require.Equal(t, "3.13.0b1", version)
+
+		plugin.Config.IncludePrereleases = true
+
+		version, err = plugin.LatestStable(t.Context(), "")
+		require.NoError(t, err)
+		require.Equal(t, "3.13.0b1", version)
+	})
+
 	t.Run("returns error when no versions found", func(t *testing.T) {
 		t.Parallel()
 
@@ -765,7 +1322,8 @@ func TestSourceBuildPluginExtractSource(t *testing.T) {
 		t.Parallel()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
-			ArchiveType: "rar", // Unsupported
+			NoSourceCache: true,
+			ArchiveType:   "rar", // Unsupported
 		})
 
 		// Create dummy archive
@@ -827,6 +1385,7 @@ func TestSourceBuildPluginExtractSource(t *testing.T) {
 		defer server.Close()
 
 		plugin := asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
+			NoSourceCache:            true,
 			RepoName:                 "repo",
 			ExtractedDirNameTemplate: "repo-{{.Version}}", // Expects repo-1.0.0
 			SourceURLTemplate:        server.URL,
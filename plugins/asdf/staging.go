@@ -0,0 +1,144 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Infixes of the directories an install leaves next to its install path:
+// the staging directory it installs into, and the previous install moved
+// aside while the staging directory takes its place. Both are followed by
+// the random suffix of the staging directory.
+const (
+	installStagingInfix = ".staging-"
+	installTrashInfix   = ".trash-"
+)
+
+// InstallsInPlace reports whether plugin installs into the final install
+// path instead of a staging directory, see InPlaceInstaller.
+func InstallsInPlace(plugin Plugin) bool {
+	installer, ok := plugin.(InPlaceInstaller)
+
+	return ok && installer.InstallsInPlace()
+}
+
+// StageInstall creates an empty staging directory next to installPath, on
+// the same filesystem so that CommitInstall can rename it into place.
+func StageInstall(installPath string) (string, error) {
+	parent := filepath.Dir(installPath)
+	if err := EnsureDir(parent); err != nil {
+		return "", fmt.Errorf("creating %s: %w", parent, err)
+	}
+
+	staging, err := os.MkdirTemp(parent, filepath.Base(installPath)+installStagingInfix+"*")
+	if err != nil {
+		return "", fmt.Errorf("creating staging directory: %w", err)
+	}
+
+	if err := os.Chmod(staging, CommonDirectoryPermission); err != nil {
+		_ = os.RemoveAll(staging)
+
+		return "", fmt.Errorf("creating staging directory: %w", err)
+	}
+
+	return staging, nil
+}
+
+// CommitInstall replaces installPath by staging, created by StageInstall.
+// A previous install is first renamed aside, restored when staging cannot
+// take its place and removed once it did, so that installPath holds either
+// the previous or the new install at any time.
+func CommitInstall(staging, installPath string) error {
+	_, suffix, _ := strings.Cut(filepath.Base(staging), installStagingInfix)
+	trash := installPath + installTrashInfix + suffix
+
+	err := os.Rename(installPath, trash)
+	switch {
+	case os.IsNotExist(err):
+		trash = ""
+	case err != nil:
+		return fmt.Errorf("moving aside the previous install: %w", err)
+	}
+
+	if err := os.Rename(staging, installPath); err != nil {
+		if trash != "" {
+			_ = os.Rename(trash, installPath)
+		}
+
+		return fmt.Errorf("moving the install into place: %w", err)
+	}
+
+	if trash != "" {
+		if err := os.RemoveAll(trash); err != nil {
+			Logger().Warn("failed to remove the previous install", "path", trash, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// RemoveInstallLeftovers removes the staging and previous install
+// directories of installPath that an interrupted install left behind.
+// Callers hold the install lock of the version.
+func RemoveInstallLeftovers(installPath string) error {
+	entries, err := os.ReadDir(filepath.Dir(installPath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", filepath.Dir(installPath), err)
+	}
+
+	base := filepath.Base(installPath)
+
+	for _, entry := range entries {
+		if version, ok := installLeftoverVersion(entry.Name()); !ok || version != base {
+			continue
+		}
+
+		path := filepath.Join(filepath.Dir(installPath), entry.Name())
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("removing %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// IsInstallLeftover reports whether name, an entry of the installs of a
+// tool, is a staging or previous install directory rather than a version.
+func IsInstallLeftover(name string) bool {
+	_, ok := installLeftoverVersion(name)
+
+	return ok
+}
+
+// installLeftoverVersion returns the version whose install left the
+// directory name behind, and whether name is such a leftover.
+func installLeftoverVersion(name string) (string, bool) {
+	for _, infix := range []string{installStagingInfix, installTrashInfix} {
+		if index := strings.LastIndex(name, infix); index > 0 {
+			return name[:index], true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,88 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestCommitInstall verifies a staged install replaces the previous one and
+// leaves nothing else next to the install path.
+func TestCommitInstall(t *testing.T) {
+	t.Parallel()
+
+	for _, previous := range []bool{false, true} {
+		installPath := filepath.Join(t.TempDir(), "tool", "1.0.0")
+
+		if previous {
+			writeTree(t, installPath, map[string]string{"bin/old": "old"})
+		}
+
+		staging, err := asdf.StageInstall(installPath)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Dir(installPath), filepath.Dir(staging))
+		require.True(t, asdf.IsInstallLeftover(filepath.Base(staging)))
+
+		writeTree(t, staging, map[string]string{"bin/tool": "new"})
+		require.NoError(t, asdf.CommitInstall(staging, installPath))
+
+		require.FileExists(t, filepath.Join(installPath, "bin", "tool"))
+		require.NoFileExists(t, filepath.Join(installPath, "bin", "old"))
+
+		entries, err := os.ReadDir(filepath.Dir(installPath))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	}
+}
+
+// TestRemoveInstallLeftovers verifies only the leftovers of the version are
+// removed, and that installed versions do not list them.
+func TestRemoveInstallLeftovers(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, data)
+	t.Setenv(asdf.InstallsDirEnv, "")
+
+	writeTree(t, data, map[string]string{
+		"installs/tool/1.0/bin/tool":               "1.0",
+		"installs/tool/1.0.staging-123/bin/tool":   "partial",
+		"installs/tool/1.0.trash-123/bin/tool":     "previous",
+		"installs/tool/1.0.1.staging-456/bin/tool": "other version",
+	})
+
+	installed, err := asdf.ListInstalled("tool", "", nil)
+	require.NoError(t, err)
+	require.Len(t, installed, 1)
+	require.Equal(t, "1.0", installed[0].Version)
+
+	require.NoError(t, asdf.RemoveInstallLeftovers(filepath.Join(data, "installs", "tool", "1.0")))
+
+	entries, err := os.ReadDir(filepath.Join(data, "installs", "tool"))
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	require.Equal(t, []string{"1.0", "1.0.1.staging-456"}, names)
+	require.NoError(t, asdf.RemoveInstallLeftovers(filepath.Join(data, "installs", "missing", "1.0")))
+}
@@ -0,0 +1,61 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// errSystemExecutableNotFound is returned when a "system" pin has no executable on PATH.
+var errSystemExecutableNotFound = errors.New("system executable not found on PATH")
+
+// FindSystemExecutable returns the host executable name from PATH for tools
+// pinned to SystemVersion. The shims directory is skipped so that the lookup
+// never resolves to one of our own shims.
+func FindSystemExecutable(name string) (string, error) {
+	shimsDir := ""
+	if layout, err := CurrentLayout(); err == nil {
+		shimsDir = filepath.Clean(layout.ShimsDir)
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || filepath.Clean(dir) == shimsDir {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+			continue
+		}
+
+		return path, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", errSystemExecutableNotFound, name)
+}
+
+// WithoutSystemVersion returns versions without the SystemVersion keyword,
+// which selects the host tool and is never a release.
+func WithoutSystemVersion(versions []string) []string {
+	return FilterVersions(versions, func(version string) bool {
+		return version != SystemVersion
+	})
+}
@@ -0,0 +1,63 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestFindSystemExecutable verifies system lookups skip the shims directory and non executables.
+func TestFindSystemExecutable(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+
+	shimsDir := filepath.Join(dataDir, "shims")
+	plainDir := t.TempDir()
+	hostDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(shimsDir, asdf.CommonDirectoryPermission))
+	require.NoError(t, os.WriteFile(filepath.Join(shimsDir, "jq"), nil, asdf.CommonExecutablePermission))
+	require.NoError(t, os.WriteFile(filepath.Join(plainDir, "jq"), nil, asdf.CommonFilePermission))
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, "jq"), nil, asdf.CommonExecutablePermission))
+
+	t.Setenv("PATH", shimsDir+string(os.PathListSeparator)+plainDir+string(os.PathListSeparator)+hostDir)
+
+	path, err := asdf.FindSystemExecutable("jq")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(hostDir, "jq"), path)
+
+	_, err = asdf.FindSystemExecutable("yq")
+	require.ErrorIs(t, err, asdf.ErrSystemExecutableNotFoundForTests())
+}
+
+// TestSystemVersionIsNeverARelease verifies "system" never comes back as a listed or latest version.
+func TestSystemVersionIsNeverARelease(t *testing.T) {
+	t.Parallel()
+
+	versions := []string{"1.0.0", asdf.SystemVersion, "1.1.0"}
+	require.Equal(t, []string{"1.0.0", "1.1.0"}, asdf.WithoutSystemVersion(versions))
+	require.Equal(t, []string{"1.0.0", asdf.SystemVersion, "1.1.0"}, versions)
+
+	latest, err := asdf.MatchLatestVersion([]string{asdf.SystemVersion}, "", true)
+	require.NoError(t, err)
+	require.Empty(t, latest)
+}
@@ -21,9 +21,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 var testGlobalsMu sync.Mutex //nolint:gochecknoglobals // test-only global to serialize mutation of package-level vars
@@ -84,9 +86,50 @@ func TestHelperProcess(_ *testing.T) {
 
 	// Handle mock asdf
 	if filepath.Base(cmd) != "asdf" {
+		if logFile := os.Getenv("ASDF_MOCK_COMMAND_LOG"); logFile != "" {
+			entry := strings.Join(append([]string{filepath.Base(cmd)}, args...), " ") + "\n"
+			for _, key := range strings.Fields(os.Getenv("ASDF_MOCK_COMMAND_LOG_ENV")) {
+				entry += key + "=" + os.Getenv(key) + "\n"
+			}
+
+			if file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, CommonFilePermission); err == nil {
+				_, _ = file.WriteString(entry)
+				_ = file.Close()
+			}
+		}
+
+		if sleep, err := time.ParseDuration(os.Getenv("ASDF_MOCK_COMMAND_SLEEP")); err == nil {
+			time.Sleep(sleep)
+		}
+
+		if failArg := os.Getenv("ASDF_MOCK_COMMAND_FAIL_ARG"); failArg != "" && slices.Contains(args, failArg) {
+			fmt.Fprintln(os.Stderr, "mock failure for "+failArg)
+			os.Exit(1) //nolint:revive // we're fine
+		}
+
+		if stderr := os.Getenv("ASDF_MOCK_COMMAND_STDERR"); stderr != "" {
+			fmt.Fprintln(os.Stderr, stderr)
+			os.Exit(1) //nolint:revive // we're fine
+		}
+
+		fmt.Fprint(os.Stdout, os.Getenv("ASDF_MOCK_COMMAND_STDOUT"))
+
 		os.Exit(0) //nolint:revive // we're fine
 	}
 
+	// Simulate a nested plugin invocation: record the install marker it inherited
+	// and apply the same recursion guard the real CLI would.
+	if len(args) >= 2 && args[0] == "install" {
+		if markerFile := os.Getenv("ASDF_MOCK_MARKER_FILE"); markerFile != "" {
+			_ = os.WriteFile(markerFile, []byte(os.Getenv(InstallGuardEnv)), CommonFilePermission)
+		}
+
+		if err := CheckInstallRecursion(args[1]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(3) //nolint:revive // we're fine
+		}
+	}
+
 	if len(args) < 2 || args[0] != "latest" {
 		os.Exit(0) //nolint:revive // we're fine
 	}
@@ -105,6 +148,14 @@ func TestHelperProcess(_ *testing.T) {
 	os.Exit(0) //nolint:revive // we're fine
 }
 
+// MockExec mocks the commands run by RunCommand for the tests of other
+// packages, which must call TestHelperProcess from their own TestHelperProcess.
+// The mocked commands are appended to ASDF_MOCK_COMMAND_LOG when set.
+func MockExec(t *testing.T) {
+	t.Helper()
+	mockExec(t, nil)
+}
+
 // mockExec mocks exec.CommandContext to run TestHelperProcess.
 func mockExec(t *testing.T, lookPath func(string) (string, error)) {
 	t.Helper()
@@ -112,12 +163,22 @@ func mockExec(t *testing.T, lookPath func(string) (string, error)) {
 
 	origLookPath := execLookPath
 	origCommandContext := execCommandContext
+	origBuildEnvAllowlist := buildEnvAllowlist
+	origToolchains := toolchains
 
 	t.Cleanup(func() {
 		execLookPath = origLookPath
 		execCommandContext = origCommandContext
+		buildEnvAllowlist = origBuildEnvAllowlist
+		toolchains = origToolchains
 	})
 
+	// Toolchains ensured by earlier tests must be bootstrapped again with the mocks.
+	toolchains = NewToolchainManager(asdfInstallToolchain)
+
+	// The helper process marker must survive the build environment policy.
+	buildEnvAllowlist = append(slices.Clone(buildEnvAllowlist), "GO_TEST_HELPER_PROCESS")
+
 	if lookPath != nil {
 		execLookPath = lookPath
 	} else {
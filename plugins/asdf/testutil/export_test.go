@@ -0,0 +1,52 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import "testing"
+
+func ErrGoldenNotFoundForTests() error {
+	return errGoldenNotFound
+}
+
+func ErrGoldenSchemaUnsupportedForTests() error {
+	return errGoldenSchemaUnsupported
+}
+
+func ErrGoldenLatestMissingForTests() error {
+	return errGoldenLatestMissing
+}
+
+func ErrGoldenTooManyMissingForTests() error {
+	return errGoldenTooManyMissing
+}
+
+func ErrGoldenLatestStableRegressedForTests() error {
+	return errGoldenLatestStableRegressed
+}
+
+func ErrGoldenSourceChangedForTests() error {
+	return errGoldenSourceChanged
+}
+
+func ErrRecordingUnsupportedForTests() error {
+	return errRecordingUnsupported
+}
+
+func RecordFixturesForTests(t *testing.T, upstream, dir string, configs []BinaryPluginTestConfig) {
+	t.Helper()
+
+	recordFixtures(t, upstream, dir, configs)
+}
@@ -0,0 +1,315 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+const (
+	// GoldenSchemaVersion is the version of the golden document format written by WriteGolden.
+	GoldenSchemaVersion = 1
+	// GoldenSuffix is the file name suffix of golden documents.
+	GoldenSuffix = ".golden"
+	// GoldenSourceReleases marks versions recorded from GitHub releases.
+	GoldenSourceReleases = "releases"
+	// GoldenSourceTags marks versions recorded from git tags.
+	GoldenSourceTags = "tags"
+	// GoldenSourceCustom marks versions recorded from a plugin specific source.
+	GoldenSourceCustom = "custom"
+	// legacyListAllSuffix is the suffix of the plain-text version list snapshots.
+	legacyListAllSuffix = "_list_all.golden"
+	// legacyLatestStableSuffix is the suffix of the plain-text latest stable snapshots.
+	legacyLatestStableSuffix = "_latest_stable.golden"
+	// defaultRequiredLatest is the number of newest recorded versions that must be listed by default.
+	defaultRequiredLatest = 5
+)
+
+var (
+	// update regenerates the golden files of every test binary importing testutil.
+	update = flag.Bool("update", false, "update golden files") //nolint:gochecknoglobals // test flag
+
+	// errGoldenNotFound is returned when neither a golden document nor legacy snapshots exist.
+	errGoldenNotFound = errors.New("golden file not found, run the test with -update")
+	// errGoldenSchemaUnsupported is returned for documents written by a newer format version.
+	errGoldenSchemaUnsupported = errors.New("unsupported golden schema version")
+	// errGoldenLatestMissing is returned when one of the newest recorded versions is no longer listed.
+	errGoldenLatestMissing = errors.New("newest recorded versions are missing")
+	// errGoldenTooManyMissing is returned when more recorded versions disappeared than tolerated.
+	errGoldenTooManyMissing = errors.New("too many recorded versions are missing")
+	// errGoldenLatestStableRegressed is returned when the latest stable version moved backwards.
+	errGoldenLatestStableRegressed = errors.New("latest stable version is older than recorded")
+	// errGoldenSourceChanged is returned when versions now come from a different source.
+	errGoldenSourceChanged = errors.New("version source changed")
+)
+
+type (
+	// Golden is a snapshot of the versions a plugin listed when it was recorded.
+	Golden struct {
+		// RecordedAt is when the snapshot was taken.
+		RecordedAt time.Time `json:"recorded_at"`
+		// Source is where the versions came from, one of the GoldenSource constants.
+		Source string `json:"source,omitempty"`
+		// LatestStable is the latest stable version at recording time.
+		LatestStable string `json:"latest_stable,omitempty"`
+		// Versions is the version list in the order the plugin returned it, oldest first.
+		Versions []string `json:"versions"`
+		// Schema is the document format version, zero for legacy plain-text snapshots.
+		Schema int `json:"schema"`
+	}
+
+	// Tolerance controls how far a live version list may diverge from a recording.
+	// Upstream projects occasionally delete tags and plugins start filtering
+	// versions, so only the newest recorded versions are required by default.
+	Tolerance struct {
+		// MaxMissingFraction is the fraction of the older recorded versions, those
+		// outside of RequiredLatest, that may be missing from the live list.
+		MaxMissingFraction float64
+		// RequiredLatest is the number of newest recorded versions that must all be listed.
+		RequiredLatest int
+	}
+)
+
+// DefaultTolerance requires the newest recorded versions and ignores older ones.
+func DefaultTolerance() Tolerance {
+	return Tolerance{MaxMissingFraction: 1, RequiredLatest: defaultRequiredLatest}
+}
+
+// NewGolden returns a snapshot of versions recorded now.
+func NewGolden(source string, versions []string, latestStable string) *Golden {
+	return &Golden{
+		RecordedAt:   time.Now().UTC().Truncate(time.Second),
+		Source:       source,
+		LatestStable: latestStable,
+		Versions:     slices.Clone(versions),
+		Schema:       GoldenSchemaVersion,
+	}
+}
+
+// Updating reports whether golden files are being regenerated with -update.
+func Updating() bool {
+	return *update
+}
+
+// GoldenPath returns the path of the golden document for name in dir.
+func GoldenPath(dir, name string) string {
+	return filepath.Join(dir, name+GoldenSuffix)
+}
+
+// ReadGolden reads the golden document for name in dir. Without one it falls
+// back to the legacy name_list_all.golden and name_latest_stable.golden files.
+func ReadGolden(dir, name string) (*Golden, error) {
+	data, err := os.ReadFile(GoldenPath(dir, name))
+	if err == nil {
+		return DecodeGolden(data)
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading golden file: %w", err)
+	}
+
+	return readLegacyGolden(dir, name)
+}
+
+// readLegacyGolden combines the plain-text snapshots written before the golden
+// documents carried their own metadata.
+func readLegacyGolden(dir, name string) (*Golden, error) {
+	listAll, listErr := os.ReadFile(filepath.Join(dir, name+legacyListAllSuffix))
+	latestStable, latestErr := os.ReadFile(filepath.Join(dir, name+legacyLatestStableSuffix))
+
+	for _, err := range []error{listErr, latestErr} {
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("reading golden file: %w", err)
+		}
+	}
+
+	if listErr != nil && latestErr != nil {
+		return nil, fmt.Errorf("%w: %s", errGoldenNotFound, GoldenPath(dir, name))
+	}
+
+	return &Golden{
+		Versions:     splitGoldenLines(listAll),
+		LatestStable: strings.TrimSpace(string(latestStable)),
+	}, nil
+}
+
+// DecodeGolden parses a golden document, or a legacy plain-text snapshot with
+// one version per line.
+func DecodeGolden(data []byte) (*Golden, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return &Golden{Versions: splitGoldenLines(data)}, nil
+	}
+
+	var golden Golden
+	if err := json.Unmarshal(data, &golden); err != nil {
+		return nil, fmt.Errorf("decoding golden file: %w", err)
+	}
+
+	if golden.Schema > GoldenSchemaVersion {
+		return nil, fmt.Errorf("%w: %d", errGoldenSchemaUnsupported, golden.Schema)
+	}
+
+	return &golden, nil
+}
+
+// splitGoldenLines returns the non-empty trimmed lines of a plain-text snapshot.
+func splitGoldenLines(data []byte) []string {
+	lines := make([]string, 0)
+
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// WriteGolden writes golden as the golden document for name in dir and removes
+// the legacy plain-text snapshots it replaces.
+func WriteGolden(dir, name string, golden *Golden) error {
+	data, err := json.MarshalIndent(golden, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding golden file: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, asdf.CommonDirectoryPermission); err != nil {
+		return fmt.Errorf("creating golden directory: %w", err)
+	}
+
+	if err := os.WriteFile(GoldenPath(dir, name), append(data, '\n'), asdf.CommonFilePermission); err != nil {
+		return fmt.Errorf("writing golden file: %w", err)
+	}
+
+	for _, suffix := range []string{legacyListAllSuffix, legacyLatestStableSuffix} {
+		if err := os.Remove(filepath.Join(dir, name+suffix)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing legacy golden file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Check compares a live snapshot against the recording. Versions listed now
+// but not recorded are always accepted, recorded versions may only be missing
+// within tolerance, and the latest stable version may only move forward.
+func (golden *Golden) Check(live *Golden, tolerance Tolerance) error {
+	var errs []error
+
+	if golden.Source != "" && live.Source != "" && golden.Source != live.Source {
+		errs = append(errs, fmt.Errorf("%w: recorded from %s, now %s", errGoldenSourceChanged, golden.Source, live.Source))
+	}
+
+	required := max(len(golden.Versions)-max(tolerance.RequiredLatest, 0), 0)
+
+	if missing := missingVersions(golden.Versions[required:], live.Versions); len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("%w: %s", errGoldenLatestMissing, strings.Join(missing, ", ")))
+	}
+
+	older := golden.Versions[:required]
+	if missing := missingVersions(older, live.Versions); len(missing) > 0 &&
+		float64(len(missing)) > tolerance.MaxMissingFraction*float64(len(older)) {
+		errs = append(errs, fmt.Errorf("%w: %d of %d older versions: %s",
+			errGoldenTooManyMissing, len(missing), len(older), strings.Join(missing, ", ")))
+	}
+
+	if golden.LatestStable != "" && live.LatestStable != "" &&
+		asdf.CompareVersions(live.LatestStable, golden.LatestStable) < 0 {
+		errs = append(errs, fmt.Errorf("%w: %s < %s", errGoldenLatestStableRegressed, live.LatestStable, golden.LatestStable))
+	}
+
+	return errors.Join(errs...)
+}
+
+// missingVersions returns the recorded versions that are not listed.
+func missingVersions(recorded, listed []string) []string {
+	var missing []string
+
+	for _, version := range recorded {
+		if !slices.Contains(listed, version) {
+			missing = append(missing, version)
+		}
+	}
+
+	return missing
+}
+
+// AssertGolden checks live against the golden document for name in dir, or
+// records it when the tests run with -update.
+func AssertGolden(t testing.TB, dir, name string, live *Golden, tolerance Tolerance) {
+	t.Helper()
+
+	if Updating() {
+		require.NoError(t, WriteGolden(dir, name, live))
+
+		return
+	}
+
+	recorded, err := ReadGolden(dir, name)
+	require.NoError(t, err)
+	require.NoError(t, recorded.Check(live, tolerance), "golden file %s", GoldenPath(dir, name))
+}
+
+// GoldieFilesExist reports whether name has a golden document or legacy snapshots in dir.
+func GoldieFilesExist(dir, name string) bool {
+	_, err := ReadGolden(dir, name)
+
+	return err == nil
+}
+
+// GoldieVersions returns the versions recorded for name in dir, oldest first.
+func GoldieVersions(t testing.TB, dir, name string) []string {
+	t.Helper()
+
+	golden, err := ReadGolden(dir, name)
+	require.NoError(t, err)
+
+	return golden.Versions
+}
+
+// GoldieLatest returns the latest stable version recorded for name in dir.
+func GoldieLatest(t testing.TB, dir, name string) string {
+	t.Helper()
+
+	golden, err := ReadGolden(dir, name)
+	require.NoError(t, err)
+
+	return golden.LatestStable
+}
+
+// GoldieFilterPattern returns the versions recorded for name in dir that match pattern.
+func GoldieFilterPattern(t testing.TB, dir, name, pattern string) []string {
+	t.Helper()
+
+	re, err := regexp.Compile(pattern)
+	require.NoError(t, err)
+
+	return asdf.FilterVersions(GoldieVersions(t, dir, name), re.MatchString)
+}
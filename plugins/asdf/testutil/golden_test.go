@@ -0,0 +1,239 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+func TestReadGolden(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reads legacy plain-text snapshots", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "doctl_list_all.golden"),
+			[]byte("1.66.0\n1.67.0\n\n1.68.0"), asdf.CommonFilePermission))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "doctl_latest_stable.golden"),
+			[]byte("1.68.0"), asdf.CommonFilePermission))
+
+		golden, err := testutil.ReadGolden(dir, "doctl")
+		require.NoError(t, err)
+		require.Zero(t, golden.Schema)
+		require.True(t, golden.RecordedAt.IsZero())
+		require.Equal(t, []string{"1.66.0", "1.67.0", "1.68.0"}, golden.Versions)
+		require.Equal(t, "1.68.0", golden.LatestStable)
+	})
+
+	t.Run("reads a legacy snapshot without latest stable", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "jq_list_all.golden"),
+			[]byte("1.7\n1.7.1\n"), asdf.CommonFilePermission))
+
+		golden, err := testutil.ReadGolden(dir, "jq")
+		require.NoError(t, err)
+		require.Equal(t, []string{"1.7", "1.7.1"}, golden.Versions)
+		require.Empty(t, golden.LatestStable)
+	})
+
+	t.Run("prefers the golden document", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "jq_list_all.golden"),
+			[]byte("1.6\n"), asdf.CommonFilePermission))
+		require.NoError(t, os.WriteFile(testutil.GoldenPath(dir, "jq"),
+			[]byte(`{"schema":1,"recorded_at":"2025-06-01T10:00:00Z","source":"releases",`+
+				`"latest_stable":"1.7.1","versions":["1.7","1.7.1"]}`),
+			asdf.CommonFilePermission))
+
+		golden, err := testutil.ReadGolden(dir, "jq")
+		require.NoError(t, err)
+		require.Equal(t, testutil.GoldenSchemaVersion, golden.Schema)
+		require.Equal(t, testutil.GoldenSourceReleases, golden.Source)
+		require.Equal(t, 2025, golden.RecordedAt.Year())
+		require.Equal(t, []string{"1.7", "1.7.1"}, golden.Versions)
+	})
+
+	t.Run("rejects newer schema versions", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := testutil.DecodeGolden([]byte(`{"schema":99,"versions":[]}`))
+		require.ErrorIs(t, err, testutil.ErrGoldenSchemaUnsupportedForTests())
+	})
+
+	t.Run("reports missing snapshots", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := testutil.ReadGolden(t.TempDir(), "kubectl")
+		require.ErrorIs(t, err, testutil.ErrGoldenNotFoundForTests())
+	})
+}
+
+func TestWriteGolden(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"helm_list_all.golden", "helm_latest_stable.golden", "sops_list_all.golden"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("3.0.0\n"), asdf.CommonFilePermission))
+	}
+
+	recorded := testutil.NewGolden(testutil.GoldenSourceTags, []string{"3.15.4", "3.16.0"}, "3.16.0")
+	require.NoError(t, testutil.WriteGolden(dir, "helm", recorded))
+
+	for _, name := range []string{"helm_list_all.golden", "helm_latest_stable.golden"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "sops_list_all.golden"))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(testutil.GoldenPath(dir, "helm"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"schema": 1`)
+	require.Contains(t, string(data), `"source": "tags"`)
+
+	golden, err := testutil.ReadGolden(dir, "helm")
+	require.NoError(t, err)
+	require.Equal(t, recorded, golden)
+}
+
+func TestGoldenCheck(t *testing.T) {
+	t.Parallel()
+
+	recorded := &testutil.Golden{
+		Schema:       testutil.GoldenSchemaVersion,
+		Source:       testutil.GoldenSourceReleases,
+		LatestStable: "1.5.0",
+		Versions:     []string{"0.9.0", "1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0"},
+	}
+
+	tests := []struct {
+		wantErr   func() error
+		live      *testutil.Golden
+		tolerance testutil.Tolerance
+		name      string
+		legacy    bool
+	}{
+		{
+			name: "accepts upstream growth",
+			live: testutil.NewGolden(testutil.GoldenSourceReleases,
+				[]string{"0.9.0", "1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0", "1.6.0"}, "1.6.0"),
+			tolerance: testutil.DefaultTolerance(),
+		},
+		{
+			name: "accepts deleted old tags by default",
+			live: testutil.NewGolden(testutil.GoldenSourceReleases,
+				[]string{"1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0"}, "1.5.0"),
+			tolerance: testutil.DefaultTolerance(),
+		},
+		{
+			name: "accepts legacy snapshots without a source",
+			live: testutil.NewGolden(testutil.GoldenSourceTags,
+				[]string{"1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0"}, ""),
+			tolerance: testutil.DefaultTolerance(),
+			legacy:    true,
+		},
+		{
+			name: "requires the newest recorded versions",
+			live: testutil.NewGolden(testutil.GoldenSourceReleases,
+				[]string{"0.9.0", "1.0.0", "1.1.0", "1.2.0", "1.4.0", "1.5.0"}, "1.5.0"),
+			tolerance: testutil.DefaultTolerance(),
+			wantErr:   testutil.ErrGoldenLatestMissingForTests,
+		},
+		{
+			name: "limits the fraction of missing older versions",
+			live: testutil.NewGolden(testutil.GoldenSourceReleases,
+				[]string{"1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0"}, "1.5.0"),
+			tolerance: testutil.Tolerance{MaxMissingFraction: 0.25, RequiredLatest: 3},
+			wantErr:   testutil.ErrGoldenTooManyMissingForTests,
+		},
+		{
+			name: "tolerates missing older versions within the fraction",
+			live: testutil.NewGolden(testutil.GoldenSourceReleases,
+				[]string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0"}, "1.5.0"),
+			tolerance: testutil.Tolerance{MaxMissingFraction: 0.25, RequiredLatest: 3},
+		},
+		{
+			name: "rejects a latest stable regression",
+			live: testutil.NewGolden(testutil.GoldenSourceReleases,
+				[]string{"0.9.0", "1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0"}, "1.4.0"),
+			tolerance: testutil.DefaultTolerance(),
+			wantErr:   testutil.ErrGoldenLatestStableRegressedForTests,
+		},
+		{
+			name: "rejects a changed source",
+			live: testutil.NewGolden(testutil.GoldenSourceTags,
+				[]string{"0.9.0", "1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0", "1.5.0"}, "1.5.0"),
+			tolerance: testutil.DefaultTolerance(),
+			wantErr:   testutil.ErrGoldenSourceChangedForTests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			golden := *recorded
+			if tt.legacy {
+				golden.Source, golden.Schema = "", 0
+			}
+
+			err := golden.Check(tt.live, tt.tolerance)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tt.wantErr())
+		})
+	}
+}
+
+func TestAssertGoldenUpdate(t *testing.T) {
+	update := flag.Lookup("update")
+	require.NotNil(t, update)
+
+	orig := update.Value.String()
+	require.NoError(t, flag.Set("update", "true"))
+	t.Cleanup(func() { require.NoError(t, flag.Set("update", orig)) })
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kind_list_all.golden"),
+		[]byte("0.22.0\n"), asdf.CommonFilePermission))
+
+	live := testutil.NewGolden(testutil.GoldenSourceReleases, []string{"0.23.0"}, "0.23.0")
+	testutil.AssertGolden(t, dir, "kind", live, testutil.DefaultTolerance())
+
+	golden, err := testutil.ReadGolden(dir, "kind")
+	require.NoError(t, err)
+	require.Equal(t, live, golden)
+
+	_, err = os.Stat(filepath.Join(dir, "kind_list_all.golden"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
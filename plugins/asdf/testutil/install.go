@@ -0,0 +1,359 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/github"
+	githubmock "github.com/sumicare/universal-asdf-plugin/plugins/github/mock"
+)
+
+// defaultHarnessVersion is the version installed when InstallHarness.Version is empty.
+const defaultHarnessVersion = "1.2.3"
+
+// InstallHarness downloads and installs a BinaryPlugin based plugin from a
+// local asset server publishing synthesized release archives, so plugin
+// tests cover Download and Install without reimplementing the mocking.
+type InstallHarness struct {
+	// Config selects the plugin, which must embed an asdf.BinaryPlugin.
+	Config BinaryPluginTestConfig
+	// Version is the installed version, defaultHarnessVersion when empty.
+	Version string
+	// Publish, when set, is called with the URL of the asset server and the
+	// synthesized archives keyed by file name before the install, and returns
+	// extra files the asset server serves keyed by path, so plugins can
+	// publish what they verify downloads against, e.g. a checksums file.
+	Publish func(assetsURL string, archives map[string][]byte) map[string][]byte
+	// BinaryPath is the path of the binary in the archive, relative to the
+	// stripped archive root and rendered like FileNameTemplate, for plugins
+	// placing it themselves. BinaryPathInArchive, or the archive root, when empty.
+	BinaryPath string
+	// WantErr, when set, must be contained in the error Download or Install
+	// fails with; the install is not inspected.
+	WantErr string
+	// ArchiveFiles are extra archive members, relative to the stripped archive
+	// root and rendered like FileNameTemplate.
+	ArchiveFiles map[string]string
+	// ExpectedFiles are paths relative to the install path that must exist
+	// after the install, in addition to the binary.
+	ExpectedFiles []string
+	// Reinstall downloads and installs a second time into the same paths and
+	// asserts it succeeds with an identical install, see asdf.Plugin.Install.
+	Reinstall bool
+	// Platform is the "<os>/<arch>" the assets are selected for through
+	// asdf.ForcePlatformEnv and asdf.ForceArchEnv, the running one when empty.
+	// Unlike the running platform, the plugin must support it.
+	Platform string
+}
+
+// Run installs the plugin into a temporary directory and returns the install
+// path after asserting the binary and every one of ExtraBinaries are
+// executable under ListBinPaths. The test is skipped when the plugin does not
+// support the running platform.
+func (harness InstallHarness) Run(t *testing.T) string {
+	t.Helper()
+
+	version := harness.Version
+	if version == "" {
+		version = defaultHarnessVersion
+	}
+
+	plugin := harness.Config.Factory()
+	binary := binaryPluginOf(plugin, github.NewClientWithHTTP(http.DefaultClient, harness.githubServer(t)))
+	require.NotNil(t, binary, "%s does not embed an asdf.BinaryPlugin", harness.Config.Name)
+
+	config := binary.Config
+
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if harness.Platform != "" {
+		goos, goarch, _ = strings.Cut(harness.Platform, "/")
+		t.Setenv(asdf.ForcePlatformEnv, goos)
+		t.Setenv(asdf.ForceArchEnv, goarch)
+		require.Contains(t, config.Platforms(), harness.Platform, "%s does not support %s", harness.Config.Name, harness.Platform)
+	}
+
+	platform := config.OsMap[goos]
+
+	arch, ok := config.MappedArch(goos, goarch)
+	if platform == "" || !ok || !slices.Contains(config.Platforms(), goos+"/"+goarch) {
+		t.Skipf("%s does not support %s/%s", harness.Config.Name, goos, goarch)
+	}
+
+	fileNameTemplate := config.FileNameTemplateFor(goos, goarch)
+	archiveType := config.ArchiveTypeFor(goos, goarch)
+	binaries := append([]asdf.ReleaseBinary{{FileNameTemplate: fileNameTemplate, BinaryName: config.BinaryName}},
+		config.ExtraBinaries...)
+
+	archives := make(map[string][]byte, len(binaries))
+
+	files := make(map[string][]byte, len(binaries))
+
+	for i, binary := range binaries {
+		render := strings.NewReplacer(
+			"{{.Version}}", version,
+			"{{.Platform}}", platform,
+			"{{.Arch}}", arch,
+			"{{.BinaryName}}", binary.BinaryName,
+		).Replace
+
+		binaryPath := binary.BinaryName
+
+		switch {
+		case i == 0 && harness.BinaryPath != "":
+			binaryPath = render(harness.BinaryPath)
+		case config.BinaryPathInArchive != "":
+			binaryPath = render(config.BinaryPathInArchive)
+		}
+
+		content := binaryContent(binary.BinaryName, version)
+		members := harness.archiveMembers(config, binaryPath, render, content)
+
+		name := render(binary.FileNameTemplate)
+		archives[name] = SynthesizeArchive(t, archiveType, members)
+		files["/"+name] = archives[name]
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		data, ok := files[request.URL.Path]
+		if !ok {
+			http.NotFound(writer, request)
+
+			return
+		}
+
+		_, _ = writer.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	config.DownloadURLTemplate = server.URL + "/{{.FileName}}"
+
+	if harness.Publish != nil {
+		maps.Copy(files, harness.Publish(server.URL, archives))
+	}
+
+	downloadPath, installPath := t.TempDir(), t.TempDir()
+
+	err := plugin.Download(t.Context(), version, downloadPath)
+	if err == nil {
+		err = plugin.Install(t.Context(), version, downloadPath, installPath)
+	}
+
+	if harness.WantErr != "" {
+		require.ErrorContains(t, err, harness.WantErr)
+
+		return installPath
+	}
+
+	require.NoError(t, err)
+
+	for _, binary := range binaries {
+		binaryPath := findInstalledBinary(installPath, plugin.ListBinPaths(), binary.BinaryName)
+		require.NotEmpty(t, binaryPath, "%s not found under %s", binary.BinaryName, plugin.ListBinPaths())
+
+		info, err := os.Stat(binaryPath)
+		require.NoError(t, err)
+		require.NotZero(t, info.Mode().Perm()&0o111, "%s is not executable", binaryPath)
+
+		data, err := os.ReadFile(binaryPath)
+		require.NoError(t, err)
+		require.Equal(t, binaryContent(binary.BinaryName, version), string(data))
+	}
+
+	for _, expected := range harness.ExpectedFiles {
+		require.FileExists(t, filepath.Join(installPath, filepath.FromSlash(expected)))
+	}
+
+	if harness.Reinstall {
+		installed, err := asdf.HashTree(installPath)
+		require.NoError(t, err)
+
+		require.NoError(t, plugin.Download(t.Context(), version, downloadPath), "downloading again")
+		require.NoError(t, plugin.Install(t.Context(), version, downloadPath, installPath), "installing again")
+
+		reinstalled, err := asdf.HashTree(installPath)
+		require.NoError(t, err)
+		require.Equal(t, installed.Files, reinstalled.Files, "reinstalling changed the install")
+	}
+
+	return installPath
+}
+
+// githubServer starts a GitHub API mock for the plugin, which publishes no
+// attestations, and returns its URL.
+func (InstallHarness) githubServer(t *testing.T) string {
+	t.Helper()
+
+	server := githubmock.NewServer()
+	t.Cleanup(server.Close)
+
+	return server.URL()
+}
+
+// binaryContent returns the script synthesized as binaryName.
+func binaryContent(binaryName, version string) string {
+	return "#!/bin/sh\necho " + binaryName + " " + version + "\n"
+}
+
+// archiveMembers returns the archive members: the binary at binaryPath below
+// StripComponents leading directories, a placeholder in each of ArchiveDirs
+// and ArchiveFiles.
+func (harness InstallHarness) archiveMembers(
+	config *asdf.BinaryPluginConfig,
+	binaryPath string,
+	render func(string) string,
+	content string,
+) map[string]string {
+	prefix := ""
+	for i := range config.StripComponents {
+		prefix += "strip" + strconv.Itoa(i) + "/"
+	}
+
+	members := map[string]string{prefix + binaryPath: content}
+	for _, dir := range config.ArchiveDirs {
+		members[prefix+dir+"/.keep"] = ""
+	}
+
+	for name, data := range harness.ArchiveFiles {
+		members[prefix+render(name)] = data
+	}
+
+	return members
+}
+
+// binaryPluginOf returns the asdf.BinaryPlugin behind plugin, pointed at client,
+// or nil when plugin does not embed one.
+func binaryPluginOf(plugin asdf.Plugin, client *github.Client) *asdf.BinaryPlugin {
+	typed, ok := plugin.(interface {
+		WithGithubClient(client *github.Client) *asdf.BinaryPlugin
+	})
+	if !ok {
+		return nil
+	}
+
+	return typed.WithGithubClient(client)
+}
+
+// findInstalledBinary returns the path of name in the first of the
+// space-separated binPaths of installPath holding it.
+func findInstalledBinary(installPath, binPaths, name string) string {
+	for _, binPath := range strings.Fields(binPaths) {
+		candidate := filepath.Join(installPath, filepath.FromSlash(binPath), name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// SynthesizeArchive returns an archive of archiveType holding members, keyed
+// by slash-separated path: "gz" compresses the only member, "tar.gz",
+// "tar.xz" and "zip" hold all members executable, and "", "none" or "raw"
+// return the only member as-is.
+func SynthesizeArchive(t testing.TB, archiveType string, members map[string]string) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+
+	switch archiveType {
+	case "gz":
+		require.Len(t, members, 1, "gz archives hold a single file")
+
+		writer := gzip.NewWriter(&buffer)
+		for _, content := range members {
+			_, err := io.WriteString(writer, content)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, writer.Close())
+
+	case "tar.gz":
+		writer := gzip.NewWriter(&buffer)
+		writeTarMembers(t, writer, members)
+		require.NoError(t, writer.Close())
+
+	case "tar.xz":
+		writer, err := xz.NewWriter(&buffer)
+		require.NoError(t, err)
+		writeTarMembers(t, writer, members)
+		require.NoError(t, writer.Close())
+
+	case "zip":
+		writer := zip.NewWriter(&buffer)
+
+		for name, content := range members {
+			header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+			header.SetMode(asdf.CommonExecutablePermission)
+
+			file, err := writer.CreateHeader(header)
+			require.NoError(t, err)
+
+			_, err = io.WriteString(file, content)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, writer.Close())
+
+	default:
+		require.Len(t, members, 1, "raw downloads hold a single file")
+
+		for _, content := range members {
+			buffer.WriteString(content)
+		}
+	}
+
+	return buffer.Bytes()
+}
+
+// writeTarMembers writes members as executable files to writer.
+func writeTarMembers(t testing.TB, writer io.Writer, members map[string]string) {
+	t.Helper()
+
+	tarWriter := tar.NewWriter(writer)
+
+	for name, content := range members {
+		require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     int64(asdf.CommonExecutablePermission),
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+
+		_, err := io.WriteString(tarWriter, content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tarWriter.Close())
+}
@@ -0,0 +1,204 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// harnessPlugin returns a BinaryPlugin factory downloading archiveType releases of tool.
+func harnessPlugin(archiveType, fileNameTemplate string, configure func(*asdf.BinaryPluginConfig)) func() asdf.Plugin {
+	return func() asdf.Plugin {
+		config := &asdf.BinaryPluginConfig{
+			Name:             "tool",
+			RepoOwner:        "example",
+			RepoName:         "tool",
+			BinaryName:       "tool",
+			ArchiveType:      archiveType,
+			FileNameTemplate: fileNameTemplate,
+		}
+
+		if configure != nil {
+			configure(config)
+		}
+
+		return asdf.NewBinaryPlugin(config)
+	}
+}
+
+func TestInstallHarness(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		configure   func(*asdf.BinaryPluginConfig)
+		name        string
+		archiveType string
+		fileName    string
+	}{
+		{name: "raw", archiveType: "", fileName: "tool-{{.Platform}}-{{.Arch}}"},
+		{name: "none", archiveType: "none", fileName: "tool_{{.Version}}_{{.Platform}}_{{.Arch}}"},
+		{name: "gz", archiveType: "gz", fileName: "tool-{{.Platform}}-{{.Arch}}.gz"},
+		{name: "tar.gz", archiveType: "tar.gz", fileName: "tool-{{.Version}}-{{.Platform}}-{{.Arch}}.tar.gz"},
+		{name: "tar.xz", archiveType: "tar.xz", fileName: "tool-{{.Version}}.{{.Platform}}.{{.Arch}}.tar.xz"},
+		{name: "zip", archiveType: "zip", fileName: "tool_{{.Version}}_{{.Platform}}_{{.Arch}}.zip"},
+		{
+			name:        "nested tar.gz",
+			archiveType: "tar.gz",
+			fileName:    "tool-{{.Version}}.tar.gz",
+			configure: func(config *asdf.BinaryPluginConfig) {
+				config.StripComponents = 1
+				config.BinaryPathInArchive = "{{.BinaryName}}-{{.Version}}/bin/{{.BinaryName}}"
+			},
+		},
+		{
+			name:        "extra binaries",
+			archiveType: "tar.gz",
+			fileName:    "tool_{{.Version}}_{{.Platform}}_{{.Arch}}.tar.gz",
+			configure: func(config *asdf.BinaryPluginConfig) {
+				config.ExtraBinaries = []asdf.ReleaseBinary{{
+					FileNameTemplate: "tool-helper_{{.Version}}_{{.Platform}}_{{.Arch}}.tar.gz",
+					BinaryName:       "tool-helper",
+				}}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			installPath := testutil.InstallHarness{
+				Config: testutil.BinaryPluginTestConfig{
+					Name:    "tool",
+					Factory: harnessPlugin(tt.archiveType, tt.fileName, tt.configure),
+				},
+			}.Run(t)
+
+			require.FileExists(t, filepath.Join(installPath, "bin", "tool"))
+		})
+	}
+}
+
+func TestInstallHarnessExpectedFiles(t *testing.T) {
+	t.Parallel()
+
+	testutil.InstallHarness{
+		Config: testutil.BinaryPluginTestConfig{
+			Name:    "tool",
+			Factory: harnessPlugin("zip", "tool.zip", nil),
+		},
+		Version:       "2.0.0",
+		ArchiveFiles:  map[string]string{"LICENSE": "license"},
+		ExpectedFiles: []string{"bin/tool"},
+	}.Run(t)
+}
+
+func TestInstallHarnessPlatform(t *testing.T) {
+	factory := harnessPlugin("tar.gz", "tool_{{.Version}}_{{.Platform}}_{{.Arch}}.tar.gz", func(config *asdf.BinaryPluginConfig) {
+		config.PlatformFileNameTemplates = map[string]string{"darwin": "tool-{{.Platform}}-{{.Arch}}"}
+		config.PlatformArchiveTypes = map[string]string{"darwin": "none"}
+	})
+
+	tests := []struct {
+		platform string
+		artifact string
+	}{
+		{platform: "darwin/arm64", artifact: "tool-darwin-arm64"},
+		{platform: "linux/arm64", artifact: "tool_1.2.3_linux_arm64.tar.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			testutil.InstallHarness{
+				Config:   testutil.BinaryPluginTestConfig{Name: "tool", Factory: factory},
+				Platform: tt.platform,
+			}.Run(t)
+
+			names, err := factory().(asdf.PluginWithArtifacts).ArtifactNames("1.2.3")
+			require.NoError(t, err)
+			require.Equal(t, []string{tt.artifact}, names)
+		})
+	}
+}
+
+func TestSynthesizeArchive(t *testing.T) {
+	t.Parallel()
+
+	members := map[string]string{"dir/tool": "binary", "README": "readme"}
+
+	for _, archiveType := range []string{"tar.gz", "tar.xz", "zip"} {
+		t.Run(archiveType, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			archivePath := filepath.Join(dir, "archive."+archiveType)
+			require.NoError(t, os.WriteFile(archivePath,
+				testutil.SynthesizeArchive(t, archiveType, members), asdf.CommonFilePermission))
+
+			extract := map[string]func(string, string) error{
+				"tar.gz": asdf.ExtractTarGz,
+				"tar.xz": asdf.ExtractTarXz,
+				"zip":    asdf.ExtractZip,
+			}[archiveType]
+
+			extracted := filepath.Join(dir, "extracted")
+			require.NoError(t, extract(archivePath, extracted))
+			require.FileExists(t, filepath.Join(extracted, "dir", "tool"))
+			require.FileExists(t, filepath.Join(extracted, "README"))
+		})
+	}
+}
+
+func TestInstallHarnessPublish(t *testing.T) {
+	t.Parallel()
+
+	factory := harnessPlugin("tar.gz", "tool_{{.Version}}.tar.gz", func(config *asdf.BinaryPluginConfig) {
+		config.ChecksumFileTemplate = "tool_{{.Version}}_checksums.txt"
+	})
+
+	checksums := func(sum string) func(string, map[string][]byte) map[string][]byte {
+		return func(_ string, archives map[string][]byte) map[string][]byte {
+			require.Contains(t, archives, "tool_1.2.3.tar.gz")
+
+			return map[string][]byte{"/tool_1.2.3_checksums.txt": []byte(sum + "  tool_1.2.3.tar.gz\n")}
+		}
+	}
+
+	testutil.InstallHarness{
+		Config:     testutil.BinaryPluginTestConfig{Name: "tool", Factory: factory},
+		BinaryPath: "tool-{{.Version}}/{{.BinaryName}}",
+		Publish: func(assetsURL string, archives map[string][]byte) map[string][]byte {
+			sum := sha256.Sum256(archives["tool_1.2.3.tar.gz"])
+
+			return checksums(hex.EncodeToString(sum[:]))(assetsURL, archives)
+		},
+	}.Run(t)
+
+	testutil.InstallHarness{
+		Config:  testutil.BinaryPluginTestConfig{Name: "tool", Factory: factory},
+		Publish: checksums(strings.Repeat("0", sha256.Size*2)),
+		WantErr: "checksum mismatch",
+	}.Run(t)
+}
@@ -0,0 +1,204 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/github"
+)
+
+const (
+	// GitHubAPIURL is the upstream recorded by RecordAllFixtures.
+	GitHubAPIURL = "https://api.github.com"
+	// OnlineEnv enables tests that talk to upstream services when set to 1.
+	OnlineEnv = "ONLINE"
+)
+
+// errRecordingUnsupported is returned for plugins that cannot list versions through a GitHub client.
+var errRecordingUnsupported = errors.New("plugin does not list versions through a GitHub client")
+
+type (
+	// BinaryPluginTestConfig registers a plugin whose golden versions are
+	// recorded from its GitHub tags or releases.
+	BinaryPluginTestConfig struct {
+		// Factory creates the plugin under test.
+		Factory func() asdf.Plugin
+		// Name is the golden file name, usually the plugin name.
+		Name string
+	}
+
+	// GitHubRecorder is a reverse proxy to the GitHub API that records which
+	// endpoints a plugin queried, to tell tags from releases.
+	GitHubRecorder struct {
+		server *httptest.Server
+		paths  []string
+		mu     sync.Mutex
+	}
+)
+
+// Recording reports whether golden files are recorded from upstream, which
+// needs both ONLINE=1 and -update.
+func Recording() bool {
+	return os.Getenv(OnlineEnv) == "1" && Updating()
+}
+
+// NewGitHubRecorder starts a reverse proxy to upstream, stopped when t ends.
+func NewGitHubRecorder(t testing.TB, upstream string) *GitHubRecorder {
+	t.Helper()
+
+	target, err := url.Parse(upstream)
+	require.NoError(t, err)
+
+	recorder := &GitHubRecorder{}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(request *httputil.ProxyRequest) {
+			request.SetURL(target)
+			request.Out.Host = target.Host
+		},
+		ModifyResponse: func(response *http.Response) error {
+			if response.StatusCode == http.StatusOK {
+				recorder.record(response.Request.URL.Path)
+			}
+
+			return nil
+		},
+	}
+
+	recorder.server = httptest.NewServer(proxy)
+	t.Cleanup(recorder.server.Close)
+
+	return recorder
+}
+
+// record remembers a successfully proxied request path.
+func (recorder *GitHubRecorder) record(path string) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	recorder.paths = append(recorder.paths, path)
+}
+
+// Client returns a GitHub client sending its requests through the recorder.
+func (recorder *GitHubRecorder) Client() *github.Client {
+	return github.NewClientWithHTTP(recorder.server.Client(), recorder.server.URL)
+}
+
+// Paths returns the request paths answered so far, in request order.
+func (recorder *GitHubRecorder) Paths() []string {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	return slices.Clone(recorder.paths)
+}
+
+// Source returns the GoldenSource constant matching the recorded requests:
+// releases when any release listing was queried, then tags, then custom.
+func (recorder *GitHubRecorder) Source() string {
+	paths := recorder.Paths()
+
+	switch {
+	case slices.ContainsFunc(paths, func(path string) bool { return strings.HasSuffix(path, "/releases") }):
+		return GoldenSourceReleases
+	case slices.ContainsFunc(paths, func(path string) bool { return strings.Contains(path, "/git/refs/tags") }):
+		return GoldenSourceTags
+	default:
+		return GoldenSourceCustom
+	}
+}
+
+// UseGitHubClient points the BinaryPlugin or SourceBuildPlugin behind plugin,
+// including embedded ones, at client. It reports false for other plugins.
+func UseGitHubClient(plugin asdf.Plugin, client *github.Client) bool {
+	switch typed := plugin.(type) {
+	case interface {
+		WithGithubClient(client *github.Client) *asdf.BinaryPlugin
+	}:
+		typed.WithGithubClient(client)
+	case interface{ WithGithubClient(client *github.Client) }:
+		typed.WithGithubClient(client)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// CaptureGolden lists the versions of the configured plugin through a
+// GitHubRecorder proxying upstream and returns them as a golden snapshot.
+func CaptureGolden(t testing.TB, upstream string, config BinaryPluginTestConfig) (*Golden, error) {
+	t.Helper()
+
+	recorder := NewGitHubRecorder(t, upstream)
+
+	plugin := config.Factory()
+	if !UseGitHubClient(plugin, recorder.Client()) {
+		return nil, fmt.Errorf("%w: %s", errRecordingUnsupported, config.Name)
+	}
+
+	versions, err := plugin.ListAll(t.Context())
+	if err != nil {
+		return nil, fmt.Errorf("listing %s versions: %w", config.Name, err)
+	}
+
+	latest, err := plugin.LatestStable(t.Context(), "")
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s latest stable: %w", config.Name, err)
+	}
+
+	return NewGolden(recorder.Source(), versions, latest), nil
+}
+
+// RecordAllFixtures refreshes the golden document of every config in dir from
+// the live GitHub API. It is skipped unless Recording, so it can be wired into
+// a make target: ONLINE=1 go test ./plugins/asdf/plugins -run TestRecordFixtures -update.
+func RecordAllFixtures(t *testing.T, dir string, configs []BinaryPluginTestConfig) {
+	t.Helper()
+
+	if !Recording() {
+		t.Skip("set " + OnlineEnv + "=1 and run with -update to record fixtures")
+	}
+
+	recordFixtures(t, GitHubAPIURL, dir, configs)
+}
+
+// recordFixtures writes the golden documents of configs recorded from upstream.
+func recordFixtures(t *testing.T, upstream, dir string, configs []BinaryPluginTestConfig) {
+	t.Helper()
+
+	for _, config := range configs {
+		t.Run(config.Name, func(t *testing.T) {
+			t.Parallel()
+
+			live, err := CaptureGolden(t, upstream, config)
+			require.NoError(t, err)
+			require.NoError(t, WriteGolden(dir, config.Name, live))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+	githubmock "github.com/sumicare/universal-asdf-plugin/plugins/github/mock"
+)
+
+// fixturePlugin lists the versions of example/tool from releases, or tags when useTags is set.
+func fixturePlugin(useTags bool) func() asdf.Plugin {
+	return func() asdf.Plugin {
+		return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+			Name:      "tool",
+			RepoOwner: "example",
+			RepoName:  "tool",
+			UseTags:   useTags,
+		})
+	}
+}
+
+// wrappedPlugin embeds a BinaryPlugin like the plugins overriding some methods.
+type wrappedPlugin struct {
+	*asdf.BinaryPlugin
+}
+
+// customPlugin does not list versions from GitHub.
+type customPlugin struct {
+	asdf.Plugin
+}
+
+func TestCaptureGolden(t *testing.T) {
+	t.Parallel()
+
+	upstream := githubmock.NewServer()
+	t.Cleanup(upstream.Close)
+
+	upstream.AddReleases("example", "tool", []string{"v1.0.0", "v1.1.0", "v1.2.0-rc.1"})
+	upstream.AddTags("example", "tool", []string{"v0.9.0", "v1.0.0"})
+
+	t.Run("records versions from releases", func(t *testing.T) {
+		t.Parallel()
+
+		live, err := testutil.CaptureGolden(t, upstream.URL(),
+			testutil.BinaryPluginTestConfig{Name: "tool", Factory: fixturePlugin(false)})
+		require.NoError(t, err)
+		require.Equal(t, testutil.GoldenSourceReleases, live.Source)
+		require.Equal(t, []string{"1.0.0", "1.1.0"}, live.Versions)
+		require.Equal(t, "1.1.0", live.LatestStable)
+		require.Equal(t, testutil.GoldenSchemaVersion, live.Schema)
+	})
+
+	t.Run("records versions from tags", func(t *testing.T) {
+		t.Parallel()
+
+		live, err := testutil.CaptureGolden(t, upstream.URL(),
+			testutil.BinaryPluginTestConfig{Name: "tool", Factory: fixturePlugin(true)})
+		require.NoError(t, err)
+		require.Equal(t, testutil.GoldenSourceTags, live.Source)
+		require.Equal(t, []string{"0.9.0", "1.0.0"}, live.Versions)
+	})
+
+	t.Run("records embedded binary plugins", func(t *testing.T) {
+		t.Parallel()
+
+		factory := func() asdf.Plugin {
+			return &wrappedPlugin{fixturePlugin(false)().(*asdf.BinaryPlugin)}
+		}
+
+		live, err := testutil.CaptureGolden(t, upstream.URL(),
+			testutil.BinaryPluginTestConfig{Name: "tool", Factory: factory})
+		require.NoError(t, err)
+		require.Equal(t, testutil.GoldenSourceReleases, live.Source)
+	})
+
+	t.Run("rejects plugins without a GitHub client", func(t *testing.T) {
+		t.Parallel()
+
+		factory := func() asdf.Plugin { return customPlugin{} }
+
+		_, err := testutil.CaptureGolden(t, upstream.URL(),
+			testutil.BinaryPluginTestConfig{Name: "custom", Factory: factory})
+		require.ErrorIs(t, err, testutil.ErrRecordingUnsupportedForTests())
+	})
+}
+
+func TestRecordFixtures(t *testing.T) {
+	t.Parallel()
+
+	upstream := githubmock.NewServer()
+	t.Cleanup(upstream.Close)
+
+	upstream.AddReleases("example", "tool", []string{"v1.0.0", "v1.1.0"})
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tool_list_all.golden"),
+		[]byte("0.9.0\n1.0.0\n"), asdf.CommonFilePermission))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tool_latest_stable.golden"),
+		[]byte("1.0.0"), asdf.CommonFilePermission))
+
+	require.True(t, testutil.GoldieFilesExist(dir, "tool"))
+	require.Equal(t, []string{"0.9.0", "1.0.0"}, testutil.GoldieVersions(t, dir, "tool"))
+
+	// The recordings run as parallel subtests, which finish with this group.
+	t.Run("record", func(t *testing.T) {
+		testutil.RecordFixturesForTests(t, upstream.URL(), dir, []testutil.BinaryPluginTestConfig{
+			{Name: "tool", Factory: fixturePlugin(false)},
+		})
+	})
+
+	require.FileExists(t, testutil.GoldenPath(dir, "tool"))
+	require.NoFileExists(t, filepath.Join(dir, "tool_list_all.golden"))
+	require.NoFileExists(t, filepath.Join(dir, "tool_latest_stable.golden"))
+
+	golden, err := testutil.ReadGolden(dir, "tool")
+	require.NoError(t, err)
+	require.Equal(t, testutil.GoldenSourceReleases, golden.Source)
+	require.Equal(t, []string{"1.0.0", "1.1.0"}, testutil.GoldieVersions(t, dir, "tool"))
+	require.Equal(t, "1.1.0", testutil.GoldieLatest(t, dir, "tool"))
+}
+
+func TestGoldieHelpers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.False(t, testutil.GoldieFilesExist(dir, "jq"))
+
+	require.NoError(t, testutil.WriteGolden(dir, "jq",
+		testutil.NewGolden(testutil.GoldenSourceReleases, []string{"1.6", "1.7", "1.7.1", "1.8.0"}, "1.8.0")))
+
+	require.True(t, testutil.GoldieFilesExist(dir, "jq"))
+	require.Equal(t, "1.8.0", testutil.GoldieLatest(t, dir, "jq"))
+	require.Equal(t, []string{"1.7", "1.7.1"}, testutil.GoldieFilterPattern(t, dir, "jq", `^1\.7`))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "yq_list_all.golden"),
+		[]byte("4.40.0\n4.44.1\n"), asdf.CommonFilePermission))
+	require.Equal(t, []string{"4.44.1"}, testutil.GoldieFilterPattern(t, dir, "yq", `^4\.44\.`))
+	require.Empty(t, testutil.GoldieLatest(t, dir, "yq"))
+}
+
+func TestRecording(t *testing.T) {
+	t.Setenv(testutil.OnlineEnv, "")
+	require.False(t, testutil.Recording())
+}
@@ -0,0 +1,289 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/github"
+	githubmock "github.com/sumicare/universal-asdf-plugin/plugins/github/mock"
+)
+
+type (
+	// VersionSource is a local server publishing versions the way an upstream
+	// does, so plugin tests only declare which kind of upstream they list from.
+	VersionSource interface {
+		// SetupVersions replaces the published versions, oldest first.
+		SetupVersions(versions []string)
+		// URL is the address the plugin under test lists versions from.
+		URL() string
+		// Close shuts the server down.
+		Close()
+	}
+
+	// GitHubSource publishes versions as the tags or releases of a GitHub repository.
+	GitHubSource struct {
+		server *githubmock.Server
+		owner  string
+		repo   string
+		// prefix is prepended to every version, e.g. "v".
+		prefix   string
+		releases bool
+	}
+
+	// JSONIndexSource publishes versions as a JSON download index, either an
+	// object keyed by version (zig) or an array of entries (nodejs).
+	JSONIndexSource struct {
+		server *httptest.Server
+		// entry returns the index entry of a version.
+		entry    func(version string) any
+		versions []string
+		mu       sync.Mutex
+		keyed    bool
+	}
+
+	// ListingSource publishes versions as paginated object listings of a
+	// bucket, like the Google Cloud Storage JSON API.
+	ListingSource struct {
+		server *httptest.Server
+		// objectName returns the object published for a version.
+		objectName func(version string) string
+		objects    []string
+		mu         sync.Mutex
+		pageSize   int
+	}
+
+	// listingPage is a page of a ListingSource listing.
+	listingPage struct {
+		NextPageToken string          `json:"nextPageToken,omitempty"`
+		Items         []listingObject `json:"items"`
+	}
+
+	// listingObject is an object of a listingPage.
+	listingObject struct {
+		Name string `json:"name"`
+	}
+)
+
+// NewGitHubTagsSource publishes versions as the git tags of owner/repo.
+func NewGitHubTagsSource(owner, repo, prefix string) *GitHubSource {
+	return &GitHubSource{server: githubmock.NewServer(), owner: owner, repo: repo, prefix: prefix}
+}
+
+// NewGitHubReleasesSource publishes versions as the releases of owner/repo,
+// each with a single asset.
+func NewGitHubReleasesSource(owner, repo, prefix string) *GitHubSource {
+	source := NewGitHubTagsSource(owner, repo, prefix)
+	source.releases = true
+
+	return source
+}
+
+// SetupVersions publishes versions as tags or releases.
+func (source *GitHubSource) SetupVersions(versions []string) {
+	tags := make([]string, 0, len(versions))
+	for _, version := range versions {
+		tags = append(tags, source.prefix+version)
+	}
+
+	if source.releases {
+		source.server.AddReleases(source.owner, source.repo, tags)
+
+		return
+	}
+
+	source.server.AddTags(source.owner, source.repo, tags)
+}
+
+// URL returns the GitHub API base URL.
+func (source *GitHubSource) URL() string {
+	return source.server.URL()
+}
+
+// Client returns a GitHub client talking to the source.
+func (source *GitHubSource) Client() *github.Client {
+	return github.NewClientWithHTTP(source.server.HTTPServer.Client(), source.server.URL())
+}
+
+// Close shuts the server down.
+func (source *GitHubSource) Close() {
+	source.server.Close()
+}
+
+// NewKeyedIndexSource publishes versions as a JSON object mapping every
+// version to its entry, like the Zig download index.
+func NewKeyedIndexSource(entry func(version string) any) *JSONIndexSource {
+	return newJSONIndexSource(entry, true)
+}
+
+// NewListIndexSource publishes versions as a JSON array of entries, newest
+// first, like the Node.js distribution index.
+func NewListIndexSource(entry func(version string) any) *JSONIndexSource {
+	return newJSONIndexSource(entry, false)
+}
+
+// newJSONIndexSource starts a JSONIndexSource serving the index at any path.
+func newJSONIndexSource(entry func(version string) any, keyed bool) *JSONIndexSource {
+	source := &JSONIndexSource{entry: entry, keyed: keyed}
+	source.server = httptest.NewServer(http.HandlerFunc(source.serveIndex))
+
+	return source
+}
+
+// SetupVersions replaces the versions of the index.
+func (source *JSONIndexSource) SetupVersions(versions []string) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	source.versions = slices.Clone(versions)
+}
+
+// URL returns the URL of the index.
+func (source *JSONIndexSource) URL() string {
+	return source.server.URL + "/index.json"
+}
+
+// Close shuts the server down.
+func (source *JSONIndexSource) Close() {
+	source.server.Close()
+}
+
+// serveIndex writes the index of the current versions.
+func (source *JSONIndexSource) serveIndex(writer http.ResponseWriter, _ *http.Request) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	var index any
+
+	if source.keyed {
+		entries := make(map[string]any, len(source.versions))
+		for _, version := range source.versions {
+			entries[version] = source.entry(version)
+		}
+
+		index = entries
+	} else {
+		entries := make([]any, 0, len(source.versions))
+		for _, version := range slices.Backward(source.versions) {
+			entries = append(entries, source.entry(version))
+		}
+
+		index = entries
+	}
+
+	writeSourceJSON(writer, index)
+}
+
+// NewListingSource publishes the objectName of every version in pages of
+// pageSize objects, following nextPageToken like the GCS JSON API.
+func NewListingSource(objectName func(version string) string, pageSize int) *ListingSource {
+	source := &ListingSource{objectName: objectName, pageSize: max(pageSize, 1)}
+	source.server = httptest.NewServer(http.HandlerFunc(source.serveListing))
+
+	return source
+}
+
+// SetupVersions replaces the objects of the listing.
+func (source *ListingSource) SetupVersions(versions []string) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	source.objects = make([]string, 0, len(versions))
+	for _, version := range versions {
+		source.objects = append(source.objects, source.objectName(version))
+	}
+}
+
+// URL returns the URL of the listing.
+func (source *ListingSource) URL() string {
+	return source.server.URL + "/o"
+}
+
+// Close shuts the server down.
+func (source *ListingSource) Close() {
+	source.server.Close()
+}
+
+// serveListing writes the page selected by the pageToken query parameter,
+// keeping only objects starting with the prefix query parameter.
+func (source *ListingSource) serveListing(writer http.ResponseWriter, request *http.Request) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	query := request.URL.Query()
+
+	objects := slices.DeleteFunc(slices.Clone(source.objects), func(object string) bool {
+		return !strings.HasPrefix(object, query.Get("prefix"))
+	})
+
+	start, err := strconv.Atoi(query.Get("pageToken"))
+	if err != nil || start < 0 || start > len(objects) {
+		start = 0
+	}
+
+	end := min(start+source.pageSize, len(objects))
+
+	page := listingPage{Items: make([]listingObject, 0, end-start)}
+	for _, object := range objects[start:end] {
+		page.Items = append(page.Items, listingObject{Name: object})
+	}
+
+	if end < len(objects) {
+		page.NextPageToken = strconv.Itoa(end)
+	}
+
+	writeSourceJSON(writer, page)
+}
+
+// writeSourceJSON writes value as a JSON response.
+func writeSourceJSON(writer http.ResponseWriter, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(writer).Encode(value); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// StartVersionSource publishes versions from source until t ends.
+func StartVersionSource[S VersionSource](t testing.TB, source S, versions []string) S {
+	t.Helper()
+
+	source.SetupVersions(versions)
+	t.Cleanup(source.Close)
+
+	return source
+}
+
+// SetupVersionsFromGoldie publishes the versions recorded for name in dir from
+// source, in place of the ad-hoc per-plugin fixture setup.
+func SetupVersionsFromGoldie(t testing.TB, source VersionSource, dir, name string) []string {
+	t.Helper()
+
+	versions := GoldieVersions(t, dir, name)
+	require.NotEmpty(t, versions, "no versions recorded for %s", name)
+
+	source.SetupVersions(versions)
+
+	return versions
+}
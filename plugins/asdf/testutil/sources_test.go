@@ -0,0 +1,133 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutil_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// getSourceJSON decodes the JSON served at url.
+func getSourceJSON(t *testing.T, url string, value any) {
+	t.Helper()
+
+	request, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, http.NoBody)
+	require.NoError(t, err)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.NoError(t, json.NewDecoder(response.Body).Decode(value))
+}
+
+func TestJSONIndexSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keyed index", func(t *testing.T) {
+		t.Parallel()
+
+		source := testutil.StartVersionSource(t, testutil.NewKeyedIndexSource(func(version string) any {
+			return map[string]string{"date": version + "-date"}
+		}), []string{"0.13.0", "0.14.0"})
+
+		var index map[string]map[string]string
+		getSourceJSON(t, source.URL(), &index)
+		require.Equal(t, map[string]map[string]string{
+			"0.13.0": {"date": "0.13.0-date"},
+			"0.14.0": {"date": "0.14.0-date"},
+		}, index)
+	})
+
+	t.Run("list index is newest first", func(t *testing.T) {
+		t.Parallel()
+
+		source := testutil.StartVersionSource(t, testutil.NewListIndexSource(func(version string) any {
+			return map[string]string{"version": "v" + version}
+		}), []string{"20.0.0", "22.1.0"})
+
+		var index []map[string]string
+		getSourceJSON(t, source.URL(), &index)
+		require.Equal(t, []map[string]string{{"version": "v22.1.0"}, {"version": "v20.0.0"}}, index)
+
+		source.SetupVersions([]string{"24.0.0"})
+		getSourceJSON(t, source.URL(), &index)
+		require.Equal(t, []map[string]string{{"version": "v24.0.0"}}, index)
+	})
+}
+
+func TestListingSource(t *testing.T) {
+	t.Parallel()
+
+	source := testutil.StartVersionSource(t, testutil.NewListingSource(func(version string) string {
+		return "sdk-" + version + ".tar.gz"
+	}, 2), []string{"1.0.0", "1.1.0", "1.2.0"})
+
+	type page struct {
+		NextPageToken string `json:"nextPageToken"`
+		Items         []struct {
+			Name string `json:"name"`
+		} `json:"items"`
+	}
+
+	var names []string
+
+	token := ""
+
+	for range 3 {
+		var current page
+		getSourceJSON(t, source.URL()+"?prefix=sdk-&pageToken="+token, &current)
+
+		for _, item := range current.Items {
+			names = append(names, item.Name)
+		}
+
+		if token = current.NextPageToken; token == "" {
+			break
+		}
+	}
+
+	require.Empty(t, token)
+	require.Equal(t, []string{"sdk-1.0.0.tar.gz", "sdk-1.1.0.tar.gz", "sdk-1.2.0.tar.gz"}, names)
+
+	var filtered page
+	getSourceJSON(t, source.URL()+"?prefix=other", &filtered)
+	require.Empty(t, filtered.Items)
+}
+
+func TestGitHubSource(t *testing.T) {
+	t.Parallel()
+
+	tags := testutil.StartVersionSource(t, testutil.NewGitHubTagsSource("example", "tool", "v"),
+		[]string{"1.0.0", "1.1.0"})
+
+	listed, err := tags.Client().GetTags(t.Context(), "https://github.com/example/tool")
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1.0.0", "v1.1.0"}, listed)
+
+	releases := testutil.StartVersionSource(t, testutil.NewGitHubReleasesSource("example", "tool", ""),
+		[]string{"2.0.0"})
+
+	listed, err = releases.Client().GetReleases(t.Context(), "https://github.com/example/tool")
+	require.NoError(t, err)
+	require.Equal(t, []string{"2.0.0"}, listed)
+}
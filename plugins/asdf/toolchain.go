@@ -0,0 +1,322 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	// errCommandFailed is returned when a helper command exits unsuccessfully.
+	errCommandFailed = errors.New("command failed")
+	// errDefaultPackagesFailed is returned when default packages fail to install in strict mode.
+	errDefaultPackagesFailed = errors.New("default packages failed to install")
+)
+
+// ReadDefaultPackagesFile reads a default packages file such as
+// .default-cloud-sdk-components from the home directory. Blank lines and "#"
+// comments are ignored. It returns nil when there is no such file. The working
+// directory is never consulted, as a checkout must not choose what gets
+// installed.
+func ReadDefaultPackagesFile(name string) ([]string, error) {
+	home, err := osUserHomeDir()
+	if err != nil {
+		return nil, nil //nolint:nilerr // without a home directory there are no defaults
+	}
+
+	return ReadPackagesFile(filepath.Join(home, name))
+}
+
+// ReadPackagesFile reads the default packages file at path, ignoring blank lines
+// and "#" comments. A "#" only starts a comment at the start of a line or after
+// whitespace, as specs such as "github:user/repo#v1.2.0" or "...#egg=name"
+// contain one. It returns nil when the file does not exist.
+func ReadPackagesFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	packages := make([]string, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := stripPackageComment(scanner.Text())
+		if line = strings.TrimSpace(line); line != "" {
+			packages = append(packages, line)
+		}
+	}
+
+	Logger().Debug("read default packages", "file", path, "packages", packages)
+
+	return packages, scanner.Err()
+}
+
+// stripPackageComment returns line without its "#" comment, which starts at
+// the first "#" that begins the line or follows whitespace.
+func stripPackageComment(line string) string {
+	for i := range len(line) {
+		if line[i] == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+
+	return line
+}
+
+// InstallDefaultPackages runs name with args followed by the fields of each
+// package line, e.g. "npm install -g <pkg>". Every line is installed separately
+// so that one broken package does not prevent the others from being installed.
+// Failures are logged; they are only returned when strict is set.
+func InstallDefaultPackages(
+	ctx context.Context,
+	packages []string,
+	strict bool,
+	env map[string]string,
+	name string,
+	args ...string,
+) error {
+	var failures []error
+
+	for _, line := range packages {
+		commandArgs := append(slices.Clone(args), strings.Fields(line)...)
+
+		if err := RunCommand(ctx, env, name, commandArgs...); err != nil {
+			Errf("Failed to install default package %s: %v", line, err)
+
+			failures = append(failures, err)
+
+			continue
+		}
+
+		Msgf("Installed default package %s", line)
+	}
+
+	if !strict || len(failures) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %d of %d: %w", errDefaultPackagesFailed, len(failures), len(packages), errors.Join(failures...))
+}
+
+// RunCommand runs name with args and the extra environment variables, streaming
+// stdout to stderr. List variables such as PATH are prepended to, not replaced.
+// On failure the captured stderr is included in the error.
+func RunCommand(ctx context.Context, env map[string]string, name string, args ...string) error {
+	cmd := execCommandContext(ctx, name, args...)
+	cmd.Env = mergeCommandEnv(cmd.Env, env)
+
+	var stderr bytes.Buffer
+
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = &stderr
+
+	Logger().DebugContext(ctx, "running command", "command", name, "args", args)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s %s: %w: %s",
+			errCommandFailed, filepath.Base(name), strings.Join(args, " "), err,
+			strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// mergeCommandEnv returns base, or os.Environ() when base is nil, with env merged
+// over it. List variables such as PATH are prepended to, others replaced.
+func mergeCommandEnv(base []string, env map[string]string) []string {
+	if base == nil {
+		base = os.Environ()
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(env)) {
+		if IsListEnvVar(key) {
+			base = PrependEnvList(base, key, strings.Split(env[key], string(os.PathListSeparator))...)
+
+			continue
+		}
+
+		base = append(base, key+"="+env[key])
+	}
+
+	return base
+}
+
+// ToolchainBinary returns the path of binary inside an installed version of
+// tool. The version pinned in .tool-versions is preferred, otherwise the newest
+// installed version is used. It returns "" when no installed version provides it.
+func ToolchainBinary(tool, binary string) string {
+	layout, err := CurrentLayout()
+	if err != nil {
+		return ""
+	}
+
+	entries, err := os.ReadDir(filepath.Join(layout.InstallsDir, tool))
+	if err != nil {
+		return ""
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && !IsInstallLeftover(entry.Name()) {
+			versions = append(versions, entry.Name())
+		}
+	}
+
+	SortVersions(versions)
+	slices.Reverse(versions)
+
+	if pinned := pinnedToolVersion(tool); pinned != "" {
+		versions = append([]string{pinned}, versions...)
+	}
+
+	for _, version := range versions {
+		path := filepath.Join(layout.InstallPath(tool, version), binary)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// ToolVersionEnv returns the variable selecting the version of tool, e.g.
+// ASDF_GOLANG_VERSION or ASDF_GITHUB_CLI_VERSION.
+func ToolVersionEnv(tool string) string {
+	return "ASDF_" + strings.ToUpper(strings.ReplaceAll(tool, "-", "_")) + "_VERSION"
+}
+
+// legacyToolVersion returns the version in the nearest of the legacy version
+// files of plugin, e.g. .nvmrc, in dir or its parents, or "" when none pins one.
+func legacyToolVersion(plugin Plugin, dir string) (string, error) {
+	filenames := plugin.ListLegacyFilenames()
+	if len(filenames) == 0 {
+		return "", nil
+	}
+
+	for ; ; dir = filepath.Dir(dir) {
+		for _, filename := range filenames {
+			path := filepath.Join(dir, filename)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+
+			version, err := plugin.ParseLegacyFile(path)
+			if errors.Is(err, ErrNoLegacyPin) {
+				continue
+			}
+
+			if err != nil {
+				return "", fmt.Errorf("reading %s: %w", path, err)
+			}
+
+			if version != "" {
+				return version, nil
+			}
+		}
+
+		if filepath.Dir(dir) == dir {
+			return "", nil
+		}
+	}
+}
+
+// pinnedToolVersion returns the version of tool pinned in the nearest tool
+// versions file mentioning it (see ToolVersionsFiles), without creating any
+// file. Variables are expanded when enabled, and a pin that cannot be expanded
+// is treated as missing.
+func pinnedToolVersion(tool string) string {
+	cwd, err := osGetwd()
+	if err != nil {
+		cwd = ""
+	}
+
+	for _, path := range ToolVersionsFiles(cwd) {
+		raw, ok := readToolVersionPins(path)[tool]
+		if !ok {
+			continue
+		}
+
+		version, err := ExpandToolVersion(raw)
+		if err != nil {
+			return ""
+		}
+
+		return version
+	}
+
+	return ""
+}
+
+// SelectedToolVersions returns the version selected for every tool pinned in
+// the tool versions files of the working directory, the nearest file
+// mentioning a tool winning, with ASDF_<TOOL>_VERSION overriding the version
+// of installed tools. Pins that cannot be expanded are left out. Project
+// keywords are not resolved (see ResolveToolVersion).
+func SelectedToolVersions() (map[string]string, error) {
+	cwd, err := osGetwd()
+	if err != nil {
+		return nil, err
+	}
+
+	selected := map[string]string{}
+	seen := map[string]bool{}
+
+	for _, path := range ToolVersionsFiles(cwd) {
+		for tool, raw := range readToolVersionPins(path) {
+			if seen[tool] {
+				continue
+			}
+
+			seen[tool] = true
+
+			version, err := ExpandToolVersion(raw)
+			if err != nil {
+				Logger().Warn("skipping tool version that cannot be expanded", "file", path, "tool", tool, "error", err)
+
+				continue
+			}
+
+			selected[tool] = version
+		}
+	}
+
+	installed, err := InstalledTools()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tool := range installed {
+		if version := os.Getenv(ToolVersionEnv(tool)); version != "" {
+			selected[tool] = version
+		}
+	}
+
+	return selected, nil
+}
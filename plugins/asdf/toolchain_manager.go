@@ -0,0 +1,156 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+type (
+	// Toolchain is a tool bootstrapped with `asdf install` before building another.
+	Toolchain struct {
+		// Tool is the asdf tool name, e.g. "golang".
+		Tool string
+		// Version is the version resolved from the project .tool-versions.
+		Version string
+		// Dir is the directory `asdf install` runs in, the working directory when empty.
+		Dir string
+	}
+
+	// ToolchainManager ensures each toolchain at most once per process and data
+	// directory. Concurrent callers wait for the first one, and concurrent
+	// processes serialize on a lock file in the data layout.
+	ToolchainManager struct {
+		install func(ctx context.Context, toolchain Toolchain) error
+		ensured map[string]*toolchainEnsure
+		mu      sync.Mutex
+	}
+
+	// toolchainEnsure is a toolchain being, or already, ensured.
+	toolchainEnsure struct {
+		err  error
+		done chan struct{}
+	}
+)
+
+// toolchains is the ToolchainManager of the process.
+var toolchains = NewToolchainManager(asdfInstallToolchain) //nolint:gochecknoglobals // per-process memoization
+
+// NewToolchainManager returns a ToolchainManager bootstrapping toolchains with install.
+func NewToolchainManager(install func(ctx context.Context, toolchain Toolchain) error) *ToolchainManager {
+	return &ToolchainManager{
+		install: install,
+		ensured: make(map[string]*toolchainEnsure),
+	}
+}
+
+// Toolchains returns the ToolchainManager of the process.
+func Toolchains() *ToolchainManager {
+	return toolchains
+}
+
+// Ensure installs toolchain unless this process already did. A failed attempt
+// is reported to the callers waiting for it and retried by later callers.
+func (manager *ToolchainManager) Ensure(ctx context.Context, toolchain Toolchain) error {
+	layout, err := CurrentLayout()
+	if err != nil {
+		return err
+	}
+
+	key := layout.DataDir + "\x00" + toolchain.Tool + "\x00" + toolchain.Version
+
+	manager.mu.Lock()
+
+	if ensure, ok := manager.ensured[key]; ok {
+		manager.mu.Unlock()
+
+		select {
+		case <-ensure.done:
+			return ensure.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	ensure := &toolchainEnsure{done: make(chan struct{})}
+	manager.ensured[key] = ensure
+	manager.mu.Unlock()
+
+	ensure.err = manager.ensureLocked(ctx, toolchain)
+	if ensure.err != nil {
+		manager.mu.Lock()
+		delete(manager.ensured, key)
+		manager.mu.Unlock()
+	}
+
+	close(ensure.done)
+
+	return ensure.err
+}
+
+// ensureLocked installs toolchain holding its lock in the data layout.
+func (manager *ToolchainManager) ensureLocked(ctx context.Context, toolchain Toolchain) error {
+	lock, err := AcquireLock(
+		ctx,
+		"toolchain-"+toolchain.Tool+"-"+toolchain.Version,
+		fmt.Sprintf("bootstrap toolchain %s %s", toolchain.Tool, toolchain.Version),
+		false,
+	)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if unlockErr := lock.Release(); unlockErr != nil {
+			Logger().Warn("failed to release toolchain lock", "error", unlockErr)
+		}
+	}()
+
+	return manager.install(ctx, toolchain)
+}
+
+// asdfInstallToolchain runs `asdf install <tool>` in toolchain.Dir. Nothing is
+// installed when asdf is not in PATH.
+func asdfInstallToolchain(ctx context.Context, toolchain Toolchain) error {
+	asdfPath, err := execLookPath("asdf")
+	if err != nil {
+		Logger().DebugContext(ctx, "asdf not found, skipping toolchain bootstrap", "tool", toolchain.Tool)
+
+		return nil
+	}
+
+	Logger().InfoContext(ctx, "bootstrapping toolchain", "tool", toolchain.Tool, "version", toolchain.Version,
+		"dir", toolchain.Dir)
+
+	cmd := execCommandContext(ctx, asdfPath, "install", toolchain.Tool)
+
+	cmd.Dir = toolchain.Dir
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if toolchain.Dir == "" {
+			return fmt.Errorf("running asdf install %s: %w", toolchain.Tool, err)
+		}
+
+		return fmt.Errorf("running asdf install %s in %s: %w", toolchain.Tool, toolchain.Dir, err)
+	}
+
+	return nil
+}
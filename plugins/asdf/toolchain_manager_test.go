@@ -0,0 +1,103 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+func TestToolchainManagerEnsuresOnce(t *testing.T) {
+	t.Setenv("ASDF_DATA_DIR", t.TempDir())
+
+	var installs sync.Map
+
+	manager := asdf.NewToolchainManager(func(_ context.Context, toolchain asdf.Toolchain) error {
+		count, _ := installs.LoadOrStore(toolchain.Tool, new(atomic.Int32))
+		count.(*atomic.Int32).Add(1)
+
+		return nil
+	})
+
+	var group sync.WaitGroup
+
+	for range 16 {
+		for _, tool := range []string{"golang", "nodejs"} {
+			group.Go(func() {
+				require.NoError(t, manager.Ensure(t.Context(), asdf.Toolchain{Tool: tool, Version: "1.0.0"}))
+			})
+		}
+	}
+
+	group.Wait()
+
+	for _, tool := range []string{"golang", "nodejs"} {
+		count, ok := installs.Load(tool)
+		require.True(t, ok)
+		require.Equal(t, int32(1), count.(*atomic.Int32).Load(), tool)
+	}
+}
+
+func TestToolchainManagerRetriesFailures(t *testing.T) {
+	t.Setenv("ASDF_DATA_DIR", t.TempDir())
+
+	errInstall := errors.New("install failed")
+
+	var attempts atomic.Int32
+
+	manager := asdf.NewToolchainManager(func(context.Context, asdf.Toolchain) error {
+		if attempts.Add(1) == 1 {
+			return errInstall
+		}
+
+		return nil
+	})
+
+	toolchain := asdf.Toolchain{Tool: "golang", Version: "1.0.0"}
+
+	require.ErrorIs(t, manager.Ensure(t.Context(), toolchain), errInstall)
+	require.NoError(t, manager.Ensure(t.Context(), toolchain))
+	require.NoError(t, manager.Ensure(t.Context(), toolchain))
+	require.Equal(t, int32(2), attempts.Load())
+}
+
+func TestToolchainManagerPerDataDir(t *testing.T) {
+	var attempts atomic.Int32
+
+	manager := asdf.NewToolchainManager(func(context.Context, asdf.Toolchain) error {
+		attempts.Add(1)
+
+		return nil
+	})
+
+	toolchain := asdf.Toolchain{Tool: "golang", Version: "1.0.0"}
+
+	t.Setenv("ASDF_DATA_DIR", t.TempDir())
+	require.NoError(t, manager.Ensure(t.Context(), toolchain))
+
+	t.Setenv("ASDF_DATA_DIR", t.TempDir())
+	require.NoError(t, manager.Ensure(t.Context(), toolchain))
+
+	require.Equal(t, int32(2), attempts.Load())
+	require.Same(t, asdf.Toolchains(), asdf.Toolchains())
+}
@@ -0,0 +1,237 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+func TestReadDefaultPackagesFile(t *testing.T) {
+	const name = ".default-cloud-sdk-components"
+
+	t.Run("reads the home directory", func(t *testing.T) {
+		wd, home := t.TempDir(), t.TempDir()
+		asdf.MockOSForTests(t, wd, home)
+
+		require.NoError(t, os.WriteFile(filepath.Join(home, name),
+			[]byte("# cluster access\ngke-gcloud-auth-plugin\n\n  kubectl  # pinned by gcloud\n"),
+			asdf.CommonFilePermission))
+
+		packages, err := asdf.ReadDefaultPackagesFile(name)
+		require.NoError(t, err)
+		require.Equal(t, []string{"gke-gcloud-auth-plugin", "kubectl"}, packages)
+	})
+
+	t.Run("ignores the working directory", func(t *testing.T) {
+		wd, home := t.TempDir(), t.TempDir()
+		asdf.MockOSForTests(t, wd, home)
+
+		require.NoError(t, os.WriteFile(filepath.Join(wd, name), []byte("beta\n"), asdf.CommonFilePermission))
+
+		packages, err := asdf.ReadDefaultPackagesFile(name)
+		require.NoError(t, err)
+		require.Empty(t, packages)
+	})
+
+	t.Run("returns nothing without a file", func(t *testing.T) {
+		asdf.MockOSForTests(t, t.TempDir(), t.TempDir())
+
+		packages, err := asdf.ReadDefaultPackagesFile(name)
+		require.NoError(t, err)
+		require.Empty(t, packages)
+	})
+}
+
+func TestInstallDefaultPackages(t *testing.T) {
+	packages := []string{"typescript", "broken-pkg", "eslint@9 --no-fund"}
+	npm := filepath.Join("/opt", "node", "bin", "npm")
+
+	t.Run("installs each package and tolerates failures", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "npm.log")
+		t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+		t.Setenv("ASDF_MOCK_COMMAND_LOG_ENV", "NODE")
+		t.Setenv("ASDF_MOCK_COMMAND_FAIL_ARG", "broken-pkg")
+
+		asdf.MockExecForTests(t, nil)
+
+		err := asdf.InstallDefaultPackages(t.Context(), packages, false,
+			map[string]string{"NODE": "/opt/node/bin/node"}, npm, "install", "-g")
+		require.NoError(t, err)
+
+		logged, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		require.Equal(t,
+			"npm install -g typescript\nNODE=/opt/node/bin/node\n"+
+				"npm install -g broken-pkg\nNODE=/opt/node/bin/node\n"+
+				"npm install -g eslint@9 --no-fund\nNODE=/opt/node/bin/node\n",
+			string(logged))
+	})
+
+	t.Run("strict mode reports failures after installing the rest", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "npm.log")
+		t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+		t.Setenv("ASDF_MOCK_COMMAND_FAIL_ARG", "broken-pkg")
+
+		asdf.MockExecForTests(t, nil)
+
+		err := asdf.InstallDefaultPackages(t.Context(), packages, true, nil, npm, "install", "-g")
+		require.ErrorIs(t, err, asdf.ErrDefaultPackagesFailedForTests())
+		require.ErrorIs(t, err, asdf.ErrCommandFailedForTests())
+		require.Contains(t, err.Error(), "1 of 3")
+		require.Contains(t, err.Error(), "mock failure for broken-pkg")
+
+		logged, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		require.Contains(t, string(logged), "npm install -g eslint@9 --no-fund\n")
+	})
+
+	t.Run("pip installs a packages file and skips failures", func(t *testing.T) {
+		dir := t.TempDir()
+		logFile := filepath.Join(dir, "pip.log")
+		t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+		t.Setenv("ASDF_MOCK_COMMAND_FAIL_ARG", "not-on-pypi")
+
+		asdf.MockExecForTests(t, nil)
+
+		packagesFile := filepath.Join(dir, ".default-python-packages")
+		require.NoError(t, os.WriteFile(packagesFile,
+			[]byte("# tooling\nnot-on-pypi\n\nruff==0.6.9\n"), asdf.CommonFilePermission))
+
+		pythonPackages, err := asdf.ReadPackagesFile(packagesFile)
+		require.NoError(t, err)
+
+		python := filepath.Join("/opt", "python", "bin", "python3")
+		require.NoError(t, asdf.InstallDefaultPackages(t.Context(), pythonPackages, false, nil,
+			python, "-m", "pip", "install"))
+
+		logged, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		require.Equal(t,
+			"python3 -m pip install not-on-pypi\npython3 -m pip install ruff==0.6.9\n",
+			string(logged))
+	})
+
+	t.Run("puts the install first on PATH", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "npm.log")
+		t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+		t.Setenv("ASDF_MOCK_COMMAND_LOG_ENV", "PATH")
+		t.Setenv("PATH", "/usr/bin")
+
+		asdf.MockExecForTests(t, nil)
+
+		binDir := filepath.Join("/opt", "node", "bin")
+		require.NoError(t, asdf.InstallDefaultPackages(t.Context(), packages[:1], true,
+			map[string]string{"PATH": binDir}, npm, "install", "-g"))
+
+		logged, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		require.Contains(t, string(logged), "PATH="+binDir+string(os.PathListSeparator)+"/usr/bin\n")
+	})
+}
+
+func TestReadPackagesFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".default-npm-packages")
+
+	packages, err := asdf.ReadPackagesFile(path)
+	require.NoError(t, err)
+	require.Nil(t, packages)
+
+	require.NoError(t, os.WriteFile(path, []byte("# linters\neslint\n\ntypescript # compiler\n"), asdf.CommonFilePermission))
+
+	packages, err = asdf.ReadPackagesFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"eslint", "typescript"}, packages)
+
+	require.NoError(t, os.WriteFile(path, []byte("  # pinned from git\n"+
+		"github:user/repo#v1.2.0\n"+
+		"git+https://github.com/user/repo.git#semver:^1 # range\n"+
+		"git+https://example.com/pkg.git#egg=pkg\t# egg\n"), asdf.CommonFilePermission))
+
+	packages, err = asdf.ReadPackagesFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"github:user/repo#v1.2.0",
+		"git+https://github.com/user/repo.git#semver:^1",
+		"git+https://example.com/pkg.git#egg=pkg",
+	}, packages)
+}
+
+func TestRunCommand(t *testing.T) {
+	t.Run("passes arguments and environment", func(t *testing.T) {
+		logFile := filepath.Join(t.TempDir(), "command.log")
+		t.Setenv("ASDF_MOCK_COMMAND_LOG", logFile)
+		t.Setenv("ASDF_MOCK_COMMAND_LOG_ENV", "CLOUDSDK_CORE_DISABLE_PROMPTS")
+
+		asdf.MockExecForTests(t, nil)
+
+		err := asdf.RunCommand(t.Context(),
+			map[string]string{"CLOUDSDK_CORE_DISABLE_PROMPTS": "1"},
+			"/opt/google-cloud-sdk/bin/gcloud", "components", "install", "kubectl", "--quiet")
+		require.NoError(t, err)
+
+		logged, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		require.Equal(t,
+			"gcloud components install kubectl --quiet\nCLOUDSDK_CORE_DISABLE_PROMPTS=1\n",
+			string(logged))
+	})
+
+	t.Run("captures stderr on failure", func(t *testing.T) {
+		t.Setenv("ASDF_MOCK_COMMAND_STDERR", "ERROR: (gcloud.components.install) unknown component [nope]")
+
+		asdf.MockExecForTests(t, nil)
+
+		err := asdf.RunCommand(t.Context(), nil, "gcloud", "components", "install", "nope", "--quiet")
+		require.ErrorIs(t, err, asdf.ErrCommandFailedForTests())
+		require.Contains(t, err.Error(), "gcloud components install nope --quiet")
+		require.Contains(t, err.Error(), "unknown component [nope]")
+	})
+}
+
+func TestToolchainBinary(t *testing.T) {
+	dataDir, wd := t.TempDir(), t.TempDir()
+	t.Setenv("ASDF_DATA_DIR", dataDir)
+	t.Setenv("ASDF_INSTALLS_DIR", "")
+	asdf.MockOSForTests(t, wd, wd)
+
+	for _, version := range []string{"3.11.9", "3.12.4", "3.9.1"} {
+		bin := filepath.Join(dataDir, "installs", "python", version, "bin")
+		require.NoError(t, os.MkdirAll(bin, asdf.CommonDirectoryPermission))
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "python3"), nil, asdf.CommonExecutablePermission))
+	}
+
+	binary := filepath.Join("bin", "python3")
+
+	require.Equal(t,
+		filepath.Join(dataDir, "installs", "python", "3.12.4", "bin", "python3"),
+		asdf.ToolchainBinary("python", binary))
+
+	require.NoError(t, os.WriteFile(filepath.Join(wd, ".tool-versions"),
+		[]byte("python 3.11.9\n"), asdf.CommonFilePermission))
+	require.Equal(t,
+		filepath.Join(dataDir, "installs", "python", "3.11.9", "bin", "python3"),
+		asdf.ToolchainBinary("python", binary))
+
+	require.Empty(t, asdf.ToolchainBinary("python", filepath.Join("bin", "pip9")))
+	require.Empty(t, asdf.ToolchainBinary("ruby", binary))
+}
@@ -0,0 +1,197 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// ExpandToolVersionsEnv enables ${VAR} and ${VAR:-default} expansion in the
+	// version field of .tool-versions entries when set to 1.
+	ExpandToolVersionsEnv = "ASDF_EXPAND_TOOL_VERSIONS"
+	// ToolVersionsFilenameEnv overrides the name of tool versions files, as in asdf.
+	ToolVersionsFilenameEnv = "ASDF_DEFAULT_TOOL_VERSIONS_FILENAME"
+	// DefaultToolVersionsFilename is the name of tool versions files unless overridden.
+	DefaultToolVersionsFilename = ".tool-versions"
+)
+
+var (
+	// errToolVersionUndefinedVariable is returned when a version references an unset variable without a default.
+	errToolVersionUndefinedVariable = errors.New("undefined variable")
+	// errToolVersionMalformedVariable is returned when a version contains an unterminated or invalid ${...} reference.
+	errToolVersionMalformedVariable = errors.New("malformed variable reference")
+
+	// toolVersionVariableName matches the variable names accepted inside ${...}.
+	toolVersionVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// ToolVersionsFilename returns the name of tool versions files:
+// ASDF_DEFAULT_TOOL_VERSIONS_FILENAME, or .tool-versions when unset.
+func ToolVersionsFilename() string {
+	if name := os.Getenv(ToolVersionsFilenameEnv); name != "" {
+		return name
+	}
+
+	return DefaultToolVersionsFilename
+}
+
+// ToolVersionsFiles returns the tool versions files consulted from dir,
+// nearest first: the one in dir and in each of its parents up to the
+// filesystem root, then the one in the home directory. An empty dir only
+// yields the home file. The files need not exist.
+func ToolVersionsFiles(dir string) []string {
+	name := ToolVersionsFilename()
+
+	var files []string
+
+	for dir != "" {
+		files = append(files, filepath.Join(dir, name))
+
+		if filepath.Dir(dir) == dir {
+			break
+		}
+
+		dir = filepath.Dir(dir)
+	}
+
+	if home, err := osUserHomeDir(); err == nil {
+		if homeFile := filepath.Join(home, name); !slices.Contains(files, homeFile) {
+			files = append(files, homeFile)
+		}
+	}
+
+	return files
+}
+
+// readToolVersionPins returns the raw version pinned for each tool in the tool
+// versions file at path, the first one of lines listing several; a missing or
+// unreadable file pins nothing.
+func readToolVersionPins(path string) map[string]string {
+	pins := map[string]string{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return pins
+	}
+
+	for _, line := range VersionFileLines(string(data)) {
+		line, _, _ = strings.Cut(line, "#")
+
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			continue
+		}
+
+		if _, ok := pins[parts[0]]; !ok {
+			pins[parts[0]] = parts[1]
+		}
+	}
+
+	return pins
+}
+
+// ExpandToolVersionsEnabled reports whether ASDF_EXPAND_TOOL_VERSIONS=1 is set.
+func ExpandToolVersionsEnabled() bool {
+	return os.Getenv(ExpandToolVersionsEnv) == "1"
+}
+
+// ExpandToolVersion expands ${VAR} and ${VAR:-default} references in a
+// .tool-versions version field when ASDF_EXPAND_TOOL_VERSIONS=1, and returns
+// raw unchanged otherwise. A default is used when the variable is unset or
+// empty and may itself reference other variables; an unset variable without a
+// default is an error.
+func ExpandToolVersion(raw string) (string, error) {
+	if !ExpandToolVersionsEnabled() || !strings.Contains(raw, "${") {
+		return raw, nil
+	}
+
+	return expandVariables(raw)
+}
+
+// expandVariables replaces every ${...} reference of value.
+func expandVariables(value string) (string, error) {
+	var expanded strings.Builder
+
+	for {
+		start := strings.Index(value, "${")
+		if start < 0 {
+			expanded.WriteString(value)
+
+			return expanded.String(), nil
+		}
+
+		expanded.WriteString(value[:start])
+
+		end := closingBrace(value, start+2)
+		if end < 0 {
+			return "", fmt.Errorf("%w: unterminated %q", errToolVersionMalformedVariable, value[start:])
+		}
+
+		replacement, err := expandVariable(value[start+2 : end])
+		if err != nil {
+			return "", err
+		}
+
+		expanded.WriteString(replacement)
+
+		value = value[end+1:]
+	}
+}
+
+// expandVariable resolves the body of a single ${...} reference.
+func expandVariable(reference string) (string, error) {
+	name, fallback, hasDefault := strings.Cut(reference, ":-")
+	if !toolVersionVariableName.MatchString(name) {
+		return "", fmt.Errorf("%w: ${%s}", errToolVersionMalformedVariable, reference)
+	}
+
+	value, set := os.LookupEnv(name)
+	switch {
+	case hasDefault && value == "":
+		return expandVariables(fallback)
+	case !set:
+		return "", fmt.Errorf("%w: %s", errToolVersionUndefinedVariable, name)
+	default:
+		return value, nil
+	}
+}
+
+// closingBrace returns the index of the brace closing the reference whose body
+// starts at from, accounting for nested references, or -1 when there is none.
+func closingBrace(value string, from int) int {
+	depth := 0
+
+	for i := from; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], "${"):
+			depth++
+			i++
+		case value[i] == '}' && depth == 0:
+			return i
+		case value[i] == '}':
+			depth--
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,136 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// TestExpandToolVersion verifies ${VAR} and ${VAR:-default} expansion of .tool-versions versions.
+func TestExpandToolVersion(t *testing.T) {
+	t.Setenv(asdf.ExpandToolVersionsEnv, "1")
+	t.Setenv("UAP_TEST_TF_VERSION", "1.8.0")
+	t.Setenv("UAP_TEST_EMPTY", "")
+	t.Setenv("UAP_TEST_MINOR", "29")
+
+	tests := []struct {
+		err      error
+		name     string
+		raw      string
+		expected string
+	}{
+		{name: "literal", raw: "1.7.5", expected: "1.7.5"},
+		{name: "variable", raw: "${UAP_TEST_TF_VERSION}", expected: "1.8.0"},
+		{name: "set variable ignores default", raw: "${UAP_TEST_TF_VERSION:-1.7.5}", expected: "1.8.0"},
+		{name: "unset variable uses default", raw: "${UAP_TEST_UNSET:-1.7.5}", expected: "1.7.5"},
+		{name: "empty variable uses default", raw: "${UAP_TEST_EMPTY:-latest}", expected: "latest"},
+		{name: "empty variable without default", raw: "${UAP_TEST_EMPTY}", expected: ""},
+		{name: "surrounding text", raw: "1.${UAP_TEST_MINOR}.3", expected: "1.29.3"},
+		{name: "nested default", raw: "${UAP_TEST_UNSET:-${UAP_TEST_TF_VERSION}}", expected: "1.8.0"},
+		{name: "bare dollar", raw: "$UAP_TEST_TF_VERSION", expected: "$UAP_TEST_TF_VERSION"},
+		{name: "unset variable", raw: "${UAP_TEST_UNSET}", err: asdf.ErrToolVersionUndefinedVariableForTests()},
+		{
+			name: "unset variable in default",
+			raw:  "${UAP_TEST_UNSET:-${UAP_TEST_ALSO_UNSET}}",
+			err:  asdf.ErrToolVersionUndefinedVariableForTests(),
+		},
+		{name: "unterminated", raw: "${UAP_TEST_TF_VERSION", err: asdf.ErrToolVersionMalformedVariableForTests()},
+		{name: "invalid name", raw: "${1VERSION}", err: asdf.ErrToolVersionMalformedVariableForTests()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := asdf.ExpandToolVersion(tt.raw)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+// TestExpandToolVersionDisabled verifies versions are left verbatim unless expansion is enabled.
+func TestExpandToolVersionDisabled(t *testing.T) {
+	t.Setenv(asdf.ExpandToolVersionsEnv, "")
+	t.Setenv("UAP_TEST_TF_VERSION", "1.8.0")
+
+	require.False(t, asdf.ExpandToolVersionsEnabled())
+
+	for _, raw := range []string{"${UAP_TEST_TF_VERSION}", "${UAP_TEST_UNSET}"} {
+		version, err := asdf.ExpandToolVersion(raw)
+		require.NoError(t, err)
+		require.Equal(t, raw, version)
+	}
+}
+
+// TestSelectedToolVersionsNested verifies the nearest tool versions file
+// mentioning a tool wins, walking from nested directories up to the home
+// file, and that the filename and per-tool environment overrides apply.
+func TestSelectedToolVersionsNested(t *testing.T) {
+	t.Setenv(asdf.ToolVersionsFilenameEnv, "")
+	t.Setenv(asdf.ToolVersionEnv("jq"), "")
+	t.Setenv(asdf.ToolVersionEnv("mock"), "")
+
+	root, home, dataDir := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv(asdf.DataDirEnv, dataDir)
+	service := filepath.Join(root, "services", "api")
+	writeTree(t, root, map[string]string{
+		".tool-versions":                "golang 1.22.0\njq 1.7.1  # monorepo pin\n",
+		"services/.tool-versions":       "# services only pin mock\nmock 20.11.0\n",
+		"services/api/.tool-versions":   "golang 1.23.0\n",
+		"services/api/.custom-versions": "golang 1.21.0\n",
+	})
+	writeTree(t, home, map[string]string{".tool-versions": "terraform 1.8.0\njq 1.6\n"})
+	writeTree(t, dataDir, map[string]string{"installs/jq/1.8.0/bin/jq": ""})
+	asdf.MockOSForTests(t, service, home)
+
+	require.Equal(t, []string{
+		filepath.Join(service, ".tool-versions"),
+		filepath.Join(root, "services", ".tool-versions"),
+		filepath.Join(root, ".tool-versions"),
+	}, asdf.ToolVersionsFiles(service)[:3])
+
+	selected, err := asdf.SelectedToolVersions()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"golang": "1.23.0", "mock": "20.11.0", "jq": "1.7.1", "terraform": "1.8.0",
+	}, selected)
+
+	version, err := asdf.ResolveToolVersion(&mockPlugin{})
+	require.NoError(t, err)
+	require.Equal(t, "20.11.0", version, "resolution agrees with the selection")
+
+	t.Setenv(asdf.ToolVersionEnv("jq"), "1.8.0")
+
+	selected, err = asdf.SelectedToolVersions()
+	require.NoError(t, err)
+	require.Equal(t, "1.8.0", selected["jq"])
+
+	t.Setenv(asdf.ToolVersionsFilenameEnv, ".custom-versions")
+
+	selected, err = asdf.SelectedToolVersions()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"golang": "1.21.0", "jq": "1.8.0"}, selected)
+}
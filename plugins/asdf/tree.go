@@ -0,0 +1,308 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	// TreeFile describes a regular file or symlink below a hashed directory.
+	TreeFile struct {
+		// Path is relative to the hashed directory.
+		Path string `json:"path"`
+		// Hash is the sha256 of the file content, empty for symlinks.
+		Hash string `json:"hash,omitempty"`
+		// LinkTarget is the symlink target, empty for regular files.
+		LinkTarget string `json:"linkTarget,omitempty"`
+		// Size is the file size in bytes, zero for symlinks.
+		Size int64 `json:"size"`
+	}
+
+	// TreeDigest is the result of hashing a directory tree.
+	TreeDigest struct {
+		// Hash is the combined "sha256:" digest of the whole tree.
+		Hash string
+		// Files lists every hashed file in lexical order.
+		Files []TreeFile
+	}
+
+	// TreeChange describes a file present in both trees with different content.
+	TreeChange struct {
+		Old TreeFile `json:"old"`
+		New TreeFile `json:"new"`
+	}
+
+	// TreeDiff is the file-level difference between two trees.
+	TreeDiff struct {
+		Added   []TreeFile   `json:"added"`
+		Removed []TreeFile   `json:"removed"`
+		Changed []TreeChange `json:"changed"`
+	}
+)
+
+// HashTree walks dir in lexical order and hashes every regular file and symlink.
+// Unreadable entries are skipped, and so are the files and directories whose
+// slash-separated relative path or base name matches one of the ignore globs
+// (see HashIgnoreGlobsOf), as is the InstallMetadataFileName at the root of
+// dir, which records when the tree was made. The combined hash covers each relative path
+// followed by the file content, or "path->target" for symlinks, which is the
+// format recorded in tool checksum files.
+func HashTree(dir string, ignore ...string) (TreeDigest, error) {
+	combined := sha256.New()
+
+	var files []TreeFile
+
+	err := filepath.WalkDir(dir, func(path string, dirEntry os.DirEntry, err error) error {
+		if err != nil {
+			Logger().Debug("skipping unreadable path while hashing", "path", path, "error", err)
+
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if relPath == InstallMetadataFileName {
+			return nil
+		}
+
+		if relPath != "." && matchesAnyGlob(filepath.ToSlash(relPath), ignore) {
+			if dirEntry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if dirEntry.IsDir() {
+			return nil
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			Logger().Debug("skipping file without info while hashing", "path", path, "error", err)
+
+			return nil
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				Logger().Debug("skipping unreadable symlink while hashing", "path", path, "error", err)
+
+				return nil
+			}
+
+			combined.Write([]byte(relPath + "->" + target))
+
+			files = append(files, TreeFile{Path: relPath, LinkTarget: target})
+
+			return nil
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		combined.Write([]byte(relPath))
+
+		file, err := os.Open(path)
+		if err != nil {
+			Logger().Debug("skipping unreadable file while hashing", "path", path, "error", err)
+
+			return nil
+		}
+		defer file.Close()
+
+		fileHash := sha256.New()
+
+		size, err := io.Copy(io.MultiWriter(combined, fileHash), file)
+		if err != nil {
+			Logger().Debug("failed to read file while hashing", "path", path, "error", err)
+
+			return nil
+		}
+
+		files = append(files, TreeFile{
+			Path: relPath,
+			Hash: "sha256:" + hex.EncodeToString(fileHash.Sum(nil)),
+			Size: size,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return TreeDigest{}, err
+	}
+
+	return TreeDigest{
+		Hash:  "sha256:" + hex.EncodeToString(combined.Sum(nil)),
+		Files: files,
+	}, nil
+}
+
+// matchesAnyGlob reports whether the slash-separated relPath or its base name
+// matches one of globs.
+func matchesAnyGlob(relPath string, globs []string) bool {
+	for _, glob := range globs {
+		if matched, _ := path.Match(glob, relPath); matched {
+			return true
+		}
+
+		if matched, _ := path.Match(glob, path.Base(relPath)); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DiffTrees compares two file lists as returned by HashTree. Files are matched
+// by relative path; a file is changed when its size, hash or link target differ.
+func DiffTrees(oldFiles, newFiles []TreeFile) TreeDiff {
+	oldByPath := make(map[string]TreeFile, len(oldFiles))
+	for _, file := range oldFiles {
+		oldByPath[file.Path] = file
+	}
+
+	newPaths := make(map[string]bool, len(newFiles))
+
+	diff := TreeDiff{Added: []TreeFile{}, Removed: []TreeFile{}, Changed: []TreeChange{}}
+
+	for _, file := range newFiles {
+		newPaths[file.Path] = true
+
+		old, ok := oldByPath[file.Path]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, file)
+		case old != file:
+			diff.Changed = append(diff.Changed, TreeChange{Old: old, New: file})
+		}
+	}
+
+	for _, file := range oldFiles {
+		if !newPaths[file.Path] {
+			diff.Removed = append(diff.Removed, file)
+		}
+	}
+
+	return diff
+}
+
+// FilterTreeFiles keeps the files located below one of the given relative directories.
+// A "." directory matches every file.
+func FilterTreeFiles(files []TreeFile, dirs []string) []TreeFile {
+	filtered := make([]TreeFile, 0, len(files))
+
+	for _, file := range files {
+		for _, dir := range dirs {
+			dir = filepath.Clean(dir)
+			if dir == "." || strings.HasPrefix(file.Path, dir+string(os.PathSeparator)) {
+				filtered = append(filtered, file)
+
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// IsEmpty reports whether the trees are identical.
+func (diff TreeDiff) IsEmpty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0
+}
+
+// Write renders the diff to out, as indented JSON when asJSON is set and as
+// one "+", "-" or "~" prefixed line per file otherwise.
+func (diff TreeDiff) Write(out io.Writer, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(diff)
+	}
+
+	for _, file := range diff.Added {
+		if _, err := fmt.Fprintf(out, "+ %s (%s)\n", file.Path, describeTreeFile(file)); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range diff.Removed {
+		if _, err := fmt.Fprintf(out, "- %s (%s)\n", file.Path, describeTreeFile(file)); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range diff.Changed {
+		_, err := fmt.Fprintf(out, "~ %s (%s -> %s)\n",
+			change.New.Path, describeTreeFile(change.Old), describeTreeFile(change.New))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// describeTreeFile renders the size and short hash of a file, or its symlink target.
+func describeTreeFile(file TreeFile) string {
+	if file.LinkTarget != "" {
+		return "-> " + file.LinkTarget
+	}
+
+	const shortHashLength = len("sha256:") + 12
+
+	hash := file.Hash
+	if len(hash) > shortHashLength {
+		hash = hash[:shortHashLength]
+	}
+
+	return FormatBytes(file.Size) + ", " + hash
+}
+
+// TreeSize returns the total size of the regular files below root, or of root
+// itself when it is a file. Unreadable entries are left out.
+func TreeSize(root string) int64 {
+	var size int64
+
+	_ = filepath.WalkDir(root, func(_ string, dirEntry os.DirEntry, err error) error {
+		if err != nil || !dirEntry.Type().IsRegular() {
+			return nil //nolint:nilerr // unreadable entries do not count
+		}
+
+		if info, err := dirEntry.Info(); err == nil {
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size
+}
@@ -0,0 +1,193 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// writeTree creates the given files below root; values prefixed with "->" become symlinks.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), asdf.CommonDirectoryPermission))
+
+		if target, ok := bytes.CutPrefix([]byte(content), []byte("->")); ok {
+			require.NoError(t, os.Symlink(string(target), path))
+
+			continue
+		}
+
+		require.NoError(t, os.WriteFile(path, []byte(content), asdf.CommonExecutablePermission))
+	}
+}
+
+func TestHashTree(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"bin/tool":  "binary",
+		"lib/alias": "->../bin/tool",
+	})
+
+	digest, err := asdf.HashTree(root)
+	require.NoError(t, err)
+
+	toolHash := sha256.Sum256([]byte("binary"))
+	require.Equal(t, []asdf.TreeFile{
+		{Path: filepath.Join("bin", "tool"), Hash: "sha256:" + hex.EncodeToString(toolHash[:]), Size: 6},
+		{Path: filepath.Join("lib", "alias"), LinkTarget: "../bin/tool"},
+	}, digest.Files)
+
+	combined := sha256.Sum256([]byte(
+		filepath.Join("bin", "tool") + "binary" + filepath.Join("lib", "alias") + "->../bin/tool",
+	))
+	require.Equal(t, "sha256:"+hex.EncodeToString(combined[:]), digest.Hash)
+}
+
+func TestHashTreeIgnore(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"bin/tool":                   "binary",
+		"lib/__pycache__/mod.pyc":    "cache",
+		"lib/stale.pyc":              "cache",
+		"share/cache/index.json":     "{}",
+		"share/plugins/diff/plugin":  "plugin",
+		"share/plugins/diff/version": "1",
+	})
+
+	digest, err := asdf.HashTree(root, "__pycache__", "*.pyc", "share/plugins")
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(digest.Files))
+	for _, file := range digest.Files {
+		paths = append(paths, filepath.ToSlash(file.Path))
+	}
+
+	require.Equal(t, []string{"bin/tool", "share/cache/index.json"}, paths)
+}
+
+func TestDiffTrees(t *testing.T) {
+	t.Parallel()
+
+	oldRoot, newRoot := t.TempDir(), t.TempDir()
+
+	writeTree(t, oldRoot, map[string]string{
+		"bin/terraform":          "v1",
+		"bin/legacy-helper":      "helper",
+		"share/completion.bash":  "complete -C terraform terraform",
+		"share/docs/README":      "old docs",
+		"lib/current":            "->v1",
+		"share/docs/UNCHANGED":   "same",
+		"share/plugins/provider": "provider",
+	})
+	writeTree(t, newRoot, map[string]string{
+		"bin/terraform":         "v2-longer",
+		"bin/new-helper":        "helper",
+		"share/completion.bash": "complete -C terraform terraform",
+		"share/docs/README":     "new docs",
+		"lib/current":           "->v2",
+		"share/docs/UNCHANGED":  "same",
+	})
+
+	oldDigest, err := asdf.HashTree(oldRoot)
+	require.NoError(t, err)
+
+	newDigest, err := asdf.HashTree(newRoot)
+	require.NoError(t, err)
+
+	diff := asdf.DiffTrees(oldDigest.Files, newDigest.Files)
+
+	paths := func(files []asdf.TreeFile) []string {
+		result := make([]string, 0, len(files))
+		for _, file := range files {
+			result = append(result, filepath.ToSlash(file.Path))
+		}
+
+		return result
+	}
+
+	require.Equal(t, []string{"bin/new-helper"}, paths(diff.Added))
+	require.Equal(t, []string{"bin/legacy-helper", "share/plugins/provider"}, paths(diff.Removed))
+
+	changed := make([]string, 0, len(diff.Changed))
+	for _, change := range diff.Changed {
+		changed = append(changed, filepath.ToSlash(change.New.Path))
+	}
+
+	require.Equal(t, []string{"bin/terraform", "lib/current", "share/docs/README"}, changed)
+	require.Equal(t, int64(2), diff.Changed[0].Old.Size)
+	require.Equal(t, int64(9), diff.Changed[0].New.Size)
+	require.Equal(t, "v2", diff.Changed[1].New.LinkTarget)
+	require.Equal(t, diff.Changed[2].Old.Size, diff.Changed[2].New.Size)
+	require.NotEqual(t, diff.Changed[2].Old.Hash, diff.Changed[2].New.Hash)
+	require.False(t, diff.IsEmpty())
+
+	t.Run("text output", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		require.NoError(t, diff.Write(&out, false))
+
+		require.Contains(t, out.String(), "+ "+filepath.Join("bin", "new-helper")+" (6 B, sha256:")
+		require.Contains(t, out.String(), "- "+filepath.Join("bin", "legacy-helper"))
+		require.Contains(t, out.String(), "~ "+filepath.Join("lib", "current")+" (-> v1 -> -> v2)")
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		require.NoError(t, diff.Write(&out, true))
+
+		var decoded asdf.TreeDiff
+		require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+		require.Equal(t, diff, decoded)
+	})
+
+	t.Run("bin paths only", func(t *testing.T) {
+		t.Parallel()
+
+		binDiff := asdf.DiffTrees(
+			asdf.FilterTreeFiles(oldDigest.Files, []string{"bin"}),
+			asdf.FilterTreeFiles(newDigest.Files, []string{"bin"}),
+		)
+
+		require.Equal(t, []string{"bin/new-helper"}, paths(binDiff.Added))
+		require.Equal(t, []string{"bin/legacy-helper"}, paths(binDiff.Removed))
+		require.Len(t, binDiff.Changed, 1)
+	})
+
+	t.Run("identical trees", func(t *testing.T) {
+		t.Parallel()
+
+		require.True(t, asdf.DiffTrees(oldDigest.Files, oldDigest.Files).IsEmpty())
+	})
+}
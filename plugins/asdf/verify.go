@@ -0,0 +1,114 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumSidecarSuffix is appended to a download path to name the file holding
+// its expected SHA256, in sha256sum format.
+const ChecksumSidecarSuffix = ".sha256"
+
+// errSizeMismatch is returned when a file does not have the expected size.
+var errSizeMismatch = errors.New("size mismatch")
+
+// ExpectedFile describes the published size and checksum of a download.
+type ExpectedFile struct {
+	// SHA256 is the hex encoded checksum.
+	SHA256 string
+	// Size is the size in bytes, or zero when unknown.
+	Size int64
+}
+
+// VerifyFile checks the size and SHA256 of the file at path.
+func VerifyFile(path string, expected ExpectedFile) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if expected.Size > 0 && info.Size() != expected.Size {
+		return fmt.Errorf("%w: %s is %d bytes, expected %d", errSizeMismatch, path, info.Size(), expected.Size)
+	}
+
+	return VerifySHA256(path, expected.SHA256)
+}
+
+// WriteChecksumSidecar records sha256 next to path so it can be re-verified later.
+func WriteChecksumSidecar(path, sha256 string) error {
+	line := fmt.Sprintf("%s  %s\n", sha256, filepath.Base(path))
+
+	if err := os.WriteFile(path+ChecksumSidecarSuffix, []byte(line), CommonFilePermission); err != nil {
+		return fmt.Errorf("writing checksum for %s: %w", path, err)
+	}
+
+	if IsGroupShared(filepath.Dir(path)) {
+		shareEntry(path+ChecksumSidecarSuffix, groupReadExecute)
+	}
+
+	return nil
+}
+
+// VerifyChecksumSidecar verifies path against the checksum recorded by
+// WriteChecksumSidecar. The error wraps os.ErrNotExist when none was recorded.
+func VerifyChecksumSidecar(path string) error {
+	data, err := os.ReadFile(path + ChecksumSidecarSuffix)
+	if err != nil {
+		return fmt.Errorf("reading checksum for %s: %w", path, err)
+	}
+
+	return VerifySHA256(path, strings.TrimSpace(string(data)))
+}
+
+// DownloadVerifiedFile downloads url to destPath unless a cached copy already
+// matches expected. A cached copy that does not match is deleted and downloaded
+// again; a fresh download that does not match is deleted and reported. The
+// expected checksum is recorded with WriteChecksumSidecar.
+func DownloadVerifiedFile(ctx context.Context, url, destPath string, expected ExpectedFile) error {
+	if _, err := os.Stat(destPath); err == nil {
+		err := VerifyFile(destPath, expected)
+		if err == nil {
+			Msgf("Using verified cached download %s", filepath.Base(destPath))
+
+			return WriteChecksumSidecar(destPath, expected.SHA256)
+		}
+
+		Logger().WarnContext(ctx, "cached download failed verification, downloading again",
+			"path", destPath, "error", err)
+
+		if err := os.Remove(destPath); err != nil {
+			return fmt.Errorf("removing corrupt download %s: %w", destPath, err)
+		}
+	}
+
+	if err := DownloadFile(ctx, url, destPath); err != nil {
+		return err
+	}
+
+	if err := VerifyFile(destPath, expected); err != nil {
+		_ = os.Remove(destPath)
+
+		return fmt.Errorf("verifying %s: %w", url, err)
+	}
+
+	return WriteChecksumSidecar(destPath, expected.SHA256)
+}
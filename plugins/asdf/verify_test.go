@@ -0,0 +1,129 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package asdf_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// servePayload serves payload and counts the requests it receives.
+func servePayload(t *testing.T, payload []byte) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+
+		_, _ = w.Write(payload)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func expectedFileFor(payload []byte) asdf.ExpectedFile {
+	sum := sha256.Sum256(payload)
+
+	return asdf.ExpectedFile{SHA256: hex.EncodeToString(sum[:]), Size: int64(len(payload))}
+}
+
+func TestDownloadVerifiedFile(t *testing.T) {
+	t.Parallel()
+
+	payload := []byte("genuine zig tarball")
+	expected := expectedFileFor(payload)
+
+	t.Run("replaces a tampered cached file", func(t *testing.T) {
+		t.Parallel()
+
+		server, requests := servePayload(t, payload)
+
+		destPath := filepath.Join(t.TempDir(), "zig.tar.xz")
+		require.NoError(t, os.WriteFile(destPath, []byte("tampered zig tarbal"), asdf.CommonFilePermission))
+
+		require.NoError(t, asdf.DownloadVerifiedFile(t.Context(), server.URL, destPath, expected))
+		require.Equal(t, int32(1), requests.Load())
+
+		content, err := os.ReadFile(destPath)
+		require.NoError(t, err)
+		require.Equal(t, payload, content)
+		require.NoError(t, asdf.VerifyChecksumSidecar(destPath))
+	})
+
+	t.Run("replaces a truncated cached file", func(t *testing.T) {
+		t.Parallel()
+
+		server, requests := servePayload(t, payload)
+
+		destPath := filepath.Join(t.TempDir(), "zig.tar.xz")
+		require.NoError(t, os.WriteFile(destPath, payload[:5], asdf.CommonFilePermission))
+
+		require.NoError(t, asdf.DownloadVerifiedFile(t.Context(), server.URL, destPath, expected))
+		require.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("reuses a valid cached file", func(t *testing.T) {
+		t.Parallel()
+
+		server, requests := servePayload(t, payload)
+
+		destPath := filepath.Join(t.TempDir(), "zig.tar.xz")
+		require.NoError(t, os.WriteFile(destPath, payload, asdf.CommonFilePermission))
+
+		require.NoError(t, asdf.DownloadVerifiedFile(t.Context(), server.URL, destPath, expected))
+		require.Zero(t, requests.Load())
+		require.NoError(t, asdf.VerifyChecksumSidecar(destPath))
+	})
+
+	t.Run("deletes a download that does not match", func(t *testing.T) {
+		t.Parallel()
+
+		server, _ := servePayload(t, []byte("rewritten by a mirror"))
+
+		destPath := filepath.Join(t.TempDir(), "zig.tar.xz")
+
+		err := asdf.DownloadVerifiedFile(t.Context(), server.URL, destPath, expected)
+		require.ErrorIs(t, err, asdf.ErrSizeMismatchForTests())
+		require.NoFileExists(t, destPath)
+		require.NoFileExists(t, destPath+asdf.ChecksumSidecarSuffix)
+	})
+}
+
+func TestVerifyChecksumSidecar(t *testing.T) {
+	t.Parallel()
+
+	destPath := filepath.Join(t.TempDir(), "zig.tar.xz")
+	require.NoError(t, os.WriteFile(destPath, []byte("archive"), asdf.CommonFilePermission))
+
+	require.ErrorIs(t, asdf.VerifyChecksumSidecar(destPath), os.ErrNotExist)
+
+	require.NoError(t, asdf.WriteChecksumSidecar(destPath, expectedFileFor([]byte("archive")).SHA256))
+	require.NoError(t, asdf.VerifyChecksumSidecar(destPath))
+
+	require.NoError(t, os.WriteFile(destPath, []byte("tampered"), asdf.CommonFilePermission))
+	require.Error(t, asdf.VerifyChecksumSidecar(destPath))
+}
@@ -17,16 +17,15 @@ package plugins
 
 import (
 	"context"
+	_ "embed"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
-	"sort"
 	"strings"
 
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
@@ -44,6 +43,18 @@ var (
 	errAWSDownloadFailed = errors.New("download failed")
 	// errAWSUnsupportedInstallOS is returned when Install is invoked on an unsupported OS.
 	errAWSUnsupportedInstallOS = errors.New("unsupported install platform")
+
+	// awscliPlatforms are the "<os>/<arch>" pairs AWS publishes installers for;
+	// musl Linux builds from the source archive on any architecture.
+	awscliPlatforms = []string{ //nolint:gochecknoglobals // read-only lookup table
+		"darwin/amd64", "darwin/arm64", "linux/amd64", "linux/arm64", "windows/amd64",
+	}
+
+	// awscliPublicKey is the AWS CLI team key the Linux installers are signed with,
+	// as published in the AWS CLI installation guide.
+	//
+	//go:embed awscli-public-key.asc
+	awscliPublicKey []byte //nolint:gochecknoglobals // embedded signing key
 )
 
 const (
@@ -51,19 +62,55 @@ const (
 	awscliGitRepoURL = "https://github.com/aws/aws-cli"
 	// awscliDownloadBaseURL is the base URL for downloading AWS CLI packages.
 	awscliDownloadBaseURL = "https://awscli.amazonaws.com"
+	// awscliLibcEnv overrides the detected C library (glibc or musl).
+	awscliLibcEnv = "ASDF_AWSCLI_LIBC"
+	// awscliPGPKeyEnv replaces the embedded public key, as a path to the key or the armored key itself.
+	awscliPGPKeyEnv = "ASDF_AWSCLI_PGP_KEY"
+	// awscliSkipVerifyEnv skips the signature verification of the Linux installers when set to 1.
+	awscliSkipVerifyEnv = "ASDF_AWSCLI_SKIP_VERIFY"
 )
 
 type (
 	// AwscliPlugin implements the asdf.Plugin interface for AWS CLI.
 	AwscliPlugin struct {
 		githubClient *github.Client
+		// DownloadURL is the base URL of the AWS CLI installers.
+		DownloadURL string
+	}
+
+	// awscliRuntime describes the platform an AWS CLI installer is selected for.
+	awscliRuntime struct {
+		os   string
+		arch string
+		libc string
 	}
 )
 
+// currentAwscliRuntime returns the runtime of the running system, with the
+// operating system overridden by asdf.ForcePlatformEnv.
+func currentAwscliRuntime() awscliRuntime {
+	arch, err := asdf.GetArch()
+	if err != nil {
+		arch = runtime.GOARCH
+	}
+
+	goos := runtime.GOOS
+	if forced := os.Getenv(asdf.ForcePlatformEnv); forced != "" {
+		goos = strings.ToLower(forced)
+	}
+
+	return awscliRuntime{
+		os:   goos,
+		arch: arch,
+		libc: asdf.GetLibc(awscliLibcEnv),
+	}
+}
+
 // NewAwscliPlugin creates a new AWS CLI plugin instance.
 func NewAwscliPlugin() asdf.Plugin {
 	return &AwscliPlugin{
 		githubClient: github.NewClient(),
+		DownloadURL:  awscliDownloadBaseURL,
 	}
 }
 
@@ -74,6 +121,10 @@ func (*AwscliPlugin) Name() string {
 
 // ListBinPaths returns the binary paths for AWS CLI installations.
 func (*AwscliPlugin) ListBinPaths() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join("aws-cli", "Amazon", "AWSCLIV2")
+	}
+
 	return "bin"
 }
 
@@ -89,7 +140,7 @@ func (*AwscliPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy AWS CLI version file.
 func (*AwscliPlugin) ParseLegacyFile(path string) (string, error) {
-	return asdf.ReadLegacyVersionFile(path)
+	return asdf.ReadVersionFile(path)
 }
 
 // Uninstall removes an AWS CLI installation.
@@ -97,16 +148,31 @@ func (*AwscliPlugin) Uninstall(_ context.Context, installPath string) error {
 	return os.RemoveAll(installPath)
 }
 
+// InstallsInPlace reports that the AWS CLI installs in place, as its bin
+// directory holds absolute symlinks and, on musl, a virtual environment.
+func (*AwscliPlugin) InstallsInPlace() bool {
+	return true
+}
+
 // Help returns help information for the AWS CLI plugin.
 func (*AwscliPlugin) Help() asdf.PluginHelp {
 	return asdf.PluginHelp{
 		Overview: `AWS CLI - The AWS Command Line Interface.
 This plugin downloads pre-built AWS CLI v2 binaries.`,
-		Deps: `Linux: glibc, groff, less
-macOS: Rosetta 2 (for Apple Silicon)`,
-		Config: `Environment variables:
-  AWS_CONFIG_FILE - Override AWS awscliConfig file location
-  AWS_SHARED_CREDENTIALS_FILE - Override credentials file location`,
+		Deps: `Linux (glibc): groff, less
+Linux (musl, e.g. Alpine): python3 with venv and pip, plus the build dependencies
+  of the AWS CLI source distribution (gcc, musl-dev, libffi-dev, cmake)
+macOS: Rosetta 2 (for Apple Silicon)
+Windows: msiexec`,
+		HelpConfigEntries: []asdf.ConfigEntry{
+			{Name: "AWS_CONFIG_FILE", Description: "Override AWS awscliConfig file location"},
+			{Name: "AWS_SHARED_CREDENTIALS_FILE", Description: "Override credentials file location"},
+			{Name: "ASDF_AWSCLI_LIBC", Description: "Force the Linux C library (glibc or musl) instead of detecting it"},
+			{Name: awscliPGPKeyEnv, Description: "Public key the Linux installer signature is verified with, as a path or armored key"},
+			{Name: awscliSkipVerifyEnv, Default: "0", Description: "Set to 1 to skip the installer signature verification"},
+		},
+		Config: `On musl systems the official binary installer does not run, so the AWS CLI is
+built from the source distribution with pip into a virtualenv.`,
 		Links: `Homepage: https://aws.amazon.com/cli/
 Documentation: https://docs.aws.amazon.com/cli/
 Source: https://github.com/aws/aws-cli`,
@@ -129,9 +195,7 @@ func (plugin *AwscliPlugin) ListAll(ctx context.Context) ([]string, error) {
 		}
 	}
 
-	sort.Slice(versions, func(i, j int) bool {
-		return asdf.CompareVersions(versions[i], versions[j]) < 0
-	})
+	asdf.SortVersions(versions)
 
 	stable := asdf.FilterVersions(versions, func(v string) bool {
 		return !asdf.IsPrereleaseVersion(v)
@@ -160,35 +224,104 @@ func (plugin *AwscliPlugin) LatestStable(ctx context.Context, query string) (str
 	)
 }
 
-// getDownloadURL returns the download URL for the specified version.
-func (*AwscliPlugin) getDownloadURL(version string) (string, error) {
-	switch runtime.GOOS {
+// getDownloadURL returns the download URL for the specified version and runtime.
+func (plugin *AwscliPlugin) getDownloadURL(version string, target awscliRuntime) (string, error) {
+	switch target.os {
 	case "linux":
-		switch runtime.GOARCH {
+		if target.libc == asdf.LibcMusl {
+			return fmt.Sprintf("%s/awscli-%s.tar.gz", plugin.DownloadURL, version), nil
+		}
+
+		switch target.arch {
 		case "amd64":
 			return fmt.Sprintf(
 				"%s/awscli-exe-linux-x86_64-%s.zip",
-				awscliDownloadBaseURL,
+				plugin.DownloadURL,
 				version,
 			), nil
 		case "arm64":
 			return fmt.Sprintf(
 				"%s/awscli-exe-linux-aarch64-%s.zip",
-				awscliDownloadBaseURL,
+				plugin.DownloadURL,
 				version,
 			), nil
 		}
 
 	case "darwin":
-		return fmt.Sprintf("%s/AWSCLIV2-%s.pkg", awscliDownloadBaseURL, version), nil
+		return fmt.Sprintf("%s/AWSCLIV2-%s.pkg", plugin.DownloadURL, version), nil
+
+	case "windows":
+		if target.arch == "amd64" {
+			return fmt.Sprintf("%s/AWSCLIV2-%s.msi", plugin.DownloadURL, version), nil
+		}
+	}
+
+	return "", asdf.UnsupportedPlatformError(errAWSUnsupportedPlatform, target.os+"/"+target.arch, awscliPlatforms)
+}
+
+// ArtifactNames returns the name of the installer Download stores for version.
+func (plugin *AwscliPlugin) ArtifactNames(version string) ([]string, error) {
+	url, err := plugin.getDownloadURL(version, currentAwscliRuntime())
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{filepath.Base(url)}, nil
+}
+
+// ResolveArtifacts returns the installer Download fetches for version.
+func (plugin *AwscliPlugin) ResolveArtifacts(_ context.Context, version string) ([]asdf.Artifact, error) {
+	url, err := plugin.getDownloadURL(version, currentAwscliRuntime())
+	if err != nil {
+		return nil, err
 	}
 
-	return "", fmt.Errorf("%w: %s/%s", errAWSUnsupportedPlatform, runtime.GOOS, runtime.GOARCH)
+	return []asdf.Artifact{{Name: filepath.Base(url), URL: url}}, nil
+}
+
+// DescribeArtifacts returns the platform the installer is selected for, as
+// named by the installers, their format and the path of aws in the install.
+// The macOS package is universal and musl systems build the source archive.
+func (plugin *AwscliPlugin) DescribeArtifacts(version string) (asdf.ArtifactDescription, error) {
+	target := currentAwscliRuntime()
+
+	url, err := plugin.getDownloadURL(version, target)
+	if err != nil {
+		return asdf.ArtifactDescription{}, err
+	}
+
+	platform, arch := target.os, target.arch
+
+	switch {
+	case target.os == "linux" && target.libc == asdf.LibcMusl:
+		platform, arch = "linux-musl", "any"
+	case target.os == "linux":
+		arch = strings.NewReplacer("amd64", "x86_64", "arm64", "aarch64").Replace(arch)
+	case target.os == "darwin":
+		arch = "universal"
+	}
+
+	archiveType := strings.TrimPrefix(path.Ext(url), ".")
+	if strings.HasSuffix(url, ".tar.gz") {
+		archiveType = "tar.gz"
+	}
+
+	binary := "aws"
+	if target.os == "windows" {
+		binary += ".exe"
+	}
+
+	return asdf.ArtifactDescription{
+		Platform:    platform,
+		Arch:        arch,
+		ArchiveType: archiveType,
+		BinaryPath:  path.Join(filepath.ToSlash(plugin.ListBinPaths()), binary),
+	}, nil
 }
 
 // Download downloads the specified AWS CLI version.
 func (plugin *AwscliPlugin) Download(ctx context.Context, version, downloadPath string) error {
-	url, err := plugin.getDownloadURL(version)
+	url, err := plugin.getDownloadURL(version, currentAwscliRuntime())
 	if err != nil {
 		return err
 	}
@@ -196,10 +329,25 @@ func (plugin *AwscliPlugin) Download(ctx context.Context, version, downloadPath
 	filename := filepath.Base(url)
 
 	filePath := filepath.Join(downloadPath, filename)
+	signed := strings.HasSuffix(filename, ".zip")
+
 	if info, err := os.Stat(filePath); err == nil && info.Size() > 1024 {
-		asdf.Msgf("Using cached download for awscli %s", version)
+		// Only the signed Linux installer can be trusted from the cache, after
+		// its signature is checked again; anything else is downloaded afresh.
+		if signed {
+			err := verifyAwscliSignature(ctx, url, filePath)
+			if err == nil {
+				asdf.Msgf("Using cached download for awscli %s", version)
 
-		return nil
+				return extractAwscliInstaller(filePath, downloadPath)
+			}
+
+			asdf.Logger().WarnContext(ctx, "discarding cached awscli installer", "path", filePath, "error", err)
+		}
+
+		if err := os.Remove(filePath); err != nil {
+			return fmt.Errorf("removing cached awscli installer: %w", err)
+		}
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
@@ -207,60 +355,131 @@ func (plugin *AwscliPlugin) Download(ctx context.Context, version, downloadPath
 		return fmt.Errorf("creating request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := asdf.HTTPClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("downloading awscli: %w", err)
 	}
 	defer resp.Body.Close()
 
+	asdf.LogHTTPResponse(ctx, resp)
+
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("%w with status: %d", errAWSDownloadFailed, resp.StatusCode)
 	}
 
-	outFile, err := os.OpenFile(
-		filePath,
-		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
-		asdf.CommonFilePermission,
-	)
+	if err := asdf.SaveResponse(ctx, resp, filePath); err != nil {
+		return err
+	}
+
+	if signed {
+		if err := verifyAwscliSignature(ctx, url, filePath); err != nil {
+			_ = os.Remove(filePath)
+
+			return err
+		}
+
+		return extractAwscliInstaller(filePath, downloadPath)
+	}
+
+	return nil
+}
+
+// extractAwscliInstaller extracts the verified Linux installer zip at filePath
+// into downloadPath.
+func extractAwscliInstaller(filePath, downloadPath string) error {
+	if err := asdf.ExtractZip(filePath, downloadPath); err != nil {
+		return fmt.Errorf("extracting zip: %w", err)
+	}
+
+	return nil
+}
+
+// verifyAwscliSignature verifies the Linux installer at filePath against the
+// detached signature published at url.sig, unless ASDF_AWSCLI_SKIP_VERIFY is 1.
+func verifyAwscliSignature(ctx context.Context, url, filePath string) error {
+	if os.Getenv(awscliSkipVerifyEnv) == "1" {
+		asdf.Logger().WarnContext(ctx, "skipping awscli signature verification", "path", filePath)
+
+		return nil
+	}
+
+	publicKey, err := awscliVerificationKey()
 	if err != nil {
-		return fmt.Errorf("creating file: %w", err)
+		return err
 	}
-	defer outFile.Close()
 
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
-		return fmt.Errorf("writing file: %w", err)
+	signaturePath := filePath + ".sig"
+	defer os.Remove(signaturePath)
+
+	if err := asdf.DownloadFile(ctx, url+".sig", signaturePath); err != nil {
+		return fmt.Errorf("downloading awscli signature: %w", err)
 	}
 
-	if strings.HasSuffix(filename, ".zip") {
-		err := asdf.ExtractZip(filePath, downloadPath)
-		if err != nil {
-			return fmt.Errorf("extracting zip: %w", err)
-		}
+	signature, err := os.ReadFile(signaturePath)
+	if err != nil {
+		return err
+	}
+
+	err = asdf.VerifyPGPSignature(filePath, signature, publicKey)
+	if errors.Is(err, asdf.ErrPGPKeyInvalid) {
+		return fmt.Errorf("verifying awscli installer (set %s to the current AWS CLI team key): %w", awscliPGPKeyEnv, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("verifying awscli installer (set %s=1 to skip): %w", awscliSkipVerifyEnv, err)
 	}
 
 	return nil
 }
 
+// awscliVerificationKey returns the key set by ASDF_AWSCLI_PGP_KEY, either
+// armored or as a path, or else the embedded AWS CLI team key.
+func awscliVerificationKey() ([]byte, error) {
+	override := os.Getenv(awscliPGPKeyEnv)
+
+	switch {
+	case override == "":
+		return awscliPublicKey, nil
+	case strings.Contains(override, "-----BEGIN PGP PUBLIC KEY BLOCK-----"):
+		return []byte(override), nil
+	}
+
+	publicKey, err := os.ReadFile(override)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", awscliPGPKeyEnv, err)
+	}
+
+	return publicKey, nil
+}
+
 // Install installs AWS CLI from the downloaded files.
 func (plugin *AwscliPlugin) Install(
 	ctx context.Context,
 	version, downloadPath, installPath string,
 ) error {
-	switch runtime.GOOS {
+	target := currentAwscliRuntime()
+
+	switch target.os {
 	case "linux":
+		if target.libc == asdf.LibcMusl {
+			return plugin.installLinuxMusl(ctx, version, downloadPath, installPath)
+		}
+
 		return plugin.installLinux(ctx, downloadPath, installPath)
 	case "darwin":
 		return plugin.installDarwin(ctx, version, downloadPath, installPath)
+	case "windows":
+		return plugin.installWindows(ctx, version, downloadPath, installPath)
 	default:
-		return fmt.Errorf("%w: %s", errAWSUnsupportedInstallOS, runtime.GOOS)
+		return fmt.Errorf("%w: %s", errAWSUnsupportedInstallOS, target.os)
 	}
 }
 
-// installLinux installs AWS CLI on Linux.
+// installLinux installs AWS CLI on glibc based Linux with the bundled installer.
 func (*AwscliPlugin) installLinux(ctx context.Context, downloadPath, installPath string) error {
 	installerPath := filepath.Join(downloadPath, "aws", "install")
 
-	err := os.Chmod(installerPath, asdf.CommonDirectoryPermission)
+	err := os.Chmod(installerPath, asdf.CommonExecutablePermission)
 	if err != nil {
 		return fmt.Errorf("making installer executable: %w", err)
 	}
@@ -268,14 +487,70 @@ func (*AwscliPlugin) installLinux(ctx context.Context, downloadPath, installPath
 	binDir := filepath.Join(installPath, "bin")
 	libDir := filepath.Join(installPath, "aws-cli")
 
-	cmd := exec.CommandContext(ctx, installerPath, "-i", libDir, "-b", binDir)
+	err = asdf.RunCommand(ctx, nil, installerPath,
+		"--install-dir", libDir, "--bin-dir", binDir, "--update")
+	if err != nil {
+		return fmt.Errorf("running installer: %w", err)
+	}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	return nil
+}
+
+// installLinuxMusl installs AWS CLI on musl based Linux, where the bundled
+// installer does not run, by building the source distribution with pip into a
+// virtualenv below installPath.
+func (*AwscliPlugin) installLinuxMusl(
+	ctx context.Context,
+	version, downloadPath, installPath string,
+) error {
+	sourcePath := filepath.Join(downloadPath, fmt.Sprintf("awscli-%s.tar.gz", version))
+	venvDir := filepath.Join(installPath, "aws-cli")
+	binDir := filepath.Join(installPath, "bin")
+
+	python := asdf.ToolchainBinary("python", filepath.Join("bin", "python3"))
+	if python == "" {
+		python = "python3"
+	}
+
+	if err := asdf.RunCommand(ctx, nil, python, "-m", "venv", venvDir); err != nil {
+		return fmt.Errorf("creating virtualenv: %w", err)
+	}
+
+	pip := filepath.Join(venvDir, "bin", "pip")
+	if err := asdf.RunCommand(ctx, nil, pip, "install", sourcePath); err != nil {
+		return fmt.Errorf("installing awscli from source: %w", err)
+	}
+
+	if err := os.MkdirAll(binDir, asdf.CommonDirectoryPermission); err != nil {
+		return fmt.Errorf("creating bin directory: %w", err)
+	}
 
-	err = cmd.Run()
+	for _, name := range []string{"aws", "aws_completer"} {
+		err := os.Symlink(filepath.Join(venvDir, "bin", name), filepath.Join(binDir, name))
+		if err != nil {
+			return fmt.Errorf("creating %s symlink: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// installWindows installs AWS CLI on Windows by performing an administrative
+// install of the MSI, which only extracts its files into installPath.
+func (*AwscliPlugin) installWindows(
+	ctx context.Context,
+	version, downloadPath, installPath string,
+) error {
+	msiPath := filepath.Join(downloadPath, fmt.Sprintf("AWSCLIV2-%s.msi", version))
+	targetDir := filepath.Join(installPath, "aws-cli")
+
+	if err := os.MkdirAll(targetDir, asdf.CommonDirectoryPermission); err != nil {
+		return fmt.Errorf("creating install directory: %w", err)
+	}
+
+	err := asdf.RunCommand(ctx, nil, "msiexec", "/a", msiPath, "/qn", "TARGETDIR="+targetDir)
 	if err != nil {
-		return fmt.Errorf("running installer: %w", err)
+		return fmt.Errorf("extracting msi: %w", err)
 	}
 
 	return nil
@@ -297,9 +572,7 @@ func (*AwscliPlugin) installDarwin(
 
 	extractDir := filepath.Join(downloadPath, "extracted")
 
-	cmd := exec.CommandContext(ctx, "pkgutil", "--expand-full", pkgPath, extractDir)
-
-	err = cmd.Run()
+	err = asdf.RunCommand(ctx, nil, "pkgutil", "--expand-full", pkgPath, extractDir)
 	if err != nil {
 		return fmt.Errorf("extracting pkg: %w", err)
 	}
@@ -307,9 +580,7 @@ func (*AwscliPlugin) installDarwin(
 	awsCliSrc := filepath.Join(extractDir, "aws-cli.pkg", "Payload", "aws-cli")
 	awsCliDst := filepath.Join(installPath, "aws-cli")
 
-	cmd = exec.CommandContext(ctx, "cp", "-r", awsCliSrc, awsCliDst)
-
-	err = cmd.Run()
+	err = asdf.RunCommand(ctx, nil, "cp", "-r", awsCliSrc, awsCliDst)
 	if err != nil {
 		return fmt.Errorf("copying aws-cli: %w", err)
 	}
@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// bunPackageJSON is the file whose engines.bun range pins the Bun version.
+const bunPackageJSON = "package.json"
+
+// errBunEngineRangeUnsupported is returned when engines.bun is a range no
+// pinned version or latest query can express.
+var errBunEngineRangeUnsupported = errors.New("unsupported engines.bun range")
+
+// bunEngineRange matches the engines.bun ranges mapped to a latest query:
+// an optional ^, ~ or = operator followed by a version, or a version ending in x or *.
+var bunEngineRange = regexp.MustCompile(`^([~^]|=)?v?(\d+)(?:\.(\d+|[x*]))?(?:\.(\d+|[x*]))?$`)
+
+// BunPlugin implements the asdf.Plugin interface for Bun.
+type BunPlugin struct {
+	*asdf.BinaryPlugin
+}
+
+// NewBunPlugin creates a new bun plugin instance. Releases are tagged
+// "bun-v1.1.8" and each zip asset holds a bun-<platform>-<arch> directory.
+func NewBunPlugin() asdf.Plugin {
+	return &BunPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:       "bun",
+		RepoOwner:  "oven-sh",
+		RepoName:   "bun",
+		BinaryName: "bun",
+
+		TagPrefix:           "bun-",
+		VersionPrefix:       "v",
+		FileNameTemplate:    "bun-{{.Platform}}-{{.Arch}}.zip",
+		BinaryPathInArchive: "bun-{{.Platform}}-{{.Arch}}/bun",
+		ArchMap: map[string]string{
+			"amd64": "x64",
+			"arm64": "aarch64",
+		},
+		HelpDescription: "Bun - A fast all-in-one JavaScript runtime",
+		HelpLink:        "https://bun.sh/",
+		ArchiveType:     "zip",
+	})}
+}
+
+// ListLegacyFilenames returns the version file of setup-bun and package.json,
+// whose engines.bun range selects a version.
+func (*BunPlugin) ListLegacyFilenames() []string {
+	return []string{".bun-version", bunPackageJSON}
+}
+
+// ParseLegacyFile returns the version pinned by a .bun-version file, or the
+// version or latest query matching the engines.bun range of a package.json.
+func (*BunPlugin) ParseLegacyFile(path string) (string, error) {
+	if filepath.Base(path) == bunPackageJSON {
+		return parseBunEngine(path)
+	}
+
+	version, err := asdf.ReadVersionFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	version = strings.TrimPrefix(version, "bun-")
+
+	return strings.TrimPrefix(version, "v"), nil
+}
+
+// parseBunEngine maps the engines.bun range of a package.json to an exact
+// version, or to "latest:<query>" for caret, tilde and wildcard ranges. A
+// package.json without engines.bun pins nothing.
+func parseBunEngine(path string) (string, error) {
+	data, err := asdf.ReadTextFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var manifest struct {
+		Engines struct {
+			Bun string `json:"bun"`
+		} `json:"engines"`
+	}
+
+	if err := json.Unmarshal([]byte(data), &manifest); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	engine := strings.TrimSpace(manifest.Engines.Bun)
+
+	switch engine {
+	case "":
+		return "", nil
+	case "*", "latest", "x":
+		return "latest", nil
+	}
+
+	if minimum, found := strings.CutPrefix(engine, ">="); found && bunEngineRange.MatchString(strings.TrimSpace(minimum)) {
+		return "latest", nil
+	}
+
+	match := bunEngineRange.FindStringSubmatch(engine)
+	if match == nil {
+		return "", fmt.Errorf("%w %q in %s", errBunEngineRangeUnsupported, engine, path)
+	}
+
+	operator, parts := match[1], []string{match[2]}
+
+	for _, part := range match[3:] {
+		if part == "" || part == "x" || part == "*" {
+			break
+		}
+
+		parts = append(parts, part)
+	}
+
+	switch {
+	case operator == "^" && parts[0] == "0" && len(parts) > 1:
+		parts = parts[:2]
+	case operator == "^":
+		parts = parts[:1]
+	case operator == "~" && len(parts) > 2:
+		parts = parts[:2]
+	case len(parts) == 3:
+		return strings.Join(parts, "."), nil
+	}
+
+	return asdf.LatestVersionPrefix + strings.Join(parts, ".") + ".", nil
+}
+
+// Help returns help information for the bun plugin.
+func (plugin *BunPlugin) Help() asdf.PluginHelp {
+	help := plugin.BinaryPlugin.Help()
+	help.Config = `Reads .bun-version files and the engines.bun range of package.json:
+an exact version is pinned as-is, ^, ~ and x ranges select the latest
+matching release and >= or * select the latest release.`
+
+	return help
+}
@@ -16,27 +16,232 @@
 package plugins
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+var (
+	// errCmakeArtifactNotIndexed is returned when the files index of a release does not list the archive.
+	errCmakeArtifactNotIndexed = errors.New("archive not listed in the release files index")
+	// errCmakeNoChecksum is returned when neither the files index nor its hash files give the archive checksum.
+	errCmakeNoChecksum = errors.New("no sha256 for archive")
+)
+
+const (
+	// cmakeIndexURLTemplate is the files-v1.json index Kitware publishes with every release.
+	cmakeIndexURLTemplate = "https://github.com/Kitware/CMake/releases/download/v{{.Version}}/cmake-{{.Version}}-files-v1.json"
+	// cmakeHashAlgorithm names the hash files of the index holding sha256 sums.
+	cmakeHashAlgorithm = "SHA-256"
+)
+
+type (
+	// CmakePlugin implements the asdf.Plugin interface for CMake, installing
+	// the whole release archive, with the modules under share, after verifying
+	// it against the files index of the release.
+	CmakePlugin struct {
+		*asdf.BinaryPlugin
+		// IndexURLTemplate is the URL of the files-v1.json index of a release,
+		// with {{.Version}}. The hash files it lists are resolved relative to it.
+		IndexURLTemplate string
+	}
+
+	// cmakeFilesIndex is the files-v1.json index of a CMake release.
+	cmakeFilesIndex struct {
+		Files     []cmakeIndexedFile `json:"files"`
+		HashFiles []cmakeHashFile    `json:"hashFiles"`
+	}
+
+	// cmakeIndexedFile is a file of a CMake release.
+	cmakeIndexedFile struct {
+		Name         string   `json:"name"`
+		Class        string   `json:"class"`
+		SHA256       string   `json:"sha256"`
+		OS           []string `json:"os"`
+		Architecture []string `json:"architecture"`
+	}
+
+	// cmakeHashFile is a file of "<hash>  <name>" lines covering the release files.
+	cmakeHashFile struct {
+		Name      string   `json:"name"`
+		Algorithm []string `json:"algorithm"`
+	}
+)
+
 // NewCmakePlugin creates a new cmake plugin instance.
 func NewCmakePlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
-		Name:       "cmake",
-		RepoOwner:  "Kitware",
-		RepoName:   "CMake",
-		BinaryName: "cmake",
-
-		FileNameTemplate:    "cmake-{{.Version}}-{{.Platform}}-{{.Arch}}.tar.gz",
-		DownloadURLTemplate: "https://github.com/{{.RepoOwner}}/{{.RepoName}}/releases/download/v{{.Version}}/{{.FileName}}",
-		HelpDescription:     "CMake - Build system generator",
-		HelpLink:            "https://cmake.org/",
-		ArchiveType:         "tar.gz",
-
-		VersionFilter: `^\d+\.\d+\.\d+$`,
-		ArchMap: map[string]string{
-			"amd64": "x86_64",
-			"arm64": "aarch64",
-		},
-	})
+	return &CmakePlugin{
+		BinaryPlugin: asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+			Name:       "cmake",
+			RepoOwner:  "Kitware",
+			RepoName:   "CMake",
+			BinaryName: "cmake",
+
+			FileNameTemplate:    "cmake-{{.Version}}-{{.Platform}}-{{.Arch}}.tar.gz",
+			DownloadURLTemplate: "https://github.com/{{.RepoOwner}}/{{.RepoName}}/releases/download/v{{.Version}}/{{.FileName}}",
+			HelpDescription:     "CMake - Build system generator",
+			HelpLink:            "https://cmake.org/",
+			ArchiveType:         "tar.gz",
+
+			// Release candidates are listed, and only selected by prerelease: queries.
+			VersionFilter: `^\d+\.\d+\.\d+(-rc\d+)?$`,
+			VersionPrefix: "v",
+			OsMap: map[string]string{
+				"linux":  "linux",
+				"darwin": "macos",
+			},
+			ArchMap: map[string]string{
+				"amd64": "x86_64",
+				"arm64": "aarch64",
+				// CMake publishes a single universal archive for macOS.
+				"darwin/amd64": "universal",
+				"darwin/arm64": "universal",
+			},
+		}),
+		IndexURLTemplate: cmakeIndexURLTemplate,
+	}
+}
+
+// ListBinPaths returns the binary paths for CMake installations, inside the
+// CMake.app bundle on macOS.
+func (*CmakePlugin) ListBinPaths() string {
+	if runtime.GOOS == "darwin" {
+		return "CMake.app/Contents/bin"
+	}
+
+	return "bin"
+}
+
+// Download downloads the release archive of version and verifies its sha256
+// against the files index of the release.
+func (plugin *CmakePlugin) Download(ctx context.Context, version, downloadPath string) error {
+	names, err := plugin.ArtifactNames(version)
+	if err != nil {
+		return err
+	}
+
+	if err := plugin.BinaryPlugin.Download(ctx, version, downloadPath); err != nil {
+		return err
+	}
+
+	sum, err := plugin.archiveSHA256(ctx, version, names[0])
+	if err != nil {
+		return err
+	}
+
+	if err := asdf.VerifySHA256(filepath.Join(downloadPath, names[0]), sum); err != nil {
+		return fmt.Errorf("verifying %s: %w", names[0], err)
+	}
+
+	asdf.Msgf("Checksum of %s verified", names[0])
+
+	return nil
+}
+
+// Install extracts the release archive into installPath without its
+// top-level cmake-<version>-<platform> directory.
+func (plugin *CmakePlugin) Install(ctx context.Context, version, downloadPath, installPath string) error {
+	names, err := plugin.ArtifactNames(version)
+	if err != nil {
+		return err
+	}
+
+	archivePath := filepath.Join(downloadPath, names[0])
+	if _, err := os.Stat(archivePath); os.IsNotExist(err) {
+		if err := plugin.Download(ctx, version, downloadPath); err != nil {
+			return err
+		}
+	}
+
+	asdf.Msgf("Installing cmake %s to %s", version, installPath)
+
+	if err := asdf.ExtractTarGzRoot(archivePath, installPath); err != nil {
+		return fmt.Errorf("extracting archive: %w", err)
+	}
+
+	return nil
+}
+
+// archiveSHA256 returns the sha256 of the release file name, as listed in the
+// files index of version or in the SHA-256 hash file the index points to.
+func (plugin *CmakePlugin) archiveSHA256(ctx context.Context, version, name string) (string, error) {
+	indexURL := strings.ReplaceAll(plugin.IndexURLTemplate, "{{.Version}}", version)
+
+	data, err := asdf.DownloadString(ctx, indexURL)
+	if err != nil {
+		return "", fmt.Errorf("downloading cmake files index: %w", err)
+	}
+
+	var index cmakeFilesIndex
+	if err := json.Unmarshal([]byte(data), &index); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", indexURL, err)
+	}
+
+	file, ok := index.file(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s in %s", errCmakeArtifactNotIndexed, name, indexURL)
+	}
+
+	if file.SHA256 != "" {
+		return file.SHA256, nil
+	}
+
+	for _, hashFile := range index.HashFiles {
+		if !slices.Contains(hashFile.Algorithm, cmakeHashAlgorithm) {
+			continue
+		}
+
+		hashFileURL, err := resolveRelativeURL(indexURL, hashFile.Name)
+		if err != nil {
+			return "", err
+		}
+
+		sums, err := asdf.DownloadString(ctx, hashFileURL)
+		if err != nil {
+			return "", fmt.Errorf("downloading cmake checksums: %w", err)
+		}
+
+		for line := range strings.SplitSeq(sums, "\n") {
+			if fields := strings.Fields(line); len(fields) == 2 && fields[1] == name {
+				return fields[0], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w %s in %s", errCmakeNoChecksum, name, indexURL)
+}
+
+// file returns the archive entry of the index named name.
+func (index cmakeFilesIndex) file(name string) (cmakeIndexedFile, bool) {
+	for _, file := range index.Files {
+		if file.Name == name && file.Class == "archive" {
+			return file, true
+		}
+	}
+
+	return cmakeIndexedFile{}, false
+}
+
+// resolveRelativeURL resolves the relative reference ref against base.
+func resolveRelativeURL(base, ref string) (string, error) {
+	baseURL, err := neturl.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s: %w", base, err)
+	}
+
+	refURL, err := neturl.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s: %w", ref, err)
+	}
+
+	return baseURL.ResolveReference(refURL).String(), nil
 }
@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"strings"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// DenoPlugin implements the asdf.Plugin interface for Deno.
+type DenoPlugin struct {
+	*asdf.BinaryPlugin
+}
+
+// NewDenoPlugin creates a new deno plugin instance.
+func NewDenoPlugin() asdf.Plugin {
+	return &DenoPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:       "deno",
+		RepoOwner:  "denoland",
+		RepoName:   "deno",
+		BinaryName: "deno",
+
+		FileNameTemplate: "deno-{{.Arch}}-{{.Platform}}.zip",
+		VersionPrefix:    "v",
+		ArchMap: map[string]string{
+			"amd64": "x86_64",
+			"arm64": "aarch64",
+		},
+		OsMap: map[string]string{
+			"linux":  "unknown-linux-gnu",
+			"darwin": "apple-darwin",
+		},
+		HelpDescription: "Deno - A modern runtime for JavaScript and TypeScript",
+		HelpLink:        "https://deno.com/",
+		ArchiveType:     "zip",
+	})}
+}
+
+// ListLegacyFilenames returns the version file of the dvm version manager.
+func (*DenoPlugin) ListLegacyFilenames() []string {
+	return []string{".dvmrc"}
+}
+
+// ParseLegacyFile returns the version of a .dvmrc file without its "v" prefix.
+func (*DenoPlugin) ParseLegacyFile(path string) (string, error) {
+	version, err := asdf.ReadVersionFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimPrefix(version, "v"), nil
+}
+
+// ExecEnv points DENO_INSTALL_ROOT at the install so that scripts installed
+// with "deno install" land in its bin directory next to deno itself.
+func (*DenoPlugin) ExecEnv(installPath string) map[string]string {
+	return map[string]string{"DENO_INSTALL_ROOT": installPath}
+}
+
+// Help returns help information for the deno plugin.
+func (plugin *DenoPlugin) Help() asdf.PluginHelp {
+	help := plugin.BinaryPlugin.Help()
+	help.Config = `Reads .dvmrc files.
+
+exec-env exports DENO_INSTALL_ROOT (the install path), so scripts installed
+with 'deno install -g' are kept per version and picked up by reshim.`
+
+	return help
+}
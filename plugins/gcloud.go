@@ -20,18 +20,33 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
-	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+// gcloudSettings are the ASDF_GCLOUD_* settings read through asdf.PluginEnv.
+var gcloudSettings = []asdf.EnvSetting{ //nolint:gochecknoglobals // read-only lookup table
+	{
+		Key: "FORCE_MANAGED_PYTHON", Type: asdf.EnvTypeBool, Default: "false",
+		Description: "Install the python toolchain even when a suitable python3 is in PATH",
+	},
+}
+
+// gcloudPlatforms are the "<os>/<arch>" pairs gcloud publishes archives for.
+var gcloudPlatforms = []string{"darwin/amd64", "darwin/arm64", "linux/amd64", "linux/arm64"} //nolint:gochecknoglobals // read-only lookup table
+
+// gcloudPythonVersionRegex matches the output of python3 --version.
+var gcloudPythonVersionRegex = regexp.MustCompile(`^Python (\d+)\.(\d+)`) //nolint:gochecknoglobals // compiled once
+
 var (
 	// errGcloudNoVersionsFound is returned when no gcloud versions are discovered.
 	errGcloudNoVersionsFound = errors.New("no versions found")
@@ -57,12 +72,20 @@ const (
 	gcsDownloadPathTemplate = "/storage/v1/b/%s/o/%s?alt=media"
 	// gcsObjectPrefix is the object path prefix for gcloud SDK downloads.
 	gcsObjectPrefix = "google-cloud-sdk"
+	// gcloudDefaultComponentsFile lists components installed after the SDK, one per line.
+	gcloudDefaultComponentsFile = ".default-cloud-sdk-components"
+	// gcloudMinPythonMinor is the oldest Python 3 minor version gcloud runs on.
+	gcloudMinPythonMinor = 9
 )
 
 type (
 	// GcloudPlugin implements the asdf.Plugin interface for Google Cloud SDK.
 	GcloudPlugin struct {
+		// apiURL is the GCS JSON API object listing of the SDK bucket.
 		apiURL string
+		// ExecOutput runs a command and returns its standard output. The probe
+		// for a system python3 runs through it, through os/exec when nil.
+		ExecOutput func(ctx context.Context, name string, args ...string) ([]byte, error)
 	}
 
 	// gcsResponse represents the GCS API response.
@@ -127,21 +150,95 @@ func (*GcloudPlugin) Name() string {
 	return "gcloud"
 }
 
-// Dependencies returns the list of plugins that must be installed before gcloud.
-func (*GcloudPlugin) Dependencies() []string {
+// Dependencies returns the list of plugins that must be installed before
+// gcloud: the python toolchain, unless CLOUDSDK_PYTHON is set or python3 in
+// PATH is recent enough and ASDF_GCLOUD_FORCE_MANAGED_PYTHON is not set.
+func (plugin *GcloudPlugin) Dependencies() []string {
+	ctx := context.Background()
+
+	if asdf.PluginEnv("gcloud").Bool("FORCE_MANAGED_PYTHON", false) {
+		asdf.Logger().InfoContext(ctx, "installing the python toolchain for gcloud",
+			"reason", asdf.PluginEnv("gcloud").Name("FORCE_MANAGED_PYTHON")+" is set")
+
+		return []string{"python"}
+	}
+
+	if python := os.Getenv("CLOUDSDK_PYTHON"); python != "" {
+		asdf.Logger().InfoContext(ctx, "using CLOUDSDK_PYTHON for gcloud", "python", python)
+
+		return nil
+	}
+
+	version, ok := plugin.systemPythonVersion(ctx)
+	if ok {
+		asdf.Logger().InfoContext(ctx, "using the system python3 for gcloud", "version", version)
+
+		return nil
+	}
+
+	reason := "no python3 in PATH"
+	if version != "" {
+		reason = fmt.Sprintf("python3 %s in PATH is older than 3.%d", version, gcloudMinPythonMinor)
+	}
+
+	asdf.Logger().InfoContext(ctx, "installing the python toolchain for gcloud", "reason", reason)
+
 	return []string{"python"}
 }
 
+// systemPythonVersion returns the major.minor version of python3 in PATH, if
+// any, and whether gcloud supports it.
+func (plugin *GcloudPlugin) systemPythonVersion(ctx context.Context) (string, bool) {
+	execOutput := plugin.ExecOutput
+	if execOutput == nil {
+		execOutput = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			return exec.CommandContext(ctx, name, args...).Output()
+		}
+	}
+
+	output, err := execOutput(ctx, "python3", "--version")
+	if err != nil {
+		return "", false
+	}
+
+	matches := gcloudPythonVersionRegex.FindStringSubmatch(strings.TrimSpace(string(output)))
+	if matches == nil {
+		return "", false
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+
+	return matches[1] + "." + matches[2], major > 3 || (major == 3 && minor >= gcloudMinPythonMinor)
+}
+
 // ListBinPaths returns the binary paths for gcloud installations.
 func (*GcloudPlugin) ListBinPaths() string {
 	return "google-cloud-sdk/bin"
 }
 
-// ExecEnv returns environment variables for gcloud execution.
+// InstallRoot returns the google-cloud-sdk directory the SDK is unpacked into.
+func (*GcloudPlugin) InstallRoot(installPath string) string {
+	return filepath.Join(installPath, "google-cloud-sdk")
+}
+
+// ExecEnv returns environment variables for gcloud execution. CLOUDSDK_PYTHON
+// points at the python toolchain, preferring PYTHON_ROOT, when one is installed
+// and not already set.
 func (*GcloudPlugin) ExecEnv(installPath string) map[string]string {
-	return map[string]string{
+	env := map[string]string{
 		"CLOUDSDK_ROOT_DIR": filepath.Join(installPath, "google-cloud-sdk"),
 	}
+
+	if os.Getenv("CLOUDSDK_PYTHON") == "" {
+		if root := os.Getenv("PYTHON_ROOT"); root != "" {
+			env["CLOUDSDK_PYTHON"] = filepath.Join(root, "bin", "python3")
+		} else if python := asdf.ToolchainBinary("python", filepath.Join("bin", "python3")); python != "" {
+			env["CLOUDSDK_PYTHON"] = python
+		}
+	}
+
+	return env
 }
 
 // ListLegacyFilenames returns legacy version filenames for gcloud.
@@ -151,7 +248,7 @@ func (*GcloudPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy gcloud version file.
 func (*GcloudPlugin) ParseLegacyFile(path string) (string, error) {
-	return asdf.ReadLegacyVersionFile(path)
+	return asdf.ReadVersionFile(path)
 }
 
 // Uninstall removes a gcloud installation.
@@ -159,15 +256,24 @@ func (*GcloudPlugin) Uninstall(_ context.Context, installPath string) error {
 	return os.RemoveAll(installPath)
 }
 
+// InstallsInPlace reports that gcloud installs in place, as the components
+// installed along record the absolute root of the SDK.
+func (*GcloudPlugin) InstallsInPlace() bool {
+	return true
+}
+
 // Help returns help information for the gcloud plugin.
 func (*GcloudPlugin) Help() asdf.PluginHelp {
 	return asdf.PluginHelp{
 		Overview: `Google Cloud SDK (gcloud) - Command-line interface for Google Cloud Platform.
 This plugin downloads the Google Cloud SDK from Google Cloud Storage.`,
-		Deps: `Requires Python 3.8+ to be installed and available in PATH.`,
-		Config: `Environment variables:
-  CLOUDSDK_CONFIG - Override gcloud gcloudConfig directory
-  CLOUDSDK_PYTHON - Override Python interpreter path`,
+		Deps: `Requires Python 3.9+. The python toolchain is installed first unless CLOUDSDK_PYTHON is set
+or python3 in PATH is recent enough.`,
+		HelpConfigEntries: append(asdf.PluginEnv("gcloud").Document(gcloudSettings...).ConfigEntries(),
+			asdf.ConfigEntry{Name: "CLOUDSDK_CONFIG", Description: "Override gcloud gcloudConfig directory"},
+			asdf.ConfigEntry{Name: "CLOUDSDK_PYTHON", Description: "Override Python interpreter path, skipping the python toolchain"}),
+		Config: `Components listed in $HOME/.default-cloud-sdk-components, one per line,
+are installed with 'gcloud components install' after the SDK.`,
 		Links: `Homepage: https://cloud.google.com/sdk
 Documentation: https://cloud.google.com/sdk/docs
 Downloads: https://cloud.google.com/sdk/docs/install`,
@@ -196,11 +302,13 @@ func (plugin *GcloudPlugin) ListAll(ctx context.Context) ([]string, error) {
 			return nil, fmt.Errorf("creating request: %w", err)
 		}
 
-		resp, err := http.DefaultClient.Do(req)
+		resp, err := asdf.HTTPClient().Do(req)
 		if err != nil {
 			return nil, fmt.Errorf("fetching versions: %w", err)
 		}
 
+		asdf.LogHTTPResponse(ctx, resp)
+
 		var gcsResp gcsResponse
 		if err := json.NewDecoder(resp.Body).Decode(&gcsResp); err != nil {
 			resp.Body.Close()
@@ -229,9 +337,7 @@ func (plugin *GcloudPlugin) ListAll(ctx context.Context) ([]string, error) {
 		result = append(result, v)
 	}
 
-	sort.Slice(result, func(i, j int) bool {
-		return asdf.CompareVersions(result[i], result[j]) < 0
-	})
+	asdf.SortVersions(result)
 
 	stable := asdf.FilterVersions(result, func(v string) bool {
 		return !asdf.IsPrereleaseVersion(v)
@@ -262,34 +368,95 @@ func (plugin *GcloudPlugin) LatestStable(ctx context.Context, query string) (str
 
 // getObjectName returns the GCS object name for the specified version.
 func (*GcloudPlugin) getObjectName(version string) (string, error) {
+	platform, err := gcloudPlatform()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("google-cloud-sdk-%s-%s.tar.gz", version, platform), nil
+}
+
+// gcloudPlatform returns the "<os>-<arch>" pair naming the archive of the
+// running platform, e.g. "darwin-arm".
+func gcloudPlatform() (string, error) {
 	var platform string
 
+	arch, err := asdf.GetArch()
+	if err != nil {
+		return "", err
+	}
+
 	switch runtime.GOOS {
 	case "linux":
-		switch runtime.GOARCH {
+		switch arch {
 		case "amd64":
 			platform = "linux-x86_64"
 		case "arm64":
 			platform = "linux-arm"
 		default:
-			return "", fmt.Errorf("%w: %s", errGcloudUnsupportedArch, runtime.GOARCH)
+			return "", fmt.Errorf("%w: %s", errGcloudUnsupportedArch, arch)
 		}
 
 	case "darwin":
-		switch runtime.GOARCH {
+		switch arch {
 		case "amd64":
 			platform = "darwin-x86_64"
 		case "arm64":
 			platform = "darwin-arm"
 		default:
-			return "", fmt.Errorf("%w: %s", errGcloudUnsupportedArch, runtime.GOARCH)
+			return "", fmt.Errorf("%w: %s", errGcloudUnsupportedArch, arch)
 		}
 
 	default:
-		return "", fmt.Errorf("%w: %s", errGcloudUnsupportedPlatform, runtime.GOOS)
+		return "", asdf.UnsupportedPlatformError(errGcloudUnsupportedPlatform, runtime.GOOS, gcloudPlatforms)
 	}
 
-	return fmt.Sprintf("google-cloud-sdk-%s-%s.tar.gz", version, platform), nil
+	return platform, nil
+}
+
+// ArtifactNames returns the name of the archive Download stores for version.
+func (plugin *GcloudPlugin) ArtifactNames(version string) ([]string, error) {
+	objectName, err := plugin.getObjectName(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{objectName}, nil
+}
+
+// ResolveArtifacts returns the archive Download fetches for version.
+func (plugin *GcloudPlugin) ResolveArtifacts(_ context.Context, version string) ([]asdf.Artifact, error) {
+	objectName, err := plugin.getObjectName(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return []asdf.Artifact{{Name: objectName, URL: gcloudObjectURL(objectName)}}, nil
+}
+
+// DescribeArtifacts returns the platform pair of the archive name and the
+// path of gcloud inside the unpacked SDK.
+func (plugin *GcloudPlugin) DescribeArtifacts(_ string) (asdf.ArtifactDescription, error) {
+	platform, err := gcloudPlatform()
+	if err != nil {
+		return asdf.ArtifactDescription{}, err
+	}
+
+	goos, arch, _ := strings.Cut(platform, "-")
+
+	return asdf.ArtifactDescription{
+		Platform:    goos,
+		Arch:        arch,
+		ArchiveType: "tar.gz",
+		BinaryPath:  path.Join(plugin.ListBinPaths(), "gcloud"),
+	}, nil
+}
+
+// gcloudObjectURL returns the download URL of a GCS object of the SDK bucket.
+func gcloudObjectURL(objectName string) string {
+	encodedName := strings.ReplaceAll(objectName, "/", "%2F")
+
+	return gcloudDownloadBaseURL + fmt.Sprintf(gcsDownloadPathTemplate, gcsBucketName, encodedName)
 }
 
 // Download downloads the specified gcloud version.
@@ -306,39 +473,26 @@ func (plugin *GcloudPlugin) Download(ctx context.Context, version, downloadPath
 		return nil
 	}
 
-	encodedName := strings.ReplaceAll(objectName, "/", "%2F")
-	url := gcloudDownloadBaseURL + fmt.Sprintf(gcsDownloadPathTemplate, gcsBucketName, encodedName)
+	url := gcloudObjectURL(objectName)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := asdf.HTTPClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("downloading gcloud: %w", err)
 	}
 	defer resp.Body.Close()
 
+	asdf.LogHTTPResponse(ctx, resp)
+
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("%w with status: %d", errGcloudDownloadFailed, resp.StatusCode)
 	}
 
-	outFile, err := os.OpenFile(
-		filePath,
-		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
-		asdf.CommonFilePermission,
-	)
-	if err != nil {
-		return fmt.Errorf("creating file: %w", err)
-	}
-	defer outFile.Close()
-
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
-		return fmt.Errorf("writing file: %w", err)
-	}
-
-	return nil
+	return asdf.SaveResponse(ctx, resp, filePath)
 }
 
 // Install installs gcloud from the downloaded archive.
@@ -360,5 +514,37 @@ func (plugin *GcloudPlugin) Install(
 		return fmt.Errorf("extracting archive: %w", err)
 	}
 
+	return plugin.installDefaultComponents(ctx, installPath)
+}
+
+// installDefaultComponents installs the components listed in the
+// .default-cloud-sdk-components file, if any.
+func (plugin *GcloudPlugin) installDefaultComponents(ctx context.Context, installPath string) error {
+	components, err := asdf.ReadDefaultPackagesFile(gcloudDefaultComponentsFile)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"components", "install"}
+	for _, line := range components {
+		args = append(args, strings.Fields(line)...)
+	}
+
+	if len(args) == 2 {
+		return nil
+	}
+
+	args = append(args, "--quiet")
+
+	asdf.Msgf("Installing gcloud components: %s", strings.Join(args[2:len(args)-1], " "))
+
+	env := plugin.ExecEnv(installPath)
+	env["CLOUDSDK_CORE_DISABLE_PROMPTS"] = "1"
+
+	gcloud := filepath.Join(installPath, "google-cloud-sdk", "bin", "gcloud")
+	if err := asdf.RunCommand(ctx, env, gcloud, args...); err != nil {
+		return fmt.Errorf("installing gcloud components: %w", err)
+	}
+
 	return nil
 }
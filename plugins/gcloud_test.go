@@ -0,0 +1,52 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	p "github.com/sumicare/universal-asdf-plugin/plugins"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/testutil"
+)
+
+// goldieDir holds the recorded versions of the plugins.
+const goldieDir = "asdf/plugins/testdata"
+
+// TestGcloudListAllGoldie lists the recorded gcloud versions from a bucket
+// listing source, across several pages.
+func TestGcloudListAllGoldie(t *testing.T) {
+	t.Parallel()
+
+	source := testutil.StartVersionSource(t, testutil.NewListingSource(func(version string) string {
+		return "google-cloud-sdk-" + version + "-linux-x86_64.tar.gz"
+	}, 100), nil)
+	versions := testutil.SetupVersionsFromGoldie(t, source, goldieDir, "gcloud")
+
+	plugin, ok := p.NewGcloudPlugin().(*p.GcloudPlugin)
+	require.True(t, ok)
+
+	p.SetGcloudAPIURLForTests(plugin, source.URL())
+
+	listed, err := plugin.ListAll(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, versions, listed)
+
+	latest, err := plugin.LatestStable(t.Context(), "")
+	require.NoError(t, err)
+	require.Equal(t, testutil.GoldieLatest(t, goldieDir, "gcloud"), latest)
+}
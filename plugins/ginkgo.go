@@ -22,11 +22,20 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+const (
+	// ginkgoProjectVersion is the .tool-versions keyword selecting the ginkgo
+	// version required by the project go.mod.
+	ginkgoProjectVersion = "project"
+	// ginkgoFromGoModEnv selects the go.mod version for every pinned version when set to 1.
+	ginkgoFromGoModEnv = "ASDF_GINKGO_FROM_GOMOD"
+	// ginkgoModule is the ginkgo library module the CLI has to match.
+	ginkgoModule = "github.com/onsi/ginkgo/v2"
+)
+
 var (
 	// errGinkgoNoVersionsFound is returned when no Ginkgo versions are discovered.
 	errGinkgoNoVersionsFound = errors.New("no versions found")
@@ -48,13 +57,20 @@ func NewGinkgoPlugin() asdf.Plugin {
 		RepoOwner:     "onsi",
 		RepoName:      "ginkgo",
 		VersionPrefix: "v",
+		RefInstall:    true,
 		VersionFilter: `^2\.`,
 
+		BuildEnvPassthrough: goModuleEnvPassthrough,
+
 		Help: asdf.PluginHelp{
 			Overview: `Ginkgo - A BDD-style Go testing framework.
 Ginkgo is built from the official source archive using Go, which requires Go to be installed.`,
-			Deps:   `Requires Go to be installed and available in PATH.`,
-			Config: `No additional configuration required.`,
+			Deps: `Requires Go to be installed and available in PATH.`,
+			Config: `Pin "ginkgo ` + ginkgoProjectVersion + `" in .tool-versions to use the version of ` + ginkgoModule + `
+required by the nearest go.mod, which the ginkgo CLI must match to run the suites.`,
+			HelpConfigEntries: []asdf.ConfigEntry{
+				{Name: ginkgoFromGoModEnv, Description: "Set to 1 to use the go.mod version whatever version is pinned"},
+			},
 			Links: `Homepage: https://onsi.github.io/ginkgo/
 Source: https://github.com/onsi/ginkgo`,
 		},
@@ -77,12 +93,11 @@ Source: https://github.com/onsi/ginkgo`,
 			binDir := filepath.Join(installPath, "bin")
 			dest := filepath.Join(binDir, "ginkgo")
 
-			buildCmd := exec.CommandContext(ctx, goPath, "build", "-o", dest, "./ginkgo")
+			buildCmd := asdf.BuildCommand(ctx, goPath, "build", "-o", dest, "./ginkgo")
 
 			buildCmd.Dir = sourceDir
 			buildCmd.Stdout = os.Stderr
 			buildCmd.Stderr = os.Stderr
-			buildCmd.Env = os.Environ()
 
 			if err := buildCmd.Run(); err != nil {
 				return fmt.Errorf("building ginkgo: %w", err)
@@ -109,6 +124,18 @@ func (*GinkgoPlugin) Name() string {
 	return "ginkgo"
 }
 
+// ResolveVersion returns the ginkgo version required by the go.mod nearest to
+// dir when version is "project" or ASDF_GINKGO_FROM_GOMOD=1, and version otherwise.
+func (*GinkgoPlugin) ResolveVersion(dir, version string) (string, error) {
+	resolver := asdf.GoModVersion{
+		Module:   ginkgoModule,
+		Keyword:  ginkgoProjectVersion,
+		ForceEnv: ginkgoFromGoModEnv,
+	}
+
+	return resolver.Resolve(dir, version)
+}
+
 // Dependencies returns the list of plugins that must be installed before Ginkgo.
 func (*GinkgoPlugin) Dependencies() []string {
 	return []string{"golang"}
@@ -131,12 +158,7 @@ func (*GinkgoPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy version file.
 func (*GinkgoPlugin) ParseLegacyFile(path string) (string, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
-	}
-
-	return strings.TrimSpace(string(content)), nil
+	return asdf.ReadVersionFile(path)
 }
 
 // Uninstall removes a Ginkgo installation.
@@ -183,7 +205,17 @@ func (plugin *GinkgoPlugin) Install(
 	ctx context.Context,
 	version, downloadPath, installPath string,
 ) error {
-	err := plugin.SourceBuildPlugin.Install(ctx, version, downloadPath, installPath)
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	version, err = asdf.ResolveEffectiveVersion(plugin, cwd, version)
+	if err != nil {
+		return err
+	}
+
+	err = plugin.SourceBuildPlugin.Install(ctx, version, downloadPath, installPath)
 	if err != nil {
 		if errors.Is(err, errGinkgoBinaryNotFound) {
 			return fmt.Errorf("%w: %s", errGinkgoBinaryNotFound, version)
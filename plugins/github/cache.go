@@ -0,0 +1,117 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"sync"
+)
+
+const (
+	// listingTags caches the tags of a repository.
+	listingTags listingKind = "tags"
+	// listingReleases caches the releases of a repository.
+	listingReleases listingKind = "releases"
+)
+
+// processCache is shared by the clients of NewClient and NewClientWithToken, so
+// that every plugin of one CLI run lists a repository at most once.
+var processCache = newListingCache() //nolint:gochecknoglobals // process-lifetime cache
+
+type (
+	// listingKind is the kind of repository listing held by a listingCache.
+	listingKind string
+
+	// listingKey identifies a cached listing.
+	listingKey struct {
+		apiURL string
+		owner  string
+		repo   string
+		kind   listingKind
+	}
+
+	// listingEntry is a cached listing, or one being fetched until ready is closed.
+	listingEntry struct {
+		ready chan struct{}
+		value any
+		err   error
+	}
+
+	// listingCache memoizes repository listings. Concurrent callers of the same
+	// listing wait for a single request, and failed requests are not cached.
+	listingCache struct {
+		entries map[listingKey]*listingEntry
+		mu      sync.Mutex
+	}
+)
+
+// newListingCache returns an empty listingCache.
+func newListingCache() *listingCache {
+	return &listingCache{entries: make(map[listingKey]*listingEntry)}
+}
+
+// fetch returns the cached listing of key, calling load to fetch it first when
+// it is not cached. A nil cache always calls load.
+func (cache *listingCache) fetch(key listingKey, load func() (any, error)) (any, error) {
+	if cache == nil {
+		return load()
+	}
+
+	cache.mu.Lock()
+
+	entry, found := cache.entries[key]
+	if !found {
+		entry = &listingEntry{ready: make(chan struct{})}
+		cache.entries[key] = entry
+	}
+
+	cache.mu.Unlock()
+
+	if found {
+		<-entry.ready
+
+		return entry.value, entry.err
+	}
+
+	entry.value, entry.err = load()
+	if entry.err != nil {
+		cache.mu.Lock()
+		delete(cache.entries, key)
+		cache.mu.Unlock()
+	}
+
+	close(entry.ready)
+
+	return entry.value, entry.err
+}
+
+// clear drops every cached listing.
+func (cache *listingCache) clear() {
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	clear(cache.entries)
+}
+
+// ClearCache drops the listings cached by the client, so that the next calls
+// reach the API again. Clients of NewClient and NewClientWithToken share their
+// cache with each other for the lifetime of the process.
+func (client *Client) ClearCache() {
+	client.cache.clear()
+}
@@ -0,0 +1,139 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github_test
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	github "github.com/sumicare/universal-asdf-plugin/plugins/github"
+	githubmock "github.com/sumicare/universal-asdf-plugin/plugins/github/mock"
+)
+
+// countingHTTPClient counts the requests reaching the mock server.
+type countingHTTPClient struct {
+	client *http.Client
+	hits   atomic.Int32
+}
+
+func (counting *countingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	counting.hits.Add(1)
+
+	return counting.client.Do(req)
+}
+
+// TestClientListingCache verifies repeated listings reuse the first response until the cache is cleared.
+func TestClientListingCache(t *testing.T) {
+	t.Parallel()
+
+	server := githubmock.NewServer()
+	t.Cleanup(server.Close)
+
+	server.AddTags("argoproj", "argo-workflows", []string{"v3.5.0", "v3.6.0"})
+	server.AddReleases("argoproj", "argo-workflows", []string{"v3.5.0", "v3.6.0"})
+
+	counting := &countingHTTPClient{client: server.HTTPServer.Client()}
+	client := github.NewClientWithHTTP(counting, server.URL())
+	repoURL := "https://github.com/argoproj/argo-workflows"
+
+	for range 3 {
+		tags, err := client.GetTags(t.Context(), repoURL)
+		require.NoError(t, err)
+		require.Equal(t, []string{"v3.5.0", "v3.6.0"}, tags)
+	}
+
+	require.Equal(t, int32(1), counting.hits.Load())
+
+	releases, err := client.GetReleases(t.Context(), repoURL)
+	require.NoError(t, err)
+	require.Equal(t, []string{"v3.5.0", "v3.6.0"}, releases)
+
+	_, err = client.GetReleaseDetails(t.Context(), repoURL)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), counting.hits.Load())
+
+	server.AddTags("argoproj", "argo-workflows", []string{"v3.5.0", "v3.6.0", "v3.7.0"})
+	client.ClearCache()
+
+	tags, err := client.GetTags(t.Context(), repoURL)
+	require.NoError(t, err)
+	require.Equal(t, []string{"v3.5.0", "v3.6.0", "v3.7.0"}, tags)
+	require.Equal(t, int32(3), counting.hits.Load())
+
+	other := github.NewClientWithHTTP(counting, server.URL())
+
+	_, err = other.GetTags(t.Context(), repoURL)
+	require.NoError(t, err)
+	require.Equal(t, int32(4), counting.hits.Load(), "clients of NewClientWithHTTP do not share their cache")
+}
+
+// TestClientListingCacheConcurrent verifies concurrent listings of one repository share a single request.
+func TestClientListingCacheConcurrent(t *testing.T) {
+	t.Parallel()
+
+	server := githubmock.NewServer()
+	t.Cleanup(server.Close)
+
+	server.AddTags("argoproj", "argo-cd", []string{"v2.13.0"})
+
+	counting := &countingHTTPClient{client: server.HTTPServer.Client()}
+	first := github.NewProcessCacheClientForTests(counting, server.URL())
+	second := github.NewProcessCacheClientForTests(counting, server.URL())
+
+	t.Cleanup(first.ClearCache)
+
+	var wg sync.WaitGroup
+
+	for _, client := range []*github.Client{first, second, first, second} {
+		wg.Go(func() {
+			tags, err := client.GetTags(t.Context(), "https://github.com/argoproj/argo-cd")
+			require.NoError(t, err)
+			require.Equal(t, []string{"v2.13.0"}, tags)
+		})
+	}
+
+	wg.Wait()
+
+	require.Equal(t, int32(1), counting.hits.Load())
+}
+
+// TestClientListingCacheSkipsErrors verifies failed listings are fetched again.
+func TestClientListingCacheSkipsErrors(t *testing.T) {
+	t.Parallel()
+
+	server := githubmock.NewServer()
+	t.Cleanup(server.Close)
+
+	counting := &countingHTTPClient{client: server.HTTPServer.Client()}
+	client := github.NewClientWithHTTP(counting, server.URL())
+	repoURL := "https://github.com/argoproj/argo-rollouts"
+
+	_, err := client.GetTags(t.Context(), repoURL)
+	require.ErrorIs(t, err, github.ErrNotFound)
+	require.ErrorIs(t, err, github.ErrRepoNotFound)
+
+	server.AddTags("argoproj", "argo-rollouts", []string{"v1.7.0"})
+
+	tags, err := client.GetTags(t.Context(), repoURL)
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1.7.0"}, tags)
+	// The first listing also looked up the repository after its 404.
+	require.Equal(t, int32(3), counting.hits.Load())
+}
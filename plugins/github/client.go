@@ -21,9 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -35,6 +38,26 @@ const (
 
 	// httpTimeout is the default timeout for HTTP requests.
 	httpTimeout = 30 * time.Second
+
+	// HTTPTimeoutEnv overrides the timeout of HTTP requests, as a Go duration
+	// such as "90s" or as a number of seconds.
+	HTTPTimeoutEnv = "ASDF_HTTP_TIMEOUT"
+
+	// DefaultAPIURL is the base URL of the github.com REST API.
+	DefaultAPIURL = "https://api.github.com"
+
+	// DefaultDownloadURL is the base URL of github.com release downloads.
+	DefaultDownloadURL = "https://github.com"
+
+	// APIURLEnv overrides DefaultAPIURL, e.g. "https://ghe.example.com/api/v3"
+	// for a GitHub Enterprise Server.
+	APIURLEnv = "ASDF_GITHUB_API_URL"
+
+	// DownloadURLEnv overrides DefaultDownloadURL, e.g. "https://ghe.example.com".
+	DownloadURLEnv = "ASDF_GITHUB_DOWNLOAD_URL"
+
+	// maxRedirects is the number of redirects followed, as by net/http.
+	maxRedirects = 10
 )
 
 // Sentinel errors for GitHub API operations.
@@ -44,6 +67,22 @@ var (
 
 	// ErrHTTPRequest indicates an HTTP request to the GitHub API failed.
 	ErrHTTPRequest = errors.New("HTTP request failed")
+
+	// ErrNotFound indicates the GitHub API answered 404 Not Found.
+	ErrNotFound = errors.New("not found")
+
+	// ErrRepoNotFound indicates the repository does not exist, usually because
+	// it was deleted or moved to another owner without a redirect.
+	ErrRepoNotFound = fmt.Errorf("%w (404) — has it moved?", ErrNotFound)
+
+	// ErrRateLimited indicates the GitHub API rate limit is exhausted.
+	ErrRateLimited = errors.New("GitHub API rate limit exceeded")
+
+	// ErrUnauthorized indicates the GitHub API rejected the credentials.
+	ErrUnauthorized = errors.New("unauthorized, check GITHUB_TOKEN")
+
+	// errTooManyRedirects is returned when a request is redirected more than maxRedirects times.
+	errTooManyRedirects = errors.New("stopped after too many redirects")
 )
 
 type (
@@ -56,8 +95,13 @@ type (
 	// Client provides methods to interact with the GitHub REST API.
 	Client struct {
 		httpClient HTTPClient
-		apiURL     string
-		authToken  string
+		// cache memoizes tag and release listings; nil disables caching.
+		cache     *listingCache
+		apiURL    string
+		authToken string
+		// IncludeDrafts keeps draft releases in release listings. Releases whose
+		// tag no longer exists are excluded regardless.
+		IncludeDrafts bool
 	}
 
 	// TagResponse represents a tag from the GitHub API.
@@ -67,43 +111,148 @@ type (
 
 	// ReleaseResponse represents a release from the GitHub API.
 	ReleaseResponse struct {
-		TagName string `json:"tag_name"`
+		TagName string          `json:"tag_name"`
+		Body    string          `json:"body"`
+		Assets  []AssetResponse `json:"assets"`
+		Draft   bool            `json:"draft"`
+	}
+
+	// AssetResponse represents a release asset from the GitHub API.
+	AssetResponse struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	}
+
+	// AttestationsResponse represents the attestations of an artifact digest.
+	AttestationsResponse struct {
+		Attestations []AttestationResponse `json:"attestations"`
+	}
+
+	// AttestationResponse represents a single artifact attestation, such as a
+	// build provenance attestation, from the GitHub API.
+	AttestationResponse struct {
+		Bundle       json.RawMessage `json:"bundle"`
+		RepositoryID int64           `json:"repository_id"`
 	}
 )
 
-// NewClient creates a new GitHub API client with default settings.
-// It automatically uses GITHUB_TOKEN or GITHUB_API_TOKEN environment variable if set.
+// HTTPTimeout returns the timeout set by ASDF_HTTP_TIMEOUT, or fallback when
+// it is unset or not a positive duration.
+func HTTPTimeout(fallback time.Duration) time.Duration {
+	value := strings.TrimSpace(os.Getenv(HTTPTimeoutEnv))
+	if value == "" {
+		return fallback
+	}
+
+	if _, err := strconv.Atoi(value); err == nil {
+		value += "s"
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return fallback
+	}
+
+	return timeout
+}
+
+// APIURL returns the base URL of the GitHub API, ASDF_GITHUB_API_URL or
+// DefaultAPIURL.
+func APIURL() string {
+	return baseURLFromEnv(APIURLEnv, DefaultAPIURL)
+}
+
+// DownloadURL returns the base URL of release downloads,
+// ASDF_GITHUB_DOWNLOAD_URL or DefaultDownloadURL.
+func DownloadURL() string {
+	return baseURLFromEnv(DownloadURLEnv, DefaultDownloadURL)
+}
+
+// baseURLFromEnv returns the value of key without trailing slashes, or fallback when unset.
+func baseURLFromEnv(key, fallback string) string {
+	if value := strings.TrimRight(strings.TrimSpace(os.Getenv(key)), "/"); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// NewClient creates a new GitHub API client with default settings, talking
+// to APIURL. It automatically uses GITHUB_TOKEN or GITHUB_API_TOKEN
+// environment variable if set.
+// Tag and release listings are cached for the process, shared by all such clients.
 func NewClient() *Client {
+	return NewClientWithAPIURL(APIURL())
+}
+
+// NewClientWithAPIURL creates a new GitHub API client like NewClient talking
+// to apiURL, e.g. the API of a GitHub Enterprise Server.
+func NewClientWithAPIURL(apiURL string) *Client {
 	token := os.Getenv("GITHUB_TOKEN")
 	if token == "" {
 		token = os.Getenv("GITHUB_API_TOKEN")
 	}
 
 	return &Client{
-		httpClient: &http.Client{Timeout: httpTimeout},
-		apiURL:     "https://api.github.com",
+		httpClient: newHTTPClient(),
+		cache:      processCache,
+		apiURL:     strings.TrimRight(apiURL, "/"),
 		authToken:  token,
 	}
 }
 
 // NewClientWithHTTP creates a new GitHub client with a custom HTTP client.
+// Its tag and release listings are cached by this client only. An
+// *http.Client without a redirect policy is copied to drop the token on
+// redirects to other hosts, see keepTokenOnHost.
 func NewClientWithHTTP(httpClient HTTPClient, apiURL string) *Client {
+	if client, ok := httpClient.(*http.Client); ok && client.CheckRedirect == nil {
+		withPolicy := *client
+		withPolicy.CheckRedirect = keepTokenOnHost
+
+		httpClient = &withPolicy
+	}
+
 	return &Client{
 		httpClient: httpClient,
+		cache:      newListingCache(),
 		apiURL:     apiURL,
 		authToken:  os.Getenv("GITHUB_TOKEN"),
 	}
 }
 
-// NewClientWithToken creates a new GitHub client with explicit token.
+// NewClientWithToken creates a new GitHub client with explicit token, sharing
+// the process listing cache of NewClient.
 func NewClientWithToken(token string) *Client {
 	return &Client{
-		httpClient: &http.Client{Timeout: httpTimeout},
-		apiURL:     "https://api.github.com",
+		httpClient: newHTTPClient(),
+		cache:      processCache,
+		apiURL:     APIURL(),
 		authToken:  token,
 	}
 }
 
+// newHTTPClient returns the HTTP client of the API clients, with the
+// ASDF_HTTP_TIMEOUT timeout and the keepTokenOnHost redirect policy.
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: HTTPTimeout(httpTimeout), CheckRedirect: keepTokenOnHost}
+}
+
+// keepTokenOnHost is a redirect policy sending the Authorization header only
+// to the host of the original request. net/http already drops it for other
+// domains, but keeps it for subdomains and other ports of the same host.
+func keepTokenOnHost(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errTooManyRedirects
+	}
+
+	if req.URL.Host != via[0].URL.Host {
+		req.Header.Del("Authorization")
+	}
+
+	return nil
+}
+
 // SetToken sets the authentication token.
 func (client *Client) SetToken(token string) {
 	client.authToken = token
@@ -128,20 +277,36 @@ func GetOwnerRepo(url string) (owner, repo string, err error) {
 	return owner, repo, nil
 }
 
-// GetTags fetches all tags from a GitHub repository using the API.
+// GetTags fetches all tags from a GitHub repository using the API, or returns
+// them from the listing cache.
 func (client *Client) GetTags(ctx context.Context, repoURL string) ([]string, error) {
 	owner, repo, err := GetOwnerRepo(repoURL)
 	if err != nil {
 		return nil, err
 	}
 
-	url := fmt.Sprintf("%s/repos/%s/%s/git/refs/tags", client.apiURL, owner, repo)
+	listing, err := client.cache.fetch(listingKey{client.apiURL, owner, repo, listingTags}, func() (any, error) {
+		url := fmt.Sprintf("%s/repos/%s/%s/git/refs/tags", client.apiURL, owner, repo)
+
+		var tags []TagResponse
+		if err := client.fetchJSON(ctx, url, &tags); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				// GitHub answers 404 both for missing repositories and for
+				// repositories without tags.
+				return nil, client.checkRepo(ctx, owner, repo)
+			}
+
+			return nil, fmt.Errorf("fetching tags: %w", err)
+		}
 
-	var tags []TagResponse
-	if err := client.fetchJSON(ctx, url, &tags); err != nil {
-		return nil, fmt.Errorf("fetching tags: %w", err)
+		return tags, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	tags, _ := listing.([]TagResponse) //nolint:errcheck // the tags key only holds tags
+
 	versions := make([]string, 0, len(tags))
 	for _, tag := range tags {
 		version := strings.TrimPrefix(tag.Ref, "refs/tags/")
@@ -152,26 +317,155 @@ func (client *Client) GetTags(ctx context.Context, repoURL string) ([]string, er
 	return versions, nil
 }
 
-// GetReleases fetches all releases from a GitHub repository.
+// GetReleases fetches the tag names of all releases from a GitHub repository.
 func (client *Client) GetReleases(ctx context.Context, repoURL string) ([]string, error) {
+	releases, err := client.GetReleaseDetails(ctx, repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(releases))
+	for _, release := range releases {
+		versions = append(versions, release.TagName)
+	}
+
+	return versions, nil
+}
+
+// GetReleaseDetails fetches all releases from a GitHub repository, including
+// their assets, or returns them from the listing cache. Drafts are skipped unless IncludeDrafts is set, and releases
+// whose tag no longer exists are always skipped.
+func (client *Client) GetReleaseDetails(ctx context.Context, repoURL string) ([]ReleaseResponse, error) {
 	owner, repo, err := GetOwnerRepo(repoURL)
 	if err != nil {
 		return nil, err
 	}
 
-	url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=100", client.apiURL, owner, repo)
+	listing, err := client.cache.fetch(listingKey{client.apiURL, owner, repo, listingReleases}, func() (any, error) {
+		url := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=100", client.apiURL, owner, repo)
+
+		var releases []ReleaseResponse
+		if err := client.fetchJSON(ctx, url, &releases); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, repoNotFound(owner, repo)
+			}
 
-	var releases []ReleaseResponse
-	if err := client.fetchJSON(ctx, url, &releases); err != nil {
-		return nil, fmt.Errorf("fetching releases: %w", err)
+			return nil, fmt.Errorf("fetching releases: %w", err)
+		}
+
+		return releases, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	versions := make([]string, 0, len(releases))
+	releases, _ := listing.([]ReleaseResponse) //nolint:errcheck // the releases key only holds releases
+
+	kept := make([]ReleaseResponse, 0, len(releases))
 	for _, release := range releases {
-		versions = append(versions, release.TagName)
+		if release.Draft && !client.IncludeDrafts {
+			continue
+		}
+
+		if IsUntaggedRelease(release) {
+			slog.DebugContext(ctx, "skipping release without tag", "tag", release.TagName)
+
+			continue
+		}
+
+		kept = append(kept, release)
 	}
 
-	return versions, nil
+	return kept, nil
+}
+
+// GetReleaseByTag fetches the release of tag in a GitHub repository,
+// including its assets. It wraps ErrNotFound when no release has the tag.
+func (client *Client) GetReleaseByTag(ctx context.Context, repoURL, tag string) (ReleaseResponse, error) {
+	owner, repo, err := GetOwnerRepo(repoURL)
+	if err != nil {
+		return ReleaseResponse{}, err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", client.apiURL, owner, repo, neturl.PathEscape(tag))
+
+	var release ReleaseResponse
+	if err := client.fetchJSON(ctx, url, &release); err != nil {
+		return ReleaseResponse{}, fmt.Errorf("fetching release %s: %w", tag, err)
+	}
+
+	return release, nil
+}
+
+// IsUntaggedRelease reports whether the tag of a release no longer exists. GitHub
+// reports such releases with an empty or "untagged-" prefixed tag name.
+func IsUntaggedRelease(release ReleaseResponse) bool {
+	return release.TagName == "" || strings.HasPrefix(release.TagName, "untagged-")
+}
+
+// GetAttestations fetches the attestations published in owner/repo for an
+// artifact digest such as "sha256:<hex>". It returns no attestations when the
+// repository has none for the digest.
+func (client *Client) GetAttestations(
+	ctx context.Context,
+	owner, repo, digest string,
+) ([]AttestationResponse, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/attestations/%s", client.apiURL, owner, repo, digest)
+
+	var response AttestationsResponse
+	if err := client.fetchJSON(ctx, url, &response); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("fetching attestations: %w", err)
+	}
+
+	return response.Attestations, nil
+}
+
+// checkRepo returns ErrRepoNotFound unless owner/repo exists.
+func (client *Client) checkRepo(ctx context.Context, owner, repo string) error {
+	var response struct{}
+
+	err := client.fetchJSON(ctx, fmt.Sprintf("%s/repos/%s/%s", client.apiURL, owner, repo), &response)
+	if errors.Is(err, ErrNotFound) {
+		return repoNotFound(owner, repo)
+	}
+
+	if err != nil {
+		return fmt.Errorf("fetching repository: %w", err)
+	}
+
+	return nil
+}
+
+// repoNotFound returns ErrRepoNotFound naming owner/repo.
+func repoNotFound(owner, repo string) error {
+	return fmt.Errorf("github repo %s/%s %w", owner, repo, ErrRepoNotFound)
+}
+
+// rateLimitReset returns when the rate limit exhausted by resp resets, from
+// the X-RateLimit-Reset or Retry-After header, and whether resp was rate limited.
+func rateLimitReset(resp *http.Response) (time.Time, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+
+	if resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") != "0" &&
+		resp.Header.Get("Retry-After") == "" {
+		return time.Time{}, false
+	}
+
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		return time.Unix(reset, 0), true
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Now().Add(time.Duration(seconds) * time.Second), true
+	}
+
+	return time.Time{}, true
 }
 
 // fetchJSON fetches JSON from a URL and decodes it into the result.
@@ -184,7 +478,7 @@ func (client *Client) fetchJSON(ctx context.Context, url string, result any) err
 	req.Header.Set("X-Github-Api-Version", APIVersion)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	if client.authToken != "" {
+	if client.authToken != "" && client.isAPIHost(req.URL.Host) {
 		req.Header.Set("Authorization", "Bearer "+client.authToken)
 	}
 
@@ -194,6 +488,8 @@ func (client *Client) fetchJSON(ctx context.Context, url string, result any) err
 	}
 	defer resp.Body.Close()
 
+	slog.DebugContext(ctx, "http request", "method", req.Method, "url", url, "status", resp.StatusCode)
+
 	if resp.StatusCode != http.StatusOK {
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -205,6 +501,27 @@ func (client *Client) fetchJSON(ctx context.Context, url string, result any) err
 			)
 		}
 
+		if resp.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %d %s (%w)", ErrHTTPRequest, resp.StatusCode, string(body), ErrNotFound)
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("%w: %d %s (%w)", ErrHTTPRequest, resp.StatusCode, string(body), ErrUnauthorized)
+		}
+
+		if reset, limited := rateLimitReset(resp); limited {
+			detail := ""
+			if !reset.IsZero() {
+				detail = ", resets at " + reset.Format(time.RFC3339)
+			}
+
+			if client.authToken == "" {
+				detail += "; set GITHUB_TOKEN for a higher limit"
+			}
+
+			return fmt.Errorf("%w: %w (%d)%s", ErrHTTPRequest, ErrRateLimited, resp.StatusCode, detail)
+		}
+
 		return fmt.Errorf("%w: %d %s", ErrHTTPRequest, resp.StatusCode, string(body))
 	}
 
@@ -215,6 +532,14 @@ func (client *Client) fetchJSON(ctx context.Context, url string, result any) err
 	return nil
 }
 
+// isAPIHost reports whether host is the host of the API the client talks to,
+// the only one it sends its token to.
+func (client *Client) isAPIHost(host string) bool {
+	api, err := neturl.Parse(client.apiURL)
+
+	return err == nil && api.Host == host
+}
+
 // ParseGitTagsOutput parses git ls-remote style output into tag names.
 // This is useful for parsing cached or pre-fetched tag data.
 func ParseGitTagsOutput(output string) []string {
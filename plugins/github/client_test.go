@@ -19,7 +19,9 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -95,6 +97,82 @@ func TestNewClientFromEnv(t *testing.T) {
 	})
 }
 
+// TestGitHubBaseURLsFromEnv verifies ASDF_GITHUB_API_URL and
+// ASDF_GITHUB_DOWNLOAD_URL select a GitHub Enterprise Server.
+func TestGitHubBaseURLsFromEnv(t *testing.T) {
+	t.Setenv(github.APIURLEnv, "")
+	t.Setenv(github.DownloadURLEnv, "")
+
+	require.Equal(t, github.DefaultAPIURL, github.APIURL())
+	require.Equal(t, github.DefaultDownloadURL, github.DownloadURL())
+
+	server := githubmock.NewServer()
+	t.Cleanup(server.Close)
+
+	server.AddReleases("tools", "internal-cli", []string{"v1.0.0"})
+
+	t.Setenv(github.APIURLEnv, server.URL()+"/")
+	t.Setenv(github.DownloadURLEnv, "https://ghe.example.com/")
+
+	require.Equal(t, server.URL(), github.APIURL())
+	require.Equal(t, "https://ghe.example.com", github.DownloadURL())
+
+	releases, err := github.NewClient().GetReleases(t.Context(), "https://github.com/tools/internal-cli")
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1.0.0"}, releases)
+}
+
+// TestClientTokenRedirects verifies the token follows redirects within the API
+// host only.
+func TestClientTokenRedirects(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu         sync.Mutex
+		authByPath = map[string]string{}
+	)
+
+	record := func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		authByPath[request.URL.Path] = request.Header.Get("Authorization")
+		mu.Unlock()
+
+		_, _ = writer.Write([]byte(`[{"tag_name":"v1.0.0"}]`))
+	}
+
+	other := httptest.NewServer(http.HandlerFunc(record))
+	t.Cleanup(other.Close)
+
+	api := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/repos/owner/moved/releases":
+			http.Redirect(writer, request, "/repos/owner/renamed/releases", http.StatusMovedPermanently)
+		case "/repos/owner/elsewhere/releases":
+			http.Redirect(writer, request, other.URL+"/repos/owner/elsewhere/releases", http.StatusFound)
+		default:
+			record(writer, request)
+		}
+	}))
+	t.Cleanup(api.Close)
+
+	client := github.NewClientWithHTTP(api.Client(), api.URL)
+	client.SetToken("secret")
+
+	_, err := client.GetReleases(t.Context(), "https://github.com/owner/moved")
+	require.NoError(t, err)
+
+	_, err = client.GetReleases(t.Context(), "https://github.com/owner/elsewhere")
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Equal(t, map[string]string{
+		"/repos/owner/renamed/releases":   "Bearer secret",
+		"/repos/owner/elsewhere/releases": "",
+	}, authByPath)
+}
+
 func TestGitHubClient2(t *testing.T) {
 	t.Parallel()
 
@@ -224,6 +302,83 @@ func TestGitHubClient2(t *testing.T) {
 		require.Len(t, releases, 2)
 	})
 
+	t.Run("GetReleaseByTag fetches a single release with its assets", func(t *testing.T) {
+		t.Parallel()
+
+		server := githubmock.NewServer()
+		t.Cleanup(server.Close)
+
+		server.AddReleases("jqlang", "jq", []string{"jq-1.7.0"})
+		server.AddReleaseAsset("jqlang", "jq", "jq-1.7.1", "jq-linux-amd64", []byte("jq"))
+
+		client := github.NewClientWithHTTP(server.HTTPServer.Client(), server.URL())
+
+		release, err := client.GetReleaseByTag(t.Context(), "https://github.com/jqlang/jq", "jq-1.7.1")
+		require.NoError(t, err)
+		require.Equal(t, "jq-1.7.1", release.TagName)
+		require.Equal(t, []github.AssetResponse{{
+			Name:               "jq-linux-amd64",
+			BrowserDownloadURL: server.URL() + "/jqlang/jq/releases/download/jq-1.7.1/jq-linux-amd64",
+		}}, release.Assets)
+
+		_, err = client.GetReleaseByTag(t.Context(), "https://github.com/jqlang/jq", "jq-0.1")
+		require.ErrorIs(t, err, github.ErrNotFound)
+	})
+
+	t.Run("GetReleases skips drafts and releases without tag", func(t *testing.T) {
+		t.Parallel()
+
+		server := githubmock.NewServer()
+		t.Cleanup(server.Close)
+
+		server.AddReleaseResponses("o", "r", []githubmock.ReleaseResponse{
+			{TagName: "v1.3.0", Draft: true},
+			{TagName: "v1.2.1", Assets: []githubmock.AssetResponse{{Name: "tool.tar.gz"}}},
+			{TagName: "untagged-6e2b0c1f", Draft: true},
+			{TagName: "v1.2.0-yanked"},
+			{TagName: ""},
+			{TagName: "v1.2.0", Assets: []githubmock.AssetResponse{{Name: "tool.tar.gz"}}},
+		})
+
+		client := github.NewClientWithHTTP(server.HTTPServer.Client(), server.URL())
+
+		releases, err := client.GetReleases(t.Context(), "https://github.com/o/r")
+		require.NoError(t, err)
+		require.Equal(t, []string{"v1.2.1", "v1.2.0-yanked", "v1.2.0"}, releases)
+
+		client.IncludeDrafts = true
+
+		releases, err = client.GetReleases(t.Context(), "https://github.com/o/r")
+		require.NoError(t, err)
+		require.Equal(t, []string{"v1.3.0", "v1.2.1", "v1.2.0-yanked", "v1.2.0"}, releases)
+
+		details, err := client.GetReleaseDetails(t.Context(), "https://github.com/o/r")
+		require.NoError(t, err)
+		require.Len(t, details, 4)
+		require.Empty(t, details[2].Assets)
+		require.Equal(t, "tool.tar.gz", details[1].Assets[0].Name)
+	})
+
+	t.Run("GetAttestations returns published attestations", func(t *testing.T) {
+		t.Parallel()
+
+		server := githubmock.NewServer()
+		t.Cleanup(server.Close)
+
+		server.AddAttestation("cli", "cli", "sha256:abc")
+
+		client := github.NewClientWithHTTP(server.HTTPServer.Client(), server.URL())
+
+		attestations, err := client.GetAttestations(t.Context(), "cli", "cli", "sha256:abc")
+		require.NoError(t, err)
+		require.Len(t, attestations, 1)
+		require.Contains(t, string(attestations[0].Bundle), "sigstore")
+
+		attestations, err = client.GetAttestations(t.Context(), "cli", "cli", "sha256:def")
+		require.NoError(t, err)
+		require.Empty(t, attestations)
+	})
+
 	t.Run("GetReleases returns error for invalid URL", func(t *testing.T) {
 		t.Parallel()
 
@@ -374,10 +529,31 @@ func TestGitHubClient2(t *testing.T) {
 
 			var out []github.TagResponse
 
-			err := client.FetchJSONForTests(t.Context(), "https://example.invalid", &out)
+			err := client.FetchJSONForTests(t.Context(), "https://api.github.com/repos/owner/repo/git/refs/tags", &out)
 			require.NoError(t, err)
 			require.True(t, seen)
 		})
+
+		t.Run("omits Authorization header for hosts other than the API", func(t *testing.T) {
+			t.Parallel()
+
+			client := github.NewClientForTests(
+				&staticHTTPClient{do: func(req *http.Request) (*http.Response, error) {
+					require.Empty(t, req.Header.Get("Authorization"))
+
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(strings.NewReader("[]")),
+					}, nil
+				}},
+				"https://ghe.example.com/api/v3",
+				"test-token",
+			)
+
+			var out []github.TagResponse
+
+			require.NoError(t, client.FetchJSONForTests(t.Context(), "https://example.invalid", &out))
+		})
 	})
 
 	t.Run("ParseGitTagsOutput parses git ls-remote output", func(t *testing.T) {
@@ -447,6 +623,59 @@ func TestGitHubClient2(t *testing.T) {
 }
 
 // TestParseGitTagsOutputGoldie tests git tags output parsing with golden files.
+// TestClientStatusErrors verifies missing repositories, rejected credentials and
+// exhausted rate limits are reported as such.
+func TestClientStatusErrors(t *testing.T) {
+	t.Parallel()
+
+	server := githubmock.NewServer()
+	t.Cleanup(server.Close)
+
+	reset := time.Unix(1_900_000_000, 0)
+
+	server.AddRepo("golang", "go")
+	server.SetStatus("onsi", "ginkgo", http.StatusUnauthorized)
+	server.SetRateLimited("argoproj", "argo-cd", reset)
+
+	client := github.NewClientForTests(server.HTTPServer.Client(), server.URL(), "")
+
+	t.Run("missing repository", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := client.GetReleases(t.Context(), "https://github.com/rebuy-de/aws-nuke")
+		require.ErrorIs(t, err, github.ErrRepoNotFound)
+		require.EqualError(t, err, "github repo rebuy-de/aws-nuke not found (404) — has it moved?")
+
+		_, err = client.GetTags(t.Context(), "https://github.com/rebuy-de/aws-nuke")
+		require.ErrorIs(t, err, github.ErrRepoNotFound)
+	})
+
+	t.Run("repository without tags", func(t *testing.T) {
+		t.Parallel()
+
+		tags, err := client.GetTags(t.Context(), "https://github.com/golang/go")
+		require.NoError(t, err)
+		require.Empty(t, tags)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := client.GetTags(t.Context(), "https://github.com/onsi/ginkgo")
+		require.ErrorIs(t, err, github.ErrUnauthorized)
+		require.NotErrorIs(t, err, github.ErrRepoNotFound)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := client.GetReleases(t.Context(), "https://github.com/argoproj/argo-cd")
+		require.ErrorIs(t, err, github.ErrRateLimited)
+		require.ErrorContains(t, err, "resets at "+reset.Format(time.RFC3339))
+		require.ErrorContains(t, err, "set GITHUB_TOKEN")
+	})
+}
+
 func TestParseGitTagsOutputGoldie(t *testing.T) {
 	t.Parallel()
 
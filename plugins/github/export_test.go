@@ -32,3 +32,13 @@ func NewClientForTests(httpClient HTTPClient, apiURL, authToken string) *Client
 func (client *Client) FetchJSONForTests(ctx context.Context, url string, out any) error {
 	return client.fetchJSON(ctx, url, out)
 }
+
+// NewProcessCacheClientForTests constructs a Client sharing the process listing
+// cache of NewClient, against a custom API.
+func NewProcessCacheClientForTests(httpClient HTTPClient, apiURL string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		cache:      processCache,
+		apiURL:     apiURL,
+	}
+}
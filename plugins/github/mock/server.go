@@ -19,15 +19,37 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type (
 	// Server provides a mock GitHub API server for testing.
 	Server struct {
-		HTTPServer *httptest.Server
-		tags       map[string][]TagResponse
-		releases   map[string][]ReleaseResponse
+		HTTPServer   *httptest.Server
+		tags         map[string][]TagResponse
+		releases     map[string][]ReleaseResponse
+		attestations map[string]AttestationsResponse
+		// failures answers every request for a repository with a status.
+		failures map[string]failure
+		// repos lists the repositories that exist without tags or releases.
+		repos map[string]bool
+		// refArchives holds the source archives served at the codeload-style
+		// "/owner/repo/tar.gz/ref" paths, keyed by that path without the slash.
+		refArchives map[string][]byte
+		// assets holds the release assets served at the github.com-style
+		// "/owner/repo/releases/download/tag/name" paths, keyed by that path
+		// without the slash.
+		assets map[string][]byte
+	}
+
+	// failure is a status a repository answers with, and the rate limit reset
+	// sent with 403 and 429 statuses.
+	failure struct {
+		reset  time.Time
+		status int
 	}
 
 	// TagResponse represents a tag from the GitHub API.
@@ -37,21 +59,76 @@ type (
 
 	// ReleaseResponse represents a release from the GitHub API.
 	ReleaseResponse struct {
-		TagName string `json:"tag_name"`
+		TagName string          `json:"tag_name"`
+		Body    string          `json:"body"`
+		Assets  []AssetResponse `json:"assets"`
+		Draft   bool            `json:"draft"`
+	}
+
+	// AssetResponse represents a release asset from the GitHub API.
+	AssetResponse struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	}
+
+	// AttestationsResponse represents the attestations of an artifact digest.
+	AttestationsResponse struct {
+		Attestations []AttestationResponse `json:"attestations"`
+	}
+
+	// AttestationResponse represents a single artifact attestation.
+	AttestationResponse struct {
+		Bundle       map[string]any `json:"bundle"`
+		RepositoryID int64          `json:"repository_id"`
 	}
 )
 
 // NewServer creates a new mock GitHub API server.
 func NewServer() *Server {
 	mock := &Server{
-		tags:     make(map[string][]TagResponse),
-		releases: make(map[string][]ReleaseResponse),
+		tags:         make(map[string][]TagResponse),
+		releases:     make(map[string][]ReleaseResponse),
+		attestations: make(map[string]AttestationsResponse),
+		failures:     make(map[string]failure),
+		repos:        make(map[string]bool),
+		refArchives:  make(map[string][]byte),
+		assets:       make(map[string][]byte),
 	}
 
 	mock.HTTPServer = httptest.NewServer(
 		http.HandlerFunc(func(responseWriter http.ResponseWriter, req *http.Request) {
 			path := req.URL.Path
 
+			if failure, ok := mock.failures[repoOf(path)]; ok {
+				failure.write(responseWriter)
+
+				return
+			}
+
+			if archive, ok := mock.refArchives[strings.TrimPrefix(path, "/")]; ok {
+				responseWriter.Header().Set("Content-Type", "application/x-gzip")
+
+				_, _ = responseWriter.Write(archive)
+
+				return
+			}
+
+			if asset, ok := mock.assets[strings.TrimPrefix(req.URL.EscapedPath(), "/")]; ok {
+				responseWriter.Header().Set("Content-Type", "application/octet-stream")
+
+				_, _ = responseWriter.Write(asset)
+
+				return
+			}
+
+			if repo := strings.TrimPrefix(path, "/repos/"); mock.exists(repo) {
+				responseWriter.Header().Set("Content-Type", "application/json")
+
+				_, _ = responseWriter.Write([]byte(`{"full_name":"` + repo + `"}`))
+
+				return
+			}
+
 			if strings.Contains(path, "/git/refs/tags") {
 				repoPath := extractRepoPath(path, "/git/refs/tags")
 				if tags, ok := mock.tags[repoPath]; ok {
@@ -63,6 +140,28 @@ func NewServer() *Server {
 				}
 			}
 
+			if strings.Contains(path, "/attestations/") {
+				if attestations, ok := mock.attestations[strings.TrimPrefix(path, "/repos/")]; ok {
+					responseWriter.Header().Set("Content-Type", "application/json")
+
+					_ = json.NewEncoder(responseWriter).Encode(attestations)
+
+					return
+				}
+			}
+
+			if repoPath, tag, ok := strings.Cut(strings.TrimPrefix(path, "/repos/"), "/releases/tags/"); ok {
+				for _, release := range mock.releases[repoPath] {
+					if release.TagName == tag {
+						responseWriter.Header().Set("Content-Type", "application/json")
+
+						_ = json.NewEncoder(responseWriter).Encode(release)
+
+						return
+					}
+				}
+			}
+
 			if strings.Contains(path, "/releases") {
 				repoPath := extractRepoPath(path, "/releases")
 				if releases, ok := mock.releases[repoPath]; ok {
@@ -89,6 +188,35 @@ func extractRepoPath(path, suffix string) string {
 	)
 }
 
+// repoOf returns "owner/repo" of a path like "/repos/owner/repo/...".
+func repoOf(path string) string {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/repos/"), "/", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return parts[0] + "/" + parts[1]
+}
+
+// exists reports whether the repository "owner/repo" was added, with or without tags or releases.
+func (s *Server) exists(repoPath string) bool {
+	_, hasTags := s.tags[repoPath]
+	_, hasReleases := s.releases[repoPath]
+
+	return s.repos[repoPath] || hasTags || hasReleases
+}
+
+// write answers with the failure status, as rate limited when it is 403 or 429.
+func (f failure) write(responseWriter http.ResponseWriter) {
+	if f.status == http.StatusForbidden || f.status == http.StatusTooManyRequests {
+		responseWriter.Header().Set("X-RateLimit-Remaining", "0")
+		responseWriter.Header().Set("X-RateLimit-Reset", strconv.FormatInt(f.reset.Unix(), 10))
+	}
+
+	responseWriter.WriteHeader(f.status)
+	_, _ = responseWriter.Write([]byte(`{"message":"` + http.StatusText(f.status) + `"}`))
+}
+
 // URL returns the base URL of the mock server.
 func (s *Server) URL() string {
 	return s.HTTPServer.URL
@@ -109,12 +237,89 @@ func (s *Server) AddTags(owner, repo string, tags []string) {
 	}
 }
 
-// AddReleases adds releases for a repository.
+// AddReleases adds published releases for a repository, each with a single asset.
 func (s *Server) AddReleases(owner, repo string, releases []string) {
+	responses := make([]ReleaseResponse, 0, len(releases))
+	for _, release := range releases {
+		responses = append(responses, ReleaseResponse{
+			TagName: release,
+			Assets:  []AssetResponse{{Name: release + ".tar.gz"}},
+		})
+	}
+
+	s.AddReleaseResponses(owner, repo, responses)
+}
+
+// AddReleaseResponses adds releases for a repository as-is, e.g. drafts or
+// releases without assets.
+func (s *Server) AddReleaseResponses(owner, repo string, releases []ReleaseResponse) {
+	s.releases[owner+"/"+repo] = releases
+}
+
+// AddReleaseAsset serves content as the asset name of the release tag in
+// owner/repo, adding the release when it does not exist yet. The API lists
+// the asset with a browser_download_url pointing back at the server.
+func (s *Server) AddReleaseAsset(owner, repo, tag, name string, content []byte) {
 	repoPath := owner + "/" + repo
+	assetPath := repoPath + "/releases/download/" + url.PathEscape(tag) + "/" + url.PathEscape(name)
 
-	s.releases[repoPath] = make([]ReleaseResponse, 0, len(releases))
-	for _, release := range releases {
-		s.releases[repoPath] = append(s.releases[repoPath], ReleaseResponse{TagName: release})
+	s.assets[assetPath] = content
+
+	asset := AssetResponse{Name: name, BrowserDownloadURL: s.URL() + "/" + assetPath}
+
+	for i := range s.releases[repoPath] {
+		if release := &s.releases[repoPath][i]; release.TagName == tag {
+			release.Assets = append(release.Assets, asset)
+
+			return
+		}
 	}
+
+	s.releases[repoPath] = append(s.releases[repoPath], ReleaseResponse{TagName: tag, Assets: []AssetResponse{asset}})
+}
+
+// AddAttestation publishes a build provenance attestation in owner/repo for an
+// artifact digest such as "sha256:<hex>".
+func (s *Server) AddAttestation(owner, repo, digest string) {
+	key := owner + "/" + repo + "/attestations/" + digest
+
+	response := s.attestations[key]
+	response.Attestations = append(response.Attestations, AttestationResponse{
+		Bundle: map[string]any{
+			"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json",
+		},
+		RepositoryID: 1,
+	})
+
+	s.attestations[key] = response
+}
+
+// AddRepo adds a repository without tags or releases.
+func (s *Server) AddRepo(owner, repo string) {
+	s.repos[owner+"/"+repo] = true
+}
+
+// AddRefArchive serves archive as the tar.gz source archive of ref in
+// owner/repo, at the codeload-style path of RefArchiveURLTemplate.
+func (s *Server) AddRefArchive(owner, repo, ref string, archive []byte) {
+	s.refArchives[owner+"/"+repo+"/tar.gz/"+ref] = archive
+}
+
+// RefArchiveURLTemplate returns the URL template of the archives added with
+// AddRefArchive, with {{.RepoOwner}}, {{.RepoName}} and {{.Ref}} placeholders.
+func (s *Server) RefArchiveURLTemplate() string {
+	return s.URL() + "/{{.RepoOwner}}/{{.RepoName}}/tar.gz/{{.Ref}}"
+}
+
+// SetStatus answers every request for a repository with status, e.g.
+// http.StatusUnauthorized. Requests for repositories that were not added
+// already answer http.StatusNotFound.
+func (s *Server) SetStatus(owner, repo string, status int) {
+	s.failures[owner+"/"+repo] = failure{status: status}
+}
+
+// SetRateLimited answers every request for a repository with 403 Forbidden
+// and an exhausted rate limit resetting at reset.
+func (s *Server) SetRateLimited(owner, repo string, reset time.Time) {
+	s.failures[owner+"/"+repo] = failure{status: http.StatusForbidden, reset: reset}
 }
@@ -20,6 +20,7 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	githubmock "github.com/sumicare/universal-asdf-plugin/plugins/github/mock"
@@ -125,6 +126,11 @@ func TestMockServer(t *testing.T) {
 				path:     "/repos/unknown/repo/releases",
 				expected: http.StatusNotFound,
 			},
+			{
+				name:     "returns 404 for unknown release tags",
+				path:     "/repos/unknown/repo/releases/tags/v1.0.0",
+				expected: http.StatusNotFound,
+			},
 		}
 
 		for i := range tests {
@@ -154,6 +160,86 @@ func TestMockServer(t *testing.T) {
 		}
 	})
 
+	t.Run("SetStatus and SetRateLimited answer every repository request", func(t *testing.T) {
+		t.Parallel()
+
+		server := githubmock.NewServer()
+		t.Cleanup(server.Close)
+
+		reset := time.Unix(1_900_000_000, 0)
+
+		server.AddRepo("golang", "go")
+		server.AddTags("onsi", "ginkgo", []string{"v2.0.0"})
+		server.SetStatus("onsi", "ginkgo", http.StatusUnauthorized)
+		server.SetRateLimited("argoproj", "argo-cd", reset)
+
+		get := func(path string) *http.Response {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL()+path, http.NoBody)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_ = resp.Body.Close()
+			})
+
+			return resp
+		}
+
+		require.Equal(t, http.StatusOK, get("/repos/golang/go").StatusCode)
+		require.Equal(t, http.StatusNotFound, get("/repos/golang/go/git/refs/tags").StatusCode)
+		require.Equal(t, http.StatusUnauthorized, get("/repos/onsi/ginkgo/git/refs/tags").StatusCode)
+
+		limited := get("/repos/argoproj/argo-cd/releases")
+		require.Equal(t, http.StatusForbidden, limited.StatusCode)
+		require.Equal(t, "0", limited.Header.Get("X-RateLimit-Remaining"))
+		require.Equal(t, "1900000000", limited.Header.Get("X-RateLimit-Reset"))
+	})
+
+	t.Run("AddReleaseAsset lists the asset and serves its content", func(t *testing.T) {
+		t.Parallel()
+
+		server := githubmock.NewServer()
+		t.Cleanup(server.Close)
+
+		server.AddReleases("jqlang", "jq", []string{"jq-1.7.0"})
+		server.AddReleaseAsset("jqlang", "jq", "jq-1.7.1", "jq-linux-amd64", []byte("jq 1.7.1"))
+		server.AddReleaseAsset("jqlang", "jq", "jq-1.7.1", "jq-linux-arm64", []byte("jq 1.7.1 arm64"))
+
+		get := func(url string) []byte {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url, http.NoBody)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_ = resp.Body.Close()
+			})
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			return body
+		}
+
+		var releases []githubmock.ReleaseResponse
+		require.NoError(t, json.Unmarshal(get(server.URL()+"/repos/jqlang/jq/releases"), &releases))
+		require.Len(t, releases, 2)
+		require.Equal(t, "jq-1.7.1", releases[1].TagName)
+		require.Equal(t, []githubmock.AssetResponse{
+			{Name: "jq-linux-amd64", BrowserDownloadURL: server.URL() + "/jqlang/jq/releases/download/jq-1.7.1/jq-linux-amd64"},
+			{Name: "jq-linux-arm64", BrowserDownloadURL: server.URL() + "/jqlang/jq/releases/download/jq-1.7.1/jq-linux-arm64"},
+		}, releases[1].Assets)
+
+		require.Equal(t, "jq 1.7.1", string(get(releases[1].Assets[0].BrowserDownloadURL)))
+
+		var release githubmock.ReleaseResponse
+		require.NoError(t, json.Unmarshal(get(server.URL()+"/repos/jqlang/jq/releases/tags/jq-1.7.1"), &release))
+		require.Equal(t, releases[1], release)
+	})
+
 	t.Run("extractRepoPath extracts owner/repo from path", func(t *testing.T) {
 		t.Parallel()
 
@@ -31,5 +31,12 @@ func NewGithubCliPlugin() asdf.Plugin {
 		HelpDescription:  "GitHub CLI - GitHub's official command line tool",
 		HelpLink:         "https://github.com/cli/cli",
 		ArchiveType:      "tar.gz",
+
+		ProvenanceVerification: true,
+
+		// The archives ship man pages but no completions, which gh generates.
+		ExtraInstallFiles: []asdf.ExtraInstallFile{
+			{ArchivePathGlob: "*/share/man/man1/*.1", InstallRelDir: "share/man/man1"},
+		},
 	})
 }
@@ -18,8 +18,10 @@ package plugins
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -30,29 +32,93 @@ import (
 	"github.com/sumicare/universal-asdf-plugin/plugins/github"
 )
 
+// goModuleEnvPassthrough are the module download settings passed to go builds.
+// They select where modules are fetched from, not what is built.
+var goModuleEnvPassthrough = []string{ //nolint:gochecknoglobals // read-only lookup table
+	"GOPROXY", "GOPRIVATE", "GONOPROXY", "GONOSUMDB", "GOSUMDB", "GOINSECURE", "GOAUTH",
+}
+
+// golangSettings are the ASDF_GOLANG_* settings read through asdf.PluginEnv.
+var golangSettings = []asdf.EnvSetting{ //nolint:gochecknoglobals // read-only lookup table
+	{
+		Key: "DEFAULT_PACKAGES_FILE", Type: asdf.EnvTypeString, Default: "~/.default-golang-pkgs",
+		Description: "Path to default packages file",
+	},
+	{
+		Key: "SKIP_CHECKSUM", Type: asdf.EnvTypeBool, Default: "false",
+		Description: "Skip verifying the SHA256 checksum of downloads",
+	},
+	{
+		Key: "SET_GOBIN", Type: asdf.EnvTypeBool, Default: "false",
+		Description: "Export GOBIN as the bin directory of the install instead of leaving go install to $GOPATH/bin",
+	},
+}
+
 var (
 	// errGoVersionNotFoundInFile is returned when a go.mod/go.work file has no usable Go version.
 	errGoVersionNotFoundInFile = errors.New("no go version found in file")
 	// errGoNoVersionsFound is returned when no Go versions are discovered.
 	errGoNoVersionsFound = errors.New("no versions found")
+	// errGoBootstrapNotFound is returned when no Go toolchain can bootstrap a ref build.
+	errGoBootstrapNotFound = errors.New("no Go toolchain to bootstrap the build")
+	// errGoListingFailed is returned when the go.dev/dl listing cannot be fetched.
+	errGoListingFailed = errors.New("fetching the Go download listing failed")
+	// errGoArchiveNotPublished is returned when the listing has no archive for a version and platform.
+	errGoArchiveNotPublished = errors.New("no Go archive published")
 )
 
 const (
 	// goDownloadURL is the base URL used to download official Go release archives.
 	goDownloadURL = "https://dl.google.com/go"
+	// goDownloadListingURL lists every Go release with the SHA256 of its files.
+	goDownloadListingURL = "https://go.dev/dl/?mode=json&include=all"
 	// goGitRepoURL is the upstream Git repository for the Go project.
 	goGitRepoURL = "https://github.com/golang/go"
+	// goBootstrapEnv names the Go installation building a ref, the go in PATH when unset.
+	goBootstrapEnv = "GOROOT_BOOTSTRAP"
+	// goPathDir is the GOPATH of an install, relative to it.
+	goPathDir = "packages"
+	// goArchiveName is the name of the downloaded release archive.
+	goArchiveName = "archive.tar.gz"
+	// goRefArchiveName is the name of the downloaded source archive of a ref.
+	goRefArchiveName = "go-ref.tar.gz"
 )
 
-// GolangPlugin implements the asdf.Plugin interface for Go.
-type GolangPlugin struct {
-	*github.Client
-}
+type (
+	// GolangPlugin implements the asdf.Plugin interface for Go.
+	GolangPlugin struct {
+		*github.Client
+
+		// DownloadURL is the base URL of the release archives.
+		DownloadURL string
+		// DownloadListingURL is the go.dev/dl JSON listing the SHA256 of the
+		// release archives is read from.
+		DownloadListingURL string
+	}
+
+	// goRelease is a release of the go.dev/dl JSON listing.
+	goRelease struct {
+		Version string          `json:"version"`
+		Files   []goReleaseFile `json:"files"`
+	}
+
+	// goReleaseFile is a file of a goRelease.
+	goReleaseFile struct {
+		Filename string `json:"filename"`
+		OS       string `json:"os"`
+		Arch     string `json:"arch"`
+		SHA256   string `json:"sha256"`
+		Kind     string `json:"kind"`
+		Size     int64  `json:"size"`
+	}
+)
 
 // NewGolangPlugin creates a new Go plugin instance.
 func NewGolangPlugin() asdf.Plugin {
 	return &GolangPlugin{
-		github.NewClient(),
+		Client:             github.NewClient(),
+		DownloadURL:        goDownloadURL,
+		DownloadListingURL: goDownloadListingURL,
 	}
 }
 
@@ -66,8 +132,9 @@ func (*GolangPlugin) ListBinPaths() string {
 	return "go/bin bin"
 }
 
-// ExecEnv returns environment variables for Go execution.
-// Only sets variables if not already set by user.
+// ExecEnv returns environment variables for Go execution: GOROOT and GOPATH
+// of the install and, with ASDF_GOLANG_SET_GOBIN, GOBIN. Only sets variables
+// if not already set by user.
 func (*GolangPlugin) ExecEnv(installPath string) map[string]string {
 	env := make(map[string]string)
 
@@ -76,16 +143,34 @@ func (*GolangPlugin) ExecEnv(installPath string) map[string]string {
 	}
 
 	if os.Getenv("GOPATH") == "" {
-		env["GOPATH"] = filepath.Join(installPath, "packages")
+		env["GOPATH"] = filepath.Join(installPath, goPathDir)
 	}
 
-	if os.Getenv("GOBIN") == "" {
+	if os.Getenv("GOBIN") == "" && asdf.PluginEnv("golang").Bool("SET_GOBIN", false) {
 		env["GOBIN"] = filepath.Join(installPath, "bin")
 	}
 
 	return env
 }
 
+// ExtraBinDirs returns the bin directory of the GOPATH of the install, where
+// go install puts binaries without GOBIN, once it exists, so that reshim
+// exposes them for the Go version that built them.
+func (*GolangPlugin) ExtraBinDirs(installPath string) []string {
+	dir := filepath.Join(installPath, goPathDir, "bin")
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil
+	}
+
+	return []string{dir}
+}
+
+// HashIgnoreGlobs leaves the GOPATH, with its module cache and binaries built
+// with go install, out of the golang checksum.
+func (*GolangPlugin) HashIgnoreGlobs() []string {
+	return []string{goPathDir}
+}
+
 // ListLegacyFilenames returns legacy version filenames for Go.
 func (*GolangPlugin) ListLegacyFilenames() []string {
 	return []string{".go-version", "go.mod", "go.work"}
@@ -100,7 +185,7 @@ func (*GolangPlugin) ParseLegacyFile(path string) (string, error) {
 		return parseGoModVersion(path)
 	}
 
-	version, err := asdf.ReadLegacyVersionFile(path)
+	version, err := asdf.ReadVersionFile(path)
 	if err != nil {
 		return "", err
 	}
@@ -110,12 +195,12 @@ func (*GolangPlugin) ParseLegacyFile(path string) (string, error) {
 
 // parseGoModVersion extracts the Go version from go.mod or go.work files.
 func parseGoModVersion(path string) (string, error) {
-	content, err := os.ReadFile(path)
+	content, err := asdf.ReadTextFile(path)
 	if err != nil {
 		return "", err
 	}
 
-	lines := strings.SplitSeq(string(content), "\n")
+	lines := strings.SplitSeq(content, "\n")
 	for line := range lines {
 		line = strings.TrimSpace(line)
 
@@ -158,15 +243,41 @@ func (*GolangPlugin) Uninstall(_ context.Context, installPath string) error {
 	return os.RemoveAll(installPath)
 }
 
+// EnrichSBOM records the version and build time embedded in the VERSION file
+// of the toolchain, e.g. "go1.22.0" and "2024-02-06T21:52:21Z", which tell
+// ref builds apart. Installs without the file are left as they are.
+func (*GolangPlugin) EnrichSBOM(installPath string, component *asdf.SBOMComponent) error {
+	data, err := os.ReadFile(filepath.Join(installPath, "go", "VERSION"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	component.Properties = append(component.Properties, asdf.SBOMProperty{Name: "golang:version", Value: lines[0]})
+
+	for _, line := range lines[1:] {
+		if buildTime, ok := strings.CutPrefix(line, "time "); ok {
+			component.Properties = append(component.Properties, asdf.SBOMProperty{Name: "golang:build-time", Value: buildTime})
+		}
+	}
+
+	return nil
+}
+
 // Help returns help information for the Go plugin.
 func (*GolangPlugin) Help() asdf.PluginHelp {
 	return asdf.PluginHelp{
 		Overview: `Go (golang) - An open-source programming language supported by Google.
 This plugin downloads pre-built Go binaries from https://go.dev/dl/`,
 		Deps: `No system dependencies required - uses pre-built binaries.`,
-		Config: `Environment variables:
-  ASDF_GOLANG_DEFAULT_PACKAGES_FILE - Path to default packages file (default: ~/.default-golang-pkgs)
-  ASDF_GOLANG_MOD_VERSION_ENABLED - Enable go.mod version detection (default: false)`,
+		HelpConfigEntries: append(asdf.PluginEnv("golang").Document(golangSettings...).ConfigEntries(),
+			asdf.ConfigEntry{Name: goBootstrapEnv, Default: "the go in PATH", Description: "Go installation building git refs"}),
+		Config: `Binaries built with go install land in the install's packages/bin, or bin
+with ASDF_GOLANG_SET_GOBIN; run reshim afterwards to get shims for them.`,
 		Links: `Homepage: https://go.dev/
 Documentation: https://go.dev/doc/
 Downloads: https://go.dev/dl/
@@ -211,6 +322,7 @@ func (p *GolangPlugin) LatestStable(ctx context.Context, query string) (string,
 		return "", errGoNoVersionsFound
 	}
 
+	query, includePrereleases := asdf.ParseLatestQuery(query)
 	if query != "" {
 		filtered := asdf.FilterVersions(versions, func(v string) bool {
 			return strings.HasPrefix(v, query)
@@ -220,69 +332,168 @@ func (p *GolangPlugin) LatestStable(ctx context.Context, query string) (string,
 		}
 	}
 
-	stable := asdf.FilterVersions(versions, func(v string) bool {
-		return !asdf.IsPrereleaseVersion(v)
-	})
-
-	if len(stable) == 0 {
+	stable := asdf.FilterVersions(versions, asdf.IsStableVersion)
+	if len(stable) == 0 || includePrereleases {
 		return versions[len(versions)-1], nil
 	}
 
 	return stable[len(stable)-1], nil
 }
 
-// Download downloads the specified Go version.
-func (*GolangPlugin) Download(ctx context.Context, version, downloadPath string) error {
+// ResolveArtifacts returns the release archive Download fetches for version.
+func (plugin *GolangPlugin) ResolveArtifacts(_ context.Context, version string) ([]asdf.Artifact, error) {
+	platform, arch, err := goPlatform()
+	if err != nil {
+		return nil, err
+	}
+
+	downloadURL := fmt.Sprintf("%s/go%s.%s-%s.tar.gz", plugin.DownloadURL, version, platform, arch)
+
+	return []asdf.Artifact{{Name: goArchiveName, URL: downloadURL}}, nil
+}
+
+// goPlatform returns the running platform and architecture as named by the
+// release archives.
+func goPlatform() (string, string, error) {
 	platform, err := asdf.GetPlatform()
 	if err != nil {
-		return err
+		return "", "", err
 	}
 
 	arch, err := asdf.GetArch()
 	if err != nil {
-		return err
+		return "", "", err
 	}
 
-	downloadURL := fmt.Sprintf("%s/go%s.%s-%s.tar.gz", goDownloadURL, version, platform, arch)
-	archivePath := filepath.Join(downloadPath, "archive.tar.gz")
+	return platform, arch, nil
+}
 
-	// Ensure download directory exists
-	if err := asdf.EnsureDir(downloadPath); err != nil {
-		return fmt.Errorf("creating download directory: %w", err)
+// publishedArchive returns the release archive of version for the running
+// platform from the go.dev/dl listing, with its SHA256 and size.
+func (plugin *GolangPlugin) publishedArchive(ctx context.Context, version string) (goReleaseFile, error) {
+	platform, arch, err := goPlatform()
+	if err != nil {
+		return goReleaseFile{}, err
 	}
 
-	asdf.Msgf("Downloading Go %s from %s", version, downloadURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, plugin.DownloadListingURL, http.NoBody)
+	if err != nil {
+		return goReleaseFile{}, err
+	}
 
-	if err := asdf.DownloadFile(ctx, downloadURL, archivePath); err != nil {
-		return fmt.Errorf("downloading Go %s: %w", version, err)
+	resp, err := asdf.HTTPClient().Do(req)
+	if err != nil {
+		return goReleaseFile{}, fmt.Errorf("%w: %w", errGoListingFailed, err)
 	}
+	defer resp.Body.Close()
 
-	if os.Getenv("ASDF_GOLANG_SKIP_CHECKSUM") == "" {
-		checksumURL := downloadURL + ".sha256"
-		checksumPath := archivePath + ".sha256"
+	asdf.LogHTTPResponse(ctx, resp)
 
-		asdf.Msgf("Downloading checksum from %s", checksumURL)
+	if resp.StatusCode != http.StatusOK {
+		return goReleaseFile{}, fmt.Errorf("%w: %d", errGoListingFailed, resp.StatusCode)
+	}
+
+	var releases []goRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return goReleaseFile{}, fmt.Errorf("%w: %w", errGoListingFailed, err)
+	}
 
-		if err := asdf.DownloadFile(ctx, checksumURL, checksumPath); err != nil {
-			return fmt.Errorf("downloading checksum: %w", err)
+	for _, release := range releases {
+		if release.Version != "go"+version {
+			continue
 		}
 
-		checksumData, err := os.ReadFile(checksumPath)
+		for _, file := range release.Files {
+			if file.Kind == "archive" && file.OS == platform && file.Arch == arch {
+				return file, nil
+			}
+		}
+	}
+
+	return goReleaseFile{}, fmt.Errorf("%w: %s %s/%s", errGoArchiveNotPublished, version, platform, arch)
+}
+
+// ArtifactNames returns the archive Download stores for version and, unless
+// ASDF_GOLANG_SKIP_CHECKSUM is set, the checksum recorded next to it, which
+// Install needs to verify the archive without the go.dev/dl listing. Refs
+// need their source archive.
+func (*GolangPlugin) ArtifactNames(version string) ([]string, error) {
+	if _, isRef := asdf.ParseRefVersion(version); isRef {
+		return []string{goRefArchiveName}, nil
+	}
+
+	if asdf.PluginEnv("golang").Bool("SKIP_CHECKSUM", false) {
+		return []string{goArchiveName}, nil
+	}
+
+	return []string{goArchiveName, goArchiveName + asdf.ChecksumSidecarSuffix}, nil
+}
+
+// Download downloads the release archive of version and verifies it against
+// the SHA256 the go.dev/dl listing publishes, unless ASDF_GOLANG_SKIP_CHECKSUM
+// is set. A verified archive is kept in downloadPath, e.g. the downloads
+// directory of the data layout, and reused by later installs without asking
+// the listing again, such as the Go toolchains bootstrapped for other plugins.
+func (plugin *GolangPlugin) Download(ctx context.Context, version, downloadPath string) error {
+	archivePath := filepath.Join(downloadPath, goArchiveName)
+
+	if err := asdf.EnsureDir(downloadPath); err != nil {
+		return fmt.Errorf("creating download directory: %w", err)
+	}
+
+	if asdf.PluginEnv("golang").Bool("SKIP_CHECKSUM", false) {
+		asdf.Errf("Checksum verification skipped")
+
+		if _, err := os.Stat(archivePath); err == nil {
+			return nil
+		}
+
+		if err := asdf.OfflineDownloadError(plugin.Name(), version, archivePath); err != nil {
+			return err
+		}
+
+		artifacts, err := plugin.ResolveArtifacts(ctx, version)
 		if err != nil {
-			return fmt.Errorf("reading checksum file: %w", err)
+			return err
 		}
 
-		asdf.Msgf("Verifying checksum...")
+		asdf.Msgf("Downloading Go %s from %s", version, artifacts[0].URL)
 
-		if err := asdf.VerifySHA256(archivePath, string(checksumData)); err != nil {
-			return fmt.Errorf("checksum verification failed: %w", err)
+		if err := asdf.DownloadFile(ctx, artifacts[0].URL, archivePath); err != nil {
+			return fmt.Errorf("downloading Go %s: %w", version, err)
 		}
 
-		asdf.Msgf("Checksum verified")
-	} else {
-		asdf.Errf("Checksum verification skipped")
+		return nil
 	}
 
+	if asdf.VerifyChecksumSidecar(archivePath) == nil {
+		asdf.Msgf("Using cached download for Go %s", version)
+
+		return nil
+	}
+
+	// Offline, an archive without a matching recorded checksum cannot be verified.
+	err := asdf.OfflineDownloadError(plugin.Name(), version, archivePath, archivePath+asdf.ChecksumSidecarSuffix)
+	if err != nil {
+		return err
+	}
+
+	archive, err := plugin.publishedArchive(ctx, version)
+	if err != nil {
+		return err
+	}
+
+	downloadURL := plugin.DownloadURL + "/" + archive.Filename
+
+	asdf.Msgf("Downloading Go %s from %s", version, downloadURL)
+
+	expected := asdf.ExpectedFile{SHA256: archive.SHA256, Size: archive.Size}
+	if err := asdf.DownloadVerifiedFile(ctx, downloadURL, archivePath, expected); err != nil {
+		return fmt.Errorf("downloading Go %s: %w", version, err)
+	}
+
+	asdf.Msgf("Checksum verified")
+
 	return nil
 }
 
@@ -291,13 +502,15 @@ func (plugin *GolangPlugin) Install(
 	ctx context.Context,
 	version, downloadPath, installPath string,
 ) error {
-	archivePath := filepath.Join(downloadPath, "archive.tar.gz")
+	if ref, isRef := asdf.ParseRefVersion(version); isRef {
+		return plugin.InstallRef(ctx, ref, downloadPath, installPath)
+	}
 
-	if _, err := os.Stat(archivePath); os.IsNotExist(err) {
-		err := plugin.Download(ctx, version, downloadPath)
-		if err != nil {
-			return err
-		}
+	archivePath := filepath.Join(downloadPath, goArchiveName)
+
+	// Download reuses a verified archive, and verifies any other before it is extracted.
+	if err := plugin.Download(ctx, version, downloadPath); err != nil {
+		return err
 	}
 
 	asdf.Msgf("Installing Go %s to %s", version, installPath)
@@ -312,7 +525,7 @@ func (plugin *GolangPlugin) Install(
 		return fmt.Errorf("extracting archive: %w", err)
 	}
 
-	err = plugin.installDefaultPackages(ctx, version, installPath)
+	err = plugin.installDefaultPackages(ctx, goInstallSupported(version), installPath)
 	if err != nil {
 		asdf.Errf("Warning: failed to install default packages: %v", err)
 	}
@@ -322,12 +535,112 @@ func (plugin *GolangPlugin) Install(
 	return nil
 }
 
-// installDefaultPackages installs packages from ~/.default-golang-pkgs.
+// SupportsRefs reports that Go refs can be built.
+func (*GolangPlugin) SupportsRefs() bool {
+	return true
+}
+
+// InstallRef builds the Go toolchain at ref, e.g. "master" for tip, from its
+// GitHub source archive with make.bash, bootstrapped by GOROOT_BOOTSTRAP or
+// the go in PATH.
+func (plugin *GolangPlugin) InstallRef(ctx context.Context, ref, downloadPath, installPath string) error {
+	bootstrap, err := goBootstrapRoot(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := asdf.EnsureDir(downloadPath); err != nil {
+		return fmt.Errorf("creating download directory: %w", err)
+	}
+
+	sourceURL := asdf.GitHubRefArchiveURL("golang", "go", ref)
+	archivePath := filepath.Join(downloadPath, goRefArchiveName)
+
+	// Offline, a pre-seeded source archive is built as is.
+	if _, err := os.Stat(archivePath); err != nil || !asdf.Offline() {
+		if err := asdf.OfflineDownloadError(plugin.Name(), ref, archivePath); err != nil {
+			return err
+		}
+
+		asdf.Msgf("Downloading Go %s source from %s", ref, sourceURL)
+
+		if err := asdf.DownloadFile(ctx, sourceURL, archivePath); err != nil {
+			return fmt.Errorf("downloading Go %s: %w", ref, err)
+		}
+	}
+
+	goRoot := filepath.Join(installPath, "go")
+
+	if err := asdf.EnsureDir(goRoot); err != nil {
+		return fmt.Errorf("creating install directory: %w", err)
+	}
+
+	if err := asdf.ExtractTarGzRoot(archivePath, goRoot); err != nil {
+		return fmt.Errorf("extracting archive: %w", err)
+	}
+
+	// Outside of a git checkout, make.bash reads the version from VERSION.
+	versionPath := filepath.Join(goRoot, "VERSION")
+	if _, err := os.Stat(versionPath); os.IsNotExist(err) {
+		err := os.WriteFile(versionPath, []byte("devel "+ref+"\n"), asdf.CommonFilePermission)
+		if err != nil {
+			return fmt.Errorf("writing VERSION: %w", err)
+		}
+	}
+
+	asdf.Msgf("Building Go %s with %s", ref, bootstrap)
+
+	execer := asdf.BuildExecer{Env: map[string]string{goBootstrapEnv: bootstrap}}
+	srcDir := filepath.Join(goRoot, "src")
+
+	cmd := execer.Command(ctx, filepath.Join(srcDir, "make.bash"))
+	cmd.Dir = srcDir
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("building Go %s: %w", ref, err)
+	}
+
+	// Refs build the development tree, which has go install.
+	err = plugin.installDefaultPackages(ctx, true, installPath)
+	if err != nil {
+		asdf.Errf("Warning: failed to install default packages: %v", err)
+	}
+
+	asdf.Msgf("Go %s installed successfully", ref)
+
+	return nil
+}
+
+// goBootstrapRoot returns the GOROOT of the toolchain bootstrapping a ref
+// build, GOROOT_BOOTSTRAP or the one of the go in PATH.
+func goBootstrapRoot(ctx context.Context) (string, error) {
+	if root := os.Getenv(goBootstrapEnv); root != "" {
+		return root, nil
+	}
+
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		return "", fmt.Errorf("%w: set %s or put go in PATH", errGoBootstrapNotFound, goBootstrapEnv)
+	}
+
+	output, err := exec.CommandContext(ctx, goPath, "env", "GOROOT").Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s env GOROOT: %w", errGoBootstrapNotFound, goPath, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// installDefaultPackages installs packages from ~/.default-golang-pkgs, with
+// go install when useInstall is set and go get otherwise.
 func (*GolangPlugin) installDefaultPackages(
 	ctx context.Context,
-	version, installPath string,
+	useInstall bool,
+	installPath string,
 ) error {
-	defaultPkgsFile := os.Getenv("ASDF_GOLANG_DEFAULT_PACKAGES_FILE")
+	defaultPkgsFile := asdf.PluginEnv("golang").String("DEFAULT_PACKAGES_FILE", "")
 	if defaultPkgsFile == "" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
@@ -349,11 +662,18 @@ func (*GolangPlugin) installDefaultPackages(
 
 	goBin := filepath.Join(installPath, "go", "bin", "go")
 	goRoot := filepath.Join(installPath, "go")
-	goPath := filepath.Join(installPath, "packages")
+	goPath := filepath.Join(installPath, goPathDir)
 	goBinDir := filepath.Join(installPath, "bin")
 
-	parts := asdf.ParseVersionParts(version)
-	useInstall := len(parts) >= 2 && (parts[0] >= 2 || (parts[0] == 1 && parts[1] >= 16))
+	execer := asdf.BuildExecer{
+		Env: map[string]string{
+			"PATH":   filepath.Join(goRoot, "bin"),
+			"GOROOT": goRoot,
+			"GOPATH": goPath,
+			"GOBIN":  goBinDir,
+		},
+		Passthrough: goModuleEnvPassthrough,
+	}
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -377,18 +697,11 @@ func (*GolangPlugin) installDefaultPackages(
 				pkg += "@latest"
 			}
 
-			cmd = exec.CommandContext(ctx, goBin, "install", pkg)
+			cmd = execer.Command(ctx, goBin, "install", pkg)
 		} else {
-			cmd = exec.CommandContext(ctx, goBin, "get", "-u", line)
+			cmd = execer.Command(ctx, goBin, "get", "-u", line)
 		}
 
-		cmd.Env = append(os.Environ(),
-			"GOROOT="+goRoot,
-			"GOPATH="+goPath,
-			"GOBIN="+goBinDir,
-			"PATH="+filepath.Join(goRoot, "bin")+":"+os.Getenv("PATH"),
-		)
-
 		err := cmd.Run()
 		if err != nil {
 			asdf.Errf("Failed to install %s: %v", line, err)
@@ -400,6 +713,14 @@ func (*GolangPlugin) installDefaultPackages(
 	return scanner.Err()
 }
 
+// goInstallSupported reports whether Go version has go install with version
+// suffixes, added in Go 1.16.
+func goInstallSupported(version string) bool {
+	parts := asdf.ParseVersionParts(version)
+
+	return len(parts) >= 2 && (parts[0] >= 2 || (parts[0] == 1 && parts[1] >= 16))
+}
+
 // parseGoTags extracts version numbers from GitHub tag names.
 func parseGoTags(tags []string) []string {
 	var versions []string
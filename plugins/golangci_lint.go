@@ -28,6 +28,7 @@ func NewGolangciLintPlugin() asdf.Plugin {
 		BinaryName: "golangci-lint",
 
 		FileNameTemplate: "golangci-lint-{{.Version}}-{{.Platform}}-{{.Arch}}.tar.gz",
+		PostInstallCheck: "{{.BinaryPath}} --version",
 		HelpDescription:  "golangci-lint - Fast linters runner for Go",
 		HelpLink:         "https://github.com/golangci/golangci-lint",
 		ArchiveType:      "tar.gz",
@@ -32,5 +32,8 @@ func NewHelmPlugin() asdf.Plugin {
 		HelpDescription:     "Helm - The Kubernetes Package Manager",
 		HelpLink:            "https://github.com/helm/helm",
 		ArchiveType:         "tar.gz",
+		// Plugin, repository and cache state helm writes when its data and
+		// cache homes point into the install.
+		HashIgnoreGlobs: []string{"cache", "plugins", "repository"},
 	})
 }
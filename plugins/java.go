@@ -0,0 +1,445 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+var (
+	// errJavaNoVersionsFound is returned when no Java versions are discovered.
+	errJavaNoVersionsFound = errors.New("no versions found")
+	// errJavaNoVersionsMatching is returned when no versions match a LatestStable query.
+	errJavaNoVersionsMatching = errors.New("no versions matching query")
+	// errJavaFetchReleasesFailed indicates a non-success HTTP response when listing releases.
+	errJavaFetchReleasesFailed = errors.New("failed to fetch Adoptium releases")
+	// errJavaUnsupportedPlatform is returned when Temurin publishes no builds for the current OS or CPU.
+	errJavaUnsupportedPlatform = errors.New("unsupported platform")
+	// errJavaInvalidVersion is returned when a version is not a Temurin release version.
+	errJavaInvalidVersion = errors.New("not a temurin version")
+	// errJavaSdkmanVendorUnsupported is returned when .sdkmanrc selects a JDK other than Temurin.
+	errJavaSdkmanVendorUnsupported = errors.New("unsupported .sdkmanrc java distribution")
+)
+
+const (
+	// javaAdoptiumAPIURL is the Adoptium API publishing the Temurin releases.
+	javaAdoptiumAPIURL = "https://api.adoptium.net"
+	// javaVersionPrefix marks the versions of the Temurin distribution.
+	javaVersionPrefix = "temurin-"
+	// javaSdkmanSuffix is the SDKMAN! identifier suffix of Temurin builds, as in "21.0.3-tem".
+	javaSdkmanSuffix = "-tem"
+	// javaReleaseNamesPageSize is the largest page of release names the API returns.
+	javaReleaseNamesPageSize = 20
+)
+
+var (
+	// javaReleaseName matches Adoptium release names: "jdk-21.0.3+9", "jdk-21+35" and "jdk8u412-b08".
+	javaReleaseName = regexp.MustCompile(`^jdk-(\d+(?:\.\d+)*\+\d+)$|^jdk8u(\d+)-b(\d+)$`)
+	// javaLegacyVersion matches the Temurin version of a JDK 8 release, "8.0.412+8".
+	javaLegacyVersion = regexp.MustCompile(`^8\.0\.(\d+)\+(\d+)$`)
+)
+
+// JavaPlugin implements the asdf.Plugin interface for the Eclipse Temurin JDK.
+type JavaPlugin struct {
+	// APIURL is the base URL of the Adoptium API.
+	APIURL string
+}
+
+// NewJavaPlugin creates a new Java plugin instance.
+func NewJavaPlugin() asdf.Plugin {
+	return &JavaPlugin{APIURL: javaAdoptiumAPIURL}
+}
+
+// Name returns the plugin name.
+func (*JavaPlugin) Name() string {
+	return "java"
+}
+
+// javaHome returns the JDK home of the installation at installPath, which is
+// the Contents/Home directory of the bundle on macOS.
+func javaHome(installPath string) string {
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(installPath, "Contents", "Home")
+	}
+
+	return installPath
+}
+
+// ListBinPaths returns the binary paths for Java installations.
+func (*JavaPlugin) ListBinPaths() string {
+	if runtime.GOOS == "darwin" {
+		return "Contents/Home/bin"
+	}
+
+	return "bin"
+}
+
+// InstallRoot returns the JDK home, JAVA_HOME, of the installation.
+func (*JavaPlugin) InstallRoot(installPath string) string {
+	return javaHome(installPath)
+}
+
+// ExecEnv points JAVA_HOME at the JDK home.
+func (*JavaPlugin) ExecEnv(installPath string) map[string]string {
+	return map[string]string{"JAVA_HOME": javaHome(installPath)}
+}
+
+// ListLegacyFilenames returns the version files of jenv and SDKMAN!.
+func (*JavaPlugin) ListLegacyFilenames() []string {
+	return []string{".java-version", ".sdkmanrc"}
+}
+
+// ParseLegacyFile returns the version pinned by a .java-version file or by the
+// java entry of a .sdkmanrc file, which must select a Temurin build.
+func (*JavaPlugin) ParseLegacyFile(path string) (string, error) {
+	if filepath.Base(path) != ".sdkmanrc" {
+		version, err := asdf.ReadVersionFile(path)
+		if err != nil {
+			return "", err
+		}
+
+		return javaPinnedVersion(version), nil
+	}
+
+	data, err := asdf.ReadTextFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	for line := range strings.Lines(data) {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found || strings.TrimSpace(key) != "java" {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		version, found := strings.CutSuffix(value, javaSdkmanSuffix)
+		if !found {
+			return "", fmt.Errorf("%w: %s (only Temurin, %s, is supported)", errJavaSdkmanVendorUnsupported, value, javaSdkmanSuffix)
+		}
+
+		return javaPinnedVersion(version), nil
+	}
+
+	return "", nil
+}
+
+// javaPinnedVersion maps a JDK version to the Temurin version it pins. A
+// version with a build number is exact; others select the latest build, so
+// "21.0.3" becomes "latest:temurin-21.0.3+" and "21" "latest:temurin-21".
+func javaPinnedVersion(version string) string {
+	version = strings.TrimPrefix(version, javaVersionPrefix)
+
+	switch {
+	case strings.Contains(version, "+"):
+		return javaVersionPrefix + version
+	case strings.Count(version, ".") == 2:
+		return asdf.LatestVersionPrefix + javaVersionPrefix + version + "+"
+	default:
+		return asdf.LatestVersionPrefix + javaVersionPrefix + version
+	}
+}
+
+// Uninstall removes a Java installation.
+func (*JavaPlugin) Uninstall(_ context.Context, installPath string) error {
+	return os.RemoveAll(installPath)
+}
+
+// Help returns help information for the Java plugin.
+func (*JavaPlugin) Help() asdf.PluginHelp {
+	return asdf.PluginHelp{
+		Overview: `Java - The Eclipse Temurin build of OpenJDK.
+This plugin lists and downloads JDK builds through the Adoptium API.`,
+		Deps: `No additional dependencies required.`,
+		Config: `Versions are named after the Temurin release, e.g. temurin-21.0.3+9;
+"asdf latest java 21" selects the newest JDK 21 build.
+
+Reads .java-version files and the java entry of .sdkmanrc files
+(e.g. java=21.0.3-tem). Versions without a build number select the latest build.
+
+exec-env exports JAVA_HOME (the JDK home, Contents/Home on macOS).`,
+		Links: `Homepage: https://adoptium.net/
+API: https://api.adoptium.net/q/swagger-ui/`,
+	}
+}
+
+// platform returns the current OS and architecture as named by the Adoptium API.
+func (*JavaPlugin) platform() (string, string, error) {
+	platform, err := asdf.GetPlatform()
+	if err != nil {
+		return "", "", err
+	}
+
+	arch, err := asdf.GetArch()
+	if err != nil {
+		return "", "", err
+	}
+
+	osName, ok := map[string]string{"linux": "linux", "darwin": "mac"}[platform]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", errJavaUnsupportedPlatform, platform)
+	}
+
+	archName, ok := map[string]string{"amd64": "x64", "arm64": "aarch64"}[arch]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s (set %s to select another architecture)",
+			errJavaUnsupportedPlatform, arch, asdf.ForceArchEnv)
+	}
+
+	return osName, archName, nil
+}
+
+// ListAll lists the Temurin JDK releases published for the current OS and architecture.
+func (plugin *JavaPlugin) ListAll(ctx context.Context) ([]string, error) {
+	osName, archName, err := plugin.platform()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+
+	for page := 0; ; page++ {
+		names, err := plugin.fetchReleaseNames(ctx, osName, archName, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			if version, ok := javaVersion(name); ok {
+				versions = append(versions, version)
+			}
+		}
+
+		if len(names) < javaReleaseNamesPageSize {
+			break
+		}
+	}
+
+	asdf.SortVersions(versions)
+
+	return versions, nil
+}
+
+// fetchReleaseNames returns a page of the GA JDK release names of the platform.
+// The API answers 404 for pages past the last one.
+func (plugin *JavaPlugin) fetchReleaseNames(ctx context.Context, osName, archName string, page int) ([]string, error) {
+	query := neturl.Values{
+		"architecture": {archName},
+		"heap_size":    {"normal"},
+		"image_type":   {"jdk"},
+		"os":           {osName},
+		"page":         {fmt.Sprint(page)},
+		"page_size":    {fmt.Sprint(javaReleaseNamesPageSize)},
+		"project":      {"jdk"},
+		"release_type": {"ga"},
+		"sort_order":   {"ASC"},
+		"vendor":       {"eclipse"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		plugin.APIURL+"/v3/info/release_names?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := asdf.HTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching Java versions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	asdf.LogHTTPResponse(ctx, resp)
+
+	if resp.StatusCode == http.StatusNotFound && page > 0 {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", errJavaFetchReleasesFailed, resp.StatusCode)
+	}
+
+	var releases struct {
+		Releases []string `json:"releases"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("decoding Java versions: %w", err)
+	}
+
+	return releases.Releases, nil
+}
+
+// javaVersion returns the Temurin version of an Adoptium release name:
+// "jdk-21.0.3+9" is "temurin-21.0.3+9" and "jdk8u412-b08" "temurin-8.0.412+8".
+func javaVersion(releaseName string) (string, bool) {
+	match := javaReleaseName.FindStringSubmatch(releaseName)
+	if match == nil {
+		return "", false
+	}
+
+	if match[1] != "" {
+		return javaVersionPrefix + match[1], true
+	}
+
+	return javaVersionPrefix + "8.0." + match[2] + "+" + strings.TrimLeft(match[3], "0"), true
+}
+
+// javaRelease returns the Adoptium release name of a Temurin version, the
+// inverse of javaVersion.
+func javaRelease(version string) (string, error) {
+	plain, found := strings.CutPrefix(version, javaVersionPrefix)
+	if !found || !strings.Contains(plain, "+") {
+		return "", fmt.Errorf("%w: %s (expected e.g. %s21.0.3+9)", errJavaInvalidVersion, version, javaVersionPrefix)
+	}
+
+	if match := javaLegacyVersion.FindStringSubmatch(plain); match != nil {
+		build := match[2]
+		if len(build) == 1 {
+			build = "0" + build
+		}
+
+		return "jdk8u" + match[1] + "-b" + build, nil
+	}
+
+	return "jdk-" + plain, nil
+}
+
+// LatestStable returns the latest Temurin version matching query, which may
+// omit the "temurin-" prefix: "21" selects the newest JDK 21 build.
+func (plugin *JavaPlugin) LatestStable(ctx context.Context, query string) (string, error) {
+	versions, err := plugin.ListAll(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(query, javaVersionPrefix) {
+		query = javaVersionPrefix + query
+	}
+
+	return asdf.LatestStableWithQuery(ctx, query, versions, errJavaNoVersionsFound, errJavaNoVersionsMatching)
+}
+
+// artifact returns the archive of version for the current platform with its
+// download and checksum URLs.
+func (plugin *JavaPlugin) artifact(version string) (asdf.Artifact, string, error) {
+	release, err := javaRelease(version)
+	if err != nil {
+		return asdf.Artifact{}, "", err
+	}
+
+	osName, archName, err := plugin.platform()
+	if err != nil {
+		return asdf.Artifact{}, "", err
+	}
+
+	path := fmt.Sprintf("/version/%s/%s/%s/jdk/hotspot/normal/eclipse", neturl.PathEscape(release), osName, archName)
+
+	return asdf.Artifact{
+		Name: fmt.Sprintf("%s-%s-%s.tar.gz", version, osName, archName),
+		URL:  plugin.APIURL + "/v3/binary" + path,
+	}, plugin.APIURL + "/v3/checksum" + path, nil
+}
+
+// ArtifactNames returns the name of the archive Download stores for version.
+func (plugin *JavaPlugin) ArtifactNames(version string) ([]string, error) {
+	artifact, _, err := plugin.artifact(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{artifact.Name}, nil
+}
+
+// ResolveArtifacts returns the archive Download fetches for version.
+func (plugin *JavaPlugin) ResolveArtifacts(_ context.Context, version string) ([]asdf.Artifact, error) {
+	artifact, _, err := plugin.artifact(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return []asdf.Artifact{artifact}, nil
+}
+
+// Download downloads the JDK archive of version and verifies it against the
+// SHA256 published by the Adoptium API.
+func (plugin *JavaPlugin) Download(ctx context.Context, version, downloadPath string) error {
+	artifact, checksumURL, err := plugin.artifact(version)
+	if err != nil {
+		return err
+	}
+
+	archivePath := filepath.Join(downloadPath, artifact.Name)
+	if asdf.VerifyChecksumSidecar(archivePath) == nil {
+		asdf.Msgf("Using cached download for java %s", version)
+
+		return nil
+	}
+
+	checksum, err := asdf.DownloadString(ctx, checksumURL)
+	if err != nil {
+		return fmt.Errorf("fetching java checksum: %w", err)
+	}
+
+	fields := strings.Fields(checksum)
+	if len(fields) == 0 {
+		return fmt.Errorf("fetching java checksum: %s is empty", checksumURL)
+	}
+
+	asdf.Msgf("Downloading java %s from %s", version, artifact.URL)
+
+	if err := asdf.DownloadVerifiedFile(ctx, artifact.URL, archivePath, asdf.ExpectedFile{SHA256: fields[0]}); err != nil {
+		return fmt.Errorf("downloading java: %w", err)
+	}
+
+	return nil
+}
+
+// Install installs the JDK from the downloaded archive, without its
+// jdk-<version> top-level directory.
+func (plugin *JavaPlugin) Install(
+	ctx context.Context,
+	version, downloadPath, installPath string,
+) error {
+	if err := plugin.Download(ctx, version, downloadPath); err != nil {
+		return err
+	}
+
+	artifact, _, err := plugin.artifact(version)
+	if err != nil {
+		return err
+	}
+
+	if err := asdf.ExtractTarGzRoot(filepath.Join(downloadPath, artifact.Name), installPath); err != nil {
+		return fmt.Errorf("installing java: %w", err)
+	}
+
+	asdf.Msgf("java %s installed successfully", version)
+
+	return nil
+}
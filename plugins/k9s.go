@@ -35,5 +35,10 @@ func NewK9sPlugin() asdf.Plugin {
 		HelpDescription: "K9s - Kubernetes CLI To Manage Your Clusters In Style",
 		HelpLink:        "https://github.com/derailed/k9s",
 		ArchiveType:     "tar.gz",
+
+		ExtraInstallFiles: []asdf.ExtraInstallFile{
+			{ArchivePathGlob: "completions/*", InstallRelDir: asdf.CompletionsDir},
+			{ArchivePathGlob: "*.1", InstallRelDir: "share/man/man1"},
+		},
 	})
 }
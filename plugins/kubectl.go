@@ -16,12 +16,29 @@
 package plugins
 
 import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+const (
+	// kubectlSkewCheckEnv enables the server version skew check after installs when set to 1.
+	kubectlSkewCheckEnv = "ASDF_KUBECTL_SKEW_CHECK"
+	// defaultKubeconfig is the kubeconfig of Kubernetes clients without
+	// KUBECONFIG, relative to the home directory.
+	defaultKubeconfig = ".kube/config"
+)
+
+// KubectlPlugin implements the asdf.Plugin interface for kubectl.
+type KubectlPlugin struct {
+	*asdf.BinaryPlugin
+}
+
 // NewKubectlPlugin creates a new kubectl plugin instance.
 func NewKubectlPlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+	return &KubectlPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
 		Name:       "kubectl",
 		RepoOwner:  "kubernetes",
 		RepoName:   "kubernetes",
@@ -34,5 +51,67 @@ func NewKubectlPlugin() asdf.Plugin {
 		DownloadURLTemplate: "https://dl.k8s.io/release/v{{.Version}}/bin/{{.Platform}}/{{.Arch}}/kubectl",
 		HelpDescription:     "Kubectl - Kubernetes command-line tool",
 		HelpLink:            "https://kubernetes.io/docs/reference/kubectl/",
+	})}
+}
+
+// LatestStable returns the latest stable kubectl version. A two-component
+// query such as "1.29" selects the newest patch release of that minor series.
+func (plugin *KubectlPlugin) LatestStable(ctx context.Context, query string) (string, error) {
+	return plugin.BinaryPlugin.LatestStable(ctx, asdf.MinorSeriesQuery(query))
+}
+
+// Install installs kubectl and, when ASDF_KUBECTL_SKEW_CHECK=1 and KUBECONFIG
+// is set, warns when it is more than one minor version away from the server.
+func (plugin *KubectlPlugin) Install(
+	ctx context.Context,
+	version, downloadPath, installPath string,
+) error {
+	err := plugin.BinaryPlugin.Install(ctx, version, downloadPath, installPath)
+	if err != nil {
+		return err
+	}
+
+	plugin.VersionSkew().Warn(ctx, "kubectl", filepath.Join(installPath, "bin", "kubectl"), version,
+		"which the Kubernetes version skew policy does not support")
+
+	return nil
+}
+
+// Help returns help information for the kubectl plugin.
+func (plugin *KubectlPlugin) Help() asdf.PluginHelp {
+	help := plugin.BinaryPlugin.Help()
+	help.Config = `Pin a minor series such as "kubectl 1.29" with latest to track its newest patch release.`
+	help.HelpConfigEntries = append(help.HelpConfigEntries, asdf.ConfigEntry{
+		Name:        kubectlSkewCheckEnv,
+		Description: "Set to 1 to warn after installs when kubectl is more than one minor version away from the server of the current KUBECONFIG",
 	})
+
+	return help
+}
+
+// VersionSkew checks kubectl against the server reported by 'kubectl version -o json'.
+func (*KubectlPlugin) VersionSkew() asdf.VersionSkew {
+	return asdf.VersionSkew{
+		EnableEnv:     kubectlSkewCheckEnv,
+		ConfigEnv:     "KUBECONFIG",
+		ProbeArgs:     []string{"version", "-o", "json"},
+		TimeoutFlag:   "--request-timeout",
+		MaxMinorSkew:  1,
+		ServerVersion: kubectlServerVersion,
+	}
+}
+
+// kubectlServerVersion returns the server gitVersion from 'kubectl version -o json' output.
+func kubectlServerVersion(output []byte) (string, error) {
+	var version struct {
+		ServerVersion struct {
+			GitVersion string `json:"gitVersion"`
+		} `json:"serverVersion"`
+	}
+
+	if err := json.Unmarshal(output, &version); err != nil {
+		return "", err
+	}
+
+	return version.ServerVersion.GitVersion, nil
 }
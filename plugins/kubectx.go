@@ -0,0 +1,44 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// NewKubectxPlugin creates a new kubectx plugin instance. Releases ship
+// kubectx and kubens in separate archives, and both are installed.
+func NewKubectxPlugin() asdf.Plugin {
+	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+		Name:       "kubectx",
+		RepoOwner:  "ahmetb",
+		RepoName:   "kubectx",
+		BinaryName: "kubectx",
+
+		VersionPrefix:    "v",
+		FileNameTemplate: "kubectx_v{{.Version}}_{{.Platform}}_{{.Arch}}.tar.gz",
+		ExtraBinaries: []asdf.ReleaseBinary{
+			{FileNameTemplate: "kubens_v{{.Version}}_{{.Platform}}_{{.Arch}}.tar.gz", BinaryName: "kubens"},
+		},
+		ArchMap: map[string]string{
+			"amd64": "x86_64",
+			"arm64": "arm64",
+		},
+		HelpDescription: "kubectx and kubens - Switch between Kubernetes contexts and namespaces",
+		HelpLink:        "https://github.com/ahmetb/kubectx",
+		ArchiveType:     "tar.gz",
+	})
+}
@@ -36,6 +36,11 @@ func NewLazygitPlugin() asdf.Plugin {
 		HelpLink:        "https://github.com/jesseduffield/lazygit",
 		ArchiveType:     "tar.gz",
 
+		ExtraInstallFiles: []asdf.ExtraInstallFile{
+			{ArchivePathGlob: "completions/*", InstallRelDir: asdf.CompletionsDir},
+			{ArchivePathGlob: "*.1", InstallRelDir: "share/man/man1"},
+		},
+
 		VersionFilter: `^\d+\.\d+\.\d+$`,
 	})
 }
@@ -16,12 +16,32 @@
 package plugins
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+// linkerdClusterCheckEnv enables the control plane version check after installs when set to 1.
+const linkerdClusterCheckEnv = "ASDF_LINKERD_CLUSTER_CHECK"
+
+// linkerdServerVersionPattern matches the control plane version reported by
+// 'linkerd version', e.g. "stable-2.14.10" or "enterprise-2.15.2", capturing
+// the version without its release channel.
+var linkerdServerVersionPattern = regexp.MustCompile(`^(?:[a-z]+-)?(\d+\.\d+\.\d+\S*)$`)
+
+// LinkerdPlugin implements the asdf.Plugin interface for the Linkerd CLI.
+type LinkerdPlugin struct {
+	*asdf.BinaryPlugin
+}
+
 // NewLinkerdPlugin creates a new linkerd plugin instance.
 func NewLinkerdPlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+	return &LinkerdPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
 		Name:       "linkerd",
 		RepoOwner:  "linkerd",
 		RepoName:   "linkerd2",
@@ -35,5 +55,66 @@ func NewLinkerdPlugin() asdf.Plugin {
 		ArchiveType:         "none",
 
 		VersionFilter: `^\d+\.\d+\.\d+`,
+	})}
+}
+
+// Install installs linkerd and, when ASDF_LINKERD_CLUSTER_CHECK=1 and a
+// kubeconfig is available, warns when the control plane of the cluster runs
+// another minor version.
+func (plugin *LinkerdPlugin) Install(
+	ctx context.Context,
+	version, downloadPath, installPath string,
+) error {
+	err := plugin.BinaryPlugin.Install(ctx, version, downloadPath, installPath)
+	if err != nil {
+		return err
+	}
+
+	plugin.VersionSkew().Warn(ctx, "linkerd", filepath.Join(installPath, "bin", "linkerd"), version,
+		"install the version of the control plane or upgrade it, see 'linkerd check --pre'")
+
+	return nil
+}
+
+// Help returns help information for the linkerd plugin.
+func (plugin *LinkerdPlugin) Help() asdf.PluginHelp {
+	help := plugin.BinaryPlugin.Help()
+	help.HelpConfigEntries = append(help.HelpConfigEntries, asdf.ConfigEntry{
+		Name:        linkerdClusterCheckEnv,
+		Description: "Set to 1 to warn after installs when the control plane of the current cluster runs another minor version",
 	})
+
+	return help
+}
+
+// VersionSkew checks linkerd against the control plane reported by 'linkerd version'.
+func (*LinkerdPlugin) VersionSkew() asdf.VersionSkew {
+	return asdf.VersionSkew{
+		EnableEnv:     linkerdClusterCheckEnv,
+		ConfigEnv:     "KUBECONFIG",
+		ConfigFile:    defaultKubeconfig,
+		ProbeArgs:     []string{"version"},
+		ServerVersion: linkerdServerVersion,
+	}
+}
+
+// linkerdServerVersion returns the control plane version from the
+// "Server version:" line of 'linkerd version' output, which has no JSON form.
+// It is empty when the server version is unavailable.
+func linkerdServerVersion(output []byte) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		value, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "Server version:")
+		if !found {
+			continue
+		}
+
+		if match := linkerdServerVersionPattern.FindStringSubmatch(strings.TrimSpace(value)); match != nil {
+			return match[1], nil
+		}
+
+		return "", nil
+	}
+
+	return "", scanner.Err()
 }
@@ -0,0 +1,283 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugins
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+var (
+	// errMavenNoVersionsFound is returned when no Maven versions are discovered.
+	errMavenNoVersionsFound = errors.New("no versions found")
+	// errMavenNoVersionsMatching is returned when no versions match a LatestStable query.
+	errMavenNoVersionsMatching = errors.New("no versions matching query")
+	// errMavenChecksumMismatch is returned when an archive does not match its published SHA512.
+	errMavenChecksumMismatch = errors.New("checksum mismatch")
+	// errMavenDistributionURLNotFound is returned when maven-wrapper.properties names no Maven distribution.
+	errMavenDistributionURLNotFound = errors.New("no maven distributionUrl found")
+)
+
+const (
+	// mavenDistURL is the Apache archive directory holding the Maven release series.
+	mavenDistURL = "https://archive.apache.org/dist/maven/"
+	// mavenWrapperProperties is the Maven wrapper configuration naming the distribution it downloads.
+	mavenWrapperProperties = ".mvn/wrapper/maven-wrapper.properties"
+	// mavenChecksumSuffix names the published SHA512 of an archive.
+	mavenChecksumSuffix = ".sha512"
+)
+
+var (
+	// mavenSeries are the release series directories listed below DistURL.
+	mavenSeries = []string{"maven-3", "maven-4"} //nolint:gochecknoglobals // read-only lookup table
+	// mavenIndexVersion matches the version directories of a release series index.
+	mavenIndexVersion = regexp.MustCompile(`href="(\d+(?:\.\d+)+(?:-[0-9A-Za-z.-]+)?)/"`)
+	// mavenDistributionVersion matches the version of a distributionUrl.
+	mavenDistributionVersion = regexp.MustCompile(`apache-maven-(\d[^/]*?)-bin\.(?:zip|tar\.gz)`)
+)
+
+// MavenPlugin implements the asdf.Plugin interface for Apache Maven.
+type MavenPlugin struct {
+	// DistURL is the directory holding the maven-3 and maven-4 release series.
+	DistURL string
+}
+
+// NewMavenPlugin creates a new Maven plugin instance.
+func NewMavenPlugin() asdf.Plugin {
+	return &MavenPlugin{DistURL: mavenDistURL}
+}
+
+// Name returns the plugin name.
+func (*MavenPlugin) Name() string {
+	return "maven"
+}
+
+// ListBinPaths returns the binary paths for Maven installations.
+func (*MavenPlugin) ListBinPaths() string {
+	return "bin"
+}
+
+// ExecEnv points M2_HOME at the installation.
+func (*MavenPlugin) ExecEnv(installPath string) map[string]string {
+	return map[string]string{"M2_HOME": installPath}
+}
+
+// ListLegacyFilenames returns the Maven wrapper configuration.
+func (*MavenPlugin) ListLegacyFilenames() []string {
+	return []string{mavenWrapperProperties}
+}
+
+// ParseLegacyFile returns the Maven version of the distributionUrl of a
+// maven-wrapper.properties file.
+func (*MavenPlugin) ParseLegacyFile(path string) (string, error) {
+	data, err := asdf.ReadTextFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	for line := range strings.Lines(data) {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found || strings.TrimSpace(key) != "distributionUrl" {
+			continue
+		}
+
+		if match := mavenDistributionVersion.FindStringSubmatch(value); match != nil {
+			return match[1], nil
+		}
+	}
+
+	return "", fmt.Errorf("%w in %s", errMavenDistributionURLNotFound, path)
+}
+
+// Uninstall removes a Maven installation.
+func (*MavenPlugin) Uninstall(_ context.Context, installPath string) error {
+	return os.RemoveAll(installPath)
+}
+
+// Help returns help information for the Maven plugin.
+func (*MavenPlugin) Help() asdf.PluginHelp {
+	return asdf.PluginHelp{
+		Overview: `Apache Maven - A build automation and project management tool for Java.
+This plugin downloads the binary distribution from the Apache archive and
+verifies it against the published SHA512.`,
+		Deps: `Requires a Java runtime, found through JAVA_HOME or PATH.`,
+		Config: `Reads the distributionUrl of ` + mavenWrapperProperties + `.
+
+exec-env exports M2_HOME (the install path).`,
+		Links: `Homepage: https://maven.apache.org/
+Downloads: https://maven.apache.org/download.cgi
+Source: https://github.com/apache/maven`,
+	}
+}
+
+// ListAll lists the Maven 3 and 4 versions published in the Apache archive.
+func (plugin *MavenPlugin) ListAll(ctx context.Context) ([]string, error) {
+	var versions []string
+
+	seen := make(map[string]bool)
+
+	for _, series := range mavenSeries {
+		content, err := asdf.DownloadString(ctx, plugin.DistURL+series+"/")
+		if err != nil {
+			return nil, fmt.Errorf("fetching Maven versions: %w", err)
+		}
+
+		for _, match := range mavenIndexVersion.FindAllStringSubmatch(content, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				versions = append(versions, match[1])
+			}
+		}
+	}
+
+	asdf.SortVersions(versions)
+
+	return versions, nil
+}
+
+// LatestStable returns the latest stable Maven version.
+func (plugin *MavenPlugin) LatestStable(ctx context.Context, query string) (string, error) {
+	versions, err := plugin.ListAll(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return asdf.LatestStableWithQuery(ctx, query, versions, errMavenNoVersionsFound, errMavenNoVersionsMatching)
+}
+
+// mavenArchiveName returns the binary distribution archive of version.
+func mavenArchiveName(version string) string {
+	return "apache-maven-" + version + "-bin.tar.gz"
+}
+
+// archiveURL returns the download URL of the binary distribution of version.
+func (plugin *MavenPlugin) archiveURL(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+
+	return plugin.DistURL + "maven-" + major + "/" + version + "/binaries/" + mavenArchiveName(version)
+}
+
+// ArtifactNames returns the archive Download stores for version and its checksum.
+func (*MavenPlugin) ArtifactNames(version string) ([]string, error) {
+	archive := mavenArchiveName(version)
+
+	return []string{archive, archive + mavenChecksumSuffix}, nil
+}
+
+// ResolveArtifacts returns the archive Download fetches for version and its checksum.
+func (plugin *MavenPlugin) ResolveArtifacts(_ context.Context, version string) ([]asdf.Artifact, error) {
+	archive, url := mavenArchiveName(version), plugin.archiveURL(version)
+
+	return []asdf.Artifact{
+		{Name: archive, URL: url},
+		{Name: archive + mavenChecksumSuffix, URL: url + mavenChecksumSuffix},
+	}, nil
+}
+
+// Download downloads the binary distribution of version with its published
+// SHA512, and deletes the archive when it does not match.
+func (plugin *MavenPlugin) Download(ctx context.Context, version, downloadPath string) error {
+	archivePath := filepath.Join(downloadPath, mavenArchiveName(version))
+	checksumPath := archivePath + mavenChecksumSuffix
+
+	if verifyMavenArchive(archivePath, checksumPath) == nil {
+		asdf.Msgf("Using cached download for maven %s", version)
+
+		return nil
+	}
+
+	url := plugin.archiveURL(version)
+
+	asdf.Msgf("Downloading maven %s from %s", version, url)
+
+	if err := asdf.DownloadFile(ctx, url+mavenChecksumSuffix, checksumPath); err != nil {
+		return fmt.Errorf("downloading maven checksum: %w", err)
+	}
+
+	if err := asdf.DownloadFile(ctx, url, archivePath); err != nil {
+		return fmt.Errorf("downloading maven: %w", err)
+	}
+
+	if err := verifyMavenArchive(archivePath, checksumPath); err != nil {
+		_ = os.Remove(archivePath)
+
+		return fmt.Errorf("verifying %s: %w", url, err)
+	}
+
+	return nil
+}
+
+// verifyMavenArchive checks archivePath against the SHA512 in checksumPath,
+// which holds the hex checksum optionally followed by the file name.
+func verifyMavenArchive(archivePath, checksumPath string) error {
+	data, err := os.ReadFile(checksumPath)
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return fmt.Errorf("%w: %s is empty", errMavenChecksumMismatch, checksumPath)
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha512.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("computing checksum: %w", err)
+	}
+
+	expected, actual := strings.ToLower(fields[0]), hex.EncodeToString(hash.Sum(nil))
+	if actual != expected {
+		return fmt.Errorf("%w: expected %s, got %s", errMavenChecksumMismatch, expected, actual)
+	}
+
+	return nil
+}
+
+// Install installs Maven from the downloaded archive, without its
+// apache-maven-<version> top-level directory.
+func (plugin *MavenPlugin) Install(
+	ctx context.Context,
+	version, downloadPath, installPath string,
+) error {
+	if err := plugin.Download(ctx, version, downloadPath); err != nil {
+		return err
+	}
+
+	archivePath := filepath.Join(downloadPath, mavenArchiveName(version))
+	if err := asdf.ExtractTarGzRoot(archivePath, installPath); err != nil {
+		return fmt.Errorf("installing maven: %w", err)
+	}
+
+	asdf.Msgf("maven %s installed successfully", version)
+
+	return nil
+}
@@ -27,9 +27,14 @@ func NewNerdctlPlugin() asdf.Plugin {
 		RepoName:   "nerdctl",
 		BinaryName: "nerdctl",
 
+		// The minimal archive, not the nerdctl-full one bundling containerd.
 		FileNameTemplate: "nerdctl-{{.Version}}-{{.Platform}}-{{.Arch}}.tar.gz",
 		HelpDescription:  "nerdctl - Docker-compatible CLI for containerd",
 		HelpLink:         "https://github.com/containerd/nerdctl",
 		ArchiveType:      "tar.gz",
+		// nerdctl drives a local containerd, so no macOS builds are published.
+		OsMap: map[string]string{
+			"linux": "linux",
+		},
 	})
 }
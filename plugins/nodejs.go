@@ -41,8 +41,18 @@ var (
 	errNodeNoStableVersionFound = errors.New("no stable version found for query")
 	// errNodeChecksumNotFound is returned when the expected checksum entry cannot be found.
 	errNodeChecksumNotFound = errors.New("checksum not found")
+	// errNodeRefInvalid is returned for a ref that cannot be written to a node-build definition.
+	errNodeRefInvalid = errors.New("invalid ref")
+
+	// nodeRefRe matches the refs written to node-build definitions, which are shell scripts.
+	nodeRefRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
 )
 
+// nodejsSettings are the ASDF_NODEJS_* settings read through asdf.PluginEnv.
+var nodejsSettings = []asdf.EnvSetting{ //nolint:gochecknoglobals // read-only lookup table
+	{Key: "AUTO_ENABLE_COREPACK", Type: asdf.EnvTypeBool, Default: "false", Description: "Enable corepack after install"},
+}
+
 const (
 	// nodeBuildGitURL is the node-build repository.
 	nodeBuildGitURL = "https://github.com/nodenv/node-build.git"
@@ -52,6 +62,12 @@ const (
 	nodeIndexURL = "https://nodejs.org/dist/index.json"
 	// nodeGitRepoURL is the Node.js GitHub repository for releases.
 	nodeGitRepoURL = "https://github.com/nodejs/node"
+	// nodeDefaultPackagesFile lists npm packages to install into every new version.
+	nodeDefaultPackagesFile = ".default-npm-packages"
+	// nodeDefaultPackagesFileEnv overrides the location of the default packages file.
+	nodeDefaultPackagesFileEnv = "ASDF_NPM_DEFAULT_PACKAGES_FILE"
+	// nodeDefaultPackagesStrictEnv fails the install when a default package fails, when set to "1".
+	nodeDefaultPackagesStrictEnv = "ASDF_NPM_DEFAULT_PACKAGES_STRICT"
 )
 
 type (
@@ -128,7 +144,7 @@ func (*NodejsPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy Node.js version file.
 func (*NodejsPlugin) ParseLegacyFile(path string) (string, error) {
-	version, err := asdf.ReadLegacyVersionFile(path)
+	version, err := asdf.ReadVersionFile(path)
 	if err != nil {
 		return "", err
 	}
@@ -151,11 +167,16 @@ func (*NodejsPlugin) Help() asdf.PluginHelp {
 		Overview: `Node.js - A JavaScript runtime built on Chrome's V8 JavaScript engine.
 This plugin downloads pre-built Node.js binaries from https://nodejs.org/`,
 		Deps: `No system dependencies required - uses pre-built binaries.`,
-		Config: `Environment variables:
-  ASDF_NODEJS_DEFAULT_PACKAGES_FILE - Path to default npm packages file (default: ~/.default-npm-packages)
-  ASDF_NODEJS_AUTO_ENABLE_COREPACK - Enable corepack after install (default: false)
-  NODEJS_CHECK_SIGNATURES - Verify GPG signatures (default: strict)
-  NODEJS_ORG_MIRROR - Custom mirror URL for Node.js downloads`,
+		HelpConfigEntries: append(asdf.PluginEnv("nodejs").Document(nodejsSettings...).ConfigEntries(),
+			asdf.ConfigEntry{
+				Name:        nodeDefaultPackagesFileEnv,
+				Default:     "~/" + nodeDefaultPackagesFile,
+				Description: `Path to default npm packages file, one package per line, "#" comments allowed`,
+			},
+			asdf.ConfigEntry{
+				Name:        nodeDefaultPackagesStrictEnv,
+				Description: "Set to 1 to fail the install when a default package fails",
+			}),
 		Links: `Homepage: https://nodejs.org/
 Documentation: https://nodejs.org/docs/
 Downloads: https://nodejs.org/en/download/
@@ -531,6 +552,10 @@ func (plugin *NodejsPlugin) Install(
 	ctx context.Context,
 	version, downloadPath, installPath string,
 ) error {
+	if ref, isRef := asdf.ParseRefVersion(version); isRef {
+		return plugin.InstallRef(ctx, ref, downloadPath, installPath)
+	}
+
 	archivePath := filepath.Join(downloadPath, "node.tar.gz")
 
 	if _, err := os.Stat(archivePath); os.IsNotExist(err) {
@@ -551,12 +576,70 @@ func (plugin *NodejsPlugin) Install(
 		_ = os.RemoveAll(filepath.Join(downloadPath, "src"))
 	}
 
-	err = plugin.installDefaultPackages(ctx, installPath)
+	return plugin.finishInstall(ctx, version, installPath)
+}
+
+// SupportsRefs reports that Node.js refs can be built.
+func (*NodejsPlugin) SupportsRefs() bool {
+	return true
+}
+
+// InstallRef builds Node.js at ref from the nodejs/node repository with
+// node-build, through a definition cloning the ref.
+func (plugin *NodejsPlugin) InstallRef(ctx context.Context, ref, downloadPath, installPath string) error {
+	if !nodeRefRe.MatchString(ref) {
+		return fmt.Errorf("%w: %q", errNodeRefInvalid, ref)
+	}
+
+	if err := plugin.ensureNodeBuild(ctx); err != nil {
+		return err
+	}
+
+	if err := asdf.EnsureDir(downloadPath); err != nil {
+		return fmt.Errorf("creating download directory: %w", err)
+	}
+
+	definitionPath := filepath.Join(downloadPath, "node-ref")
+	definition := fmt.Sprintf("install_git %q %q %q standard\n", "node-"+filepath.Base(ref), nodeGitRepoURL+".git", ref)
+
+	if err := os.WriteFile(definitionPath, []byte(definition), asdf.CommonFilePermission); err != nil {
+		return fmt.Errorf("writing node-build definition: %w", err)
+	}
+
+	jobs := asdf.BuildConcurrencyFromContext(ctx)
+	asdf.Logger().InfoContext(ctx, "build concurrency", "tool", plugin.Name(), "version", ref, "jobs", jobs)
+	asdf.Msgf("Building Node.js %s to %s", ref, installPath)
+
+	cmd := exec.CommandContext(ctx, plugin.nodeBuildPath(), definitionPath, installPath)
+	cmd.Env = os.Environ()
+
+	for name, value := range asdf.BuildConcurrencyEnv(jobs) {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("building Node.js %s: %w", ref, err)
+	}
+
+	return plugin.finishInstall(ctx, ref, installPath)
+}
+
+// finishInstall installs the default npm packages into the Node.js installed
+// at installPath and enables corepack when asked to.
+func (plugin *NodejsPlugin) finishInstall(ctx context.Context, version, installPath string) error {
+	err := plugin.installDefaultPackages(ctx, installPath)
 	if err != nil {
+		if os.Getenv(nodeDefaultPackagesStrictEnv) == "1" {
+			return fmt.Errorf("installing default npm packages: %w", err)
+		}
+
 		asdf.Errf("Warning: failed to install default packages: %v", err)
 	}
 
-	if os.Getenv("ASDF_NODEJS_AUTO_ENABLE_COREPACK") != "" {
+	if asdf.PluginEnv("nodejs").Bool("AUTO_ENABLE_COREPACK", false) {
 		err := plugin.enableCorepack(ctx, installPath)
 		if err != nil {
 			asdf.Errf("Warning: failed to enable corepack: %v", err)
@@ -589,84 +672,34 @@ func (*NodejsPlugin) flattenExtractedDir(sourceDir, installPath string) error {
 	return nil
 }
 
-// installDefaultPackages installs packages from ~/.default-npm-packages.
+// installDefaultPackages npm-installs every package listed in ~/.default-npm-packages,
+// or in the file named by ASDF_NPM_DEFAULT_PACKAGES_FILE, with the freshly
+// installed node first on PATH. The working directory is never consulted, so
+// that installing inside a checkout cannot install packages it chose.
 func (*NodejsPlugin) installDefaultPackages(ctx context.Context, installPath string) error {
-	defaultPkgsFile := os.Getenv("ASDF_NPM_DEFAULT_PACKAGES_FILE")
-	if defaultPkgsFile == "" {
+	path := os.Getenv(nodeDefaultPackagesFileEnv)
+	if path == "" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
 			return nil
 		}
 
-		defaultPkgsFile = filepath.Join(homeDir, ".default-npm-packages")
-	}
-
-	if _, err := os.Stat(defaultPkgsFile); os.IsNotExist(err) {
-		return nil
+		path = filepath.Join(homeDir, nodeDefaultPackagesFile)
 	}
 
-	file, err := os.Open(defaultPkgsFile)
-	if err != nil {
+	packages, err := asdf.ReadPackagesFile(path)
+	if err != nil || len(packages) == 0 {
 		return err
 	}
-	defer file.Close()
-
-	npmPath := filepath.Join(installPath, "bin", "npm")
-	nodePath := filepath.Join(installPath, "bin", "node")
-
-	var packages []string
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		if strings.Contains(line, " -") || strings.HasPrefix(line, "-") {
-			args := strings.Fields(line)
-			asdf.Msgf("Running: npm install -g %s", line)
-
-			cmd := exec.CommandContext(ctx, npmPath, append([]string{"install", "-g"}, args...)...)
-
-			cmd.Env = append(os.Environ(),
-				"PATH="+filepath.Join(installPath, "bin")+":"+os.Getenv("PATH"),
-			)
-			cmd.Stdout = os.Stderr
 
-			cmd.Stderr = os.Stderr
-
-			err := cmd.Run()
-			if err != nil {
-				asdf.Errf("Failed to install %s: %v", line, err)
-			}
-
-			continue
-		}
-
-		packages = append(packages, strings.Fields(line)...)
+	binDir := filepath.Join(installPath, "bin")
+	env := map[string]string{
+		"PATH": binDir,
+		"NODE": filepath.Join(binDir, "node"),
 	}
 
-	if len(packages) > 0 {
-		asdf.Msgf("Running: npm install -g %s", strings.Join(packages, " "))
-
-		cmd := exec.CommandContext(ctx, npmPath, append([]string{"install", "-g"}, packages...)...)
-
-		cmd.Env = append(os.Environ(),
-			"PATH="+filepath.Join(installPath, "bin")+":"+os.Getenv("PATH"),
-			"NODE="+nodePath,
-		)
-		cmd.Stdout = os.Stderr
-
-		cmd.Stderr = os.Stderr
-
-		err := cmd.Run()
-		if err != nil {
-			asdf.Errf("Failed to install packages: %v", err)
-		}
-	}
-
-	return scanner.Err()
+	return asdf.InstallDefaultPackages(ctx, packages, os.Getenv(nodeDefaultPackagesStrictEnv) == "1",
+		env, filepath.Join(binDir, "npm"), "install", "-g")
 }
 
 // enableCorepack enables corepack for this Node.js installation.
@@ -680,9 +713,7 @@ func (*NodejsPlugin) enableCorepack(ctx context.Context, installPath string) err
 
 	cmd := exec.CommandContext(ctx, corepackPath, "enable")
 
-	cmd.Env = append(os.Environ(),
-		"PATH="+filepath.Join(installPath, "bin")+":"+os.Getenv("PATH"),
-	)
+	cmd.Env = asdf.PrependEnvList(os.Environ(), "PATH", filepath.Join(installPath, "bin"))
 	cmd.Stdout = os.Stderr
 	cmd.Stderr = os.Stderr
 
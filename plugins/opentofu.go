@@ -19,9 +19,14 @@ import (
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+// OpentofuPlugin implements the asdf.Plugin interface for OpenTofu.
+type OpentofuPlugin struct {
+	*asdf.BinaryPlugin
+}
+
 // NewOpentofuPlugin creates a new opentofu plugin instance.
 func NewOpentofuPlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+	return &OpentofuPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
 		Name:       "opentofu",
 		RepoOwner:  "opentofu",
 		RepoName:   "opentofu",
@@ -31,5 +36,30 @@ func NewOpentofuPlugin() asdf.Plugin {
 		HelpDescription:  "OpenTofu - The open source infrastructure as code tool",
 		HelpLink:         "https://github.com/opentofu/opentofu",
 		ArchiveType:      "tar.gz",
-	})
+	})}
+}
+
+// ListLegacyFilenames returns the OpenTofu version file, preferred, and the
+// Terraform one kept around by projects migrating from Terraform.
+func (*OpentofuPlugin) ListLegacyFilenames() []string {
+	return []string{".opentofu-version", ".terraform-version"}
+}
+
+// ParseLegacyFile returns the version of a .opentofu-version or .terraform-version file verbatim.
+func (*OpentofuPlugin) ParseLegacyFile(path string) (string, error) {
+	return asdf.ReadVersionFile(path)
+}
+
+// ExecEnv shares one provider cache between all OpenTofu and Terraform versions.
+func (*OpentofuPlugin) ExecEnv(_ string) map[string]string {
+	return terraformPluginCacheEnv()
+}
+
+// Help returns help information for the opentofu plugin.
+func (plugin *OpentofuPlugin) Help() asdf.PluginHelp {
+	help := plugin.BinaryPlugin.Help()
+	help.Config = "Reads .opentofu-version, or .terraform-version when there is none.\n\n" + terraformPluginCacheHelp
+	help.HelpConfigEntries = append(help.HelpConfigEntries, terraformPluginCacheEntry)
+
+	return help
 }
@@ -22,6 +22,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
@@ -33,6 +34,10 @@ var errPipxDownloadFailed = errors.New("download failed")
 const (
 	// pipxDownloadURL is the format string for constructing pipx release download URLs.
 	pipxDownloadURL = "https://github.com/pypa/pipx/releases/download/%s/pipx.pyz"
+
+	// pipxAppsDir is the PIPX_HOME of an install, holding the venvs of the apps
+	// installed with that pipx version and their executables in bin.
+	pipxAppsDir = "apps"
 )
 
 // PipxPlugin implements the asdf.Plugin interface for pipx.
@@ -80,7 +85,7 @@ func NewPipxPlugin() asdf.Plugin {
 
 			wrapperPath := filepath.Join(binDir, "pipx")
 			wrapperContent := fmt.Sprintf(`#!/bin/sh
-exec python3 "%s" "$@"
+exec "${PYTHON_ROOT:+$PYTHON_ROOT/bin/}python3" "%s" "$@"
 `, dstPath)
 
 			if err := os.WriteFile(wrapperPath, []byte(wrapperContent), asdf.CommonExecutablePermission); err != nil {
@@ -111,9 +116,28 @@ func (*PipxPlugin) ListBinPaths() string {
 	return "bin"
 }
 
-// ExecEnv returns environment variables for pipx execution.
-func (*PipxPlugin) ExecEnv(_ string) map[string]string {
-	return nil
+// ExecEnv points PIPX_HOME and PIPX_BIN_DIR into the install, so apps are
+// scoped to the pipx version and picked up by reshim.
+func (*PipxPlugin) ExecEnv(installPath string) map[string]string {
+	if installPath == "" {
+		return nil
+	}
+
+	return map[string]string{
+		"PIPX_HOME":    filepath.Join(installPath, pipxAppsDir),
+		"PIPX_BIN_DIR": filepath.Join(installPath, pipxAppsDir, "bin"),
+	}
+}
+
+// ExtraBinDirs returns the PIPX_BIN_DIR of the install, holding the apps
+// installed with pipx.
+func (*PipxPlugin) ExtraBinDirs(installPath string) []string {
+	return []string{filepath.Join(installPath, pipxAppsDir, "bin")}
+}
+
+// HashIgnoreGlobs leaves installed apps out of the pipx checksum.
+func (*PipxPlugin) HashIgnoreGlobs() []string {
+	return []string{pipxAppsDir}
 }
 
 // ListLegacyFilenames returns legacy version filenames for pipx.
@@ -123,7 +147,7 @@ func (*PipxPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy pipx version file.
 func (*PipxPlugin) ParseLegacyFile(path string) (string, error) {
-	return asdf.ReadLegacyVersionFile(path)
+	return asdf.ReadVersionFile(path)
 }
 
 // Uninstall removes a pipx installation.
@@ -131,15 +155,22 @@ func (*PipxPlugin) Uninstall(_ context.Context, installPath string) error {
 	return os.RemoveAll(installPath)
 }
 
+// InstallsInPlace reports that pipx installs in place, as its wrapper
+// script runs the zipapp by absolute path.
+func (*PipxPlugin) InstallsInPlace() bool {
+	return true
+}
+
 // Help returns help information for the pipx plugin.
 func (*PipxPlugin) Help() asdf.PluginHelp {
 	return asdf.PluginHelp{
 		Overview: `pipx - Install and Run Python Applications in Isolated Environments.
 This plugin downloads the pipx.pyz file from GitHub releases.`,
 		Deps: `Requires Python 3.8+ to be installed and available in PATH.`,
-		Config: `Environment variables:
-  PIPX_HOME - Override pipx home directory
-  PIPX_BIN_DIR - Override pipx bin directory`,
+		HelpConfigEntries: []asdf.ConfigEntry{
+			{Name: "PIPX_HOME", Default: "<install>/apps", Description: "Holds the venvs of installed apps"},
+			{Name: "PIPX_BIN_DIR", Default: "<install>/apps/bin", Description: "Holds the app binaries, which reshim exposes as shims"},
+		},
 		Links: `Homepage: https://pipx.pypa.io/
 Documentation: https://pipx.pypa.io/stable/
 Source: https://github.com/pypa/pipx`,
@@ -156,6 +187,22 @@ func (plugin *PipxPlugin) LatestStable(ctx context.Context, query string) (strin
 	return plugin.SourceBuildPlugin.LatestStable(ctx, query)
 }
 
+// ResolveArtifacts returns the zipapp Download fetches for version.
+func (*PipxPlugin) ResolveArtifacts(_ context.Context, version string) ([]asdf.Artifact, error) {
+	return []asdf.Artifact{{Name: "pipx.pyz", URL: fmt.Sprintf(pipxDownloadURL, version)}}, nil
+}
+
+// DescribeArtifacts returns the description of the zipapp, which runs on
+// any platform with Python, and the path of the pipx wrapper script.
+func (plugin *PipxPlugin) DescribeArtifacts(_ string) (asdf.ArtifactDescription, error) {
+	return asdf.ArtifactDescription{
+		Platform:    "any",
+		Arch:        "any",
+		ArchiveType: "none",
+		BinaryPath:  path.Join(plugin.ListBinPaths(), "pipx"),
+	}, nil
+}
+
 // Download downloads the specified pipx version.
 func (*PipxPlugin) Download(ctx context.Context, version, downloadPath string) error {
 	pyzPath := filepath.Join(downloadPath, "pipx.pyz")
@@ -172,27 +219,19 @@ func (*PipxPlugin) Download(ctx context.Context, version, downloadPath string) e
 		return fmt.Errorf("creating request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := asdf.HTTPClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("downloading pipx: %w", err)
 	}
 	defer resp.Body.Close()
 
+	asdf.LogHTTPResponse(ctx, resp)
+
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("%w: %d", errPipxDownloadFailed, resp.StatusCode)
 	}
 
-	outFile, err := os.Create(pyzPath)
-	if err != nil {
-		return fmt.Errorf("creating file: %w", err)
-	}
-	defer outFile.Close()
-
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
-		return fmt.Errorf("writing file: %w", err)
-	}
-
-	return nil
+	return asdf.SaveResponse(ctx, resp, pyzPath)
 }
 
 // Install installs pipx from the downloaded .pyz file.
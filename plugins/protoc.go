@@ -16,18 +16,30 @@
 package plugins
 
 import (
+	"path/filepath"
+
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+// protocIncludeDir holds the well-known types, e.g. google/protobuf/timestamp.proto,
+// shipped next to bin in protoc release archives.
+const protocIncludeDir = "include"
+
+// ProtocPlugin implements the asdf.Plugin interface for protoc.
+type ProtocPlugin struct {
+	*asdf.BinaryPlugin
+}
+
 // NewProtocPlugin creates a new protoc plugin instance.
 func NewProtocPlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+	return &ProtocPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
 		Name:       "protoc",
 		RepoOwner:  "protocolbuffers",
 		RepoName:   "protobuf",
 		BinaryName: "protoc",
 
-		FileNameTemplate: "protoc-{{.Version}}-{{.Platform}}-{{.Arch}}.zip",
+		BinaryPathInArchive: "bin/protoc",
+		FileNameTemplate:    "protoc-{{.Version}}-{{.Platform}}-{{.Arch}}.zip",
 		OsMap: map[string]string{
 			"linux":  "linux",
 			"darwin": "osx",
@@ -35,9 +47,29 @@ func NewProtocPlugin() asdf.Plugin {
 		ArchMap: map[string]string{
 			"amd64": "x86_64",
 			"arm64": "aarch_64",
+			// protoc publishes a single universal binary for macOS.
+			"darwin/amd64": "universal_binary",
+			"darwin/arm64": "universal_binary",
 		},
 		HelpDescription: "Protocol Buffers - Google's data interchange format",
 		HelpLink:        "https://github.com/protocolbuffers/protobuf",
 		ArchiveType:     "zip",
-	})
+		ArchiveDirs:     []string{protocIncludeDir},
+	})}
+}
+
+// ExecEnv sets PROTOC_INCLUDE to the well-known types of the install, for
+// build scripts passing it to protoc as -I.
+func (*ProtocPlugin) ExecEnv(installPath string) map[string]string {
+	if installPath == "" {
+		return nil
+	}
+
+	return map[string]string{"PROTOC_INCLUDE": filepath.Join(installPath, protocIncludeDir)}
+}
+
+// ListBinPaths returns bin alone: the include directory reaches builds through
+// PROTOC_INCLUDE of exec-env rather than PATH.
+func (*ProtocPlugin) ListBinPaths() string {
+	return "bin"
 }
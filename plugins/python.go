@@ -16,7 +16,6 @@
 package plugins
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -35,20 +34,67 @@ const (
 	pyenvGitURL = "https://github.com/pyenv/pyenv.git"
 	// pythonFTPURL is the Python FTP server for version listing.
 	pythonFTPURL = "https://www.python.org/ftp/python/"
+	// pythonDefaultPackagesFile lists pip packages to install into every new version.
+	pythonDefaultPackagesFile = ".default-python-packages"
 )
 
+// pythonSettings are the ASDF_PYTHON_* settings read through asdf.PluginEnv.
+var pythonSettings = []asdf.EnvSetting{ //nolint:gochecknoglobals // read-only lookup table
+	{
+		Key: "DEFAULT_PACKAGES_FILE", Type: asdf.EnvTypeString, Default: "~/" + pythonDefaultPackagesFile,
+		Description: `Path to default pip packages file, one package per line, "#" comments allowed`,
+	},
+	{Key: "PATCH_URL", Type: asdf.EnvTypeString, Description: "URL to patch file to apply during build"},
+	{Key: "PATCHES_DIRECTORY", Type: asdf.EnvTypeString, Description: "Directory containing <version>.patch files"},
+	{Key: "SKIP_PREFLIGHT", Type: asdf.EnvTypeBool, Default: "false", Description: "Set to 1 to skip the build dependency check"},
+}
+
 var (
 	// errPythonNoVersionsFound is returned when no Python versions are discovered.
 	errPythonNoVersionsFound = errors.New("no versions found")
 	// stableCPythonVersionRE matches stable CPython versions in plain X.Y.Z form.
 	stableCPythonVersionRE = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+	// pythonBuildDependencies are the libraries whose absence makes CPython
+	// builds fail late or silently drop the ssl, zlib, ctypes or readline modules.
+	pythonBuildDependencies = []asdf.BuildDependency{ //nolint:gochecknoglobals // lookup table
+		{
+			Name: "OpenSSL", PkgConfig: "openssl", Header: "openssl/ssl.h",
+			Packages: map[asdf.PackageManager]string{
+				asdf.PackageManagerApt: "libssl-dev", asdf.PackageManagerDnf: "openssl-devel",
+				asdf.PackageManagerApk: "openssl-dev", asdf.PackageManagerBrew: "openssl@3",
+			},
+		},
+		{
+			Name: "zlib", PkgConfig: "zlib", Header: "zlib.h",
+			Packages: map[asdf.PackageManager]string{
+				asdf.PackageManagerApt: "zlib1g-dev", asdf.PackageManagerDnf: "zlib-devel",
+				asdf.PackageManagerApk: "zlib-dev", asdf.PackageManagerBrew: "zlib",
+			},
+		},
+		{
+			Name: "libffi", PkgConfig: "libffi", Header: "ffi.h",
+			Packages: map[asdf.PackageManager]string{
+				asdf.PackageManagerApt: "libffi-dev", asdf.PackageManagerDnf: "libffi-devel",
+				asdf.PackageManagerApk: "libffi-dev", asdf.PackageManagerBrew: "libffi",
+			},
+		},
+		{
+			Name: "readline", PkgConfig: "readline", Header: "readline/readline.h",
+			Packages: map[asdf.PackageManager]string{
+				asdf.PackageManagerApt: "libreadline-dev", asdf.PackageManagerDnf: "readline-devel",
+				asdf.PackageManagerApk: "readline-dev", asdf.PackageManagerBrew: "readline",
+			},
+		},
+	}
 )
 
 // PythonPlugin implements the asdf.Plugin interface for Python.
 type PythonPlugin struct {
 	*asdf.SourceBuildPlugin
 
-	pyenvDir string
+	pyenvDir  string
+	preflight asdf.BuildPreflight
 }
 
 // NewPythonPlugin creates a new Python plugin instance.
@@ -60,6 +106,10 @@ func NewPythonPlugin() asdf.Plugin {
 
 	plugin := &PythonPlugin{
 		pyenvDir: filepath.Join(homeDir, ".asdf-python-build"),
+		preflight: asdf.BuildPreflight{
+			SkipEnv:      asdf.PluginEnv("python").Name("SKIP_PREFLIGHT"),
+			Dependencies: pythonBuildDependencies,
+		},
 	}
 
 	plugin.SourceBuildPlugin = asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
@@ -73,6 +123,11 @@ func NewPythonPlugin() asdf.Plugin {
 		SkipExtract:       true,
 		LegacyFilenames:   []string{".python-version"},
 		ExpectedArtifacts: []string{"bin/python"},
+		// Explicit python-build settings are passed on; stray CFLAGS are not.
+		BuildEnvPassthrough: []string{
+			"PYTHON_CONFIGURE_OPTS", "PYTHON_CFLAGS", "PYTHON_MAKE_OPTS", "PYTHON_MAKE_INSTALL_OPTS",
+			"PYTHON_BUILD_*", "CONFIGURE_OPTS", "MAKE_OPTS", "MAKE_INSTALL_OPTS",
+		},
 
 		BuildVersion: func(ctx context.Context, version, _, installPath string) error {
 			// Check build dependencies
@@ -93,8 +148,8 @@ func NewPythonPlugin() asdf.Plugin {
 			asdf.Msgf("Installing Python %s to %s", version, installPath)
 
 			// Handle patches
-			patchURL := os.Getenv("ASDF_PYTHON_PATCH_URL")
-			patchDir := os.Getenv("ASDF_PYTHON_PATCHES_DIRECTORY")
+			patchURL := asdf.PluginEnv("python").String("PATCH_URL", "")
+			patchDir := asdf.PluginEnv("python").String("PATCHES_DIRECTORY", "")
 
 			var cmd *exec.Cmd
 
@@ -106,7 +161,7 @@ func NewPythonPlugin() asdf.Plugin {
 					return fmt.Errorf("downloading patch from %s: %w", patchURL, err)
 				}
 
-				cmd = exec.CommandContext(ctx, pythonBuildPath, "--patch", version, installPath)
+				cmd = asdf.BuildCommand(ctx, pythonBuildPath, "--patch", version, installPath)
 				cmd.Stdin = bytes.NewReader(patchData)
 			} else if patchDir != "" {
 				patchFile := filepath.Join(patchDir, version+".patch")
@@ -119,18 +174,17 @@ func NewPythonPlugin() asdf.Plugin {
 					}
 					defer patchReader.Close()
 
-					cmd = exec.CommandContext(ctx, pythonBuildPath, version, installPath, "-p")
+					cmd = asdf.BuildCommand(ctx, pythonBuildPath, version, installPath, "-p")
 					cmd.Stdin = patchReader
 				}
 			}
 
 			if cmd == nil {
-				cmd = exec.CommandContext(ctx, pythonBuildPath, version, installPath)
+				cmd = asdf.BuildCommand(ctx, pythonBuildPath, version, installPath)
 			}
 
 			cmd.Stdout = os.Stderr
 			cmd.Stderr = os.Stderr
-			cmd.Env = os.Environ()
 
 			err := cmd.Run()
 			if err != nil {
@@ -184,9 +238,30 @@ func (*PythonPlugin) ListBinPaths() string {
 	return "bin"
 }
 
-// ExecEnv returns environment variables for Python execution.
-func (*PythonPlugin) ExecEnv(_ string) map[string]string {
-	return nil
+// ExecEnv returns environment variables for Python execution. PYTHON_ROOT points
+// at the install so that other tools can find the interpreter without relying on
+// PATH, and PYTHONUSERBASE keeps `pip install --user` inside the install unless
+// the user configured it.
+func (*PythonPlugin) ExecEnv(installPath string) map[string]string {
+	if installPath == "" {
+		return nil
+	}
+
+	env := map[string]string{
+		"PYTHON_ROOT": installPath,
+	}
+
+	if os.Getenv("PYTHONUSERBASE") == "" {
+		env["PYTHONUSERBASE"] = installPath
+	}
+
+	return env
+}
+
+// HashIgnoreGlobs leaves bytecode caches, written whenever a module is first
+// imported, out of tool checksums.
+func (*PythonPlugin) HashIgnoreGlobs() []string {
+	return []string{"__pycache__", "*.pyc"}
 }
 
 // ListLegacyFilenames returns legacy version filenames for Python.
@@ -196,7 +271,7 @@ func (*PythonPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy Python version file.
 func (*PythonPlugin) ParseLegacyFile(path string) (string, error) {
-	return asdf.ReadLegacyVersionFile(path)
+	return asdf.ReadVersionFile(path)
 }
 
 // Uninstall removes a Python installation.
@@ -204,6 +279,12 @@ func (*PythonPlugin) Uninstall(_ context.Context, installPath string) error {
 	return os.RemoveAll(installPath)
 }
 
+// InstallsInPlace reports that Python installs in place, as python-build
+// compiles the install path into the interpreter and its scripts.
+func (*PythonPlugin) InstallsInPlace() bool {
+	return true
+}
+
 // Help returns help information for the Python plugin.
 func (*PythonPlugin) Help() asdf.PluginHelp {
 	return asdf.PluginHelp{
@@ -212,12 +293,13 @@ This plugin uses python-build (from pyenv) to compile Python from source.`,
 		Deps: `Build dependencies (Debian/Ubuntu):
   build-essential libssl-dev zlib1g-dev libbz2-dev libreadline-dev
   libsqlite3-dev curl libncursesw5-dev xz-utils tk-dev libxml2-dev
-  libxmlsec1-dev libffi-dev liblzma-dev`,
-		Config: `Environment variables:
-  ASDF_PYTHON_DEFAULT_PACKAGES_FILE - Path to default pip packages file (default: ~/.default-python-packages)
-  ASDF_PYTHON_PATCH_URL - URL to patch file to apply during build
-  ASDF_PYTHON_PATCHES_DIRECTORY - Directory containing patch files
-  PYTHON_BUILD_MIRROR_URL - Custom mirror URL for Python source downloads`,
+  libxmlsec1-dev libffi-dev liblzma-dev
+Install checks for the OpenSSL, zlib, libffi and readline headers before
+building and lists the packages to install for the detected distro.`,
+		HelpConfigEntries: append(asdf.PluginEnv("python").Document(pythonSettings...).ConfigEntries(),
+			asdf.ConfigEntry{Name: "PYTHON_BUILD_MIRROR_URL", Description: "Custom mirror URL for Python source downloads"}),
+		Config: `exec-env exports PYTHON_ROOT (the install path) and, unless already set,
+PYTHONUSERBASE so that 'pip install --user' stays inside the install.`,
 		Links: `Homepage: https://www.python.org/
 Documentation: https://docs.python.org/
 Downloads: https://www.python.org/downloads/
@@ -303,6 +385,8 @@ func (*PythonPlugin) LatestStable(ctx context.Context, query string) (string, er
 		return "", errPythonNoVersionsFound
 	}
 
+	// The release index only lists final releases, so a prerelease query is a plain prefix.
+	query, _ = asdf.ParseLatestQuery(query)
 	if query != "" {
 		filtered := asdf.FilterVersions(versions, func(v string) bool {
 			return strings.HasPrefix(v, query)
@@ -336,6 +420,10 @@ func (plugin *PythonPlugin) Download(ctx context.Context, _, _ string) error {
 
 // Install installs the specified Python version using python-build.
 func (plugin *PythonPlugin) Install(ctx context.Context, version, _, installPath string) error {
+	if err := plugin.preflight.Check(ctx); err != nil {
+		return err
+	}
+
 	err := plugin.SourceBuildPlugin.Install(ctx, version, "", installPath)
 	if err != nil {
 		return err
@@ -346,57 +434,27 @@ func (plugin *PythonPlugin) Install(ctx context.Context, version, _, installPath
 	return nil
 }
 
-// installDefaultPackages installs default pip packages if the configuration file exists.
+// installDefaultPackages pip-installs every package listed in ~/.default-python-packages,
+// or in the file named by ASDF_PYTHON_DEFAULT_PACKAGES_FILE, into the new
+// interpreter. Packages that fail to install are logged and skipped.
 func installDefaultPackages(ctx context.Context, installPath string) error {
-	defaultPackagesFile := os.Getenv("ASDF_PYTHON_DEFAULT_PACKAGES_FILE")
-	if defaultPackagesFile == "" {
-		homeDir, err := os.UserHomeDir()
-		if err == nil {
-			defaultPackagesFile = filepath.Join(homeDir, ".default-python-packages")
-		}
-	}
-
-	if defaultPackagesFile == "" {
-		return nil
-	}
+	var (
+		packages []string
+		err      error
+	)
 
-	if _, err := os.Stat(defaultPackagesFile); err != nil {
-		return nil
+	if path := asdf.PluginEnv("python").String("DEFAULT_PACKAGES_FILE", ""); path != "" {
+		packages, err = asdf.ReadPackagesFile(path)
+	} else {
+		packages, err = asdf.ReadDefaultPackagesFile(pythonDefaultPackagesFile)
 	}
 
-	asdf.Msgf("Installing default pip packages from %s", defaultPackagesFile)
-
-	file, err := os.Open(defaultPackagesFile)
-	if err != nil {
-		return fmt.Errorf("opening default packages file: %w", err)
-	}
-	defer file.Close()
-
-	pipPath := filepath.Join(installPath, "bin", "pip")
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		pkg := strings.TrimSpace(scanner.Text())
-		if pkg == "" || strings.HasPrefix(pkg, "#") {
-			continue
-		}
-
-		asdf.Msgf("Installing %s", pkg)
-
-		pipCmd := exec.CommandContext(ctx, pipPath, "install", pkg)
-
-		pipCmd.Stdout = os.Stderr
-		pipCmd.Stderr = os.Stderr
-
-		err := pipCmd.Run()
-		if err != nil {
-			asdf.Msgf("Warning: Failed to install %s: %v", pkg, err)
-		}
+	if err != nil || len(packages) == 0 {
+		return err
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("reading default packages file: %w", err)
-	}
+	binDir := filepath.Join(installPath, "bin")
 
-	return nil
+	return asdf.InstallDefaultPackages(ctx, packages, false,
+		map[string]string{"PATH": binDir}, filepath.Join(binDir, "python3"), "-m", "pip", "install")
 }
@@ -19,7 +19,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"os/exec"
@@ -35,21 +34,47 @@ var (
 	errRustNoChannelFound = errors.New("no channel found in file")
 	// errRustDownloadFailed indicates a non-success HTTP response when downloading rustup.
 	errRustDownloadFailed = errors.New("download failed")
+	// errRustComponentFailed is returned when a default component cannot be added.
+	errRustComponentFailed = errors.New("installing rust component")
+	// errRustTargetFailed is returned when a default target cannot be added.
+	errRustTargetFailed = errors.New("installing rust target")
 )
 
 const (
 	// rustupURL is the URL of the rustup installation script.
 	rustupURL = "https://sh.rustup.rs"
+	// rustDefaultComponentsFile lists rustup components added to every new toolchain.
+	rustDefaultComponentsFile = ".default-rust-components"
+	// rustDefaultTargetsFile lists rustup targets added to every new toolchain.
+	rustDefaultTargetsFile = ".default-rust-targets"
 )
 
+// rustSettings are the ASDF_RUST_* settings read through asdf.PluginEnv.
+var rustSettings = []asdf.EnvSetting{ //nolint:gochecknoglobals // read-only lookup table
+	{Key: "PROFILE", Type: asdf.EnvTypeString, Default: "default", Description: "Rustup profile to use (default, minimal, complete)"},
+	{
+		Key: "DEFAULT_COMPONENTS_FILE", Type: asdf.EnvTypeString, Default: "~/" + rustDefaultComponentsFile,
+		Description: `Path to default components file, e.g. rustfmt or clippy, one per line, "#" comments allowed`,
+	},
+	{
+		Key: "DEFAULT_TARGETS_FILE", Type: asdf.EnvTypeString, Default: "~/" + rustDefaultTargetsFile,
+		Description: `Path to default targets file, e.g. wasm32-unknown-unknown, one per line, "#" comments allowed`,
+	},
+}
+
 // RustPlugin implements the asdf.Plugin interface for Rust.
 type RustPlugin struct {
 	*asdf.SourceBuildPlugin
+
+	// RustupURL is where the rustup-init script is downloaded from. Toolchains,
+	// components and targets come from rustup's dist server, which rustup lets
+	// RUSTUP_DIST_SERVER override.
+	RustupURL string
 }
 
 // NewRustPlugin creates a new Rust plugin instance.
 func NewRustPlugin() asdf.Plugin {
-	plugin := &RustPlugin{}
+	plugin := &RustPlugin{RustupURL: rustupURL}
 
 	plugin.SourceBuildPlugin = asdf.NewSourceBuildPlugin(&asdf.SourceBuildPluginConfig{
 		Name:              "rust",
@@ -81,10 +106,7 @@ func NewRustPlugin() asdf.Plugin {
 
 			scriptPath := filepath.Join(downloadPath, "rustup-init.sh")
 
-			profile := os.Getenv("ASDF_RUST_PROFILE")
-			if profile == "" {
-				profile = "default"
-			}
+			profile := asdf.PluginEnv("rust").String("PROFILE", "default")
 
 			env := os.Environ()
 
@@ -143,25 +165,23 @@ func (plugin *RustPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy Rust version file.
 func (*RustPlugin) ParseLegacyFile(path string) (string, error) {
-	content, err := os.ReadFile(path)
+	if !strings.HasSuffix(path, ".toml") {
+		return asdf.ReadVersionFile(path)
+	}
+
+	content, err := asdf.ReadTextFile(path)
 	if err != nil {
 		return "", err
 	}
 
-	if strings.HasSuffix(path, ".toml") {
-		re := regexp.MustCompile(`channel\s*=\s*"([^"]+)"`)
+	re := regexp.MustCompile(`channel\s*=\s*"([^"]+)"`)
 
-		matches := re.FindStringSubmatch(string(content))
-		if len(matches) >= 2 {
-			return matches[1], nil
-		}
-
-		return "", fmt.Errorf("%w: %s", errRustNoChannelFound, path)
+	matches := re.FindStringSubmatch(content)
+	if len(matches) >= 2 {
+		return matches[1], nil
 	}
 
-	version := strings.TrimSpace(string(content))
-
-	return version, nil
+	return "", fmt.Errorf("%w: %s", errRustNoChannelFound, path)
 }
 
 // Uninstall removes a Rust installation.
@@ -169,15 +189,25 @@ func (plugin *RustPlugin) Uninstall(ctx context.Context, installPath string) err
 	return plugin.SourceBuildPlugin.Uninstall(ctx, installPath)
 }
 
+// InstallsInPlace reports that Rust installs in place, as rustup writes
+// the install path into its environment scripts.
+func (*RustPlugin) InstallsInPlace() bool {
+	return true
+}
+
 // Help returns help information for the Rust plugin.
 func (*RustPlugin) Help() asdf.PluginHelp {
 	return asdf.PluginHelp{
 		Overview: `Rust - A language empowering everyone to build reliable and efficient software.
 This plugin uses rustup to install Rust toolchains.`,
 		Deps: `Requires curl and a C compiler (gcc/clang) for some crates.`,
-		Config: `Environment variables:
-  ASDF_RUST_PROFILE - Rustup profile to use (default, minimal, complete)
-  ASDF_CRATE_DEFAULT_PACKAGES_FILE - Path to default cargo crates file`,
+		HelpConfigEntries: append(asdf.PluginEnv("rust").Document(rustSettings...).ConfigEntries(),
+			asdf.ConfigEntry{Name: "ASDF_CRATE_DEFAULT_PACKAGES_FILE", Description: "Path to default cargo crates file"},
+			asdf.ConfigEntry{Name: "RUSTUP_DIST_SERVER", Description: "Mirror to download toolchains, components and targets from"}),
+		Config: `Components and targets listed in .default-rust-components and
+.default-rust-targets in $HOME are added to every
+installed toolchain. exec-env points CARGO_HOME and RUSTUP_HOME at the
+install, so cargo's global state is kept per version.`,
 		Links: `Homepage: https://www.rust-lang.org/
 Documentation: https://doc.rust-lang.org/
 Rustup: https://rustup.rs/
@@ -218,7 +248,7 @@ func (plugin *RustPlugin) LatestStable(ctx context.Context, query string) (strin
 }
 
 // Download downloads rustup installer.
-func (*RustPlugin) Download(ctx context.Context, version, downloadPath string) error {
+func (plugin *RustPlugin) Download(ctx context.Context, version, downloadPath string) error {
 	scriptPath := filepath.Join(downloadPath, "rustup-init.sh")
 	if info, err := os.Stat(scriptPath); err == nil && info.Size() > 1024 {
 		asdf.Msgf("Using cached download for rust %s", version)
@@ -226,29 +256,25 @@ func (*RustPlugin) Download(ctx context.Context, version, downloadPath string) e
 		return nil
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rustupURL, http.NoBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, plugin.RustupURL, http.NoBody)
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := asdf.HTTPClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("downloading rustup: %w", err)
 	}
 	defer resp.Body.Close()
 
+	asdf.LogHTTPResponse(ctx, resp)
+
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("%w: %d", errRustDownloadFailed, resp.StatusCode)
 	}
 
-	outFile, err := os.Create(scriptPath)
-	if err != nil {
-		return fmt.Errorf("creating file: %w", err)
-	}
-	defer outFile.Close()
-
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
-		return fmt.Errorf("writing file: %w", err)
+	if err := asdf.SaveResponse(ctx, resp, scriptPath); err != nil {
+		return err
 	}
 
 	if err := os.Chmod(scriptPath, asdf.CommonDirectoryPermission); err != nil {
@@ -263,7 +289,70 @@ func (*RustPlugin) Download(ctx context.Context, version, downloadPath string) e
 	return nil
 }
 
-// Install installs Rust using rustup via SourceBuildPlugin.
-func (p *RustPlugin) Install(ctx context.Context, version, downloadPath, installPath string) error {
-	return p.SourceBuildPlugin.Install(ctx, version, downloadPath, installPath)
+// Install installs Rust using rustup via SourceBuildPlugin, then adds the
+// default components and targets to the toolchain.
+func (plugin *RustPlugin) Install(ctx context.Context, version, downloadPath, installPath string) error {
+	if err := plugin.SourceBuildPlugin.Install(ctx, version, downloadPath, installPath); err != nil {
+		return err
+	}
+
+	return plugin.installDefaultComponents(ctx, version, installPath)
+}
+
+// installDefaultComponents adds every component and target listed in the
+// default components and targets files to the toolchain with the rustup of
+// the install. rustup skips those already added, so reinstalls are cheap.
+func (plugin *RustPlugin) installDefaultComponents(ctx context.Context, version, installPath string) error {
+	components, err := readRustDefaults("DEFAULT_COMPONENTS_FILE", rustDefaultComponentsFile)
+	if err != nil {
+		return err
+	}
+
+	targets, err := readRustDefaults("DEFAULT_TARGETS_FILE", rustDefaultTargetsFile)
+	if err != nil {
+		return err
+	}
+
+	rustup := filepath.Join(installPath, "bin", "rustup")
+	env := plugin.ExecEnv(installPath)
+
+	for _, component := range components {
+		asdf.Msgf("Adding rust component %s", component)
+
+		if err := asdf.RunCommand(ctx, env, rustup, "component", "add", "--toolchain", version, component); err != nil {
+			return fmt.Errorf("%w %s: %w", errRustComponentFailed, component, err)
+		}
+	}
+
+	for _, target := range targets {
+		asdf.Msgf("Adding rust target %s", target)
+
+		if err := asdf.RunCommand(ctx, env, rustup, "target", "add", "--toolchain", version, target); err != nil {
+			return fmt.Errorf("%w %s: %w", errRustTargetFailed, target, err)
+		}
+	}
+
+	return nil
+}
+
+// readRustDefaults reads the file named by the ASDF_RUST_<key> setting, or
+// name from the home directory. Lines may hold several entries.
+func readRustDefaults(key, name string) ([]string, error) {
+	var (
+		lines []string
+		err   error
+	)
+
+	if path := asdf.PluginEnv("rust").String(key, ""); path != "" {
+		lines, err = asdf.ReadPackagesFile(path)
+	} else {
+		lines, err = asdf.ReadDefaultPackagesFile(name)
+	}
+
+	var entries []string
+	for _, line := range lines {
+		entries = append(entries, strings.Fields(line)...)
+	}
+
+	return entries, err
 }
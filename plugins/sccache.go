@@ -36,8 +36,9 @@ func NewSccachePlugin() asdf.Plugin {
 			"amd64": "x86_64",
 			"arm64": "aarch64",
 		},
-		HelpDescription: "sccache - Shared Compilation Cache",
-		HelpLink:        "https://github.com/mozilla/sccache",
-		ArchiveType:     "tar.gz",
+		PostInstallCheck: "{{.BinaryPath}} --version",
+		HelpDescription:  "sccache - Shared Compilation Cache",
+		HelpLink:         "https://github.com/mozilla/sccache",
+		ArchiveType:      "tar.gz",
 	})
 }
@@ -29,6 +29,7 @@ func NewShellcheckPlugin() asdf.Plugin {
 
 		FileNameTemplate:    "shellcheck-v{{.Version}}.{{.Platform}}.{{.Arch}}.tar.xz",
 		DownloadURLTemplate: "https://github.com/{{.RepoOwner}}/{{.RepoName}}/releases/download/v{{.Version}}/{{.FileName}}",
+		PostInstallCheck:    "{{.BinaryPath}} --version",
 		HelpDescription:     "ShellCheck - A static analysis tool for shell scripts",
 		HelpLink:            "https://github.com/koalaman/shellcheck",
 		ArchiveType:         "tar.xz",
@@ -16,21 +16,139 @@
 package plugins
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+var (
+	// errSqlcVersionType is returned when the version of a sqlc.json is neither a string nor a number.
+	errSqlcVersionType = errors.New("version is neither a string nor a number")
+
+	// sqlcSchemaVersion matches the bare integers sqlc.yaml and sqlc.json use as
+	// their configuration schema version, e.g. "2", rather than a sqlc release.
+	sqlcSchemaVersion = regexp.MustCompile(`^\d+$`)
+)
+
+// SqlcPlugin implements the asdf.Plugin interface for sqlc.
+type SqlcPlugin struct {
+	*asdf.BinaryPlugin
+}
+
 // NewSqlcPlugin creates a new sqlc plugin instance.
 func NewSqlcPlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+	return &SqlcPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
 		Name:       "sqlc",
 		RepoOwner:  "sqlc-dev",
 		RepoName:   "sqlc",
 		BinaryName: "sqlc",
 
-		FileNameTemplate: "sqlc_{{.Version}}_{{.Platform}}_{{.Arch}}.tar.gz",
-		HelpDescription:  "sqlc - Generate type-safe code from SQL",
-		HelpLink:         "https://sqlc.dev/",
-		ArchiveType:      "tar.gz",
-		VersionFilter:    `^\d+\.\d+\.\d+$`,
-	})
+		FileNameTemplate:     "sqlc_{{.Version}}_{{.Platform}}_{{.Arch}}.tar.gz",
+		ChecksumFileTemplate: "sqlc_{{.Version}}_checksums.txt",
+		HelpDescription:      "sqlc - Generate type-safe code from SQL",
+		HelpLink:             "https://sqlc.dev/",
+		ArchiveType:          "tar.gz",
+		VersionFilter:        `^\d+\.\d+\.\d+$`,
+	})}
+}
+
+// ListLegacyFilenames returns the sqlc configuration files.
+func (*SqlcPlugin) ListLegacyFilenames() []string {
+	return []string{"sqlc.yaml", "sqlc.json"}
+}
+
+// ParseLegacyFile returns the top-level version of a sqlc.yaml or sqlc.json,
+// failing with asdf.ErrNoLegacyPin when it has none. Configurations usually
+// hold their schema version there, e.g. "2", which pins no sqlc release and
+// is reported as a warning instead.
+func (*SqlcPlugin) ParseLegacyFile(path string) (string, error) {
+	data, err := asdf.ReadTextFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var version string
+	if filepath.Ext(path) == ".json" {
+		version, err = sqlcJSONVersion(data)
+		if err != nil {
+			return "", fmt.Errorf("parsing %s: %w", path, err)
+		}
+	} else {
+		version = sqlcYAMLVersion(data)
+	}
+
+	version = strings.TrimPrefix(version, "v")
+
+	switch {
+	case version == "":
+		return "", fmt.Errorf("%w: %s has no version", asdf.ErrNoLegacyPin, path)
+	case sqlcSchemaVersion.MatchString(version):
+		asdf.Errf("Warning: version %q of %s is the sqlc configuration schema version, not a sqlc release", version, path)
+
+		return "", fmt.Errorf("%w: %s only sets schema version %s", asdf.ErrNoLegacyPin, path, version)
+	}
+
+	return version, nil
+}
+
+// sqlcJSONVersion returns the top-level version of a sqlc.json, a string or
+// a number, or "" when there is none.
+func sqlcJSONVersion(data string) (string, error) {
+	var config struct {
+		Version json.RawMessage `json:"version"`
+	}
+
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		return "", err
+	}
+
+	if len(config.Version) == 0 || string(config.Version) == "null" {
+		return "", nil
+	}
+
+	var version string
+	if err := json.Unmarshal(config.Version, &version); err == nil {
+		return strings.TrimSpace(version), nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(config.Version, &number); err != nil {
+		return "", errSqlcVersionType
+	}
+
+	return number.String(), nil
+}
+
+// sqlcYAMLVersion returns the top-level version of a sqlc.yaml, or "" when
+// there is none. It only reads unindented "version:" lines with a plain or
+// quoted scalar, which is how sqlc configurations set it.
+func sqlcYAMLVersion(data string) string {
+	for line := range strings.Lines(data) {
+		value, found := strings.CutPrefix(strings.TrimRight(line, "\n"), "version:")
+		if !found {
+			continue
+		}
+
+		if comment := strings.Index(value, " #"); comment >= 0 {
+			value = value[:comment]
+		}
+
+		return strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+
+	return ""
+}
+
+// Help returns help information for the sqlc plugin.
+func (plugin *SqlcPlugin) Help() asdf.PluginHelp {
+	help := plugin.BinaryPlugin.Help()
+	help.Config = "Reads the top-level version of sqlc.yaml or sqlc.json when it names a sqlc release,\n" +
+		"e.g. \"1.27.0\"; the configuration schema version, e.g. \"2\", pins nothing."
+
+	return help
 }
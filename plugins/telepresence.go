@@ -16,12 +16,25 @@
 package plugins
 
 import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+// telepresenceClusterCheckEnv enables the traffic manager version check after installs when set to 1.
+const telepresenceClusterCheckEnv = "ASDF_TELEPRESENCE_CLUSTER_CHECK"
+
+// TelepresencePlugin implements the asdf.Plugin interface for Telepresence.
+type TelepresencePlugin struct {
+	*asdf.BinaryPlugin
+}
+
 // NewTelepresencePlugin creates a new Telepresence plugin instance.
 func NewTelepresencePlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+	return &TelepresencePlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
 		Name:       "telepresence",
 		RepoOwner:  "telepresenceio",
 		RepoName:   "telepresence",
@@ -32,5 +45,95 @@ func NewTelepresencePlugin() asdf.Plugin {
 		HelpLink:         "https://www.telepresence.io/",
 		ArchiveType:      "none",
 		VersionFilter:    `^\d+\.\d+\.\d+$`,
+	})}
+}
+
+// Install installs telepresence and, when ASDF_TELEPRESENCE_CLUSTER_CHECK=1
+// and a kubeconfig is available, warns when the traffic manager of the
+// cluster runs another minor version.
+func (plugin *TelepresencePlugin) Install(
+	ctx context.Context,
+	version, downloadPath, installPath string,
+) error {
+	err := plugin.BinaryPlugin.Install(ctx, version, downloadPath, installPath)
+	if err != nil {
+		return err
+	}
+
+	plugin.VersionSkew().Warn(ctx, "telepresence", filepath.Join(installPath, "bin", "telepresence"), version,
+		"install the version of the traffic manager or upgrade it with 'telepresence helm upgrade'")
+
+	return nil
+}
+
+// Help returns help information for the telepresence plugin.
+func (plugin *TelepresencePlugin) Help() asdf.PluginHelp {
+	help := plugin.BinaryPlugin.Help()
+	help.HelpConfigEntries = append(help.HelpConfigEntries, asdf.ConfigEntry{
+		Name:        telepresenceClusterCheckEnv,
+		Description: "Set to 1 to warn after installs when the traffic manager of the current cluster runs another minor version",
 	})
+
+	return help
+}
+
+// VersionSkew checks telepresence against the traffic manager
+// reported by 'telepresence version --output json'.
+func (*TelepresencePlugin) VersionSkew() asdf.VersionSkew {
+	return asdf.VersionSkew{
+		EnableEnv:     telepresenceClusterCheckEnv,
+		ConfigEnv:     "KUBECONFIG",
+		ConfigFile:    defaultKubeconfig,
+		ProbeArgs:     []string{"version", "--output", "json"},
+		ServerVersion: telepresenceServerVersion,
+	}
+}
+
+// telepresenceServerVersion returns the traffic manager version from
+// 'telepresence version --output json' output. The components are wrapped in
+// "stdout", as an object or as the text of the plain command, and named e.g.
+// "OSS Traffic Manager" with values such as "v2.14.0 (api v3)". It is empty
+// when the traffic manager is not connected.
+func telepresenceServerVersion(output []byte) (string, error) {
+	var result struct {
+		Stdout json.RawMessage `json:"stdout"`
+	}
+
+	if err := json.Unmarshal(output, &result); err != nil {
+		return "", err
+	}
+
+	if result.Stdout == nil {
+		result.Stdout = output
+	}
+
+	components := make(map[string]any)
+
+	var text string
+	if err := json.Unmarshal(result.Stdout, &text); err == nil {
+		for line := range strings.SplitSeq(text, "\n") {
+			if name, value, found := strings.Cut(line, ":"); found {
+				components[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+		}
+	} else if err := json.Unmarshal(result.Stdout, &components); err != nil {
+		return "", err
+	}
+
+	for name, value := range components {
+		if !strings.HasSuffix(strings.ReplaceAll(strings.ToLower(name), "_", " "), "traffic manager") {
+			continue
+		}
+
+		if component, ok := value.(map[string]any); ok {
+			value = component["version"]
+		}
+
+		version, _ := value.(string)
+		if fields := strings.Fields(version); len(fields) > 0 && len(asdf.ParseVersionParts(fields[0])) >= 2 {
+			return fields[0], nil
+		}
+	}
+
+	return "", nil
 }
@@ -16,12 +16,28 @@
 package plugins
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+const (
+	// terraformPluginCacheDirEnv overrides the shared provider cache directory,
+	// or disables it when set to "-".
+	terraformPluginCacheDirEnv = "ASDF_TF_PLUGIN_CACHE_DIR"
+	// terraformPluginCacheDirName is the shared provider cache below the asdf cache directory.
+	terraformPluginCacheDirName = "terraform-plugins"
+)
+
+// TerraformPlugin implements the asdf.Plugin interface for Terraform.
+type TerraformPlugin struct {
+	*asdf.BinaryPlugin
+}
+
 // NewTerraformPlugin creates a new terraform plugin instance.
 func NewTerraformPlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+	return &TerraformPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
 		Name:       "terraform",
 		RepoOwner:  "hashicorp",
 		RepoName:   "terraform",
@@ -32,5 +48,64 @@ func NewTerraformPlugin() asdf.Plugin {
 		HelpDescription:     "Terraform - Infrastructure as Code",
 		HelpLink:            "https://www.terraform.io/",
 		ArchiveType:         "zip",
-	})
+	})}
+}
+
+// ExecEnv shares one provider cache between all Terraform versions.
+func (*TerraformPlugin) ExecEnv(_ string) map[string]string {
+	return terraformPluginCacheEnv()
+}
+
+// Help returns help information for the terraform plugin.
+func (plugin *TerraformPlugin) Help() asdf.PluginHelp {
+	help := plugin.BinaryPlugin.Help()
+	help.Config = terraformPluginCacheHelp
+	help.HelpConfigEntries = append(help.HelpConfigEntries, terraformPluginCacheEntry)
+
+	return help
+}
+
+// terraformPluginCacheHelp documents the provider cache shared by terraform and opentofu.
+const terraformPluginCacheHelp = `Provider downloads are shared by every installed version through TF_PLUGIN_CACHE_DIR,
+unless it is already set.`
+
+// terraformPluginCacheEntry documents the variable moving the provider cache.
+var terraformPluginCacheEntry = asdf.ConfigEntry{ //nolint:gochecknoglobals // shared by the terraform and opentofu help
+	Name:        terraformPluginCacheDirEnv,
+	Default:     "$ASDF_DATA_DIR/cache/" + terraformPluginCacheDirName,
+	Description: `Provider cache directory, or "-" to leave TF_PLUGIN_CACHE_DIR unset`,
+}
+
+// terraformPluginCacheEnv returns TF_PLUGIN_CACHE_DIR pointing at the provider
+// cache shared by terraform and opentofu, creating the directory on demand. It
+// is empty when TF_PLUGIN_CACHE_DIR is already set, the cache is disabled with
+// ASDF_TF_PLUGIN_CACHE_DIR=-, or the directory cannot be created.
+func terraformPluginCacheEnv() map[string]string {
+	env := make(map[string]string)
+
+	cacheDir := os.Getenv(terraformPluginCacheDirEnv)
+	if cacheDir == "-" || os.Getenv("TF_PLUGIN_CACHE_DIR") != "" {
+		return env
+	}
+
+	if cacheDir == "" {
+		layout, err := asdf.CurrentLayout()
+		if err != nil {
+			asdf.Logger().Warn("not sharing the terraform provider cache", "error", err)
+
+			return env
+		}
+
+		cacheDir = filepath.Join(layout.CacheDir, terraformPluginCacheDirName)
+	}
+
+	if err := os.MkdirAll(cacheDir, asdf.CommonDirectoryPermission); err != nil {
+		asdf.Logger().Warn("not sharing the terraform provider cache", "dir", cacheDir, "error", err)
+
+		return env
+	}
+
+	env["TF_PLUGIN_CACHE_DIR"] = cacheDir
+
+	return env
 }
@@ -16,12 +16,23 @@
 package plugins
 
 import (
+	"path/filepath"
+
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+// terragruntConfigFile is the Terragrunt configuration whose
+// terragrunt_version_constraint attribute may pin the Terragrunt version.
+const terragruntConfigFile = "terragrunt.hcl"
+
+// TerragruntPlugin implements the asdf.Plugin interface for Terragrunt.
+type TerragruntPlugin struct {
+	*asdf.BinaryPlugin
+}
+
 // NewTerragruntPlugin creates a new terragrunt plugin instance.
 func NewTerragruntPlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+	return &TerragruntPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
 		Name:       "terragrunt",
 		RepoOwner:  "gruntwork-io",
 		RepoName:   "terragrunt",
@@ -33,5 +44,31 @@ func NewTerragruntPlugin() asdf.Plugin {
 		HelpDescription: "Terragrunt - Thin wrapper for Terraform",
 		HelpLink:        "https://github.com/gruntwork-io/terragrunt",
 		ArchiveType:     "none",
-	})
+	})}
+}
+
+// ListLegacyFilenames returns the tgenv version file, preferred, and the
+// Terragrunt configuration file.
+func (*TerragruntPlugin) ListLegacyFilenames() []string {
+	return []string{".terragrunt-version", terragruntConfigFile}
+}
+
+// ParseLegacyFile returns the version of a .terragrunt-version file verbatim,
+// or the newest version satisfying the terragrunt_version_constraint of a
+// terragrunt.hcl, failing with asdf.ErrNoLegacyPin when it has none.
+func (plugin *TerragruntPlugin) ParseLegacyFile(path string) (string, error) {
+	if filepath.Base(path) == terragruntConfigFile {
+		return parseHCLConstraint(plugin, path, "terragrunt_version_constraint")
+	}
+
+	return asdf.ReadVersionFile(path)
+}
+
+// Help returns help information for the terragrunt plugin.
+func (plugin *TerragruntPlugin) Help() asdf.PluginHelp {
+	help := plugin.BinaryPlugin.Help()
+	help.Config = "Reads .terragrunt-version, or the terragrunt_version_constraint of terragrunt.hcl\n" +
+		"when there is none, e.g. \">= 0.67\" or \"~> 0.67.0\"."
+
+	return help
 }
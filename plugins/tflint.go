@@ -16,12 +16,24 @@
 package plugins
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+// tflintConfigFile is the TFLint configuration whose tflint block may pin the
+// TFLint version with required_version.
+const tflintConfigFile = ".tflint.hcl"
+
+// TflintPlugin implements the asdf.Plugin interface for TFLint.
+type TflintPlugin struct {
+	*asdf.BinaryPlugin
+}
+
 // NewTflintPlugin creates a new tflint plugin instance.
 func NewTflintPlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+	return &TflintPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
 		Name:       "tflint",
 		RepoOwner:  "terraform-linters",
 		RepoName:   "tflint",
@@ -31,5 +43,45 @@ func NewTflintPlugin() asdf.Plugin {
 		HelpDescription:  "TFLint - A Terraform linter",
 		HelpLink:         "https://github.com/terraform-linters/tflint",
 		ArchiveType:      "zip",
-	})
+	})}
+}
+
+// ListLegacyFilenames returns the TFLint configuration file.
+func (*TflintPlugin) ListLegacyFilenames() []string {
+	return []string{tflintConfigFile}
+}
+
+// ParseLegacyFile returns the newest version satisfying the required_version
+// constraint of a .tflint.hcl, or asdf.ErrNoLegacyPin when it has none.
+func (plugin *TflintPlugin) ParseLegacyFile(path string) (string, error) {
+	return parseHCLConstraint(plugin, path, "required_version")
+}
+
+// Help returns help information for the tflint plugin.
+func (plugin *TflintPlugin) Help() asdf.PluginHelp {
+	help := plugin.BinaryPlugin.Help()
+	help.Config = "Reads the required_version constraint of .tflint.hcl, e.g. \">= 0.50\" or \"~> 0.53.0\"."
+
+	return help
+}
+
+// parseHCLConstraint resolves the version constraint held by the attribute of
+// the HCL file at path to the newest version of plugin satisfying it, failing
+// with asdf.ErrNoLegacyPin when the file has no such attribute.
+func parseHCLConstraint(plugin asdf.Plugin, path, attribute string) (string, error) {
+	constraint, found, err := asdf.HCLStringAttribute(path, attribute)
+	if err != nil {
+		return "", err
+	}
+
+	if !found {
+		return "", fmt.Errorf("%w: %s has no %s", asdf.ErrNoLegacyPin, path, attribute)
+	}
+
+	version, err := asdf.ResolveConstraint(context.Background(), plugin, constraint)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s of %s: %w", attribute, path, err)
+	}
+
+	return version, nil
 }
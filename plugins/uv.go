@@ -16,12 +16,23 @@
 package plugins
 
 import (
+	"path/filepath"
+
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
 
+// uvToolsDir is the UV_TOOL_DIR of an install, holding the tools installed
+// with `uv tool install` and their executables in bin.
+const uvToolsDir = "tools"
+
+// UvPlugin implements the asdf.Plugin interface for uv.
+type UvPlugin struct {
+	*asdf.BinaryPlugin
+}
+
 // NewUvPlugin creates a new uv plugin instance.
 func NewUvPlugin() asdf.Plugin {
-	return asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
+	return &UvPlugin{asdf.NewBinaryPlugin(&asdf.BinaryPluginConfig{
 		Name:       "uv",
 		RepoOwner:  "astral-sh",
 		RepoName:   "uv",
@@ -41,5 +52,25 @@ func NewUvPlugin() asdf.Plugin {
 		HelpDescription: "uv - An extremely fast Python package and project manager",
 		HelpLink:        "https://github.com/astral-sh/uv",
 		ArchiveType:     "tar.gz",
-	})
+		HashIgnoreGlobs: []string{uvToolsDir},
+	})}
+}
+
+// ExecEnv points UV_TOOL_DIR and UV_TOOL_BIN_DIR into the install, so tools
+// are scoped to the uv version and picked up by reshim.
+func (*UvPlugin) ExecEnv(installPath string) map[string]string {
+	if installPath == "" {
+		return nil
+	}
+
+	return map[string]string{
+		"UV_TOOL_DIR":     filepath.Join(installPath, uvToolsDir),
+		"UV_TOOL_BIN_DIR": filepath.Join(installPath, uvToolsDir, "bin"),
+	}
+}
+
+// ExtraBinDirs returns the UV_TOOL_BIN_DIR of the install, holding the
+// executables of tools installed with `uv tool install`.
+func (*UvPlugin) ExtraBinDirs(installPath string) []string {
+	return []string{filepath.Join(installPath, uvToolsDir, "bin")}
 }
@@ -27,8 +27,20 @@ func NewYqPlugin() asdf.Plugin {
 		RepoName:         "yq",
 		BinaryName:       "yq",
 		FileNameTemplate: "yq_{{.Platform}}_{{.Arch}}",
+		PostInstallCheck: "{{.BinaryPath}} --version",
 		HelpDescription:  "yq - a portable command-line YAML processor",
 		HelpLink:         "https://mikefarah.gitbook.io/yq/",
+		OsMap: map[string]string{
+			"darwin":  "darwin",
+			"freebsd": "freebsd",
+			"linux":   "linux",
+			"netbsd":  "netbsd",
+			"openbsd": "openbsd",
+		},
+		// BSD builds are published for amd64 only.
+		SupportedPlatforms: []string{
+			"darwin/amd64", "darwin/arm64", "freebsd/amd64", "linux/amd64", "linux/arm64", "netbsd/amd64", "openbsd/amd64",
+		},
 
 		VersionFilter: `^\d+\.\d+\.\d+`,
 	})
@@ -22,9 +22,12 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
-	"sort"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
 )
@@ -45,6 +48,16 @@ var (
 const (
 	// zigIndexDownloadURL is the URL of the Zig download index.
 	zigIndexDownloadURL = "https://ziglang.org/download/index.json"
+	// zigSkipVerifyEnv disables tarball verification when set to "1", for mirrors
+	// that rewrite archives.
+	zigSkipVerifyEnv = "ASDF_ZIG_SKIP_VERIFY"
+	// zigTarballName is the file name of the downloaded tarball.
+	zigTarballName = "zig.tar.xz"
+	// zigMasterVersion is the index key of the latest nightly build.
+	zigMasterVersion = "master"
+	// zigResolvedVersionFile records, in the download and install paths, the
+	// concrete dev version that "master" resolved to.
+	zigResolvedVersionFile = ".zig-resolved-version"
 )
 
 type (
@@ -55,6 +68,13 @@ type (
 		Size    string `json:"size"`
 	}
 
+	// ZigIndexEntry represents a version of the Zig index. Version is only set for
+	// "master", where it holds the concrete dev version of the nightly build.
+	ZigIndexEntry struct {
+		Platforms map[string]ZigRelease
+		Version   string
+	}
+
 	// ZigPlugin implements the asdf.Plugin interface for Zig.
 	ZigPlugin struct {
 		*asdf.SourceBuildPlugin
@@ -72,7 +92,7 @@ func NewZigPlugin() asdf.Plugin {
 		CreateBinDir:           &createBinDir,
 		SkipDownload:           true,
 		ArchiveType:            "tar.xz",
-		ArchiveNameTemplate:    "zig.tar.xz",
+		ArchiveNameTemplate:    zigTarballName,
 		AutoDetectExtractedDir: true,
 		ExpectedArtifacts:      []string{"zig"},
 		BuildVersion: func(_ context.Context, _, sourceDir, installPath string) error {
@@ -108,12 +128,7 @@ func (*ZigPlugin) ListLegacyFilenames() []string {
 
 // ParseLegacyFile parses a legacy version file.
 func (*ZigPlugin) ParseLegacyFile(path string) (string, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
-	}
-
-	return string(content), nil
+	return asdf.ReadVersionFile(path)
 }
 
 // Uninstall removes a Zig installation.
@@ -126,8 +141,17 @@ func (*ZigPlugin) Help() asdf.PluginHelp {
 	return asdf.PluginHelp{
 		Overview: `Zig - A general-purpose programming language and toolchain for maintaining
 robust, optimal, and reusable software.`,
-		Deps:   `No additional dependencies required.`,
-		Config: `No additional configuration required.`,
+		Deps: `No additional dependencies required.`,
+		Config: `Versions:
+  master               - The latest nightly build. It is resolved to its dev
+                         version (e.g. 0.15.0-dev.123+abc) at download time and
+                         recorded in ` + zigResolvedVersionFile + ` inside the install`,
+		HelpConfigEntries: []asdf.ConfigEntry{
+			{
+				Name:        "ASDF_ZIG_SKIP_VERIFY",
+				Description: "Set to 1 to skip the SHA256 and size checks against the Zig download index (for mirrors that rewrite archives)",
+			},
+		},
 		Links: `Homepage: https://ziglang.org/
 Documentation: https://ziglang.org/documentation/
 Source: https://codeberg.org/ziglang/zig`,
@@ -136,68 +160,49 @@ Source: https://codeberg.org/ziglang/zig`,
 
 // ListAll lists all available Zig versions.
 func (plugin *ZigPlugin) ListAll(ctx context.Context) ([]string, error) {
-	// Fetch Zig download index
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, plugin.ZigIndexURL, http.NoBody)
+	index, err := plugin.fetchIndex(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: %d", errZigFetchIndexFailed, resp.StatusCode)
-	}
-
-	var rawIndex map[string]map[string]json.RawMessage
-	if err := json.NewDecoder(resp.Body).Decode(&rawIndex); err != nil {
-		return nil, err
-	}
-
-	index := make(map[string]map[string]ZigRelease)
-	for version, platforms := range rawIndex {
-		index[version] = make(map[string]ZigRelease)
-
-		for platform, data := range platforms {
-			var release ZigRelease
-
-			err := json.Unmarshal(data, &release)
-			if err != nil {
-				continue
-			}
-
-			if release.Tarball != "" {
-				index[version][platform] = release
-			}
-		}
-	}
-
 	// Extract versions
 	var versions []string
 
 	for version := range index {
-		if version != "master" {
+		if version != zigMasterVersion {
 			versions = append(versions, version)
 		}
 	}
 
-	sort.Slice(versions, func(i, j int) bool {
-		return asdf.CompareVersions(versions[i], versions[j]) < 0
-	})
+	asdf.SortVersions(versions)
+
+	if _, ok := index[zigMasterVersion]; ok {
+		versions = append(versions, zigMasterVersion)
+	}
 
 	return versions, nil
 }
 
-// LatestStable returns the latest stable Zig version.
+// LatestStable returns the latest stable Zig version. The nightly "master" build
+// is only returned when the query is exactly "master".
 func (plugin *ZigPlugin) LatestStable(ctx context.Context, query string) (string, error) {
-	versions, err := plugin.ListAll(ctx)
+	allVersions, err := plugin.ListAll(ctx)
 	if err != nil {
 		return "", err
 	}
 
+	if query == zigMasterVersion {
+		if !slices.Contains(allVersions, zigMasterVersion) {
+			return "", fmt.Errorf("%w: %s", errZigNoVersionsMatching, query)
+		}
+
+		return zigMasterVersion, nil
+	}
+
+	versions := slices.DeleteFunc(allVersions, func(version string) bool {
+		return version == zigMasterVersion
+	})
+
 	if len(versions) == 0 {
 		return "", errZigNoVersionsFound
 	}
@@ -222,41 +227,40 @@ func (plugin *ZigPlugin) LatestStable(ctx context.Context, query string) (string
 	return filtered[len(filtered)-1], nil
 }
 
-// Download downloads the Zig tarball.
-func (plugin *ZigPlugin) Download(ctx context.Context, version, downloadPath string) error {
-	destPath := filepath.Join(downloadPath, "zig.tar.xz")
-	if info, err := os.Stat(destPath); err == nil && info.Size() > 1024 {
-		asdf.Msgf("Using cached download for zig %s", version)
-
-		return nil
-	}
-
-	// Fetch Zig download index
+// fetchIndex downloads the Zig index, keyed by version.
+func (plugin *ZigPlugin) fetchIndex(ctx context.Context) (map[string]ZigIndexEntry, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, plugin.ZigIndexURL, http.NoBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := asdf.HTTPClient().Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	asdf.LogHTTPResponse(ctx, resp)
+
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("%w: %d", errZigFetchIndexFailed, resp.StatusCode)
+		return nil, fmt.Errorf("%w: %d", errZigFetchIndexFailed, resp.StatusCode)
 	}
 
 	var rawIndex map[string]map[string]json.RawMessage
 	if err := json.NewDecoder(resp.Body).Decode(&rawIndex); err != nil {
-		return err
+		return nil, err
 	}
 
-	index := make(map[string]map[string]ZigRelease)
-	for ver, platforms := range rawIndex {
-		index[ver] = make(map[string]ZigRelease)
+	index := make(map[string]ZigIndexEntry)
+	for version, fields := range rawIndex {
+		entry := ZigIndexEntry{Platforms: make(map[string]ZigRelease)}
 
-		for platform, data := range platforms {
+		// Only the master entry carries a nested "version" string.
+		if data, ok := fields["version"]; ok {
+			_ = json.Unmarshal(data, &entry.Version)
+		}
+
+		for platform, data := range fields {
 			var release ZigRelease
 
 			err := json.Unmarshal(data, &release)
@@ -265,35 +269,214 @@ func (plugin *ZigPlugin) Download(ctx context.Context, version, downloadPath str
 			}
 
 			if release.Tarball != "" {
-				index[ver][platform] = release
+				entry.Platforms[platform] = release
 			}
 		}
+
+		index[version] = entry
+	}
+
+	return index, nil
+}
+
+// ArtifactNames returns the tarball Download stores for version and, unless
+// ASDF_ZIG_SKIP_VERIFY is set, the checksum Install verifies it with. "master"
+// also needs the dev version it was resolved to.
+func (*ZigPlugin) ArtifactNames(version string) ([]string, error) {
+	names := []string{zigTarballName}
+
+	if os.Getenv(zigSkipVerifyEnv) != "1" {
+		names = append(names, zigTarballName+asdf.ChecksumSidecarSuffix)
+	}
+
+	if version == zigMasterVersion {
+		names = append(names, zigResolvedVersionFile)
+	}
+
+	return names, nil
+}
+
+// Download downloads the Zig tarball and verifies it against the SHA256 and
+// size published in the index, unless ASDF_ZIG_SKIP_VERIFY is set. "master" is
+// resolved to the current nightly dev version, which is recorded next to the tarball.
+func (plugin *ZigPlugin) Download(ctx context.Context, version, downloadPath string) error {
+	destPath := filepath.Join(downloadPath, zigTarballName)
+	skipVerify := os.Getenv(zigSkipVerifyEnv) == "1"
+
+	if version != zigMasterVersion && zigCachedTarballUsable(destPath, skipVerify) {
+		asdf.Msgf("Using cached download for zig %s", version)
+
+		return nil
+	}
+
+	names, _ := plugin.ArtifactNames(version)
+	for i, name := range names {
+		names[i] = filepath.Join(downloadPath, name)
+	}
+
+	if err := asdf.OfflineDownloadError(plugin.Name(), version, names...); err != nil {
+		return err
+	}
+
+	index, err := plugin.fetchIndex(ctx)
+	if err != nil {
+		return err
 	}
 
-	platforms, ok := index[version]
+	entry, ok := index[version]
 	if !ok {
 		return fmt.Errorf("%w: %s", errZigVersionNotFound, version)
 	}
 
-	arch := runtime.GOARCH
+	if version == zigMasterVersion {
+		if entry.Version == "" {
+			return fmt.Errorf("%w: %s has no dev version in the index", errZigVersionNotFound, version)
+		}
+
+		recorded, err := readZigResolvedVersion(downloadPath)
+		if err == nil && recorded == entry.Version && zigCachedTarballUsable(destPath, skipVerify) {
+			asdf.Msgf("Using cached download for zig %s (%s)", version, recorded)
+
+			return nil
+		}
+
+		asdf.Msgf("Resolved zig %s to %s", version, entry.Version)
+	}
+
+	release, err := zigPlatformRelease(entry)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "Downloading Zig %s from %s\n", version, release.Tarball)
+
+	if version == zigMasterVersion {
+		// The tarball of a previous nightly must not be mistaken for this one.
+		_ = os.Remove(destPath)
+	}
+
+	if skipVerify {
+		if err := asdf.DownloadFile(ctx, release.Tarball, destPath); err != nil {
+			return fmt.Errorf("downloading zig: %w", err)
+		}
+	} else {
+		size, err := strconv.ParseInt(release.Size, 10, 64)
+		if err != nil {
+			size = 0
+		}
+
+		expected := asdf.ExpectedFile{SHA256: release.Shasum, Size: size}
+		if err := asdf.DownloadVerifiedFile(ctx, release.Tarball, destPath, expected); err != nil {
+			return fmt.Errorf("downloading zig: %w", err)
+		}
+	}
+
+	if version == zigMasterVersion {
+		return writeZigResolvedVersion(downloadPath, entry.Version)
+	}
+
+	return nil
+}
+
+// zigArch returns the architecture of the running system as named by the
+// Zig index, e.g. "x86_64".
+func zigArch() (string, error) {
+	arch, err := asdf.GetArch()
+	if err != nil {
+		return "", err
+	}
+
 	switch arch {
 	case "amd64":
-		arch = "x86_64"
+		return "x86_64", nil
 	case "arm64":
-		arch = "aarch64"
+		return "aarch64", nil
+	case "386":
+		return "x86", nil
+	}
+
+	return arch, nil
+}
+
+// zigPlatformRelease returns the release of entry for the running platform.
+func zigPlatformRelease(entry ZigIndexEntry) (ZigRelease, error) {
+	arch, err := zigArch()
+	if err != nil {
+		return ZigRelease{}, err
 	}
 
 	platformKey := fmt.Sprintf("%s-%s", arch, runtime.GOOS)
 
-	release, ok := platforms[platformKey]
+	release, ok := entry.Platforms[platformKey]
 	if !ok {
-		return fmt.Errorf("%w: %s", errZigNoReleaseForPlatform, platformKey)
+		return ZigRelease{}, fmt.Errorf("%w: %s", errZigNoReleaseForPlatform, platformKey)
 	}
 
-	_, _ = fmt.Fprintf(os.Stdout, "Downloading Zig %s from %s\n", version, release.Tarball)
+	return release, nil
+}
 
-	if err := asdf.DownloadFile(ctx, release.Tarball, destPath); err != nil {
-		return fmt.Errorf("downloading zig: %w", err)
+// ResolveArtifacts returns the tarball Download fetches for version, looked
+// up in the Zig download index.
+func (plugin *ZigPlugin) ResolveArtifacts(ctx context.Context, version string) ([]asdf.Artifact, error) {
+	index, err := plugin.fetchIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := index[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errZigVersionNotFound, version)
+	}
+
+	release, err := zigPlatformRelease(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return []asdf.Artifact{{Name: zigTarballName, URL: release.Tarball}}, nil
+}
+
+// DescribeArtifacts returns the architecture and operating system keying the
+// platforms of the Zig index, and the path of zig in the install.
+func (plugin *ZigPlugin) DescribeArtifacts(_ string) (asdf.ArtifactDescription, error) {
+	arch, err := zigArch()
+	if err != nil {
+		return asdf.ArtifactDescription{}, err
+	}
+
+	return asdf.ArtifactDescription{
+		Platform:    runtime.GOOS,
+		Arch:        arch,
+		ArchiveType: "tar.xz",
+		BinaryPath:  path.Join(plugin.ListBinPaths(), "zig"),
+	}, nil
+}
+
+// zigCachedTarballUsable reports whether a previously downloaded tarball can be reused.
+func zigCachedTarballUsable(destPath string, skipVerify bool) bool {
+	info, err := os.Stat(destPath)
+	if err != nil || info.Size() <= 1024 {
+		return false
+	}
+
+	return skipVerify || asdf.VerifyChecksumSidecar(destPath) == nil
+}
+
+// readZigResolvedVersion returns the dev version recorded in dir.
+func readZigResolvedVersion(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, zigResolvedVersionFile))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// writeZigResolvedVersion records the dev version that "master" resolved to in dir.
+func writeZigResolvedVersion(dir, resolved string) error {
+	path := filepath.Join(dir, zigResolvedVersionFile)
+	if err := os.WriteFile(path, []byte(resolved+"\n"), asdf.CommonFilePermission); err != nil {
+		return fmt.Errorf("recording zig version: %w", err)
 	}
 
 	return nil
@@ -304,21 +487,55 @@ func (plugin *ZigPlugin) Install(
 	ctx context.Context,
 	version, downloadPath, installPath string,
 ) error {
-	tarballPath := filepath.Join(downloadPath, "zig.tar.xz")
+	tarballPath := filepath.Join(downloadPath, zigTarballName)
 	if _, err := os.Stat(tarballPath); err != nil {
 		return err
 	}
 
+	// Re-verify the tarball; Download checks it against the index again and
+	// replaces it when the recorded checksum is missing or does not match.
+	if os.Getenv(zigSkipVerifyEnv) != "1" {
+		if err := asdf.VerifyChecksumSidecar(tarballPath); err != nil {
+			asdf.Logger().DebugContext(ctx, "zig tarball not verified", "path", tarballPath, "error", err)
+
+			if err := plugin.Download(ctx, version, downloadPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	resolved := version
+	if version == zigMasterVersion {
+		recorded, err := readZigResolvedVersion(downloadPath)
+		if err != nil {
+			if err := plugin.Download(ctx, version, downloadPath); err != nil {
+				return err
+			}
+
+			if recorded, err = readZigResolvedVersion(downloadPath); err != nil {
+				return fmt.Errorf("reading resolved zig version: %w", err)
+			}
+		}
+
+		resolved = recorded
+	}
+
 	err := plugin.SourceBuildPlugin.Install(ctx, version, downloadPath, installPath)
 	if err != nil {
 		return err
 	}
 
+	if version == zigMasterVersion {
+		if err := writeZigResolvedVersion(installPath, resolved); err != nil {
+			return err
+		}
+	}
+
 	if downloadPath != "" {
 		_ = os.RemoveAll(filepath.Join(downloadPath, "src"))
 	}
 
-	_, _ = fmt.Fprintf(os.Stdout, "Zig %s installed successfully\n", version)
+	_, _ = fmt.Fprintf(os.Stdout, "Zig %s installed successfully\n", resolved)
 
 	return nil
 }
@@ -0,0 +1,226 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf/plugins"
+)
+
+// Prefetch states printed by prefetch.
+const (
+	prefetchStateFetched = "FETCHED"
+	prefetchStateCached  = "CACHED"
+	prefetchStateFailed  = "FAILED"
+)
+
+// defaultPrefetchJobs is the number of tool versions prefetch downloads at a time.
+const defaultPrefetchJobs = 4
+
+// errPrefetchFailed is returned when a download attempted by prefetch failed.
+var errPrefetchFailed = errors.New("prefetch failed")
+
+type (
+	// prefetchTarget is a tool version to download into downloadPath.
+	prefetchTarget struct {
+		plugin       asdf.Plugin
+		err          error
+		name         string
+		version      string
+		downloadPath string
+	}
+
+	// prefetchResult is the outcome of prefetching a target.
+	prefetchResult struct {
+		err   error
+		state string
+		bytes int64
+	}
+)
+
+// cmdPrefetch implements the `prefetch` subcommand. It downloads every tool
+// version of toolVersionsPath into the data directory, jobs at a time,
+// without installing it, and records the checksums of the downloads in
+// .tool-sums. Downloads matching .tool-sums are left alone, and only failed
+// downloads fail the command.
+func cmdPrefetch(ctx context.Context, out io.Writer, toolVersionsPath string, jobs int) error {
+	pinned, err := parseToolVersions(toolVersionsPath)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", toolVersionsPath, err)
+	}
+
+	layout, err := asdf.CurrentLayout()
+	if err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	targets := make([]prefetchTarget, 0, len(pinned))
+
+	for _, name := range slices.Sorted(maps.Keys(pinned)) {
+		version := pinned[name]
+
+		// System tools are not downloaded, and refs are fetched when building them.
+		if version == asdf.SystemVersion || strings.HasPrefix(version, asdf.RefVersionPrefix) {
+			continue
+		}
+
+		plugin, err := plugins.GetPlugin(name)
+		if err != nil {
+			asdf.Logger().Warn("not prefetching tool without plugin", "tool", name, "error", err)
+
+			continue
+		}
+
+		target := prefetchTarget{plugin: plugin, name: name, version: version}
+
+		target.version, target.err = asdf.ResolveLatestVersion(ctx, plugin, version)
+		if target.err == nil {
+			target.version, target.err = asdf.ResolveEffectiveVersion(plugin, cwd, target.version)
+		}
+
+		if target.err != nil {
+			target.version = version
+		}
+
+		target.downloadPath = layout.DownloadPath(name, target.version)
+		targets = append(targets, target)
+	}
+
+	return prefetch(ctx, out, targets, jobs)
+}
+
+// prefetch downloads targets, jobs at a time, and prints a line per target
+// followed by a summary.
+func prefetch(ctx context.Context, out io.Writer, targets []prefetchTarget, jobs int) error {
+	sums, err := readToolSums(toolSumsFile)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext(ctx)
+	defer stop()
+
+	var (
+		// sumsMu serializes the updates of .tool-sums by the jobs.
+		sumsMu sync.Mutex
+		wg     sync.WaitGroup
+	)
+
+	slots := make(chan struct{}, max(jobs, 1))
+	results := make([]prefetchResult, len(targets))
+
+	for i, target := range targets {
+		wg.Go(func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			results[i] = prefetchTool(ctx, target, sums[target.name+":"+target.version], &sumsMu)
+		})
+	}
+
+	wg.Wait()
+
+	var (
+		counts = make(map[string]int, 3)
+		total  int64
+	)
+
+	for i, result := range results {
+		counts[result.state]++
+		total += result.bytes
+
+		detail := asdf.FormatBytes(result.bytes)
+		if result.err != nil {
+			detail = "error: " + result.err.Error()
+		}
+
+		_, _ = fmt.Fprintf(out, "%-8s %-20s %-16s %s\n", result.state, targets[i].name, targets[i].version, detail)
+	}
+
+	_, _ = fmt.Fprintf(out, "\nFetched: %d, Cached: %d, Failed: %d, Downloaded: %s\n",
+		counts[prefetchStateFetched], counts[prefetchStateCached], counts[prefetchStateFailed], asdf.FormatBytes(total))
+
+	if failed := counts[prefetchStateFailed]; failed > 0 {
+		return fmt.Errorf("%w: %d of %d downloads", errPrefetchFailed, failed, len(targets))
+	}
+
+	return nil
+}
+
+// prefetchTool downloads target unless its download matches the recorded
+// checksum sum, then verifies and records the checksum of the download.
+func prefetchTool(ctx context.Context, target prefetchTarget, sum string, sumsMu *sync.Mutex) prefetchResult {
+	if target.err != nil {
+		return prefetchResult{state: prefetchStateFailed, err: target.err}
+	}
+
+	if sum != "" {
+		if hash, err := getDownloadHash(target.downloadPath); err == nil && hash == sum {
+			return prefetchResult{state: prefetchStateCached}
+		}
+	}
+
+	failed := func(err error) prefetchResult {
+		removeIncomplete(ctx, target.downloadPath, err)
+
+		return prefetchResult{state: prefetchStateFailed, err: err}
+	}
+
+	if err := os.MkdirAll(target.downloadPath, asdf.CommonDirectoryPermission); err != nil {
+		return failed(fmt.Errorf("creating download directory: %w", err))
+	}
+
+	before := asdf.TreeSize(target.downloadPath)
+
+	var err error
+	if asdf.Offline() {
+		err = asdf.CheckOfflineDownload(target.plugin, target.version, target.downloadPath)
+	} else {
+		err = target.plugin.Download(ctx, target.version, target.downloadPath)
+	}
+
+	if err != nil {
+		return failed(err)
+	}
+
+	sumsMu.Lock()
+	defer sumsMu.Unlock()
+
+	if err := verifyToolSum(target.name, target.version, target.downloadPath); err != nil {
+		return failed(err)
+	}
+
+	if err := recordToolSum(target.name, target.version, target.downloadPath); err != nil {
+		asdf.Logger().Warn("failed to record checksum", "tool", target.name, "version", target.version, "error", err)
+	}
+
+	return prefetchResult{state: prefetchStateFetched, bytes: max(asdf.TreeSize(target.downloadPath)-before, 0)}
+}
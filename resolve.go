@@ -0,0 +1,127 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// errResolveUsage indicates invalid usage of the resolve command.
+var errResolveUsage = errors.New("usage: resolve <tool> [version]")
+
+// cmdResolve implements the `resolve` subcommand. It prints how version of
+// plugin resolves, "latest" queries and project keywords included, and the
+// files installing it would download on the running platform, without
+// downloading anything. An empty version is the one pinned for the working
+// directory, else the latest stable version. Plugins that cannot tell their
+// downloads beforehand are reported as such, and those that cannot install a
+// requested ref are rejected.
+func cmdResolve(ctx context.Context, out io.Writer, plugin asdf.Plugin, version string) error {
+	requested := version
+	if requested == "" {
+		pinned, err := asdf.ResolveToolVersion(plugin)
+		if err != nil {
+			return err
+		}
+
+		requested = cmp.Or(pinned, "latest")
+	}
+
+	printField := func(name, value string) {
+		_, _ = fmt.Fprintf(out, "%-14s %s\n", name+":", value)
+	}
+
+	printField("Tool", plugin.Name())
+	printField("Requested", requested)
+
+	resolved, err := asdf.ExpandToolVersion(requested)
+	if err != nil {
+		return err
+	}
+
+	if ref, ok := asdf.ParseRefVersion(resolved); ok {
+		if err := asdf.RequireCapability(plugin, asdf.CapabilityRefInstall, "resolve "+resolved); err != nil {
+			return err
+		}
+
+		printField("Ref", ref)
+		printField("Artifacts", "none, refs are built from their sources")
+
+		return nil
+	}
+
+	if resolved == asdf.SystemVersion {
+		printField("Resolved", resolved)
+		printField("Artifacts", "none, the system version is not installed")
+
+		return nil
+	}
+
+	resolved, err = asdf.ResolveLatestVersion(ctx, plugin, resolved)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", requested, err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	resolved, err = asdf.ResolveEffectiveVersion(plugin, cwd, resolved)
+	if err != nil {
+		return err
+	}
+
+	printField("Resolved", resolved)
+
+	resolver, ok := plugin.(asdf.PluginWithArtifactResolver)
+	if !ok {
+		printField("Artifacts", "unknown, "+plugin.Name()+" only selects its downloads while downloading")
+
+		return nil
+	}
+
+	if describer, ok := plugin.(asdf.ArtifactDescriber); ok {
+		description, err := describer.DescribeArtifacts(resolved)
+		if err != nil {
+			return err
+		}
+
+		printField("Platform", description.Platform)
+		printField("Arch", description.Arch)
+		printField("Archive type", description.ArchiveType)
+		printField("Binary path", description.BinaryPath)
+	}
+
+	artifacts, err := resolver.ResolveArtifacts(ctx, resolved)
+	if err != nil {
+		return err
+	}
+
+	for _, artifact := range artifacts {
+		printField("Artifact", artifact.Name)
+		printField("URL", artifact.URL)
+	}
+
+	return nil
+}
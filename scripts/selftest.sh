@@ -55,6 +55,12 @@ log_info "Building universal-asdf-plugin..."
 ./scripts/build.sh
 log_info "Binary built at ${BINARY}"
 
+# Cross-compile for every platform GetPlatform recognizes
+for platform in darwin/arm64 freebsd/amd64 freebsd/arm64 netbsd/amd64 openbsd/amd64 openbsd/arm64 illumos/amd64; do
+    log_info "Cross-compiling for ${platform}..."
+    GOOS="${platform%/*}" GOARCH="${platform#*/}" go build ./... || { log_error "build failed for ${platform}"; exit 1; }
+done
+
 # Add shims to PATH after build so plugins can find dependencies (e.g. asdf, npm, go)
 export PATH="${ASDF_DATA_DIR}/shims:$PATH"
 
@@ -291,6 +297,7 @@ log_info "Verified ${VERIFIED_COUNT} tools"
 # Generate checksums
 log_info "Generating checksums for installed tools..."
 "${BINARY}" generate-tool-sums || log_warn "Failed to generate checksums (command may not exist)"
+"${BINARY}" verify-installs || log_warn "Installs do not match the generated checksums"
 
 # Summary
 echo ""
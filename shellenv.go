@@ -0,0 +1,171 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+// envMarkerPrefix starts the first line of the env output, followed by the
+// SHA256 of the rest, so that direnv can tell changes without running it.
+const envMarkerPrefix = "# asdf-env: "
+
+// envPrependFormats render the directories prepended to a list variable such
+// as PATH per shell, keeping the current value of the variable.
+var envPrependFormats = map[string]func(key string, dirs []string) string{ //nolint:gochecknoglobals // lookup table
+	"bash": posixPrepend,
+	"zsh":  posixPrepend,
+	"fish": func(key string, dirs []string) string {
+		quoted := make([]string, 0, len(dirs))
+		for _, dir := range dirs {
+			quoted = append(quoted, "'"+strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(dir)+"'")
+		}
+
+		return "set -gx " + key + " " + strings.Join(quoted, " ") + " $" + key
+	},
+	"pwsh": func(key string, dirs []string) string {
+		joined := strings.ReplaceAll(strings.Join(dirs, string(os.PathListSeparator)), "'", "''")
+
+		return "$env:" + key + " = '" + joined + "' + [IO.Path]::PathSeparator + $env:" + key
+	},
+}
+
+// posixPrepend renders a bash or zsh export prepending dirs to key, without a
+// trailing separator when key is unset.
+func posixPrepend(key string, dirs []string) string {
+	joined := strings.ReplaceAll(strings.Join(dirs, string(os.PathListSeparator)), "'", `'\''`)
+
+	return "export " + key + "='" + joined + `'"${` + key + ":+" + string(os.PathListSeparator) + "$" + key + `}"`
+}
+
+// cmdEnv implements the `env` subcommand, an activation script for direnv or
+// eval as an alternative to shims. It prints, for shell, the bin directories
+// of the selected version of each tool prepended to PATH and the exec
+// environment of their plugins, for the tools of the nearest .tool-versions
+// when none are given. Tools without an installed version are skipped with a
+// comment. The output is sorted and starts with an envMarkerPrefix line.
+func cmdEnv(ctx context.Context, out io.Writer, tools []string, shell string) error {
+	export, exportOK := execEnvFormats[shell]
+	prepend, prependOK := envPrependFormats[shell]
+
+	if !exportOK || !prependOK {
+		return fmt.Errorf("%w: %s (use bash, zsh, fish or pwsh)", errExecEnvShellUnsupported, shell)
+	}
+
+	if len(tools) == 0 {
+		var err error
+
+		tools, err = nearestToolVersionsTools()
+		if err != nil {
+			return err
+		}
+	}
+
+	tools = slices.Compact(slices.Sorted(slices.Values(tools)))
+
+	var lines []string
+
+	lists := make(map[string][]string)
+	values := make(map[string]string)
+
+	for _, tool := range tools {
+		plugin, installPath, err := installedToolPath(ctx, tool, "")
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("# skipped %s: %s", tool, strings.ReplaceAll(err.Error(), "\n", " ")))
+
+			continue
+		}
+
+		lists["PATH"] = append(lists["PATH"], asdf.BinDirsOf(plugin, installPath)...)
+
+		env := plugin.ExecEnv(installPath)
+		for _, key := range slices.Sorted(maps.Keys(env)) {
+			if asdf.IsListEnvVar(key) {
+				lists[key] = append(lists[key], strings.Split(env[key], string(os.PathListSeparator))...)
+			} else {
+				values[key] = env[key]
+			}
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		lines = append(lines, export(key, values[key]))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(lists)) {
+		if dirs := uniqueDirs(lists[key]); len(dirs) > 0 {
+			lines = append(lines, prepend(key, dirs))
+		}
+	}
+
+	body := strings.Join(lines, "\n")
+	sum := sha256.Sum256([]byte(body))
+
+	_, err := fmt.Fprintln(out, envMarkerPrefix+hex.EncodeToString(sum[:]))
+	if err == nil && body != "" {
+		_, err = fmt.Fprintln(out, body)
+	}
+
+	return err
+}
+
+// uniqueDirs returns dirs without empty entries and repeated directories,
+// keeping the first occurrence.
+func uniqueDirs(dirs []string) []string {
+	unique := make([]string, 0, len(dirs))
+
+	for _, dir := range dirs {
+		if dir != "" && !slices.Contains(unique, dir) {
+			unique = append(unique, dir)
+		}
+	}
+
+	return unique
+}
+
+// nearestToolVersionsTools returns the tools of the nearest tool versions
+// file consulted from the working directory, none when there is no such file.
+func nearestToolVersionsTools() ([]string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range asdf.ToolVersionsFiles(cwd) {
+		entries, err := parseToolVersionEntries(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+
+		return slices.Sorted(maps.Keys(entries)), nil
+	}
+
+	return nil, nil
+}
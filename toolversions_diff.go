@@ -0,0 +1,192 @@
+//
+// Copyright (c) 2025 Sumicare
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sumicare/universal-asdf-plugin/plugins/asdf"
+)
+
+var (
+	// errToolVersionsDiffUsage indicates invalid usage of the tool-versions-diff command.
+	errToolVersionsDiffUsage = errors.New(
+		"usage: tool-versions-diff <old-file> <new-file> | tool-versions-diff --against-installed [file]")
+	// errToolVersionsDiffer is returned by tool-versions-diff when the pins differ,
+	// so that the command exits with status 1.
+	errToolVersionsDiffer = errors.New("tool versions differ")
+)
+
+const (
+	// toolVersionsAdded marks a tool only pinned on the new side.
+	toolVersionsAdded = "added"
+	// toolVersionsRemoved marks a tool only pinned on the old side.
+	toolVersionsRemoved = "removed"
+	// toolVersionsChanged marks a tool pinned to different versions.
+	toolVersionsChanged = "changed"
+)
+
+// toolVersionsChange is a tool whose pins differ between two sides of
+// tool-versions-diff. Multiple versions of a line are joined with spaces.
+type toolVersionsChange struct {
+	Tool   string `json:"tool"`
+	Change string `json:"change"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// cmdToolVersionsDiff implements the `tool-versions-diff` subcommand. It
+// prints the tools added, removed and changed from the .tool-versions file at
+// oldPath to the one at newPath, and fails with errToolVersionsDiffer when
+// there are any.
+func cmdToolVersionsDiff(out io.Writer, oldPath, newPath string, asJSON bool) error {
+	oldVersions, err := toolVersionsDiffSide(oldPath)
+	if err != nil {
+		return err
+	}
+
+	newVersions, err := toolVersionsDiffSide(newPath)
+	if err != nil {
+		return err
+	}
+
+	return writeToolVersionsChanges(out, diffToolVersions(oldVersions, newVersions), asJSON)
+}
+
+// cmdToolVersionsDiffInstalled implements `tool-versions-diff
+// --against-installed`. It compares the version each tool of the
+// .tool-versions file at path selects with its newest installed version, the
+// one pin-installed would write. Tools without an install are reported as
+// removed; "system" pins are never installed and are skipped.
+func cmdToolVersionsDiffInstalled(out io.Writer, path string, asJSON bool) error {
+	entries, err := parseToolVersionEntries(path)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	pinned := make(map[string]string, len(entries))
+	installed := make(map[string]string, len(entries))
+
+	for name, entry := range entries {
+		if entry.Version == asdf.SystemVersion {
+			continue
+		}
+
+		pinned[name] = entry.Version
+
+		versions, err := asdf.ListInstalled(name, "", nil)
+		if err != nil {
+			return err
+		}
+
+		if len(versions) > 0 {
+			installed[name] = versions[0].Version
+		}
+	}
+
+	return writeToolVersionsChanges(out, diffToolVersions(pinned, installed), asJSON)
+}
+
+// toolVersionsDiffSide reads the .tool-versions file at path into the
+// versions of each tool, multiple versions joined with spaces.
+func toolVersionsDiffSide(path string) (map[string]string, error) {
+	entries, err := parseToolVersionEntries(path)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	versions := make(map[string]string, len(entries))
+	for name, entry := range entries {
+		versions[name] = strings.Join(entry.Versions, " ")
+	}
+
+	return versions, nil
+}
+
+// diffToolVersions returns the changes from oldVersions to newVersions,
+// sorted by tool.
+func diffToolVersions(oldVersions, newVersions map[string]string) []toolVersionsChange {
+	names := slices.Sorted(maps.Keys(oldVersions))
+	for name := range newVersions {
+		if _, ok := oldVersions[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	changes := make([]toolVersionsChange, 0, len(names))
+
+	for _, name := range names {
+		oldVersion, inOld := oldVersions[name]
+		newVersion, inNew := newVersions[name]
+
+		switch {
+		case !inOld:
+			changes = append(changes, toolVersionsChange{Tool: name, Change: toolVersionsAdded, New: newVersion})
+		case !inNew:
+			changes = append(changes, toolVersionsChange{Tool: name, Change: toolVersionsRemoved, Old: oldVersion})
+		case oldVersion != newVersion:
+			changes = append(changes, toolVersionsChange{
+				Tool: name, Change: toolVersionsChanged, Old: oldVersion, New: newVersion,
+			})
+		}
+	}
+
+	return changes
+}
+
+// writeToolVersionsChanges prints changes as a table, or as JSON, and returns
+// errToolVersionsDiffer when there are any.
+func writeToolVersionsChanges(out io.Writer, changes []toolVersionsChange, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(changes); err != nil {
+			return err
+		}
+	} else if len(changes) == 0 {
+		_, _ = fmt.Fprintln(out, "No differences")
+	} else {
+		table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(table, "TOOL\tCHANGE\tOLD\tNEW")
+
+		for _, change := range changes {
+			_, _ = fmt.Fprintf(table, "%s\t%s\t%s\t%s\n",
+				change.Tool, change.Change, cmp.Or(change.Old, "-"), cmp.Or(change.New, "-"))
+		}
+
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(changes) > 0 {
+		return fmt.Errorf("%w: %d tools", errToolVersionsDiffer, len(changes))
+	}
+
+	return nil
+}